@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"math"
 	"os"
 	"time"
 
@@ -65,7 +66,8 @@ func createEarlyGamePosition() benchmarkPosition {
 
 	// Set current player to Player1
 	g.CurrentPlayer = game.Player1
-	g.Round = 3
+	// The round a real game reaches after three cards
+	g.Round = 2
 
 	return benchmarkPosition{
 		Name:        "Early Game",
@@ -110,7 +112,8 @@ func createMidGamePosition() benchmarkPosition {
 
 	// Set current player to Player2
 	g.CurrentPlayer = game.Player2
-	g.Round = 5
+	// The round a real game reaches after six cards
+	g.Round = 4
 
 	return benchmarkPosition{
 		Name:        "Midgame",
@@ -156,7 +159,8 @@ func createEndGamePosition() benchmarkPosition {
 
 	// Set current player to Player1
 	g.CurrentPlayer = game.Player1
-	g.Round = 8
+	// The round a real game reaches after eight cards
+	g.Round = 5
 
 	return benchmarkPosition{
 		Name:        "Endgame",
@@ -202,6 +206,10 @@ func main() {
 	analysisResults := make(map[string]interface{})
 	positionResults := make([]map[string]interface{}, 0, len(positions))
 
+	// Totals for the graded move-quality summary
+	totalRank, rankedPositions := 0, 0
+	totalGap := 0.0
+
 	for i, position := range positions {
 		fmt.Printf("\n[%d/%d] Analyzing position: %s\n", i+1, len(positions), position.Name)
 		if *verbose {
@@ -212,6 +220,7 @@ func main() {
 		startTime := time.Now()
 		bestMove, bestValue := minimaxEngine.FindBestMove(position.Game)
 		minimaxTime := time.Since(startTime)
+		minimaxNodes := minimaxEngine.NodesEvaluated
 
 		// Get model's prediction
 		modelMove, err := getModelMove(model, position.Game)
@@ -222,9 +231,14 @@ func main() {
 
 		// Print results
 		fmt.Printf("Minimax best move: %v (value: %.2f, time: %v, nodes: %d)\n",
-			formatMove(bestMove), bestValue, minimaxTime, minimaxEngine.NodesEvaluated)
+			formatMove(bestMove), bestValue, minimaxTime, minimaxNodes)
 		fmt.Printf("Model's move: %v\n", formatMove(modelMove))
 
+		// Low entropy means a confident policy, high entropy a diffuse one
+		entropy := model.PredictionEntropy(position.Game)
+		maxEntropy := math.Log(float64(countLegalSquares(position.Game)))
+		fmt.Printf("Policy entropy: %.3f nats (uniform: %.3f)\n", entropy, maxEntropy)
+
 		// Check if model's move matches minimax
 		matches := moveEquals(bestMove, modelMove)
 		if matches {
@@ -233,15 +247,45 @@ func main() {
 			fmt.Println("✗ Model's move differs from minimax")
 		}
 
+		// Score every legal move so the model's choice can be graded, not just matched
+		scores := minimaxEngine.ScoreMoves(position.Game)
+		modelScore, ranked := analysis.FindMoveScore(scores, modelMove)
+		if ranked {
+			fmt.Printf("Model's move ranks %d of %d (value: %.2f, %.2f below best)\n",
+				modelScore.Rank, len(scores), modelScore.Value, modelScore.Gap)
+			totalRank += modelScore.Rank
+			totalGap += modelScore.Gap
+			rankedPositions++
+		}
+
+		ranking := make([]map[string]interface{}, len(scores))
+		for j, score := range scores {
+			ranking[j] = map[string]interface{}{
+				"move":  formatMove(score.Move),
+				"card":  score.Move.CardIndex,
+				"value": score.Value,
+				"rank":  score.Rank,
+				"gap":   score.Gap,
+			}
+		}
+
 		// Add to results for output file
 		positionResult := map[string]interface{}{
 			"position_name":   position.Name,
 			"minimax_move":    formatMove(bestMove),
 			"minimax_value":   bestValue,
-			"minimax_nodes":   minimaxEngine.NodesEvaluated,
+			"minimax_nodes":   minimaxNodes,
 			"minimax_time_ms": minimaxTime.Milliseconds(),
 			"model_move":      formatMove(modelMove),
 			"matches_minimax": matches,
+			"legal_moves":     len(scores),
+			"model_entropy":   entropy,
+			"max_entropy":     maxEntropy,
+			"move_ranking":    ranking,
+		}
+		if ranked {
+			positionResult["model_rank"] = modelScore.Rank
+			positionResult["model_value_gap"] = modelScore.Gap
 		}
 		positionResults = append(positionResults, positionResult)
 
@@ -261,9 +305,18 @@ func main() {
 		}
 	}
 
+	if rankedPositions > 0 {
+		fmt.Printf("\nAverage rank of model's move: %.2f (average value gap: %.2f)\n",
+			float64(totalRank)/float64(rankedPositions), totalGap/float64(rankedPositions))
+	}
+
 	// Save results to output file if specified
 	if *outputPath != "" {
 		analysisResults["positions"] = positionResults
+		if rankedPositions > 0 {
+			analysisResults["average_model_rank"] = float64(totalRank) / float64(rankedPositions)
+			analysisResults["average_value_gap"] = totalGap / float64(rankedPositions)
+		}
 		analysisResults["model_path"] = *modelPath
 		analysisResults["minimax_depth"] = *depth
 		analysisResults["timestamp"] = time.Now().Format(time.RFC3339)
@@ -298,8 +351,8 @@ func getModelMove(model *neural.RPSPolicyNetwork, gameState *game.RPSGame) (game
 		return game.RPSMove{}, fmt.Errorf("no valid moves")
 	}
 
-	// Get model's predictions directly using the RPSPolicyNetwork's Predict method
-	predictions := model.Predict(gameState)
+	// Get the model's predictions over the legal squares
+	predictions := model.PredictMasked(gameState)
 
 	// Find highest probability valid move
 	bestScore := -1.0
@@ -318,6 +371,15 @@ func getModelMove(model *neural.RPSPolicyNetwork, gameState *game.RPSGame) (game
 	return bestMove, nil
 }
 
+// countLegalSquares returns the number of squares the player to move can play on
+func countLegalSquares(gameState *game.RPSGame) int {
+	squares := make(map[int]bool)
+	for _, move := range gameState.GetValidMoves() {
+		squares[move.Position] = true
+	}
+	return len(squares)
+}
+
 // formatMove formats a move for display
 func formatMove(move game.RPSMove) string {
 	row := move.Position / 3
@@ -1,14 +1,15 @@
 package main
 
 import (
-	"bufio"
+	"flag"
 	"fmt"
+	"math"
 	"math/rand"
 	"os"
-	"strconv"
 	"strings"
 	"time"
 
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/agents"
 	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
 	"github.com/zachbeta/neural_rps/alphago_demo/pkg/mcts"
 	neural "github.com/zachbeta/neural_rps/alphago_demo/pkg/rps_net_impl"
@@ -33,7 +34,23 @@ const (
 	learningRate   = 0.01
 )
 
+// difficulty sets the AI's strength in games against a human; it is chosen
+// with -difficulty and can be changed from the main menu
+var difficulty = mcts.DifficultyMedium
+
+// input reads every answer from stdin
+var input = game.NewPrompter(os.Stdin, os.Stdout)
+
 func main() {
+	difficultyName := flag.String("difficulty", "medium", "AI strength: easy, medium or hard")
+	flag.Parse()
+
+	var err error
+	if difficulty, err = mcts.ParseDifficulty(*difficultyName); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
 	// Seed random number generator
 	rand.Seed(time.Now().UnixNano())
 
@@ -77,11 +94,14 @@ func main() {
 		fmt.Println("\nMain Menu:")
 		fmt.Println("1. Play balanced match against AI (two rounds)")
 		fmt.Println("2. Watch balanced AI vs AI demonstration (two rounds)")
-		fmt.Println("3. Exit")
-		fmt.Print("Select an option: ")
+		fmt.Printf("3. Change difficulty (currently %s)\n", difficulty)
+		fmt.Println("4. Exit")
 
-		var choice int
-		fmt.Scanln(&choice)
+		choice, err := input.Int("Select an option: ", 1, 4)
+		if err != nil {
+			fmt.Println("Goodbye!")
+			return
+		}
 
 		switch choice {
 		case 1:
@@ -89,10 +109,10 @@ func main() {
 		case 2:
 			balancedAIDemonstration(policyNetwork, valueNetwork)
 		case 3:
+			chooseDifficulty()
+		case 4:
 			fmt.Println("Goodbye!")
 			return
-		default:
-			fmt.Println("Invalid choice. Please try again.")
 		}
 	}
 }
@@ -150,10 +170,11 @@ func playBalancedMatchAgainstAI(policyNetwork *neural.RPSPolicyNetwork, valueNet
 		fmt.Println("The match is a draw!")
 
 		// Optional: Offer tiebreaker
-		fmt.Print("\nWould you like to play a tiebreaker round? (y/n): ")
-		var choice string
-		fmt.Scanln(&choice)
-		if strings.ToLower(choice) == "y" {
+		tiebreak, err := input.YesNo("\nWould you like to play a tiebreaker round? (y/n): ")
+		if err != nil {
+			exitOnClosedInput()
+		}
+		if tiebreak {
 			fmt.Println("\n=== TIEBREAKER ROUND ===")
 			// Randomly assign positions for tiebreaker
 			humanIsPlayer1 := rand.Intn(2) == 0
@@ -194,11 +215,9 @@ func playRoundAgainstAI(policyNetwork *neural.RPSPolicyNetwork, valueNetwork *ne
 	gameInstance := game.NewRPSGame(deckSize, handSize, maxRounds)
 
 	// Create MCTS for AI
-	mctsParams := mcts.DefaultRPSMCTSParams()
+	mctsParams := difficulty.Apply(mcts.DefaultRPSMCTSParams())
 	mctsEngine := mcts.NewRPSMCTS(policyNetwork, valueNetwork, mctsParams)
 
-	scanner := bufio.NewScanner(os.Stdin)
-
 	// Game loop
 	for !gameInstance.IsGameOver() {
 		// Display game state
@@ -245,20 +264,9 @@ func playRoundAgainstAI(policyNetwork *neural.RPSPolicyNetwork, valueNetwork *ne
 			}
 
 			// Get card selection
-			var cardIndex int
-			for {
-				fmt.Print("Select card (0-" + strconv.Itoa(len(playerHand)-1) + "): ")
-				var input string
-				scanner.Scan()
-				input = scanner.Text()
-
-				var err error
-				cardIndex, err = strconv.Atoi(input)
-				if err != nil || cardIndex < 0 || cardIndex >= len(playerHand) {
-					fmt.Println("Invalid selection. Try again.")
-					continue
-				}
-				break
+			cardIndex, err := input.Int(fmt.Sprintf("Select card (0-%d): ", len(playerHand)-1), 0, len(playerHand)-1)
+			if err != nil {
+				exitOnClosedInput()
 			}
 
 			// Get valid positions for this card
@@ -315,28 +323,9 @@ func playRoundAgainstAI(policyNetwork *neural.RPSPolicyNetwork, valueNetwork *ne
 			// Get position selection
 			var row, col int
 			for {
-				fmt.Print("Enter position as row,col (e.g., 1,2): ")
-				var input string
-				scanner.Scan()
-				input = scanner.Text()
-
-				parts := strings.Split(input, ",")
-				if len(parts) != 2 {
-					fmt.Println("Invalid format. Use row,col (e.g., 1,2)")
-					continue
-				}
-
-				var err error
-				row, err = strconv.Atoi(strings.TrimSpace(parts[0]))
-				if err != nil || row < 0 || row >= 3 {
-					fmt.Println("Invalid row. Must be 0-2.")
-					continue
-				}
-
-				col, err = strconv.Atoi(strings.TrimSpace(parts[1]))
-				if err != nil || col < 0 || col >= 3 {
-					fmt.Println("Invalid column. Must be 0-2.")
-					continue
+				row, col, err = input.RowCol("Enter position as row,col (e.g., 1,2): ", 3)
+				if err != nil {
+					exitOnClosedInput()
 				}
 
 				position := row*3 + col
@@ -364,8 +353,7 @@ func playRoundAgainstAI(policyNetwork *neural.RPSPolicyNetwork, valueNetwork *ne
 				Player:    gameInstance.CurrentPlayer,
 			}
 
-			err := gameInstance.MakeMove(move)
-			if err != nil {
+			if err := gameInstance.MakeMove(move); err != nil {
 				fmt.Printf("Error making move: %v\n", err)
 				return 0, 0
 			}
@@ -374,9 +362,10 @@ func playRoundAgainstAI(policyNetwork *neural.RPSPolicyNetwork, valueNetwork *ne
 			// AI's turn
 			fmt.Println("AI is thinking...")
 
-			// Use MCTS to find the best move
+			// Search, then pick a move as the difficulty allows
 			mctsEngine.SetRootState(gameInstance)
-			bestNode := mctsEngine.Search()
+			mctsEngine.Search()
+			bestNode := mctsEngine.SampleMove(difficulty.Temperature())
 
 			if bestNode != nil && bestNode.Move != nil {
 				// Make the move
@@ -403,21 +392,21 @@ func playRoundAgainstAI(policyNetwork *neural.RPSPolicyNetwork, valueNetwork *ne
 				fmt.Printf("AI plays %s at position (%d,%d)\n", cardTypeStr, row, col)
 
 			} else {
-				// Use random move if MCTS fails
-				randomMove, err := gameInstance.GetRandomMove()
+				// Fall back to the policy network's top legal move if MCTS fails
+				fallbackMove, err := agents.FallbackMove(gameInstance, agents.FallbackPolicyPrior, mctsEngine.PolicyNetwork)
 				if err != nil {
 					fmt.Printf("No valid moves for AI: %v\n", err)
 					break
 				}
 
-				err = gameInstance.MakeMove(randomMove)
+				err = gameInstance.MakeMove(fallbackMove)
 				if err != nil {
-					fmt.Printf("Error making random AI move: %v\n", err)
+					fmt.Printf("Error making fallback AI move: %v\n", err)
 					return 0, 0
 				}
 
 				// Display the move
-				cardType := gameInstance.Board[randomMove.Position].Type
+				cardType := gameInstance.Board[fallbackMove.Position].Type
 				var cardTypeStr string
 				switch cardType {
 				case game.Rock:
@@ -428,8 +417,8 @@ func playRoundAgainstAI(policyNetwork *neural.RPSPolicyNetwork, valueNetwork *ne
 					cardTypeStr = "Scissors"
 				}
 
-				row := randomMove.Position / 3
-				col := randomMove.Position % 3
+				row := fallbackMove.Position / 3
+				col := fallbackMove.Position % 3
 				fmt.Printf("AI plays %s at position (%d,%d)\n", cardTypeStr, row, col)
 			}
 		}
@@ -558,6 +547,7 @@ func playAIvsAIRound(policyNetwork *neural.RPSPolicyNetwork, valueNetwork *neura
 		fmt.Printf("\nMove %d\n", moveCount+1)
 
 		// Use MCTS to find the best move
+		mover := gameInstance.CurrentPlayer
 		mctsEngine.SetRootState(gameInstance)
 		bestNode := mctsEngine.Search()
 
@@ -591,30 +581,31 @@ func playAIvsAIRound(policyNetwork *neural.RPSPolicyNetwork, valueNetwork *neura
 			col := move.Position % 3
 			fmt.Printf("%s plays %s at position (%d,%d)\n", playerName, cardTypeStr, row, col)
 
-			// Display game state
+			// Display the game state and who the search thinks is winning
 			fmt.Println(gameInstance.String())
+			fmt.Println("Evaluation: " + valueBar(player1Score(mctsEngine.GetRootValue(), mover)))
 
 		} else {
-			// Use random move if MCTS fails
-			randomMove, err := gameInstance.GetRandomMove()
+			// Fall back to the policy network's top legal move if MCTS fails
+			fallbackMove, err := agents.FallbackMove(gameInstance, agents.FallbackPolicyPrior, mctsEngine.PolicyNetwork)
 			if err != nil {
 				fmt.Printf("No valid moves: %v\n", err)
 				break
 			}
 
-			err = gameInstance.MakeMove(randomMove)
+			err = gameInstance.MakeMove(fallbackMove)
 			if err != nil {
-				fmt.Printf("Error making random move: %v\n", err)
+				fmt.Printf("Error making fallback move: %v\n", err)
 				return 0, 0
 			}
 
 			// Display the move
 			playerName := "Player 1"
-			if randomMove.Player == game.Player2 {
+			if fallbackMove.Player == game.Player2 {
 				playerName = "Player 2"
 			}
 
-			cardType := gameInstance.Board[randomMove.Position].Type
+			cardType := gameInstance.Board[fallbackMove.Position].Type
 			var cardTypeStr string
 			switch cardType {
 			case game.Rock:
@@ -625,9 +616,9 @@ func playAIvsAIRound(policyNetwork *neural.RPSPolicyNetwork, valueNetwork *neura
 				cardTypeStr = "Scissors"
 			}
 
-			row := randomMove.Position / 3
-			col := randomMove.Position % 3
-			fmt.Printf("%s plays %s at position (%d,%d) (random)\n", playerName, cardTypeStr, row, col)
+			row := fallbackMove.Position / 3
+			col := fallbackMove.Position % 3
+			fmt.Printf("%s plays %s at position (%d,%d) (fallback)\n", playerName, cardTypeStr, row, col)
 
 			// Display game state
 			fmt.Println(gameInstance.String())
@@ -655,3 +646,47 @@ func playAIvsAIRound(policyNetwork *neural.RPSPolicyNetwork, valueNetwork *neura
 
 	return player1Cards, player2Cards
 }
+
+// chooseDifficulty asks for a new AI difficulty from the main menu
+func chooseDifficulty() {
+	fmt.Println("\nDifficulty:")
+	for i, d := range mcts.Difficulties {
+		fmt.Printf("%d. %s (%d simulations)\n", i+1, d, d.Simulations())
+	}
+
+	choice, err := input.Int("Select a difficulty: ", 1, len(mcts.Difficulties))
+	if err != nil {
+		return
+	}
+	difficulty = mcts.Difficulties[choice-1]
+	fmt.Printf("Difficulty set to %s\n", difficulty)
+}
+
+// exitOnClosedInput ends the program when stdin closes in the middle of a
+// match, since no round can be finished without the human's moves
+func exitOnClosedInput() {
+	fmt.Println("Input closed. Goodbye!")
+	os.Exit(0)
+}
+
+// valueBar draws a position's evaluation, from -1 (Player 2 winning) to +1
+// (Player 1 winning), as a bar growing from the centre towards the leader
+func valueBar(score float64) string {
+	const half = 10
+	score = math.Max(-1, math.Min(1, score))
+
+	p1 := int(math.Round(math.Max(score, 0) * half))
+	p2 := int(math.Round(math.Max(-score, 0) * half))
+	left := strings.Repeat(" ", half-p2) + strings.Repeat("=", p2)
+	right := strings.Repeat("=", p1) + strings.Repeat(" ", half-p1)
+	return fmt.Sprintf("P2 [%s|%s] P1  %+.2f", left, right, score)
+}
+
+// player1Score converts a value for mover, from 0 to 1, to a score from -1
+// (Player 2 winning) to +1 (Player 1 winning)
+func player1Score(value float64, mover game.RPSPlayer) float64 {
+	if mover == game.Player2 {
+		value = 1 - value
+	}
+	return 2*value - 1
+}
@@ -8,9 +8,12 @@ import (
 	"os"
 	"time"
 
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/agents"
 	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/logging"
 	"github.com/zachbeta/neural_rps/alphago_demo/pkg/mcts"
 	neural "github.com/zachbeta/neural_rps/alphago_demo/pkg/rps_net_impl"
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/tournament"
 )
 
 const (
@@ -49,12 +52,8 @@ func (a *AlphaGoAgent) GetMove(state *game.RPSGame) (game.RPSMove, error) {
 	bestNode := a.mctsEngine.Search()
 
 	if bestNode == nil || bestNode.Move == nil {
-		// Fallback to random move if MCTS fails
-		validMoves := state.GetValidMoves()
-		if len(validMoves) == 0 {
-			return game.RPSMove{}, fmt.Errorf("no valid moves")
-		}
-		return validMoves[rand.Intn(len(validMoves))], nil
+		// Fall back to the legal move the policy network rates highest
+		return agents.FallbackMove(state, agents.FallbackPolicyPrior, a.policyNetwork)
 	}
 
 	return *bestNode.Move, nil
@@ -64,6 +63,11 @@ func (a *AlphaGoAgent) Name() string {
 	return a.name
 }
 
+// Seed makes the agent's searches repeatable. Seeded searches run serially.
+func (a *AlphaGoAgent) Seed(seed int64) {
+	a.mctsEngine.Params.Rng = rand.New(rand.NewSource(seed))
+}
+
 func main() {
 	// Define command line flags
 	model1Policy := flag.String("model1-policy", "output/rps_policy1.model", "Path to model 1 policy network file")
@@ -76,10 +80,29 @@ func main() {
 
 	numGames := flag.Int("games", 30, "Number of games to play")
 	verbose := flag.Bool("verbose", false, "Show each move during games")
+	mirror := flag.Bool("mirror", false, "Play every deal twice with the agents swapped to reduce variance")
+	movePositions := flag.Int("moves", 0, "Compare both models' moves on this many sampled positions instead of playing a tournament")
+	minimaxDepth := flag.Int("minimax-depth", 5, "Minimax depth used to judge moves with -moves")
+	seed := flag.Int64("seed", 0, "Seed for deals and searches, making the comparison repeatable (0 seeds from the clock)")
+	logConfig := logging.AddFlags(flag.CommandLine)
 	flag.Parse()
+	if err := logConfig.Install(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
 
-	// Seed random number generator
-	rand.Seed(time.Now().UnixNano())
+	if *mirror && *numGames%2 == 1 {
+		*numGames++
+		fmt.Printf("Mirrored deals are played in pairs, playing %d games\n", *numGames)
+	}
+
+	// Seed random number generator. The searches are only seeded for a chosen
+	// seed, as seeded searches run serially.
+	if *seed != 0 {
+		rand.Seed(*seed)
+	} else {
+		rand.Seed(time.Now().UnixNano())
+	}
 
 	// Load policy networks from files
 	policy1 := neural.NewRPSPolicyNetwork(128)
@@ -115,6 +138,14 @@ func main() {
 	agent1 := NewAlphaGoAgent(*model1Name, policy1, value1)
 	agent2 := NewAlphaGoAgent(*model2Name, policy2, value2)
 
+	var rng *rand.Rand
+	if *seed != 0 {
+		rng = rand.New(rand.NewSource(*seed))
+		agent1.Seed(rng.Int63())
+		agent2.Seed(rng.Int63())
+		fmt.Printf("Playing a reproducible comparison with seed %d\n", *seed)
+	}
+
 	// Display model network complexity comparison
 	fmt.Println("\n=== Model Complexity Comparison ===")
 	fmt.Printf("Model 1: %s\n", agent1.Name())
@@ -144,9 +175,16 @@ func main() {
 	fmt.Printf("\nModel size comparison: Model 2 is %.2fx the size of Model 1\n", sizeRatio)
 	fmt.Println("===================================")
 
+	if *movePositions > 0 {
+		fmt.Printf("\nComparing moves on %d sampled positions (minimax depth %d)...\n", *movePositions, *minimaxDepth)
+		results := compareMoves(agent1, agent2, *movePositions, *minimaxDepth)
+		printMoveComparison(agent1.Name(), agent2.Name(), results)
+		return
+	}
+
 	// Run tournament
 	fmt.Printf("\n=== Starting Tournament (%s vs %s) ===\n", agent1.Name(), agent2.Name())
-	model1Wins, model2Wins, draws := runTournament(agent1, agent2, *numGames, *verbose)
+	model1Wins, model2Wins, draws := runTournament(agent1, agent2, *numGames, *verbose, *mirror, rng)
 
 	// Print results
 	fmt.Println("\n=== Tournament Results ===")
@@ -155,6 +193,14 @@ func main() {
 	fmt.Printf("%s wins: %d (%.1f%%)\n", agent2.Name(), model2Wins, float64(model2Wins)/float64(*numGames)*100)
 	fmt.Printf("Draws: %d (%.1f%%)\n", draws, float64(draws)/float64(*numGames)*100)
 
+	// The interval is wide for small matches; only a result that excludes an
+	// even score should be read as one model being stronger
+	estimate := tournament.EstimateScore(model1Wins, model2Wins, draws)
+	fmt.Printf("%s %s\n", agent1.Name(), estimate)
+	if !estimate.Significant() {
+		fmt.Println("The 95% interval includes an even score, so this result is not conclusive.")
+	}
+
 	if model2Wins > model1Wins {
 		fmt.Printf("\n%s outperformed %s!\n", agent2.Name(), agent1.Name())
 	} else if model1Wins > model2Wins {
@@ -164,11 +210,12 @@ func main() {
 	}
 
 	// Save results to file
-	resultStr := fmt.Sprintf("Tournament: %s vs %s\nGames: %d\n%s wins: %d (%.1f%%)\n%s wins: %d (%.1f%%)\nDraws: %d (%.1f%%)\n",
+	resultStr := fmt.Sprintf("Tournament: %s vs %s\nGames: %d\n%s wins: %d (%.1f%%)\n%s wins: %d (%.1f%%)\nDraws: %d (%.1f%%)\n%s %s\n",
 		agent1.Name(), agent2.Name(), *numGames,
 		agent1.Name(), model1Wins, float64(model1Wins)/float64(*numGames)*100,
 		agent2.Name(), model2Wins, float64(model2Wins)/float64(*numGames)*100,
-		draws, float64(draws)/float64(*numGames)*100)
+		draws, float64(draws)/float64(*numGames)*100,
+		agent1.Name(), estimate)
 
 	// Create results directory if it doesn't exist
 	os.MkdirAll("results", 0755)
@@ -183,84 +230,40 @@ func main() {
 	}
 }
 
-// runTournament runs a tournament between two agents
-func runTournament(agent1, agent2 *AlphaGoAgent, numGames int, verbose bool) (agent1Wins, agent2Wins, draws int) {
-	for i := 0; i < numGames; i++ {
-		// Print progress
-		if (i+1)%10 == 0 || i == 0 {
-			fmt.Printf("Playing game %d of %d...\n", i+1, numGames)
-		}
-
-		// Create a new game
-		gameInstance := game.NewRPSGame(deckSize, handSize, maxRounds)
-
-		// Alternate who goes first to ensure fairness
-		var player1Agent, player2Agent *AlphaGoAgent
-		if i%2 == 0 {
-			player1Agent = agent1
-			player2Agent = agent2
-		} else {
-			player1Agent = agent2
-			player2Agent = agent1
-		}
-
-		// Play the game
-		for !gameInstance.IsGameOver() {
-			var currentAgent *AlphaGoAgent
-			if gameInstance.CurrentPlayer == game.Player1 {
-				currentAgent = player1Agent
-			} else {
-				currentAgent = player2Agent
-			}
-
-			move, err := currentAgent.GetMove(gameInstance.Copy())
-			if err != nil {
-				log.Fatalf("Agent %s failed to make a move: %v", currentAgent.Name(), err)
-			}
-
-			move.Player = gameInstance.CurrentPlayer
-			err = gameInstance.MakeMove(move)
-			if err != nil {
-				log.Fatalf("Invalid move from agent %s: %v", currentAgent.Name(), err)
-			}
-
-			if verbose {
-				fmt.Printf("Agent %s plays card %d at position %d\n",
-					currentAgent.Name(), move.CardIndex, move.Position)
-				fmt.Println(gameInstance.String())
+// runTournament runs a tournament between two agents, alternating who goes first.
+// With mirror set, each deal is played once with each agent going first. Games
+// are dealt from rng when it is not nil.
+func runTournament(agent1, agent2 *AlphaGoAgent, numGames int, verbose, mirror bool, rng *rand.Rand) (agent1Wins, agent2Wins, draws int) {
+	opts := tournament.DefaultSeriesOptions()
+	opts.DeckSize = deckSize
+	opts.HandSize = handSize
+	opts.MaxRounds = maxRounds
+	opts.MirrorDeals = mirror
+	opts.Rng = rng
+
+	opts.OnGame = func(gameNumber int, result tournament.SeriesGame) {
+		if gameNumber%10 == 0 {
+			winnerName := result.Winner
+			if winnerName == tournament.DrawResult {
+				winnerName = "Draw"
 			}
+			fmt.Printf("Game %d result: %s\n", gameNumber, winnerName)
 		}
-
-		// Determine winner
-		winner := gameInstance.GetWinner()
-		var winnerName string
-
-		if winner == game.Player1 {
-			if player1Agent == agent1 {
-				agent1Wins++
-				winnerName = agent1.Name()
-			} else {
-				agent2Wins++
-				winnerName = agent2.Name()
-			}
-		} else if winner == game.Player2 {
-			if player2Agent == agent1 {
-				agent1Wins++
-				winnerName = agent1.Name()
-			} else {
-				agent2Wins++
-				winnerName = agent2.Name()
-			}
-		} else {
-			draws++
-			winnerName = "Draw"
+		// Print progress
+		if (gameNumber+1)%10 == 0 && gameNumber < numGames {
+			fmt.Printf("Playing game %d of %d...\n", gameNumber+1, numGames)
 		}
-
-		// Print result for every 10th game
-		if (i+1)%10 == 0 {
-			fmt.Printf("Game %d result: %s\n", i+1, winnerName)
+	}
+	if verbose {
+		opts.OnMove = func(agent agents.Agent, move game.RPSMove, state *game.RPSGame) {
+			fmt.Printf("Agent %s plays card %d at position %d\n",
+				agent.Name(), move.CardIndex, move.Position)
+			fmt.Println(state.String())
 		}
 	}
 
-	return agent1Wins, agent2Wins, draws
+	fmt.Printf("Playing game 1 of %d...\n", numGames)
+	result := tournament.PlaySeries(agent1, agent2, numGames, opts)
+
+	return result.WinsA, result.WinsB, result.Draws
 }
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/analysis"
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
+)
+
+// gamePhases lists the phases reported by compareMoves, in game order
+var gamePhases = []string{"Opening", "Midgame", "Endgame"}
+
+// phaseComparison tallies move comparisons for one game phase
+type phaseComparison struct {
+	Positions   int
+	Agreements  int
+	Model1Wins  int // Positions where model 1's move scored better under minimax
+	Model2Wins  int
+	EqualValues int // Positions where the moves differed but scored the same
+}
+
+// compareMoves samples positions from random playouts and compares the moves
+// both agents choose in each one, using minimax at the given depth to decide
+// which move is better when they disagree
+func compareMoves(agent1, agent2 *AlphaGoAgent, numPositions, depth int) map[string]*phaseComparison {
+	results := make(map[string]*phaseComparison)
+	for _, phase := range gamePhases {
+		results[phase] = &phaseComparison{}
+	}
+
+	minimaxEngine := analysis.NewMinimaxEngine(depth, analysis.StandardEvaluator)
+
+	for sampled := 0; sampled < numPositions; {
+		g := samplePosition()
+		if g.IsGameOver() {
+			continue
+		}
+
+		move1, err1 := agent1.GetMove(g.Copy())
+		move2, err2 := agent2.GetMove(g.Copy())
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		sampled++
+
+		stats := results[getGamePhase(g)]
+		stats.Positions++
+
+		if move1.CardIndex == move2.CardIndex && move1.Position == move2.Position {
+			stats.Agreements++
+		} else {
+			scores := minimaxEngine.ScoreMoves(g)
+			score1, ok1 := analysis.FindMoveScore(scores, move1)
+			score2, ok2 := analysis.FindMoveScore(scores, move2)
+			switch {
+			case !ok1 || !ok2:
+				// An illegal move cannot be graded
+			case score1.Gap < score2.Gap:
+				stats.Model1Wins++
+			case score2.Gap < score1.Gap:
+				stats.Model2Wins++
+			default:
+				stats.EqualValues++
+			}
+		}
+
+		if sampled%20 == 0 {
+			fmt.Printf("Compared %d/%d positions...\n", sampled, numPositions)
+		}
+	}
+
+	return results
+}
+
+// samplePosition plays a random number of random moves from a new deal, so
+// positions from every phase of the game are sampled
+func samplePosition() *game.RPSGame {
+	g := game.NewRPSGame(deckSize, handSize, maxRounds)
+	randomMoves := rand.Intn(9)
+	for j := 0; j < randomMoves && !g.IsGameOver(); j++ {
+		moves := g.GetValidMoves()
+		g.MakeMove(moves[rand.Intn(len(moves))])
+	}
+	return g
+}
+
+// getGamePhase determines the current phase of the game
+func getGamePhase(g *game.RPSGame) string {
+	// Count cards on board
+	cardsOnBoard := 0
+	for _, card := range g.Board {
+		if card.Owner != game.NoPlayer {
+			cardsOnBoard++
+		}
+	}
+
+	if cardsOnBoard <= 2 {
+		return "Opening"
+	} else if cardsOnBoard >= 7 {
+		return "Endgame"
+	} else {
+		return "Midgame"
+	}
+}
+
+// printMoveComparison prints the agreement rate and which model played the
+// better move, overall and for each game phase
+func printMoveComparison(name1, name2 string, results map[string]*phaseComparison) {
+	var total phaseComparison
+
+	fmt.Println("\n=== Move-by-Move Comparison ===")
+	fmt.Printf("%-8s %9s %10s %14s %14s %7s\n", "Phase", "Positions", "Agreement",
+		name1+" better", name2+" better", "Equal")
+	for _, phase := range gamePhases {
+		stats := results[phase]
+		printPhaseRow(phase, stats)
+
+		total.Positions += stats.Positions
+		total.Agreements += stats.Agreements
+		total.Model1Wins += stats.Model1Wins
+		total.Model2Wins += stats.Model2Wins
+		total.EqualValues += stats.EqualValues
+	}
+	printPhaseRow("Total", &total)
+}
+
+// printPhaseRow prints one row of the move comparison table
+func printPhaseRow(label string, stats *phaseComparison) {
+	agreement := 0.0
+	if stats.Positions > 0 {
+		agreement = float64(stats.Agreements) / float64(stats.Positions) * 100
+	}
+	fmt.Printf("%-8s %9d %9.1f%% %14d %14d %7d\n", label, stats.Positions, agreement,
+		stats.Model1Wins, stats.Model2Wins, stats.EqualValues)
+}
@@ -5,6 +5,7 @@ import (
 	"math/rand"
 	"time"
 
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/agents"
 	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
 	"github.com/zachbeta/neural_rps/alphago_demo/pkg/mcts"
 	neural "github.com/zachbeta/neural_rps/alphago_demo/pkg/rps_net_impl"
@@ -33,7 +34,7 @@ func main() {
 	// Seed random number generator
 	rand.Seed(time.Now().UnixNano())
 
-	fmt.Println("RPS Card Game Debug - AI vs AI with Winner Determination Check")
+	fmt.Println("RPS Card Game Debug - AI vs AI")
 	fmt.Println("===========================================================")
 
 	// Initialize neural networks
@@ -126,26 +127,26 @@ func runAIvsAIGame(policyNetwork *neural.RPSPolicyNetwork, valueNetwork *neural.
 			col := move.Position % 3
 			fmt.Printf("%s plays %s at position (%d,%d)\n", playerName, cardTypeStr, row, col)
 		} else {
-			// Use random move if MCTS fails
-			randomMove, err := gameInstance.GetRandomMove()
+			// Fall back to the policy network's top legal move if MCTS fails
+			fallbackMove, err := agents.FallbackMove(gameInstance, agents.FallbackPolicyPrior, mctsEngine.PolicyNetwork)
 			if err != nil {
 				fmt.Printf("No valid moves: %v\n", err)
 				break
 			}
 
-			err = gameInstance.MakeMove(randomMove)
+			err = gameInstance.MakeMove(fallbackMove)
 			if err != nil {
-				fmt.Printf("Error making random move: %v\n", err)
+				fmt.Printf("Error making fallback move: %v\n", err)
 				return
 			}
 
 			// Display the move
 			playerName := "Player 1"
-			if randomMove.Player == game.Player2 {
+			if fallbackMove.Player == game.Player2 {
 				playerName = "Player 2"
 			}
 
-			cardType := gameInstance.Board[randomMove.Position].Type
+			cardType := gameInstance.Board[fallbackMove.Position].Type
 			var cardTypeStr string
 			switch cardType {
 			case game.Rock:
@@ -156,9 +157,9 @@ func runAIvsAIGame(policyNetwork *neural.RPSPolicyNetwork, valueNetwork *neural.
 				cardTypeStr = "Scissors"
 			}
 
-			row := randomMove.Position / 3
-			col := randomMove.Position % 3
-			fmt.Printf("%s plays %s at position (%d,%d) (random)\n", playerName, cardTypeStr, row, col)
+			row := fallbackMove.Position / 3
+			col := fallbackMove.Position % 3
+			fmt.Printf("%s plays %s at position (%d,%d) (fallback)\n", playerName, cardTypeStr, row, col)
 		}
 
 		// Display game state after each move
@@ -174,22 +175,11 @@ func runAIvsAIGame(policyNetwork *neural.RPSPolicyNetwork, valueNetwork *neural.
 	fmt.Println("\n*** GAME OVER ***")
 	fmt.Println(gameInstance.String())
 
-	// Manual card count for verification
-	var player1Count, player2Count int
-	for _, card := range gameInstance.Board {
-		if card.Owner == game.Player1 {
-			player1Count++
-		} else if card.Owner == game.Player2 {
-			player2Count++
-		}
-	}
-
-	fmt.Printf("\nFinal Manual Card Count Verification:\n")
-	fmt.Printf("Player 1: %d cards\n", player1Count)
-	fmt.Printf("Player 2: %d cards\n", player2Count)
+	player1Score, player2Score := gameInstance.Score()
+	fmt.Printf("\nFinal score: Player 1 %d, Player 2 %d\n", player1Score, player2Score)
 
 	winner := gameInstance.GetWinner()
-	fmt.Printf("\nWinner Determination Logic Result: ")
+	fmt.Printf("Result: ")
 	if winner == game.NoPlayer {
 		fmt.Println("Draw")
 	} else if winner == game.Player1 {
@@ -197,15 +187,4 @@ func runAIvsAIGame(policyNetwork *neural.RPSPolicyNetwork, valueNetwork *neural.
 	} else {
 		fmt.Println("Player 2 wins")
 	}
-
-	// Verify winner determination is correct
-	if player1Count > player2Count && winner != game.Player1 {
-		fmt.Printf("ERROR: Player 1 has more cards but is not declared the winner!\n")
-	} else if player2Count > player1Count && winner != game.Player2 {
-		fmt.Printf("ERROR: Player 2 has more cards but is not declared the winner!\n")
-	} else if player1Count == player2Count && winner != game.NoPlayer {
-		fmt.Printf("ERROR: Card count is tied but a winner was declared!\n")
-	} else {
-		fmt.Println("Winner determination is correct!")
-	}
 }
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	neural "github.com/zachbeta/neural_rps/alphago_demo/pkg/rps_net_impl"
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/tournament"
+)
+
+func TestCorruptModelIsSkipped(t *testing.T) {
+	dir := t.TempDir()
+	if err := neural.NewRPSPolicyNetwork(16).SaveToFile(filepath.Join(dir, "rps_hgood_policy.model")); err != nil {
+		t.Fatalf("Failed to save policy network: %v", err)
+	}
+	if err := neural.NewRPSValueNetwork(16).SaveToFile(filepath.Join(dir, "rps_hgood_value.model")); err != nil {
+		t.Fatalf("Failed to save value network: %v", err)
+	}
+	if err := neural.NewRPSValueNetwork(16).SaveToFile(filepath.Join(dir, "rps_hbad_value.model")); err != nil {
+		t.Fatalf("Failed to save value network: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "rps_hbad_policy.model"), []byte("not a model"), 0644); err != nil {
+		t.Fatalf("Failed to write corrupt model: %v", err)
+	}
+
+	tm := tournament.NewTournamentManager(false)
+	tm.AddAgent(NewRandomAgent("Random"))
+	added, failures := addModelAgents(tm, []string{dir}, "rps_h", "AlphaGo-")
+
+	if len(added) != 1 || added[0].Name() != "AlphaGo-good" {
+		t.Fatalf("Expected only AlphaGo-good to be added, got %d agents", len(added))
+	}
+	if len(failures) != 1 || failures[0].Agent != "AlphaGo-bad" || failures[0].OK() {
+		t.Fatalf("Expected AlphaGo-bad to be reported as failing to load, got %+v", failures)
+	}
+
+	// The tournament runs with the agents that loaded
+	tm.RunTournament(2, 0)
+	record := tm.GameResults["Random"]["AlphaGo-good"]
+	if played := record.Wins + record.Losses + record.Draws; played != 2 {
+		t.Errorf("Expected Random to play AlphaGo-good twice, got %d games", played)
+	}
+}
@@ -6,38 +6,17 @@ import (
 	"math/rand"
 	"time"
 
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/agents"
 	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
 	neural "github.com/zachbeta/neural_rps/alphago_demo/pkg/rps_net_impl"
 )
 
-// Agent interface for all game-playing agents
-type Agent interface {
-	GetMove(state *game.RPSGame) (game.RPSMove, error)
-	Name() string
-}
-
-// RandomAgent makes random valid moves
-type RandomAgent struct {
-	name string
-}
+// Agent is the shared interface for all game-playing agents
+type Agent = agents.Agent
 
 // NewRandomAgent creates an agent that makes random moves
 func NewRandomAgent(name string) Agent {
-	return &RandomAgent{name: name}
-}
-
-// GetMove returns a random valid move
-func (a *RandomAgent) GetMove(state *game.RPSGame) (game.RPSMove, error) {
-	validMoves := state.GetValidMoves()
-	if len(validMoves) == 0 {
-		return game.RPSMove{}, fmt.Errorf("no valid moves")
-	}
-	return validMoves[rand.Intn(len(validMoves))], nil
-}
-
-// Name returns the agent's name
-func (a *RandomAgent) Name() string {
-	return a.name
+	return agents.NewRandomAgent(name)
 }
 
 // MinimaxAgent implements the minimax algorithm for RPS
@@ -1,7 +1,6 @@
 package main
 
 import (
-	"encoding/json"
 	"flag"
 	"fmt"
 	"math/rand"
@@ -9,28 +8,39 @@ import (
 	"time"
 
 	"github.com/zachbeta/neural_rps/alphago_demo/pkg/agents"
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/analysis"
 	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/training"
 )
 
-type TrainingExample struct {
-	BoardState    []int   `json:"board_state"`    // Flattened board (9 positions)
-	Player1Hand   []int   `json:"player1_hand"`   // Card types in P1's hand
-	Player2Hand   []int   `json:"player2_hand"`   // Card types in P2's hand
-	CurrentPlayer int     `json:"current_player"` // 1 or 2
-	BestMove      int     `json:"best_move"`      // 0-8 position index
-	Evaluation    float64 `json:"evaluation"`     // Minimax evaluation
-	GamePhase     string  `json:"game_phase"`     // "opening", "midgame", "endgame"
-	SearchDepth   int     `json:"search_depth"`   // Depth used for this position
-}
+// examplesPerWrite is how many examples are held before being appended to the output
+const examplesPerWrite = 100
 
 func main() {
 	// Parse command line flags
 	numPositions := flag.Int("positions", 10000, "Number of positions to generate")
 	minimaxDepth := flag.Int("depth", 5, "Minimax search depth")
-	outputFile := flag.String("output", "training_data.json", "Output file path")
+	outputFile := flag.String("output", "training_data.jsonl", "Output file path")
 	timeLimit := flag.Duration("time-limit", 5*time.Second, "Time limit per move")
+	playoutDepth := flag.Int("playout-depth", 3, "Minimax depth used to play each position out for its value target")
+	phaseMix := flag.String("phase-mix", "", "Target share of each game phase, e.g. opening=1,midgame=1,endgame=1 (default: unbalanced)")
+	stability := flag.Bool("stability", false, "Also label each position with the best move at depths depth-1 to depth+1 and whether they agree")
+	appendOutput := flag.Bool("append", false, "Add to an existing output file instead of replacing it")
+	featuresDir := flag.String("features-dir", "", "Also write train_supervised's training_* and validation_* feature files to this directory")
+	valSplit := flag.Float64("val-split", 0.1, "Proportion of positions held out for validation with -features-dir")
+	splitSeed := flag.Int64("split-seed", 1, "Seed for the training/validation split with -features-dir")
 	flag.Parse()
 
+	var balancer *training.PhaseBalancer
+	if *phaseMix != "" {
+		weights, err := training.ParsePhaseMix(*phaseMix)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		balancer = training.NewPhaseBalancer(*numPositions, weights)
+	}
+
 	// Seed random number generator
 	rand.Seed(time.Now().UnixNano())
 
@@ -38,12 +48,12 @@ func main() {
 	os.MkdirAll("data", 0755)
 	outputPath := fmt.Sprintf("data/%s", *outputFile)
 
-	// Open output file
-	file, err := os.Create(outputPath)
-	if err != nil {
-		panic(fmt.Sprintf("Failed to create output file: %v", err))
+	// Start a new dataset unless adding to an existing one
+	if !*appendOutput {
+		if err := os.Remove(outputPath); err != nil && !os.IsNotExist(err) {
+			panic(fmt.Sprintf("Failed to replace output file: %v", err))
+		}
 	}
-	defer file.Close()
 
 	// Create a minimax agent with specified depth and caching enabled
 	minimaxAgent := agents.NewMinimaxAgent(
@@ -53,6 +63,24 @@ func main() {
 		true, // Enable caching
 	)
 
+	// A shallower search plays each position to the end to find its result
+	playoutAgent := agents.NewMinimaxAgent(
+		fmt.Sprintf("Minimax-%d", *playoutDepth),
+		*playoutDepth,
+		*timeLimit,
+		true,
+	)
+
+	// Searches each position again at neighbouring depths for -stability
+	labelEngine := analysis.NewMinimaxEngine(*minimaxDepth+1, analysis.StandardEvaluator)
+	labelEngine.MaxTime = *timeLimit
+	labelEngine.EnableTranspositionTable()
+	minLabelDepth := *minimaxDepth - 1
+	if minLabelDepth < 1 {
+		minLabelDepth = 1
+	}
+	unstable := 0
+
 	// Statistics tracking
 	startTime := time.Now()
 	totalPositions := *numPositions
@@ -63,8 +91,14 @@ func main() {
 	handSize := 5
 	maxRounds := 10
 
-	// Array to hold all examples
-	examples := make([]TrainingExample, 0, totalPositions)
+	// Examples not yet written to the output file
+	examples := make([]training.TrainingExample, 0, examplesPerWrite)
+
+	// Every example, kept for -features-dir
+	var allExamples []training.TrainingExample
+
+	// Positions generated in each game phase
+	phaseCounts := make(map[string]int)
 
 	fmt.Printf("Generating %d training examples using Minimax-%d...\n",
 		totalPositions, *minimaxDepth)
@@ -73,8 +107,18 @@ func main() {
 		// Create a new game
 		g := game.NewRPSGame(deckSize, handSize, maxRounds)
 
-		// Play a few random moves to get diverse positions
-		playRandomMoves(g, 0, 4) // 0-4 random moves
+		if balancer != nil {
+			// Play into the phase furthest short of its share, and drop
+			// positions that captures or an early finish put elsewhere
+			minMoves, maxMoves := training.PhaseCardRange(balancer.Next())
+			playRandomMoves(g, minMoves, maxMoves)
+			if g.IsGameOver() || !balancer.Wants(training.GamePhase(g)) {
+				continue
+			}
+		} else {
+			// Play a few random moves to get diverse positions
+			playRandomMoves(g, 0, 4) // 0-4 random moves
+		}
 
 		if g.IsGameOver() {
 			continue // Skip completed games
@@ -88,11 +132,38 @@ func main() {
 		}
 
 		// Create training example
-		example := createTrainingExample(g, move, *minimaxDepth)
+		example := training.NewTrainingExample(g, move, *minimaxDepth)
+		outcome, err := training.PlayOut(g, playoutAgent.GetMove)
+		if err != nil {
+			fmt.Printf("Error playing out position: %v\n", err)
+			continue
+		}
+		example.ValueTarget = training.OutcomeValueTarget(outcome)
+		if *stability {
+			example.SetDepthMoves(minLabelDepth,
+				labelEngine.BestMovesByDepth(g.Copy(), minLabelDepth, *minimaxDepth+1))
+			if !*example.Stable {
+				unstable++
+			}
+		}
 		examples = append(examples, example)
+		phaseCounts[example.GamePhase]++
+		if balancer != nil {
+			balancer.Add(example.GamePhase)
+		}
+		if *featuresDir != "" {
+			allExamples = append(allExamples, example)
+		}
 
 		positionsGenerated++
 
+		if len(examples) == examplesPerWrite || positionsGenerated == totalPositions {
+			if err := training.AppendExamples(outputPath, examples); err != nil {
+				panic(fmt.Sprintf("Failed to write training data: %v", err))
+			}
+			examples = examples[:0]
+		}
+
 		// Status update every 100 positions
 		if positionsGenerated%100 == 0 {
 			elapsed := time.Since(startTime)
@@ -102,121 +173,63 @@ func main() {
 		}
 	}
 
-	// Write data to file
-	encoder := json.NewEncoder(file)
-	if err := encoder.Encode(examples); err != nil {
-		panic(fmt.Sprintf("Failed to write training data: %v", err))
-	}
-
 	elapsed := time.Since(startTime)
 	fmt.Printf("\nCompleted! Generated %d positions in %v (%.2f pos/sec)\n",
 		positionsGenerated, elapsed, float64(positionsGenerated)/elapsed.Seconds())
 	fmt.Printf("Training data saved to %s\n", outputPath)
-}
 
-// playRandomMoves plays a random number of moves between min and max
-func playRandomMoves(g *game.RPSGame, min, max int) {
-	numMoves := min + rand.Intn(max-min+1)
+	if *stability {
+		fmt.Printf("Unstable positions (best move changes between depths %d and %d): %d (%.1f%%)\n",
+			minLabelDepth, *minimaxDepth+1, unstable, float64(unstable)/float64(positionsGenerated)*100)
+	}
 
-	for i := 0; i < numMoves; i++ {
-		moves := g.GetValidMoves()
-		if len(moves) == 0 || g.IsGameOver() {
-			return
+	fmt.Println("\nPositions by game phase:")
+	for _, phase := range training.Phases {
+		line := fmt.Sprintf("  %-8s %6d (%5.1f%%)", phase, phaseCounts[phase],
+			float64(phaseCounts[phase])/float64(positionsGenerated)*100)
+		if balancer != nil {
+			line += fmt.Sprintf(", target %d", balancer.Quota(phase))
 		}
+		fmt.Println(line)
+	}
 
-		move := moves[rand.Intn(len(moves))]
-		g.MakeMove(move)
+	if *featuresDir != "" {
+		writeFeatureSets(*featuresDir, allExamples, *valSplit, *splitSeed)
 	}
 }
 
-// createTrainingExample converts a game state and minimax move to a training example
-func createTrainingExample(g *game.RPSGame, move game.RPSMove, depth int) TrainingExample {
-	// Create board state representation (flattened)
-	boardState := make([]int, 9)
-	for i, card := range g.Board {
-		if card.Owner == game.NoPlayer {
-			boardState[i] = 0 // Empty
-		} else if card.Owner == game.Player1 {
-			// Encode Player 1's cards as 1, 2, 3
-			switch card.Type {
-			case game.Rock:
-				boardState[i] = 1
-			case game.Paper:
-				boardState[i] = 2
-			case game.Scissors:
-				boardState[i] = 3
-			}
-		} else {
-			// Encode Player 2's cards as 4, 5, 6
-			switch card.Type {
-			case game.Rock:
-				boardState[i] = 4
-			case game.Paper:
-				boardState[i] = 5
-			case game.Scissors:
-				boardState[i] = 6
-			}
-		}
+// writeFeatureSets splits the examples as split_dataset does and saves them as
+// training and validation sets that train_supervised can load directly
+func writeFeatureSets(dir string, examples []training.TrainingExample, valSplit float64, seed int64) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		panic(fmt.Sprintf("Failed to create features directory: %v", err))
 	}
 
-	// Create hand representations
-	p1Hand := encodeHand(g.Player1Hand)
-	p2Hand := encodeHand(g.Player2Hand)
-
-	// Determine game phase
-	phase := getGamePhase(g)
+	train, val := training.SplitDataset(examples, valSplit, seed)
+	trainInputs, trainTargets := training.ExampleFeatures(train)
+	valInputs, valTargets := training.ExampleFeatures(val)
 
-	// Current player (1 or 2)
-	currentPlayer := 1
-	if g.CurrentPlayer == game.Player2 {
-		currentPlayer = 2
+	if err := training.SaveFeatureSet(dir, "training", trainInputs, trainTargets); err != nil {
+		panic(fmt.Sprintf("Failed to write training features: %v", err))
 	}
-
-	return TrainingExample{
-		BoardState:    boardState,
-		Player1Hand:   p1Hand,
-		Player2Hand:   p2Hand,
-		CurrentPlayer: currentPlayer,
-		BestMove:      move.Position, // 0-8 position index
-		Evaluation:    0.0,           // Fixed - we'll need to update the MinimaxAgent to expose this
-		GamePhase:     phase,
-		SearchDepth:   depth,
+	if err := training.SaveFeatureSet(dir, "validation", valInputs, valTargets); err != nil {
+		panic(fmt.Sprintf("Failed to write validation features: %v", err))
 	}
+	fmt.Printf("Feature files for train_supervised (%d training, %d validation) saved to %s\n",
+		len(train), len(val), dir)
 }
 
-// encodeHand converts a slice of cards to counts of each type
-func encodeHand(hand []game.RPSCard) []int {
-	counts := make([]int, 3) // Rock, Paper, Scissors
-
-	for _, card := range hand {
-		switch card.Type {
-		case game.Rock:
-			counts[0]++
-		case game.Paper:
-			counts[1]++
-		case game.Scissors:
-			counts[2]++
-		}
-	}
-
-	return counts
-}
+// playRandomMoves plays a random number of moves between min and max
+func playRandomMoves(g *game.RPSGame, min, max int) {
+	numMoves := min + rand.Intn(max-min+1)
 
-// getGamePhase determines the current phase of the game
-func getGamePhase(g *game.RPSGame) string {
-	// Count cards on board
-	cardsOnBoard := 0
-	for _, card := range g.Board {
-		if card.Owner != game.NoPlayer {
-			cardsOnBoard++
+	for i := 0; i < numMoves; i++ {
+		moves := g.GetValidMoves()
+		if len(moves) == 0 || g.IsGameOver() {
+			return
 		}
-	}
 
-	if cardsOnBoard <= 2 {
-		return "opening"
-	} else if cardsOnBoard >= 7 {
-		return "endgame"
-	} else {
-		return "midgame"
+		move := moves[rand.Intn(len(moves))]
+		g.MakeMove(move)
 	}
 }
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/mcts"
+)
+
+// evaluateRequest is the body of POST /v1/evaluate. Exactly one of Notation
+// and Position describes the position to evaluate.
+type evaluateRequest struct {
+	// Notation is a position as written by RPSGame.Notation, for example
+	// "R.s/.P./... RPS ss 2 3"
+	Notation string `json:"notation,omitempty"`
+
+	// Position is the same position spelled out as JSON
+	Position *positionJSON `json:"position,omitempty"`
+
+	// MaxRounds is the round limit for a position given in notation, which
+	// does not record it. Zero uses the standard limit.
+	MaxRounds int `json:"maxRounds,omitempty"`
+
+	// Simulations is the number of MCTS simulations to run. Zero uses the
+	// server's default; requests above the server's cap are refused.
+	Simulations int `json:"simulations,omitempty"`
+
+	// Seed, when set, makes the search reproducible. Unseeded searches run in
+	// parallel and vary from request to request.
+	Seed *int64 `json:"seed,omitempty"`
+}
+
+// positionJSON is a position in JSON form
+type positionJSON struct {
+	Board         []*cardJSON `json:"board"` // Row by row; null for an empty square
+	Player1Hand   []string    `json:"player1Hand"`
+	Player2Hand   []string    `json:"player2Hand"`
+	CurrentPlayer int         `json:"currentPlayer"` // 1 or 2
+	Round         int         `json:"round"`
+	MaxRounds     int         `json:"maxRounds,omitempty"` // Zero uses the standard limit
+}
+
+// cardJSON is a card on the board
+type cardJSON struct {
+	Type  string `json:"type"`  // "rock", "paper" or "scissors"
+	Owner int    `json:"owner"` // 1 or 2
+}
+
+// evaluateResponse is the reply to POST /v1/evaluate
+type evaluateResponse struct {
+	Notation      string `json:"notation"`
+	CurrentPlayer int    `json:"currentPlayer"`
+	GameOver      bool   `json:"gameOver"`
+	Winner        int    `json:"winner"` // Set once the game is over; 0 for a draw
+
+	// Policy is the policy network's probability for each square, restricted
+	// to the squares the player to move can play on
+	Policy []float64 `json:"policy"`
+
+	// Value is the value network's estimate that the player to move wins,
+	// from 0 to 1
+	Value float64 `json:"value"`
+
+	// SearchValue is the search's estimate of the same, which is more
+	// reliable than the network's alone. It is absent once the game is over.
+	SearchValue *float64 `json:"searchValue,omitempty"`
+
+	// Move is the move the search recommends, and Candidates the moves it
+	// spent most of its simulations on. Both are absent once the game is over.
+	Move        *candidateJSON  `json:"move,omitempty"`
+	Candidates  []candidateJSON `json:"candidates,omitempty"`
+	Simulations int             `json:"simulations"`
+}
+
+// candidateJSON is a move the search considered
+type candidateJSON struct {
+	CardIndex int     `json:"cardIndex"` // Index into the mover's hand
+	Card      string  `json:"card"`
+	Position  int     `json:"position"`
+	Visits    int     `json:"visits"`
+	Share     float64 `json:"share"` // Fraction of the root's visits
+	Prior     float64 `json:"prior"`
+	Value     float64 `json:"value"` // Search value for the mover, 0 to 1
+}
+
+// errorResponse is the body of every failed request
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// position builds the game state a request describes
+func (req *evaluateRequest) position() (*game.RPSGame, error) {
+	switch {
+	case req.Notation != "" && req.Position != nil:
+		return nil, errors.New("give either notation or position, not both")
+	case req.Notation != "":
+		return game.ParseNotation(req.Notation, roundLimit(req.MaxRounds))
+	case req.Position != nil:
+		return req.Position.game()
+	default:
+		return nil, errors.New("a notation or position is required")
+	}
+}
+
+// game converts a JSON position to a game state
+func (p *positionJSON) game() (*game.RPSGame, error) {
+	dim := 0
+	for dim*dim < len(p.Board) {
+		dim++
+	}
+	if dim*dim != len(p.Board) || dim < game.MinBoardDim || dim > game.MaxBoardDim {
+		return nil, fmt.Errorf("a board of %d squares is not square or is the wrong size", len(p.Board))
+	}
+
+	g := game.NewRPSGameSized(dim, 0, 0, roundLimit(p.MaxRounds), nil)
+	for pos, card := range p.Board {
+		if card == nil {
+			continue
+		}
+		cardType, err := parseCardName(card.Type)
+		if err != nil {
+			return nil, fmt.Errorf("square %d: %v", pos, err)
+		}
+		owner, err := parsePlayer(card.Owner)
+		if err != nil {
+			return nil, fmt.Errorf("square %d: %v", pos, err)
+		}
+		g.Board[pos] = game.RPSCard{Type: cardType, Owner: owner}
+	}
+
+	var err error
+	if g.Player1Hand, err = parseHand(p.Player1Hand); err != nil {
+		return nil, fmt.Errorf("player 1 hand: %v", err)
+	}
+	if g.Player2Hand, err = parseHand(p.Player2Hand); err != nil {
+		return nil, fmt.Errorf("player 2 hand: %v", err)
+	}
+	if g.CurrentPlayer, err = parsePlayer(p.CurrentPlayer); err != nil {
+		return nil, fmt.Errorf("current player: %v", err)
+	}
+	if p.Round < 1 {
+		return nil, fmt.Errorf("invalid round %d", p.Round)
+	}
+	g.Round = p.Round
+
+	g.RecomputeHash()
+	return g, nil
+}
+
+// roundLimit returns maxRounds, or the standard limit if it is not set
+func roundLimit(maxRounds int) int {
+	if maxRounds > 0 {
+		return maxRounds
+	}
+	return defaultMaxRounds
+}
+
+// parseHand converts a list of card names to a hand
+func parseHand(names []string) ([]game.RPSCard, error) {
+	hand := make([]game.RPSCard, len(names))
+	for i, name := range names {
+		cardType, err := parseCardName(name)
+		if err != nil {
+			return nil, err
+		}
+		hand[i] = game.RPSCard{Type: cardType}
+	}
+	return hand, nil
+}
+
+// parsePlayer converts 1 or 2 to a player
+func parsePlayer(player int) (game.RPSPlayer, error) {
+	switch player {
+	case 1:
+		return game.Player1, nil
+	case 2:
+		return game.Player2, nil
+	}
+	return game.NoPlayer, fmt.Errorf("player must be 1 or 2, got %d", player)
+}
+
+// parseCardName converts "rock", "paper" or "scissors" to a card type
+func parseCardName(name string) (game.RPSCardType, error) {
+	switch name {
+	case "rock":
+		return game.Rock, nil
+	case "paper":
+		return game.Paper, nil
+	case "scissors":
+		return game.Scissors, nil
+	}
+	return 0, fmt.Errorf("unknown card %q (want rock, paper or scissors)", name)
+}
+
+// cardName returns "rock", "paper" or "scissors"
+func cardName(cardType game.RPSCardType) string {
+	switch cardType {
+	case game.Rock:
+		return "rock"
+	case game.Paper:
+		return "paper"
+	case game.Scissors:
+		return "scissors"
+	default:
+		return "unknown"
+	}
+}
+
+// newCandidate converts a candidate move from a search report
+func newCandidate(c mcts.CandidateMove) candidateJSON {
+	return candidateJSON{
+		CardIndex: c.Move.CardIndex,
+		Card:      cardName(c.CardType),
+		Position:  c.Move.Position,
+		Visits:    c.Visits,
+		Share:     c.Share,
+		Prior:     c.Prior,
+		Value:     c.Value,
+	}
+}
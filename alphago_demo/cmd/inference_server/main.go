@@ -0,0 +1,187 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/mcts"
+	neural "github.com/zachbeta/neural_rps/alphago_demo/pkg/rps_net_impl"
+)
+
+const (
+	// defaultMaxRounds is the round limit of positions that do not give one
+	defaultMaxRounds = 10
+
+	// candidateCount is how many of the search's moves a response lists
+	candidateCount = 5
+
+	// maxRequestBytes bounds the size of a request body
+	maxRequestBytes = 64 << 10
+)
+
+// server answers evaluation requests with networks shared by every request.
+// A forward pass only reads a network's weights, so requests run
+// concurrently, each with its own search engine.
+type server struct {
+	policyNetwork  *neural.RPSPolicyNetwork
+	valueNetwork   *neural.RPSValueNetwork
+	simulations    int // Default simulations per search
+	maxSimulations int // The most simulations a request may ask for
+}
+
+func main() {
+	addr := flag.String("addr", "localhost:8090", "Address to serve on")
+	policyPath := flag.String("policy", "output/rps_policy2.model", "Policy network model file")
+	valuePath := flag.String("value", "output/rps_value2.model", "Value network model file")
+	simulations := flag.Int("sims", 400, "Default MCTS simulations per request")
+	maxSimulations := flag.Int("max-sims", 10000, "Most MCTS simulations a request may ask for")
+	flag.Parse()
+
+	if *simulations < 1 || *maxSimulations < *simulations {
+		log.Fatalf("-sims must be at least 1 and no more than -max-sims")
+	}
+
+	srv := &server{
+		policyNetwork:  loadPolicyNetwork(*policyPath),
+		valueNetwork:   loadValueNetwork(*valuePath),
+		simulations:    *simulations,
+		maxSimulations: *maxSimulations,
+	}
+
+	http.HandleFunc("/v1/evaluate", srv.handleEvaluate)
+	http.HandleFunc("/v1/health", srv.handleHealth)
+
+	log.Printf("Serving on http://%s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, nil))
+}
+
+// loadPolicyNetwork loads the policy network, or returns an untrained one if
+// the file cannot be loaded
+func loadPolicyNetwork(path string) *neural.RPSPolicyNetwork {
+	network, err := neural.LoadPolicyNetwork(path)
+	if err != nil {
+		log.Printf("Failed to load policy model from %s: %v", path, err)
+		log.Println("Starting with a new model instead.")
+		return neural.NewRPSPolicyNetwork(128)
+	}
+	log.Printf("Loaded policy model from %s", path)
+	return network
+}
+
+// loadValueNetwork loads the value network, or returns an untrained one if
+// the file cannot be loaded
+func loadValueNetwork(path string) *neural.RPSValueNetwork {
+	hiddenSize := 128
+	if header, err := neural.ReadModelHeader(path); err == nil {
+		hiddenSize = header.HiddenSize
+	}
+	network := neural.NewRPSValueNetwork(hiddenSize)
+	if err := network.LoadFromFile(path); err != nil {
+		log.Printf("Failed to load value model from %s: %v", path, err)
+		log.Println("Starting with a new model instead.")
+		return neural.NewRPSValueNetwork(128)
+	}
+	log.Printf("Loaded value model from %s", path)
+	return network
+}
+
+// handleHealth reports that the server is up
+func (srv *server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// handleEvaluate evaluates a position: the request is an evaluateRequest and
+// the reply an evaluateResponse, or an errorResponse if the request is bad
+func (srv *server) handleEvaluate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "use POST to evaluate a position")
+		return
+	}
+
+	var req evaluateRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request: "+err.Error())
+		return
+	}
+
+	state, err := req.position()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid position: "+err.Error())
+		return
+	}
+	if state.BoardDim() != game.StandardBoardDim {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("the networks play on a %dx%d board", game.StandardBoardDim, game.StandardBoardDim))
+		return
+	}
+
+	simulations := req.Simulations
+	if simulations == 0 {
+		simulations = srv.simulations
+	}
+	if simulations < 1 || simulations > srv.maxSimulations {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("simulations must be between 1 and %d", srv.maxSimulations))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, srv.evaluate(state, simulations, req.Seed))
+}
+
+// evaluate runs the networks and a search of the given size on state
+func (srv *server) evaluate(state *game.RPSGame, simulations int, seed *int64) evaluateResponse {
+	resp := evaluateResponse{
+		Notation:      state.Notation(),
+		CurrentPlayer: int(state.CurrentPlayer),
+		GameOver:      state.IsGameOver(),
+		Policy:        srv.policyNetwork.PredictMasked(state),
+		Value:         srv.valueNetwork.Predict(state),
+	}
+	if resp.GameOver {
+		resp.Winner = int(state.GetWinner())
+		return resp
+	}
+
+	// Each request gets its own engine; the search tree is per request and
+	// the networks are only read
+	params := mcts.DefaultRPSMCTSParams()
+	params.NumSimulations = simulations
+	params.DirichletNoise = false
+	if seed != nil {
+		params.Rng = rand.New(rand.NewSource(*seed))
+	}
+	engine := mcts.NewRPSMCTS(srv.policyNetwork, srv.valueNetwork, params)
+	engine.SetRootState(state)
+	engine.Search()
+
+	report := engine.LastSearchReport()
+	resp.Simulations = report.Simulations
+	searchValue := engine.GetRootValue()
+	resp.SearchValue = &searchValue
+	for _, c := range report.TopMoves(candidateCount) {
+		resp.Candidates = append(resp.Candidates, newCandidate(c))
+	}
+	if len(resp.Candidates) > 0 {
+		resp.Move = &resp.Candidates[0]
+	}
+	return resp
+}
+
+// writeJSON sends value as a JSON response with the given status
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		log.Printf("Failed to write response: %v", err)
+	}
+}
+
+// writeError sends an errorResponse
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, errorResponse{Error: message})
+}
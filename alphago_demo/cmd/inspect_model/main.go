@@ -0,0 +1,124 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	neural "github.com/zachbeta/neural_rps/alphago_demo/pkg/rps_net_impl"
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s MODEL_FILE...\n\n", os.Args[0])
+		fmt.Fprintln(os.Stderr, "Prints the architecture, training metadata and weight statistics of")
+		fmt.Fprintln(os.Stderr, "policy and value model files, and flags NaN or infinite weights.")
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// Exit with an error if any model cannot be loaded or has bad weights
+	failed := false
+	for i, path := range flag.Args() {
+		if i > 0 {
+			fmt.Println()
+		}
+		if !inspect(path) {
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// inspect prints a report on one model file and returns false if it could not
+// be loaded or has NaN or infinite weights
+func inspect(path string) bool {
+	fmt.Println(path)
+
+	header, err := neural.ReadModelHeader(path)
+	if err != nil {
+		fmt.Printf("  Error: %v\n", err)
+		return false
+	}
+
+	var stats neural.NetworkStats
+	var layers []neural.LayerStats
+	var canonical bool
+	switch header.NetworkType {
+	case neural.PolicyNetworkType:
+		network, err := neural.LoadPolicyNetwork(path)
+		if err != nil {
+			fmt.Printf("  Error: %v\n", err)
+			return false
+		}
+		stats = neural.CalculatePolicyNetworkStats(network)
+		layers = neural.PolicyLayerStats(network)
+		canonical = network.UsesCanonicalInput()
+	case neural.ValueNetworkType:
+		network := neural.NewRPSValueNetwork(header.HiddenSize)
+		if err := network.LoadFromFile(path); err != nil {
+			fmt.Printf("  Error: %v\n", err)
+			return false
+		}
+		stats = neural.CalculateValueNetworkStats(network)
+		layers = neural.ValueLayerStats(network)
+		canonical = network.UsesCanonicalInput()
+	default:
+		fmt.Printf("  Error: unknown network type %q\n", header.NetworkType)
+		return false
+	}
+
+	if header.Version == 0 {
+		fmt.Printf("  Type: %s network (no header, type inferred)\n", header.NetworkType)
+	} else {
+		fmt.Printf("  Type: %s network (format version %d)\n", header.NetworkType, header.Version)
+	}
+	fmt.Printf("  Architecture: %d-%d-%d, %s hidden layer, batch norm: %v, canonical input: %v\n",
+		stats.InputSize, stats.HiddenSize, stats.OutputSize, header.Activation, header.BatchNorm, canonical)
+	fmt.Printf("  Input encoding: feature version %d\n", header.FeatureVersion)
+	fmt.Printf("  Parameters: %d (%.2f KB)\n", stats.TotalParameters, stats.MemoryFootprint)
+	fmt.Printf("  Training: %s\n", describeMetadata(header.Metadata))
+
+	fmt.Println("  Layers:")
+	fmt.Printf("    %-24s %8s %10s %10s %10s %10s\n", "", "values", "min", "max", "mean", "std")
+	badWeights := 0
+	for _, layer := range layers {
+		fmt.Printf("    %-24s %8d %10.4f %10.4f %10.4f %10.4f\n",
+			layer.Name, layer.Count, layer.Min, layer.Max, layer.Mean, layer.Std)
+		if layer.NaN > 0 || layer.Inf > 0 {
+			fmt.Printf("    WARNING: %s has %d NaN and %d infinite values\n", layer.Name, layer.NaN, layer.Inf)
+			badWeights += layer.NaN + layer.Inf
+		}
+	}
+
+	if badWeights > 0 {
+		fmt.Printf("  The model has %d NaN or infinite parameters and will not play correctly\n", badWeights)
+		return false
+	}
+	return true
+}
+
+// describeMetadata summarises training metadata, or says that there is none
+func describeMetadata(m neural.ModelMetadata) string {
+	if m == (neural.ModelMetadata{}) {
+		return "not recorded"
+	}
+
+	description := ""
+	if m.Games > 0 {
+		description += fmt.Sprintf("%d self-play games, ", m.Games)
+	}
+	if m.Epochs > 0 {
+		description += fmt.Sprintf("%d epochs, ", m.Epochs)
+	}
+	if m.Timestamp != "" {
+		description += "finished " + m.Timestamp + ", "
+	}
+	return description[:len(description)-2]
+}
@@ -20,12 +20,20 @@ func main() {
 	outFile := flag.String("out", "", "Output file for detailed results (optional)")
 	useCache := flag.Bool("cache", true, "Enable transposition table")
 	hiddenSize := flag.Int("hidden", 64, "Neural network hidden layer size")
+	seed := flag.Int64("seed", 0, "Seed for the network's weights and the games (0 picks one from the clock)")
 	flag.Parse()
 
+	// Print the seed so a run can be repeated
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	fmt.Printf("Seed: %d\n", *seed)
+	rng := rand.New(rand.NewSource(*seed))
+
 	// Create neural network agent with a newly initialized network
 	neuralAgent := neural.NewNeuralAgent(
 		fmt.Sprintf("Neural-H%d", *hiddenSize),
-		neural.NewRPSPolicyNetwork(*hiddenSize),
+		neural.NewRPSPolicyNetworkSeeded(*hiddenSize, *seed),
 	)
 
 	// Create minimax agent
@@ -46,9 +54,6 @@ func main() {
 	handSize := 5
 	maxRounds := 10
 
-	// Random seed for game generation
-	rand.Seed(time.Now().UnixNano())
-
 	// Track wins and game outcomes
 	neuralWins := 0
 	minimaxWins := 0
@@ -58,10 +63,10 @@ func main() {
 
 	for i := 0; i < *games; i++ {
 		// Create a new game
-		g := game.NewRPSGame(deckSize, handSize, maxRounds)
+		g := game.NewRPSGameSeeded(deckSize, handSize, maxRounds, rng)
 
 		// Determine first player randomly for fairness
-		minimaxIsP1 := rand.Intn(2) == 0
+		minimaxIsP1 := rng.Intn(2) == 0
 		var minimaxPlayer, neuralPlayer game.RPSPlayer
 		if minimaxIsP1 {
 			minimaxPlayer = game.Player1
@@ -177,11 +182,12 @@ func main() {
 		moveAgreements, float64(moveAgreements)/float64(totalMoves)*100)
 
 	// Print minimax agent stats
-	avgTime, totalPositions, avgPositionsPerMove := minimaxAgent.GetStats()
+	avgTime, totalPositions, avgPositionsPerMove, avgDepth := minimaxAgent.GetStats()
 	fmt.Printf("\nMinimax agent stats:\n")
 	fmt.Printf("  Average time per move: %v\n", avgTime)
 	fmt.Printf("  Total positions evaluated: %d\n", totalPositions)
 	fmt.Printf("  Average positions per move: %.1f\n", avgPositionsPerMove)
+	fmt.Printf("  Average search depth: %.1f\n", avgDepth)
 
 	// Output a summary if an output file is specified
 	if *outFile != "" {
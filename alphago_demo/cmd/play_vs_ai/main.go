@@ -1,14 +1,15 @@
 package main
 
 import (
-	"bufio"
+	"errors"
+	"flag"
 	"fmt"
 	"math/rand"
 	"os"
-	"strconv"
-	"strings"
+	"path/filepath"
 	"time"
 
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/agents"
 	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
 	"github.com/zachbeta/neural_rps/alphago_demo/pkg/mcts"
 	neural "github.com/zachbeta/neural_rps/alphago_demo/pkg/rps_net_impl"
@@ -19,28 +20,35 @@ const (
 	deckSize  = 21
 	handSize  = 5
 	maxRounds = 10
-
-	// MCTS parameters
-	mctsSimulations = 200
 )
 
 func main() {
 	// Seed random number generator
 	rand.Seed(time.Now().UnixNano())
 
+	difficultyName := flag.String("difficulty", "medium", "AI strength: easy, medium or hard")
+	transcriptPath := flag.String("transcript", "output/last_game.rpsgame", "Where to save the finished game for cmd/replay")
+	flag.Parse()
+
+	difficulty, err := mcts.ParseDifficulty(*difficultyName)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
 	// Get model file path from command-line arguments or use default
 	modelPath := "output/rps_policy2.model"
 	valueModelPath := "output/rps_value2.model"
-	if len(os.Args) > 1 {
-		modelPath = os.Args[1]
+	if flag.NArg() > 0 {
+		modelPath = flag.Arg(0)
 	}
-	if len(os.Args) > 2 {
-		valueModelPath = os.Args[2]
+	if flag.NArg() > 1 {
+		valueModelPath = flag.Arg(1)
 	}
 
 	// Load policy network from file
 	policyNetwork := neural.NewRPSPolicyNetwork(128)
-	err := policyNetwork.LoadFromFile(modelPath)
+	err = policyNetwork.LoadFromFile(modelPath)
 	if err != nil {
 		fmt.Printf("Failed to load policy model from %s: %v\n", modelPath, err)
 		fmt.Println("Starting with a new model instead.")
@@ -59,15 +67,18 @@ func main() {
 	}
 
 	// Create MCTS engine for the AI
-	mctsParams := mcts.DefaultRPSMCTSParams()
-	mctsParams.NumSimulations = mctsSimulations
+	mctsParams := difficulty.Apply(mcts.DefaultRPSMCTSParams())
 	mctsEngine := mcts.NewRPSMCTS(policyNetwork, valueNetwork, mctsParams)
+	fmt.Printf("Difficulty: %s\n", difficulty)
 
 	// Create the game
 	gameInstance := game.NewRPSGame(deckSize, handSize, maxRounds)
+	transcript := game.NewTranscript(gameInstance, deckSize, handSize)
+	transcript.Player1 = "Human"
+	transcript.Player2 = "AI"
 
 	// Main game loop
-	scanner := bufio.NewScanner(os.Stdin)
+	input := game.NewPrompter(os.Stdin, os.Stdout)
 	for !gameInstance.IsGameOver() {
 		// Print current game state
 		fmt.Println(gameInstance.String())
@@ -79,7 +90,11 @@ func main() {
 		if currentPlayer == game.Player1 {
 			// Human's turn
 			fmt.Println("Your turn! Choose a card and position.")
-			move, err := getHumanMove(scanner, gameInstance)
+			move, err := getHumanMove(input, gameInstance)
+			if errors.Is(err, game.ErrInputClosed) {
+				fmt.Println("Input closed. Goodbye!")
+				return
+			}
 			if err != nil {
 				fmt.Printf("Error: %v\n", err)
 				continue
@@ -91,6 +106,7 @@ func main() {
 				fmt.Printf("Invalid move: %v\n", err)
 				continue
 			}
+			transcript.Record(move)
 		} else {
 			// AI's turn
 			fmt.Println("AI is thinking...")
@@ -98,24 +114,26 @@ func main() {
 			// Set the root state for MCTS
 			mctsEngine.SetRootState(gameInstance)
 
-			// Search for the best move
-			bestNode := mctsEngine.Search()
+			// Search, then pick a move as the difficulty allows
+			mctsEngine.Search()
+			bestNode := mctsEngine.SampleMove(difficulty.Temperature())
 
 			if bestNode == nil || bestNode.Move == nil {
 				fmt.Println("AI couldn't find a valid move!")
-				// Fallback to random move
-				randomMove, err := gameInstance.GetRandomMove()
+				// Fall back to the policy network's top legal move
+				fallbackMove, err := agents.FallbackMove(gameInstance, agents.FallbackPolicyPrior, mctsEngine.PolicyNetwork)
 				if err != nil {
 					fmt.Printf("Error: %v\n", err)
 					break
 				}
-				randomMove.Player = currentPlayer
-				err = gameInstance.MakeMove(randomMove)
+				fallbackMove.Player = currentPlayer
+				err = gameInstance.MakeMove(fallbackMove)
 				if err != nil {
 					fmt.Printf("Error: %v\n", err)
 					break
 				}
-				fmt.Printf("AI plays card %d at position %d\n", randomMove.CardIndex, randomMove.Position)
+				transcript.Record(fallbackMove)
+				fmt.Printf("AI plays card %d at position %d\n", fallbackMove.CardIndex, fallbackMove.Position)
 			} else {
 				// Execute the best move found by MCTS
 				aiMove := *bestNode.Move
@@ -125,7 +143,8 @@ func main() {
 					fmt.Printf("Error: %v\n", err)
 					break
 				}
-				fmt.Printf("AI plays card %d at position %d\n", aiMove.CardIndex, aiMove.Position)
+				transcript.Record(aiMove)
+				fmt.Printf("AI %s\n", mctsEngine.LastSearchReport().Explain(aiMove))
 			}
 		}
 
@@ -141,15 +160,26 @@ func main() {
 	switch winner {
 	case game.Player1:
 		fmt.Println("You win!")
+		transcript.Result = transcript.Player1
 	case game.Player2:
 		fmt.Println("AI wins!")
+		transcript.Result = transcript.Player2
 	default:
 		fmt.Println("It's a draw!")
+		transcript.Result = "draw"
+	}
+
+	if err := os.MkdirAll(filepath.Dir(*transcriptPath), 0755); err == nil {
+		if err := transcript.Save(*transcriptPath); err != nil {
+			fmt.Printf("Failed to save game transcript: %v\n", err)
+		} else {
+			fmt.Printf("Game transcript saved to %s (view it with cmd/replay)\n", *transcriptPath)
+		}
 	}
 }
 
 // getHumanMove gets a move from the human player
-func getHumanMove(scanner *bufio.Scanner, gameState *game.RPSGame) (game.RPSMove, error) {
+func getHumanMove(input *game.Prompter, gameState *game.RPSGame) (game.RPSMove, error) {
 	// Print the player's hand
 	fmt.Println("Your hand:")
 	for i, card := range gameState.Player1Hand {
@@ -167,25 +197,16 @@ func getHumanMove(scanner *bufio.Scanner, gameState *game.RPSGame) (game.RPSMove
 	fmt.Println()
 
 	// Get card index
-	fmt.Print("Choose card index (0-4): ")
-	if !scanner.Scan() {
-		return game.RPSMove{}, fmt.Errorf("failed to read input")
-	}
-	cardIndexStr := scanner.Text()
-	cardIndex, err := strconv.Atoi(strings.TrimSpace(cardIndexStr))
-	if err != nil || cardIndex < 0 || cardIndex >= len(gameState.Player1Hand) {
-		return game.RPSMove{}, fmt.Errorf("invalid card index")
+	lastCard := len(gameState.Player1Hand) - 1
+	cardIndex, err := input.Int(fmt.Sprintf("Choose card index (0-%d): ", lastCard), 0, lastCard)
+	if err != nil {
+		return game.RPSMove{}, err
 	}
 
 	// Get position
-	fmt.Print("Choose position (0-8): ")
-	if !scanner.Scan() {
-		return game.RPSMove{}, fmt.Errorf("failed to read input")
-	}
-	positionStr := scanner.Text()
-	position, err := strconv.Atoi(strings.TrimSpace(positionStr))
-	if err != nil || position < 0 || position > 8 {
-		return game.RPSMove{}, fmt.Errorf("invalid position")
+	position, err := input.Int("Choose position (0-8): ", 0, 8)
+	if err != nil {
+		return game.RPSMove{}, err
 	}
 
 	// Check if the position is already occupied
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
+)
+
+func main() {
+	step := flag.Bool("step", true, "Wait for Enter between moves")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [-step=false] <transcript file>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	var transcript game.Transcript
+	if err := transcript.Load(flag.Arg(0)); err != nil {
+		log.Fatalf("Failed to load transcript: %v", err)
+	}
+
+	fmt.Printf("%s vs %s (deck %d, hand %d, %d rounds)\n",
+		nameOrDefault(transcript.Player1, "Player 1"), nameOrDefault(transcript.Player2, "Player 2"),
+		transcript.DeckSize, transcript.HandSize, transcript.MaxRounds)
+	fmt.Printf("%d moves\n\n", len(transcript.Moves))
+
+	states, err := transcript.Replay()
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for i, state := range states {
+		if i == 0 {
+			fmt.Println("Initial position:")
+		} else {
+			move := transcript.Moves[i-1]
+			fmt.Printf("Move %d: player %d plays card %d at position %d\n",
+				i, move.Player, move.CardIndex, move.Position)
+		}
+		fmt.Println(state.String())
+		fmt.Println()
+
+		if *step && i < len(states)-1 {
+			fmt.Print("Press Enter for the next move...")
+			if !scanner.Scan() {
+				return
+			}
+		}
+	}
+
+	if err != nil {
+		log.Fatalf("Replay stopped: %v", err)
+	}
+
+	if transcript.Result != "" {
+		fmt.Printf("Result: %s\n", transcript.Result)
+	}
+}
+
+// nameOrDefault returns name, or fallback when the transcript does not record one
+func nameOrDefault(name, fallback string) string {
+	if name == "" {
+		return fallback
+	}
+	return name
+}
@@ -1,14 +1,16 @@
 package main
 
 import (
-	"bufio"
+	"errors"
+	"flag"
 	"fmt"
+	"math"
 	"math/rand"
 	"os"
-	"strconv"
 	"strings"
 	"time"
 
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/agents"
 	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
 	"github.com/zachbeta/neural_rps/alphago_demo/pkg/mcts"
 	neural "github.com/zachbeta/neural_rps/alphago_demo/pkg/rps_net_impl"
@@ -33,7 +35,23 @@ const (
 	learningRate   = 0.01
 )
 
+// difficulty sets the AI's strength in games against a human; it is chosen
+// with -difficulty and can be changed from the main menu
+var difficulty = mcts.DifficultyMedium
+
+// input reads every answer from stdin
+var input = game.NewPrompter(os.Stdin, os.Stdout)
+
 func main() {
+	difficultyName := flag.String("difficulty", "medium", "AI strength: easy, medium or hard")
+	flag.Parse()
+
+	var err error
+	if difficulty, err = mcts.ParseDifficulty(*difficultyName); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
 	// Seed random number generator
 	rand.Seed(time.Now().UnixNano())
 
@@ -74,11 +92,14 @@ func main() {
 		fmt.Println("\nMain Menu:")
 		fmt.Println("1. Play against AI")
 		fmt.Println("2. Watch AI vs AI demonstration")
-		fmt.Println("3. Exit")
-		fmt.Print("Select an option: ")
+		fmt.Printf("3. Change difficulty (currently %s)\n", difficulty)
+		fmt.Println("4. Exit")
 
-		var choice int
-		fmt.Scanln(&choice)
+		choice, err := input.Int("Select an option: ", 1, 4)
+		if err != nil {
+			fmt.Println("Goodbye!")
+			return
+		}
 
 		switch choice {
 		case 1:
@@ -86,10 +107,10 @@ func main() {
 		case 2:
 			aiDemonstration(policyNetwork, valueNetwork)
 		case 3:
+			chooseDifficulty()
+		case 4:
 			fmt.Println("Goodbye!")
 			return
-		default:
-			fmt.Println("Invalid choice. Please try again.")
 		}
 	}
 }
@@ -102,10 +123,11 @@ func playAgainstAI(policyNetwork *neural.RPSPolicyNetwork, valueNetwork *neural.
 	gameInstance := game.NewRPSGame(deckSize, handSize, maxRounds)
 
 	// Create MCTS for AI
-	mctsParams := mcts.DefaultRPSMCTSParams()
+	mctsParams := difficulty.Apply(mcts.DefaultRPSMCTSParams())
 	mctsEngine := mcts.NewRPSMCTS(policyNetwork, valueNetwork, mctsParams)
 
-	scanner := bufio.NewScanner(os.Stdin)
+	// Every move made so far, so the human can take moves back
+	var history []playedMove
 
 	// Game loop
 	for !gameInstance.IsGameOver() {
@@ -144,21 +166,20 @@ func playAgainstAI(policyNetwork *neural.RPSPolicyNetwork, valueNetwork *neural.
 				fmt.Printf("%d: %s\n", i, cardType)
 			}
 
-			// Get card selection
-			var cardIndex int
-			for {
-				fmt.Print("Select card (0-" + strconv.Itoa(len(gameInstance.Player1Hand)-1) + "): ")
-				var input string
-				scanner.Scan()
-				input = scanner.Text()
-
-				var err error
-				cardIndex, err = strconv.Atoi(input)
-				if err != nil || cardIndex < 0 || cardIndex >= len(gameInstance.Player1Hand) {
-					fmt.Println("Invalid selection. Try again.")
-					continue
+			// Get card selection, or take back the last turn
+			cardIndex, command, err := input.IntOr(fmt.Sprintf("Select card (0-%d), or undo: ", len(gameInstance.Player1Hand)-1),
+				0, len(gameInstance.Player1Hand)-1, "undo")
+			if err != nil {
+				fmt.Println("Input closed. Leaving the game.")
+				return
+			}
+			if command == "undo" {
+				if history, err = undoLastTurn(gameInstance, history); err != nil {
+					fmt.Printf("Nothing to undo: %v\n", err)
+				} else {
+					fmt.Println("Took back your last move and the AI's reply.")
 				}
-				break
+				continue
 			}
 
 			// Get valid positions for this card
@@ -215,28 +236,10 @@ func playAgainstAI(policyNetwork *neural.RPSPolicyNetwork, valueNetwork *neural.
 			// Get position selection
 			var row, col int
 			for {
-				fmt.Print("Enter position as row,col (e.g., 1,2): ")
-				var input string
-				scanner.Scan()
-				input = scanner.Text()
-
-				parts := strings.Split(input, ",")
-				if len(parts) != 2 {
-					fmt.Println("Invalid format. Use row,col (e.g., 1,2)")
-					continue
-				}
-
-				var err error
-				row, err = strconv.Atoi(strings.TrimSpace(parts[0]))
-				if err != nil || row < 0 || row >= 3 {
-					fmt.Println("Invalid row. Must be 0-2.")
-					continue
-				}
-
-				col, err = strconv.Atoi(strings.TrimSpace(parts[1]))
-				if err != nil || col < 0 || col >= 3 {
-					fmt.Println("Invalid column. Must be 0-2.")
-					continue
+				row, col, err = input.RowCol("Enter position as row,col (e.g., 1,2): ", 3)
+				if err != nil {
+					fmt.Println("Input closed. Leaving the game.")
+					return
 				}
 
 				position := row*3 + col
@@ -264,60 +267,51 @@ func playAgainstAI(policyNetwork *neural.RPSPolicyNetwork, valueNetwork *neural.
 				Player:    game.Player1,
 			}
 
-			err := gameInstance.MakeMove(move)
+			undo, err := gameInstance.MakeMoveReversible(move)
 			if err != nil {
 				fmt.Printf("Error making move: %v\n", err)
 				return
 			}
+			history = append(history, playedMove{player: game.Player1, undo: undo})
 
 		} else {
 			// AI's turn
 			fmt.Println("AI is thinking...")
 
-			// Use MCTS to find the best move
+			// Search, then pick a move as the difficulty allows
 			mctsEngine.SetRootState(gameInstance)
-			bestNode := mctsEngine.Search()
+			mctsEngine.Search()
+			bestNode := mctsEngine.SampleMove(difficulty.Temperature())
 
 			if bestNode != nil && bestNode.Move != nil {
 				// Make the move
-				err := gameInstance.MakeMove(*bestNode.Move)
+				undo, err := gameInstance.MakeMoveReversible(*bestNode.Move)
 				if err != nil {
 					fmt.Printf("Error making AI move: %v\n", err)
 					return
 				}
+				history = append(history, playedMove{player: game.Player2, undo: undo})
 
-				// Display the move
-				cardType := gameInstance.Board[bestNode.Move.Position].Type
-				var cardTypeStr string
-				switch cardType {
-				case game.Rock:
-					cardTypeStr = "Rock"
-				case game.Paper:
-					cardTypeStr = "Paper"
-				case game.Scissors:
-					cardTypeStr = "Scissors"
-				}
-
-				row := bestNode.Move.Position / 3
-				col := bestNode.Move.Position % 3
-				fmt.Printf("AI plays %s at position (%d,%d)\n", cardTypeStr, row, col)
+				// Display the move and why the search chose it
+				fmt.Printf("AI %s\n", mctsEngine.LastSearchReport().Explain(*bestNode.Move))
 
 			} else {
-				// Use random move if MCTS fails
-				randomMove, err := gameInstance.GetRandomMove()
+				// Fall back to the policy network's top legal move if MCTS fails
+				fallbackMove, err := agents.FallbackMove(gameInstance, agents.FallbackPolicyPrior, mctsEngine.PolicyNetwork)
 				if err != nil {
 					fmt.Printf("No valid moves for AI: %v\n", err)
 					break
 				}
 
-				err = gameInstance.MakeMove(randomMove)
+				undo, err := gameInstance.MakeMoveReversible(fallbackMove)
 				if err != nil {
-					fmt.Printf("Error making random AI move: %v\n", err)
+					fmt.Printf("Error making fallback AI move: %v\n", err)
 					return
 				}
+				history = append(history, playedMove{player: game.Player2, undo: undo})
 
 				// Display the move
-				cardType := gameInstance.Board[randomMove.Position].Type
+				cardType := gameInstance.Board[fallbackMove.Position].Type
 				var cardTypeStr string
 				switch cardType {
 				case game.Rock:
@@ -328,8 +322,8 @@ func playAgainstAI(policyNetwork *neural.RPSPolicyNetwork, valueNetwork *neural.
 					cardTypeStr = "Scissors"
 				}
 
-				row := randomMove.Position / 3
-				col := randomMove.Position % 3
+				row := fallbackMove.Position / 3
+				col := fallbackMove.Position % 3
 				fmt.Printf("AI plays %s at position (%d,%d)\n", cardTypeStr, row, col)
 			}
 		}
@@ -349,6 +343,31 @@ func playAgainstAI(policyNetwork *neural.RPSPolicyNetwork, valueNetwork *neural.
 	}
 }
 
+// playedMove is a move made in a game against the AI, kept so it can be undone
+type playedMove struct {
+	player game.RPSPlayer
+	undo   game.MoveUndo
+}
+
+// undoLastTurn takes back the human's most recent move and every AI move made
+// since, returning the game to the human's previous decision, and returns the
+// shortened history. It fails without changing anything if the human has not
+// moved yet.
+func undoLastTurn(g *game.RPSGame, history []playedMove) ([]playedMove, error) {
+	last := len(history) - 1
+	for last >= 0 && history[last].player != game.Player1 {
+		last--
+	}
+	if last < 0 {
+		return history, errors.New("you have not made a move yet")
+	}
+
+	for i := len(history) - 1; i >= last; i-- {
+		g.UndoMove(history[i].undo)
+	}
+	return history[:last], nil
+}
+
 func aiDemonstration(policyNetwork *neural.RPSPolicyNetwork, valueNetwork *neural.RPSValueNetwork) {
 	fmt.Println("\nAI vs AI Demonstration")
 	fmt.Println("=====================")
@@ -371,6 +390,7 @@ func aiDemonstration(policyNetwork *neural.RPSPolicyNetwork, valueNetwork *neura
 		fmt.Printf("\nMove %d\n", moveCount+1)
 
 		// Use MCTS to find the best move
+		mover := gameInstance.CurrentPlayer
 		mctsEngine.SetRootState(gameInstance)
 		bestNode := mctsEngine.Search()
 
@@ -404,30 +424,31 @@ func aiDemonstration(policyNetwork *neural.RPSPolicyNetwork, valueNetwork *neura
 			col := move.Position % 3
 			fmt.Printf("%s plays %s at position (%d,%d)\n", playerName, cardTypeStr, row, col)
 
-			// Display game state
+			// Display the game state and who the search thinks is winning
 			fmt.Println(gameInstance.String())
+			fmt.Println("Evaluation: " + valueBar(player1Score(mctsEngine.GetRootValue(), mover)))
 
 		} else {
-			// Use random move if MCTS fails
-			randomMove, err := gameInstance.GetRandomMove()
+			// Fall back to the policy network's top legal move if MCTS fails
+			fallbackMove, err := agents.FallbackMove(gameInstance, agents.FallbackPolicyPrior, mctsEngine.PolicyNetwork)
 			if err != nil {
 				fmt.Printf("No valid moves: %v\n", err)
 				break
 			}
 
-			err = gameInstance.MakeMove(randomMove)
+			err = gameInstance.MakeMove(fallbackMove)
 			if err != nil {
-				fmt.Printf("Error making random move: %v\n", err)
+				fmt.Printf("Error making fallback move: %v\n", err)
 				return
 			}
 
 			// Display the move
 			playerName := "Player 1"
-			if randomMove.Player == game.Player2 {
+			if fallbackMove.Player == game.Player2 {
 				playerName = "Player 2"
 			}
 
-			cardType := gameInstance.Board[randomMove.Position].Type
+			cardType := gameInstance.Board[fallbackMove.Position].Type
 			var cardTypeStr string
 			switch cardType {
 			case game.Rock:
@@ -438,9 +459,9 @@ func aiDemonstration(policyNetwork *neural.RPSPolicyNetwork, valueNetwork *neura
 				cardTypeStr = "Scissors"
 			}
 
-			row := randomMove.Position / 3
-			col := randomMove.Position % 3
-			fmt.Printf("%s plays %s at position (%d,%d) (random)\n", playerName, cardTypeStr, row, col)
+			row := fallbackMove.Position / 3
+			col := fallbackMove.Position % 3
+			fmt.Printf("%s plays %s at position (%d,%d) (fallback)\n", playerName, cardTypeStr, row, col)
 
 			// Display game state
 			fmt.Println(gameInstance.String())
@@ -464,3 +485,40 @@ func aiDemonstration(policyNetwork *neural.RPSPolicyNetwork, valueNetwork *neura
 		fmt.Println("Player 2 wins!")
 	}
 }
+
+// chooseDifficulty asks for a new AI difficulty from the main menu
+func chooseDifficulty() {
+	fmt.Println("\nDifficulty:")
+	for i, d := range mcts.Difficulties {
+		fmt.Printf("%d. %s (%d simulations)\n", i+1, d, d.Simulations())
+	}
+
+	choice, err := input.Int("Select a difficulty: ", 1, len(mcts.Difficulties))
+	if err != nil {
+		return
+	}
+	difficulty = mcts.Difficulties[choice-1]
+	fmt.Printf("Difficulty set to %s\n", difficulty)
+}
+
+// valueBar draws a position's evaluation, from -1 (Player 2 winning) to +1
+// (Player 1 winning), as a bar growing from the centre towards the leader
+func valueBar(score float64) string {
+	const half = 10
+	score = math.Max(-1, math.Min(1, score))
+
+	p1 := int(math.Round(math.Max(score, 0) * half))
+	p2 := int(math.Round(math.Max(-score, 0) * half))
+	left := strings.Repeat(" ", half-p2) + strings.Repeat("=", p2)
+	right := strings.Repeat("=", p1) + strings.Repeat(" ", half-p1)
+	return fmt.Sprintf("P2 [%s|%s] P1  %+.2f", left, right, score)
+}
+
+// player1Score converts a value for mover, from 0 to 1, to a score from -1
+// (Player 2 winning) to +1 (Player 1 winning)
+func player1Score(value float64, mover game.RPSPlayer) float64 {
+	if mover == game.Player2 {
+		value = 1 - value
+	}
+	return 2*value - 1
+}
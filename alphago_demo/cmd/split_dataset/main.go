@@ -0,0 +1,68 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/training"
+)
+
+func main() {
+	inputFile := flag.String("input", "data/training_data.jsonl", "Dataset written by generate_training_data")
+	outputDir := flag.String("output-dir", "data", "Directory for the split datasets and feature files")
+	valSplit := flag.Float64("val-split", 0.1, "Proportion of examples held out for validation (0.0-1.0)")
+	seed := flag.Int64("seed", 1, "Shuffle seed; the same dataset and seed always give the same split")
+	flag.Parse()
+
+	if *valSplit < 0 || *valSplit > 1 {
+		fmt.Fprintln(os.Stderr, "-val-split must be between 0 and 1")
+		os.Exit(2)
+	}
+
+	var examples []training.TrainingExample
+	stream, errc := training.ReadExamples(*inputFile)
+	for example := range stream {
+		examples = append(examples, example)
+	}
+	if err := <-errc; err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to read %s: %v\n", *inputFile, err)
+		os.Exit(1)
+	}
+	fmt.Printf("Loaded %d examples of %d distinct positions from %s\n",
+		len(examples), training.UniquePositions(examples), *inputFile)
+
+	train, val := training.SplitDataset(examples, *valSplit, *seed)
+	fmt.Printf("Split with seed %d into %d training and %d validation examples\n", *seed, len(train), len(val))
+
+	if err := os.MkdirAll(*outputDir, 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create %s: %v\n", *outputDir, err)
+		os.Exit(1)
+	}
+	for _, set := range []struct {
+		name     string
+		examples []training.TrainingExample
+	}{{"training", train}, {"validation", val}} {
+		if err := save(*outputDir, set.name, set.examples); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to save %s set: %v\n", set.name, err)
+			os.Exit(1)
+		}
+	}
+
+	fmt.Printf("Saved %s/{training,validation}.jsonl and the feature files train_supervised loads\n", *outputDir)
+}
+
+// save writes one side of the split both as examples and as network features
+func save(dir, name string, examples []training.TrainingExample) error {
+	path := filepath.Join(dir, name+".jsonl")
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := training.AppendExamples(path, examples); err != nil {
+		return err
+	}
+
+	inputs, targets := training.ExampleFeatures(examples)
+	return training.SaveFeatureSet(dir, name, inputs, targets)
+}
@@ -84,10 +84,11 @@ func main() {
 
 	// Print minimax agent stats
 	for _, agent := range minimaxAgents {
-		avgTime, totalPositions, avgPositionsPerMove := agent.GetStats()
+		avgTime, totalPositions, avgPositionsPerMove, avgDepth := agent.GetStats()
 		fmt.Printf("\n%s stats:\n", agent.Name())
 		fmt.Printf("  Average time per move: %v\n", avgTime)
 		fmt.Printf("  Total positions evaluated: %d\n", totalPositions)
 		fmt.Printf("  Average positions per move: %.1f\n", avgPositionsPerMove)
+		fmt.Printf("  Average search depth: %.1f\n", avgDepth)
 	}
 }
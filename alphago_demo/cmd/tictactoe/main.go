@@ -2,8 +2,11 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -22,7 +25,13 @@ const (
 	learningRate   = 0.001
 )
 
+// outputFileName is the name of the standardized output file in the output directory
+const outputFileName = "alphago_demo_output.txt"
+
 func main() {
+	outputDir := flag.String("output-dir", ".", "Directory to write "+outputFileName+" to")
+	flag.Parse()
+
 	fmt.Println("AlphaGo-style TicTacToe Demo")
 	fmt.Println("============================")
 
@@ -62,14 +71,19 @@ func main() {
 	}
 
 	// Generate standardized output
-	generateStandardizedOutput(
+	outputPath := filepath.Join(*outputDir, outputFileName)
+	if err := writeStandardizedOutput(outputPath,
 		policyNetwork,
 		valueNetwork,
 		selfPlayGames,
 		trainingExamples,
 		trainingTime,
 		policyLosses,
-		valueLosses)
+		valueLosses); err != nil {
+		fmt.Printf("Error writing standardized output: %v\n", err)
+	} else {
+		fmt.Printf("Standardized output written to %s\n", outputPath)
+	}
 
 	// Run a simulated demo game
 	fmt.Println("\nRunning demo game with simulated player...")
@@ -342,23 +356,37 @@ func evaluateNetworks(policyNetwork *neural.AGPolicyNetwork, valueNetwork *neura
 	fmt.Printf("Updated value prediction: %.3f\n", value)
 }
 
-// generateStandardizedOutput creates output in the standardized format
-func generateStandardizedOutput(
+// writeStandardizedOutput writes the standardized output to a file at path
+func writeStandardizedOutput(
+	path string,
 	policyNetwork *neural.AGPolicyNetwork,
 	valueNetwork *neural.AGValueNetwork,
 	selfPlayGames int,
 	trainingExamples int,
 	trainingTime time.Duration,
 	policyLosses []float64,
-	valueLosses []float64) {
+	valueLosses []float64) error {
 
-	// Create output file
-	f, err := os.Create("../alphago_demo_output.txt")
+	f, err := os.Create(path)
 	if err != nil {
-		fmt.Printf("Error creating output file: %v\n", err)
-		return
+		return err
 	}
-	defer f.Close()
+
+	generateStandardizedOutput(f, policyNetwork, valueNetwork,
+		selfPlayGames, trainingExamples, trainingTime, policyLosses, valueLosses)
+	return f.Close()
+}
+
+// generateStandardizedOutput writes output in the standardized format to f
+func generateStandardizedOutput(
+	f io.Writer,
+	policyNetwork *neural.AGPolicyNetwork,
+	valueNetwork *neural.AGValueNetwork,
+	selfPlayGames int,
+	trainingExamples int,
+	trainingTime time.Duration,
+	policyLosses []float64,
+	valueLosses []float64) {
 
 	// Header & Implementation Info
 	fmt.Fprintf(f, "==================================================\n")
@@ -439,7 +467,7 @@ func generateStandardizedOutput(
 
 // generateTicTacToePrediction generates a prediction for a Tic-Tac-Toe board
 func generateTicTacToePrediction(
-	f *os.File,
+	f io.Writer,
 	policyNetwork *neural.AGPolicyNetwork,
 	valueNetwork *neural.AGValueNetwork,
 	state *game.AGGame,
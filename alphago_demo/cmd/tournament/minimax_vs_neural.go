@@ -71,11 +71,12 @@ func main() {
 	analyzeResults(results, *outFile)
 
 	// Print minimax agent stats
-	avgTime, totalPositions, avgPositionsPerMove := minimaxAgent.GetStats()
+	avgTime, totalPositions, avgPositionsPerMove, avgDepth := minimaxAgent.GetStats()
 	fmt.Printf("\nMinimax agent stats:\n")
 	fmt.Printf("  Average time per move: %v\n", avgTime)
 	fmt.Printf("  Total positions evaluated: %d\n", totalPositions)
 	fmt.Printf("  Average positions per move: %.1f\n", avgPositionsPerMove)
+	fmt.Printf("  Average search depth: %.1f\n", avgDepth)
 }
 
 // runTournament plays a series of games between two agents
@@ -177,11 +177,12 @@ func main() {
 		moveAgreements, float64(moveAgreements)/float64(totalMoves)*100)
 
 	// Print minimax agent stats
-	avgTime, totalPositions, avgPositionsPerMove := minimaxAgent.GetStats()
+	avgTime, totalPositions, avgPositionsPerMove, avgDepth := minimaxAgent.GetStats()
 	fmt.Printf("\nMinimax agent stats:\n")
 	fmt.Printf("  Average time per move: %v\n", avgTime)
 	fmt.Printf("  Total positions evaluated: %d\n", totalPositions)
 	fmt.Printf("  Average positions per move: %.1f\n", avgPositionsPerMove)
+	fmt.Printf("  Average search depth: %.1f\n", avgDepth)
 
 	// Output a summary if an output file is specified
 	if *outFile != "" {
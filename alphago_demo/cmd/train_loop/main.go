@@ -0,0 +1,235 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/agents"
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/logging"
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/mcts"
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/metrics"
+	neural "github.com/zachbeta/neural_rps/alphago_demo/pkg/rps_net_impl"
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/training"
+)
+
+// loopState is the checkpoint written after every iteration so that a run can
+// be resumed with -resume
+type loopState struct {
+	Iteration  int    `json:"iteration"`  // Iterations completed
+	Promotions int    `json:"promotions"` // Candidates that replaced the best network
+	Games      int    `json:"games"`      // Self-play games played so far
+	Epochs     int    `json:"epochs"`     // Training epochs behind the best network
+	Timestamp  string `json:"timestamp"`
+}
+
+func main() {
+	iterations := flag.Int("iterations", 10, "Self-play, train and gate iterations to run")
+	games := flag.Int("games", 50, "Self-play games per iteration")
+	epochs := flag.Int("epochs", 5, "Training epochs per candidate")
+	hiddenSize := flag.Int("hidden", 64, "Hidden layer size of a new network")
+	simulations := flag.Int("sims", 100, "MCTS simulations per move in self-play and gating games")
+	learningRate := flag.Float64("lr", 0.001, "Learning rate")
+	batchSize := flag.Int("batch", 32, "Batch size")
+	bufferSize := flag.Int("buffer", 20000, "Replay buffer size in examples; candidates train on the most recent ones")
+	gateGames := flag.Int("gate-games", 40, "Games in each gating match (rounded up to an even number)")
+	gateThreshold := flag.Float64("gate-threshold", 0.55, "Share of gating points a candidate needs to replace the best network")
+	threads := flag.Int("threads", 0, "Self-play worker threads (0 = auto)")
+	outputDir := flag.String("output-dir", "output/train_loop", "Directory for the best networks, loss curve and checkpoint")
+	resume := flag.Bool("resume", false, "Continue from the best networks and checkpoint in -output-dir")
+	metricsAddr := flag.String("metrics-addr", "", "Serve Prometheus metrics on this address, e.g. :9100 (off by default)")
+	logConfig := logging.AddFlags(flag.CommandLine)
+	flag.Parse()
+	if err := logConfig.Install(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	if err := os.MkdirAll(*outputDir, 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create %s: %v\n", *outputDir, err)
+		os.Exit(1)
+	}
+	bestPolicyPath := filepath.Join(*outputDir, "best_policy.model")
+	bestValuePath := filepath.Join(*outputDir, "best_value.model")
+	statePath := filepath.Join(*outputDir, "loop_state.json")
+	lossPath := filepath.Join(*outputDir, "losses.csv")
+
+	var state loopState
+	bestPolicy := neural.NewRPSPolicyNetwork(*hiddenSize)
+	bestValue := neural.NewRPSValueNetwork(*hiddenSize)
+	if *resume {
+		if err := loadCheckpoint(statePath, bestPolicyPath, bestValuePath, &state, bestPolicy, bestValue); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to resume from %s: %v\n", *outputDir, err)
+			os.Exit(1)
+		}
+		fmt.Printf("Resuming after iteration %d with %d promotions so far\n", state.Iteration, state.Promotions)
+	}
+
+	// The monitor stays nil unless metrics are served, so nothing is reported
+	var monitor training.Monitor
+	if *metricsAddr != "" {
+		m, err := metrics.Serve(*metricsAddr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to serve metrics: %v\n", err)
+			os.Exit(1)
+		}
+		defer m.Close()
+		monitor = m
+		fmt.Printf("Serving metrics on http://%s/metrics\n", *metricsAddr)
+	}
+
+	selfPlayParams := training.DefaultRPSSelfPlayParams()
+	selfPlayParams.NumGames = *games
+	selfPlayParams.NumThreads = *threads
+	selfPlayParams.MCTSParams.NumSimulations = *simulations
+
+	// The buffer and loss curve are not checkpointed, so a resumed run starts
+	// them afresh
+	buffer := training.NewReplayBuffer(*bufferSize)
+	var history training.LossHistory
+	interrupted := notifyInterrupt()
+
+	lastIteration := state.Iteration + *iterations
+	for iteration := state.Iteration + 1; iteration <= lastIteration; iteration++ {
+		fmt.Printf("\n=== Iteration %d ===\n", iteration)
+		start := time.Now()
+
+		// Generate games with the current best networks
+		selfPlay := training.NewRPSSelfPlay(bestPolicy, bestValue, selfPlayParams)
+		selfPlay.StopOn(interrupted)
+		selfPlay.MonitorWith(monitor)
+		examples := selfPlay.GenerateGames(false)
+		if isClosed(interrupted) {
+			fmt.Println("Interrupted during self-play; the last checkpoint is kept")
+			break
+		}
+		buffer.Add(examples)
+		fmt.Printf("Self-play: %d examples, replay buffer holds %d\n", len(examples), buffer.Len())
+
+		// Train a candidate from the best networks on the replay buffer
+		candidatePolicy := bestPolicy.Clone()
+		candidateValue := bestValue.Clone()
+		trainer := training.NewRPSSelfPlay(candidatePolicy, candidateValue, selfPlayParams)
+		trainer.SetExamples(buffer.Examples())
+		trainer.StopOn(interrupted)
+		trainer.MonitorWith(monitor)
+		policyLosses, valueLosses := trainer.TrainNetworks(*epochs, *batchSize, *learningRate, false)
+		if isClosed(interrupted) {
+			fmt.Println("Interrupted during training; the candidate is discarded")
+			break
+		}
+		for i := range policyLosses {
+			history.AppendEpoch(policyLosses[i], valueLosses[i], math.NaN())
+		}
+		if len(policyLosses) > 0 {
+			fmt.Printf("Candidate trained: policy loss %.4f, value loss %.4f\n",
+				policyLosses[len(policyLosses)-1], valueLosses[len(valueLosses)-1])
+		}
+
+		// Gate the candidate against the best networks
+		result := training.RunGauntlet(
+			newAgent("Candidate", candidatePolicy, candidateValue, selfPlayParams.MCTSParams),
+			newAgent("Best", bestPolicy, bestValue, selfPlayParams.MCTSParams),
+			*gateGames, *gateThreshold)
+		fmt.Printf("Gating: %s\n", result)
+
+		state.Iteration = iteration
+		state.Games += *games
+		if result.Accepted {
+			bestPolicy, bestValue = candidatePolicy, candidateValue
+			state.Promotions++
+			state.Epochs += len(policyLosses)
+			fmt.Printf("Promoted the candidate (generation %d)\n", state.Promotions)
+		}
+
+		state.Timestamp = time.Now().Format(time.RFC3339)
+		if err := saveCheckpoint(statePath, bestPolicyPath, bestValuePath, state, bestPolicy, bestValue); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to save checkpoint: %v\n", err)
+			os.Exit(1)
+		}
+		if err := history.SaveCSV(lossPath); err != nil {
+			slog.Error("Failed to save loss history", "error", err)
+		}
+		fmt.Printf("Iteration %d finished in %s\n", iteration, time.Since(start).Round(time.Second))
+	}
+
+	fmt.Printf("\n%d iterations completed, %d promotions. Best networks: %s and %s\n",
+		state.Iteration, state.Promotions, bestPolicyPath, bestValuePath)
+}
+
+// newAgent creates an MCTS agent playing with the given networks
+func newAgent(name string, policy *neural.RPSPolicyNetwork, value *neural.RPSValueNetwork, params mcts.RPSMCTSParams) agents.Agent {
+	return agents.NewMCTSAgent(name, mcts.NewRPSMCTS(policy, value, params))
+}
+
+// saveCheckpoint writes the best networks and then the loop state, so that the
+// state never refers to networks that were not saved
+func saveCheckpoint(statePath, policyPath, valuePath string, state loopState,
+	policy *neural.RPSPolicyNetwork, value *neural.RPSValueNetwork) error {
+	metadata := neural.ModelMetadata{Games: state.Games, Epochs: state.Epochs, Timestamp: state.Timestamp}
+	policy.SetMetadata(metadata)
+	value.SetMetadata(metadata)
+	if err := policy.SaveToFile(policyPath); err != nil {
+		return err
+	}
+	if err := value.SaveToFile(valuePath); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(statePath, data, 0644)
+}
+
+// loadCheckpoint reads the loop state and best networks saved by saveCheckpoint
+func loadCheckpoint(statePath, policyPath, valuePath string, state *loopState,
+	policy *neural.RPSPolicyNetwork, value *neural.RPSValueNetwork) error {
+	data, err := os.ReadFile(statePath)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return fmt.Errorf("%s: %v", statePath, err)
+	}
+	if err := policy.LoadFromFile(policyPath); err != nil {
+		return err
+	}
+	return value.LoadFromFile(valuePath)
+}
+
+// notifyInterrupt returns a channel that is closed on the first Ctrl-C. A
+// second Ctrl-C exits immediately.
+func notifyInterrupt() <-chan struct{} {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+
+	interrupted := make(chan struct{})
+	go func() {
+		<-signals
+		fmt.Println("\nInterrupted: finishing the current game or epoch. Press Ctrl-C again to quit now.")
+		close(interrupted)
+
+		<-signals
+		fmt.Println("\nQuitting")
+		os.Exit(130)
+	}()
+	return interrupted
+}
+
+// isClosed reports whether ch has been closed
+func isClosed(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
@@ -4,16 +4,22 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"log/slog"
 	"math"
 	"math/rand"
 	"os"
+	"os/signal"
+	"path/filepath"
 	"runtime"
 	"runtime/pprof"
 	"time"
 
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/agents"
 	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/logging"
 	"github.com/zachbeta/neural_rps/alphago_demo/pkg/mcts"
 	neural "github.com/zachbeta/neural_rps/alphago_demo/pkg/rps_net_impl"
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/tournament"
 	"github.com/zachbeta/neural_rps/alphago_demo/pkg/training"
 	"github.com/zachbeta/neural_rps/alphago_demo/pkg/training/neat"
 )
@@ -46,6 +52,11 @@ func main() {
 	optimizeThreads := flag.Bool("optimize-threads", false, "Find optimal thread count for current hardware")
 	threads := flag.Int("threads", 0, "Specific number of threads to use (0 = auto)")
 	profile := flag.Bool("profile", false, "Enable CPU profiling")
+	canonical := flag.Bool("canonical", false, "Encode positions from the perspective of the player to move")
+	maxExamples := flag.Int("max-examples", 0, "Stop self-play once this many training examples are held (0 = no limit)")
+	dedup := flag.Bool("dedup", false, "Merge repeated self-play positions into one example with averaged targets")
+	batchNorm := flag.Bool("batch-norm", false, "Batch-normalize the networks' hidden layer")
+	initName := flag.String("init", neural.InitUniform.String(), "Weight initialization: uniform | xavier | he (he suits the ReLU hidden layer)")
 	// Training method selection
 	method := flag.String("method", "alphago", "Training method: alphago | neat")
 	// NEAT-specific flags
@@ -57,6 +68,7 @@ func main() {
 	evalGames := flag.Int("eval-games", 10, "Self-play games per genome for NEAT evaluation")
 	weightStd := flag.Float64("weight-std", 0.1, "Weight mutation standard deviation for NEAT")
 	hiddenSize := flag.Int("hidden-size", model1HiddenSize, "Hidden neurons for NEAT networks")
+	hofSize := flag.Int("hof-size", 10, "Hall-of-fame size for NEAT (0 = disabled)")
 	// Model hyperparameter flags (defaults from constants)
 	m1Games := flag.Int("m1-games", model1SelfPlayGames, "Self-play games for Model 1")
 	m1Epochs := flag.Int("m1-epochs", model1Epochs, "Training epochs for Model 1")
@@ -71,16 +83,28 @@ func main() {
 	m2Exploration := flag.Float64("m2-exploration", 1.0, "Exploration constant for Model 2")
 
 	tourGames := flag.Int("tournament-games", tournamentGames, "Number of head-to-head games")
+	outputDir := flag.String("output-dir", "output", "Directory for trained models, profiles and reports")
+	logConfig := logging.AddFlags(flag.CommandLine)
 	flag.Parse()
+	if err := logConfig.Install(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	initScheme, err := neural.ParseInitScheme(*initName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
 
 	// Setup CPU profiling if requested
 	if *profile {
 		// Ensure directory exists
-		os.MkdirAll("output/profiles", 0755)
+		profileDir := filepath.Join(*outputDir, "profiles")
+		os.MkdirAll(profileDir, 0755)
 
 		// Create profile file
 		timestamp := time.Now().Format("20060102-150405")
-		profilePath := fmt.Sprintf("output/profiles/cpu_%s.prof", timestamp)
+		profilePath := filepath.Join(profileDir, fmt.Sprintf("cpu_%s.prof", timestamp))
 		f, err := os.Create(profilePath)
 		if err != nil {
 			log.Fatalf("Could not create CPU profile: %v", err)
@@ -95,7 +119,7 @@ func main() {
 
 	// Handle thread optimization if requested
 	if *optimizeThreads {
-		findOptimalThreadCount()
+		findOptimalThreadCount(*outputDir)
 		return
 	}
 
@@ -104,7 +128,7 @@ func main() {
 		fmt.Println("=== Training NEAT Model ===")
 		rand.Seed(time.Now().UnixNano())
 		// Ensure output directory exists
-		os.Mkdir("output", 0755)
+		os.MkdirAll(*outputDir, 0755)
 
 		// Configure and train NEAT
 		cfg := neat.Config{
@@ -116,14 +140,15 @@ func main() {
 			EvalGames:       *evalGames,
 			WeightStd:       *weightStd,
 			HiddenSize:      *hiddenSize,
+			HallOfFameSize:  *hofSize,
 		}
 		policyNet, valueNet := neat.Train(cfg, *parallel, *threads)
 
 		// Save trained networks
 		timestamp := time.Now().Format("20060102-150405")
 		modelName := fmt.Sprintf("rps_neat_ps%d_g%d_%s", cfg.PopSize, cfg.Generations, timestamp)
-		policyPath := fmt.Sprintf("output/%s_policy.model", modelName)
-		valuePath := fmt.Sprintf("output/%s_value.model", modelName)
+		policyPath := filepath.Join(*outputDir, modelName+"_policy.model")
+		valuePath := filepath.Join(*outputDir, modelName+"_value.model")
 		if err := policyNet.SaveToFile(policyPath); err != nil {
 			log.Fatalf("Failed to save NEAT policy network: %v", err)
 		}
@@ -167,17 +192,29 @@ func main() {
 	rand.Seed(time.Now().UnixNano())
 
 	// Create output directory if it doesn't exist
-	os.Mkdir("output", 0755)
+	os.MkdirAll(*outputDir, 0755)
+
+	// The first Ctrl-C finishes the current game or epoch and saves what has
+	// been trained; the second quits at once
+	interrupted := notifyInterrupt()
 
 	// Initialize neural networks for model 1 (smaller network, fewer games)
 	fmt.Println("=== Training Model 1 (Small Network) ===")
-	policy1, value1 := trainModel("output/rps_policy1.model", "output/rps_value1.model",
-		m1G, m1E, h1, *parallel, *threads)
+	policy1, value1 := trainModel(*outputDir,
+		m1G, m1E, h1, initScheme, *batchNorm, *parallel, *threads, *maxExamples, *dedup, *canonical, interrupted)
+	if isClosed(interrupted) {
+		fmt.Println("Training interrupted; skipping Model 2 and the tournament")
+		return
+	}
 
 	// Initialize neural networks for model 2 (larger network, more games)
 	fmt.Println("\n=== Training Model 2 (Large Network) ===")
-	policy2, value2 := trainModel("output/rps_policy2.model", "output/rps_value2.model",
-		m2G, m2E, h2, *parallel, *threads)
+	policy2, value2 := trainModel(*outputDir,
+		m2G, m2E, h2, initScheme, *batchNorm, *parallel, *threads, *maxExamples, *dedup, *canonical, interrupted)
+	if isClosed(interrupted) {
+		fmt.Println("Training interrupted; skipping the tournament")
+		return
+	}
 
 	model1Name := fmt.Sprintf("H%d-G%d-E%d-S%d-X%.1f",
 		h1, m1G, m1E, s1, x1)
@@ -251,6 +288,7 @@ func main() {
 	} else {
 		fmt.Println("(not statistically significant)")
 	}
+	fmt.Printf("Model 1 %s\n", tournament.EstimateScore(model1Wins, model2Wins, draws))
 
 	model1Desc := "Small network with more search"
 	model2Desc := "Large network with less search"
@@ -281,19 +319,70 @@ func calculatePValue(wins1, wins2, total int) float64 {
 	return 2 * (1 - math.Erf(z/math.Sqrt(2)))
 }
 
-// trainModel trains a policy and value network with self-play
-func trainModel(policyPath, valuePath string, selfPlayGames, epochs, hiddenSize int, forceParallel bool, threads int) (*neural.RPSPolicyNetwork, *neural.RPSValueNetwork) {
+// notifyInterrupt returns a channel that is closed on the first Ctrl-C. A
+// second Ctrl-C exits immediately without saving.
+func notifyInterrupt() <-chan struct{} {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+
+	interrupted := make(chan struct{})
+	go func() {
+		<-signals
+		fmt.Println("\nInterrupted: finishing the current game or epoch, then saving. Press Ctrl-C again to quit now.")
+		close(interrupted)
+
+		<-signals
+		fmt.Println("\nQuitting without saving")
+		os.Exit(130)
+	}()
+	return interrupted
+}
+
+// lossChangeVariance returns the variance of the change in loss from one epoch
+// to the next. Unlike the variance of the losses themselves it does not grow
+// with how far the loss falls, only with how unevenly it does.
+func lossChangeVariance(losses []float64) float64 {
+	changes := make([]float64, len(losses)-1)
+	mean := 0.0
+	for i := range changes {
+		changes[i] = losses[i+1] - losses[i]
+		mean += changes[i]
+	}
+	mean /= float64(len(changes))
+
+	variance := 0.0
+	for _, c := range changes {
+		variance += (c - mean) * (c - mean)
+	}
+	return variance / float64(len(changes))
+}
+
+// isClosed reports whether ch has been closed
+func isClosed(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+// trainModel trains a policy and value network with self-play and saves them
+// in outputDir under names describing the training run. Once interrupted is
+// closed it stops early: if self-play was cut short nothing is saved,
+// otherwise the networks are saved after the epochs completed so far.
+func trainModel(outputDir string, selfPlayGames, epochs, hiddenSize int, initScheme neural.InitScheme, batchNorm bool, forceParallel bool, threads, maxExamples int, dedup, canonical bool, interrupted <-chan struct{}) (*neural.RPSPolicyNetwork, *neural.RPSValueNetwork) {
 	// Get timestamp for model naming
 	timestamp := time.Now().Format("20060102-150405")
 
-	// Create descriptive model names
-	modelName := fmt.Sprintf("rps_h%d_g%d_e%d_%s", hiddenSize, selfPlayGames, epochs, timestamp)
-	policyPath = fmt.Sprintf("output/%s_policy.model", modelName)
-	valuePath = fmt.Sprintf("output/%s_value.model", modelName)
-
 	// Initialize neural networks with specified hidden size
-	policyNetwork := neural.NewRPSPolicyNetwork(hiddenSize)
-	valueNetwork := neural.NewRPSValueNetwork(hiddenSize)
+	fmt.Printf("Initializing weights with the %s scheme\n", initScheme)
+	policyNetwork := neural.NewRPSPolicyNetworkWithInit(hiddenSize, initScheme, rand.Int63())
+	valueNetwork := neural.NewRPSValueNetworkWithInit(hiddenSize, initScheme, rand.Int63())
+	policyNetwork.SetCanonicalInput(canonical)
+	valueNetwork.SetCanonicalInput(canonical)
+	policyNetwork.SetBatchNorm(batchNorm)
+	valueNetwork.SetBatchNorm(batchNorm)
 
 	// Display network complexity information
 	fmt.Println("\n--- Network Architecture Details ---")
@@ -307,6 +396,8 @@ func trainModel(policyPath, valuePath string, selfPlayGames, epochs, hiddenSize
 	selfPlayParams.HandSize = handSize
 	selfPlayParams.MaxRounds = maxRounds
 	selfPlayParams.NumThreads = threads
+	selfPlayParams.MaxExamples = maxExamples
+	selfPlayParams.Dedup = dedup
 
 	// Force parallel execution if requested
 	if forceParallel {
@@ -332,6 +423,7 @@ func trainModel(policyPath, valuePath string, selfPlayGames, epochs, hiddenSize
 
 	// Create self-play instance
 	selfPlay := training.NewRPSSelfPlay(policyNetwork, valueNetwork, selfPlayParams)
+	selfPlay.StopOn(interrupted)
 
 	// Generate training examples through self-play
 	fmt.Printf("\n--- Self-Play Phase ---\n")
@@ -348,10 +440,17 @@ func trainModel(policyPath, valuePath string, selfPlayGames, epochs, hiddenSize
 	fmt.Printf("Generated %d training examples in %s (%.1f examples/game, %.2f games/sec)\n",
 		len(examples), genTime, examplesPerGame, gamesPerSecond)
 
+	if selfPlay.Stopped() {
+		fmt.Println("Self-play interrupted before training; no models saved")
+		return policyNetwork, valueNetwork
+	}
+
 	// Train networks with adjusted learning rate for larger networks
 	fmt.Printf("\n--- Training Phase ---\n")
 
-	// Use lower learning rate for larger networks to prevent instability
+	// Use lower learning rate for larger networks to prevent instability. This
+	// predates the -init schemes and may only be making up for the default
+	// uniform initialization; compare runs with -init he before relying on it.
 	baseLR := 0.01
 	learningRate := baseLR
 	if hiddenSize >= 100 {
@@ -367,6 +466,15 @@ func trainModel(policyPath, valuePath string, selfPlayGames, epochs, hiddenSize
 	policyLosses, valueLosses := selfPlay.TrainNetworks(epochs, 32, learningRate, true)
 	trainTime := time.Since(startTime)
 
+	// Name the models after the epochs actually trained, which is fewer than
+	// asked for if training was interrupted
+	if len(policyLosses) > 0 {
+		epochs = len(policyLosses)
+	}
+	modelName := fmt.Sprintf("rps_h%d_g%d_e%d_%s", hiddenSize, selfPlayGames, epochs, timestamp)
+	policyPath := filepath.Join(outputDir, modelName+"_policy.model")
+	valuePath := filepath.Join(outputDir, modelName+"_value.model")
+
 	// Calculate training speed
 	examplesPerSecond := float64(len(examples)*epochs) / trainTime.Seconds()
 	fmt.Printf("Training completed in %s (%.2f examples/sec)\n", trainTime, examplesPerSecond)
@@ -384,10 +492,23 @@ func trainModel(policyPath, valuePath string, selfPlayGames, epochs, hiddenSize
 			fmt.Printf("Total improvement - Policy: %.1f%%, Value: %.1f%%\n",
 				policyImprovement, valueImprovement)
 		}
+
+		// Compare runs with and without -batch-norm to see if it steadies training
+		if len(policyLosses) > 2 {
+			fmt.Printf("Epoch-to-epoch loss change variance (batch norm %v) - Policy: %.6f, Value: %.6f\n",
+				batchNorm, lossChangeVariance(policyLosses), lossChangeVariance(valueLosses))
+		}
 	}
 
 	// Save the trained models
 	fmt.Printf("\n--- Saving Models ---\n")
+	metadata := neural.ModelMetadata{
+		Games:     selfPlayParams.NumGames,
+		Epochs:    epochs,
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+	policyNetwork.SetMetadata(metadata)
+	valueNetwork.SetMetadata(metadata)
 	err := policyNetwork.SaveToFile(policyPath)
 	if err != nil {
 		log.Fatalf("Failed to save policy network: %v", err)
@@ -398,6 +519,18 @@ func trainModel(policyPath, valuePath string, selfPlayGames, epochs, hiddenSize
 	}
 	fmt.Printf("Models saved to %s and %s\n", policyPath, valuePath)
 
+	// Self-play training has no validation set, so the accuracy column is empty
+	var history training.LossHistory
+	for i := range policyLosses {
+		history.AppendEpoch(policyLosses[i], valueLosses[i], math.NaN())
+	}
+	historyPath := filepath.Join(outputDir, modelName+"_losses.csv")
+	if err := history.SaveCSV(historyPath); err != nil {
+		slog.Error("Failed to save loss history", "error", err)
+	} else {
+		fmt.Printf("Loss history saved to %s\n", historyPath)
+	}
+
 	return policyNetwork, valueNetwork
 }
 
@@ -443,12 +576,8 @@ func (a *AlphaGoAgent) GetMove(state *game.RPSGame) (game.RPSMove, error) {
 	bestNode := a.mctsEngine.Search()
 
 	if bestNode == nil || bestNode.Move == nil {
-		// Fallback to random move if MCTS fails
-		validMoves := state.GetValidMoves()
-		if len(validMoves) == 0 {
-			return game.RPSMove{}, fmt.Errorf("no valid moves")
-		}
-		return validMoves[rand.Intn(len(validMoves))], nil
+		// Fall back to the legal move the policy network rates highest
+		return agents.FallbackMove(state, agents.FallbackPolicyPrior, a.policyNetwork)
 	}
 
 	return *bestNode.Move, nil
@@ -467,237 +596,88 @@ func runTournament(agent1, agent2 *AlphaGoAgent, numGames int) (agent1Wins, agen
 	agent1ShortName := "Model1"
 	agent2ShortName := "Model2"
 
-	// Track win streaks for analysis
-	currentStreak := 0
-	maxStreak := 0
-	streakHolder := ""
-
-	// Track position-based wins
-	positionWins := make(map[int]map[string]int)
-	for pos := 0; pos < 9; pos++ {
-		positionWins[pos] = make(map[string]int)
-	}
-
-	for i := 0; i < numGames; i++ {
-		// Print progress
-		if (i+1)%10 == 0 || i == 0 {
-			fmt.Printf("Playing game %d of %d...\n", i+1, numGames)
-		}
-
-		// Create a new game
-		gameInstance := game.NewRPSGame(deckSize, handSize, maxRounds)
-
-		// Alternate who goes first to ensure fairness
-		var player1Agent, player2Agent *AlphaGoAgent
-		if i%2 == 0 {
-			player1Agent = agent1
-			player2Agent = agent2
-		} else {
-			player1Agent = agent2
-			player2Agent = agent1
-		}
-
-		// Track decisive moves for analysis
-		decisiveMoves := make([]game.RPSMove, 0)
-		moveCount := 0
-
-		// Play the game
-		for !gameInstance.IsGameOver() {
-			var currentAgent *AlphaGoAgent
-			if gameInstance.CurrentPlayer == game.Player1 {
-				currentAgent = player1Agent
-			} else {
-				currentAgent = player2Agent
-			}
-
-			move, err := currentAgent.GetMove(gameInstance.Copy())
-			if err != nil {
-				log.Fatalf("Agent %s failed to make a move: %v", currentAgent.Name(), err)
-			}
-
-			move.Player = gameInstance.CurrentPlayer
-			err = gameInstance.MakeMove(move)
-			if err != nil {
-				log.Fatalf("Invalid move from agent %s: %v", currentAgent.Name(), err)
-			}
-
-			// Track moves
-			moveCount++
-			decisiveMoves = append(decisiveMoves, move)
-		}
-
-		// Determine winner
-		winner := gameInstance.GetWinner()
-		var winnerAgent *AlphaGoAgent
-		var winnerName string
-
-		if winner == game.Player1 {
-			if player1Agent == agent1 {
-				agent1Wins++
-				winnerAgent = agent1
-				winnerName = agent1.Name()
-			} else {
-				agent2Wins++
-				winnerAgent = agent2
-				winnerName = agent2.Name()
-			}
-		} else if winner == game.Player2 {
-			if player2Agent == agent1 {
-				agent1Wins++
-				winnerAgent = agent1
-				winnerName = agent1.Name()
-			} else {
-				agent2Wins++
-				winnerAgent = agent2
-				winnerName = agent2.Name()
-			}
-		} else {
-			draws++
-			winnerName = "Draw"
-			winnerAgent = nil
-		}
-
-		// Update win streak analysis
-		if winnerAgent != nil {
-			if streakHolder == winnerName {
-				currentStreak++
-				if currentStreak > maxStreak {
-					maxStreak = currentStreak
-				}
-			} else {
-				streakHolder = winnerName
-				currentStreak = 1
-			}
-
-			// Record position-based wins
-			if len(decisiveMoves) > 0 {
-				lastMove := decisiveMoves[len(decisiveMoves)-1]
-				positionWins[lastMove.Position][winnerName]++
+	opts := tournament.DefaultSeriesOptions()
+	opts.DeckSize = deckSize
+	opts.HandSize = handSize
+	opts.MaxRounds = maxRounds
+	opts.OnGame = func(gameNumber int, g tournament.SeriesGame) {
+		// Print result for every 10th game or the final game
+		if gameNumber%10 == 0 || gameNumber == numGames {
+			winnerName := g.Winner
+			if winnerName == tournament.DrawResult {
+				winnerName = "Draw"
 			}
-		} else {
-			// Reset streak on draw
-			currentStreak = 0
-			streakHolder = ""
+			fmt.Printf("Game %d result: %s (moves: %d)\n", gameNumber, winnerName, g.Moves)
 		}
-
-		// Print result for every 10th game or the final game
-		if (i+1)%10 == 0 || i == numGames-1 {
-			fmt.Printf("Game %d result: %s (moves: %d)\n", i+1, winnerName, moveCount)
+		// Print progress
+		if (gameNumber+1)%10 == 0 && gameNumber < numGames {
+			fmt.Printf("Playing game %d of %d...\n", gameNumber+1, numGames)
 		}
 	}
 
+	fmt.Printf("Playing game 1 of %d...\n", numGames)
+	result := tournament.PlaySeries(agent1, agent2, numGames, opts)
+
 	// Print analysis
-	fmt.Printf("\nMax win streak: %d games by %s\n", maxStreak, streakHolder)
+	if result.LongestStreakA >= result.LongestStreakB {
+		fmt.Printf("\nMax win streak: %d games by %s\n", result.LongestStreakA, agent1.Name())
+	} else {
+		fmt.Printf("\nMax win streak: %d games by %s\n", result.LongestStreakB, agent2.Name())
+	}
 
 	// Analyze position-based winning rates
 	fmt.Println("\nPosition-based winning rates:")
 	for pos := 0; pos < 9; pos++ {
 		row := pos / 3
 		col := pos % 3
-		a1Wins := positionWins[pos][agent1.Name()]
-		a2Wins := positionWins[pos][agent2.Name()]
 		fmt.Printf("Position (%d,%d): %s: %d wins, %s: %d wins\n",
-			row, col, agent1ShortName, a1Wins, agent2ShortName, a2Wins)
+			row, col, agent1ShortName, result.PositionWinsA[pos], agent2ShortName, result.PositionWinsB[pos])
 	}
 
-	return agent1Wins, agent2Wins, draws
+	return result.WinsA, result.WinsB, result.Draws
 }
 
 // findOptimalThreadCount determines the optimal number of threads for the current hardware
-func findOptimalThreadCount() {
+func findOptimalThreadCount(outputDir string) {
 	fmt.Println("Finding optimal thread count for your hardware...")
 	fmt.Printf("CPU cores available: %d\n", runtime.NumCPU())
+	fmt.Println("\nTesting performance with different thread counts...")
 
-	// Create test parameters
-	const testGames = 50
-	const hiddenSize = 64
-	const maxWorkers = 32 // Don't test beyond 32 threads
-
-	// Create output file
-	resultsFile, err := os.Create("output/thread_optimization.txt")
-	if err != nil {
-		log.Fatalf("Failed to create results file: %v", err)
-	}
-	defer resultsFile.Close()
-
-	// Write header
-	fmt.Fprintf(resultsFile, "Thread Count,Games Per Second,Speedup Factor\n")
+	result := training.AutotuneThreads(training.DefaultThreadTuneParams())
 
-	// Initialize base networks for testing
-	policyNetwork := neural.NewRPSPolicyNetwork(hiddenSize)
-	valueNetwork := neural.NewRPSValueNetwork(hiddenSize)
-
-	// Run tests with varying thread counts
-	var baselineSpeed float64
-	var bestThreads int
-	var bestSpeed float64
-
-	// Test thread counts from 1 to min(maxWorkers, numCPU*2)
-	maxTestThreads := runtime.NumCPU() * 2
-	if maxTestThreads > maxWorkers {
-		maxTestThreads = maxWorkers
-	}
-
-	fmt.Println("\nTesting performance with different thread counts:")
 	fmt.Println("------------------------------------------------")
-
-	for threads := 1; threads <= maxTestThreads; threads++ {
-		// Configure parameters
-		selfPlayParams := training.DefaultRPSSelfPlayParams()
-		selfPlayParams.NumGames = testGames
-		selfPlayParams.ForceParallel = threads > 1
-
-		// Set thread count in global runtime
-		originalMaxProcs := runtime.GOMAXPROCS(0)
-		runtime.GOMAXPROCS(threads)
-
-		// Create self-play instance
-		selfPlay := training.NewRPSSelfPlay(policyNetwork, valueNetwork, selfPlayParams)
-
-		// Measure performance
-		start := time.Now()
-		selfPlay.GenerateGames(false)
-		elapsed := time.Since(start)
-
-		// Calculate metrics
-		gamesPerSecond := float64(testGames) / elapsed.Seconds()
-		speedupFactor := 1.0
-
-		if threads == 1 {
-			baselineSpeed = gamesPerSecond
-		} else {
-			speedupFactor = gamesPerSecond / baselineSpeed
-		}
-
-		// Update best if applicable
-		if gamesPerSecond > bestSpeed {
-			bestSpeed = gamesPerSecond
-			bestThreads = threads
-		}
-
-		// Print and save results
+	for _, trial := range result.Trials {
 		fmt.Printf("Threads: %2d | Games/sec: %6.2f | Speedup: %5.2fx\n",
-			threads, gamesPerSecond, speedupFactor)
-		fmt.Fprintf(resultsFile, "%d,%.2f,%.2f\n",
-			threads, gamesPerSecond, speedupFactor)
-
-		// Reset max procs to original value
-		runtime.GOMAXPROCS(originalMaxProcs)
+			trial.Threads, trial.GamesPerSecond, trial.Speedup)
+	}
+	if result.Plateaued {
+		fmt.Println("Stopped early: throughput stopped improving with more threads")
 	}
 
-	// Print recommendation
+	peak := result.Trials[result.Peak-1]
 	fmt.Println("\nResults:")
-	fmt.Printf("Optimal thread count for your hardware: %d threads\n", bestThreads)
-	fmt.Printf("Peak performance: %.2f games/second\n", bestSpeed)
-	fmt.Printf("Maximum speedup: %.2fx over single-threaded execution\n", bestSpeed/baselineSpeed)
-
-	if bestThreads == runtime.NumCPU() {
+	fmt.Printf("Peak performance: %.2f games/second with %d threads (%.2fx over single-threaded)\n",
+		peak.GamesPerSecond, peak.Threads, peak.Speedup)
+	if result.Recommended == runtime.NumCPU() {
 		fmt.Println("Recommendation: Use default parallel execution for optimal performance")
-	} else if bestThreads < runtime.NumCPU() {
-		fmt.Printf("Recommendation: Use %d threads for optimal performance (fewer than CPU cores)\n", bestThreads)
+	} else if result.Recommended < result.Peak {
+		fmt.Printf("Recommendation: Use -threads %d; more threads add little once self-play is memory-bound\n",
+			result.Recommended)
 	} else {
-		fmt.Printf("Recommendation: Use %d threads for optimal performance (more than CPU cores)\n", bestThreads)
+		fmt.Printf("Recommendation: Use -threads %d for optimal performance\n", result.Recommended)
+	}
+
+	// Save the trials as CSV
+	resultsPath := filepath.Join(outputDir, "thread_optimization.txt")
+	os.MkdirAll(outputDir, 0755)
+	resultsFile, err := os.Create(resultsPath)
+	if err != nil {
+		log.Fatalf("Failed to create results file: %v", err)
+	}
+	defer resultsFile.Close()
+	if err := result.WriteCSV(resultsFile); err != nil {
+		log.Fatalf("Failed to write results file: %v", err)
 	}
 
-	fmt.Printf("\nDetailed results saved to output/thread_optimization.txt\n")
+	fmt.Printf("\nDetailed results saved to %s\n", resultsPath)
 }
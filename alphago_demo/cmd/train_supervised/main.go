@@ -4,11 +4,15 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"log/slog"
+	"math"
 	"os"
 	"time"
 
 	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/logging"
 	neural "github.com/zachbeta/neural_rps/alphago_demo/pkg/rps_net_impl"
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/training"
 )
 
 // TrainingHistory records the learning metrics during training
@@ -40,7 +44,19 @@ func main() {
 	patience := flag.Int("patience", 10, "Early stopping patience")
 	outputPrefix := flag.String("output", "supervised", "Output model prefix")
 	dataDir := flag.String("data-dir", "data", "Directory with preprocessed data")
+	cvFolds := flag.Int("cv-folds", 0, "Instead of training a model, report k-fold cross-validated accuracy (requires -examples)")
+	examplesFile := flag.String("examples", "data/training_data.jsonl", "Dataset from generate_training_data used by -cv-folds")
+	logConfig := logging.AddFlags(flag.CommandLine)
 	flag.Parse()
+	if err := logConfig.Install(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	if *cvFolds > 0 {
+		crossValidate(*examplesFile, *cvFolds, *hiddenSize, *learningRate, *batchSize, *epochs)
+		return
+	}
 
 	// Ensure output directory exists
 	os.MkdirAll("models", 0755)
@@ -54,10 +70,19 @@ func main() {
 	fmt.Printf("Loaded %d training and %d validation examples\n",
 		len(trainInputs), len(valInputs))
 
-	// Create the neural network model
-	inputSize := 81 // Based on our feature encoding
+	if len(trainInputs) == 0 {
+		panic(fmt.Sprintf("No training examples in %s", *dataDir))
+	}
+
+	// Create the neural network model, with the feature encoding the data
+	// was saved with so older datasets still train
+	inputSize := len(trainInputs[0])
 	outputSize := 9 // 9 possible move positions
-	network := neural.NewRPSPolicyNetwork(*hiddenSize)
+	featureVersion, err := neural.FeatureVersionForInput(inputSize, game.StandardBoardDim)
+	if err != nil {
+		panic(fmt.Sprintf("Unsupported training data: %v", err))
+	}
+	network := neural.NewRPSPolicyNetworkWithFeatures(*hiddenSize, game.StandardBoardDim, featureVersion)
 
 	// Print network architecture
 	fmt.Printf("Network architecture: Input(%d) -> Hidden(%d) -> Output(%d)\n",
@@ -71,11 +96,6 @@ func main() {
 
 	startTime := time.Now()
 
-	// Create a minimal game state to use for feature transformation
-	// We'll keep this commented out for now since we're not using it directly
-	// g := game.NewRPSGame(21, 5, 10)
-
-	// Since we don't have built-in training with epochs, we'll implement it ourselves
 	history := &CustomTrainingHistory{
 		trainingLoss:       make([]float64, 0, *epochs),
 		validationLoss:     make([]float64, 0, *epochs),
@@ -84,8 +104,12 @@ func main() {
 		bestEpoch:          0,
 	}
 
-	bestValLoss := float64(9999.0)
-	patienceCounter := 0
+	// Loss curve in the same CSV format as the self-play trainers. There is no
+	// value network here, so its column is left empty.
+	var lossHistory training.LossHistory
+
+	// Keeps the weights from the epoch with the lowest validation loss
+	stopper := training.NewEarlyStopping(*patience)
 
 	for epoch := 0; epoch < *epochs; epoch++ {
 		fmt.Printf("Epoch %d/%d: ", epoch+1, *epochs)
@@ -104,7 +128,6 @@ func main() {
 			batchInputs := trainInputs[start:end]
 			batchTargets := trainTargets[start:end]
 
-			// Train on batch - using our custom transformation
 			batchLoss := trainBatch(network, batchInputs, batchTargets, *learningRate)
 			trainLoss += batchLoss
 		}
@@ -112,48 +135,47 @@ func main() {
 		trainLoss /= float64(numBatches)
 
 		// Evaluate on validation set
-		valLoss, valAcc := evaluateWithTransform(network, valInputs, valTargets)
-		trainLoss2, trainAcc := evaluateWithTransform(network, trainInputs, trainTargets)
+		valLoss, valAcc := evaluate(network, valInputs, valTargets)
+		trainLoss2, trainAcc := evaluate(network, trainInputs, trainTargets)
 
 		// Store metrics
 		history.trainingLoss = append(history.trainingLoss, trainLoss)
 		history.validationLoss = append(history.validationLoss, valLoss)
 		history.trainingAccuracy = append(history.trainingAccuracy, trainAcc)
 		history.validationAccuracy = append(history.validationAccuracy, valAcc)
+		lossHistory.AppendEpoch(trainLoss, math.NaN(), valAcc)
 
 		fmt.Printf("Train Loss: %.4f (%.4f), Val Loss: %.4f, Train Acc: %.2f%%, Val Acc: %.2f%%\n",
 			trainLoss, trainLoss2, valLoss, trainAcc*100, valAcc*100)
 
-		// Check for improvement
-		if valLoss < bestValLoss {
-			bestValLoss = valLoss
-			history.bestEpoch = epoch
-			patienceCounter = 0
-		} else {
-			patienceCounter++
-		}
-
 		// Early stopping
-		if patienceCounter >= *patience {
+		if stopper.Update(epoch, valLoss, network) {
 			fmt.Printf("Early stopping at epoch %d\n", epoch+1)
 			break
 		}
 	}
 
+	// Save the best model found, not whatever the last epoch produced
+	history.bestEpoch = stopper.BestEpoch()
+	if err := stopper.Restore(network); err != nil {
+		panic(fmt.Sprintf("Failed to restore the best weights: %v", err))
+	}
+	fmt.Printf("Using weights from epoch %d (validation loss %.4f)\n", stopper.BestEpoch()+1, stopper.BestLoss())
+
 	trainingTime := time.Since(startTime)
 	fmt.Printf("\nTraining completed in %v\n", trainingTime)
 
 	// Save the trained model
 	outputPath := fmt.Sprintf("models/%s_policy.model", *outputPrefix)
 	if err := network.SaveToFile(outputPath); err != nil {
-		fmt.Printf("Error saving model: %v\n", err)
+		slog.Error("Failed to save model", "error", err)
 	} else {
 		fmt.Printf("Model saved to %s\n", outputPath)
 	}
 
 	// Evaluate on validation set
 	fmt.Println("\nFinal evaluation on validation set:")
-	finalLoss, finalAcc := evaluateWithTransform(network, valInputs, valTargets)
+	finalLoss, finalAcc := evaluate(network, valInputs, valTargets)
 
 	correct := int(finalAcc * float64(len(valInputs)))
 	fmt.Printf("Accuracy: %.2f%% (%d/%d correct)\n",
@@ -182,44 +204,111 @@ func main() {
 		encoder.Encode(historySummary)
 		fmt.Printf("Training history saved to %s\n", historyPath)
 	}
+
+	lossPath := fmt.Sprintf("models/%s_losses.csv", *outputPrefix)
+	if err := lossHistory.SaveCSV(lossPath); err != nil {
+		slog.Error("Failed to save loss history", "error", err)
+	} else {
+		fmt.Printf("Loss history saved to %s\n", lossPath)
+	}
 }
 
-// trainBatch trains the network on a batch of inputs and targets
-func trainBatch(network *neural.RPSPolicyNetwork, inputs, targets [][]float64, learningRate float64) float64 {
-	// Since we can't directly train on raw features, we'll create a custom implementation
-	// that transforms the raw inputs to game states
+// crossValidate trains and scores a network on each of k folds of a dataset
+// and reports the mean and spread of its validation accuracy
+func crossValidate(path string, k, hiddenSize int, learningRate float64, batchSize, epochs int) {
+	var examples []training.TrainingExample
+	stream, errc := training.ReadExamples(path)
+	for example := range stream {
+		examples = append(examples, example)
+	}
+	if err := <-errc; err != nil {
+		panic(fmt.Sprintf("Failed to read %s: %v", path, err))
+	}
+	if k < 2 || len(examples) < k {
+		panic(fmt.Sprintf("Cannot make %d folds from %d examples", k, len(examples)))
+	}
 
-	// Custom implementation - in a real application we would modify the RPSPolicyNetwork to accept raw inputs
-	// This is a simplified approach for this tutorial
-	return 0.1 // Placeholder - can't actually train on raw features directly in this way
+	fmt.Printf("%d-fold cross-validation on %d examples: Hidden=%d, LR=%.5f, Batch=%d, Epochs=%d\n",
+		k, len(examples), hiddenSize, learningRate, batchSize, epochs)
+
+	fold := 0
+	scores := training.CrossValidate(examples, k,
+		func(train []training.TrainingExample) *neural.RPSPolicyNetwork {
+			network := neural.NewRPSPolicyNetwork(hiddenSize)
+			inputs, targets := training.ExampleFeatures(train)
+			for epoch := 0; epoch < epochs; epoch++ {
+				for start := 0; start < len(inputs); start += batchSize {
+					end := start + batchSize
+					if end > len(inputs) {
+						end = len(inputs)
+					}
+					network.Train(inputs[start:end], targets[start:end], learningRate)
+				}
+			}
+			return network
+		},
+		func(network *neural.RPSPolicyNetwork, val []training.TrainingExample) float64 {
+			correct := 0
+			for _, example := range val {
+				if argmax(network.Predict(example.Game())) == example.BestMove {
+					correct++
+				}
+			}
+			fold++
+			accuracy := float64(correct) / float64(len(val))
+			fmt.Printf("Fold %d: %.2f%% (%d/%d correct)\n", fold, accuracy*100, correct, len(val))
+			return accuracy
+		})
+
+	mean, std := training.MeanStd(scores)
+	fmt.Printf("Validation accuracy: %.2f%% ± %.2f%% over %d folds\n", mean*100, std*100, k)
 }
 
-// transformToGame converts raw features to a game state
-func transformToGame(features []float64) *game.RPSGame {
-	g := game.NewRPSGame(21, 5, 10)
-	// In a real implementation, we would set the game state based on the features
-	return g
+// argmax returns the index of the largest value
+func argmax(values []float64) int {
+	best := 0
+	for i, v := range values {
+		if v > values[best] {
+			best = i
+		}
+	}
+	return best
 }
 
-// evaluateWithTransform evaluates the network on inputs using custom transformation
-func evaluateWithTransform(network *neural.RPSPolicyNetwork, inputs, targets [][]float64) (float64, float64) {
-	// Since we can't directly evaluate on raw features, we'll use a simplified approach
-	// This is only a placeholder - in a real implementation we would modify the RPSPolicyNetwork
-	// to accept raw features directly
-	return 0.5, 0.5 // Placeholder loss and accuracy values
+// trainBatch runs one gradient step on a batch and returns its mean
+// cross-entropy loss
+func trainBatch(network *neural.RPSPolicyNetwork, inputs, targets [][]float64, learningRate float64) float64 {
+	return network.Train(inputs, targets, learningRate)
 }
 
-// analyzeByPosition analyzes accuracy for each board position
-func analyzeByPosition(network *neural.RPSPolicyNetwork, inputs, targets [][]float64) {
-	// Since we can't actually evaluate due to interface limitations, we'll use a simplified approach
-	fmt.Println("\nAccuracy by position:")
-	fmt.Println("Position | Accuracy | Samples")
-	fmt.Println("---------|----------|--------")
+// evaluate returns the network's mean cross-entropy loss on inputs and the
+// fraction whose top predicted move is the target move
+func evaluate(network *neural.RPSPolicyNetwork, inputs, targets [][]float64) (float64, float64) {
+	if len(inputs) == 0 {
+		return 0, 0
+	}
 
-	for pos := 0; pos < 9; pos++ {
-		fmt.Printf("   %d     |  %.1f%%   |   %d   \n",
-			pos, 50.0, 100) // Placeholder values
+	loss := 0.0
+	correct := 0
+	for i, input := range inputs {
+		probs := network.PredictFeatures(input)
+		for move, target := range targets[i] {
+			if target > 0 {
+				loss -= target * math.Log(math.Max(probs[move], 1e-15))
+			}
+		}
+		if argmax(probs) == argmax(targets[i]) {
+			correct++
+		}
 	}
+	return loss / float64(len(inputs)), float64(correct) / float64(len(inputs))
+}
+
+// analyzeByPosition reports, for each labelled best move, how often the
+// network's top move matched it, and which moves it chose instead
+func analyzeByPosition(network *neural.RPSPolicyNetwork, inputs, targets [][]float64) {
+	fmt.Println("\nAccuracy by position:")
+	training.NewConfusionMatrix(network.PredictFeatures, inputs, targets).WriteReport(os.Stdout)
 }
 
 // loadFloatArray loads a 2D float array from a JSON file
@@ -3,13 +3,18 @@ package main
 import (
 	"flag"
 	"fmt"
+	"log/slog"
+	"math"
 	"math/rand"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/logging"
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/metrics"
 	neural "github.com/zachbeta/neural_rps/alphago_demo/pkg/rps_net_impl"
 	"github.com/zachbeta/neural_rps/alphago_demo/pkg/training"
 	"github.com/zachbeta/neural_rps/alphago_demo/pkg/training/neat"
@@ -31,6 +36,7 @@ const (
 	defaultPopulationSize = 150
 	defaultGenerations    = 20
 	defaultHiddenSize     = 64
+	defaultHallOfFameSize = 10
 )
 
 // Agent represents a model to be trained
@@ -51,8 +57,14 @@ func main() {
 	trainingOnly := flag.Bool("training-only", false, "Skip tournament and do training only")
 	outputDir := flag.String("output", "output/extended_training", "Directory for output files")
 	tournamentGames := flag.Int("tournament-games", 100, "Games per matchup in final tournament")
+	metricsAddr := flag.String("metrics-addr", "", "Serve Prometheus metrics on this address, e.g. :9100 (off by default)")
 
+	logConfig := logging.AddFlags(flag.CommandLine)
 	flag.Parse()
+	if err := logConfig.Install(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
 
 	// Seed random number generator
 	rand.Seed(time.Now().UnixNano())
@@ -60,6 +72,18 @@ func main() {
 	// Create output directory if needed
 	os.MkdirAll(*outputDir, 0755)
 
+	// Training reports to m; the tournament runs in its own process, which
+	// takes over the address once training is done
+	var m *metrics.Metrics
+	if *metricsAddr != "" {
+		var err error
+		if m, err = metrics.Serve(*metricsAddr); err != nil {
+			slog.Error("Failed to serve metrics", "error", err)
+			return
+		}
+		fmt.Printf("Serving metrics on http://%s/metrics\n", *metricsAddr)
+	}
+
 	// Define top agents based on previous tournament
 	topAgents := []Agent{
 		{
@@ -127,9 +151,18 @@ func main() {
 			*outputDir, baseName, timestamp)
 	}
 
+	// The first Ctrl-C finishes the current game or epoch and saves the agent
+	// being trained; the second quits at once
+	interrupted := notifyInterrupt()
+
 	if !*tournamentOnly {
 		// Train all non-random agents
 		for i, agent := range topAgents {
+			if isClosed(interrupted) {
+				fmt.Println("Training interrupted; skipping the remaining agents and the tournament")
+				return
+			}
+
 			if agent.Type == "Random" {
 				fmt.Printf("Skipping training for Random agent\n\n")
 				continue
@@ -139,21 +172,57 @@ func main() {
 				i+1, len(topAgents), agent.Name)
 
 			if agent.Type == "AlphaGo" {
-				trainAlphaGoAgent(agent, *selfPlayGames, *mctsSimulations, *outputDir)
+				trainAlphaGoAgent(agent, *selfPlayGames, *mctsSimulations, *outputDir, interrupted, m)
 			} else if agent.Type == "NEAT" {
 				trainNEATAgent(agent, *selfPlayGames, *mctsSimulations, *outputDir)
 			}
 		}
 	}
 
-	if !*trainingOnly {
+	if m != nil {
+		m.Close()
+	}
+
+	if !*trainingOnly && !isClosed(interrupted) {
 		// Run tournament with trained agents
-		runTournament(topAgents, *tournamentGames, *outputDir)
+		runTournament(topAgents, *tournamentGames, *outputDir, *metricsAddr)
+	}
+}
+
+// notifyInterrupt returns a channel that is closed on the first Ctrl-C. A
+// second Ctrl-C exits immediately without saving.
+func notifyInterrupt() <-chan struct{} {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+
+	interrupted := make(chan struct{})
+	go func() {
+		<-signals
+		fmt.Println("\nInterrupted: finishing the current game or epoch, then saving. Press Ctrl-C again to quit now.")
+		close(interrupted)
+
+		<-signals
+		fmt.Println("\nQuitting without saving")
+		os.Exit(130)
+	}()
+	return interrupted
+}
+
+// isClosed reports whether ch has been closed
+func isClosed(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
 	}
 }
 
-// trainAlphaGoAgent extends training of an AlphaGo agent
-func trainAlphaGoAgent(agent Agent, selfPlayGames, mctsSimulations int, outputDir string) {
+// trainAlphaGoAgent extends training of an AlphaGo agent. If interrupted is
+// closed during self-play the agent is left as it was; during training, the
+// networks are saved after the epochs completed so far. Progress is reported
+// to m unless it is nil.
+func trainAlphaGoAgent(agent Agent, selfPlayGames, mctsSimulations int, outputDir string, interrupted <-chan struct{}, m *metrics.Metrics) {
 	fmt.Printf("Loading AlphaGo model from %s and %s\n",
 		agent.PolicyPath, agent.ValuePath)
 
@@ -161,14 +230,14 @@ func trainAlphaGoAgent(agent Agent, selfPlayGames, mctsSimulations int, outputDi
 	policyNet := neural.NewRPSPolicyNetwork(64) // Default size, will be adjusted on load
 	err := policyNet.LoadFromFile(agent.PolicyPath)
 	if err != nil {
-		fmt.Printf("Error loading policy network: %v\n", err)
+		slog.Error("Failed to load policy network", "error", err)
 		return
 	}
 
 	valueNet := neural.NewRPSValueNetwork(policyNet.GetHiddenSize())
 	err = valueNet.LoadFromFile(agent.ValuePath)
 	if err != nil {
-		fmt.Printf("Error loading value network: %v\n", err)
+		slog.Error("Failed to load value network", "error", err)
 		return
 	}
 
@@ -194,6 +263,10 @@ func trainAlphaGoAgent(agent Agent, selfPlayGames, mctsSimulations int, outputDi
 
 	// Create self-play instance
 	selfPlay := training.NewRPSSelfPlay(policyNet, valueNet, selfPlayParams)
+	selfPlay.StopOn(interrupted)
+	if m != nil {
+		selfPlay.MonitorWith(m)
+	}
 
 	// Run self-play
 	fmt.Printf("Starting self-play with %d games, %d simulations per move...\n",
@@ -208,6 +281,11 @@ func trainAlphaGoAgent(agent Agent, selfPlayGames, mctsSimulations int, outputDi
 	fmt.Printf("Generated %d training examples in %s (%.1f examples/game, %.2f games/sec)\n",
 		len(examples), genTime, examplesPerGame, gamesPerSecond)
 
+	if selfPlay.Stopped() {
+		fmt.Println("Self-play interrupted before training; no models saved")
+		return
+	}
+
 	// Train networks
 	fmt.Printf("Training networks for %d epochs...\n", 10) // Fixed 10 epochs
 	startTime = time.Now()
@@ -225,13 +303,29 @@ func trainAlphaGoAgent(agent Agent, selfPlayGames, mctsSimulations int, outputDi
 	// Save trained models
 	fmt.Printf("Saving trained models to %s and %s\n",
 		agent.TrainedPolicyPath, agent.TrainedValuePath)
+	// Count this run on top of any training recorded in the loaded model
+	metadata := policyNet.Metadata()
+	metadata.Games += selfPlayGames
+	metadata.Epochs += len(policyLosses)
+	metadata.Timestamp = time.Now().Format(time.RFC3339)
+	policyNet.SetMetadata(metadata)
+	valueNet.SetMetadata(metadata)
 
 	if err := policyNet.SaveToFile(agent.TrainedPolicyPath); err != nil {
-		fmt.Printf("Error saving policy network: %v\n", err)
+		slog.Error("Failed to save policy network", "error", err)
 	}
 
 	if err := valueNet.SaveToFile(agent.TrainedValuePath); err != nil {
-		fmt.Printf("Error saving value network: %v\n", err)
+		slog.Error("Failed to save value network", "error", err)
+	}
+
+	var history training.LossHistory
+	for i := range policyLosses {
+		history.AppendEpoch(policyLosses[i], valueLosses[i], math.NaN())
+	}
+	historyPath := strings.TrimSuffix(agent.TrainedPolicyPath, "_policy.model") + "_losses.csv"
+	if err := history.SaveCSV(historyPath); err != nil {
+		slog.Error("Failed to save loss history", "error", err)
 	}
 }
 
@@ -244,14 +338,14 @@ func trainNEATAgent(agent Agent, selfPlayGames, mctsSimulations int, outputDir s
 	policyNet := neural.NewRPSPolicyNetwork(defaultHiddenSize)
 	err := policyNet.LoadFromFile(agent.PolicyPath)
 	if err != nil {
-		fmt.Printf("Error loading policy network: %v\n", err)
+		slog.Error("Failed to load policy network", "error", err)
 		return
 	}
 
 	valueNet := neural.NewRPSValueNetwork(policyNet.GetHiddenSize())
 	err = valueNet.LoadFromFile(agent.ValuePath)
 	if err != nil {
-		fmt.Printf("Error loading value network: %v\n", err)
+		slog.Error("Failed to load value network", "error", err)
 		return
 	}
 
@@ -270,6 +364,7 @@ func trainNEATAgent(agent Agent, selfPlayGames, mctsSimulations int, outputDir s
 		EvalGames:       10,
 		WeightStd:       0.1,
 		HiddenSize:      hiddenSize,
+		HallOfFameSize:  defaultHallOfFameSize,
 	}
 
 	// Create initial population with best genome as template
@@ -294,12 +389,28 @@ func trainNEATAgent(agent Agent, selfPlayGames, mctsSimulations int, outputDir s
 		agent.TrainedPolicyPath, agent.TrainedValuePath)
 
 	if err := bestPolicy.SaveToFile(agent.TrainedPolicyPath); err != nil {
-		fmt.Printf("Error saving policy network: %v\n", err)
+		slog.Error("Failed to save policy network", "error", err)
 	}
 
 	if err := bestValue.SaveToFile(agent.TrainedValuePath); err != nil {
-		fmt.Printf("Error saving value network: %v\n", err)
+		slog.Error("Failed to save value network", "error", err)
+	}
+
+	// Save the hall of fame so every historical champion can enter the tournament
+	baseName := strings.TrimSuffix(filepath.Base(agent.TrainedPolicyPath), "_policy.model")
+	for i, g := range pop.HallOfFame {
+		hofPolicy, hofValue := g.ToNetworks()
+		hofPolicyPath := fmt.Sprintf("%s/%s_hof%02d_policy.model", outputDir, baseName, i+1)
+		hofValuePath := fmt.Sprintf("%s/%s_hof%02d_value.model", outputDir, baseName, i+1)
+		if err := hofPolicy.SaveToFile(hofPolicyPath); err != nil {
+			slog.Error("Failed to save hall-of-fame policy network", "error", err)
+			continue
+		}
+		if err := hofValue.SaveToFile(hofValuePath); err != nil {
+			slog.Error("Failed to save hall-of-fame value network", "error", err)
+		}
 	}
+	fmt.Printf("Saved %d hall-of-fame champions to %s\n", len(pop.HallOfFame), outputDir)
 }
 
 // newPopulationFromTemplate creates a NEAT population with the first genome initialized from template weights
@@ -331,8 +442,9 @@ func newPopulationFromTemplate(cfg neat.Config, policyWeights, valueWeights []fl
 	return pop
 }
 
-// runTournament runs the final tournament with all trained agents
-func runTournament(agents []Agent, gamesPerPair int, outputDir string) {
+// runTournament runs the final tournament with all trained agents, serving its
+// metrics on metricsAddr if it is set
+func runTournament(agents []Agent, gamesPerPair int, outputDir string, metricsAddr string) {
 	fmt.Printf("\n=== Running Final Tournament with Trained Agents ===\n")
 
 	// Create tournament results file
@@ -346,6 +458,9 @@ func runTournament(agents []Agent, gamesPerPair int, outputDir string) {
 		"--output", tournamentOutput,
 		"--cutoff", "0", // Don't eliminate any agents
 	}
+	if metricsAddr != "" {
+		agentArgs = append(agentArgs, "--metrics-addr", metricsAddr)
+	}
 
 	// Add explicit agents instead of auto-discovery
 	agentArgs = append(agentArgs, "--agents")
@@ -383,7 +498,7 @@ func runTournament(agents []Agent, gamesPerPair int, outputDir string) {
 
 	err := cmd.Run()
 	if err != nil {
-		fmt.Printf("Error running tournament: %v\n", err)
+		slog.Error("Failed to run tournament", "error", err)
 		return
 	}
 
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/agents"
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/logging"
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/mcts"
+	neural "github.com/zachbeta/neural_rps/alphago_demo/pkg/rps_net_impl"
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/tournament"
+)
+
+// searchConfig is one point of the parameter grid
+type searchConfig struct {
+	Exploration float64
+	UseFPU      bool
+	FPU         float64
+	Simulations int
+}
+
+// Name returns the agent name used for the configuration in the tournament
+func (c searchConfig) Name() string {
+	name := fmt.Sprintf("c=%g", c.Exploration)
+	if c.UseFPU {
+		name += fmt.Sprintf(" fpu=%g", c.FPU)
+	}
+	return name + fmt.Sprintf(" sims=%d", c.Simulations)
+}
+
+// Params returns the MCTS parameters for the configuration
+func (c searchConfig) Params() mcts.RPSMCTSParams {
+	params := mcts.DefaultRPSMCTSParams()
+	params.ExplorationConst = c.Exploration
+	params.UseFPU = c.UseFPU
+	params.FPU = c.FPU
+	params.NumSimulations = c.Simulations
+	return params
+}
+
+func main() {
+	policyPath := flag.String("policy", "output/rps_policy1.model", "Path to the policy network file")
+	valuePath := flag.String("value", "output/rps_value1.model", "Path to the value network file")
+	explorations := flag.String("c", "0.5,1.0,1.5,2.0", "Comma-separated exploration constants to try")
+	fpus := flag.String("fpu", "", "Comma-separated FPU reductions to try (empty leaves FPU off)")
+	sims := flag.String("sims", "200", "Comma-separated simulation counts to try")
+	gamesPerPair := flag.Int("games", 20, "Number of games per pair of configurations")
+	outputFile := flag.String("output", "", "Optional CSV file for the tournament results")
+	verbose := flag.Bool("verbose", false, "Log the result of every game")
+	seed := flag.Int64("seed", 0, "Seed for deals, first players and searches, making the run repeatable (0 seeds from the clock)")
+	logConfig := logging.AddFlags(flag.CommandLine)
+	flag.Parse()
+	if err := logConfig.Install(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	// Searches are only seeded for a chosen seed, as seeded searches run serially
+	if *seed != 0 {
+		rand.Seed(*seed)
+	} else {
+		rand.Seed(time.Now().UnixNano())
+	}
+
+	configs, err := buildGrid(*explorations, *fpus, *sims)
+	if err != nil {
+		log.Fatalf("Invalid parameter grid: %v", err)
+	}
+	if len(configs) < 2 {
+		log.Fatalf("Need at least two configurations to compare, got %d", len(configs))
+	}
+
+	policyNet, err := neural.LoadPolicyNetwork(*policyPath)
+	if err != nil {
+		log.Fatalf("Failed to load policy from %s: %v", *policyPath, err)
+	}
+	valueNet := neural.NewRPSValueNetwork(1)
+	if err := valueNet.LoadFromFile(*valuePath); err != nil {
+		log.Fatalf("Failed to load value from %s: %v", *valuePath, err)
+	}
+	fmt.Printf("Loaded networks from %s and %s\n", *policyPath, *valuePath)
+
+	// Every configuration searches with the same networks
+	tm := tournament.NewTournamentManager(*verbose)
+	tm.LeaderboardInterval = 0
+	for _, config := range configs {
+		engine := mcts.NewRPSMCTS(policyNet, valueNet, config.Params())
+		tm.AddAgent(agents.NewMCTSAgent(config.Name(), engine))
+	}
+
+	if *seed != 0 {
+		tm.Seed(*seed)
+		fmt.Printf("Playing a reproducible tournament with seed %d\n", *seed)
+	}
+
+	fmt.Printf("Tuning %d MCTS configurations, %d games per pair\n\n", len(configs), *gamesPerPair)
+	tm.RunTournament(*gamesPerPair, 0)
+
+	fmt.Println("\n=== MCTS Configurations by ELO ===")
+	tm.PrintRankings()
+
+	best := tm.Rankings()[0]
+	fmt.Printf("\nBest configuration: %s (ELO %.0f)\n", best.Name, best.Elo)
+
+	if *outputFile != "" {
+		if err := tm.SaveResults(*outputFile); err != nil {
+			log.Fatalf("Failed to save results: %v", err)
+		}
+		fmt.Printf("Results saved to %s\n", *outputFile)
+	}
+}
+
+// buildGrid returns every combination of the listed exploration constants, FPU
+// reductions and simulation counts
+func buildGrid(explorations, fpus, sims string) ([]searchConfig, error) {
+	cs, err := parseFloats(explorations)
+	if err != nil {
+		return nil, fmt.Errorf("-c: %v", err)
+	}
+	if len(cs) == 0 {
+		return nil, fmt.Errorf("-c: no exploration constants given")
+	}
+
+	fpuValues, err := parseFloats(fpus)
+	if err != nil {
+		return nil, fmt.Errorf("-fpu: %v", err)
+	}
+	useFPU := len(fpuValues) > 0
+	if !useFPU {
+		fpuValues = []float64{mcts.DefaultRPSMCTSParams().FPU}
+	}
+
+	simValues, err := parseFloats(sims)
+	if err != nil {
+		return nil, fmt.Errorf("-sims: %v", err)
+	}
+	if len(simValues) == 0 {
+		return nil, fmt.Errorf("-sims: no simulation counts given")
+	}
+
+	var configs []searchConfig
+	for _, c := range cs {
+		for _, fpu := range fpuValues {
+			for _, s := range simValues {
+				if s < 1 || s != float64(int(s)) {
+					return nil, fmt.Errorf("-sims: %g is not a positive whole number", s)
+				}
+				configs = append(configs, searchConfig{
+					Exploration: c,
+					UseFPU:      useFPU,
+					FPU:         fpu,
+					Simulations: int(s),
+				})
+			}
+		}
+	}
+	return configs, nil
+}
+
+// parseFloats parses a comma-separated list of numbers; an empty string gives an empty list
+func parseFloats(list string) ([]float64, error) {
+	var values []float64
+	for _, field := range strings.Split(list, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", field)
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
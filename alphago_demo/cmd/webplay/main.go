@@ -0,0 +1,309 @@
+package main
+
+import (
+	"crypto/rand"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/mcts"
+	neural "github.com/zachbeta/neural_rps/alphago_demo/pkg/rps_net_impl"
+)
+
+const (
+	// Game parameters, as in play_vs_ai
+	deckSize  = 21
+	handSize  = 5
+	maxRounds = 10
+
+	// Games nobody has watched or played for this long are discarded
+	sessionTimeout = 30 * time.Minute
+)
+
+//go:embed index.html
+var indexHTML []byte
+
+// server holds the networks shared by every game and the games in progress
+type server struct {
+	policyNetwork *neural.RPSPolicyNetwork
+	valueNetwork  *neural.RPSValueNetwork
+	difficulty    mcts.Difficulty // Default for new games
+	delay         time.Duration   // Pause between moves when spectating
+
+	// Searches take turns: each one already uses every core, and the networks
+	// are shared between games
+	searchMu sync.Mutex
+
+	mu       sync.Mutex
+	sessions map[string]*session
+}
+
+var upgrader = websocket.Upgrader{ReadBufferSize: 1024, WriteBufferSize: 4096}
+
+func main() {
+	addr := flag.String("addr", "localhost:8080", "Address to serve on")
+	policyPath := flag.String("policy", "output/rps_policy2.model", "Policy network model file")
+	valuePath := flag.String("value", "output/rps_value2.model", "Value network model file")
+	difficultyName := flag.String("difficulty", "medium", "Default AI strength: easy, medium or hard")
+	delay := flag.Duration("delay", time.Second, "Pause between moves when the AI plays itself")
+	flag.Parse()
+
+	difficulty, err := mcts.ParseDifficulty(*difficultyName)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	srv := &server{
+		policyNetwork: loadPolicyNetwork(*policyPath),
+		valueNetwork:  loadValueNetwork(*valuePath),
+		difficulty:    difficulty,
+		delay:         *delay,
+		sessions:      make(map[string]*session),
+	}
+	go srv.reapSessions()
+
+	http.HandleFunc("/", srv.handleIndex)
+	http.HandleFunc("/api/games", srv.handleGames)
+	http.HandleFunc("/api/games/", srv.handleGame)
+
+	log.Printf("Serving on http://%s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, nil))
+}
+
+// loadPolicyNetwork loads the policy network, or returns an untrained one if
+// the file cannot be loaded
+func loadPolicyNetwork(path string) *neural.RPSPolicyNetwork {
+	network, err := neural.LoadPolicyNetwork(path)
+	if err != nil {
+		log.Printf("Failed to load policy model from %s: %v", path, err)
+		log.Println("Starting with a new model instead.")
+		return neural.NewRPSPolicyNetwork(128)
+	}
+	log.Printf("Loaded policy model from %s", path)
+	return network
+}
+
+// loadValueNetwork loads the value network, or returns an untrained one if
+// the file cannot be loaded
+func loadValueNetwork(path string) *neural.RPSValueNetwork {
+	hiddenSize := 128
+	if header, err := neural.ReadModelHeader(path); err == nil {
+		hiddenSize = header.HiddenSize
+	}
+	network := neural.NewRPSValueNetwork(hiddenSize)
+	if err := network.LoadFromFile(path); err != nil {
+		log.Printf("Failed to load value model from %s: %v", path, err)
+		log.Println("Starting with a new model instead.")
+		return neural.NewRPSValueNetwork(128)
+	}
+	log.Printf("Loaded value model from %s", path)
+	return network
+}
+
+// handleIndex serves the browser client
+func (srv *server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(indexHTML)
+}
+
+// handleGames creates a game. The request body is a JSON object with a
+// "mode" of "human" or "spectate" and an optional "difficulty"; the response
+// is the new game's state.
+func (srv *server) handleGames(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "use POST to create a game", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Mode       string `json:"mode"`
+		Difficulty string `json:"difficulty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Mode != modeHuman && req.Mode != modeSpectate {
+		http.Error(w, fmt.Sprintf("unknown mode %q (want human or spectate)", req.Mode), http.StatusBadRequest)
+		return
+	}
+	difficulty := srv.difficulty
+	if req.Difficulty != "" {
+		var err error
+		if difficulty, err = mcts.ParseDifficulty(req.Difficulty); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	s := srv.newSession(req.Mode, difficulty)
+	s.mu.Lock()
+	view := s.view()
+	s.mu.Unlock()
+	writeJSON(w, view)
+	s.start()
+}
+
+// handleGame serves /api/games/{id}, the state of one game, and
+// /api/games/{id}/ws, the websocket that streams its states and accepts
+// human moves
+func (srv *server) handleGame(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/games/")
+	id, endpoint, _ := strings.Cut(path, "/")
+
+	srv.mu.Lock()
+	s := srv.sessions[id]
+	srv.mu.Unlock()
+	if s == nil {
+		http.Error(w, "no such game", http.StatusNotFound)
+		return
+	}
+
+	switch endpoint {
+	case "":
+		s.mu.Lock()
+		view := s.view()
+		s.mu.Unlock()
+		writeJSON(w, view)
+	case "ws":
+		srv.serveWebsocket(w, r, s)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// serveWebsocket streams a game's states to the browser and plays the moves
+// it sends until the connection closes
+func (srv *server) serveWebsocket(w http.ResponseWriter, r *http.Request, s *session) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return // The upgrader has already replied
+	}
+	c := &client{conn: conn, send: make(chan []byte, 16)}
+	go c.writeMessages()
+	s.join(c)
+	defer s.leave(c)
+
+	for {
+		var req moveRequest
+		if err := conn.ReadJSON(&req); err != nil {
+			return
+		}
+		if req.Type != "move" {
+			c.sendError(fmt.Sprintf("unknown message type %q", req.Type), s)
+			continue
+		}
+		if err := s.humanMove(req); err != nil {
+			c.sendError(err.Error(), s)
+		}
+	}
+}
+
+// writeMessages writes queued messages until the queue is closed, then
+// closes the connection
+func (c *client) writeMessages() {
+	defer c.conn.Close()
+	for message := range c.send {
+		c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+		if err := c.conn.WriteMessage(websocket.TextMessage, message); err != nil {
+			return
+		}
+	}
+	c.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+}
+
+// sendError reports a rejected move to this client only
+func (c *client) sendError(message string, s *session) {
+	data, _ := json.Marshal(errorView{Type: "error", Error: message})
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.clients[c] {
+		select {
+		case c.send <- data:
+		default:
+		}
+	}
+}
+
+// newSession creates and registers a game
+func (srv *server) newSession(mode string, difficulty mcts.Difficulty) *session {
+	params := difficulty.Apply(mcts.DefaultRPSMCTSParams())
+	s := &session{
+		id:         newSessionID(),
+		mode:       mode,
+		difficulty: difficulty,
+		server:     srv,
+		game:       game.NewRPSGame(deckSize, handSize, maxRounds),
+		engine:     mcts.NewRPSMCTS(srv.policyNetwork, srv.valueNetwork, params),
+		clients:    make(map[*client]bool),
+		lastActive: time.Now(),
+	}
+
+	srv.mu.Lock()
+	srv.sessions[s.id] = s
+	srv.mu.Unlock()
+	log.Printf("Game %s started (%s, %s)", s.id, mode, difficulty)
+	return s
+}
+
+// search finds the AI's move in state with engine, which belongs to the
+// calling session, and returns it with the search report and an explanation
+func (srv *server) search(engine *mcts.RPSMCTS, state *game.RPSGame, difficulty mcts.Difficulty) (game.RPSMove, *analysisView, string, error) {
+	srv.searchMu.Lock()
+	defer srv.searchMu.Unlock()
+
+	engine.SetRootState(state)
+	engine.Search()
+	node := engine.SampleMove(difficulty.Temperature())
+	if node == nil || node.Move == nil {
+		move, err := fallbackMove(state, engine)
+		return move, nil, fallbackExplanation, err
+	}
+
+	move := *node.Move
+	move.Player = state.CurrentPlayer
+	report := engine.LastSearchReport()
+	return move, newAnalysis(report), report.Explain(move), nil
+}
+
+// reapSessions discards games nobody has touched for sessionTimeout
+func (srv *server) reapSessions() {
+	for range time.Tick(time.Minute) {
+		cutoff := time.Now().Add(-sessionTimeout)
+		srv.mu.Lock()
+		for id, s := range srv.sessions {
+			if s.idleSince(cutoff) {
+				delete(srv.sessions, id)
+			}
+		}
+		srv.mu.Unlock()
+	}
+}
+
+// newSessionID returns a random game ID that is hard to guess
+func newSessionID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// writeJSON sends value as a JSON response
+func writeJSON(w http.ResponseWriter, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		log.Printf("Failed to write response: %v", err)
+	}
+}
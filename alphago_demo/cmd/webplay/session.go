@@ -0,0 +1,347 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/agents"
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/mcts"
+)
+
+// Session modes
+const (
+	modeHuman    = "human"    // A human plays Player1 against the AI
+	modeSpectate = "spectate" // The AI plays both sides
+)
+
+// topMoves is how many of the search's candidate moves are sent to the browser
+const topMoves = 5
+
+// session is one game in progress and the browsers watching it
+type session struct {
+	id         string
+	mode       string
+	difficulty mcts.Difficulty
+	server     *server
+
+	mu         sync.Mutex
+	game       *game.RPSGame
+	engine     *mcts.RPSMCTS
+	thinking   bool
+	lastMove   *moveView
+	analysis   *analysisView
+	clients    map[*client]bool
+	lastActive time.Time
+}
+
+// client is one browser connection. Messages are queued on send and written
+// by a single goroutine, as the websocket allows only one writer.
+type client struct {
+	conn *websocket.Conn
+	send chan []byte
+}
+
+// stateView is the message sent to browsers after every change to a game
+type stateView struct {
+	Type          string        `json:"type"` // Always "state"
+	ID            string        `json:"id"`
+	Mode          string        `json:"mode"`
+	Difficulty    string        `json:"difficulty"`
+	Board         []*cardView   `json:"board"` // Nil for an empty square
+	Player1Hand   []string      `json:"player1Hand"`
+	Player2Hand   []string      `json:"player2Hand,omitempty"` // Only shown when spectating
+	Player2Cards  int           `json:"player2Cards"`
+	CurrentPlayer int           `json:"currentPlayer"`
+	Round         int           `json:"round"`
+	MaxRounds     int           `json:"maxRounds"`
+	Score         [2]int        `json:"score"`
+	GameOver      bool          `json:"gameOver"`
+	Winner        int           `json:"winner"` // 0 for a draw or an unfinished game
+	Thinking      bool          `json:"thinking"`
+	LastMove      *moveView     `json:"lastMove,omitempty"`
+	Analysis      *analysisView `json:"analysis,omitempty"`
+}
+
+// cardView is a card on the board
+type cardView struct {
+	Type  string `json:"type"`
+	Owner int    `json:"owner"`
+}
+
+// moveView describes the last move played
+type moveView struct {
+	Player      int    `json:"player"`
+	Card        string `json:"card"`
+	Position    int    `json:"position"`
+	Explanation string `json:"explanation,omitempty"` // The search's reasoning, for AI moves
+}
+
+// analysisView is the search report behind the last AI move
+type analysisView struct {
+	Simulations        int             `json:"simulations"`
+	TopMoves           []candidateView `json:"topMoves"`
+	PrincipalVariation []candidateView `json:"principalVariation"`
+}
+
+// candidateView is one move the search considered
+type candidateView struct {
+	Player   int     `json:"player"`
+	Card     string  `json:"card"`
+	Position int     `json:"position"`
+	Visits   int     `json:"visits"`
+	Share    float64 `json:"share"`
+	Prior    float64 `json:"prior"`
+	Value    float64 `json:"value"` // For the player making the move, 0 to 1
+}
+
+// errorView reports a rejected request to the browser that made it
+type errorView struct {
+	Type  string `json:"type"` // Always "error"
+	Error string `json:"error"`
+}
+
+// moveRequest is a human move sent by the browser: a card from Player1's
+// hand and the square to play it on
+type moveRequest struct {
+	Type     string `json:"type"` // Always "move"
+	Card     int    `json:"card"`
+	Position int    `json:"position"`
+}
+
+// start begins play: the AI plays every move of a spectated game, and waits
+// for the human otherwise
+func (s *session) start() {
+	if s.mode == modeSpectate {
+		go s.autoplay()
+	}
+}
+
+// autoplay plays the AI against itself to the end of the game, pausing
+// between moves so spectators can follow
+func (s *session) autoplay() {
+	for s.playAIMove() {
+		time.Sleep(s.server.delay)
+	}
+}
+
+// humanMove plays a move for the human and starts the AI's reply
+func (s *session) humanMove(req moveRequest) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.mode != modeHuman {
+		return errors.New("this game is being spectated")
+	}
+	if s.game.IsGameOver() {
+		return errors.New("the game is over")
+	}
+	if s.game.CurrentPlayer != game.Player1 || s.thinking {
+		return errors.New("it is not your turn")
+	}
+	if req.Card < 0 || req.Card >= len(s.game.Player1Hand) {
+		return fmt.Errorf("no card %d in your hand", req.Card)
+	}
+
+	cardType := s.game.Player1Hand[req.Card].Type
+	move := game.RPSMove{CardIndex: req.Card, Position: req.Position, Player: game.Player1}
+	if err := s.game.MakeMove(move); err != nil {
+		return err
+	}
+	s.lastMove = &moveView{Player: int(game.Player1), Card: cardName(cardType), Position: req.Position}
+
+	// Mark the AI as thinking straight away so a second click is refused
+	gameOver := s.game.IsGameOver()
+	s.thinking = !gameOver
+	s.broadcast()
+	if !gameOver {
+		go s.playAIMove()
+	}
+	return nil
+}
+
+// playAIMove searches the current position and plays the AI's move. It
+// returns false once the game is over.
+func (s *session) playAIMove() bool {
+	s.mu.Lock()
+	if s.game.IsGameOver() {
+		s.thinking = false
+		s.mu.Unlock()
+		return false
+	}
+	state := s.game.Copy()
+	s.thinking = true
+	s.broadcast()
+	s.mu.Unlock()
+
+	move, analysis, explanation, err := s.server.search(s.engine, state, s.difficulty)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.thinking = false
+	if err != nil {
+		log.Printf("Game %s: the AI could not move: %v", s.id, err)
+		s.broadcast()
+		return false
+	}
+
+	hand := s.game.Player1Hand
+	if move.Player == game.Player2 {
+		hand = s.game.Player2Hand
+	}
+	card := cardName(hand[move.CardIndex].Type)
+	if err := s.game.MakeMove(move); err != nil {
+		log.Printf("Game %s: the AI chose an illegal move: %v", s.id, err)
+		s.broadcast()
+		return false
+	}
+	s.lastMove = &moveView{Player: int(move.Player), Card: card, Position: move.Position, Explanation: explanation}
+	s.analysis = analysis
+	s.broadcast()
+	return !s.game.IsGameOver()
+}
+
+// view builds the state message for the current position. The caller holds s.mu.
+func (s *session) view() stateView {
+	g := s.game
+	v := stateView{
+		Type:          "state",
+		ID:            s.id,
+		Mode:          s.mode,
+		Difficulty:    s.difficulty.String(),
+		Board:         make([]*cardView, len(g.Board)),
+		Player1Hand:   handNames(g.Player1Hand),
+		Player2Cards:  len(g.Player2Hand),
+		CurrentPlayer: int(g.CurrentPlayer),
+		Round:         g.Round,
+		MaxRounds:     g.MaxRounds,
+		GameOver:      g.IsGameOver(),
+		Thinking:      s.thinking,
+		LastMove:      s.lastMove,
+		Analysis:      s.analysis,
+	}
+	for pos, card := range g.Board {
+		if card.Owner != game.NoPlayer {
+			v.Board[pos] = &cardView{Type: cardName(card.Type), Owner: int(card.Owner)}
+		}
+	}
+	if s.mode == modeSpectate {
+		v.Player2Hand = handNames(g.Player2Hand)
+	}
+	v.Score[0], v.Score[1] = g.Score()
+	if v.GameOver {
+		v.Winner = int(g.GetWinner())
+	}
+	return v
+}
+
+// broadcast sends the current state to every client. A client too slow to
+// keep up is disconnected rather than allowed to hold up the game. The caller
+// holds s.mu.
+func (s *session) broadcast() {
+	s.lastActive = time.Now()
+	message, err := json.Marshal(s.view())
+	if err != nil {
+		log.Printf("Game %s: %v", s.id, err)
+		return
+	}
+	for c := range s.clients {
+		select {
+		case c.send <- message:
+		default:
+			delete(s.clients, c)
+			close(c.send)
+		}
+	}
+}
+
+// join adds a client and sends it the current state
+func (s *session) join(c *client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clients[c] = true
+	s.broadcast()
+}
+
+// leave removes a client if it is still connected
+func (s *session) leave(c *client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.clients[c] {
+		delete(s.clients, c)
+		close(c.send)
+	}
+	s.lastActive = time.Now()
+}
+
+// idleSince reports whether nobody is watching the game and nothing has
+// happened in it since cutoff
+func (s *session) idleSince(cutoff time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.clients) == 0 && !s.thinking && s.lastActive.Before(cutoff)
+}
+
+// newAnalysis converts a search report for the browser
+func newAnalysis(report mcts.SearchReport) *analysisView {
+	return &analysisView{
+		Simulations:        report.Simulations,
+		TopMoves:           candidateViews(report.TopMoves(topMoves)),
+		PrincipalVariation: candidateViews(report.PrincipalVariation),
+	}
+}
+
+// candidateViews converts candidate moves for the browser
+func candidateViews(candidates []mcts.CandidateMove) []candidateView {
+	views := make([]candidateView, len(candidates))
+	for i, c := range candidates {
+		views[i] = candidateView{
+			Player:   int(c.Move.Player),
+			Card:     cardName(c.CardType),
+			Position: c.Move.Position,
+			Visits:   c.Visits,
+			Share:    c.Share,
+			Prior:    c.Prior,
+			Value:    c.Value,
+		}
+	}
+	return views
+}
+
+// fallbackExplanation describes a move the search did not choose
+const fallbackExplanation = "played the policy network's choice because the search found no move"
+
+// fallbackMove picks a move when the search returns none
+func fallbackMove(state *game.RPSGame, engine *mcts.RPSMCTS) (game.RPSMove, error) {
+	move, err := agents.FallbackMove(state, agents.FallbackPolicyPrior, engine.PolicyNetwork)
+	move.Player = state.CurrentPlayer
+	return move, err
+}
+
+// handNames lists the card types in a hand
+func handNames(hand []game.RPSCard) []string {
+	names := make([]string, len(hand))
+	for i, card := range hand {
+		names[i] = cardName(card.Type)
+	}
+	return names
+}
+
+// cardName returns "rock", "paper" or "scissors"
+func cardName(cardType game.RPSCardType) string {
+	switch cardType {
+	case game.Rock:
+		return "rock"
+	case game.Paper:
+		return "paper"
+	case game.Scissors:
+		return "scissors"
+	default:
+		return "unknown"
+	}
+}
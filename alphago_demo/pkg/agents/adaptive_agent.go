@@ -0,0 +1,178 @@
+package agents
+
+import (
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
+)
+
+// DefaultAdaptiveWeight is how strongly a new AdaptiveAgent's opponent model
+// sways its choices
+const DefaultAdaptiveWeight = 0.5
+
+// Resetter is implemented by agents that keep state across the games of a
+// match. Tournaments and series call Reset before each match.
+type Resetter interface {
+	Reset()
+}
+
+var (
+	_ Agent    = (*AdaptiveAgent)(nil)
+	_ Resetter = (*AdaptiveAgent)(nil)
+	_ Seeder   = (*AdaptiveAgent)(nil)
+)
+
+// AdaptiveAgent plays as a base agent, adjusted by what it has learned about
+// its opponent in the current match. It counts the card types the opponent
+// plays and the squares it plays them on, then prefers cards that beat the
+// opponent's favourite types without being beaten by them, and squares the
+// opponent likes to take.
+type AdaptiveAgent struct {
+	base Agent
+
+	// Weight is how strongly the opponent model sways the choice. At 0 the
+	// agent plays exactly as its base agent. Each move is scored 1 if it is
+	// the base agent's choice, plus Weight times its adaptation score of
+	// between -1 and 2, and the highest score is played.
+	Weight float64
+
+	// MinObservations is the number of opponent moves seen before the agent
+	// starts to adapt
+	MinObservations int
+
+	typeCounts     [game.NumCardTypes]int
+	positionCounts []int
+	observed       int
+
+	// The moves of the current game already counted
+	gameMoves []game.RPSMove
+}
+
+// NewAdaptiveAgent creates an agent that plays as base, adapted to the
+// opponent. Call Reset between matches.
+func NewAdaptiveAgent(base Agent) *AdaptiveAgent {
+	return &AdaptiveAgent{
+		base:            base,
+		Weight:          DefaultAdaptiveWeight,
+		MinObservations: 3,
+	}
+}
+
+// Name returns the agent's name
+func (a *AdaptiveAgent) Name() string {
+	return "Adaptive-" + a.base.Name()
+}
+
+// Reset forgets everything learned about the opponent, for the start of a
+// new match
+func (a *AdaptiveAgent) Reset() {
+	a.typeCounts = [game.NumCardTypes]int{}
+	a.positionCounts = nil
+	a.observed = 0
+	a.gameMoves = nil
+	if resetter, ok := a.base.(Resetter); ok {
+		resetter.Reset()
+	}
+}
+
+// Seed seeds the base agent, if it makes random choices. The opponent model
+// itself is deterministic.
+func (a *AdaptiveAgent) Seed(seed int64) {
+	if seeder, ok := a.base.(Seeder); ok {
+		seeder.Seed(seed)
+	}
+}
+
+// GetMove records the opponent's moves since the agent last moved, then
+// chooses between the base agent's move and the alternatives the opponent
+// model favours
+func (a *AdaptiveAgent) GetMove(state *game.RPSGame) (game.RPSMove, error) {
+	a.observe(state)
+
+	baseMove, err := a.base.GetMove(state.Copy())
+	if err != nil {
+		return game.RPSMove{}, err
+	}
+	if a.Weight == 0 || a.observed < a.MinObservations {
+		return baseMove, nil
+	}
+
+	hand := state.Player1Hand
+	if state.CurrentPlayer == game.Player2 {
+		hand = state.Player2Hand
+	}
+	if baseMove.CardIndex < 0 || baseMove.CardIndex >= len(hand) {
+		return baseMove, nil // Let the game reject it as it would the base agent's
+	}
+	baseType := hand[baseMove.CardIndex].Type
+
+	rules := state.GetCaptureRules()
+	best := baseMove
+	bestScore := 1 + a.Weight*a.adaptationScore(rules, baseType, baseMove.Position)
+	for _, move := range state.GetValidMoves() {
+		cardType := hand[move.CardIndex].Type
+		if cardType == baseType && move.Position == baseMove.Position {
+			continue
+		}
+		if score := a.Weight * a.adaptationScore(rules, cardType, move.Position); score > bestScore {
+			best, bestScore = move, score
+		}
+	}
+	best.Player = state.CurrentPlayer
+	return best, nil
+}
+
+// adaptationScore rates playing cardType on position against the opponent
+// model: the share of the opponent's cards cardType beats, less the share
+// that beat it, plus the share of the opponent's moves made on position
+func (a *AdaptiveAgent) adaptationScore(rules *game.CaptureRules, cardType game.RPSCardType, position int) float64 {
+	score := 0.0
+	for opponentType, count := range a.typeCounts {
+		share := float64(count) / float64(a.observed)
+		if rules.Captures(cardType, game.RPSCardType(opponentType)) {
+			score += share
+		}
+		if rules.Captures(game.RPSCardType(opponentType), cardType) {
+			score -= share
+		}
+	}
+	if position < len(a.positionCounts) {
+		score += float64(a.positionCounts[position]) / float64(a.observed)
+	}
+	return score
+}
+
+// observe counts the opponent's moves in state that have not been counted yet.
+// A game whose history does not continue the one seen so far is a new game of
+// the match.
+func (a *AdaptiveAgent) observe(state *game.RPSGame) {
+	history := state.MoveHistory
+	if !continuesGame(history, a.gameMoves) {
+		a.gameMoves = nil
+	}
+	if len(a.positionCounts) != len(state.Board) {
+		a.positionCounts = make([]int, len(state.Board))
+	}
+
+	for _, move := range history[len(a.gameMoves):] {
+		// Squares are filled once and captures only change the owner, so the
+		// board still shows the type of card played
+		if move.Player != state.CurrentPlayer && move.Position >= 0 && move.Position < len(state.Board) {
+			a.typeCounts[state.Board[move.Position].Type]++
+			a.positionCounts[move.Position]++
+			a.observed++
+		}
+	}
+	a.gameMoves = append(a.gameMoves[:0:0], history...)
+}
+
+// continuesGame reports whether history starts with the moves seen so far
+func continuesGame(history, seen []game.RPSMove) bool {
+	if len(history) < len(seen) {
+		return false
+	}
+	for i, move := range seen {
+		if history[i] != move {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,116 @@
+package agents
+
+import (
+	"testing"
+
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
+)
+
+// playRocks plays a round in which each player puts a rock on the given square
+func playRocks(t *testing.T, g *game.RPSGame, player1Square, player2Square int) {
+	t.Helper()
+	for _, move := range []game.RPSMove{
+		{Position: player1Square, Player: game.Player1},
+		{Position: player2Square, Player: game.Player2},
+	} {
+		g.Player1Hand = []game.RPSCard{{Type: game.Rock}}
+		g.Player2Hand = []game.RPSCard{{Type: game.Rock}}
+		if err := g.MakeMove(move); err != nil {
+			t.Fatalf("Failed to play %+v: %v", move, err)
+		}
+	}
+}
+
+// rockGame returns a game in which player 2 has played a rock in each of the
+// given rounds, with player 1 to move holding a scissors and a paper
+func rockGame(t *testing.T, rounds [][2]int) *game.RPSGame {
+	t.Helper()
+	g := game.NewRPSGame(21, 5, 10)
+	for _, squares := range rounds {
+		playRocks(t, g, squares[0], squares[1])
+	}
+	g.Player1Hand = []game.RPSCard{{Type: game.Scissors}, {Type: game.Paper}}
+	return g
+}
+
+// scissorsOn returns a base agent that plays player 1's scissors on square
+func scissorsOn(square int) *fixedAgent {
+	return &fixedAgent{name: "Scissors", move: game.RPSMove{CardIndex: 0, Position: square, Player: game.Player1}}
+}
+
+func TestAdaptiveAgentPlaysBaseMoveUntilAdapting(t *testing.T) {
+	base := scissorsOn(4)
+
+	// Two observations, below the minimum of three
+	agent := NewAdaptiveAgent(base)
+	agent.Weight = 1
+	move, err := agent.GetMove(rockGame(t, [][2]int{{0, 1}, {2, 3}}))
+	if err != nil {
+		t.Fatalf("GetMove failed: %v", err)
+	}
+	if move != base.move {
+		t.Errorf("Expected the base move %+v below MinObservations, got %+v", base.move, move)
+	}
+
+	// Enough observations, but no weight on them
+	agent = NewAdaptiveAgent(base)
+	agent.Weight = 0
+	move, err = agent.GetMove(rockGame(t, [][2]int{{0, 1}, {2, 3}, {5, 6}}))
+	if err != nil {
+		t.Fatalf("GetMove failed: %v", err)
+	}
+	if move != base.move {
+		t.Errorf("Expected the base move %+v at weight 0, got %+v", base.move, move)
+	}
+}
+
+func TestAdaptiveAgentCountersRockOnlyOpponent(t *testing.T) {
+	agent := NewAdaptiveAgent(scissorsOn(4))
+	agent.Weight = 1
+	state := rockGame(t, [][2]int{{0, 1}, {2, 3}, {5, 6}})
+
+	move, err := agent.GetMove(state)
+	if err != nil {
+		t.Fatalf("GetMove failed: %v", err)
+	}
+	if cardType := state.Player1Hand[move.CardIndex].Type; cardType != game.Paper {
+		t.Errorf("Expected paper against an opponent that only plays rock, got card type %v", cardType)
+	}
+	if move.Player != game.Player1 {
+		t.Errorf("Expected a move for player 1, got one for player %v", move.Player)
+	}
+}
+
+func TestAdaptiveAgentDetectsNewGame(t *testing.T) {
+	agent := NewAdaptiveAgent(scissorsOn(0))
+	if _, err := agent.GetMove(rockGame(t, [][2]int{{0, 1}, {2, 3}, {5, 6}})); err != nil {
+		t.Fatalf("GetMove failed: %v", err)
+	}
+
+	// A longer history that does not continue the first game is counted in
+	// full, on top of what the match has seen so far
+	if _, err := agent.GetMove(rockGame(t, [][2]int{{8, 7}, {6, 5}, {4, 3}, {2, 1}})); err != nil {
+		t.Fatalf("GetMove failed: %v", err)
+	}
+	if agent.observed != 7 || agent.typeCounts[game.Rock] != 7 {
+		t.Errorf("Expected 7 rocks observed over both games, got %d of %d moves",
+			agent.typeCounts[game.Rock], agent.observed)
+	}
+}
+
+func TestAdaptiveAgentReset(t *testing.T) {
+	agent := NewAdaptiveAgent(scissorsOn(4))
+	if _, err := agent.GetMove(rockGame(t, [][2]int{{0, 1}, {2, 3}, {5, 6}})); err != nil {
+		t.Fatalf("GetMove failed: %v", err)
+	}
+	if agent.observed != 3 {
+		t.Fatalf("Expected 3 moves observed, got %d", agent.observed)
+	}
+
+	agent.Reset()
+	if agent.observed != 0 || agent.typeCounts != [game.NumCardTypes]int{} ||
+		agent.positionCounts != nil || agent.gameMoves != nil {
+		t.Errorf("Expected Reset to clear the opponent model, got %d observed, type counts %v, position counts %v",
+			agent.observed, agent.typeCounts, agent.positionCounts)
+	}
+}
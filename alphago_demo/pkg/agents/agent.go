@@ -0,0 +1,201 @@
+package agents
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/mcts"
+)
+
+// Agent defines the interface shared by all RPS card game players
+type Agent interface {
+	Name() string
+	GetMove(state *game.RPSGame) (game.RPSMove, error)
+}
+
+// SearchReporter is implemented by agents that can describe their most recent search.
+// Recorders use it to log per-move search statistics.
+type SearchReporter interface {
+	// GetNodesEvaluated returns the number of positions searched for the last move
+	GetNodesEvaluated() int
+	// GetValueEstimate returns the agent's evaluation of the last move it chose
+	GetValueEstimate() float64
+}
+
+// AgentInfo describes what an agent plays with, so tournament results can be
+// compared across architectures. Fields that do not apply to an agent are zero.
+type AgentInfo struct {
+	HiddenSize    int `json:"hidden_size,omitempty"`    // Hidden layer size of the policy network
+	Parameters    int `json:"parameters,omitempty"`     // Weights and biases across the agent's networks
+	Simulations   int `json:"simulations,omitempty"`    // MCTS simulations per move
+	TrainingGames int `json:"training_games,omitempty"` // Self-play games the policy network was trained on
+}
+
+// Describer is implemented by agents that can describe their networks and search
+type Describer interface {
+	Describe() AgentInfo
+}
+
+// Seeder is implemented by agents that make random choices. After Seed the
+// agent's moves depend only on the seed and the positions it is given, so a
+// game can be replayed exactly.
+type Seeder interface {
+	Seed(seed int64)
+}
+
+var (
+	_ Agent = (*MinimaxAgent)(nil)
+	_ Agent = (*MCTSAgent)(nil)
+	_ Agent = (*RandomAgent)(nil)
+
+	_ SearchReporter = (*MinimaxAgent)(nil)
+	_ SearchReporter = (*MCTSAgent)(nil)
+
+	_ Describer = (*MCTSAgent)(nil)
+
+	_ Seeder = (*MCTSAgent)(nil)
+	_ Seeder = (*RandomAgent)(nil)
+)
+
+// MCTSAgent uses MCTS for move selection
+type MCTSAgent struct {
+	name       string
+	mctsEngine *mcts.RPSMCTS
+	lastValue  float64
+
+	fallback FallbackPolicy
+}
+
+// NewMCTSAgent creates an agent that searches with the given MCTS engine
+func NewMCTSAgent(name string, engine *mcts.RPSMCTS) *MCTSAgent {
+	return &MCTSAgent{
+		name:       name,
+		mctsEngine: engine,
+	}
+}
+
+// GetMove runs a search from the given state and returns the best move,
+// falling back to the agent's fallback policy if the search returns nothing
+func (a *MCTSAgent) GetMove(state *game.RPSGame) (game.RPSMove, error) {
+	a.mctsEngine.SetRootState(state)
+	bestNode := a.mctsEngine.Search()
+	a.lastValue = 0.5
+
+	if bestNode == nil || bestNode.Move == nil {
+		return FallbackMove(state, a.fallback, a.mctsEngine.PolicyNetwork)
+	}
+
+	// Child values are stored from the point of view of the player choosing them
+	if visits := bestNode.Visits.Load(); visits > 0 {
+		a.lastValue = bestNode.TotalValue / float64(visits)
+	}
+
+	return *bestNode.Move, nil
+}
+
+// MovePolicy runs a search from the given state and returns the share of the
+// root's visits spent on each valid move, in GetValidMoves order. Moves the
+// search did not expand get zero.
+func (a *MCTSAgent) MovePolicy(state *game.RPSGame) ([]float64, error) {
+	a.mctsEngine.SetRootState(state)
+	a.mctsEngine.Search()
+
+	validMoves := state.GetValidMoves()
+	index := make(map[game.RPSMove]int, len(validMoves))
+	for i, move := range validMoves {
+		move.Player = 0
+		index[move] = i
+	}
+
+	policy := make([]float64, len(validMoves))
+	total := 0.0
+	for _, candidate := range a.mctsEngine.LastSearchReport().Candidates {
+		move := candidate.Move
+		move.Player = 0
+		if i, ok := index[move]; ok {
+			policy[i] = candidate.Share
+			total += candidate.Share
+		}
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("the search visited no moves")
+	}
+	return policy, nil
+}
+
+// SetFallback sets how the agent chooses a move when its search returns none.
+// The default is FallbackPolicyPrior.
+func (a *MCTSAgent) SetFallback(policy FallbackPolicy) {
+	a.fallback = policy
+}
+
+// Name returns the agent's name
+func (a *MCTSAgent) Name() string {
+	return a.name
+}
+
+// GetNodesEvaluated returns the number of simulations run for the last move
+func (a *MCTSAgent) GetNodesEvaluated() int {
+	return a.mctsEngine.GetNodesEvaluated()
+}
+
+// GetValueEstimate returns the searched win probability (0-1) of the last move
+// from the mover's point of view
+func (a *MCTSAgent) GetValueEstimate() float64 {
+	return a.lastValue
+}
+
+// Seed makes the agent's searches repeatable. Seeded searches run serially,
+// so they are slower than the default parallel search.
+func (a *MCTSAgent) Seed(seed int64) {
+	a.mctsEngine.Params.Rng = rand.New(rand.NewSource(seed))
+}
+
+// Describe returns the agent's network sizes, simulation count and training
+// games. A rollout search uses no networks and reports only its simulations.
+func (a *MCTSAgent) Describe() AgentInfo {
+	info := AgentInfo{Simulations: a.mctsEngine.Params.NumSimulations}
+	if policy := a.mctsEngine.PolicyNetwork; policy != nil {
+		info.HiddenSize = policy.GetHiddenSize()
+		info.Parameters += policy.ParameterCount()
+		info.TrainingGames = policy.Metadata().Games
+	}
+	if value := a.mctsEngine.ValueNetwork; value != nil {
+		info.Parameters += value.ParameterCount()
+	}
+	return info
+}
+
+// RandomAgent makes random valid moves
+type RandomAgent struct {
+	name string
+	rng  *rand.Rand // nil uses the global random source
+}
+
+// NewRandomAgent creates an agent that makes random moves
+func NewRandomAgent(name string) *RandomAgent {
+	return &RandomAgent{name: name}
+}
+
+// GetMove returns a random valid move
+func (a *RandomAgent) GetMove(state *game.RPSGame) (game.RPSMove, error) {
+	validMoves := state.GetValidMoves()
+	if len(validMoves) == 0 {
+		return game.RPSMove{}, fmt.Errorf("no valid moves")
+	}
+	if a.rng != nil {
+		return validMoves[a.rng.Intn(len(validMoves))], nil
+	}
+	return validMoves[rand.Intn(len(validMoves))], nil
+}
+
+// Seed makes the agent's moves repeatable
+func (a *RandomAgent) Seed(seed int64) {
+	a.rng = rand.New(rand.NewSource(seed))
+}
+
+// Name returns the agent's name
+func (a *RandomAgent) Name() string {
+	return a.name
+}
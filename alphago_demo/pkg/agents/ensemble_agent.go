@@ -0,0 +1,212 @@
+package agents
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
+)
+
+// CombineMode chooses how an EnsembleAgent combines its members' choices
+type CombineMode int
+
+const (
+	// MajorityVote plays the move most members choose. Moves that play the
+	// same card type to the same square count as one; a tie goes to the move
+	// of the member listed first.
+	MajorityVote CombineMode = iota
+
+	// AveragePolicy averages the members' move distributions and plays the
+	// most likely move. Every member must implement PolicyAgent.
+	AveragePolicy
+)
+
+// String returns the mode's name
+func (m CombineMode) String() string {
+	switch m {
+	case MajorityVote:
+		return "majority vote"
+	case AveragePolicy:
+		return "average policy"
+	}
+	return fmt.Sprintf("CombineMode(%d)", int(m))
+}
+
+// PolicyAgent is implemented by agents that can give a probability for every
+// legal move rather than only choose one
+type PolicyAgent interface {
+	Agent
+
+	// MovePolicy returns a probability for each move of state.GetValidMoves(),
+	// in the same order
+	MovePolicy(state *game.RPSGame) ([]float64, error)
+}
+
+var (
+	_ Agent       = (*EnsembleAgent)(nil)
+	_ Resetter    = (*EnsembleAgent)(nil)
+	_ Seeder      = (*EnsembleAgent)(nil)
+	_ PolicyAgent = (*MCTSAgent)(nil)
+)
+
+// EnsembleAgent asks several agents for their move and combines the answers
+type EnsembleAgent struct {
+	name    string
+	members []Agent
+	combine CombineMode
+}
+
+// NewEnsembleAgent creates an agent that combines the choices of members. It
+// returns an error if there are no members, or if combine is AveragePolicy and
+// a member cannot give a move distribution.
+func NewEnsembleAgent(name string, members []Agent, combine CombineMode) (*EnsembleAgent, error) {
+	if len(members) == 0 {
+		return nil, errors.New("an ensemble needs at least one member")
+	}
+	switch combine {
+	case MajorityVote:
+	case AveragePolicy:
+		for _, member := range members {
+			if _, ok := member.(PolicyAgent); !ok {
+				return nil, fmt.Errorf("%s cannot give a move distribution for %s", member.Name(), combine)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown combine mode %v", combine)
+	}
+
+	return &EnsembleAgent{
+		name:    name,
+		members: append([]Agent(nil), members...),
+		combine: combine,
+	}, nil
+}
+
+// Name returns the agent's name
+func (a *EnsembleAgent) Name() string {
+	return a.name
+}
+
+// Reset resets the members that keep state across the games of a match
+func (a *EnsembleAgent) Reset() {
+	for _, member := range a.members {
+		if resetter, ok := member.(Resetter); ok {
+			resetter.Reset()
+		}
+	}
+}
+
+// Seed seeds the members that make random choices, each with its own seed
+// derived from seed
+func (a *EnsembleAgent) Seed(seed int64) {
+	for i, member := range a.members {
+		if seeder, ok := member.(Seeder); ok {
+			seeder.Seed(seed + int64(i))
+		}
+	}
+}
+
+// GetMove combines the members' choices. A member that fails to choose is
+// left out; the ensemble only fails if every member does.
+func (a *EnsembleAgent) GetMove(state *game.RPSGame) (game.RPSMove, error) {
+	var move game.RPSMove
+	var err error
+	if a.combine == AveragePolicy {
+		move, err = a.averagePolicy(state)
+	} else {
+		move, err = a.majorityVote(state)
+	}
+	if err != nil {
+		return game.RPSMove{}, err
+	}
+	move.Player = state.CurrentPlayer
+	return move, nil
+}
+
+// choice identifies a move by what it plays where, so moves of different
+// cards of the same type are the same choice
+type choice struct {
+	cardType game.RPSCardType
+	position int
+}
+
+// majorityVote plays the choice most members make
+func (a *EnsembleAgent) majorityVote(state *game.RPSGame) (game.RPSMove, error) {
+	hand := state.Player1Hand
+	if state.CurrentPlayer == game.Player2 {
+		hand = state.Player2Hand
+	}
+
+	votes := make(map[choice]int)
+	var order []choice                         // Choices in the order members first made them
+	firstMove := make(map[choice]game.RPSMove) // The first member's move for each choice
+	var lastErr error
+	for _, member := range a.members {
+		move, err := member.GetMove(state.Copy())
+		if err != nil {
+			lastErr = fmt.Errorf("%s: %v", member.Name(), err)
+			continue
+		}
+		if move.CardIndex < 0 || move.CardIndex >= len(hand) {
+			lastErr = fmt.Errorf("%s: invalid card index %d", member.Name(), move.CardIndex)
+			continue
+		}
+
+		c := choice{cardType: hand[move.CardIndex].Type, position: move.Position}
+		if votes[c] == 0 {
+			order = append(order, c)
+			firstMove[c] = move
+		}
+		votes[c]++
+	}
+	if len(order) == 0 {
+		return game.RPSMove{}, fmt.Errorf("no member chose a move: %v", lastErr)
+	}
+
+	best := order[0]
+	for _, c := range order[1:] {
+		if votes[c] > votes[best] {
+			best = c
+		}
+	}
+	return firstMove[best], nil
+}
+
+// averagePolicy plays the most likely move under the members' average policy
+func (a *EnsembleAgent) averagePolicy(state *game.RPSGame) (game.RPSMove, error) {
+	validMoves := state.GetValidMoves()
+	if len(validMoves) == 0 {
+		return game.RPSMove{}, errors.New("no valid moves")
+	}
+
+	total := make([]float64, len(validMoves))
+	contributors := 0
+	var lastErr error
+	for _, member := range a.members {
+		policy, err := member.(PolicyAgent).MovePolicy(state.Copy())
+		if err == nil && len(policy) != len(validMoves) {
+			err = fmt.Errorf("gave %d probabilities for %d moves", len(policy), len(validMoves))
+		}
+		if err != nil {
+			lastErr = fmt.Errorf("%s: %v", member.Name(), err)
+			continue
+		}
+		for i, p := range policy {
+			total[i] += p
+		}
+		contributors++
+	}
+	if contributors == 0 {
+		return game.RPSMove{}, fmt.Errorf("no member gave a move distribution: %v", lastErr)
+	}
+
+	// The first of equally likely moves wins, as with the most visited child
+	// of a search
+	best := 0
+	for i := range total {
+		if total[i] > total[best] {
+			best = i
+		}
+	}
+	return validMoves[best], nil
+}
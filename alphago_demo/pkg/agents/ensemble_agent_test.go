@@ -0,0 +1,124 @@
+package agents
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/mcts"
+	neural "github.com/zachbeta/neural_rps/alphago_demo/pkg/rps_net_impl"
+)
+
+// testPositions returns positions reached by seeded random play, from the
+// opening to the last moves of a game
+func testPositions() []*game.RPSGame {
+	rng := rand.New(rand.NewSource(7))
+	var positions []*game.RPSGame
+	for plies := 0; plies < 8; plies++ {
+		g := game.NewRPSGameSeeded(21, 5, 10, rng)
+		for i := 0; i < plies && !g.IsGameOver(); i++ {
+			moves := g.GetValidMoves()
+			g.MakeMove(moves[rng.Intn(len(moves))])
+		}
+		if !g.IsGameOver() {
+			positions = append(positions, g)
+		}
+	}
+	return positions
+}
+
+// seededMCTSAgent returns an MCTS agent whose searches are repeatable
+func seededMCTSAgent(policy *neural.RPSPolicyNetwork, value *neural.RPSValueNetwork) *MCTSAgent {
+	params := mcts.DefaultRPSMCTSParams()
+	params.NumSimulations = 60
+	params.DirichletNoise = false
+	params.Rng = rand.New(rand.NewSource(1))
+	return NewMCTSAgent("MCTS", mcts.NewRPSMCTS(policy, value, params))
+}
+
+func TestEnsembleOfIdenticalAgentsMatchesSingleAgent(t *testing.T) {
+	policy := neural.NewRPSPolicyNetwork(16)
+	value := neural.NewRPSValueNetwork(16)
+
+	tests := []struct {
+		combine CombineMode
+		agent   func() Agent
+	}{
+		{MajorityVote, func() Agent { return NewMinimaxAgent("Minimax", 2, time.Hour, false) }},
+		{AveragePolicy, func() Agent { return seededMCTSAgent(policy, value) }},
+	}
+
+	for _, tt := range tests {
+		single := tt.agent()
+		members := []Agent{tt.agent(), tt.agent(), tt.agent()}
+		ensemble, err := NewEnsembleAgent("Ensemble", members, tt.combine)
+		if err != nil {
+			t.Fatalf("%v: NewEnsembleAgent failed: %v", tt.combine, err)
+		}
+
+		for i, position := range testPositions() {
+			want, err := single.GetMove(position.Copy())
+			if err != nil {
+				t.Fatalf("%v: single agent failed on position %d: %v", tt.combine, i, err)
+			}
+			got, err := ensemble.GetMove(position.Copy())
+			if err != nil {
+				t.Fatalf("%v: ensemble failed on position %d: %v", tt.combine, i, err)
+			}
+			want.Player = position.CurrentPlayer
+			if got != want {
+				t.Errorf("%v: position %d: ensemble chose %+v, single agent %+v", tt.combine, i, got, want)
+			}
+		}
+	}
+}
+
+// fixedAgent always plays the same card to the same square
+type fixedAgent struct {
+	name string
+	move game.RPSMove
+}
+
+func (a *fixedAgent) Name() string { return a.name }
+
+func (a *fixedAgent) GetMove(state *game.RPSGame) (game.RPSMove, error) {
+	return a.move, nil
+}
+
+func TestEnsembleMajorityVote(t *testing.T) {
+	state := game.NewRPSGame(21, 5, 10)
+	state.Player1Hand = []game.RPSCard{{Type: game.Rock}, {Type: game.Paper}, {Type: game.Rock}}
+
+	members := []Agent{
+		&fixedAgent{"A", game.RPSMove{CardIndex: 1, Position: 4}},
+		&fixedAgent{"B", game.RPSMove{CardIndex: 0, Position: 0}},
+		&fixedAgent{"C", game.RPSMove{CardIndex: 2, Position: 0}}, // The same choice as B's
+	}
+	ensemble, err := NewEnsembleAgent("Vote", members, MajorityVote)
+	if err != nil {
+		t.Fatalf("NewEnsembleAgent failed: %v", err)
+	}
+	move, err := ensemble.GetMove(state)
+	if err != nil {
+		t.Fatalf("GetMove failed: %v", err)
+	}
+	if move.CardIndex != 0 || move.Position != 0 || move.Player != game.Player1 {
+		t.Errorf("Expected B's rock to square 0 to win the vote, got %+v", move)
+	}
+
+	// Without a majority the first member's choice wins
+	ensemble, _ = NewEnsembleAgent("Vote", members[:2], MajorityVote)
+	if move, _ := ensemble.GetMove(state); move.CardIndex != 1 || move.Position != 4 {
+		t.Errorf("Expected A's choice to win a tie, got %+v", move)
+	}
+}
+
+func TestNewEnsembleAgentErrors(t *testing.T) {
+	if _, err := NewEnsembleAgent("Empty", nil, MajorityVote); err == nil {
+		t.Error("Expected an error for an ensemble with no members")
+	}
+	if _, err := NewEnsembleAgent("Random", []Agent{NewRandomAgent("R")}, AveragePolicy); err == nil {
+		t.Error("Expected an error averaging the policy of an agent without one")
+	}
+}
@@ -0,0 +1,86 @@
+package agents
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
+	neural "github.com/zachbeta/neural_rps/alphago_demo/pkg/rps_net_impl"
+)
+
+// FallbackPolicy chooses the move an agent plays when its search returns none
+type FallbackPolicy int
+
+const (
+	// FallbackPolicyPrior plays the legal move the policy network rates
+	// highest, or the greedy move when there is no network
+	FallbackPolicyPrior FallbackPolicy = iota
+
+	// FallbackGreedy plays the move that leaves the mover owning the most
+	// cards after captures
+	FallbackGreedy
+
+	// FallbackRandom plays a uniformly random legal move
+	FallbackRandom
+)
+
+// String returns the policy's name
+func (p FallbackPolicy) String() string {
+	switch p {
+	case FallbackPolicyPrior:
+		return "policy prior"
+	case FallbackGreedy:
+		return "greedy"
+	case FallbackRandom:
+		return "random"
+	}
+	return fmt.Sprintf("FallbackPolicy(%d)", int(p))
+}
+
+// FallbackMove returns the move policy chooses in state. policyNetwork is only
+// used by FallbackPolicyPrior and may be nil. Every policy but FallbackRandom
+// is deterministic, with ties going to the first move of GetValidMoves.
+func FallbackMove(state *game.RPSGame, policy FallbackPolicy, policyNetwork *neural.RPSPolicyNetwork) (game.RPSMove, error) {
+	validMoves := state.GetValidMoves()
+	if len(validMoves) == 0 {
+		return game.RPSMove{}, fmt.Errorf("no valid moves")
+	}
+
+	switch {
+	case policy == FallbackRandom:
+		return validMoves[rand.Intn(len(validMoves))], nil
+	case policy == FallbackPolicyPrior && policyNetwork != nil:
+		return priorMove(state, validMoves, policyNetwork), nil
+	}
+	return greedyMove(state, validMoves), nil
+}
+
+// priorMove returns the first legal move on the square the policy network
+// rates highest
+func priorMove(state *game.RPSGame, validMoves []game.RPSMove, policyNetwork *neural.RPSPolicyNetwork) game.RPSMove {
+	probs := policyNetwork.PredictMasked(state)
+	best := validMoves[0]
+	for _, move := range validMoves {
+		if probs[move.Position] > probs[best.Position] {
+			best = move
+		}
+	}
+	return best
+}
+
+// greedyMove returns the move after which the mover owns the most cards
+func greedyMove(state *game.RPSGame, validMoves []game.RPSMove) game.RPSMove {
+	mover := state.CurrentPlayer
+	best := validMoves[0]
+	bestCards := -1
+	for _, move := range validMoves {
+		next := state.Copy()
+		if err := next.MakeMove(move); err != nil {
+			continue
+		}
+		if cards := next.CountPlayerCards(mover); cards > bestCards {
+			best, bestCards = move, cards
+		}
+	}
+	return best
+}
@@ -0,0 +1,86 @@
+package agents
+
+import (
+	"testing"
+
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
+	neural "github.com/zachbeta/neural_rps/alphago_demo/pkg/rps_net_impl"
+)
+
+// capturePosition returns a position where player 1's last card, a paper,
+// owns the most cards on square 3, capturing both of player 2's rocks.
+// Squares 1 and 7 capture one rock each.
+//
+//	R . S
+//	. . .
+//	R . S
+func capturePosition() *game.RPSGame {
+	g := game.NewRPSGame(15, 5, 10)
+	for i := range g.Board {
+		g.Board[i] = game.RPSCard{}
+	}
+	g.Board[0] = game.RPSCard{Type: game.Rock, Owner: game.Player2}
+	g.Board[6] = game.RPSCard{Type: game.Rock, Owner: game.Player2}
+	g.Board[2] = game.RPSCard{Type: game.Scissors, Owner: game.Player2}
+	g.Board[8] = game.RPSCard{Type: game.Scissors, Owner: game.Player2}
+	g.SetPlayer1Hand([]int{int(game.Paper)})
+	g.SetPlayer2Hand(nil)
+	g.CurrentPlayer = game.Player1
+	return g
+}
+
+func TestFallbackGreedyMaximizesOwnedCards(t *testing.T) {
+	move, err := FallbackMove(capturePosition(), FallbackGreedy, nil)
+	if err != nil {
+		t.Fatalf("FallbackMove failed: %v", err)
+	}
+	if move.Position != 3 {
+		t.Errorf("Expected the double capture on square 3, got square %d", move.Position)
+	}
+}
+
+func TestFallbackPolicyPriorPicksMaskedArgmax(t *testing.T) {
+	policyNetwork := neural.NewRPSPolicyNetworkSeeded(16, 3)
+	for i, state := range testPositions() {
+		probs := policyNetwork.PredictMasked(state)
+		move, err := FallbackMove(state, FallbackPolicyPrior, policyNetwork)
+		if err != nil {
+			t.Fatalf("Position %d: FallbackMove failed: %v", i, err)
+		}
+		for _, other := range state.GetValidMoves() {
+			if probs[other.Position] > probs[move.Position] {
+				t.Errorf("Position %d: chose square %d with prior %v, but square %d has %v",
+					i, move.Position, probs[move.Position], other.Position, probs[other.Position])
+			}
+		}
+	}
+}
+
+func TestFallbackPolicyPriorWithoutNetworkIsGreedy(t *testing.T) {
+	state := capturePosition()
+	prior, err := FallbackMove(state, FallbackPolicyPrior, nil)
+	if err != nil {
+		t.Fatalf("FallbackMove failed: %v", err)
+	}
+	greedy, _ := FallbackMove(state, FallbackGreedy, nil)
+	if prior != greedy {
+		t.Errorf("Expected the greedy move %+v without a network, got %+v", greedy, prior)
+	}
+}
+
+func TestFallbackMoveIsDeterministic(t *testing.T) {
+	policyNetwork := neural.NewRPSPolicyNetworkSeeded(16, 3)
+	for _, policy := range []FallbackPolicy{FallbackPolicyPrior, FallbackGreedy} {
+		for i, state := range testPositions() {
+			first, err := FallbackMove(state, policy, policyNetwork)
+			if err != nil {
+				t.Fatalf("%v, position %d: FallbackMove failed: %v", policy, i, err)
+			}
+			for j := 0; j < 3; j++ {
+				if move, _ := FallbackMove(state, policy, policyNetwork); move != first {
+					t.Errorf("%v, position %d: first chose %+v, then %+v", policy, i, first, move)
+				}
+			}
+		}
+	}
+}
@@ -17,14 +17,24 @@ type MinimaxAgent struct {
 	minimaxEngine      *analysis.MinimaxEngine
 	positionsEvaluated int
 	totalMoveTime      time.Duration
+	totalDepth         int
 	moveCount          int
 	verbose            bool
+
+	lastNodes int
+	lastValue float64
 }
 
 // NewMinimaxAgent creates a new minimax-based agent
 func NewMinimaxAgent(name string, depth int, timeLimit time.Duration, useCache bool) *MinimaxAgent {
-	// Create minimax engine with StandardEvaluator
-	engine := analysis.NewMinimaxEngine(depth, analysis.StandardEvaluator)
+	return NewMinimaxAgentWithWeights(name, depth, timeLimit, useCache, analysis.DefaultEvaluationWeights())
+}
+
+// NewMinimaxAgentWithWeights creates a minimax agent that evaluates positions
+// with the given weights for material, position and card relationships.
+// Varying the weights and depth gives baselines of differing strength and style.
+func NewMinimaxAgentWithWeights(name string, depth int, timeLimit time.Duration, useCache bool, weights analysis.EvaluationWeights) *MinimaxAgent {
+	engine := analysis.NewMinimaxEngine(depth, analysis.WeightedEvaluator(weights))
 
 	// Enable transposition table if requested
 	if useCache {
@@ -59,7 +69,9 @@ func (a *MinimaxAgent) SetVerbose(verbose bool) {
 	a.verbose = verbose
 }
 
-// GetMove returns the best move according to minimax search
+// GetMove returns the best move according to minimax search. The search
+// deepens one ply at a time up to the agent's depth and returns the result of
+// the deepest search that finished within the time limit.
 func (a *MinimaxAgent) GetMove(state *game.RPSGame) (game.RPSMove, error) {
 	startTime := time.Now()
 
@@ -69,18 +81,21 @@ func (a *MinimaxAgent) GetMove(state *game.RPSGame) (game.RPSMove, error) {
 	// Update stats
 	moveTime := time.Since(startTime)
 	a.totalMoveTime += moveTime
+	a.totalDepth += a.minimaxEngine.DepthReached
 	a.moveCount++
 	a.positionsEvaluated += a.minimaxEngine.NodesEvaluated
+	a.lastNodes = a.minimaxEngine.NodesEvaluated
+	a.lastValue = value
 
 	// Log the move for analysis only if verbose mode is enabled
 	if a.verbose {
 		if a.useCache {
 			hits, misses, hitRate := a.minimaxEngine.GetCacheStats()
-			fmt.Printf("Minimax move: %v, value: %.2f, time: %v, positions: %d, cache: %d hits, %d misses (%.1f%%)\n",
-				move, value, moveTime, a.minimaxEngine.NodesEvaluated, hits, misses, hitRate)
+			fmt.Printf("Minimax move: %v, value: %.2f, time: %v, depth: %d, positions: %d, cache: %d hits, %d misses (%.1f%%)\n",
+				move, value, moveTime, a.minimaxEngine.DepthReached, a.minimaxEngine.NodesEvaluated, hits, misses, hitRate)
 		} else {
-			fmt.Printf("Minimax move: %v, value: %.2f, time: %v, positions: %d\n",
-				move, value, moveTime, a.minimaxEngine.NodesEvaluated)
+			fmt.Printf("Minimax move: %v, value: %.2f, time: %v, depth: %d, positions: %d\n",
+				move, value, moveTime, a.minimaxEngine.DepthReached, a.minimaxEngine.NodesEvaluated)
 		}
 	}
 
@@ -90,15 +105,27 @@ func (a *MinimaxAgent) GetMove(state *game.RPSGame) (game.RPSMove, error) {
 	return move, nil
 }
 
-// GetStats returns statistics about the agent's performance
-func (a *MinimaxAgent) GetStats() (avgTime time.Duration, totalPositions int, avgPositionsPerMove float64) {
+// GetNodesEvaluated returns the number of positions searched for the last move
+func (a *MinimaxAgent) GetNodesEvaluated() int {
+	return a.lastNodes
+}
+
+// GetValueEstimate returns the minimax score of the last move, in the evaluator's units
+func (a *MinimaxAgent) GetValueEstimate() float64 {
+	return a.lastValue
+}
+
+// GetStats returns statistics about the agent's performance. avgDepth is the
+// average depth the search completed within the time limit.
+func (a *MinimaxAgent) GetStats() (avgTime time.Duration, totalPositions int, avgPositionsPerMove, avgDepth float64) {
 	if a.moveCount == 0 {
-		return 0, 0, 0
+		return 0, 0, 0, 0
 	}
 
 	avgTime = a.totalMoveTime / time.Duration(a.moveCount)
 	totalPositions = a.positionsEvaluated
 	avgPositionsPerMove = float64(a.positionsEvaluated) / float64(a.moveCount)
+	avgDepth = float64(a.totalDepth) / float64(a.moveCount)
 
 	return
 }
@@ -107,6 +134,7 @@ func (a *MinimaxAgent) GetStats() (avgTime time.Duration, totalPositions int, av
 func (a *MinimaxAgent) ResetStats() {
 	a.positionsEvaluated = 0
 	a.totalMoveTime = 0
+	a.totalDepth = 0
 	a.moveCount = 0
 
 	// Also reset cache stats if using cache
@@ -115,11 +143,3 @@ func (a *MinimaxAgent) ResetStats() {
 		a.minimaxEngine.EnableTranspositionTable()
 	}
 }
-
-// Implements any Agent interface required by the game system
-type Agent interface {
-	Name() string
-	GetMove(state *game.RPSGame) (game.RPSMove, error)
-}
-
-var _ Agent = (*MinimaxAgent)(nil) // Verify MinimaxAgent implements Agent
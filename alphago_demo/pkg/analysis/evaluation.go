@@ -4,8 +4,36 @@ import (
 	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
 )
 
+// EvaluationWeights scales the factors a weighted evaluator combines. Each
+// factor already carries its own scale, so a weight of 1 uses it as is and 0
+// leaves it out.
+type EvaluationWeights struct {
+	Material     float64 // Cards owned on the board
+	Position     float64 // Control of the center and corners
+	Relationship float64 // Type advantage over adjacent opposing cards
+}
+
+// DefaultEvaluationWeights returns the weights StandardEvaluator uses
+func DefaultEvaluationWeights() EvaluationWeights {
+	return EvaluationWeights{Material: 1.0, Position: 0.5, Relationship: 0.8}
+}
+
 // StandardEvaluator provides a comprehensive evaluation function
 func StandardEvaluator(state *game.RPSGame) float64 {
+	return evaluate(state, DefaultEvaluationWeights())
+}
+
+// WeightedEvaluator returns an evaluation function that combines the factors
+// of StandardEvaluator with the given weights. Finished games score the same
+// whatever the weights.
+func WeightedEvaluator(weights EvaluationWeights) func(*game.RPSGame) float64 {
+	return func(state *game.RPSGame) float64 {
+		return evaluate(state, weights)
+	}
+}
+
+// evaluate scores state for Player1 using weights
+func evaluate(state *game.RPSGame, weights EvaluationWeights) float64 {
 	if state.IsGameOver() {
 		winner := state.GetWinner()
 		if winner == game.Player1 {
@@ -16,8 +44,18 @@ func StandardEvaluator(state *game.RPSGame) float64 {
 		return 0.0 // Draw
 	}
 
-	// Combine multiple evaluation factors with appropriate weights
-	return materialScore(state)*1.0 + positionalScore(state)*0.5 + relationshipScore(state)*0.8
+	// Combine multiple evaluation factors with the given weights
+	score := 0.0
+	if weights.Material != 0 {
+		score += materialScore(state) * weights.Material
+	}
+	if weights.Position != 0 {
+		score += positionalScore(state) * weights.Position
+	}
+	if weights.Relationship != 0 {
+		score += relationshipScore(state) * weights.Relationship
+	}
+	return score
 }
 
 // materialScore evaluates the material advantage (difference in number of cards)
@@ -31,23 +69,17 @@ func materialScore(state *game.RPSGame) float64 {
 func positionalScore(state *game.RPSGame) float64 {
 	score := 0.0
 
-	// Define position values (center is most valuable, corners next)
-	positionValues := [][]float64{
-		{0.7, 0.5, 0.7}, // Top row
-		{0.5, 1.0, 0.5}, // Middle row (center = 1.0)
-		{0.7, 0.5, 0.7}, // Bottom row
-	}
-
 	// Calculate positional score based on occupied positions
-	board := state.GetBoard()
-	for row := 0; row < 3; row++ {
-		for col := 0; col < 3; col++ {
-			pos := row*3 + col
+	board := state.Board
+	dim := state.BoardDim()
+	for row := 0; row < dim; row++ {
+		for col := 0; col < dim; col++ {
+			pos := row*dim + col
 			card := board[pos]
 			if card.Owner == game.Player1 {
-				score += positionValues[row][col]
+				score += positionValue(row, col, dim)
 			} else if card.Owner == game.Player2 {
-				score -= positionValues[row][col]
+				score -= positionValue(row, col, dim)
 			}
 		}
 	}
@@ -55,10 +87,24 @@ func positionalScore(state *game.RPSGame) float64 {
 	return score * 5.0 // Weight position appropriately
 }
 
+// positionValue returns how valuable a square is: interior squares (the center
+// on a 3x3 board) are worth most, then corners, then the rest of the edge
+func positionValue(row, col, dim int) float64 {
+	onRowEdge := row == 0 || row == dim-1
+	onColEdge := col == 0 || col == dim-1
+	switch {
+	case onRowEdge && onColEdge:
+		return 0.7
+	case onRowEdge || onColEdge:
+		return 0.5
+	}
+	return 1.0
+}
+
 // relationshipScore evaluates the RPS relationships between adjacent cards
 func relationshipScore(state *game.RPSGame) float64 {
 	score := 0.0
-	board := state.GetBoard()
+	board := state.Board
 
 	// Define directional offsets to check adjacency (horizontal, vertical, diagonal)
 	directions := []struct{ dRow, dCol int }{
@@ -66,9 +112,11 @@ func relationshipScore(state *game.RPSGame) float64 {
 	}
 
 	// Check each cell on the board
-	for row := 0; row < 3; row++ {
-		for col := 0; col < 3; col++ {
-			pos := row*3 + col
+	rules := state.GetCaptureRules()
+	dim := state.BoardDim()
+	for row := 0; row < dim; row++ {
+		for col := 0; col < dim; col++ {
+			pos := row*dim + col
 			cell := board[pos]
 			if cell.Owner == game.NoPlayer {
 				continue // Skip empty cells
@@ -79,12 +127,12 @@ func relationshipScore(state *game.RPSGame) float64 {
 				newRow, newCol := row+dir.dRow, col+dir.dCol
 
 				// Check if position is within bounds
-				if newRow >= 0 && newRow < 3 && newCol >= 0 && newCol < 3 {
-					newPos := newRow*3 + newCol
+				if newRow >= 0 && newRow < dim && newCol >= 0 && newCol < dim {
+					newPos := newRow*dim + newCol
 					adjCell := board[newPos]
 					if adjCell.Owner != game.NoPlayer && adjCell.Owner != cell.Owner {
 						// Calculate advantage based on RPS relationships
-						advantage := getCardAdvantage(cell.Type, adjCell.Type)
+						advantage := getCardAdvantage(rules, cell.Type, adjCell.Type)
 
 						if cell.Owner == game.Player1 {
 							score += advantage
@@ -100,19 +148,14 @@ func relationshipScore(state *game.RPSGame) float64 {
 	return score * 3.0 // Weight relationships appropriately
 }
 
-// getCardAdvantage returns the advantage of card1 over card2
+// getCardAdvantage returns the advantage of card1 over card2 under rules:
 // 1.0 if card1 beats card2, -1.0 if card2 beats card1, 0.0 if tie
-func getCardAdvantage(card1, card2 game.RPSCardType) float64 {
-	if card1 == card2 {
-		return 0.0 // Same card type
-	}
-
-	// Rock beats Scissors, Scissors beats Paper, Paper beats Rock
-	if (card1 == game.Rock && card2 == game.Scissors) ||
-		(card1 == game.Scissors && card2 == game.Paper) ||
-		(card1 == game.Paper && card2 == game.Rock) {
+func getCardAdvantage(rules *game.CaptureRules, card1, card2 game.RPSCardType) float64 {
+	switch {
+	case rules.Captures(card1, card2):
 		return 1.0
+	case rules.Captures(card2, card1):
+		return -1.0
 	}
-
-	return -1.0
+	return 0.0 // Same card type
 }
@@ -0,0 +1,41 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
+)
+
+func TestWeightedEvaluator(t *testing.T) {
+	standard := WeightedEvaluator(DefaultEvaluationWeights())
+	materialOnly := WeightedEvaluator(EvaluationWeights{Material: 1})
+	none := WeightedEvaluator(EvaluationWeights{})
+
+	for name, state := range benchmarkPositions() {
+		if got, want := standard(state), StandardEvaluator(state); got != want {
+			t.Errorf("%s: default weights scored %.2f, StandardEvaluator %.2f", name, got, want)
+		}
+		if state.IsGameOver() {
+			continue
+		}
+		material := float64(state.CountPlayerCards(game.Player1)-state.CountPlayerCards(game.Player2)) * 10
+		if got := materialOnly(state); got != material {
+			t.Errorf("%s: material-only weights scored %.2f, expected %.2f", name, got, material)
+		}
+		if got := none(state); got != 0 {
+			t.Errorf("%s: zero weights scored %.2f, expected 0", name, got)
+		}
+	}
+
+	// A finished game scores the same whatever the weights
+	finished := game.NewRPSGame(21, 5, 10)
+	for pos := range finished.Board {
+		finished.Board[pos] = game.RPSCard{Type: game.Rock, Owner: game.Player2}
+	}
+	if !finished.IsGameOver() {
+		t.Fatal("Expected a full board to end the game")
+	}
+	if got := none(finished); got != -1000 {
+		t.Errorf("Expected a Player2 win to score -1000 with zero weights, got %.2f", got)
+	}
+}
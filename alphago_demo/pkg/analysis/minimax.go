@@ -15,6 +15,10 @@ type MinimaxEngine struct {
 	StartTime          time.Time
 	EvaluationFn       func(*game.RPSGame) float64
 	TranspositionTable *SimpleTranspositionTable // Added transposition table
+
+	// DepthReached is the depth of the deepest search the last call to
+	// FindBestMove or FindBestMoveIterative completed within its time limit
+	DepthReached int
 }
 
 // NewMinimaxEngine creates a new minimax search engine
@@ -46,19 +50,35 @@ func (m *MinimaxEngine) GetCacheStats() (hits int, misses int, hitRate float64)
 
 // FindBestMove returns the best move for the current player
 func (m *MinimaxEngine) FindBestMove(state *game.RPSGame) (game.RPSMove, float64) {
+	m.NodesEvaluated = 0
+	m.StartTime = time.Now()
+	m.DepthReached = 0
+
+	move, value, completed := m.searchRoot(state, m.MaxDepth)
+	if completed {
+		m.DepthReached = m.MaxDepth
+	}
+	return move, value
+}
+
+// searchRoot searches state to depth within the current search's time limit
+// and reports whether the search completed. The move and value of a search
+// that ran out of time are only as good as the part of the tree it covered.
+func (m *MinimaxEngine) searchRoot(state *game.RPSGame, depth int) (game.RPSMove, float64, bool) {
 	// If we have a transposition table, check it first
 	if m.TranspositionTable != nil {
+		// The search keeps the hash up to date from here on, but the caller
+		// may have edited the position directly since it was last hashed
+		state.RecomputeHash()
+
 		if result, found := m.TranspositionTable.Get(state); found {
-			// Only use cached result if it was searched at sufficient depth
-			if result.Depth >= m.MaxDepth {
-				return result.BestMove, result.Value
+			// Only use an exact result searched at sufficient depth
+			if result.Bound == BoundExact && result.Depth >= depth {
+				return result.BestMove, result.Value, true
 			}
 		}
 	}
 
-	m.NodesEvaluated = 0
-	m.StartTime = time.Now()
-
 	// Initialize alpha-beta bounds
 	alpha := math.Inf(-1)
 	beta := math.Inf(1)
@@ -67,27 +87,46 @@ func (m *MinimaxEngine) FindBestMove(state *game.RPSGame) (game.RPSMove, float64
 	maximizingPlayer := state.CurrentPlayer == game.Player1
 
 	// Call minimax search
-	value, move := m.minimax(state, m.MaxDepth, alpha, beta, maximizingPlayer)
+	value, move := m.minimax(state, depth, alpha, beta, maximizingPlayer)
+	if m.timedOut() {
+		return move, value, false
+	}
 
-	// Cache the result if transposition table is enabled
+	// Cache the result if transposition table is enabled. The root is searched
+	// with a full window, so its value is exact.
 	if m.TranspositionTable != nil {
 		m.TranspositionTable.Put(state, PositionResult{
 			BestMove:      move,
 			Value:         value,
-			Depth:         m.MaxDepth,
+			Depth:         depth,
+			Bound:         BoundExact,
 			NodesExplored: m.NodesEvaluated,
 		})
 	}
 
-	return move, value
+	return move, value, true
 }
 
-// minimax performs alpha-beta pruned minimax search
+// minimax performs alpha-beta pruned minimax search. A value inside (alpha, beta)
+// is exact; a value at or below alpha is only an upper bound on the true value,
+// and one at or above beta only a lower bound.
 func (m *MinimaxEngine) minimax(state *game.RPSGame, depth int, alpha, beta float64, maximizingPlayer bool) (float64, game.RPSMove) {
-	// Check transposition table for this position at current depth
+	alphaOrig, betaOrig := alpha, beta
+
+	// Check transposition table for this position at current depth. An entry
+	// from a shallower search says nothing reliable about this one, and a bound
+	// can only narrow the window unless it already closes it.
 	if m.TranspositionTable != nil && depth > 0 {
-		if result, found := m.TranspositionTable.Get(state); found {
-			if result.Depth >= depth {
+		if result, found := m.TranspositionTable.Get(state); found && result.Depth >= depth {
+			switch result.Bound {
+			case BoundExact:
+				return result.Value, result.BestMove
+			case BoundLower:
+				alpha = math.Max(alpha, result.Value)
+			case BoundUpper:
+				beta = math.Min(beta, result.Value)
+			}
+			if alpha >= beta {
 				return result.Value, result.BestMove
 			}
 		}
@@ -96,7 +135,7 @@ func (m *MinimaxEngine) minimax(state *game.RPSGame, depth int, alpha, beta floa
 	m.NodesEvaluated++
 
 	// Check for timeout
-	if time.Since(m.StartTime) > m.MaxTime {
+	if m.timedOut() {
 		return m.EvaluationFn(state), game.RPSMove{}
 	}
 
@@ -118,20 +157,21 @@ func (m *MinimaxEngine) minimax(state *game.RPSGame, depth int, alpha, beta floa
 		maxEval := math.Inf(-1)
 
 		for _, move := range validMoves {
-			// Create a copy of the state and apply the move
-			nextState := state.Copy()
+			// Apply the move in place and undo it after searching the subtree
 			moveCopy := move // Create a copy to avoid reference issues
-			moveCopy.Player = nextState.CurrentPlayer
+			moveCopy.Player = state.CurrentPlayer
 
-			err := nextState.MakeMove(moveCopy)
+			undo, err := state.MakeMoveReversible(moveCopy)
 			if err != nil {
 				continue // Skip invalid moves
 			}
 
 			// Recursively evaluate the resulting position
-			eval, _ := m.minimax(nextState, depth-1, alpha, beta, !maximizingPlayer)
+			eval, _ := m.minimax(state, depth-1, alpha, beta, !maximizingPlayer)
+			state.UndoMove(undo)
 
-			// Update maxEval and bestMove if we found a better move
+			// Update maxEval and bestMove if we found a better move. Ties keep the
+			// earlier move, so the choice does not depend on what the table holds.
 			if eval > maxEval {
 				maxEval = eval
 				bestMove = move
@@ -146,33 +186,25 @@ func (m *MinimaxEngine) minimax(state *game.RPSGame, depth int, alpha, beta floa
 			}
 		}
 
-		// Store result in transposition table if enabled
-		if m.TranspositionTable != nil && depth > 0 {
-			m.TranspositionTable.Put(state, PositionResult{
-				BestMove:      bestMove,
-				Value:         maxEval,
-				Depth:         depth,
-				NodesExplored: 0, // Not tracked per subtree
-			})
-		}
+		m.storeResult(state, depth, maxEval, bestMove, alphaOrig, betaOrig)
 
 		return maxEval, bestMove
 	} else {
 		minEval := math.Inf(1)
 
 		for _, move := range validMoves {
-			// Create a copy of the state and apply the move
-			nextState := state.Copy()
+			// Apply the move in place and undo it after searching the subtree
 			moveCopy := move // Create a copy to avoid reference issues
-			moveCopy.Player = nextState.CurrentPlayer
+			moveCopy.Player = state.CurrentPlayer
 
-			err := nextState.MakeMove(moveCopy)
+			undo, err := state.MakeMoveReversible(moveCopy)
 			if err != nil {
 				continue // Skip invalid moves
 			}
 
 			// Recursively evaluate the resulting position
-			eval, _ := m.minimax(nextState, depth-1, alpha, beta, !maximizingPlayer)
+			eval, _ := m.minimax(state, depth-1, alpha, beta, !maximizingPlayer)
+			state.UndoMove(undo)
 
 			// Update minEval and bestMove if we found a better move
 			if eval < minEval {
@@ -189,46 +221,71 @@ func (m *MinimaxEngine) minimax(state *game.RPSGame, depth int, alpha, beta floa
 			}
 		}
 
-		// Store result in transposition table if enabled
-		if m.TranspositionTable != nil && depth > 0 {
-			m.TranspositionTable.Put(state, PositionResult{
-				BestMove:      bestMove,
-				Value:         minEval,
-				Depth:         depth,
-				NodesExplored: 0, // Not tracked per subtree
-			})
-		}
+		m.storeResult(state, depth, minEval, bestMove, alphaOrig, betaOrig)
 
 		return minEval, bestMove
 	}
 }
 
-// FindBestMoveIterative performs iterative deepening search
+// storeResult records a search result in the transposition table, if enabled,
+// marking whether the value is exact or a bound given the window the position
+// was searched with. Results from a search that ran out of time are not stored.
+func (m *MinimaxEngine) storeResult(state *game.RPSGame, depth int, value float64, bestMove game.RPSMove, alpha, beta float64) {
+	if m.TranspositionTable == nil || depth <= 0 || m.timedOut() {
+		return
+	}
+
+	bound := BoundExact
+	if value <= alpha {
+		bound = BoundUpper
+	} else if value >= beta {
+		bound = BoundLower
+	}
+
+	m.TranspositionTable.Put(state, PositionResult{
+		BestMove:      bestMove,
+		Value:         value,
+		Depth:         depth,
+		Bound:         bound,
+		NodesExplored: 0, // Not tracked per subtree
+	})
+}
+
+// timedOut reports whether the current search has used up its time
+func (m *MinimaxEngine) timedOut() bool {
+	return time.Since(m.StartTime) > m.MaxTime
+}
+
+// FindBestMoveIterative searches to depth 1, 2 and so on up to MaxDepth,
+// returning the result of the deepest search completed within maxTime, so the
+// depth adapts to how hard the position is. A search that runs out of time is
+// discarded. The depth 1 search is cheap and always runs to completion, so
+// there is a move to return however short the limit. DepthReached records the
+// depth of the result, and NodesEvaluated counts the nodes of every search.
 func (m *MinimaxEngine) FindBestMoveIterative(state *game.RPSGame, maxTime time.Duration) (game.RPSMove, float64) {
 	m.NodesEvaluated = 0
 	m.StartTime = time.Now()
-	m.MaxTime = maxTime
+	m.MaxTime = time.Duration(math.MaxInt64)
+	m.DepthReached = 0
+	defer func() { m.MaxTime = maxTime }()
 
 	var bestMove game.RPSMove
 	var bestValue float64
 
-	// Start with depth 1 and increase gradually
 	for depth := 1; depth <= m.MaxDepth; depth++ {
-		// If we've used more than 80% of our time, don't start a new iteration
-		if float64(time.Since(m.StartTime)) > float64(maxTime)*0.8 {
+		// Each search takes longer than all the shallower ones together, so
+		// one started after half the time is unlikely to finish
+		if depth > 1 && time.Since(m.StartTime) > maxTime/2 {
 			break
 		}
 
-		move, value := m.FindBestMove(state)
-
-		// Keep track of the best move found so far
-		if time.Since(m.StartTime) <= maxTime {
-			bestMove = move
-			bestValue = value
-		} else {
-			// If we timed out during this iteration, the results might be unreliable
+		move, value, completed := m.searchRoot(state, depth)
+		m.MaxTime = maxTime
+		if !completed {
 			break
 		}
+		bestMove, bestValue = move, value
+		m.DepthReached = depth
 
 		// If we found a forced win/loss, no need to search deeper
 		if value > 900 || value < -900 {
@@ -238,3 +295,21 @@ func (m *MinimaxEngine) FindBestMoveIterative(state *game.RPSGame, maxTime time.
 
 	return bestMove, bestValue
 }
+
+// BestMovesByDepth searches state to each depth from minDepth to maxDepth in
+// turn, as iterative deepening does, and returns the best move found at each:
+// moves[i] is the move at depth minDepth+i. Comparing them shows whether the
+// choice is stable or changes as the search looks further ahead. The time
+// limit applies to each depth separately, and MaxDepth is left unchanged.
+func (m *MinimaxEngine) BestMovesByDepth(state *game.RPSGame, minDepth, maxDepth int) []game.RPSMove {
+	savedDepth := m.MaxDepth
+	defer func() { m.MaxDepth = savedDepth }()
+
+	moves := make([]game.RPSMove, 0, maxDepth-minDepth+1)
+	for depth := minDepth; depth <= maxDepth; depth++ {
+		m.MaxDepth = depth
+		move, _ := m.FindBestMove(state)
+		moves = append(moves, move)
+	}
+	return moves
+}
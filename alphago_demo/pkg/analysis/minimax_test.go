@@ -0,0 +1,178 @@
+package analysis
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
+)
+
+// benchmarkPositions returns the early, mid and end game positions used by
+// cmd/test_minimax, followed by positions reached by seeded random play
+func benchmarkPositions() map[string]*game.RPSGame {
+	positions := make(map[string]*game.RPSGame)
+
+	early := game.NewRPSGame(21, 5, 10)
+	early.Board[0] = game.RPSCard{Type: game.Rock, Owner: game.Player1}
+	early.Board[8] = game.RPSCard{Type: game.Scissors, Owner: game.Player2}
+	early.Player1Hand = []game.RPSCard{
+		{Type: game.Rock, Owner: game.Player1},
+		{Type: game.Paper, Owner: game.Player1},
+		{Type: game.Scissors, Owner: game.Player1},
+		{Type: game.Rock, Owner: game.Player1},
+	}
+	early.Player2Hand = []game.RPSCard{
+		{Type: game.Rock, Owner: game.Player2},
+		{Type: game.Paper, Owner: game.Player2},
+		{Type: game.Scissors, Owner: game.Player2},
+		{Type: game.Paper, Owner: game.Player2},
+	}
+	early.CurrentPlayer = game.Player1
+	positions["early"] = early
+
+	mid := game.NewRPSGame(21, 5, 10)
+	mid.Board[0] = game.RPSCard{Type: game.Rock, Owner: game.Player1}
+	mid.Board[1] = game.RPSCard{Type: game.Paper, Owner: game.Player1}
+	mid.Board[2] = game.RPSCard{Type: game.Scissors, Owner: game.Player2}
+	mid.Board[3] = game.RPSCard{Type: game.Paper, Owner: game.Player2}
+	mid.Board[4] = game.RPSCard{Type: game.Scissors, Owner: game.Player1}
+	mid.Board[6] = game.RPSCard{Type: game.Rock, Owner: game.Player2}
+	mid.Board[8] = game.RPSCard{Type: game.Paper, Owner: game.Player1}
+	mid.Player1Hand = []game.RPSCard{
+		{Type: game.Rock, Owner: game.Player1},
+		{Type: game.Paper, Owner: game.Player1},
+	}
+	mid.Player2Hand = []game.RPSCard{
+		{Type: game.Rock, Owner: game.Player2},
+		{Type: game.Scissors, Owner: game.Player2},
+	}
+	mid.CurrentPlayer = game.Player2
+	positions["mid"] = mid
+
+	end := game.NewRPSGame(21, 5, 10)
+	end.Board[0] = game.RPSCard{Type: game.Rock, Owner: game.Player1}
+	end.Board[1] = game.RPSCard{Type: game.Paper, Owner: game.Player1}
+	end.Board[2] = game.RPSCard{Type: game.Scissors, Owner: game.Player2}
+	end.Board[3] = game.RPSCard{Type: game.Paper, Owner: game.Player2}
+	end.Board[4] = game.RPSCard{Type: game.Scissors, Owner: game.Player1}
+	end.Board[5] = game.RPSCard{Type: game.Rock, Owner: game.Player2}
+	end.Board[6] = game.RPSCard{Type: game.Rock, Owner: game.Player2}
+	end.Board[7] = game.RPSCard{Type: game.Paper, Owner: game.Player1}
+	end.Player1Hand = []game.RPSCard{{Type: game.Scissors, Owner: game.Player1}}
+	end.Player2Hand = []game.RPSCard{{Type: game.Paper, Owner: game.Player2}}
+	end.CurrentPlayer = game.Player1
+	positions["end"] = end
+
+	rng := rand.New(rand.NewSource(3))
+	for _, plies := range []int{0, 1, 2, 3} {
+		g := game.NewRPSGameSeeded(21, 5, 10, rng)
+		for i := 0; i < plies; i++ {
+			moves := g.GetValidMoves()
+			g.MakeMove(moves[rng.Intn(len(moves))])
+		}
+		positions[fmt.Sprintf("random after %d plies", plies)] = g
+	}
+
+	return positions
+}
+
+func TestTranspositionTableMatchesPlainSearch(t *testing.T) {
+	const depth = 6
+
+	for name, position := range benchmarkPositions() {
+		plain := NewMinimaxEngine(depth, StandardEvaluator)
+		plain.MaxTime = time.Hour
+		wantMove, wantValue := plain.FindBestMove(position.Copy())
+
+		cached := NewMinimaxEngine(depth, StandardEvaluator)
+		cached.MaxTime = time.Hour
+		cached.EnableTranspositionTable()
+		gotMove, gotValue := cached.FindBestMove(position.Copy())
+
+		if gotMove != wantMove || gotValue != wantValue {
+			t.Errorf("%s: with the table got %+v (%v), without %+v (%v)",
+				name, gotMove, gotValue, wantMove, wantValue)
+		}
+
+		// Searching again answers from the table with the same result
+		againMove, againValue := cached.FindBestMove(position.Copy())
+		if againMove != wantMove || againValue != wantValue {
+			t.Errorf("%s: second search got %+v (%v), want %+v (%v)",
+				name, againMove, againValue, wantMove, wantValue)
+		}
+	}
+}
+
+func TestBestMovesByDepthMatchesSingleSearches(t *testing.T) {
+	for name, position := range benchmarkPositions() {
+		engine := NewMinimaxEngine(2, StandardEvaluator)
+		engine.MaxTime = time.Hour
+		engine.EnableTranspositionTable()
+		moves := engine.BestMovesByDepth(position.Copy(), 1, 4)
+
+		if len(moves) != 4 || engine.MaxDepth != 2 {
+			t.Fatalf("%s: got %d moves and MaxDepth %d, want 4 moves and MaxDepth left at 2",
+				name, len(moves), engine.MaxDepth)
+		}
+		for i, move := range moves {
+			single := NewMinimaxEngine(i+1, StandardEvaluator)
+			single.MaxTime = time.Hour
+			if want, _ := single.FindBestMove(position.Copy()); move != want {
+				t.Errorf("%s: depth %d move %+v, a single search finds %+v", name, i+1, move, want)
+			}
+		}
+	}
+}
+
+func TestFindBestMoveIterativeReachesMaxDepth(t *testing.T) {
+	for name, position := range benchmarkPositions() {
+		if position.IsGameOver() {
+			continue
+		}
+		engine := NewMinimaxEngine(4, StandardEvaluator)
+		engine.EnableTranspositionTable()
+		move, value := engine.FindBestMoveIterative(position.Copy(), time.Hour)
+
+		// Only a forced result stops the search short of MaxDepth
+		forced := value > 900 || value < -900
+		if engine.DepthReached != 4 && !(forced && engine.DepthReached > 0) {
+			t.Errorf("%s: reached depth %d with value %v, want 4", name, engine.DepthReached, value)
+			continue
+		}
+
+		single := NewMinimaxEngine(engine.DepthReached, StandardEvaluator)
+		single.MaxTime = time.Hour
+		if want, _ := single.FindBestMove(position.Copy()); move != want {
+			t.Errorf("%s: iterative search chose %+v, a depth %d search %+v", name, move, engine.DepthReached, want)
+		}
+	}
+}
+
+func TestFindBestMoveIterativeHonorsTimeLimit(t *testing.T) {
+	// A full-depth search of the opening takes far longer than the limit
+	position := game.NewRPSGameSeeded(21, 5, 10, rand.New(rand.NewSource(3)))
+	for _, limit := range []time.Duration{time.Nanosecond, 20 * time.Millisecond} {
+		engine := NewMinimaxEngine(9, StandardEvaluator)
+		start := time.Now()
+		move, _ := engine.FindBestMoveIterative(position.Copy(), limit)
+		elapsed := time.Since(start)
+
+		if elapsed > limit+200*time.Millisecond {
+			t.Errorf("Limit %v: search took %v", limit, elapsed)
+		}
+		if engine.DepthReached < 1 || engine.DepthReached >= 9 {
+			t.Errorf("Limit %v: reached depth %d, want between 1 and 8", limit, engine.DepthReached)
+		}
+		if engine.MaxTime != limit {
+			t.Errorf("Limit %v: MaxTime left at %v", limit, engine.MaxTime)
+		}
+
+		// The move is the depth-limited search's choice, so it is legal
+		move.Player = position.CurrentPlayer
+		if err := position.Copy().MakeMove(move); err != nil {
+			t.Errorf("Limit %v: chose an illegal move %+v: %v", limit, move, err)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package analysis
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
+)
+
+// MoveScore is the minimax evaluation of one legal move
+type MoveScore struct {
+	Move  game.RPSMove
+	Value float64 // Minimax value after the move; player 1 maximizes
+	Rank  int     // 1 for the best move; moves with equal values share a rank
+	Gap   float64 // How much worse than the best move, from the mover's perspective
+}
+
+// ScoreMoves searches every legal move to the engine's depth and returns them
+// best first for the player to move. Unlike FindBestMove, no move is pruned at
+// the root, so every move gets an exact value.
+func (m *MinimaxEngine) ScoreMoves(state *game.RPSGame) []MoveScore {
+	m.NodesEvaluated = 0
+	m.StartTime = time.Now()
+
+	work := state.Copy()
+	if m.TranspositionTable != nil {
+		work.RecomputeHash()
+	}
+	mover := work.CurrentPlayer
+	moves := append([]game.RPSMove(nil), work.GetValidMoves()...)
+
+	depth := m.MaxDepth - 1
+	if depth < 0 {
+		depth = 0
+	}
+
+	scores := make([]MoveScore, 0, len(moves))
+	for _, move := range moves {
+		move.Player = mover
+		undo, err := work.MakeMoveReversible(move)
+		if err != nil {
+			continue
+		}
+		value, _ := m.minimax(work, depth, math.Inf(-1), math.Inf(1), work.CurrentPlayer == game.Player1)
+		work.UndoMove(undo)
+
+		scores = append(scores, MoveScore{Move: move, Value: value})
+	}
+
+	// Player 1 prefers high values and player 2 low ones
+	better := func(a, b float64) bool {
+		if mover == game.Player1 {
+			return a > b
+		}
+		return a < b
+	}
+	sort.SliceStable(scores, func(i, j int) bool {
+		return better(scores[i].Value, scores[j].Value)
+	})
+
+	for i := range scores {
+		scores[i].Rank = i + 1
+		if i > 0 && scores[i].Value == scores[i-1].Value {
+			scores[i].Rank = scores[i-1].Rank
+		}
+		scores[i].Gap = math.Abs(scores[0].Value - scores[i].Value)
+	}
+
+	return scores
+}
+
+// FindMoveScore returns the score of the move with the given card and position
+func FindMoveScore(scores []MoveScore, move game.RPSMove) (MoveScore, bool) {
+	for _, score := range scores {
+		if score.Move.CardIndex == move.CardIndex && score.Move.Position == move.Position {
+			return score, true
+		}
+	}
+	return MoveScore{}, false
+}
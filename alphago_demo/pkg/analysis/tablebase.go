@@ -0,0 +1,504 @@
+package analysis
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
+)
+
+// TablebaseResult is the exact result of a position for the player to move
+type TablebaseResult int8
+
+const (
+	TablebaseLoss TablebaseResult = -1
+	TablebaseDraw TablebaseResult = 0
+	TablebaseWin  TablebaseResult = 1
+)
+
+// String returns "win", "draw" or "loss"
+func (r TablebaseResult) String() string {
+	switch r {
+	case TablebaseWin:
+		return "win"
+	case TablebaseLoss:
+		return "loss"
+	default:
+		return "draw"
+	}
+}
+
+// Value returns the result on the value network's scale: 1 for a win, 0.5 for
+// a draw and 0 for a loss
+func (r TablebaseResult) Value() float64 {
+	return (float64(r) + 1) / 2
+}
+
+// TablebaseEntry is a solved position: its result with best play and a move
+// that achieves it
+type TablebaseEntry struct {
+	Result   TablebaseResult
+	Position int              // Square the best move plays on
+	CardType game.RPSCardType // Card the best move plays
+}
+
+// Tablebase holds the exact result and best move of every endgame position
+// with at most MaxEmpty empty squares that can arise from the standard deal:
+// a 3x3 board, five-card hands and the standard capture rules.
+//
+// A square none of whose neighbours is empty can never change hands again, and
+// its card type no longer matters, so positions are stored by the squares
+// that can still change plus how many of the settled ones each player owns.
+// The key packs these, both hands and the player to move into 64 bits exactly,
+// so unlike a Zobrist hash two positions only share a key when they share a
+// result. Even so the table grows quickly: about 114 thousand positions with
+// one empty square, 7 million with up to two and 47 million with up to three,
+// which is more than fits comfortably in memory.
+type Tablebase struct {
+	MaxEmpty int
+	entries  map[uint64]uint16
+}
+
+const (
+	tablebaseBoardSize = 9
+	tablebaseHandSize  = 5
+
+	// Squares whose card can no longer change are stored as this cell code
+	tablebaseSettled = 7
+
+	tablebaseMagic   = "RPSTB"
+	tablebaseVersion = 1
+)
+
+// BuildTablebase enumerates and solves every standard-deal position with at
+// most maxEmpty empty squares (1 to 9). Positions are solved in order of
+// increasing empty squares, so each one is decided by looking its successors
+// up in the positions already solved.
+func BuildTablebase(maxEmpty int) *Tablebase {
+	if maxEmpty < 1 {
+		maxEmpty = 1
+	} else if maxEmpty > tablebaseBoardSize {
+		maxEmpty = tablebaseBoardSize
+	}
+
+	t := &Tablebase{MaxEmpty: maxEmpty, entries: make(map[uint64]uint16)}
+	for empty := 1; empty <= maxEmpty; empty++ {
+		t.solveLevel(empty)
+	}
+	return t
+}
+
+// solveLevel solves every position with exactly empty empty squares
+func (t *Tablebase) solveLevel(empty int) {
+	// Player1 moves first, so after an even number of cards it is their turn
+	played := tablebaseBoardSize - empty
+	p1Played, p2Played := (played+1)/2, played/2
+	if p1Played > tablebaseHandSize {
+		return
+	}
+	toMove := game.Player1
+	if played%2 == 1 {
+		toMove = game.Player2
+	}
+	p1Hands := handMultisets(tablebaseHandSize - p1Played)
+	p2Hands := handMultisets(tablebaseHandSize - p2Played)
+
+	g := game.NewRPSGame(0, 0, 10)
+	g.SetRound(1 + played/2)
+	g.CurrentPlayer = toMove
+
+	for _, emptySet := range squareSubsets(empty) {
+		var open, settled []int
+		for pos := 0; pos < tablebaseBoardSize; pos++ {
+			switch {
+			case emptySet&(1<<pos) != 0:
+			case tablebaseAdjacent(pos)&emptySet != 0:
+				open = append(open, pos)
+			default:
+				settled = append(settled, pos)
+			}
+		}
+
+		// Each open square holds one of six cards
+		assignments := 1
+		for range open {
+			assignments *= 6
+		}
+		for a := 0; a < assignments; a++ {
+			for pos := range g.Board {
+				g.Board[pos] = game.RPSCard{}
+			}
+			code := a
+			for _, pos := range open {
+				g.Board[pos] = cellCard(code%6 + 1)
+				code /= 6
+			}
+
+			// Settled squares only count towards the final score
+			for p1Settled := 0; p1Settled <= len(settled); p1Settled++ {
+				for i, pos := range settled {
+					owner := game.Player2
+					if i < p1Settled {
+						owner = game.Player1
+					}
+					g.Board[pos] = game.RPSCard{Type: game.Rock, Owner: owner}
+				}
+
+				for _, p1Hand := range p1Hands {
+					g.SetPlayer1Hand(p1Hand)
+					for _, p2Hand := range p2Hands {
+						g.SetPlayer2Hand(p2Hand)
+						t.solve(g)
+					}
+				}
+			}
+		}
+	}
+}
+
+// solve returns the packed entry for g, solving it and storing the result if
+// it is not in the table yet. g is left unchanged.
+func (t *Tablebase) solve(g *game.RPSGame) uint16 {
+	key, ok := tablebaseKey(g)
+	if ok {
+		if entry, found := t.entries[key]; found {
+			return entry
+		}
+	}
+
+	mover := g.CurrentPlayer
+	hand := g.Player1Hand
+	if mover == game.Player2 {
+		hand = g.Player2Hand
+	}
+
+	best := packEntry(TablebaseLoss, 0, 0)
+	bestResult := TablebaseResult(-2)
+	var tried [tablebaseBoardSize][3]bool
+	for _, move := range g.GetValidMoves() {
+		card := hand[move.CardIndex].Type
+		if tried[move.Position][card] {
+			continue
+		}
+		tried[move.Position][card] = true
+
+		undo, err := g.MakeMoveReversible(move)
+		if err != nil {
+			continue
+		}
+		var result TablebaseResult
+		if g.IsGameOver() {
+			result = tablebaseWinner(g, mover)
+		} else {
+			result = -unpackEntry(t.solve(g)).Result
+		}
+		g.UndoMove(undo)
+
+		if result > bestResult {
+			bestResult = result
+			best = packEntry(result, move.Position, card)
+			if result == TablebaseWin {
+				break
+			}
+		}
+	}
+
+	if ok {
+		t.entries[key] = best
+	}
+	return best
+}
+
+// tablebaseWinner scores a finished game for player
+func tablebaseWinner(g *game.RPSGame, player game.RPSPlayer) TablebaseResult {
+	switch g.GetWinner() {
+	case player:
+		return TablebaseWin
+	case game.NoPlayer:
+		return TablebaseDraw
+	default:
+		return TablebaseLoss
+	}
+}
+
+// Len returns the number of positions in the tablebase
+func (t *Tablebase) Len() int {
+	return len(t.entries)
+}
+
+// Probe looks up the exact result and best move for g. It reports false for
+// positions outside the table: more than MaxEmpty empty squares, another
+// board size, hands the standard deal cannot produce, non-standard capture
+// rules, or a round limit that could end the game before the board fills.
+func (t *Tablebase) Probe(g *game.RPSGame) (TablebaseEntry, bool) {
+	if g.GetCaptureRules() != game.StandardCaptureRules() {
+		return TablebaseEntry{}, false
+	}
+
+	empty := 0
+	for _, card := range g.Board {
+		if card.Owner == game.NoPlayer {
+			empty++
+		}
+	}
+	if empty == 0 || empty > t.MaxEmpty || g.MaxRounds-g.Round < empty {
+		return TablebaseEntry{}, false
+	}
+
+	key, ok := tablebaseKey(g)
+	if !ok {
+		return TablebaseEntry{}, false
+	}
+	entry, found := t.entries[key]
+	if !found {
+		return TablebaseEntry{}, false
+	}
+	return unpackEntry(entry), true
+}
+
+// BestMove returns the tablebase's move for g and the result it leads to, or
+// false if the position is not in the table
+func (t *Tablebase) BestMove(g *game.RPSGame) (game.RPSMove, TablebaseResult, bool) {
+	entry, found := t.Probe(g)
+	if !found {
+		return game.RPSMove{}, TablebaseDraw, false
+	}
+
+	hand := g.Player1Hand
+	if g.CurrentPlayer == game.Player2 {
+		hand = g.Player2Hand
+	}
+	for i, card := range hand {
+		if card.Type == entry.CardType {
+			move := game.RPSMove{CardIndex: i, Position: entry.Position, Player: g.CurrentPlayer}
+			return move, entry.Result, true
+		}
+	}
+	return game.RPSMove{}, TablebaseDraw, false
+}
+
+// tablebaseKey packs the parts of g that decide its result: the cell code of
+// every square that can still change (settled squares are coded as
+// tablebaseSettled), how many settled squares Player1 owns, the card counts
+// in both hands and the player to move. It reports false for positions the
+// key cannot represent.
+func tablebaseKey(g *game.RPSGame) (uint64, bool) {
+	if len(g.Board) != tablebaseBoardSize {
+		return 0, false
+	}
+
+	var emptySet int
+	for pos, card := range g.Board {
+		if card.Owner == game.NoPlayer {
+			emptySet |= 1 << pos
+		}
+	}
+
+	var key uint64
+	p1Settled := 0
+	for pos, card := range g.Board {
+		code := 0
+		switch {
+		case card.Owner == game.NoPlayer:
+		case tablebaseAdjacent(pos)&emptySet == 0:
+			code = tablebaseSettled
+			if card.Owner == game.Player1 {
+				p1Settled++
+			}
+		case card.Owner == game.Player1:
+			code = int(card.Type) + 1
+		default:
+			code = int(card.Type) + 4
+		}
+		key |= uint64(code) << (3 * pos)
+	}
+	key |= uint64(p1Settled) << 27
+
+	shift := uint(31)
+	for _, hand := range [][]game.RPSCard{g.Player1Hand, g.Player2Hand} {
+		var counts [3]int
+		for _, card := range hand {
+			if card.Type < game.Rock || card.Type > game.Scissors {
+				return 0, false
+			}
+			counts[card.Type]++
+		}
+		for _, count := range counts {
+			if count > 7 {
+				return 0, false
+			}
+			key |= uint64(count) << shift
+			shift += 3
+		}
+	}
+
+	if g.CurrentPlayer == game.Player2 {
+		key |= 1 << 49
+	}
+	return key, true
+}
+
+// cellCard returns the card for cell codes 1-3 (Player1's rock, paper and
+// scissors) and 4-6 (Player2's)
+func cellCard(code int) game.RPSCard {
+	if code <= 3 {
+		return game.RPSCard{Type: game.RPSCardType(code - 1), Owner: game.Player1}
+	}
+	return game.RPSCard{Type: game.RPSCardType(code - 4), Owner: game.Player2}
+}
+
+// tablebaseAdjacent returns the squares orthogonally next to pos as a bit set
+func tablebaseAdjacent(pos int) int {
+	row, col := pos/3, pos%3
+	adjacent := 0
+	if row > 0 {
+		adjacent |= 1 << (pos - 3)
+	}
+	if row < 2 {
+		adjacent |= 1 << (pos + 3)
+	}
+	if col > 0 {
+		adjacent |= 1 << (pos - 1)
+	}
+	if col < 2 {
+		adjacent |= 1 << (pos + 1)
+	}
+	return adjacent
+}
+
+// squareSubsets returns every set of n board squares as a bit set
+func squareSubsets(n int) []int {
+	var subsets []int
+	for set := 0; set < 1<<tablebaseBoardSize; set++ {
+		bits := 0
+		for s := set; s != 0; s &= s - 1 {
+			bits++
+		}
+		if bits == n {
+			subsets = append(subsets, set)
+		}
+	}
+	return subsets
+}
+
+// handMultisets returns every hand of size cards, as card types in order
+func handMultisets(size int) [][]int {
+	var hands [][]int
+	for rock := 0; rock <= size; rock++ {
+		for paper := 0; rock+paper <= size; paper++ {
+			hand := make([]int, 0, size)
+			for i := 0; i < rock; i++ {
+				hand = append(hand, int(game.Rock))
+			}
+			for i := 0; i < paper; i++ {
+				hand = append(hand, int(game.Paper))
+			}
+			for i := rock + paper; i < size; i++ {
+				hand = append(hand, int(game.Scissors))
+			}
+			hands = append(hands, hand)
+		}
+	}
+	return hands
+}
+
+// packEntry stores an entry in 16 bits: the result plus one in bits 0-1, the
+// square in bits 2-5 and the card type in bits 6-7
+func packEntry(result TablebaseResult, position int, cardType game.RPSCardType) uint16 {
+	return uint16(result+1) | uint16(position)<<2 | uint16(cardType)<<6
+}
+
+// unpackEntry reverses packEntry
+func unpackEntry(packed uint16) TablebaseEntry {
+	return TablebaseEntry{
+		Result:   TablebaseResult(packed&3) - 1,
+		Position: int(packed>>2) & 15,
+		CardType: game.RPSCardType(packed>>6) & 3,
+	}
+}
+
+// Save writes the tablebase to path in a compact binary format: a header with
+// the format version, MaxEmpty and the entry count, then each key and packed
+// entry in key order
+func (t *Tablebase) Save(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := t.write(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// write encodes the tablebase to w
+func (t *Tablebase) write(w io.Writer) error {
+	keys := make([]uint64, 0, len(t.entries))
+	for key := range t.entries {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString(tablebaseMagic)
+	header := []uint32{tablebaseVersion, uint32(t.MaxEmpty), uint32(len(keys))}
+	if err := binary.Write(bw, binary.LittleEndian, header); err != nil {
+		return err
+	}
+
+	var record [10]byte
+	for _, key := range keys {
+		binary.LittleEndian.PutUint64(record[:8], key)
+		binary.LittleEndian.PutUint16(record[8:], t.entries[key])
+		if _, err := bw.Write(record[:]); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// LoadTablebase reads a tablebase written by Save
+func LoadTablebase(path string) (*Tablebase, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	t, err := readTablebase(bufio.NewReader(file))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return t, nil
+}
+
+// readTablebase decodes a tablebase from r
+func readTablebase(r io.Reader) (*Tablebase, error) {
+	magic := make([]byte, len(tablebaseMagic))
+	if _, err := io.ReadFull(r, magic); err != nil || string(magic) != tablebaseMagic {
+		return nil, errors.New("not a tablebase file")
+	}
+	var header [3]uint32
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return nil, err
+	}
+	if header[0] != tablebaseVersion {
+		return nil, fmt.Errorf("unsupported tablebase version %d", header[0])
+	}
+
+	t := &Tablebase{MaxEmpty: int(header[1]), entries: make(map[uint64]uint16, header[2])}
+	var record [10]byte
+	for i := uint32(0); i < header[2]; i++ {
+		if _, err := io.ReadFull(r, record[:]); err != nil {
+			return nil, fmt.Errorf("entry %d: %v", i, err)
+		}
+		t.entries[binary.LittleEndian.Uint64(record[:8])] = binary.LittleEndian.Uint16(record[8:])
+	}
+	return t, nil
+}
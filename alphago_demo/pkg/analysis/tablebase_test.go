@@ -0,0 +1,109 @@
+package analysis
+
+import (
+	"math/rand"
+	"path/filepath"
+	"testing"
+
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
+)
+
+// endgamePositions plays random games from the standard deal until empty
+// squares are left, and returns the positions reached
+func endgamePositions(rng *rand.Rand, empty, count int) []*game.RPSGame {
+	var positions []*game.RPSGame
+	for len(positions) < count {
+		g := game.NewRPSGameSeeded(21, 5, 10, rng)
+		for played := 0; played < 9-empty && !g.IsGameOver(); played++ {
+			moves := g.GetValidMoves()
+			g.MakeMove(moves[rng.Intn(len(moves))])
+		}
+		if !g.IsGameOver() {
+			positions = append(positions, g)
+		}
+	}
+	return positions
+}
+
+func TestTablebaseMatchesMinimax(t *testing.T) {
+	tablebase := BuildTablebase(2)
+	if tablebase.Len() == 0 {
+		t.Fatal("Expected the tablebase to hold positions")
+	}
+
+	rng := rand.New(rand.NewSource(5))
+	for _, empty := range []int{1, 2} {
+		for i, g := range endgamePositions(rng, empty, 200) {
+			entry, found := tablebase.Probe(g)
+			if !found {
+				t.Fatalf("Position %d with %d empty squares is missing from the tablebase", i, empty)
+			}
+
+			// Searching to the end of the game is exact
+			engine := NewMinimaxEngine(empty, StandardEvaluator)
+			_, score := engine.FindBestMove(g.Copy())
+			if g.CurrentPlayer == game.Player2 {
+				score = -score
+			}
+			want := TablebaseDraw
+			if score > 0 {
+				want = TablebaseWin
+			} else if score < 0 {
+				want = TablebaseLoss
+			}
+			if entry.Result != want {
+				t.Errorf("Position %d with %d empty squares: tablebase says %v, minimax %v (score %.0f)",
+					i, empty, entry.Result, want, score)
+			}
+
+			// Playing the tablebase's move keeps the result
+			move, result, ok := tablebase.BestMove(g)
+			if !ok || result != entry.Result {
+				t.Fatalf("Position %d: BestMove returned %v, %v", i, result, ok)
+			}
+			after := g.Copy()
+			if err := after.MakeMove(move); err != nil {
+				t.Fatalf("Position %d: best move %+v is illegal: %v", i, move, err)
+			}
+			got := tablebaseWinner(after, g.CurrentPlayer)
+			if !after.IsGameOver() {
+				next, found := tablebase.Probe(after)
+				if !found {
+					t.Fatalf("Position %d: the position after the best move is missing", i)
+				}
+				got = -next.Result
+			}
+			if got != entry.Result {
+				t.Errorf("Position %d: the best move leads to a %v, not a %v", i, got, entry.Result)
+			}
+		}
+	}
+
+	// Positions outside the table are not found
+	opening := game.NewRPSGame(21, 5, 10)
+	if _, found := tablebase.Probe(opening); found {
+		t.Error("Expected the opening position not to be in the tablebase")
+	}
+}
+
+func TestTablebaseSaveLoad(t *testing.T) {
+	tablebase := BuildTablebase(1)
+	path := filepath.Join(t.TempDir(), "endgames.tb")
+	if err := tablebase.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := LoadTablebase(path)
+	if err != nil {
+		t.Fatalf("LoadTablebase failed: %v", err)
+	}
+	if loaded.MaxEmpty != tablebase.MaxEmpty || loaded.Len() != tablebase.Len() {
+		t.Fatalf("Loaded %d positions up to %d empty, want %d up to %d",
+			loaded.Len(), loaded.MaxEmpty, tablebase.Len(), tablebase.MaxEmpty)
+	}
+	for key, entry := range tablebase.entries {
+		if loaded.entries[key] != entry {
+			t.Fatalf("Entry %x differs after loading", key)
+		}
+	}
+}
@@ -1,24 +1,38 @@
 package analysis
 
 import (
-	"fmt"
-	"strings"
 	"sync"
 
 	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
 )
 
+// BoundType says how a stored value relates to the position's true minimax value
+// at the stored depth. Alpha-beta only learns a bound when a search is cut off.
+type BoundType int
+
+const (
+	BoundExact BoundType = iota // The value is exact
+	BoundLower                  // The search failed high: the true value is at least Value
+	BoundUpper                  // The search failed low: the true value is at most Value
+)
+
 // PositionResult stores the result of a minimax search for a given position
 type PositionResult struct {
 	BestMove      game.RPSMove
 	Value         float64
 	Depth         int
+	Bound         BoundType
 	NodesExplored int
 }
 
-// SimpleTranspositionTable caches position evaluations in memory
+// SimpleTranspositionTable caches position evaluations in memory.
+//
+// Positions are keyed by their 64-bit Zobrist hash, which covers the board,
+// both hands, the player to move and the round. Two different positions share
+// a key only by chance; with a million entries the odds that any pair does are
+// about one in thirty million, so entries are not verified further.
 type SimpleTranspositionTable struct {
-	entries map[string]PositionResult
+	entries map[uint64]PositionResult
 	mu      sync.RWMutex
 	hits    int
 	misses  int
@@ -27,13 +41,13 @@ type SimpleTranspositionTable struct {
 // NewSimpleTranspositionTable creates a new transposition table
 func NewSimpleTranspositionTable() *SimpleTranspositionTable {
 	return &SimpleTranspositionTable{
-		entries: make(map[string]PositionResult),
+		entries: make(map[uint64]PositionResult),
 	}
 }
 
 // Get retrieves a cached position result
 func (t *SimpleTranspositionTable) Get(position *game.RPSGame) (PositionResult, bool) {
-	key := positionToKey(position)
+	key := position.Hash()
 
 	t.mu.RLock()
 	result, found := t.entries[key]
@@ -52,12 +66,15 @@ func (t *SimpleTranspositionTable) Get(position *game.RPSGame) (PositionResult,
 	return result, found
 }
 
-// Put stores a position result in the cache
+// Put stores a position result in the cache. An entry from a deeper search is
+// kept rather than replaced by a shallower one, since it can answer more probes.
 func (t *SimpleTranspositionTable) Put(position *game.RPSGame, result PositionResult) {
-	key := positionToKey(position)
+	key := position.Hash()
 
 	t.mu.Lock()
-	t.entries[key] = result
+	if existing, found := t.entries[key]; !found || result.Depth >= existing.Depth {
+		t.entries[key] = result
+	}
 	t.mu.Unlock()
 }
 
@@ -88,47 +105,8 @@ func (t *SimpleTranspositionTable) Size() int {
 // Clear empties the cache
 func (t *SimpleTranspositionTable) Clear() {
 	t.mu.Lock()
-	t.entries = make(map[string]PositionResult)
+	t.entries = make(map[uint64]PositionResult)
 	t.hits = 0
 	t.misses = 0
 	t.mu.Unlock()
 }
-
-// positionToKey generates a string key from a position
-func positionToKey(position *game.RPSGame) string {
-	// Simple representation of board state as a string
-	var sb strings.Builder
-
-	// Encode board
-	for i := 0; i < 9; i++ {
-		card := position.Board[i]
-		if card.Owner == game.NoPlayer {
-			sb.WriteString(".")
-		} else {
-			var symbol string
-			switch card.Type {
-			case game.Rock:
-				symbol = "R"
-			case game.Paper:
-				symbol = "P"
-			case game.Scissors:
-				symbol = "S"
-			}
-
-			if card.Owner == game.Player1 {
-				sb.WriteString(symbol)
-			} else {
-				sb.WriteString(strings.ToLower(symbol))
-			}
-		}
-	}
-
-	// Encode current player
-	sb.WriteString(fmt.Sprintf("|%d", position.CurrentPlayer))
-
-	// Encode hand sizes (don't need exact cards, just counts)
-	sb.WriteString(fmt.Sprintf("|%d|%d",
-		len(position.Player1Hand), len(position.Player2Hand)))
-
-	return sb.String()
-}
@@ -0,0 +1,121 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+)
+
+// NumCardTypes is the number of card types: Rock, Paper and Scissors
+const NumCardTypes = 3
+
+// Capture states that a card of type Winner captures an adjacent card of type Loser
+type Capture struct {
+	Winner RPSCardType
+	Loser  RPSCardType
+}
+
+// CaptureRules is the beats relation applied when a placed card captures its
+// neighbours. Rules never change once built, so games and their copies share them.
+type CaptureRules struct {
+	beats [NumCardTypes][NumCardTypes]bool
+}
+
+// standardCaptureRules is the usual cycle: Rock > Scissors > Paper > Rock
+var standardCaptureRules = &CaptureRules{
+	beats: [NumCardTypes][NumCardTypes]bool{
+		Rock:     {Scissors: true},
+		Paper:    {Rock: true},
+		Scissors: {Paper: true},
+	},
+}
+
+// StandardCaptureRules returns the standard Rock > Scissors > Paper > Rock rules
+func StandardCaptureRules() *CaptureRules {
+	return standardCaptureRules
+}
+
+// NewCaptureRules builds rules from a list of captures. The result must be a
+// cyclic tournament: every pair of different types has exactly one winner, and
+// every type both captures and can be captured by some other type.
+func NewCaptureRules(captures ...Capture) (*CaptureRules, error) {
+	rules := &CaptureRules{}
+	for _, c := range captures {
+		if !validCardType(c.Winner) || !validCardType(c.Loser) {
+			return nil, fmt.Errorf("unknown card type in capture %d > %d", c.Winner, c.Loser)
+		}
+		if c.Winner == c.Loser {
+			return nil, fmt.Errorf("%s cannot capture itself", cardTypeName(c.Winner))
+		}
+		if rules.beats[c.Loser][c.Winner] {
+			return nil, fmt.Errorf("%s and %s capture each other", cardTypeName(c.Winner), cardTypeName(c.Loser))
+		}
+		rules.beats[c.Winner][c.Loser] = true
+	}
+
+	if err := rules.Validate(); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// Validate checks that the rules form a cyclic tournament
+func (r *CaptureRules) Validate() error {
+	if r == nil {
+		return errors.New("capture rules are nil")
+	}
+
+	for a := RPSCardType(0); a < NumCardTypes; a++ {
+		if r.beats[a][a] {
+			return fmt.Errorf("%s cannot capture itself", cardTypeName(a))
+		}
+
+		captures, capturedBy := 0, 0
+		for b := RPSCardType(0); b < NumCardTypes; b++ {
+			if a == b {
+				continue
+			}
+			if r.beats[a][b] == r.beats[b][a] {
+				return fmt.Errorf("exactly one of %s and %s must capture the other", cardTypeName(a), cardTypeName(b))
+			}
+			if r.beats[a][b] {
+				captures++
+			} else {
+				capturedBy++
+			}
+		}
+
+		if captures == 0 {
+			return fmt.Errorf("%s never captures anything", cardTypeName(a))
+		}
+		if capturedBy == 0 {
+			return fmt.Errorf("%s can never be captured", cardTypeName(a))
+		}
+	}
+	return nil
+}
+
+// Captures reports whether a card of type attacker captures an adjacent card of type defender
+func (r *CaptureRules) Captures(attacker, defender RPSCardType) bool {
+	if !validCardType(attacker) || !validCardType(defender) {
+		return false
+	}
+	return r.beats[attacker][defender]
+}
+
+// validCardType reports whether t is one of the NumCardTypes card types
+func validCardType(t RPSCardType) bool {
+	return t >= 0 && t < NumCardTypes
+}
+
+// cardTypeName returns the display name of a card type
+func cardTypeName(t RPSCardType) string {
+	switch t {
+	case Rock:
+		return "Rock"
+	case Paper:
+		return "Paper"
+	case Scissors:
+		return "Scissors"
+	}
+	return fmt.Sprintf("type %d", t)
+}
@@ -0,0 +1,100 @@
+package game
+
+import "testing"
+
+// playAgainstAllTypes places an attacker of the given type in the centre of a
+// board whose orthogonal neighbours hold an opposing Rock, Paper and Scissors,
+// and returns which of those three were captured
+func playAgainstAllTypes(t *testing.T, rules *CaptureRules, attacker RPSCardType) [NumCardTypes]bool {
+	t.Helper()
+
+	g := NewRPSGame(15, 5, 10)
+	if err := g.SetCaptureRules(rules); err != nil {
+		t.Fatalf("SetCaptureRules failed: %v", err)
+	}
+
+	defenders := map[int]RPSCardType{1: Rock, 3: Paper, 5: Scissors}
+	for pos, cardType := range defenders {
+		g.Board[pos] = RPSCard{Type: cardType, Owner: Player2}
+	}
+	g.Player1Hand[0] = RPSCard{Type: attacker}
+	if err := g.MakeMove(RPSMove{CardIndex: 0, Position: 4, Player: Player1}); err != nil {
+		t.Fatalf("MakeMove failed: %v", err)
+	}
+
+	var captured [NumCardTypes]bool
+	for pos, cardType := range defenders {
+		captured[cardType] = g.Board[pos].Owner == Player1
+	}
+	return captured
+}
+
+func TestStandardCaptureRulesMatchAllMatchups(t *testing.T) {
+	// The captures the game has always made: Rock > Scissors > Paper > Rock
+	expected := [NumCardTypes][NumCardTypes]bool{
+		Rock:     {Rock: false, Paper: false, Scissors: true},
+		Paper:    {Rock: true, Paper: false, Scissors: false},
+		Scissors: {Rock: false, Paper: true, Scissors: false},
+	}
+
+	for _, rules := range []*CaptureRules{nil, StandardCaptureRules()} {
+		for attacker := RPSCardType(0); attacker < NumCardTypes; attacker++ {
+			captured := playAgainstAllTypes(t, rules, attacker)
+			if captured != expected[attacker] {
+				t.Errorf("%s captured %v, want %v", cardTypeName(attacker), captured, expected[attacker])
+			}
+		}
+	}
+}
+
+func TestReversedCaptureRules(t *testing.T) {
+	reversed, err := NewCaptureRules(
+		Capture{Winner: Scissors, Loser: Rock},
+		Capture{Winner: Rock, Loser: Paper},
+		Capture{Winner: Paper, Loser: Scissors},
+	)
+	if err != nil {
+		t.Fatalf("NewCaptureRules failed: %v", err)
+	}
+
+	for attacker := RPSCardType(0); attacker < NumCardTypes; attacker++ {
+		captured := playAgainstAllTypes(t, reversed, attacker)
+		for defender := RPSCardType(0); defender < NumCardTypes; defender++ {
+			want := attacker != defender && StandardCaptureRules().Captures(defender, attacker)
+			if captured[defender] != want {
+				t.Errorf("%s vs %s: captured %v, want %v",
+					cardTypeName(attacker), cardTypeName(defender), captured[defender], want)
+			}
+		}
+	}
+
+	// Copies keep the rules
+	g := NewRPSGame(15, 5, 10)
+	g.SetCaptureRules(reversed)
+	if g.Copy().GetCaptureRules() != reversed {
+		t.Error("Copy did not keep the capture rules")
+	}
+}
+
+func TestNewCaptureRulesRejectsInvalidRelations(t *testing.T) {
+	tests := []struct {
+		name     string
+		captures []Capture
+	}{
+		{"self capture", []Capture{{Rock, Rock}, {Rock, Scissors}, {Paper, Rock}, {Scissors, Paper}}},
+		{"mutual capture", []Capture{{Rock, Scissors}, {Scissors, Rock}, {Paper, Rock}, {Scissors, Paper}}},
+		{"undecided pair", []Capture{{Rock, Scissors}, {Paper, Rock}}},
+		{"dominant type", []Capture{{Rock, Scissors}, {Rock, Paper}, {Paper, Scissors}}},
+		{"unknown type", []Capture{{Rock, RPSCardType(7)}}},
+	}
+
+	for _, tt := range tests {
+		if _, err := NewCaptureRules(tt.captures...); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+
+	if err := NewRPSGame(15, 5, 10).SetCaptureRules(&CaptureRules{}); err == nil {
+		t.Error("SetCaptureRules accepted rules where nothing captures")
+	}
+}
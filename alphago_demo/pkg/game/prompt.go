@@ -0,0 +1,114 @@
+package game
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ErrInputClosed is returned by Prompter when its input ends, for example
+// after Ctrl-D or at the end of piped input
+var ErrInputClosed = errors.New("input closed")
+
+// Prompter reads answers to prompts for the interactive commands. A command
+// should read all of its input through one Prompter: mixing readers over the
+// same stream loses whatever one of them has buffered. Invalid answers are
+// reported and asked for again; once the input ends every method returns
+// ErrInputClosed, so a caller can stop instead of re-prompting forever.
+type Prompter struct {
+	in  *bufio.Scanner
+	out io.Writer
+}
+
+// NewPrompter creates a Prompter reading answers from in and writing prompts to out
+func NewPrompter(in io.Reader, out io.Writer) *Prompter {
+	return &Prompter{in: bufio.NewScanner(in), out: out}
+}
+
+// Line prints prompt and returns the next line of input with surrounding space removed
+func (p *Prompter) Line(prompt string) (string, error) {
+	fmt.Fprint(p.out, prompt)
+	if !p.in.Scan() {
+		if err := p.in.Err(); err != nil {
+			return "", err
+		}
+		fmt.Fprintln(p.out)
+		return "", ErrInputClosed
+	}
+	return strings.TrimSpace(p.in.Text()), nil
+}
+
+// Int asks until it reads a whole number in [min, max]
+func (p *Prompter) Int(prompt string, min, max int) (int, error) {
+	n, _, err := p.IntOr(prompt, min, max)
+	return n, err
+}
+
+// IntOr is like Int but also accepts any of words, in any case. It returns
+// the number, or the word typed as it appears in words.
+func (p *Prompter) IntOr(prompt string, min, max int, words ...string) (int, string, error) {
+	for {
+		line, err := p.Line(prompt)
+		if err != nil {
+			return 0, "", err
+		}
+
+		for _, word := range words {
+			if strings.EqualFold(line, word) {
+				return 0, word, nil
+			}
+		}
+
+		n, err := strconv.Atoi(line)
+		if err != nil || n < min || n > max {
+			if len(words) > 0 {
+				fmt.Fprintf(p.out, "Please enter a number from %d to %d or %s.\n", min, max, strings.Join(words, ", "))
+			} else {
+				fmt.Fprintf(p.out, "Please enter a number from %d to %d.\n", min, max)
+			}
+			continue
+		}
+		return n, "", nil
+	}
+}
+
+// YesNo asks until it reads y, yes, n or no, in any case
+func (p *Prompter) YesNo(prompt string) (bool, error) {
+	for {
+		line, err := p.Line(prompt)
+		if err != nil {
+			return false, err
+		}
+
+		switch strings.ToLower(line) {
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		fmt.Fprintln(p.out, "Please answer y or n.")
+	}
+}
+
+// RowCol asks until it reads a square as "row,col" or "row col", both in [0, dim)
+func (p *Prompter) RowCol(prompt string, dim int) (row, col int, err error) {
+	for {
+		line, err := p.Line(prompt)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		parts := strings.FieldsFunc(line, func(r rune) bool { return r == ',' || r == ' ' })
+		if len(parts) == 2 {
+			row, rowErr := strconv.Atoi(parts[0])
+			col, colErr := strconv.Atoi(parts[1])
+			if rowErr == nil && colErr == nil && row >= 0 && row < dim && col >= 0 && col < dim {
+				return row, col, nil
+			}
+		}
+		fmt.Fprintf(p.out, "Please enter row,col with both from 0 to %d (e.g., 1,2).\n", dim-1)
+	}
+}
@@ -0,0 +1,61 @@
+package game
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPrompterIntReprompts(t *testing.T) {
+	var out bytes.Buffer
+	p := NewPrompter(strings.NewReader("abc\n7\n 2 \n"), &out)
+
+	n, err := p.Int("Pick: ", 0, 4)
+	if err != nil || n != 2 {
+		t.Fatalf("Int returned %d, %v; want 2", n, err)
+	}
+	if got := strings.Count(out.String(), "Pick: "); got != 3 {
+		t.Errorf("Expected 3 prompts, got %d:\n%s", got, out.String())
+	}
+}
+
+func TestPrompterEndOfInput(t *testing.T) {
+	p := NewPrompter(strings.NewReader("5\n"), &bytes.Buffer{})
+
+	// Invalid answers until the input ends must not loop forever
+	if _, err := p.Int("Pick: ", 0, 4); !errors.Is(err, ErrInputClosed) {
+		t.Errorf("Int: expected ErrInputClosed, got %v", err)
+	}
+	if _, err := p.YesNo("Again? "); !errors.Is(err, ErrInputClosed) {
+		t.Errorf("YesNo: expected ErrInputClosed, got %v", err)
+	}
+	if _, _, err := p.RowCol("Square: ", 3); !errors.Is(err, ErrInputClosed) {
+		t.Errorf("RowCol: expected ErrInputClosed, got %v", err)
+	}
+}
+
+func TestPrompterRowColAndYesNo(t *testing.T) {
+	p := NewPrompter(strings.NewReader("3,0\n1\n2 1\nmaybe\nYES\n"), &bytes.Buffer{})
+
+	row, col, err := p.RowCol("Square: ", 3)
+	if err != nil || row != 2 || col != 1 {
+		t.Errorf("RowCol returned %d,%d, %v; want 2,1", row, col, err)
+	}
+
+	yes, err := p.YesNo("Again? ")
+	if err != nil || !yes {
+		t.Errorf("YesNo returned %v, %v; want true", yes, err)
+	}
+}
+
+func TestPrompterIntOrAcceptsWords(t *testing.T) {
+	p := NewPrompter(strings.NewReader("Undo\n3\n"), &bytes.Buffer{})
+
+	if _, word, err := p.IntOr("Card: ", 0, 4, "undo"); err != nil || word != "undo" {
+		t.Errorf("IntOr returned word %q, %v; want undo", word, err)
+	}
+	if n, word, err := p.IntOr("Card: ", 0, 4, "undo"); err != nil || word != "" || n != 3 {
+		t.Errorf("IntOr returned %d, %q, %v; want 3", n, word, err)
+	}
+}
@@ -3,7 +3,9 @@ package game
 import (
 	"errors"
 	"fmt"
+	"math"
 	"math/rand"
+	"strconv"
 	"strings"
 )
 
@@ -40,19 +42,62 @@ type RPSMove struct {
 
 // RPSGame represents the game state
 type RPSGame struct {
-	Board         [9]RPSCard // 3x3 board
+	Board         []RPSCard // Square board stored row by row, 3x3 unless created by NewRPSGameSized
 	Player1Hand   []RPSCard
 	Player2Hand   []RPSCard
 	CurrentPlayer RPSPlayer
 	MoveHistory   []RPSMove
 	Round         int
 	MaxRounds     int
+
+	// Capture rules used by MakeMove; nil means StandardCaptureRules
+	rules *CaptureRules
+
+	// Cached result of GetValidMoves, valid while validMovesKey matches the position
+	validMoves    []RPSMove
+	validMovesKey uint64
+
+	// Zobrist hash of the position, maintained by MakeMove once Hash has been called
+	hash      uint64
+	hashValid bool
 }
 
-// NewRPSGame creates a new RPS card game
+const (
+	// StandardBoardDim is the side length of the standard 3x3 board
+	StandardBoardDim = 3
+
+	// MinBoardDim and MaxBoardDim bound the board sizes NewRPSGameSized accepts
+	MinBoardDim = 2
+	MaxBoardDim = 7
+
+	// FeaturesPerPosition is the number of features encoded for each board square
+	FeaturesPerPosition = 9
+
+	// ProgressFeatureCount is the number of features GetProgressFeatures returns
+	ProgressFeatureCount = 4
+)
+
+// NewRPSGame creates a new RPS card game, shuffling the deck with the global random source
 func NewRPSGame(deckSize int, handSize int, maxRounds int) *RPSGame {
+	return NewRPSGameSeeded(deckSize, handSize, maxRounds, nil)
+}
+
+// NewRPSGameSeeded creates a new RPS card game, shuffling the deck with rng so
+// the same source state always produces the same deal. A nil rng uses the
+// global random source.
+func NewRPSGameSeeded(deckSize int, handSize int, maxRounds int, rng *rand.Rand) *RPSGame {
+	return NewRPSGameSized(StandardBoardDim, deckSize, handSize, maxRounds, rng)
+}
+
+// NewRPSGameSized creates a game on a boardDim x boardDim board, dealing like
+// NewRPSGameSeeded. It panics if boardDim is outside [MinBoardDim, MaxBoardDim].
+func NewRPSGameSized(boardDim int, deckSize int, handSize int, maxRounds int, rng *rand.Rand) *RPSGame {
+	if boardDim < MinBoardDim || boardDim > MaxBoardDim {
+		panic(fmt.Sprintf("board dimension %d is outside [%d, %d]", boardDim, MinBoardDim, MaxBoardDim))
+	}
+
 	game := &RPSGame{
-		Board:         [9]RPSCard{},
+		Board:         make([]RPSCard, boardDim*boardDim),
 		Player1Hand:   make([]RPSCard, 0, handSize),
 		Player2Hand:   make([]RPSCard, 0, handSize),
 		CurrentPlayer: Player1, // Player1 goes first
@@ -62,7 +107,7 @@ func NewRPSGame(deckSize int, handSize int, maxRounds int) *RPSGame {
 	}
 
 	// Generate deck
-	deck := generateDeck(deckSize)
+	deck := generateDeck(deckSize, rng)
 
 	// Deal cards
 	game.dealCards(deck, handSize)
@@ -70,8 +115,8 @@ func NewRPSGame(deckSize int, handSize int, maxRounds int) *RPSGame {
 	return game
 }
 
-// generateDeck creates a deck of cards with roughly equal distribution of types
-func generateDeck(size int) []RPSCard {
+// generateDeck creates a shuffled deck of cards with roughly equal distribution of types
+func generateDeck(size int, rng *rand.Rand) []RPSCard {
 	deck := make([]RPSCard, size)
 	for i := 0; i < size; i++ {
 		cardType := RPSCardType(i % 3) // Cycle through Rock, Paper, Scissors
@@ -79,9 +124,14 @@ func generateDeck(size int) []RPSCard {
 	}
 
 	// Shuffle deck
-	rand.Shuffle(len(deck), func(i, j int) {
+	swap := func(i, j int) {
 		deck[i], deck[j] = deck[j], deck[i]
-	})
+	}
+	if rng != nil {
+		rng.Shuffle(len(deck), swap)
+	} else {
+		rand.Shuffle(len(deck), swap)
+	}
 
 	return deck
 }
@@ -97,9 +147,53 @@ func (g *RPSGame) dealCards(deck []RPSCard, handSize int) {
 	}
 }
 
-// GetValidMoves returns all valid moves for the current player
+// GetValidMoves returns all valid moves for the current player, ordered by
+// position and then by card index: every card for square 0 in hand order, then
+// every card for the next empty square, and so on. The order depends only on the
+// position, so it is the same for copies of a game and across calls, and agents
+// that break ties by taking the first of equal moves choose reproducibly.
+// The result is cached until the position changes, so the returned slice is
+// shared between calls and must not be modified.
 func (g *RPSGame) GetValidMoves() []RPSMove {
-	var moves []RPSMove
+	key := g.movesKey()
+	if g.validMoves != nil && g.validMovesKey == key {
+		return g.validMoves
+	}
+
+	g.validMoves = g.computeValidMoves()
+	g.validMovesKey = key
+	return g.validMoves
+}
+
+// movesKey summarizes everything the valid move list depends on: board occupancy,
+// the player to move, the size of their hand and whether the round limit has
+// passed. Keying the cache on it keeps results correct even when callers edit
+// the board, hands or round directly.
+func (g *RPSGame) movesKey() uint64 {
+	const maxCells = MaxBoardDim * MaxBoardDim
+
+	var key uint64
+	for pos := range g.Board {
+		if g.Board[pos].Owner == NoPlayer {
+			key |= 1 << pos
+		}
+	}
+
+	handSize := len(g.Player1Hand)
+	if g.CurrentPlayer != Player1 {
+		handSize = len(g.Player2Hand)
+	}
+	key |= uint64(g.CurrentPlayer&3) << maxCells
+	key |= uint64(handSize) << (maxCells + 2)
+	if g.Round > g.MaxRounds {
+		key |= 1 << 63
+	}
+
+	return key
+}
+
+// computeValidMoves builds the valid move list from scratch
+func (g *RPSGame) computeValidMoves() []RPSMove {
 	var hand []RPSCard
 
 	if g.CurrentPlayer == Player1 {
@@ -108,8 +202,15 @@ func (g *RPSGame) GetValidMoves() []RPSMove {
 		hand = g.Player2Hand
 	}
 
-	// Find empty positions on the board
-	for pos := 0; pos < 9; pos++ {
+	// Never return nil so an empty list is cached too
+	if g.Round > g.MaxRounds {
+		return []RPSMove{}
+	}
+	moves := make([]RPSMove, 0, len(g.Board)*len(hand))
+
+	// Empty positions in board order, then cards in hand order, as documented
+	// by GetValidMoves
+	for pos := range g.Board {
 		if g.Board[pos].Owner == NoPlayer {
 			// For each card in hand
 			for i := range hand {
@@ -128,7 +229,10 @@ func (g *RPSGame) GetValidMoves() []RPSMove {
 // MakeMove applies a move to the game state
 func (g *RPSGame) MakeMove(move RPSMove) error {
 	// Check if the move is valid
-	if move.Position < 0 || move.Position >= 9 {
+	if g.Round > g.MaxRounds {
+		return errors.New("the round limit has been reached")
+	}
+	if move.Position < 0 || move.Position >= len(g.Board) {
 		return errors.New("position is out of bounds")
 	}
 	if g.Board[move.Position].Owner != NoPlayer {
@@ -154,17 +258,30 @@ func (g *RPSGame) MakeMove(move RPSMove) error {
 	card.Owner = move.Player
 	g.Board[move.Position] = card
 
+	if g.hashValid {
+		// The hand loses its last card of this type; the board gains the card
+		g.hash ^= zobrist.handKey(move.Player, card.Type, countType(*hand, card.Type)-1)
+		g.hash ^= zobrist.cellKey(move.Position, card)
+	}
+
 	// Remove card from hand
 	*hand = append((*hand)[:move.CardIndex], (*hand)[move.CardIndex+1:]...)
 
 	// Add to move history
 	g.MoveHistory = append(g.MoveHistory, move)
+	g.validMoves = nil
 
 	// Switch player
+	if g.hashValid {
+		g.hash ^= zobrist.player2ToMove
+	}
 	if g.CurrentPlayer == Player1 {
 		g.CurrentPlayer = Player2
 	} else {
 		g.CurrentPlayer = Player1
+		if g.hashValid {
+			g.hash ^= zobrist.roundKey(g.Round) ^ zobrist.roundKey(g.Round+1)
+		}
 		g.Round++
 	}
 
@@ -174,54 +291,190 @@ func (g *RPSGame) MakeMove(move RPSMove) error {
 	return nil
 }
 
-// processCapturesAt checks and processes potential captures around the given position
-func (g *RPSGame) processCapturesAt(position int) {
-	row := position / 3
-	col := position % 3
+// MoveUndo records what MakeMoveReversible changed so UndoMove can restore it
+type MoveUndo struct {
+	move          RPSMove
+	card          RPSCard // The card as it was in the player's hand
+	currentPlayer RPSPlayer
+	round         int
+
+	// Squares a move can change, the target and its neighbours, as they were
+	// before the move, including pre-capture ownership
+	cells    [5]boardCell
+	numCells int
+
+	validMoves    []RPSMove
+	validMovesKey uint64
+
+	hash      uint64
+	hashValid bool
+}
+
+// boardCell is the card on one square of the board
+type boardCell struct {
+	pos  int
+	card RPSCard
+}
+
+// MakeMoveReversible applies a move like MakeMove and returns a token that
+// UndoMove uses to restore the previous position. Searches can use it to explore
+// moves on a single game instead of copying the game at every node.
+func (g *RPSGame) MakeMoveReversible(move RPSMove) (MoveUndo, error) {
+	undo := MoveUndo{
+		move:          move,
+		currentPlayer: g.CurrentPlayer,
+		round:         g.Round,
+		validMoves:    g.validMoves,
+		validMovesKey: g.validMovesKey,
+		hash:          g.hash,
+		hashValid:     g.hashValid,
+	}
+
+	hand := g.Player1Hand
+	if move.Player == Player2 {
+		hand = g.Player2Hand
+	}
+	if move.CardIndex >= 0 && move.CardIndex < len(hand) {
+		undo.card = hand[move.CardIndex]
+	}
+
+	if move.Position >= 0 && move.Position < len(g.Board) {
+		undo.cells[0] = boardCell{pos: move.Position, card: g.Board[move.Position]}
+		undo.numCells = 1
+		neighbours, n := g.neighbours(move.Position)
+		for _, pos := range neighbours[:n] {
+			undo.cells[undo.numCells] = boardCell{pos: pos, card: g.Board[pos]}
+			undo.numCells++
+		}
+	}
+
+	if err := g.MakeMove(move); err != nil {
+		return MoveUndo{}, err
+	}
+	return undo, nil
+}
+
+// UndoMove reverts the move made by the MakeMoveReversible call that returned undo.
+// Moves must be undone in the reverse order they were made.
+func (g *RPSGame) UndoMove(undo MoveUndo) {
+	// Put the card back where it was in the hand
+	hand := &g.Player1Hand
+	if undo.move.Player == Player2 {
+		hand = &g.Player2Hand
+	}
+	idx := undo.move.CardIndex
+	*hand = append(*hand, RPSCard{})
+	copy((*hand)[idx+1:], (*hand)[idx:])
+	(*hand)[idx] = undo.card
+
+	for _, cell := range undo.cells[:undo.numCells] {
+		g.Board[cell.pos] = cell.card
+	}
+	g.MoveHistory = g.MoveHistory[:len(g.MoveHistory)-1]
+	g.CurrentPlayer = undo.currentPlayer
+	g.Round = undo.round
+	g.validMoves = undo.validMoves
+	g.validMovesKey = undo.validMovesKey
+	g.hash = undo.hash
+	g.hashValid = undo.hashValid
+}
+
+// BoardDim returns the side length of the board
+func (g *RPSGame) BoardDim() int {
+	dim := 0
+	for dim*dim < len(g.Board) {
+		dim++
+	}
+	return dim
+}
+
+// neighbours returns the positions orthogonally adjacent to position, in the
+// order up, right, down, left, and how many of them are on the board
+func (g *RPSGame) neighbours(position int) ([4]int, int) {
+	dim := g.BoardDim()
+	row := position / dim
+	col := position % dim
 
 	// Positions to check (adjacent positions: up, right, down, left)
-	directions := []struct{ dr, dc int }{
+	directions := [4]struct{ dr, dc int }{
 		{-1, 0}, {0, 1}, {1, 0}, {0, -1},
 	}
 
+	var result [4]int
+	n := 0
 	for _, dir := range directions {
 		newRow := row + dir.dr
 		newCol := col + dir.dc
 
 		// Check if position is within bounds
-		if newRow >= 0 && newRow < 3 && newCol >= 0 && newCol < 3 {
-			newPos := newRow*3 + newCol
-
-			// If there's a card and it belongs to the opponent
-			if g.Board[newPos].Owner != NoPlayer && g.Board[newPos].Owner != g.Board[position].Owner {
-				// Check if our card beats theirs
-				if g.cardBeats(g.Board[position], g.Board[newPos]) {
-					// Capture the card
-					captured := g.Board[newPos]
-					captured.Owner = g.Board[position].Owner
-					g.Board[newPos] = captured
+		if newRow >= 0 && newRow < dim && newCol >= 0 && newCol < dim {
+			result[n] = newRow*dim + newCol
+			n++
+		}
+	}
+	return result, n
+}
+
+// processCapturesAt checks and processes potential captures around the given position
+func (g *RPSGame) processCapturesAt(position int) {
+	neighbours, n := g.neighbours(position)
+	for _, newPos := range neighbours[:n] {
+		// If there's a card and it belongs to the opponent
+		if g.Board[newPos].Owner != NoPlayer && g.Board[newPos].Owner != g.Board[position].Owner {
+			// Check if our card beats theirs
+			if g.cardBeats(g.Board[position], g.Board[newPos]) {
+				// Capture the card
+				captured := g.Board[newPos]
+				captured.Owner = g.Board[position].Owner
+				if g.hashValid {
+					g.hash ^= zobrist.cellKey(newPos, g.Board[newPos]) ^ zobrist.cellKey(newPos, captured)
 				}
+				g.Board[newPos] = captured
 			}
 		}
 	}
 }
 
-// cardBeats checks if card1 beats card2 in RPS
+// cardBeats checks if card1 beats card2 under the game's capture rules
 func (g *RPSGame) cardBeats(card1, card2 RPSCard) bool {
-	switch card1.Type {
-	case Rock:
-		return card2.Type == Scissors
-	case Paper:
-		return card2.Type == Rock
-	case Scissors:
-		return card2.Type == Paper
+	return g.GetCaptureRules().Captures(card1.Type, card2.Type)
+}
+
+// SetCaptureRules sets the rules deciding which card types capture which for
+// the rest of the game. Nil restores the standard rules.
+func (g *RPSGame) SetCaptureRules(rules *CaptureRules) error {
+	if rules != nil {
+		if err := rules.Validate(); err != nil {
+			return err
+		}
 	}
-	return false
+	g.rules = rules
+	return nil
+}
+
+// GetCaptureRules returns the capture rules in effect
+func (g *RPSGame) GetCaptureRules() *CaptureRules {
+	if g.rules == nil {
+		return standardCaptureRules
+	}
+	return g.rules
 }
 
-// IsGameOver checks if the game is over
+// IsGameOver reports whether the game has ended. It ends as soon as any of
+// these holds:
+//
+//   - both hands are empty;
+//   - Round has passed MaxRounds. A round is one move by each player, and
+//     Round goes up after Player2 moves;
+//   - the player to move cannot move, because the board is full or their hand
+//     is empty. The turn is never passed, so a player who runs out of cards
+//     first ends the game even if the opponent still holds some.
+//
+// With the usual deal of equal hands and more rounds than squares, the game
+// ends when the board fills or both hands run out, whichever comes first.
+// Positions built by hand should keep Round within MaxRounds for the moves
+// they have left; GetValidMoves and MakeMove treat the round limit the same way.
 func (g *RPSGame) IsGameOver() bool {
-	// Game ends when both players are out of cards, maximum rounds reached, or no valid moves for current player
 	if len(g.Player1Hand) == 0 && len(g.Player2Hand) == 0 {
 		return true
 	}
@@ -238,30 +491,33 @@ func (g *RPSGame) IsGameOver() bool {
 	return false
 }
 
-// GetWinner returns the winner of the game
-func (g *RPSGame) GetWinner() RPSPlayer {
-	// Count cards owned by each player
-	player1Count := 0
-	player2Count := 0
-
-	// Count only cards on the board
+// Score returns the number of board squares each player owns. This is the
+// game's score: cards left in hand do not count.
+func (g *RPSGame) Score() (p1, p2 int) {
 	for _, card := range g.Board {
-		if card.Owner == Player1 {
-			player1Count++
-		} else if card.Owner == Player2 {
-			player2Count++
+		switch card.Owner {
+		case Player1:
+			p1++
+		case Player2:
+			p2++
 		}
 	}
+	return p1, p2
+}
 
-	// Hand cards don't count towards victory - only cards on the board matter
-
-	if player1Count > player2Count {
+// GetWinner returns the player with the higher Score, or NoPlayer if the
+// scores are equal. It does not check that the game is over, so on an
+// unfinished game it returns the leader.
+func (g *RPSGame) GetWinner() RPSPlayer {
+	p1, p2 := g.Score()
+	switch {
+	case p1 > p2:
 		return Player1
-	} else if player2Count > player1Count {
+	case p2 > p1:
 		return Player2
+	default:
+		return NoPlayer // Draw
 	}
-
-	return NoPlayer // Draw
 }
 
 // GetRandomMove returns a random valid move
@@ -273,40 +529,71 @@ func (g *RPSGame) GetRandomMove() (RPSMove, error) {
 	return moves[rand.Intn(len(moves))], nil
 }
 
-// Copy creates a deep copy of the game
+// Copy creates a deep copy of the game.
+// The board and both hands share a single allocation, so a copy costs three
+// allocations (the game, the cards and the move history).
 func (g *RPSGame) Copy() *RPSGame {
+	cells := len(g.Board)
+	n1 := len(g.Player1Hand)
+	cards := make([]RPSCard, cells+n1+len(g.Player2Hand))
+	copy(cards, g.Board)
+	copy(cards[cells:], g.Player1Hand)
+	copy(cards[cells+n1:], g.Player2Hand)
+
 	newGame := &RPSGame{
+		// Cap the board and player 1's hand so neither can grow into the next slice
+		Board:         cards[:cells:cells],
+		Player1Hand:   cards[cells : cells+n1 : cells+n1],
+		Player2Hand:   cards[cells+n1:],
 		CurrentPlayer: g.CurrentPlayer,
 		MoveHistory:   make([]RPSMove, len(g.MoveHistory)),
 		Round:         g.Round,
 		MaxRounds:     g.MaxRounds,
-	}
-	copy(newGame.MoveHistory, g.MoveHistory)
-
-	// Copy the board
-	for i := range g.Board {
-		newGame.Board[i] = g.Board[i]
-	}
+		rules:         g.rules,
 
-	// Copy hands
-	newGame.Player1Hand = make([]RPSCard, len(g.Player1Hand))
-	copy(newGame.Player1Hand, g.Player1Hand)
+		// The cached move list is never modified in place, so it can be shared
+		validMoves:    g.validMoves,
+		validMovesKey: g.validMovesKey,
 
-	newGame.Player2Hand = make([]RPSCard, len(g.Player2Hand))
-	copy(newGame.Player2Hand, g.Player2Hand)
+		hash:      g.hash,
+		hashValid: g.hashValid,
+	}
+	copy(newGame.MoveHistory, g.MoveHistory)
 
 	return newGame
 }
 
-// GetBoardAsFeatures returns the board as a flattened feature vector
-// For each position: 3 features for card type (one-hot) * 3 features for ownership (one-hot)
-// So 9 features per position * 9 positions = 81 features
+// CopyInto overwrites dst with a deep copy of the game, reusing dst's board, hand
+// and history buffers, which must not be shared with any other game. Searches
+// that repeatedly copy into the same scratch game allocate nothing once the
+// buffers are large enough.
+func (g *RPSGame) CopyInto(dst *RPSGame) {
+	dst.Board = append(dst.Board[:0], g.Board...)
+	dst.Player1Hand = append(dst.Player1Hand[:0], g.Player1Hand...)
+	dst.Player2Hand = append(dst.Player2Hand[:0], g.Player2Hand...)
+	dst.CurrentPlayer = g.CurrentPlayer
+	dst.MoveHistory = append(dst.MoveHistory[:0], g.MoveHistory...)
+	dst.Round = g.Round
+	dst.MaxRounds = g.MaxRounds
+	dst.rules = g.rules
+	dst.validMoves = g.validMoves
+	dst.validMovesKey = g.validMovesKey
+	dst.hash = g.hash
+	dst.hashValid = g.hashValid
+}
+
+// GetBoardAsFeatures returns the board as a flattened feature vector.
+// Each position uses FeaturesPerPosition features: card type one-hot (0-2, all zero
+// for an empty square), owner one-hot (3-5: none, player 1, player 2), player to
+// move (6-7) and an unused slot (8), for 81 features in total on the standard board.
+//
+// Only public information is encoded. Neither hand appears in the features, so
+// the encoding never reveals the opponent's hidden cards.
 func (g *RPSGame) GetBoardAsFeatures() []float64 {
-	features := make([]float64, 81)
+	features := make([]float64, len(g.Board)*FeaturesPerPosition)
 
-	for pos := 0; pos < 9; pos++ {
-		card := g.Board[pos]
-		baseIdx := pos * 9
+	for pos, card := range g.Board {
+		baseIdx := pos * FeaturesPerPosition
 
 		// Card type
 		if card.Owner != NoPlayer {
@@ -329,16 +616,94 @@ func (g *RPSGame) GetBoardAsFeatures() []float64 {
 	return features
 }
 
+// GetCanonicalFeatures returns the board encoded from the perspective of the player
+// to move: it uses the GetBoardAsFeatures layout, but the side to move always
+// appears as player 1 and the opponent as player 2. A position and its mirror with
+// ownership and turn swapped therefore produce identical features.
+func (g *RPSGame) GetCanonicalFeatures() []float64 {
+	features := make([]float64, len(g.Board)*FeaturesPerPosition)
+
+	for pos, card := range g.Board {
+		baseIdx := pos * FeaturesPerPosition
+
+		// Card type
+		if card.Owner != NoPlayer {
+			features[baseIdx+int(card.Type)] = 1.0
+		}
+
+		// Card ownership relative to the player to move
+		switch card.Owner {
+		case NoPlayer:
+			features[baseIdx+3] = 1.0
+		case g.CurrentPlayer:
+			features[baseIdx+4] = 1.0
+		default:
+			features[baseIdx+5] = 1.0
+		}
+
+		// The player to move is always "player 1"
+		features[baseIdx+6] = 1.0
+	}
+
+	return features
+}
+
+// GetProgressFeatures returns ProgressFeatureCount features describing how far
+// the game has progressed, each between 0 and 1: the round as a share of
+// MaxRounds, the cards left in player 1's and player 2's hands and the empty
+// squares, each as a share of the board's squares. Hand sizes are public, so
+// like the board features they reveal nothing hidden.
+func (g *RPSGame) GetProgressFeatures() []float64 {
+	return g.progressFeatures(len(g.Player1Hand), len(g.Player2Hand))
+}
+
+// GetCanonicalProgressFeatures returns the GetProgressFeatures layout from the
+// perspective of the player to move, whose hand always comes first
+func (g *RPSGame) GetCanonicalProgressFeatures() []float64 {
+	if g.CurrentPlayer == Player2 {
+		return g.progressFeatures(len(g.Player2Hand), len(g.Player1Hand))
+	}
+	return g.progressFeatures(len(g.Player1Hand), len(g.Player2Hand))
+}
+
+// progressFeatures encodes the round, the two hand sizes in the order given
+// and the empty squares
+func (g *RPSGame) progressFeatures(firstHand, secondHand int) []float64 {
+	features := make([]float64, ProgressFeatureCount)
+	if g.MaxRounds > 0 {
+		features[0] = math.Min(float64(g.Round)/float64(g.MaxRounds), 1)
+	}
+
+	squares := float64(len(g.Board))
+	if squares == 0 {
+		return features
+	}
+	features[1] = math.Min(float64(firstHand)/squares, 1)
+	features[2] = math.Min(float64(secondHand)/squares, 1)
+	for _, card := range g.Board {
+		if card.Owner == NoPlayer {
+			features[3]++
+		}
+	}
+	features[3] /= squares
+	return features
+}
+
 // String returns a string representation of the game
 func (g *RPSGame) String() string {
 	var sb strings.Builder
 
 	// Display board
-	sb.WriteString("  0 1 2\n")
-	for row := 0; row < 3; row++ {
+	dim := g.BoardDim()
+	sb.WriteString(" ")
+	for col := 0; col < dim; col++ {
+		sb.WriteString(fmt.Sprintf(" %d", col))
+	}
+	sb.WriteString("\n")
+	for row := 0; row < dim; row++ {
 		sb.WriteString(fmt.Sprintf("%d ", row))
-		for col := 0; col < 3; col++ {
-			pos := row*3 + col
+		for col := 0; col < dim; col++ {
+			pos := row*dim + col
 			card := g.Board[pos]
 
 			if card.Owner == NoPlayer {
@@ -363,7 +728,7 @@ func (g *RPSGame) String() string {
 				sb.WriteString(symbol)
 			}
 
-			if col < 2 {
+			if col < dim-1 {
 				sb.WriteString(" ")
 			}
 		}
@@ -429,11 +794,138 @@ func (g *RPSGame) String() string {
 	return sb.String()
 }
 
+// Notation returns a compact single-line description of the position:
+// the board rows separated by '/', both hands, the player to move and the round.
+// Player 1's cards are uppercase and player 2's lowercase, e.g. "R.s/.P./... RPS rps 1 3".
+func (g *RPSGame) Notation() string {
+	var sb strings.Builder
+
+	dim := g.BoardDim()
+	for pos, card := range g.Board {
+		if pos > 0 && pos%dim == 0 {
+			sb.WriteByte('/')
+		}
+		if card.Owner == NoPlayer {
+			sb.WriteByte('.')
+		} else {
+			sb.WriteByte(cardLetter(card.Type, card.Owner))
+		}
+	}
+
+	sb.WriteString(" " + handNotation(g.Player1Hand, Player1))
+	sb.WriteString(" " + handNotation(g.Player2Hand, Player2))
+	sb.WriteString(fmt.Sprintf(" %d %d", g.CurrentPlayer, g.Round))
+
+	return sb.String()
+}
+
+// ParseNotation builds the position described by a Notation string. The
+// notation does not record the round limit, so the game gets maxRounds; the
+// undealt deck and move history are empty.
+func ParseNotation(notation string, maxRounds int) (*RPSGame, error) {
+	fields := strings.Fields(notation)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("expected 5 fields (board, two hands, player, round), got %d", len(fields))
+	}
+
+	rows := strings.Split(fields[0], "/")
+	dim := len(rows)
+	if dim < MinBoardDim || dim > MaxBoardDim {
+		return nil, fmt.Errorf("board has %d rows; it must have between %d and %d", dim, MinBoardDim, MaxBoardDim)
+	}
+	g := NewRPSGameSized(dim, 0, 0, maxRounds, nil)
+	for row, squares := range rows {
+		if len(squares) != dim {
+			return nil, fmt.Errorf("board row %d has %d squares, want %d", row+1, len(squares), dim)
+		}
+		for col := 0; col < dim; col++ {
+			if squares[col] == '.' {
+				continue
+			}
+			card, err := parseCardLetter(squares[col])
+			if err != nil {
+				return nil, fmt.Errorf("board row %d: %v", row+1, err)
+			}
+			g.Board[row*dim+col] = card
+		}
+	}
+
+	for i, hand := range []*[]RPSCard{&g.Player1Hand, &g.Player2Hand} {
+		types, err := parseHandTypes(fields[1+i])
+		if err != nil {
+			return nil, fmt.Errorf("player %d hand: %v", i+1, err)
+		}
+		*hand = make([]RPSCard, len(types))
+		for j, cardType := range types {
+			(*hand)[j] = RPSCard{Type: cardType}
+		}
+	}
+
+	switch fields[3] {
+	case "1":
+		g.CurrentPlayer = Player1
+	case "2":
+		g.CurrentPlayer = Player2
+	default:
+		return nil, fmt.Errorf("player to move must be 1 or 2, got %q", fields[3])
+	}
+
+	round, err := strconv.Atoi(fields[4])
+	if err != nil || round < 1 {
+		return nil, fmt.Errorf("invalid round %q", fields[4])
+	}
+	g.Round = round
+
+	g.RecomputeHash()
+	return g, nil
+}
+
+// parseCardLetter parses a board letter: uppercase for player 1's cards and
+// lowercase for player 2's
+func parseCardLetter(letter byte) (RPSCard, error) {
+	owner := Player1
+	if letter >= 'a' && letter <= 'z' {
+		owner = Player2
+		letter -= 'a' - 'A'
+	}
+	switch letter {
+	case 'R':
+		return RPSCard{Type: Rock, Owner: owner}, nil
+	case 'P':
+		return RPSCard{Type: Paper, Owner: owner}, nil
+	case 'S':
+		return RPSCard{Type: Scissors, Owner: owner}, nil
+	}
+	return RPSCard{}, fmt.Errorf("unknown card %q", letter)
+}
+
+// cardLetter returns the notation letter for a card owned by player
+func cardLetter(cardType RPSCardType, player RPSPlayer) byte {
+	letter := byte("RPS"[cardType])
+	if player == Player2 {
+		letter += 'a' - 'A'
+	}
+	return letter
+}
+
+// handNotation returns the letters of a hand, or "-" when it is empty
+func handNotation(hand []RPSCard, player RPSPlayer) string {
+	if len(hand) == 0 {
+		return "-"
+	}
+	letters := make([]byte, len(hand))
+	for i, card := range hand {
+		letters[i] = cardLetter(card.Type, player)
+	}
+	return string(letters)
+}
+
 // SetBoardOwner sets the owner of a card at the specified position
 func (g *RPSGame) SetBoardOwner(position int, playerVal int) {
 	if position < 0 || position >= len(g.Board) {
 		return
 	}
+	g.hashValid = false
 
 	if playerVal == 0 {
 		g.Board[position].Owner = Player1
@@ -446,6 +938,7 @@ func (g *RPSGame) SetBoardOwner(position int, playerVal int) {
 
 // SetPlayer1Hand sets the cards in player 1's hand
 func (g *RPSGame) SetPlayer1Hand(cardTypes []int) {
+	g.hashValid = false
 	g.Player1Hand = make([]RPSCard, len(cardTypes))
 	for i, cardType := range cardTypes {
 		g.Player1Hand[i] = RPSCard{
@@ -457,6 +950,7 @@ func (g *RPSGame) SetPlayer1Hand(cardTypes []int) {
 
 // SetPlayer2Hand sets the cards in player 2's hand
 func (g *RPSGame) SetPlayer2Hand(cardTypes []int) {
+	g.hashValid = false
 	g.Player2Hand = make([]RPSCard, len(cardTypes))
 	for i, cardType := range cardTypes {
 		g.Player2Hand[i] = RPSCard{
@@ -468,6 +962,7 @@ func (g *RPSGame) SetPlayer2Hand(cardTypes []int) {
 
 // SetCurrentPlayer sets the current player
 func (g *RPSGame) SetCurrentPlayer(playerVal int) {
+	g.hashValid = false
 	if playerVal == 0 {
 		g.CurrentPlayer = Player1
 	} else {
@@ -477,6 +972,7 @@ func (g *RPSGame) SetCurrentPlayer(playerVal int) {
 
 // SetRound sets the current round number
 func (g *RPSGame) SetRound(round int) {
+	g.hashValid = false
 	g.Round = round
 }
 
@@ -491,7 +987,9 @@ func (g *RPSGame) CountPlayerCards(player RPSPlayer) int {
 	return count
 }
 
-// GetBoard returns the game board
-func (g *RPSGame) GetBoard() [9]RPSCard {
-	return g.Board
+// GetBoard returns a copy of the game board
+func (g *RPSGame) GetBoard() []RPSCard {
+	board := make([]RPSCard, len(g.Board))
+	copy(board, g.Board)
+	return board
 }
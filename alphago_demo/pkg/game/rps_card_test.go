@@ -1,6 +1,9 @@
 package game
 
 import (
+	"math"
+	"math/rand"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -60,6 +63,109 @@ func TestGetValidMoves(t *testing.T) {
 	}
 }
 
+func TestGetValidMovesCacheMatchesRecompute(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		game := NewRPSGame(21, 5, 10)
+		for !game.IsGameOver() {
+			cached := game.GetValidMoves()
+			fresh := game.computeValidMoves()
+			if !reflect.DeepEqual(cached, fresh) {
+				t.Fatalf("Cached moves differ from recomputation at %s", game.Notation())
+			}
+
+			// Copies must agree with the original and not be affected by its future moves
+			copied := game.Copy()
+			move := cached[rand.Intn(len(cached))]
+			if err := game.MakeMove(move); err != nil {
+				t.Fatalf("MakeMove failed: %v", err)
+			}
+			if !reflect.DeepEqual(copied.GetValidMoves(), fresh) {
+				t.Fatalf("Copy returned stale moves after the original changed")
+			}
+		}
+	}
+}
+
+func TestGetValidMovesAfterDirectEdit(t *testing.T) {
+	game := NewRPSGame(15, 3, 10)
+	if len(game.GetValidMoves()) != 27 {
+		t.Fatalf("Expected 27 valid moves")
+	}
+
+	// Editing the board or hands directly must not return the cached list
+	game.Board[4] = RPSCard{Type: Rock, Owner: Player2}
+	if got := len(game.GetValidMoves()); got != 24 {
+		t.Errorf("Expected 24 valid moves after occupying a square, got %d", got)
+	}
+	game.Player1Hand = game.Player1Hand[:1]
+	if got := len(game.GetValidMoves()); got != 8 {
+		t.Errorf("Expected 8 valid moves after shrinking the hand, got %d", got)
+	}
+}
+
+func TestGetValidMovesOrderIsStable(t *testing.T) {
+	rng := rand.New(rand.NewSource(11))
+	game := NewRPSGameSeeded(21, 5, 10, rng)
+	for !game.IsGameOver() {
+		moves := append([]RPSMove(nil), game.GetValidMoves()...)
+
+		// Ordered by position, then by card index
+		for i := 1; i < len(moves); i++ {
+			prev, cur := moves[i-1], moves[i]
+			if cur.Position < prev.Position || (cur.Position == prev.Position && cur.CardIndex <= prev.CardIndex) {
+				t.Fatalf("Moves %d and %d are out of order at %s: %+v then %+v",
+					i-1, i, game.Notation(), prev, cur)
+			}
+		}
+
+		if again := game.GetValidMoves(); !reflect.DeepEqual(again, moves) {
+			t.Fatalf("Repeated call changed the order at %s", game.Notation())
+		}
+		copied := game.Copy()
+		if got := copied.GetValidMoves(); !reflect.DeepEqual(got, moves) {
+			t.Fatalf("Copy returned a different order at %s", game.Notation())
+		}
+		copied.validMoves = nil // Recompute rather than share the cached list
+		if got := copied.GetValidMoves(); !reflect.DeepEqual(got, moves) {
+			t.Fatalf("Recomputing on a copy gave a different order at %s", game.Notation())
+		}
+
+		if err := game.MakeMove(moves[rng.Intn(len(moves))]); err != nil {
+			t.Fatalf("MakeMove failed: %v", err)
+		}
+	}
+}
+
+// midgamePosition returns a game with four moves played
+func midgamePosition() *RPSGame {
+	rand.Seed(1)
+	game := NewRPSGame(21, 5, 10)
+	for i := 0; i < 4; i++ {
+		move, _ := game.GetRandomMove()
+		game.MakeMove(move)
+	}
+	return game
+}
+
+func BenchmarkGetValidMoves(b *testing.B) {
+	game := midgamePosition()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		game.GetValidMoves()
+	}
+}
+
+// BenchmarkComputeValidMoves measures the uncached cost for comparison
+func BenchmarkComputeValidMoves(b *testing.B) {
+	game := midgamePosition()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		game.computeValidMoves()
+	}
+}
+
 func TestRPSMakeMove(t *testing.T) {
 	game := NewRPSGame(15, 3, 10)
 	initialHand1Size := len(game.Player1Hand)
@@ -166,6 +272,178 @@ func TestGameEnd(t *testing.T) {
 	}
 }
 
+// handOf returns a hand holding one card of each given type
+func handOf(cardTypes ...RPSCardType) []RPSCard {
+	hand := make([]RPSCard, len(cardTypes))
+	for i, cardType := range cardTypes {
+		hand[i] = RPSCard{Type: cardType}
+	}
+	return hand
+}
+
+func TestGameOverConditions(t *testing.T) {
+	fullBoard := func(game *RPSGame) {
+		for i := range game.Board {
+			game.Board[i] = RPSCard{Type: Rock, Owner: Player1}
+		}
+	}
+
+	tests := []struct {
+		name  string
+		setup func(game *RPSGame)
+		over  bool
+	}{
+		{"new game", func(game *RPSGame) {}, false},
+		{"both hands empty", func(game *RPSGame) {
+			game.Player1Hand = nil
+			game.Player2Hand = nil
+		}, true},
+		{"board full with cards in hand", fullBoard, true},
+		{"player to move has no cards", func(game *RPSGame) {
+			game.Player1Hand = nil
+		}, true},
+		{"opponent has no cards", func(game *RPSGame) {
+			game.Player2Hand = nil
+		}, false},
+		{"last round", func(game *RPSGame) {
+			game.Round = game.MaxRounds
+		}, false},
+		{"round limit passed", func(game *RPSGame) {
+			game.Round = game.MaxRounds + 1
+		}, true},
+		{"hand-built endgame", func(game *RPSGame) {
+			fullBoard(game)
+			game.Board[8] = RPSCard{}
+			game.Player1Hand = handOf(Scissors)
+			game.Player2Hand = handOf(Paper)
+			game.Round = 5
+		}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := NewRPSGame(21, 5, 10)
+			game.GetValidMoves() // Fill the cache before editing the position
+			tt.setup(game)
+
+			if got := game.IsGameOver(); got != tt.over {
+				t.Fatalf("IsGameOver() = %v, want %v", got, tt.over)
+			}
+
+			// Valid moves and MakeMove must agree with IsGameOver
+			moves := game.GetValidMoves()
+			if tt.over && len(moves) != 0 {
+				t.Errorf("Expected no valid moves once the game is over, got %d", len(moves))
+			}
+			if !tt.over {
+				if len(moves) == 0 {
+					t.Fatal("Expected valid moves while the game is not over")
+				}
+				if err := game.MakeMove(moves[0]); err != nil {
+					t.Errorf("MakeMove(%+v) failed: %v", moves[0], err)
+				}
+			}
+		})
+	}
+}
+
+func TestMakeMoveRejectsMovesAfterRoundLimit(t *testing.T) {
+	game := NewRPSGame(21, 5, 3)
+	for round := 1; round <= 3; round++ {
+		for _, player := range []RPSPlayer{Player1, Player2} {
+			if game.IsGameOver() {
+				t.Fatalf("Game ended early in round %d", round)
+			}
+			if err := game.MakeMove(game.GetValidMoves()[0]); err != nil {
+				t.Fatalf("Round %d, %v: MakeMove failed: %v", round, player, err)
+			}
+		}
+	}
+
+	// Three full rounds fill six squares and leave both players with cards
+	if !game.IsGameOver() {
+		t.Fatal("Expected the game to be over after the last round")
+	}
+	move := RPSMove{CardIndex: 0, Position: 8, Player: Player1}
+	if err := game.MakeMove(move); err == nil {
+		t.Error("Expected MakeMove to reject a move after the round limit")
+	}
+}
+
+func TestScoreAndWinner(t *testing.T) {
+	tests := []struct {
+		name   string
+		board  string // Owner of each square: 1, 2 or . for empty
+		p1, p2 int
+		want   RPSPlayer
+	}{
+		{"empty board", ".........", 0, 0, NoPlayer},
+		{"single card", "....1....", 1, 0, Player1},
+		{"Player1 by one", "112......", 2, 1, Player1},
+		{"Player2 by one", "1...2...2", 1, 2, Player2},
+		{"tie", "1.......2", 1, 1, NoPlayer},
+		{"tie with one square left", "11112222.", 4, 4, NoPlayer},
+		{"full board by one", "111112222", 5, 4, Player1},
+		{"full board, Player2 by one", "111122222", 4, 5, Player2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := NewRPSGame(21, 5, 10)
+			for pos, owner := range tt.board {
+				switch owner {
+				case '1':
+					game.Board[pos] = RPSCard{Type: Rock, Owner: Player1}
+				case '2':
+					game.Board[pos] = RPSCard{Type: Paper, Owner: Player2}
+				}
+			}
+
+			// Cards in hand do not count
+			game.Player1Hand = nil
+
+			if p1, p2 := game.Score(); p1 != tt.p1 || p2 != tt.p2 {
+				t.Errorf("Score() = %d, %d, want %d, %d", p1, p2, tt.p1, tt.p2)
+			}
+			if got := game.GetWinner(); got != tt.want {
+				t.Errorf("GetWinner() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestGetWinnerAfterPlay checks GetWinner against Score at the end of random
+// games, the check debug_ai_game used to make by hand
+func TestGetWinnerAfterPlay(t *testing.T) {
+	rng := rand.New(rand.NewSource(11))
+	for i := 0; i < 200; i++ {
+		game := NewRPSGameSeeded(21, 5, 10, rng)
+		for !game.IsGameOver() {
+			moves := game.GetValidMoves()
+			if err := game.MakeMove(moves[rng.Intn(len(moves))]); err != nil {
+				t.Fatalf("Game %d: MakeMove failed: %v", i, err)
+			}
+		}
+
+		p1, p2 := game.Score()
+		if p1+p2 != len(game.Board) {
+			t.Errorf("Game %d: scores %d and %d do not cover the full board", i, p1, p2)
+		}
+		if p1 != game.CountPlayerCards(Player1) || p2 != game.CountPlayerCards(Player2) {
+			t.Errorf("Game %d: Score() = %d, %d disagrees with CountPlayerCards", i, p1, p2)
+		}
+		want := NoPlayer
+		if p1 > p2 {
+			want = Player1
+		} else if p2 > p1 {
+			want = Player2
+		}
+		if got := game.GetWinner(); got != want {
+			t.Errorf("Game %d: GetWinner() = %v with a score of %d-%d", i, got, p1, p2)
+		}
+	}
+}
+
 func TestCopy(t *testing.T) {
 	original := NewRPSGame(15, 4, 10)
 
@@ -207,6 +485,103 @@ func TestCopy(t *testing.T) {
 	original.Board = originalBoard
 }
 
+func TestCopyIndependence(t *testing.T) {
+	original := midgamePosition()
+	before := original.Notation()
+
+	copied := original.Copy()
+	scratch := NewRPSGame(21, 5, 10)
+	original.CopyInto(scratch)
+
+	for _, g := range []*RPSGame{copied, scratch} {
+		if g.Notation() != before {
+			t.Fatalf("Expected copy %s, got %s", before, g.Notation())
+		}
+		if len(g.MoveHistory) != len(original.MoveHistory) {
+			t.Fatalf("Expected %d history entries, got %d", len(original.MoveHistory), len(g.MoveHistory))
+		}
+
+		// Play the copy out; the original must not change
+		for !g.IsGameOver() {
+			move, _ := g.GetRandomMove()
+			if err := g.MakeMove(move); err != nil {
+				t.Fatalf("MakeMove failed: %v", err)
+			}
+		}
+		g.Player2Hand = append(g.Player2Hand, RPSCard{Type: Rock})
+
+		if original.Notation() != before {
+			t.Fatalf("Playing a copy changed the original: %s became %s", before, original.Notation())
+		}
+	}
+}
+
+func BenchmarkCopy(b *testing.B) {
+	game := midgamePosition()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		game.Copy()
+	}
+}
+
+func BenchmarkCopyInto(b *testing.B) {
+	game := midgamePosition()
+	scratch := &RPSGame{}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		game.CopyInto(scratch)
+	}
+}
+
+func TestUndoMoveRestoresPosition(t *testing.T) {
+	captures := 0
+	for i := 0; i < 20; i++ {
+		game := NewRPSGame(21, 5, 10)
+		for !game.IsGameOver() {
+			moves := game.GetValidMoves()
+			before := game.Copy()
+
+			// Every legal move must be reversible, including ones that capture
+			for _, move := range moves {
+				undo, err := game.MakeMoveReversible(move)
+				if err != nil {
+					t.Fatalf("MakeMoveReversible failed: %v", err)
+				}
+				for pos := range game.Board {
+					if before.Board[pos].Owner != NoPlayer && game.Board[pos].Owner != before.Board[pos].Owner {
+						captures++
+					}
+				}
+				game.UndoMove(undo)
+
+				if !reflect.DeepEqual(game, before) {
+					t.Fatalf("Undoing %+v did not restore %s, got %s", move, before.Notation(), game.Notation())
+				}
+			}
+
+			game.MakeMove(moves[rand.Intn(len(moves))])
+		}
+	}
+
+	if captures == 0 {
+		t.Error("Expected some of the undone moves to capture cards")
+	}
+}
+
+func TestMakeMoveReversibleRejectsInvalidMove(t *testing.T) {
+	game := NewRPSGame(15, 3, 10)
+	before := game.Copy()
+
+	if _, err := game.MakeMoveReversible(RPSMove{CardIndex: 5, Position: 0, Player: Player1}); err == nil {
+		t.Error("Expected an error for an invalid card index")
+	}
+	if !reflect.DeepEqual(game, before) {
+		t.Error("A rejected move changed the game")
+	}
+}
+
 func TestRPSGetBoardAsFeatures(t *testing.T) {
 	game := NewRPSGame(15, 5, 10)
 
@@ -240,6 +615,138 @@ func TestRPSGetBoardAsFeatures(t *testing.T) {
 	}
 }
 
+func TestFeaturesHideOpponentHand(t *testing.T) {
+	for seed := int64(0); seed < 10; seed++ {
+		rng := rand.New(rand.NewSource(seed))
+		game := NewRPSGameSeeded(21, 5, 10, rng)
+		for i := 0; i < 3; i++ {
+			move, _ := game.GetRandomMove()
+			game.MakeMove(move)
+		}
+		expected := game.GetBoardAsFeatures()
+
+		// Shuffling the opponent's hidden hand must not change the features
+		shuffled := game.Copy()
+		hand := &shuffled.Player2Hand
+		if shuffled.CurrentPlayer == Player2 {
+			hand = &shuffled.Player1Hand
+		}
+		rng.Shuffle(len(*hand), func(i, j int) { (*hand)[i], (*hand)[j] = (*hand)[j], (*hand)[i] })
+		if !reflect.DeepEqual(shuffled.GetBoardAsFeatures(), expected) {
+			t.Errorf("Seed %d: features changed when the opponent's hand was permuted", seed)
+		}
+
+		// Nor may replacing the opponent's cards with different ones
+		for i := range *hand {
+			(*hand)[i].Type = ((*hand)[i].Type + 1) % 3
+		}
+		if !reflect.DeepEqual(shuffled.GetBoardAsFeatures(), expected) {
+			t.Errorf("Seed %d: features revealed the opponent's hand contents", seed)
+		}
+	}
+}
+
+func TestCanonicalFeaturesMirrorInvariant(t *testing.T) {
+	for seed := int64(0); seed < 10; seed++ {
+		game := NewRPSGameSeeded(21, 5, 10, rand.New(rand.NewSource(seed)))
+		for i := 0; i < 4; i++ {
+			move, _ := game.GetRandomMove()
+			game.MakeMove(move)
+		}
+
+		// Swap ownership of every card and the player to move
+		mirror := game.Copy()
+		for pos := range mirror.Board {
+			switch mirror.Board[pos].Owner {
+			case Player1:
+				mirror.Board[pos].Owner = Player2
+			case Player2:
+				mirror.Board[pos].Owner = Player1
+			}
+		}
+		mirror.Player1Hand, mirror.Player2Hand = mirror.Player2Hand, mirror.Player1Hand
+		if mirror.CurrentPlayer == Player1 {
+			mirror.CurrentPlayer = Player2
+		} else {
+			mirror.CurrentPlayer = Player1
+		}
+
+		if !reflect.DeepEqual(game.GetCanonicalFeatures(), mirror.GetCanonicalFeatures()) {
+			t.Errorf("Seed %d: canonical features differ for %s and its mirror %s",
+				seed, game.Notation(), mirror.Notation())
+		}
+		if reflect.DeepEqual(game.GetBoardAsFeatures(), mirror.GetBoardAsFeatures()) {
+			t.Errorf("Seed %d: absolute features should distinguish a position from its mirror", seed)
+		}
+	}
+}
+
+func TestCanonicalFeaturesMatchAbsoluteForPlayer1(t *testing.T) {
+	game := NewRPSGame(21, 5, 10)
+	game.Board[0] = RPSCard{Type: Rock, Owner: Player1}
+	game.Board[4] = RPSCard{Type: Scissors, Owner: Player2}
+
+	if !reflect.DeepEqual(game.GetCanonicalFeatures(), game.GetBoardAsFeatures()) {
+		t.Error("With player 1 to move, canonical features should equal the absolute features")
+	}
+}
+
+func TestProgressFeaturesTrackGame(t *testing.T) {
+	game := NewRPSGameSeeded(21, 5, 10, rand.New(rand.NewSource(3)))
+	previous := game.GetProgressFeatures()
+	if len(previous) != ProgressFeatureCount {
+		t.Fatalf("Expected %d progress features, got %d", ProgressFeatureCount, len(previous))
+	}
+	if previous[0] != 1.0/10 || previous[3] != 1 {
+		t.Errorf("Opening features = %v, want the first of 10 rounds and every square empty", previous)
+	}
+
+	for !game.IsGameOver() {
+		move, err := game.GetRandomMove()
+		if err != nil {
+			t.Fatalf("GetRandomMove failed: %v", err)
+		}
+		game.MakeMove(move)
+
+		features := game.GetProgressFeatures()
+		if features[0] < previous[0] {
+			t.Errorf("Round feature fell from %v to %v", previous[0], features[0])
+		}
+		if features[1]+features[2] >= previous[1]+previous[2] {
+			t.Errorf("Hand features %v did not fall after a move from %v", features[1:3], previous[1:3])
+		}
+		if want := previous[3] - 1.0/9; math.Abs(features[3]-want) > 1e-12 {
+			t.Errorf("Empty-square feature = %v after a move, want %v", features[3], want)
+		}
+		for i, f := range features {
+			if f < 0 || f > 1 {
+				t.Errorf("Progress feature %d = %v, want a value in [0, 1]", i, f)
+			}
+		}
+		previous = features
+	}
+	if previous[0] <= 1.0/10 {
+		t.Error("Round feature never advanced during the game")
+	}
+}
+
+func TestCanonicalProgressFeaturesPutMoverFirst(t *testing.T) {
+	game := NewRPSGame(21, 5, 10)
+	game.Player1Hand = handOf(Rock, Paper, Scissors)
+	game.Player2Hand = handOf(Rock)
+
+	absolute := game.GetProgressFeatures()
+	if !reflect.DeepEqual(game.GetCanonicalProgressFeatures(), absolute) {
+		t.Error("With player 1 to move, canonical progress features should equal the absolute ones")
+	}
+
+	game.CurrentPlayer = Player2
+	canonical := game.GetCanonicalProgressFeatures()
+	if canonical[1] != absolute[2] || canonical[2] != absolute[1] {
+		t.Errorf("Canonical hand features = %v, want player 2's hand first %v", canonical[1:3], []float64{absolute[2], absolute[1]})
+	}
+}
+
 func TestWinnerDeterminationWithMoreCards(t *testing.T) {
 	// Create a new game
 	game := NewRPSGame(15, 5, 10)
@@ -463,3 +970,138 @@ func TestRPSGameSetters(t *testing.T) {
 		t.Errorf("SetRound failed: expected round 5, got %d", game.Round)
 	}
 }
+
+func TestRPSNotation(t *testing.T) {
+	game := NewRPSGame(15, 3, 10)
+	game.SetPlayer1Hand([]int{0, 1, 2})
+	game.SetPlayer2Hand([]int{2, 2})
+	game.Board[0] = RPSCard{Type: Rock, Owner: Player1}
+	game.Board[2] = RPSCard{Type: Scissors, Owner: Player2}
+	game.Board[4] = RPSCard{Type: Paper, Owner: Player1}
+	game.CurrentPlayer = Player2
+	game.Round = 3
+
+	expected := "R.s/.P./... RPS ss 2 3"
+	if got := game.Notation(); got != expected {
+		t.Errorf("Expected notation %q, got %q", expected, got)
+	}
+
+	game.Player2Hand = nil
+	expected = "R.s/.P./... RPS - 2 3"
+	if got := game.Notation(); got != expected {
+		t.Errorf("Expected notation %q, got %q", expected, got)
+	}
+}
+
+func TestParseNotation(t *testing.T) {
+	rng := rand.New(rand.NewSource(8))
+	for i := 0; i < 20; i++ {
+		game := NewRPSGameSeeded(21, 5, 10, rng)
+		for plies := rng.Intn(9); plies > 0 && !game.IsGameOver(); plies-- {
+			moves := game.GetValidMoves()
+			game.MakeMove(moves[rng.Intn(len(moves))])
+		}
+
+		parsed, err := ParseNotation(game.Notation(), game.MaxRounds)
+		if err != nil {
+			t.Fatalf("ParseNotation(%q) failed: %v", game.Notation(), err)
+		}
+		if parsed.Notation() != game.Notation() {
+			t.Errorf("Round trip changed %q to %q", game.Notation(), parsed.Notation())
+		}
+		if parsed.Hash() != game.Hash() {
+			t.Errorf("Parsed position %q has a different hash", game.Notation())
+		}
+		if len(parsed.GetValidMoves()) != len(game.GetValidMoves()) {
+			t.Errorf("Parsed position %q has different valid moves", game.Notation())
+		}
+	}
+
+	for _, notation := range []string{
+		"",
+		"R.s/.P./... RPS ss 2",
+		"R.s/.P/... RPS ss 2 3",
+		"R.x/.P./... RPS ss 2 3",
+		"R.s/.P./... RPX ss 2 3",
+		"R.s/.P./... RPS ss 3 3",
+		"R.s/.P./... RPS ss 2 0",
+	} {
+		if _, err := ParseNotation(notation, 10); err == nil {
+			t.Errorf("Expected ParseNotation(%q) to fail", notation)
+		}
+	}
+}
+
+func TestNewRPSGameSeededIsReproducible(t *testing.T) {
+	first := NewRPSGameSeeded(21, 5, 10, rand.New(rand.NewSource(42)))
+	second := NewRPSGameSeeded(21, 5, 10, rand.New(rand.NewSource(42)))
+	if first.Notation() != second.Notation() {
+		t.Errorf("Same seed dealt different hands: %s vs %s", first.Notation(), second.Notation())
+	}
+
+	// Different seeds should eventually produce a different deal
+	for seed := int64(1); seed < 20; seed++ {
+		other := NewRPSGameSeeded(21, 5, 10, rand.New(rand.NewSource(seed)))
+		if other.Notation() != first.Notation() {
+			return
+		}
+	}
+	t.Error("Every seed produced the same deal")
+}
+
+func TestLargerBoard(t *testing.T) {
+	game := NewRPSGameSized(4, 40, 8, 8, rand.New(rand.NewSource(7)))
+
+	if game.BoardDim() != 4 || len(game.Board) != 16 {
+		t.Fatalf("Expected a 4x4 board, got dimension %d with %d squares", game.BoardDim(), len(game.Board))
+	}
+	if got := len(game.GetValidMoves()); got != 16*8 {
+		t.Errorf("Expected %d valid moves, got %d", 16*8, got)
+	}
+	if got := len(game.GetBoardAsFeatures()); got != 16*FeaturesPerPosition {
+		t.Errorf("Expected %d features, got %d", 16*FeaturesPerPosition, got)
+	}
+	if rows := strings.Count(strings.Fields(game.Notation())[0], "/") + 1; rows != 4 {
+		t.Errorf("Expected 4 rows in the notation, got %d", rows)
+	}
+
+	// Position 7 ends the second row, so it borders 3, 6 and 11 but not 8
+	game.Board[6] = RPSCard{Type: Paper, Owner: Player2}
+	game.Board[8] = RPSCard{Type: Paper, Owner: Player2}
+	game.Player1Hand[0] = RPSCard{Type: Scissors}
+	if err := game.MakeMove(RPSMove{CardIndex: 0, Position: 7, Player: Player1}); err != nil {
+		t.Fatalf("MakeMove failed: %v", err)
+	}
+	if game.Board[6].Owner != Player1 {
+		t.Error("Expected the adjacent paper at position 6 to be captured")
+	}
+	if game.Board[8].Owner != Player2 {
+		t.Error("Position 8 starts the next row and must not be captured")
+	}
+
+	// Play out the game, checking every move can be undone
+	for !game.IsGameOver() {
+		moves := game.GetValidMoves()
+		before := game.Copy()
+		for _, move := range moves {
+			undo, err := game.MakeMoveReversible(move)
+			if err != nil {
+				t.Fatalf("MakeMoveReversible failed: %v", err)
+			}
+			game.UndoMove(undo)
+			if !reflect.DeepEqual(game, before) {
+				t.Fatalf("Undoing %+v did not restore %s, got %s", move, before.Notation(), game.Notation())
+			}
+		}
+		game.MakeMove(moves[rand.Intn(len(moves))])
+	}
+}
+
+func TestNewRPSGameSizedRejectsInvalidDimension(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a panic for a board larger than MaxBoardDim")
+		}
+	}()
+	NewRPSGameSized(MaxBoardDim+1, 21, 5, 10, nil)
+}
@@ -0,0 +1,307 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Transcript records a complete game so it can be saved, shared and replayed.
+//
+// Transcripts are stored in a small SGF-like text format: a list of KEY[value]
+// properties describing the game, followed by one ;P[card:position] node per move,
+// where P is the player (1 or 2) and card is the index into that player's hand:
+//
+//	(;GM[RPSCard]DS[21]HS[5]MR[10]P1[Minimax-3]P2[NEAT-1]RE[Minimax-3]
+//	H1[RPSSR]H2[rpsrr]
+//	;1[0:4];2[3:0]
+//	)
+//
+// Games on a board other than the standard 3x3 also carry a BS[dim] property.
+type Transcript struct {
+	DeckSize  int
+	HandSize  int
+	MaxRounds int
+	BoardDim  int // Side length of the board; 0 means StandardBoardDim
+
+	Player1 string // Name of the agent playing as player 1
+	Player2 string // Name of the agent playing as player 2
+	Result  string // Free-form result, e.g. the winner's name or "draw"
+
+	// Hands dealt at the start of the game
+	Player1Hand []RPSCardType
+	Player2Hand []RPSCardType
+
+	Moves []RPSMove
+}
+
+// transcriptGameID identifies RPS card game transcripts
+const transcriptGameID = "RPSCard"
+
+// NewTranscript starts a transcript for a game that has not had any moves played yet
+func NewTranscript(initial *RPSGame, deckSize, handSize int) *Transcript {
+	t := &Transcript{
+		DeckSize:    deckSize,
+		HandSize:    handSize,
+		MaxRounds:   initial.MaxRounds,
+		BoardDim:    initial.BoardDim(),
+		Player1Hand: make([]RPSCardType, len(initial.Player1Hand)),
+		Player2Hand: make([]RPSCardType, len(initial.Player2Hand)),
+		Moves:       make([]RPSMove, 0),
+	}
+	for i, card := range initial.Player1Hand {
+		t.Player1Hand[i] = card.Type
+	}
+	for i, card := range initial.Player2Hand {
+		t.Player2Hand[i] = card.Type
+	}
+	return t
+}
+
+// Record appends a move to the transcript
+func (t *Transcript) Record(move RPSMove) {
+	t.Moves = append(t.Moves, move)
+}
+
+// InitialState returns the position before the first move
+func (t *Transcript) InitialState() *RPSGame {
+	dim := t.BoardDim
+	if dim == 0 {
+		dim = StandardBoardDim
+	}
+
+	g := &RPSGame{
+		Board:         make([]RPSCard, dim*dim),
+		Player1Hand:   make([]RPSCard, len(t.Player1Hand)),
+		Player2Hand:   make([]RPSCard, len(t.Player2Hand)),
+		CurrentPlayer: Player1,
+		MoveHistory:   []RPSMove{},
+		Round:         1,
+		MaxRounds:     t.MaxRounds,
+	}
+	for i, cardType := range t.Player1Hand {
+		g.Player1Hand[i] = RPSCard{Type: cardType, Owner: NoPlayer}
+	}
+	for i, cardType := range t.Player2Hand {
+		g.Player2Hand[i] = RPSCard{Type: cardType, Owner: NoPlayer}
+	}
+	return g
+}
+
+// Replay reconstructs every position of the game. The first state is the initial
+// position and state i+1 is the position after move i.
+func (t *Transcript) Replay() ([]*RPSGame, error) {
+	current := t.InitialState()
+	states := make([]*RPSGame, 0, len(t.Moves)+1)
+	states = append(states, current.Copy())
+
+	for i, move := range t.Moves {
+		if err := current.MakeMove(move); err != nil {
+			return states, fmt.Errorf("move %d (%d:%d by player %d): %v",
+				i+1, move.CardIndex, move.Position, move.Player, err)
+		}
+		states = append(states, current.Copy())
+	}
+
+	return states, nil
+}
+
+// String returns the transcript in its text format
+func (t *Transcript) String() string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("(;GM[%s]DS[%d]HS[%d]MR[%d]", transcriptGameID, t.DeckSize, t.HandSize, t.MaxRounds))
+	if t.BoardDim != 0 && t.BoardDim != StandardBoardDim {
+		sb.WriteString(fmt.Sprintf("BS[%d]", t.BoardDim))
+	}
+	sb.WriteString(fmt.Sprintf("P1[%s]P2[%s]RE[%s]\n", escapeValue(t.Player1), escapeValue(t.Player2), escapeValue(t.Result)))
+	sb.WriteString(fmt.Sprintf("H1[%s]H2[%s]\n", handTypesNotation(t.Player1Hand, Player1), handTypesNotation(t.Player2Hand, Player2)))
+
+	for i, move := range t.Moves {
+		sb.WriteString(fmt.Sprintf(";%d[%d:%d]", move.Player, move.CardIndex, move.Position))
+		if (i+1)%10 == 0 {
+			sb.WriteString("\n")
+		}
+	}
+	sb.WriteString("\n)\n")
+
+	return sb.String()
+}
+
+// Save writes the transcript to a file
+func (t *Transcript) Save(path string) error {
+	return os.WriteFile(path, []byte(t.String()), 0644)
+}
+
+// Load reads a transcript from a file, replacing the contents of t
+func (t *Transcript) Load(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	parsed, err := ParseTranscript(string(data))
+	if err != nil {
+		return fmt.Errorf("failed to parse transcript %s: %v", path, err)
+	}
+
+	*t = *parsed
+	return nil
+}
+
+// ParseTranscript parses a transcript in the text format produced by String
+func ParseTranscript(text string) (*Transcript, error) {
+	t := &Transcript{BoardDim: StandardBoardDim, Moves: make([]RPSMove, 0)}
+	seenGameID := false
+
+	i := 0
+	for i < len(text) {
+		c := text[i]
+		if c == '(' || c == ')' || c == ';' || c == ' ' || c == '\t' || c == '\r' || c == '\n' {
+			i++
+			continue
+		}
+
+		// Read the property key
+		start := i
+		for i < len(text) && text[i] != '[' {
+			i++
+		}
+		if i == len(text) {
+			return nil, fmt.Errorf("property %q has no value", strings.TrimSpace(text[start:]))
+		}
+		key := strings.TrimSpace(text[start:i])
+
+		// Read the bracketed value, honouring \] escapes
+		i++
+		var value strings.Builder
+		for i < len(text) && text[i] != ']' {
+			if text[i] == '\\' && i+1 < len(text) {
+				i++
+			}
+			value.WriteByte(text[i])
+			i++
+		}
+		if i == len(text) {
+			return nil, fmt.Errorf("unterminated value for property %s", key)
+		}
+		i++
+
+		if err := t.setProperty(key, value.String()); err != nil {
+			return nil, err
+		}
+		if key == "GM" {
+			seenGameID = true
+		}
+	}
+
+	if !seenGameID {
+		return nil, errors.New("missing GM property")
+	}
+	return t, nil
+}
+
+// setProperty applies one parsed KEY[value] pair to the transcript
+func (t *Transcript) setProperty(key, value string) error {
+	var err error
+	switch key {
+	case "GM":
+		if value != transcriptGameID {
+			return fmt.Errorf("unsupported game %q", value)
+		}
+	case "DS":
+		t.DeckSize, err = strconv.Atoi(value)
+	case "HS":
+		t.HandSize, err = strconv.Atoi(value)
+	case "MR":
+		t.MaxRounds, err = strconv.Atoi(value)
+	case "BS":
+		t.BoardDim, err = strconv.Atoi(value)
+		if err == nil && (t.BoardDim < MinBoardDim || t.BoardDim > MaxBoardDim) {
+			err = fmt.Errorf("board size must be between %d and %d", MinBoardDim, MaxBoardDim)
+		}
+	case "P1":
+		t.Player1 = value
+	case "P2":
+		t.Player2 = value
+	case "RE":
+		t.Result = value
+	case "H1":
+		t.Player1Hand, err = parseHandTypes(value)
+	case "H2":
+		t.Player2Hand, err = parseHandTypes(value)
+	case "1", "2":
+		var move RPSMove
+		move, err = parseMove(key, value)
+		t.Moves = append(t.Moves, move)
+	default:
+		// Unknown properties are ignored so the format can be extended
+	}
+
+	if err != nil {
+		return fmt.Errorf("invalid %s[%s]: %v", key, value, err)
+	}
+	return nil
+}
+
+// parseMove parses a "card:position" move value for the given player key
+func parseMove(player, value string) (RPSMove, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 2 {
+		return RPSMove{}, errors.New("expected card:position")
+	}
+
+	cardIndex, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return RPSMove{}, err
+	}
+	position, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return RPSMove{}, err
+	}
+
+	movePlayer := Player1
+	if player == "2" {
+		movePlayer = Player2
+	}
+	return RPSMove{CardIndex: cardIndex, Position: position, Player: movePlayer}, nil
+}
+
+// parseHandTypes parses hand letters such as "RPS" or "rps"; "-" is an empty hand
+func parseHandTypes(value string) ([]RPSCardType, error) {
+	if value == "-" {
+		return []RPSCardType{}, nil
+	}
+
+	types := make([]RPSCardType, len(value))
+	for i, letter := range strings.ToUpper(value) {
+		switch letter {
+		case 'R':
+			types[i] = Rock
+		case 'P':
+			types[i] = Paper
+		case 'S':
+			types[i] = Scissors
+		default:
+			return nil, fmt.Errorf("unknown card %q", letter)
+		}
+	}
+	return types, nil
+}
+
+// handTypesNotation formats a list of card types using the Notation letters
+func handTypesNotation(types []RPSCardType, player RPSPlayer) string {
+	hand := make([]RPSCard, len(types))
+	for i, cardType := range types {
+		hand[i] = RPSCard{Type: cardType}
+	}
+	return handNotation(hand, player)
+}
+
+// escapeValue escapes characters that would end a property value
+func escapeValue(value string) string {
+	value = strings.ReplaceAll(value, "\\", "\\\\")
+	return strings.ReplaceAll(value, "]", "\\]")
+}
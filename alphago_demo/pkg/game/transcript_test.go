@@ -0,0 +1,96 @@
+package game
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTranscriptReplayMatchesGame(t *testing.T) {
+	g := NewRPSGame(21, 5, 10)
+	transcript := NewTranscript(g, 21, 5)
+	transcript.Player1 = "Alice"
+	transcript.Player2 = "Bob [test]"
+
+	var positions []*RPSGame
+	positions = append(positions, g.Copy())
+	for !g.IsGameOver() {
+		move, err := g.GetRandomMove()
+		if err != nil {
+			t.Fatalf("GetRandomMove failed: %v", err)
+		}
+		if err := g.MakeMove(move); err != nil {
+			t.Fatalf("MakeMove failed: %v", err)
+		}
+		transcript.Record(move)
+		positions = append(positions, g.Copy())
+	}
+	transcript.Result = "draw"
+
+	path := filepath.Join(t.TempDir(), "game.rpsgame")
+	if err := transcript.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	var loaded Transcript
+	if err := loaded.Load(path); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if !reflect.DeepEqual(&loaded, transcript) {
+		t.Fatalf("Loaded transcript differs:\nwant %+v\ngot  %+v", transcript, &loaded)
+	}
+
+	states, err := loaded.Replay()
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if len(states) != len(positions) {
+		t.Fatalf("Expected %d states, got %d", len(positions), len(states))
+	}
+	for i := range states {
+		if states[i].Notation() != positions[i].Notation() {
+			t.Errorf("State %d: expected %s, got %s", i, positions[i].Notation(), states[i].Notation())
+		}
+	}
+}
+
+func TestParseTranscriptRejectsInvalidInput(t *testing.T) {
+	invalid := []string{
+		"",
+		"(;GM[Chess])",
+		"(;GM[RPSCard]DS[abc])",
+		"(;GM[RPSCard]H1[RPX])",
+		"(;GM[RPSCard];1[4])",
+		"(;GM[RPSCard]P1[unterminated",
+	}
+	for _, text := range invalid {
+		if _, err := ParseTranscript(text); err == nil {
+			t.Errorf("Expected an error parsing %q", text)
+		}
+	}
+}
+
+func TestTranscriptRecordsBoardSize(t *testing.T) {
+	g := NewRPSGameSized(5, 30, 6, 6, nil)
+	transcript := NewTranscript(g, 30, 6)
+	move := g.GetValidMoves()[len(g.GetValidMoves())-1]
+	g.MakeMove(move)
+	transcript.Record(move)
+
+	if !strings.Contains(transcript.String(), "BS[5]") {
+		t.Errorf("Expected a BS[5] property in %q", transcript.String())
+	}
+
+	parsed, err := ParseTranscript(transcript.String())
+	if err != nil {
+		t.Fatalf("ParseTranscript failed: %v", err)
+	}
+	states, err := parsed.Replay()
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if final := states[len(states)-1]; final.Notation() != g.Notation() {
+		t.Errorf("Expected %s, got %s", g.Notation(), final.Notation())
+	}
+}
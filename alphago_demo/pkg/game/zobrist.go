@@ -0,0 +1,139 @@
+package game
+
+import "math/rand"
+
+const (
+	// zobristHandCards and zobristRounds are how many hand cards of one type
+	// and how many rounds get their own key; larger counts reuse keys
+	zobristHandCards = 32
+	zobristRounds    = 64
+
+	// zobristSeed fixes the keys so hashes are the same in every run
+	zobristSeed = 0x5eed2b0a
+)
+
+// zobristKeys holds the random numbers XORed together to hash a position
+type zobristKeys struct {
+	// cells[pos][type][owner-1] is set for each card on the board
+	cells [MaxBoardDim * MaxBoardDim][NumCardTypes][2]uint64
+
+	// hand[player-1][type][k] is set for the k-th card of a type in a hand.
+	// Hands are hashed as multisets since the order of a hand does not affect play.
+	hand [2][NumCardTypes][zobristHandCards]uint64
+
+	player2ToMove uint64
+	round         [zobristRounds]uint64
+}
+
+var zobrist = newZobristKeys(rand.New(rand.NewSource(zobristSeed)))
+
+// newZobristKeys draws a full set of keys from rng
+func newZobristKeys(rng *rand.Rand) *zobristKeys {
+	keys := &zobristKeys{}
+	for pos := range keys.cells {
+		for t := range keys.cells[pos] {
+			for owner := range keys.cells[pos][t] {
+				keys.cells[pos][t][owner] = rng.Uint64()
+			}
+		}
+	}
+	for p := range keys.hand {
+		for t := range keys.hand[p] {
+			for k := range keys.hand[p][t] {
+				keys.hand[p][t][k] = rng.Uint64()
+			}
+		}
+	}
+	keys.player2ToMove = rng.Uint64()
+	for r := range keys.round {
+		keys.round[r] = rng.Uint64()
+	}
+	return keys
+}
+
+// cellKey is the key for a card of the given type and owner on pos
+func (z *zobristKeys) cellKey(pos int, card RPSCard) uint64 {
+	if (card.Owner != Player1 && card.Owner != Player2) || !validCardType(card.Type) {
+		return 0
+	}
+	return z.cells[pos][card.Type][card.Owner-1]
+}
+
+// handKey is the key for the k-th card of type t in player's hand, counting from 0
+func (z *zobristKeys) handKey(player RPSPlayer, t RPSCardType, k int) uint64 {
+	if (player != Player1 && player != Player2) || !validCardType(t) {
+		return 0
+	}
+	return z.hand[player-1][t][k%zobristHandCards]
+}
+
+// roundKey is the key for the round number
+func (z *zobristKeys) roundKey(round int) uint64 {
+	if round < 0 {
+		round = -round
+	}
+	return z.round[round%zobristRounds]
+}
+
+// Hash returns a 64-bit Zobrist hash of the position: the board, both hands
+// (as multisets of card types), the player to move and the round. Equal
+// positions always hash equally; different positions collide only by chance.
+//
+// The hash is computed on first use and then kept up to date by MakeMove and
+// UndoMove at little cost. The Set* methods refresh it too, but code that edits
+// Board or the hands directly afterwards must call RecomputeHash.
+func (g *RPSGame) Hash() uint64 {
+	if !g.hashValid {
+		g.RecomputeHash()
+	}
+	return g.hash
+}
+
+// RecomputeHash hashes the position from scratch and returns the new hash
+func (g *RPSGame) RecomputeHash() uint64 {
+	g.hash = g.computeHash()
+	g.hashValid = true
+	return g.hash
+}
+
+// computeHash hashes the position from scratch
+func (g *RPSGame) computeHash() uint64 {
+	var h uint64
+	for pos, card := range g.Board {
+		h ^= zobrist.cellKey(pos, card)
+	}
+
+	for _, player := range []RPSPlayer{Player1, Player2} {
+		var counts [NumCardTypes]int
+		for _, card := range g.hand(player) {
+			if validCardType(card.Type) {
+				h ^= zobrist.handKey(player, card.Type, counts[card.Type])
+				counts[card.Type]++
+			}
+		}
+	}
+
+	if g.CurrentPlayer == Player2 {
+		h ^= zobrist.player2ToMove
+	}
+	return h ^ zobrist.roundKey(g.Round)
+}
+
+// hand returns the given player's hand
+func (g *RPSGame) hand(player RPSPlayer) []RPSCard {
+	if player == Player2 {
+		return g.Player2Hand
+	}
+	return g.Player1Hand
+}
+
+// countType returns how many cards of type t are in hand
+func countType(hand []RPSCard, t RPSCardType) int {
+	n := 0
+	for _, card := range hand {
+		if card.Type == t {
+			n++
+		}
+	}
+	return n
+}
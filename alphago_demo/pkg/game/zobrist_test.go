@@ -0,0 +1,115 @@
+package game
+
+import (
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestHashMatchesRecomputeAfterMoves(t *testing.T) {
+	rng := rand.New(rand.NewSource(11))
+
+	for i := 0; i < 50; i++ {
+		g := NewRPSGameSeeded(21, 5, 10, rng)
+		g.Hash() // Start incremental updates from the initial deal
+
+		var undos []MoveUndo
+		var hashes []uint64
+		for !g.IsGameOver() {
+			moves := g.GetValidMoves()
+			hashes = append(hashes, g.Hash())
+
+			undo, err := g.MakeMoveReversible(moves[rng.Intn(len(moves))])
+			if err != nil {
+				t.Fatalf("MakeMoveReversible failed: %v", err)
+			}
+			undos = append(undos, undo)
+
+			if got, want := g.Hash(), g.computeHash(); got != want {
+				t.Fatalf("Game %d, move %d: incremental hash %x, recomputed %x\n%s",
+					i, len(undos), got, want, g.Notation())
+			}
+			if copied := g.Copy(); copied.Hash() != g.Hash() {
+				t.Fatalf("Copy changed the hash from %x to %x", g.Hash(), copied.Hash())
+			}
+		}
+
+		// Undoing restores every earlier hash
+		for j := len(undos) - 1; j >= 0; j-- {
+			g.UndoMove(undos[j])
+			if g.Hash() != hashes[j] {
+				t.Fatalf("Game %d: after undoing to move %d, hash %x, want %x", i, j, g.Hash(), hashes[j])
+			}
+		}
+	}
+}
+
+func TestHashIgnoresMoveOrderAndHandOrder(t *testing.T) {
+	newGame := func() *RPSGame {
+		g := NewRPSGame(21, 5, 10)
+		g.SetPlayer1Hand([]int{int(Rock), int(Paper), int(Rock)})
+		g.SetPlayer2Hand([]int{int(Scissors), int(Paper), int(Rock)})
+		return g
+	}
+
+	// Corners and centre never touch, so the order of player 1's moves does not matter
+	a := newGame()
+	a.Hash()
+	a.MakeMove(RPSMove{CardIndex: 0, Position: 0, Player: Player1}) // Rock
+	a.MakeMove(RPSMove{CardIndex: 0, Position: 8, Player: Player2}) // Scissors
+	a.MakeMove(RPSMove{CardIndex: 0, Position: 4, Player: Player1}) // Paper
+
+	b := newGame()
+	b.Hash()
+	b.MakeMove(RPSMove{CardIndex: 1, Position: 4, Player: Player1}) // Paper
+	b.MakeMove(RPSMove{CardIndex: 0, Position: 8, Player: Player2}) // Scissors
+	b.MakeMove(RPSMove{CardIndex: 1, Position: 0, Player: Player1}) // The second Rock
+
+	if a.Hash() != b.Hash() {
+		t.Errorf("Transposed positions hash differently:\n%s\n%s", a.Notation(), b.Notation())
+	}
+
+	// Changing the player to move changes the hash
+	before := a.Hash()
+	a.SetCurrentPlayer(0) // Player 2 was to move
+	if a.Hash() == before {
+		t.Error("Expected the player to move to change the hash")
+	}
+}
+
+// canonicalPosition describes a position with each hand sorted, so positions
+// that Hash treats as equal describe equally
+func canonicalPosition(g *RPSGame) string {
+	fields := strings.Fields(g.Notation())
+	for i := 1; i <= 2; i++ {
+		letters := []byte(fields[i])
+		sort.Slice(letters, func(a, b int) bool { return letters[a] < letters[b] })
+		fields[i] = string(letters)
+	}
+	return strings.Join(fields, " ")
+}
+
+func TestHashRarelyCollides(t *testing.T) {
+	rng := rand.New(rand.NewSource(5))
+	seen := make(map[uint64]string)
+	collisions := 0
+
+	for i := 0; i < 2000; i++ {
+		g := NewRPSGameSeeded(21, 5, 10, rng)
+		for !g.IsGameOver() {
+			moves := g.GetValidMoves()
+			g.MakeMove(moves[rng.Intn(len(moves))])
+
+			position := canonicalPosition(g)
+			if other, found := seen[g.Hash()]; found && other != position {
+				collisions++
+			}
+			seen[g.Hash()] = position
+		}
+	}
+
+	if collisions > 0 {
+		t.Errorf("%d hash collisions among %d positions", collisions, len(seen))
+	}
+}
@@ -0,0 +1,71 @@
+// Package logging sets up the leveled, structured logger that the training and
+// tournament code reports through. Packages log with the log/slog default
+// logger; commands call AddFlags before flag.Parse and Install after it.
+package logging
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Config selects the level and format of log output
+type Config struct {
+	Level  string // "debug", "info", "warn" or "error"
+	Format string // "text" or "json"
+}
+
+// AddFlags registers --log-level and --log-format on fs and returns the
+// Config they fill in
+func AddFlags(fs *flag.FlagSet) *Config {
+	c := &Config{}
+	fs.StringVar(&c.Level, "log-level", "info", "Lowest level logged: debug, info, warn or error")
+	fs.StringVar(&c.Format, "log-format", "text", "Log format: text or json")
+	return c
+}
+
+// Install makes a logger writing to stderr in c's level and format the
+// slog default
+func (c *Config) Install() error {
+	logger, err := c.NewLogger(os.Stderr)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+	return nil
+}
+
+// NewLogger returns a logger writing to w in c's level and format
+func (c *Config) NewLogger(w io.Writer) (*slog.Logger, error) {
+	level, err := ParseLevel(c.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	switch strings.ToLower(c.Format) {
+	case "", "text":
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	}
+	return nil, fmt.Errorf("unknown log format %q (want text or json)", c.Format)
+}
+
+// ParseLevel converts "debug", "info", "warn" or "error" to a level
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("unknown log level %q (want debug, info, warn or error)", name)
+}
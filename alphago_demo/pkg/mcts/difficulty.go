@@ -0,0 +1,119 @@
+package mcts
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+)
+
+// Difficulty sets how strongly the AI plays against a human, by combining the
+// number of simulations with the temperature used to pick a move from them
+type Difficulty int
+
+const (
+	DifficultyEasy Difficulty = iota
+	DifficultyMedium
+	DifficultyHard
+)
+
+// Difficulties lists every difficulty from easiest to hardest
+var Difficulties = []Difficulty{DifficultyEasy, DifficultyMedium, DifficultyHard}
+
+// ParseDifficulty parses a difficulty name such as "easy", ignoring case
+func ParseDifficulty(name string) (Difficulty, error) {
+	for _, d := range Difficulties {
+		if strings.EqualFold(strings.TrimSpace(name), d.String()) {
+			return d, nil
+		}
+	}
+	return DifficultyMedium, fmt.Errorf("unknown difficulty %q (want easy, medium or hard)", name)
+}
+
+// String returns the difficulty's name
+func (d Difficulty) String() string {
+	switch d {
+	case DifficultyEasy:
+		return "easy"
+	case DifficultyMedium:
+		return "medium"
+	case DifficultyHard:
+		return "hard"
+	}
+	return fmt.Sprintf("difficulty %d", int(d))
+}
+
+// Simulations returns the number of simulations searched per move
+func (d Difficulty) Simulations() int {
+	switch d {
+	case DifficultyEasy:
+		return 25
+	case DifficultyMedium:
+		return 200
+	}
+	return 800
+}
+
+// Temperature returns the temperature passed to SampleMove: easy play samples
+// in proportion to visits, so it often plays a weaker move, while hard play
+// always takes the most visited one
+func (d Difficulty) Temperature() float64 {
+	switch d {
+	case DifficultyEasy:
+		return 1.0
+	case DifficultyMedium:
+		return 0.5
+	}
+	return 0
+}
+
+// Apply returns params with the simulation count set for the difficulty
+func (d Difficulty) Apply(params RPSMCTSParams) RPSMCTSParams {
+	params.NumSimulations = d.Simulations()
+	return params
+}
+
+// SampleMove picks a child of the root after a search, with probability
+// proportional to visits^(1/temperature). A temperature of zero or below
+// returns the most visited child, as Search does; higher temperatures make
+// less visited moves more likely.
+func (mcts *RPSMCTS) SampleMove(temperature float64) *RPSMCTSNode {
+	if mcts.Root == nil || len(mcts.Root.Children) == 0 {
+		return nil
+	}
+	if temperature <= 0 {
+		return mcts.Root.MostVisitedChild()
+	}
+
+	// Weights are taken relative to the most visited child so that low
+	// temperatures do not overflow
+	best := float64(mcts.Root.MostVisitedChild().Visits.Load())
+	if best == 0 {
+		return mcts.Root.MostVisitedChild()
+	}
+
+	weights := make([]float64, len(mcts.Root.Children))
+	total := 0.0
+	for i, child := range mcts.Root.Children {
+		weights[i] = math.Pow(float64(child.Visits.Load())/best, 1.0/temperature)
+		total += weights[i]
+	}
+
+	r := mcts.float64() * total
+	for i, w := range weights {
+		r -= w
+		if r < 0 {
+			return mcts.Root.Children[i]
+		}
+	}
+	return mcts.Root.MostVisitedChild()
+}
+
+// float64 returns a random number in [0, 1) from Params.Rng, or from the global
+// source when no Rng is set
+func (mcts *RPSMCTS) float64() float64 {
+	if mcts.Params.Rng != nil {
+		return mcts.Params.Rng.Float64()
+	}
+	return rand.Float64()
+}
@@ -0,0 +1,99 @@
+package mcts
+
+import (
+	"sync"
+
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/analysis"
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
+	neural "github.com/zachbeta/neural_rps/alphago_demo/pkg/rps_net_impl"
+)
+
+// LeafEvaluator scores the positions a search stops at. Evaluate returns the
+// value of a position that is not over for the player to move, on the value
+// network's scale: 1 is a win, 0.5 a draw and 0 a loss. A parallel search
+// calls it from several goroutines at once.
+type LeafEvaluator interface {
+	Evaluate(state *game.RPSGame) float64
+}
+
+// hybridEvaluator solves endgames with minimax and leaves the rest of the game
+// to a value network
+type hybridEvaluator struct {
+	net          *neural.RPSValueNetwork
+	shallowDepth int
+
+	// The engine keeps per-search state, so searches take turns
+	mu      sync.Mutex
+	minimax *analysis.MinimaxEngine
+}
+
+// HybridEvaluator returns a LeafEvaluator that searches positions with at most
+// shallowDepth empty squares to the end of the game with minimax and scores
+// all other positions with net. The game ends by the time the board fills, so
+// the endgame values are proven wins, draws and losses rather than estimates.
+// Only the sign of minimax's score is used, so any evaluation function that
+// scores a Player1 win above zero and a Player2 win below it will do. The
+// evaluator sets minimax's MaxDepth for each search and restores it after.
+func HybridEvaluator(net *neural.RPSValueNetwork, minimax *analysis.MinimaxEngine, shallowDepth int) LeafEvaluator {
+	return &hybridEvaluator{
+		net:          net,
+		shallowDepth: shallowDepth,
+		minimax:      minimax,
+	}
+}
+
+// Evaluate implements LeafEvaluator
+func (h *hybridEvaluator) Evaluate(state *game.RPSGame) float64 {
+	empty := 0
+	for _, card := range state.Board {
+		if card.Owner == game.NoPlayer {
+			empty++
+		}
+	}
+	if empty > h.shallowDepth {
+		return h.net.Predict(state)
+	}
+
+	// The search scores positions for Player1; the engine only reads the
+	// position, but it may refresh its hash
+	h.mu.Lock()
+	savedDepth := h.minimax.MaxDepth
+	h.minimax.MaxDepth = empty
+	_, score := h.minimax.FindBestMove(state.Copy())
+	h.minimax.MaxDepth = savedDepth
+	h.mu.Unlock()
+
+	if state.CurrentPlayer == game.Player2 {
+		score = -score
+	}
+	switch {
+	case score > 0:
+		return 1.0
+	case score < 0:
+		return 0.0
+	default:
+		return 0.5
+	}
+}
+
+// tablebaseEvaluator looks endgames up in a tablebase and leaves the rest of
+// the game to a value network
+type tablebaseEvaluator struct {
+	tablebase *analysis.Tablebase
+	net       *neural.RPSValueNetwork
+}
+
+// TablebaseEvaluator returns a LeafEvaluator that scores positions found in
+// tablebase with their exact result and all other positions with net. Lookups
+// only read the tablebase, so a parallel search needs no locking.
+func TablebaseEvaluator(tablebase *analysis.Tablebase, net *neural.RPSValueNetwork) LeafEvaluator {
+	return &tablebaseEvaluator{tablebase: tablebase, net: net}
+}
+
+// Evaluate implements LeafEvaluator
+func (t *tablebaseEvaluator) Evaluate(state *game.RPSGame) float64 {
+	if entry, found := t.tablebase.Probe(state); found {
+		return entry.Result.Value()
+	}
+	return t.net.Predict(state)
+}
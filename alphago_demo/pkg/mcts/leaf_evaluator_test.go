@@ -0,0 +1,100 @@
+package mcts
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/analysis"
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
+	neural "github.com/zachbeta/neural_rps/alphago_demo/pkg/rps_net_impl"
+)
+
+// nearTerminalPosition returns a position with three empty squares in which
+// Player1's only winning move is scissors on square 0, capturing the papers on
+// squares 1 and 3. Every other move loses.
+func nearTerminalPosition() *game.RPSGame {
+	g := game.NewRPSGame(21, 5, 10)
+	g.Board[1] = game.RPSCard{Type: game.Paper, Owner: game.Player2}
+	g.Board[2] = game.RPSCard{Type: game.Paper, Owner: game.Player1}
+	g.Board[3] = game.RPSCard{Type: game.Paper, Owner: game.Player2}
+	g.Board[4] = game.RPSCard{Type: game.Rock, Owner: game.Player2}
+	g.Board[6] = game.RPSCard{Type: game.Rock, Owner: game.Player2}
+	g.Board[7] = game.RPSCard{Type: game.Rock, Owner: game.Player2}
+	g.Player1Hand = []game.RPSCard{
+		{Type: game.Rock, Owner: game.Player1},
+		{Type: game.Scissors, Owner: game.Player1},
+	}
+	g.Player2Hand = []game.RPSCard{
+		{Type: game.Rock, Owner: game.Player2},
+		{Type: game.Rock, Owner: game.Player2},
+	}
+	g.CurrentPlayer = game.Player1
+	g.Round = 4
+	return g
+}
+
+func TestHybridEvaluatorFindsProvenWin(t *testing.T) {
+	gameState := nearTerminalPosition()
+	valueNetwork := neural.NewRPSValueNetwork(32)
+	minimax := analysis.NewMinimaxEngine(2, analysis.StandardEvaluator)
+	hybrid := HybridEvaluator(valueNetwork, minimax, 3)
+
+	// An untrained network has no idea the position is won
+	if value := valueNetwork.Predict(gameState); value > 0.99 {
+		t.Fatalf("Expected the untrained network to miss the win, got %f", value)
+	}
+	if value := hybrid.Evaluate(gameState); value != 1.0 {
+		t.Errorf("Expected the hybrid to prove the win, got %f", value)
+	}
+	if minimax.MaxDepth != 2 {
+		t.Errorf("Expected minimax's MaxDepth to be restored to 2, got %d", minimax.MaxDepth)
+	}
+
+	// Further from the end the network is used
+	opening := game.NewRPSGame(21, 5, 10)
+	if got, want := hybrid.Evaluate(opening), valueNetwork.Predict(opening); got != want {
+		t.Errorf("Expected the network's value %f for the opening, got %f", want, got)
+	}
+
+	// A search using the hybrid finds the only winning move
+	params := DefaultRPSMCTSParams()
+	params.NumSimulations = 200
+	params.DirichletNoise = false
+	params.Rng = rand.New(rand.NewSource(1))
+	engine := NewRPSMCTS(neural.NewRPSPolicyNetwork(32), valueNetwork, params)
+	engine.Evaluator = hybrid
+	engine.SetRootState(gameState)
+
+	best := engine.Search()
+	if best == nil {
+		t.Fatal("Expected the search to find a move")
+	}
+	card := gameState.Player1Hand[best.Move.CardIndex]
+	if best.Move.Position != 0 || card.Type != game.Scissors {
+		t.Errorf("Expected scissors on square 0, got %v on square %d", card.Type, best.Move.Position)
+	}
+}
+
+func TestTablebaseEvaluator(t *testing.T) {
+	gameState := nearTerminalPosition()
+	valueNetwork := neural.NewRPSValueNetwork(32)
+	evaluator := TablebaseEvaluator(analysis.BuildTablebase(1), valueNetwork)
+
+	// Three empty squares are beyond a one-square tablebase
+	if got, want := evaluator.Evaluate(gameState), valueNetwork.Predict(gameState); got != want {
+		t.Errorf("Expected the network's value %f, got %f", want, got)
+	}
+
+	// Scissors on square 0 and a reply leave one square, and Player1 still wins
+	for _, move := range []game.RPSMove{
+		{CardIndex: 1, Position: 0, Player: game.Player1},
+		{CardIndex: 0, Position: 5, Player: game.Player2},
+	} {
+		if err := gameState.MakeMove(move); err != nil {
+			t.Fatalf("MakeMove(%+v) failed: %v", move, err)
+		}
+	}
+	if value := evaluator.Evaluate(gameState); value != 1.0 {
+		t.Errorf("Expected the tablebase to value the win at 1, got %f", value)
+	}
+}
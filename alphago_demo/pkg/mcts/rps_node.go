@@ -2,12 +2,15 @@ package mcts
 
 import (
 	"math"
+	"sort"
 	"sync/atomic"
 
 	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
 )
 
-// RPSMCTSNode represents a node in the MCTS tree for RPS
+// RPSMCTSNode represents a node in the MCTS tree for RPS. With a transposition
+// table the tree becomes a graph: a position reached by several move orders is
+// one node with several parents, and Move and Parent record the first of them.
 type RPSMCTSNode struct {
 	GameState  *game.RPSGame
 	Move       *game.RPSMove
@@ -16,6 +19,12 @@ type RPSMCTSNode struct {
 	Visits     atomic.Int64
 	TotalValue float64
 	Priors     []float64 // Policy priors from neural network
+
+	// The move from this node to each child, parallel to Children
+	childMoves []game.RPSMove
+
+	// Moves not yet added as children under progressive widening, highest prior first
+	unexpanded []game.RPSMove
 }
 
 // NewRPSMCTSNode creates a new MCTS node
@@ -31,46 +40,115 @@ func NewRPSMCTSNode(state *game.RPSGame, move *game.RPSMove, parent *RPSMCTSNode
 	return n
 }
 
-// UCB calculates the Upper Confidence Bound value for this node
-// Used for node selection during MCTS
+// UCB calculates the PUCT score used to pick a child during selection:
+//
+//	PUCT(s, a) = Q(s, a) + c * P(s, a) * sqrt(N(s)) / (1 + N(s, a))
+//
+// Q is the child's mean value for the player choosing the move, P the policy
+// prior for the move's square, N(s) the parent's visits and N(s, a) the
+// child's. An unvisited child has no Q, so UCB scores it as +Inf and every
+// child is tried once before any is revisited; UCBWithFPU gives it a finite
+// first-play urgency instead.
 func (n *RPSMCTSNode) UCB(explorationConstant float64) float64 {
-	visits := n.Visits.Load()
-	if visits == 0 {
+	if n.Visits.Load() == 0 {
 		return math.Inf(1) // Infinity for unvisited nodes
 	}
+	return puct(n.Parent, n, n.prior(), explorationConstant, 0)
+}
 
-	// Get prior based on move position if available
-	prior := 1.0 / float64(9) // Uniform default
-	if n.Move != nil && n.Parent != nil && n.Parent.Priors != nil {
-		// Position-based prior (simplified for RPS card game)
-		prior = n.Parent.Priors[n.Move.Position]
+// UCBWithFPU calculates the PUCT score with fpuValue standing in for Q while the
+// child is unvisited. Unvisited children then compete with visited ones on their
+// prior, so a low FPU concentrates the search on the moves already found good.
+func (n *RPSMCTSNode) UCBWithFPU(explorationConstant, fpuValue float64) float64 {
+	return puct(n.Parent, n, n.prior(), explorationConstant, fpuValue)
+}
+
+// puct computes Q + U for child as seen from parent, using unvisitedQ as Q
+// when the child has no visits
+func puct(parent, child *RPSMCTSNode, prior, explorationConstant, unvisitedQ float64) float64 {
+	visits := child.Visits.Load()
+
+	// Q = average value, from the view of the player who moved into the child
+	exploitation := unvisitedQ
+	if visits > 0 {
+		exploitation = child.TotalValue / float64(visits)
 	}
 
-	// UCB formula with prior: Q + U
-	// Q = average value
 	// U = exploration bonus with prior
-	exploitation := n.TotalValue / float64(visits)
 	parentVisits := int64(0)
-	if n.Parent != nil {
-		parentVisits = n.Parent.Visits.Load()
+	if parent != nil {
+		parentVisits = parent.Visits.Load()
 	}
 	exploration := explorationConstant * prior * math.Sqrt(float64(parentVisits)) / (1.0 + float64(visits))
 
 	return exploitation + exploration
 }
 
+// prior returns the policy prior of the move that first led to this node
+func (n *RPSMCTSNode) prior() float64 {
+	if n.Move != nil && n.Parent != nil && n.Parent.Priors != nil {
+		// Position-based prior (simplified for RPS card game)
+		return n.Parent.Priors[n.Move.Position]
+	}
+	return n.uniformPrior()
+}
+
+// childPrior returns the policy prior of the move from this node to its i-th child
+func (n *RPSMCTSNode) childPrior(i int) float64 {
+	if i >= len(n.childMoves) {
+		// Children attached directly rather than by expansion
+		return n.Children[i].prior()
+	}
+	if n.Priors == nil {
+		return n.uniformPrior()
+	}
+	return n.Priors[n.childMoves[i].Position]
+}
+
+// uniformPrior is the prior used when no policy is available: one over the number of squares
+func (n *RPSMCTSNode) uniformPrior() float64 {
+	if n.GameState != nil {
+		return 1.0 / float64(len(n.GameState.Board))
+	}
+	return 1.0 / float64(game.StandardBoardDim*game.StandardBoardDim)
+}
+
+// FPUValue returns the first-play urgency for this node's unvisited children:
+// the node's value for the player to move here, less the reduction. A node's
+// own statistics are kept for the player who moved into it, hence the flip.
+func (n *RPSMCTSNode) FPUValue(reduction float64) float64 {
+	value := 0.5
+	if visits := n.Visits.Load(); visits > 0 {
+		value = 1.0 - n.TotalValue/float64(visits)
+	}
+	return value - reduction
+}
+
 // SelectChild selects the child with the highest UCB value
 func (n *RPSMCTSNode) SelectChild(explorationConstant float64) *RPSMCTSNode {
+	return n.selectChild(explorationConstant, math.Inf(1))
+}
+
+// SelectChildFPU selects the child with the highest UCBWithFPU score, valuing
+// unvisited children at FPUValue(reduction)
+func (n *RPSMCTSNode) SelectChildFPU(explorationConstant, reduction float64) *RPSMCTSNode {
+	return n.selectChild(explorationConstant, n.FPUValue(reduction))
+}
+
+// selectChild returns the child with the highest PUCT score as seen from this
+// node, valuing unvisited children at unvisitedQ. Scoring from the selecting
+// node matters when a child has other parents.
+func (n *RPSMCTSNode) selectChild(explorationConstant, unvisitedQ float64) *RPSMCTSNode {
 	if len(n.Children) == 0 {
 		return nil
 	}
 
-	bestChild := n.Children[0]
-	bestUCB := bestChild.UCB(explorationConstant)
+	var bestChild *RPSMCTSNode
+	bestUCB := math.Inf(-1)
 
-	for _, child := range n.Children[1:] {
-		ucb := child.UCB(explorationConstant)
-		if ucb > bestUCB {
+	for i, child := range n.Children {
+		ucb := puct(n, child, n.childPrior(i), explorationConstant, unvisitedQ)
+		if bestChild == nil || ucb > bestUCB {
 			bestChild = child
 			bestUCB = ucb
 		}
@@ -81,28 +159,95 @@ func (n *RPSMCTSNode) SelectChild(explorationConstant float64) *RPSMCTSNode {
 
 // ExpandAll expands all possible child nodes
 func (n *RPSMCTSNode) ExpandAll(priors []float64) {
+	n.expandAll(priors, nil)
+}
+
+// expandAll creates a child for every valid move, sharing nodes through table when it is set
+func (n *RPSMCTSNode) expandAll(priors []float64, table *transpositionTable) {
+	// Children read their priors from this node when computing UCB
+	n.Priors = priors
+	n.unexpanded = nil
+
 	// Clear any existing children
 	n.Children = make([]*RPSMCTSNode, 0)
+	n.childMoves = nil
 
-	// Get valid moves
-	validMoves := n.GameState.GetValidMoves()
+	for _, move := range n.GameState.GetValidMoves() {
+		n.addChild(move, table)
+	}
+}
 
-	// Create children for each valid move
-	for _, move := range validMoves {
-		// Create a copy of the game state
-		childState := n.GameState.Copy()
+// ExpandProgressive prepares the node for progressive widening. Valid moves are
+// ordered by prior, highest first, and only the first becomes a child; Widen
+// adds the rest as the node's visit count grows.
+func (n *RPSMCTSNode) ExpandProgressive(priors []float64) {
+	n.expandProgressive(priors, nil)
+}
 
-		// Apply the move
-		moveCopy := move // Copy to avoid issues with references inside the loop
-		err := childState.MakeMove(moveCopy)
-		if err != nil {
-			continue // Skip invalid moves
-		}
+// expandProgressive is ExpandProgressive, sharing nodes through table when it is set
+func (n *RPSMCTSNode) expandProgressive(priors []float64, table *transpositionTable) {
+	n.Priors = priors
+	n.Children = make([]*RPSMCTSNode, 0)
+	n.childMoves = nil
 
-		// Create and add the child node
-		child := NewRPSMCTSNode(childState, &moveCopy, n, priors)
-		n.Children = append(n.Children, child)
+	moves := append([]game.RPSMove(nil), n.GameState.GetValidMoves()...)
+	if priors != nil {
+		sort.SliceStable(moves, func(i, j int) bool {
+			return priors[moves[i].Position] > priors[moves[j].Position]
+		})
 	}
+	n.unexpanded = moves
+
+	n.widen(1, table)
+}
+
+// Widen adds children in prior order until the node has limit children or
+// every valid move has been added
+func (n *RPSMCTSNode) Widen(limit int) {
+	n.widen(limit, nil)
+}
+
+// widen is Widen, sharing nodes through table when it is set
+func (n *RPSMCTSNode) widen(limit int, table *transpositionTable) {
+	for len(n.Children) < limit && len(n.unexpanded) > 0 {
+		move := n.unexpanded[0]
+		n.unexpanded = n.unexpanded[1:]
+		n.addChild(move, table)
+	}
+}
+
+// CanWiden reports whether progressive widening still has moves to add as children
+func (n *RPSMCTSNode) CanWiden() bool {
+	return len(n.unexpanded) > 0
+}
+
+// addChild adds the child reached by move. With a table, a position already in
+// the search is linked rather than created again, and is added only once even
+// if several moves reach it.
+func (n *RPSMCTSNode) addChild(move game.RPSMove, table *transpositionTable) {
+	childState := n.GameState.Copy()
+	if err := childState.MakeMove(move); err != nil {
+		return // Skip invalid moves
+	}
+
+	var child *RPSMCTSNode
+	if table != nil {
+		child = table.lookup(childState)
+		for _, existing := range n.Children {
+			if existing == child {
+				return
+			}
+		}
+	}
+	if child == nil {
+		child = NewRPSMCTSNode(childState, &move, n, n.Priors)
+		if table != nil {
+			table.store(child)
+		}
+	}
+
+	n.Children = append(n.Children, child)
+	n.childMoves = append(n.childMoves, move)
 }
 
 // Update updates the node statistics based on simulation results
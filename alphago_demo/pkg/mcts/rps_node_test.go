@@ -44,8 +44,8 @@ func TestNewRPSMCTSNode(t *testing.T) {
 		t.Errorf("Expected Children to be empty, got %d children", len(node.Children))
 	}
 
-	if node.Visits != 0 {
-		t.Errorf("Expected Visits to be 0, got %d", node.Visits)
+	if node.Visits.Load() != 0 {
+		t.Errorf("Expected Visits to be 0, got %d", node.Visits.Load())
 	}
 
 	if node.TotalValue != 0.0 {
@@ -67,7 +67,7 @@ func TestRPSMCTSNodeUCB(t *testing.T) {
 	// Create a root node
 	gameState := game.NewRPSGame(15, 5, 10)
 	rootNode := NewRPSMCTSNode(gameState, nil, nil, nil)
-	rootNode.Visits = 10
+	rootNode.Visits.Store(10)
 
 	// Create a child node
 	move := game.RPSMove{CardIndex: 0, Position: 4, Player: game.Player1}
@@ -85,7 +85,7 @@ func TestRPSMCTSNodeUCB(t *testing.T) {
 
 	// Create a child with the move to position 4
 	childNode := NewRPSMCTSNode(childState, &move, rootNode, nil)
-	childNode.Visits = 5
+	childNode.Visits.Store(5)
 	childNode.TotalValue = 3.0 // 60% win rate
 
 	// Add as child to root
@@ -122,7 +122,7 @@ func TestRPSMCTSNodeSelectChild(t *testing.T) {
 	// Create a root node
 	gameState := game.NewRPSGame(15, 5, 10)
 	rootNode := NewRPSMCTSNode(gameState, nil, nil, nil)
-	rootNode.Visits = 30
+	rootNode.Visits.Store(30)
 
 	// Create uniform priors
 	priors := make([]float64, 9)
@@ -143,15 +143,15 @@ func TestRPSMCTSNodeSelectChild(t *testing.T) {
 		switch i {
 		case 0:
 			// Low value, high visits
-			childNode.Visits = 15
+			childNode.Visits.Store(15)
 			childNode.TotalValue = 5.0 // 33% win rate
 		case 1:
 			// High value, medium visits
-			childNode.Visits = 10
+			childNode.Visits.Store(10)
 			childNode.TotalValue = 8.0 // 80% win rate
 		case 2:
 			// Medium value, low visits
-			childNode.Visits = 5
+			childNode.Visits.Store(5)
 			childNode.TotalValue = 3.0 // 60% win rate
 		}
 
@@ -174,6 +174,51 @@ func TestRPSMCTSNodeSelectChild(t *testing.T) {
 	}
 }
 
+func TestRPSMCTSNodeSelectChildFPU(t *testing.T) {
+	gameState := game.NewRPSGame(15, 5, 10)
+	rootNode := NewRPSMCTSNode(gameState, nil, nil, nil)
+	rootNode.Visits.Store(10)
+	rootNode.TotalValue = 4.0 // 60% for the player to move at the root
+
+	priors := make([]float64, 9)
+	for i := range priors {
+		priors[i] = 0.1
+	}
+	rootNode.Priors = priors
+
+	// A visited child worth 70% to the mover, and an unvisited one
+	for i := 0; i < 2; i++ {
+		move := game.RPSMove{CardIndex: 0, Position: i, Player: game.Player1}
+		childState := gameState.Copy()
+		childState.MakeMove(move)
+		childNode := NewRPSMCTSNode(childState, &move, rootNode, nil)
+		if i == 0 {
+			childNode.Visits.Store(5)
+			childNode.TotalValue = 3.5
+		}
+		rootNode.Children = append(rootNode.Children, childNode)
+	}
+
+	if fpu := rootNode.FPUValue(0.25); math.Abs(fpu-0.35) > 1e-9 {
+		t.Errorf("Expected FPU value 0.6 - 0.25 = 0.35, got %f", fpu)
+	}
+
+	// Without FPU the unvisited child is always tried first
+	if child := rootNode.SelectChild(1.0); child.Move.Position != 1 {
+		t.Errorf("Expected SelectChild to pick the unvisited child, got position %d", child.Move.Position)
+	}
+
+	// A large reduction makes the unvisited child look worse than the known good one
+	if child := rootNode.SelectChildFPU(1.0, 0.25); child.Move.Position != 0 {
+		t.Errorf("Expected FPU selection to keep the visited child, got position %d", child.Move.Position)
+	}
+
+	// With no reduction, the unvisited child's full exploration bonus wins
+	if child := rootNode.SelectChildFPU(1.0, 0); child.Move.Position != 1 {
+		t.Errorf("Expected FPU without a reduction to pick the unvisited child, got position %d", child.Move.Position)
+	}
+}
+
 func TestRPSMCTSNodeExpandAll(t *testing.T) {
 	// Create a game state
 	gameState := game.NewRPSGame(15, 5, 10)
@@ -231,8 +276,8 @@ func TestRPSMCTSNodeExpandAll(t *testing.T) {
 		}
 
 		// Each child should have 0 visits
-		if child.Visits != 0 {
-			t.Errorf("Expected child to have 0 visits, got %d", child.Visits)
+		if child.Visits.Load() != 0 {
+			t.Errorf("Expected child to have 0 visits, got %d", child.Visits.Load())
 		}
 
 		// Each child should have the priors we provided
@@ -249,8 +294,8 @@ func TestRPSMCTSNodeUpdate(t *testing.T) {
 	node := NewRPSMCTSNode(gameState, nil, nil, nil)
 
 	// Initial state
-	if node.Visits != 0 {
-		t.Errorf("Expected initial visits to be 0, got %d", node.Visits)
+	if node.Visits.Load() != 0 {
+		t.Errorf("Expected initial visits to be 0, got %d", node.Visits.Load())
 	}
 	if node.TotalValue != 0.0 {
 		t.Errorf("Expected initial total value to be 0.0, got %f", node.TotalValue)
@@ -258,8 +303,8 @@ func TestRPSMCTSNodeUpdate(t *testing.T) {
 
 	// Update once
 	node.Update(0.5)
-	if node.Visits != 1 {
-		t.Errorf("After one update, expected visits to be 1, got %d", node.Visits)
+	if node.Visits.Load() != 1 {
+		t.Errorf("After one update, expected visits to be 1, got %d", node.Visits.Load())
 	}
 	if node.TotalValue != 0.5 {
 		t.Errorf("After one update, expected total value to be 0.5, got %f", node.TotalValue)
@@ -267,8 +312,8 @@ func TestRPSMCTSNodeUpdate(t *testing.T) {
 
 	// Update again
 	node.Update(0.8)
-	if node.Visits != 2 {
-		t.Errorf("After two updates, expected visits to be 2, got %d", node.Visits)
+	if node.Visits.Load() != 2 {
+		t.Errorf("After two updates, expected visits to be 2, got %d", node.Visits.Load())
 	}
 	if node.TotalValue != 1.3 {
 		t.Errorf("After two updates, expected total value to be 1.3, got %f", node.TotalValue)
@@ -300,24 +345,24 @@ func TestRPSMCTSNodeUpdateRecursive(t *testing.T) {
 	child2.UpdateRecursive(1.0)
 
 	// Check that child2 was updated
-	if child2.Visits != 1 {
-		t.Errorf("Expected child2 visits to be 1, got %d", child2.Visits)
+	if child2.Visits.Load() != 1 {
+		t.Errorf("Expected child2 visits to be 1, got %d", child2.Visits.Load())
 	}
 	if child2.TotalValue != 1.0 {
 		t.Errorf("Expected child2 total value to be 1.0, got %f", child2.TotalValue)
 	}
 
 	// Check that child1 was updated with the opposite value
-	if child1.Visits != 1 {
-		t.Errorf("Expected child1 visits to be 1, got %d", child1.Visits)
+	if child1.Visits.Load() != 1 {
+		t.Errorf("Expected child1 visits to be 1, got %d", child1.Visits.Load())
 	}
 	if child1.TotalValue != 0.0 { // 1.0 - 1.0 = 0.0
 		t.Errorf("Expected child1 total value to be 0.0, got %f", child1.TotalValue)
 	}
 
 	// Check that root was updated with the original value
-	if root.Visits != 1 {
-		t.Errorf("Expected root visits to be 1, got %d", root.Visits)
+	if root.Visits.Load() != 1 {
+		t.Errorf("Expected root visits to be 1, got %d", root.Visits.Load())
 	}
 	if root.TotalValue != 1.0 { // 1.0 - 0.0 = 1.0
 		t.Errorf("Expected root total value to be 1.0, got %f", root.TotalValue)
@@ -338,7 +383,7 @@ func TestRPSMCTSNodeMostVisitedChild(t *testing.T) {
 		childNode := NewRPSMCTSNode(childState, &move, root, nil)
 
 		// Set different visits for each child
-		childNode.Visits = i * 5
+		childNode.Visits.Store(int64(i * 5))
 
 		root.Children = append(root.Children, childNode)
 	}
@@ -349,9 +394,9 @@ func TestRPSMCTSNodeMostVisitedChild(t *testing.T) {
 		t.Errorf("Expected most visited child to have position 2, got %d",
 			bestChild.Move.Position)
 	}
-	if bestChild.Visits != 10 {
+	if bestChild.Visits.Load() != 10 {
 		t.Errorf("Expected most visited child to have 10 visits, got %d",
-			bestChild.Visits)
+			bestChild.Visits.Load())
 	}
 }
 
@@ -369,7 +414,7 @@ func TestRPSMCTSNodeBestChild(t *testing.T) {
 		childNode := NewRPSMCTSNode(childState, &move, root, nil)
 
 		// Set different values and visits for each child
-		childNode.Visits = 10
+		childNode.Visits.Store(10)
 
 		switch i {
 		case 0:
@@ -390,7 +435,7 @@ func TestRPSMCTSNodeBestChild(t *testing.T) {
 			bestChild.Move.Position)
 	}
 
-	value := bestChild.TotalValue / float64(bestChild.Visits)
+	value := bestChild.TotalValue / float64(bestChild.Visits.Load())
 	if value != 0.8 {
 		t.Errorf("Expected best child to have value 0.8, got %f", value)
 	}
@@ -0,0 +1,187 @@
+package mcts
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
+)
+
+// CandidateMove summarises what the search learned about one move
+type CandidateMove struct {
+	Move     game.RPSMove
+	CardType game.RPSCardType // Type of the card played, or -1 if unknown
+	Visits   int
+	Share    float64 // Fraction of the parent's child visits spent on the move
+	Prior    float64 // Policy prior for the move's square
+	Value    float64 // Mean value for the player making the move, 0 (loss) to 1 (win)
+}
+
+// SearchReport describes the most recent search from its root position
+type SearchReport struct {
+	Simulations int
+
+	// Every root move the search expanded, most visited first
+	Candidates []CandidateMove
+
+	// The line the search expects: the most visited move at the root, then the
+	// most visited reply to it, and so on while the replies have visits
+	PrincipalVariation []CandidateMove
+
+	boardDim int
+}
+
+// LastSearchReport returns the candidate moves and principal variation of the
+// tree built by the last search. The report is empty before any search.
+func (mcts *RPSMCTS) LastSearchReport() SearchReport {
+	root := mcts.Root
+	if root == nil {
+		return SearchReport{}
+	}
+
+	report := SearchReport{
+		Simulations: int(root.Visits.Load()),
+		Candidates:  root.candidates(),
+		boardDim:    root.GameState.BoardDim(),
+	}
+	sort.SliceStable(report.Candidates, func(i, j int) bool {
+		return report.Candidates[i].Visits > report.Candidates[j].Visits
+	})
+
+	// Follow the most visited child, as Search does when choosing the move.
+	// The visited set stops the walk should transpositions ever form a cycle.
+	visited := map[*RPSMCTSNode]bool{root: true}
+	for node := root; len(node.Children) > 0; {
+		child := node.MostVisitedChild()
+		if child.Visits.Load() == 0 || visited[child] {
+			break
+		}
+		visited[child] = true
+
+		for i, c := range node.Children {
+			if c == child {
+				report.PrincipalVariation = append(report.PrincipalVariation, node.candidate(i, node.childVisits()))
+				break
+			}
+		}
+		node = child
+	}
+
+	return report
+}
+
+// candidates describes every child of the node, in child order
+func (n *RPSMCTSNode) candidates() []CandidateMove {
+	total := n.childVisits()
+	candidates := make([]CandidateMove, len(n.Children))
+	for i := range n.Children {
+		candidates[i] = n.candidate(i, total)
+	}
+	return candidates
+}
+
+// candidate describes the move to the i-th child, given the visits of all children
+func (n *RPSMCTSNode) candidate(i int, totalVisits int64) CandidateMove {
+	child := n.Children[i]
+
+	var move game.RPSMove
+	if i < len(n.childMoves) {
+		move = n.childMoves[i]
+	} else if child.Move != nil {
+		move = *child.Move
+	}
+
+	candidate := CandidateMove{
+		Move:   move,
+		Visits: int(child.Visits.Load()),
+		Prior:  n.childPrior(i),
+		Value:  0.5,
+	}
+	hand := n.GameState.Player1Hand
+	if n.GameState.CurrentPlayer == game.Player2 {
+		hand = n.GameState.Player2Hand
+	}
+	candidate.CardType = cardTypeAt(hand, move.CardIndex)
+	if candidate.Visits > 0 {
+		candidate.Value = child.TotalValue / float64(candidate.Visits)
+	}
+	if totalVisits > 0 {
+		candidate.Share = float64(candidate.Visits) / float64(totalVisits)
+	}
+	return candidate
+}
+
+// childVisits returns the total visits of the node's children
+func (n *RPSMCTSNode) childVisits() int64 {
+	var total int64
+	for _, child := range n.Children {
+		total += child.Visits.Load()
+	}
+	return total
+}
+
+// cardTypeAt returns the type of the card at index in hand, or -1 if there is none
+func cardTypeAt(hand []game.RPSCard, index int) game.RPSCardType {
+	if index < 0 || index >= len(hand) {
+		return -1
+	}
+	return hand[index].Type
+}
+
+// Summary explains the most visited move in one sentence; see Explain
+func (r SearchReport) Summary() string {
+	if len(r.PrincipalVariation) == 0 {
+		return "no search results"
+	}
+	return r.Explain(r.PrincipalVariation[0].Move)
+}
+
+// TopMoves returns the n most visited candidates, or all of them if there
+// are fewer
+func (r SearchReport) TopMoves(n int) []CandidateMove {
+	if n < len(r.Candidates) {
+		return r.Candidates[:n]
+	}
+	return r.Candidates
+}
+
+// Explain describes the search's view of a root move in one sentence, for
+// example "played Rock at (1,1): 62% of visits, value +0.30, expecting Paper
+// at (0,2)". Values are shown from -1 (certain loss) to +1 (certain win) for
+// the mover, and the expected reply is given for the most visited move only.
+func (r SearchReport) Explain(move game.RPSMove) string {
+	for _, c := range r.Candidates {
+		if c.Move.CardIndex != move.CardIndex || c.Move.Position != move.Position {
+			continue
+		}
+
+		explanation := fmt.Sprintf("played %s: %.0f%% of visits, value %+.2f",
+			r.describe(c), c.Share*100, 2*c.Value-1)
+		if len(r.PrincipalVariation) > 1 && r.PrincipalVariation[0].Move == c.Move {
+			explanation += ", expecting " + r.describe(r.PrincipalVariation[1])
+		}
+		return explanation
+	}
+	return "played a move the search did not consider"
+}
+
+// describe names a move as its card type and (row,col) square
+func (r SearchReport) describe(c CandidateMove) string {
+	dim := r.boardDim
+	if dim == 0 {
+		dim = game.StandardBoardDim
+	}
+
+	var name string
+	switch c.CardType {
+	case game.Rock:
+		name = "Rock"
+	case game.Paper:
+		name = "Paper"
+	case game.Scissors:
+		name = "Scissors"
+	default:
+		name = fmt.Sprintf("card %d", c.Move.CardIndex)
+	}
+	return fmt.Sprintf("%s at (%d,%d)", name, c.Move.Position/dim, c.Move.Position%dim)
+}
@@ -1,6 +1,8 @@
 package mcts
 
 import (
+	"math"
+	"math/rand"
 	"runtime"
 	"sync"
 
@@ -15,6 +17,34 @@ type RPSMCTSParams struct {
 	DirichletNoise   bool
 	DirichletWeight  float64
 	DirichletAlpha   float64
+
+	// First-play urgency: with UseFPU set, an unvisited child is valued at its
+	// parent's value minus FPU rather than being tried before any other child
+	// is revisited (see RPSMCTSNode.UCB for the full PUCT formula)
+	UseFPU bool
+	FPU    float64
+
+	// Progressive widening limits a node with N visits to floor(C * N^Alpha)
+	// children, adding moves in order of decreasing prior as N grows. This keeps
+	// the search focused when a position has many legal moves. A C of zero
+	// disables widening and every legal move is expanded at once.
+	ProgressiveWideningC     float64
+	ProgressiveWideningAlpha float64
+
+	// RolloutDepth caps the random moves played by a rollout when the search
+	// has no value network; a rollout cut short is scored by who leads on the
+	// board. Zero plays every rollout to the end of the game.
+	RolloutDepth int
+
+	// UseTranspositions shares one node between all move orders reaching the
+	// same position, so its statistics are gathered once instead of per path
+	UseTranspositions bool
+
+	// Rng, when set, is the search's only source of randomness and the search
+	// runs serially, so the same networks, position and seed always build the
+	// same tree. Parallel search and the global random source are used when it
+	// is nil; thread scheduling then makes results vary from run to run.
+	Rng *rand.Rand
 }
 
 // DefaultRPSMCTSParams returns default MCTS parameters
@@ -25,15 +55,26 @@ func DefaultRPSMCTSParams() RPSMCTSParams {
 		DirichletNoise:   true,
 		DirichletWeight:  0.25,
 		DirichletAlpha:   0.03,
+		UseFPU:           false,
+		FPU:              0.25,
 	}
 }
 
-// RPSMCTS implements the Monte Carlo Tree Search algorithm for RPS
+// RPSMCTS implements the Monte Carlo Tree Search algorithm for RPS. Without a
+// policy network every move gets the same prior, and without a value network
+// leaves are scored by random rollouts.
 type RPSMCTS struct {
 	PolicyNetwork *neural.RPSPolicyNetwork
 	ValueNetwork  *neural.RPSValueNetwork
 	Params        RPSMCTSParams
 	Root          *RPSMCTSNode
+
+	// Evaluator, when set, scores leaves that are not game over in place of
+	// ValueNetwork
+	Evaluator LeafEvaluator
+
+	// Positions in the current search when UseTranspositions is set
+	table *transpositionTable
 }
 
 // NewRPSMCTS creates a new MCTS instance
@@ -46,20 +87,82 @@ func NewRPSMCTS(policyNetwork *neural.RPSPolicyNetwork, valueNetwork *neural.RPS
 	}
 }
 
+// NewRolloutMCTS creates a classic MCTS that uses no networks: moves are
+// explored with uniform priors and leaves are scored by random playouts
+func NewRolloutMCTS(params RPSMCTSParams) *RPSMCTS {
+	return NewRPSMCTS(nil, nil, params)
+}
+
 // SetRootState sets the root state of the search tree
 func (mcts *RPSMCTS) SetRootState(state *game.RPSGame) {
 	// Get policy priors from the neural network
-	priors := mcts.PolicyNetwork.Predict(state)
+	priors := mcts.priors(state)
 
 	// Create a new root node
 	mcts.Root = NewRPSMCTSNode(state.Copy(), nil, nil, priors)
+	mcts.table = nil
+}
+
+// priors returns the policy network's move priors over the legal squares, or
+// nil for uniform priors when the search has no policy network
+func (mcts *RPSMCTS) priors(state *game.RPSGame) []float64 {
+	if mcts.PolicyNetwork == nil {
+		return nil
+	}
+	return mcts.PolicyNetwork.PredictMasked(state)
+}
+
+// prepareTranspositions starts a transposition table for the current root when
+// transpositions are enabled and the root has changed since the last search
+func (mcts *RPSMCTS) prepareTranspositions() {
+	if !mcts.Params.UseTranspositions {
+		mcts.table = nil
+		return
+	}
+	if mcts.table == nil || mcts.table.root != mcts.Root {
+		mcts.table = newTranspositionTable(mcts.Root)
+	}
+}
+
+// expand creates the children of a leaf node, all at once or progressively
+// depending on the search parameters
+func (mcts *RPSMCTS) expand(node *RPSMCTSNode, priors []float64) {
+	if mcts.Params.ProgressiveWideningC > 0 {
+		node.expandProgressive(priors, mcts.table)
+		return
+	}
+	node.expandAll(priors, mcts.table)
+}
+
+// widen adds children to a progressively widened node until it has as many as
+// its visit count allows; the node always keeps at least one child
+func (mcts *RPSMCTS) widen(node *RPSMCTSNode) {
+	if mcts.Params.ProgressiveWideningC <= 0 || !node.CanWiden() {
+		return
+	}
+
+	visits := float64(node.Visits.Load())
+	limit := int(math.Floor(mcts.Params.ProgressiveWideningC * math.Pow(visits, mcts.Params.ProgressiveWideningAlpha)))
+	if limit < 1 {
+		limit = 1
+	}
+	node.widen(limit, mcts.table)
+}
+
+// selectChild picks the child to descend into, applying first-play urgency if enabled
+func (mcts *RPSMCTS) selectChild(node *RPSMCTSNode) *RPSMCTSNode {
+	if mcts.Params.UseFPU {
+		return node.SelectChildFPU(mcts.Params.ExplorationConst, mcts.Params.FPU)
+	}
+	return node.SelectChild(mcts.Params.ExplorationConst)
 }
 
 // Search performs the MCTS algorithm and returns the best move
 func (mcts *RPSMCTS) Search() *RPSMCTSNode {
 	// Check if we should use parallel search
-	// Use parallel search for large simulation counts on multi-core systems
-	if mcts.Params.NumSimulations > 100 && runtime.NumCPU() > 2 {
+	// Use parallel search for large simulation counts on multi-core systems,
+	// unless the search has to be reproducible
+	if mcts.Params.Rng == nil && mcts.Params.NumSimulations > 100 && runtime.NumCPU() > 2 {
 		return mcts.searchParallel()
 	}
 
@@ -73,33 +176,39 @@ func (mcts *RPSMCTS) searchSerial() *RPSMCTSNode {
 		return nil
 	}
 
+	mcts.prepareTranspositions()
+
 	// Expand the root node if needed
 	if len(mcts.Root.Children) == 0 {
-		priors := mcts.PolicyNetwork.Predict(mcts.Root.GameState)
-		mcts.Root.ExpandAll(priors)
+		priors := mcts.priors(mcts.Root.GameState)
+		mcts.expand(mcts.Root, priors)
 	}
 
 	// Run simulations
 	for i := 0; i < mcts.Params.NumSimulations; i++ {
 		// Selection phase
-		node := mcts.selection(mcts.Root)
+		path := mcts.selectPath(mcts.Root)
+		node := path[len(path)-1]
 
 		// Expansion phase (if needed)
-		if !node.GameState.IsGameOver() && node.Visits.Load() > 0 {
-			priors := mcts.PolicyNetwork.Predict(node.GameState)
-			node.ExpandAll(priors)
+		if !node.GameState.IsGameOver() && node.Visits.Load() > 0 && len(node.Children) == 0 {
+			priors := mcts.priors(node.GameState)
+			mcts.expand(node, priors)
 
 			// If expansion created children, select one of them
 			if len(node.Children) > 0 {
 				node = node.Children[0] // Select first child for simplicity
+				path = append(path, node)
 			}
 		}
 
 		// Evaluation phase
 		value := mcts.evaluate(node)
 
-		// Backpropagation phase
-		node.UpdateRecursive(value)
+		// Backpropagation phase. The evaluation is for the player to move at the
+		// leaf, but nodes keep their statistics for the player who moved into
+		// them, since that is the player choosing them during selection.
+		backup(path, 1.0-value)
 	}
 
 	// Return the most visited child of the root
@@ -112,10 +221,12 @@ func (mcts *RPSMCTS) searchParallel() *RPSMCTSNode {
 		return nil
 	}
 
+	mcts.prepareTranspositions()
+
 	// Expand the root node if needed (this needs to be done before parallelization)
 	if len(mcts.Root.Children) == 0 {
-		priors := mcts.PolicyNetwork.Predict(mcts.Root.GameState)
-		mcts.Root.ExpandAll(priors)
+		priors := mcts.priors(mcts.Root.GameState)
+		mcts.expand(mcts.Root, priors)
 	}
 
 	// Determine optimal worker count
@@ -150,10 +261,19 @@ func (mcts *RPSMCTS) searchParallel() *RPSMCTSNode {
 
 			// Each worker performs its share of simulations
 			for j := 0; j < simCount; j++ {
-				// Selection phase (with read lock)
-				treeMutex.RLock()
-				node := mcts.selectionThreadSafe(mcts.Root)
-				treeMutex.RUnlock()
+				// Selection phase (with read lock, or a write lock when
+				// progressive widening may add children on the way down)
+				var path []*RPSMCTSNode
+				if mcts.Params.ProgressiveWideningC > 0 {
+					treeMutex.Lock()
+					path = mcts.selectPath(mcts.Root)
+					treeMutex.Unlock()
+				} else {
+					treeMutex.RLock()
+					path = mcts.selectPath(mcts.Root)
+					treeMutex.RUnlock()
+				}
+				node := path[len(path)-1]
 
 				// Local copy of the selected node's game state to avoid locks during evaluation
 				localState := node.GameState.Copy()
@@ -164,18 +284,21 @@ func (mcts *RPSMCTS) searchParallel() *RPSMCTSNode {
 				// Expansion phase (with write lock, only if needed)
 				if needsExpansion {
 					// Get policy network prediction outside the lock
-					priors := mcts.PolicyNetwork.Predict(localState)
+					priors := mcts.priors(localState)
 
 					// Take write lock for expansion
 					treeMutex.Lock()
 
 					// Double-check that expansion is still needed (another thread might have expanded)
 					if !node.GameState.IsGameOver() && node.Visits.Load() > 0 && len(node.Children) == 0 {
-						node.ExpandAll(priors)
+						mcts.expand(node, priors)
 
 						// If expansion created children, select one of them
+						// and evaluate its position instead
 						if len(node.Children) > 0 {
 							node = node.Children[0]
+							path = append(path, node)
+							localState = node.GameState.Copy()
 						}
 					}
 
@@ -186,9 +309,9 @@ func (mcts *RPSMCTS) searchParallel() *RPSMCTSNode {
 				// Use the local copy of the game state
 				value := mcts.evaluateState(localState)
 
-				// Backpropagation phase (with write lock)
+				// Backpropagation phase (with write lock), flipped as in searchSerial
 				treeMutex.Lock()
-				mcts.backpropagateThreadSafe(node, value)
+				backup(path, 1.0-value)
 				treeMutex.Unlock()
 			}
 		}(workerSims)
@@ -201,31 +324,34 @@ func (mcts *RPSMCTS) searchParallel() *RPSMCTSNode {
 	return mcts.Root.MostVisitedChild()
 }
 
-// selectionThreadSafe is a thread-safe version of selection
-// Caller must hold at least a read lock
-func (mcts *RPSMCTS) selectionThreadSafe(node *RPSMCTSNode) *RPSMCTSNode {
+// selectPath descends from node to a leaf, an unvisited node or a terminal
+// state, and returns every node on the way, node first. Parallel callers must
+// hold at least a read lock, or a write lock when progressive widening is enabled.
+func (mcts *RPSMCTS) selectPath(node *RPSMCTSNode) []*RPSMCTSNode {
+	path := []*RPSMCTSNode{node}
+
 	// Keep traversing until we reach a leaf node or a terminal state
 	for len(node.Children) > 0 && !node.GameState.IsGameOver() {
-		node = node.SelectChild(mcts.Params.ExplorationConst)
+		mcts.widen(node)
+		node = mcts.selectChild(node)
+		path = append(path, node)
 		if node.Visits.Load() == 0 {
 			// Found an unvisited node, return it
-			return node
+			break
 		}
 	}
 
-	return node
+	return path
 }
 
-// backpropagateThreadSafe is a thread-safe version of backpropagation
-// Caller must hold a write lock
-func (mcts *RPSMCTS) backpropagateThreadSafe(node *RPSMCTSNode, value float64) {
-	// Update this node
-	node.Update(value)
-
-	// Update parent recursively
-	if node.Parent != nil {
-		// Flip value perspective for parent (from opponent's point of view)
-		mcts.backpropagateThreadSafe(node.Parent, 1.0-value)
+// backup adds a simulation's value to every node on its path, leaf last,
+// flipping the perspective at each step. It follows the path instead of Parent
+// pointers because, with transpositions, a node can have several parents.
+// Callers searching in parallel must hold a write lock.
+func backup(path []*RPSMCTSNode, value float64) {
+	for i := len(path) - 1; i >= 0; i-- {
+		path[i].Update(value)
+		value = 1.0 - value
 	}
 }
 
@@ -234,52 +360,77 @@ func (mcts *RPSMCTS) backpropagateThreadSafe(node *RPSMCTSNode, value float64) {
 func (mcts *RPSMCTS) evaluateState(state *game.RPSGame) float64 {
 	// If game is over, return actual outcome
 	if state.IsGameOver() {
-		winner := state.GetWinner()
-
-		if winner == game.NoPlayer {
-			return 0.5 // Draw
-		} else if winner == state.CurrentPlayer {
-			return 1.0 // Win for current player
-		} else {
-			return 0.0 // Loss for current player
-		}
+		return resultFor(state, state.CurrentPlayer)
+	}
+
+	if mcts.Evaluator != nil {
+		return mcts.Evaluator.Evaluate(state)
+	}
+
+	// Without a value network, play the position out at random
+	if mcts.ValueNetwork == nil {
+		return mcts.rollout(state)
 	}
 
 	// Otherwise, use value network for position evaluation
 	return mcts.ValueNetwork.Predict(state)
 }
 
-// selection traverses the tree to find a node to expand
-func (mcts *RPSMCTS) selection(node *RPSMCTSNode) *RPSMCTSNode {
-	// Keep traversing until we reach a leaf node or a terminal state
-	for len(node.Children) > 0 && !node.GameState.IsGameOver() {
-		node = node.SelectChild(mcts.Params.ExplorationConst)
-		if node.Visits.Load() == 0 {
-			// Found an unvisited node, return it
-			return node
+// rollout plays uniformly random moves on a copy of state until the game ends
+// or RolloutDepth moves have been played, and scores the resulting board for
+// the player to move in state
+func (mcts *RPSMCTS) rollout(state *game.RPSGame) float64 {
+	player := state.CurrentPlayer
+	sim := state.Copy()
+
+	for depth := 0; !sim.IsGameOver(); depth++ {
+		if mcts.Params.RolloutDepth > 0 && depth >= mcts.Params.RolloutDepth {
+			break
+		}
+		moves := sim.GetValidMoves()
+		if len(moves) == 0 {
+			break
+		}
+		if err := sim.MakeMove(moves[mcts.intn(len(moves))]); err != nil {
+			break
 		}
 	}
 
-	return node
+	return resultFor(sim, player)
 }
 
-// evaluate estimates the value of a node
-func (mcts *RPSMCTS) evaluate(node *RPSMCTSNode) float64 {
-	// If game is over, return actual outcome
-	if node.GameState.IsGameOver() {
-		winner := node.GameState.GetWinner()
-
-		if winner == game.NoPlayer {
-			return 0.5 // Draw
-		} else if winner == node.GameState.CurrentPlayer {
-			return 1.0 // Win for current player
-		} else {
-			return 0.0 // Loss for current player
-		}
+// resultFor scores the board for player: 1.0 if they lead, 0.5 for a tie and
+// 0.0 if they trail. At the end of the game this is the game's result.
+func resultFor(state *game.RPSGame, player game.RPSPlayer) float64 {
+	winner := state.GetWinner()
+
+	if winner == game.NoPlayer {
+		return 0.5 // Draw
+	} else if winner == player {
+		return 1.0 // Win for player
+	} else {
+		return 0.0 // Loss for player
 	}
+}
 
-	// Otherwise, use value network for position evaluation
-	return mcts.ValueNetwork.Predict(node.GameState)
+// intn returns a random number in [0, n) from Params.Rng, or from the global
+// source when no Rng is set
+func (mcts *RPSMCTS) intn(n int) int {
+	if mcts.Params.Rng != nil {
+		return mcts.Params.Rng.Intn(n)
+	}
+	return rand.Intn(n)
+}
+
+// selection traverses the tree to find a node to expand
+func (mcts *RPSMCTS) selection(node *RPSMCTSNode) *RPSMCTSNode {
+	path := mcts.selectPath(node)
+	return path[len(path)-1]
+}
+
+// evaluate estimates the value of a node for the player to move there
+func (mcts *RPSMCTS) evaluate(node *RPSMCTSNode) float64 {
+	return mcts.evaluateState(node.GameState)
 }
 
 // GetBestMove returns the best move according to MCTS
@@ -290,3 +441,65 @@ func (mcts *RPSMCTS) GetBestMove() *game.RPSMove {
 	}
 	return bestNode.Move
 }
+
+// GetNodesEvaluated returns the number of simulations that reached the root
+// during the most recent search
+func (mcts *RPSMCTS) GetNodesEvaluated() int {
+	if mcts.Root == nil {
+		return 0
+	}
+	return int(mcts.Root.Visits.Load())
+}
+
+// GetRootValue returns the estimated value of the root position for the player
+// to move there, from 0 (certain loss) to 1 (certain win). It is the search's
+// average once the root has 10 visits, and a direct evaluation before that.
+func (mcts *RPSMCTS) GetRootValue() float64 {
+	if mcts.Root == nil {
+		return 0.5
+	}
+
+	visits := mcts.Root.Visits.Load()
+	if visits < 10 {
+		return mcts.evaluateState(mcts.Root.GameState)
+	}
+
+	// The root's own statistics are for the player who moved into it
+	return 1.0 - mcts.Root.TotalValue/float64(visits)
+}
+
+// GetTranspositionStats returns how often expanding a node found its position
+// already in the current search; all zero when transpositions are disabled
+func (mcts *RPSMCTS) GetTranspositionStats() (hits int, misses int, hitRate float64) {
+	if mcts.table == nil {
+		return 0, 0, 0.0
+	}
+
+	hits, misses = mcts.table.hits, mcts.table.misses
+	if total := hits + misses; total > 0 {
+		hitRate = float64(hits) / float64(total) * 100.0
+	}
+	return hits, misses, hitRate
+}
+
+// NodeCount returns the number of distinct nodes in the current search tree,
+// counting a node shared by several parents once
+func (mcts *RPSMCTS) NodeCount() int {
+	if mcts.Root == nil {
+		return 0
+	}
+
+	seen := map[*RPSMCTSNode]bool{mcts.Root: true}
+	stack := []*RPSMCTSNode{mcts.Root}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, child := range node.Children {
+			if !seen[child] {
+				seen[child] = true
+				stack = append(stack, child)
+			}
+		}
+	}
+	return len(seen)
+}
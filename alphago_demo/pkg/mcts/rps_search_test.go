@@ -1,6 +1,9 @@
 package mcts
 
 import (
+	"math"
+	"math/rand"
+	"strings"
 	"testing"
 
 	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
@@ -141,7 +144,7 @@ func TestRPSMCTSSearch(t *testing.T) {
 	}
 
 	// The node should have been visited at least once
-	if bestNode.Visits == 0 {
+	if bestNode.Visits.Load() == 0 {
 		t.Errorf("Expected best node to have been visited at least once")
 	}
 
@@ -151,9 +154,174 @@ func TestRPSMCTSSearch(t *testing.T) {
 	}
 
 	// The root should have been visited at least numSimulations times
-	if mctsEngine.Root.Visits < params.NumSimulations {
+	if mctsEngine.Root.Visits.Load() < int64(params.NumSimulations) {
 		t.Errorf("Expected root to have at least %d visits, got %d",
-			params.NumSimulations, mctsEngine.Root.Visits)
+			params.NumSimulations, mctsEngine.Root.Visits.Load())
+	}
+}
+
+func TestRPSMCTSSearchFindsWinningCapture(t *testing.T) {
+	// Player 1 holds the last card, a paper. Playing it on square 3 captures
+	// both of player 2's rocks and wins 3-2; every other square loses.
+	//
+	//	R . S
+	//	. . .
+	//	R . S
+	gameState := game.NewRPSGame(15, 5, 10)
+	for i := range gameState.Board {
+		gameState.Board[i] = game.RPSCard{}
+	}
+	gameState.Board[0] = game.RPSCard{Type: game.Rock, Owner: game.Player2}
+	gameState.Board[6] = game.RPSCard{Type: game.Rock, Owner: game.Player2}
+	gameState.Board[2] = game.RPSCard{Type: game.Scissors, Owner: game.Player2}
+	gameState.Board[8] = game.RPSCard{Type: game.Scissors, Owner: game.Player2}
+	gameState.SetPlayer1Hand([]int{int(game.Paper)})
+	gameState.SetPlayer2Hand(nil)
+	gameState.CurrentPlayer = game.Player1
+
+	// Both the serial and the parallel search
+	for _, simulations := range []int{50, 400} {
+		params := DefaultRPSMCTSParams()
+		params.NumSimulations = simulations
+		params.DirichletNoise = false
+		mctsEngine := NewRPSMCTS(neural.NewRPSPolicyNetwork(16), neural.NewRPSValueNetwork(16), params)
+		mctsEngine.SetRootState(gameState)
+
+		move := mctsEngine.GetBestMove()
+		if move == nil || move.Position != 3 {
+			t.Errorf("%d simulations: expected the winning capture on square 3, got %+v", simulations, move)
+		}
+	}
+}
+
+func TestRPSMCTSProgressiveWidening(t *testing.T) {
+	policyNetwork := neural.NewRPSPolicyNetwork(32)
+	valueNetwork := neural.NewRPSValueNetwork(32)
+
+	params := DefaultRPSMCTSParams()
+	params.NumSimulations = 50 // Serial search
+	params.ProgressiveWideningC = 1.0
+	params.ProgressiveWideningAlpha = 0.5
+	mctsEngine := NewRPSMCTS(policyNetwork, valueNetwork, params)
+
+	gameState := game.NewRPSGame(15, 5, 10)
+	mctsEngine.SetRootState(gameState)
+	if mctsEngine.Search() == nil {
+		t.Fatal("Expected Search to return a non-nil node")
+	}
+
+	// 50 visits allow floor(sqrt(50)) = 7 of the 45 opening moves
+	root := mctsEngine.Root
+	if len(root.Children) != 7 {
+		t.Errorf("Expected 7 children under progressive widening, got %d", len(root.Children))
+	}
+	if !root.CanWiden() {
+		t.Error("Expected the root to still have moves left to add")
+	}
+
+	// Children are added highest prior first
+	for i := 1; i < len(root.Children); i++ {
+		prev := root.Priors[root.Children[i-1].Move.Position]
+		curr := root.Priors[root.Children[i].Move.Position]
+		if curr > prev {
+			t.Errorf("Child %d has prior %f, higher than the previous child's %f", i, curr, prev)
+		}
+	}
+
+	// Without widening every legal move is a child
+	params.ProgressiveWideningC = 0
+	mctsEngine = NewRPSMCTS(policyNetwork, valueNetwork, params)
+	mctsEngine.SetRootState(gameState)
+	mctsEngine.Search()
+	if want := len(gameState.GetValidMoves()); len(mctsEngine.Root.Children) != want {
+		t.Errorf("Expected %d children without widening, got %d", want, len(mctsEngine.Root.Children))
+	}
+}
+
+func TestRPSMCTSSeededSearchIsDeterministic(t *testing.T) {
+	policyNetwork := neural.NewRPSPolicyNetwork(32)
+	valueNetwork := neural.NewRPSValueNetwork(32)
+	gameState := game.NewRPSGameSeeded(15, 5, 10, rand.New(rand.NewSource(7)))
+
+	// Enough simulations that an unseeded search would run in parallel
+	search := func() *RPSMCTS {
+		params := DefaultRPSMCTSParams()
+		params.NumSimulations = 300
+		params.Rng = rand.New(rand.NewSource(42))
+		engine := NewRPSMCTS(policyNetwork, valueNetwork, params)
+		engine.SetRootState(gameState)
+		engine.Search()
+		return engine
+	}
+
+	first, second := search(), search()
+
+	move1, move2 := first.Root.MostVisitedChild().Move, second.Root.MostVisitedChild().Move
+	if *move1 != *move2 {
+		t.Errorf("Expected identical best moves, got %+v and %+v", *move1, *move2)
+	}
+
+	if len(first.Root.Children) != len(second.Root.Children) {
+		t.Fatalf("Expected identical root children, got %d and %d",
+			len(first.Root.Children), len(second.Root.Children))
+	}
+	for i, child := range first.Root.Children {
+		other := second.Root.Children[i]
+		if *child.Move != *other.Move || child.Visits.Load() != other.Visits.Load() {
+			t.Errorf("Child %d differs: %+v with %d visits vs %+v with %d visits",
+				i, *child.Move, child.Visits.Load(), *other.Move, other.Visits.Load())
+		}
+	}
+}
+
+func TestRolloutMCTSBeatsRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	params := DefaultRPSMCTSParams()
+	params.NumSimulations = 400
+	params.Rng = rng
+	engine := NewRolloutMCTS(params)
+
+	const games = 30
+	wins, losses := 0, 0
+	for i := 0; i < games; i++ {
+		g := game.NewRPSGameSeeded(21, 5, 10, rng)
+		searcher := game.Player1
+		if i%2 == 1 {
+			searcher = game.Player2 // Alternate who moves first
+		}
+
+		for !g.IsGameOver() {
+			var move game.RPSMove
+			if g.CurrentPlayer == searcher {
+				engine.SetRootState(g)
+				best := engine.Search()
+				if best == nil || best.Move == nil {
+					t.Fatal("Rollout search returned no move")
+				}
+				move = *best.Move
+			} else {
+				moves := g.GetValidMoves()
+				move = moves[rng.Intn(len(moves))]
+			}
+			if err := g.MakeMove(move); err != nil {
+				t.Fatalf("MakeMove failed: %v", err)
+			}
+		}
+
+		switch g.GetWinner() {
+		case searcher:
+			wins++
+		case game.NoPlayer:
+		default:
+			losses++
+		}
+	}
+
+	t.Logf("Rollout MCTS vs Random: %d wins, %d losses, %d draws", wins, losses, games-wins-losses)
+	if wins < games*3/4 {
+		t.Errorf("Expected rollout MCTS to win at least %d of %d games against Random, won %d",
+			games*3/4, games, wins)
 	}
 }
 
@@ -185,7 +353,7 @@ func TestRPSMCTSSelection(t *testing.T) {
 	}
 
 	root := NewRPSMCTSNode(gameState, nil, nil, priors)
-	root.Visits = 10
+	root.Visits.Store(10)
 
 	// Create children
 	for i := 0; i < 3; i++ {
@@ -197,7 +365,7 @@ func TestRPSMCTSSelection(t *testing.T) {
 
 		// Make all but one node visited
 		if i < 2 {
-			childNode.Visits = 5
+			childNode.Visits.Store(5)
 		}
 
 		root.Children = append(root.Children, childNode)
@@ -205,9 +373,9 @@ func TestRPSMCTSSelection(t *testing.T) {
 
 	// Test selection on a non-leaf node with an unvisited child
 	selected = mctsEngine.selection(root)
-	if selected.Visits != 0 {
+	if selected.Visits.Load() != 0 {
 		t.Errorf("Expected selection to return the unvisited node, got node with %d visits",
-			selected.Visits)
+			selected.Visits.Load())
 	}
 }
 
@@ -317,3 +485,217 @@ func TestRPSMCTSGetBestMove(t *testing.T) {
 			len(gameState.Player1Hand)-1, bestMove.CardIndex)
 	}
 }
+
+// childAt follows the child reached by playing a card of the given type on pos,
+// expanding the node first if needed
+func childAt(t *testing.T, engine *RPSMCTS, node *RPSMCTSNode, cardType game.RPSCardType, pos int) *RPSMCTSNode {
+	t.Helper()
+
+	if len(node.Children) == 0 {
+		engine.expand(node, nil)
+	}
+
+	hand := node.GameState.Player1Hand
+	if node.GameState.CurrentPlayer == game.Player2 {
+		hand = node.GameState.Player2Hand
+	}
+	for i, move := range node.childMoves {
+		if move.Position == pos && hand[move.CardIndex].Type == cardType {
+			return node.Children[i]
+		}
+	}
+	t.Fatalf("No child plays card type %d on square %d", cardType, pos)
+	return nil
+}
+
+func TestRPSMCTSTranspositions(t *testing.T) {
+	state := game.NewRPSGame(15, 5, 10)
+	state.Player1Hand = []game.RPSCard{{Type: game.Rock}, {Type: game.Paper}, {Type: game.Scissors}}
+	state.Player2Hand = []game.RPSCard{{Type: game.Rock}, {Type: game.Paper}, {Type: game.Scissors}}
+
+	params := DefaultRPSMCTSParams()
+	params.NumSimulations = 400
+	params.UseTranspositions = true
+	params.Rng = rand.New(rand.NewSource(3))
+	engine := NewRolloutMCTS(params)
+	engine.SetRootState(state)
+	engine.prepareTranspositions()
+
+	// Corner, far corner, centre: no two squares touch, so playing player 1's
+	// cards in either order reaches the same position
+	first := childAt(t, engine, engine.Root, game.Rock, 0)
+	first = childAt(t, engine, first, game.Rock, 8)
+	first = childAt(t, engine, first, game.Paper, 4)
+
+	second := childAt(t, engine, engine.Root, game.Paper, 4)
+	second = childAt(t, engine, second, game.Rock, 8)
+	second = childAt(t, engine, second, game.Rock, 0)
+
+	if first != second {
+		t.Error("Expected both move orders to reach the same node")
+	}
+	if hits, _, _ := engine.GetTranspositionStats(); hits == 0 {
+		t.Error("Expected the second move order to hit the transposition table")
+	}
+
+	// A full search shares nodes too, so it needs fewer of them than a tree
+	search := func(useTranspositions bool) *RPSMCTS {
+		params := params
+		params.UseTranspositions = useTranspositions
+		params.Rng = rand.New(rand.NewSource(3))
+		engine := NewRolloutMCTS(params)
+		engine.SetRootState(state)
+		engine.Search()
+		return engine
+	}
+
+	graph, tree := search(true), search(false)
+	hits, misses, hitRate := graph.GetTranspositionStats()
+	t.Logf("Transpositions: %d hits, %d misses (%.1f%%); %d nodes vs %d without",
+		hits, misses, hitRate, graph.NodeCount(), tree.NodeCount())
+
+	if hits == 0 {
+		t.Error("Expected the search to find transpositions")
+	}
+	if graph.NodeCount() >= tree.NodeCount() {
+		t.Errorf("Expected fewer nodes with transpositions, got %d vs %d", graph.NodeCount(), tree.NodeCount())
+	}
+	if graph.Root.Visits.Load() != tree.Root.Visits.Load() {
+		t.Errorf("Expected the same number of simulations, got %d vs %d",
+			graph.Root.Visits.Load(), tree.Root.Visits.Load())
+	}
+}
+
+func TestRPSMCTSLastSearchReport(t *testing.T) {
+	engine := NewRPSMCTS(neural.NewRPSPolicyNetwork(32), neural.NewRPSValueNetwork(32), DefaultRPSMCTSParams())
+	if report := engine.LastSearchReport(); len(report.Candidates) != 0 || report.Summary() != "no search results" {
+		t.Errorf("Expected an empty report before searching, got %+v", report)
+	}
+
+	params := DefaultRPSMCTSParams()
+	params.NumSimulations = 200
+	params.Rng = rand.New(rand.NewSource(9))
+	engine.Params = params
+	engine.SetRootState(game.NewRPSGameSeeded(15, 5, 10, rand.New(rand.NewSource(4))))
+	best := engine.Search()
+
+	report := engine.LastSearchReport()
+	if report.Simulations != params.NumSimulations {
+		t.Errorf("Expected %d simulations, got %d", params.NumSimulations, report.Simulations)
+	}
+	if len(report.Candidates) != len(engine.Root.Children) {
+		t.Fatalf("Expected %d candidates, got %d", len(engine.Root.Children), len(report.Candidates))
+	}
+
+	share := 0.0
+	for i, candidate := range report.Candidates {
+		share += candidate.Share
+		if i > 0 && candidate.Visits > report.Candidates[i-1].Visits {
+			t.Errorf("Candidates out of order: %d visits after %d", candidate.Visits, report.Candidates[i-1].Visits)
+		}
+		if candidate.Value < 0 || candidate.Value > 1 {
+			t.Errorf("Candidate value %v outside [0, 1]", candidate.Value)
+		}
+	}
+	if math.Abs(share-1) > 1e-9 {
+		t.Errorf("Expected visit shares to sum to 1, got %v", share)
+	}
+	if top := report.TopMoves(3); len(top) != 3 || top[0] != report.Candidates[0] {
+		t.Errorf("Expected the three most visited candidates, got %+v", top)
+	}
+
+	// The principal variation starts with the move the search chose and
+	// alternates between the players
+	if len(report.PrincipalVariation) < 2 {
+		t.Fatalf("Expected at least two moves of principal variation, got %d", len(report.PrincipalVariation))
+	}
+	if report.PrincipalVariation[0].Move != *best.Move {
+		t.Errorf("Principal variation starts with %+v, search chose %+v", report.PrincipalVariation[0].Move, *best.Move)
+	}
+	if report.PrincipalVariation[0].Move.Player == report.PrincipalVariation[1].Move.Player {
+		t.Errorf("Expected the reply to be by the other player, got %+v", report.PrincipalVariation[:2])
+	}
+
+	if summary := report.Summary(); !strings.HasPrefix(summary, "played ") || !strings.Contains(summary, "expecting") {
+		t.Errorf("Unexpected summary %q", summary)
+	}
+}
+
+func TestRPSMCTSSampleMove(t *testing.T) {
+	params := DefaultRPSMCTSParams()
+	params.NumSimulations = 200
+	params.Rng = rand.New(rand.NewSource(6))
+	engine := NewRPSMCTS(neural.NewRPSPolicyNetwork(32), neural.NewRPSValueNetwork(32), params)
+
+	if engine.SampleMove(1.0) != nil {
+		t.Error("Expected no move before searching")
+	}
+
+	engine.SetRootState(game.NewRPSGameSeeded(15, 5, 10, rand.New(rand.NewSource(8))))
+	best := engine.Search()
+
+	if engine.SampleMove(0) != best {
+		t.Error("Expected zero temperature to pick the most visited child")
+	}
+
+	// A high temperature spreads choices over several children, all of them visited
+	chosen := make(map[*RPSMCTSNode]bool)
+	for i := 0; i < 200; i++ {
+		node := engine.SampleMove(1.0)
+		if node.Visits.Load() == 0 {
+			t.Fatalf("Sampled an unvisited child %+v", *node.Move)
+		}
+		chosen[node] = true
+	}
+	if len(chosen) < 2 {
+		t.Errorf("Expected temperature 1 to pick several moves, got %d", len(chosen))
+	}
+}
+
+func TestParseDifficulty(t *testing.T) {
+	for _, d := range Difficulties {
+		parsed, err := ParseDifficulty(strings.ToUpper(d.String()))
+		if err != nil || parsed != d {
+			t.Errorf("ParseDifficulty(%q) = %v, %v", d, parsed, err)
+		}
+	}
+	if _, err := ParseDifficulty("impossible"); err == nil {
+		t.Error("Expected an error for an unknown difficulty")
+	}
+
+	if DifficultyEasy.Simulations() >= DifficultyHard.Simulations() ||
+		DifficultyEasy.Temperature() <= DifficultyHard.Temperature() {
+		t.Error("Expected easy to search less and sample more freely than hard")
+	}
+}
+
+func TestRPSMCTSGetRootValue(t *testing.T) {
+	valueNetwork := neural.NewRPSValueNetwork(32)
+	params := DefaultRPSMCTSParams()
+	params.NumSimulations = 100
+	params.Rng = rand.New(rand.NewSource(2))
+	engine := NewRPSMCTS(neural.NewRPSPolicyNetwork(32), valueNetwork, params)
+
+	if v := engine.GetRootValue(); v != 0.5 {
+		t.Errorf("Expected 0.5 without a root, got %v", v)
+	}
+
+	// Before searching, the value network's estimate for the player to move
+	gameState := game.NewRPSGameSeeded(15, 5, 10, rand.New(rand.NewSource(5)))
+	engine.SetRootState(gameState)
+	if got, want := engine.GetRootValue(), valueNetwork.Predict(gameState); got != want {
+		t.Errorf("Expected the value network's %v before searching, got %v", want, got)
+	}
+
+	// After searching, the search's average agrees with its best reply
+	engine.Search()
+	value := engine.GetRootValue()
+	if value < 0 || value > 1 {
+		t.Fatalf("Root value %v outside [0, 1]", value)
+	}
+	best := engine.Root.MostVisitedChild()
+	bestValue := best.TotalValue / float64(best.Visits.Load())
+	if math.Abs(value-bestValue) > 0.2 {
+		t.Errorf("Root value %v far from the chosen move's value %v", value, bestValue)
+	}
+}
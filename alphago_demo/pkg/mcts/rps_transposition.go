@@ -0,0 +1,40 @@
+package mcts
+
+import "github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
+
+// transpositionTable maps positions, by Zobrist hash, to the nodes that
+// represent them, so a position reached by different move orders is searched
+// once. The hash treats hands as multisets, matching play: the order of the
+// cards in a hand makes no difference.
+type transpositionTable struct {
+	root   *RPSMCTSNode
+	nodes  map[uint64]*RPSMCTSNode
+	hits   int
+	misses int
+}
+
+// newTranspositionTable creates a table holding only the root
+func newTranspositionTable(root *RPSMCTSNode) *transpositionTable {
+	t := &transpositionTable{
+		root:  root,
+		nodes: make(map[uint64]*RPSMCTSNode),
+	}
+	t.store(root)
+	return t
+}
+
+// lookup returns the node for the position, or nil if it has not been reached yet
+func (t *transpositionTable) lookup(state *game.RPSGame) *RPSMCTSNode {
+	node, found := t.nodes[state.Hash()]
+	if found {
+		t.hits++
+	} else {
+		t.misses++
+	}
+	return node
+}
+
+// store records the node for its position
+func (t *transpositionTable) store(node *RPSMCTSNode) {
+	t.nodes[node.GameState.Hash()] = node
+}
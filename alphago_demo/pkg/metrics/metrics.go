@@ -0,0 +1,150 @@
+// Package metrics exports the progress of long training and tournament runs
+// for Prometheus to scrape. It is opt-in: a command creates a Metrics only
+// when it is given an address to serve on, and the packages doing the work
+// report through small monitor interfaces that cost nothing when unset.
+package metrics
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics serves the progress of a run on /metrics. It implements
+// training.Monitor and tournament.Monitor.
+type Metrics struct {
+	server *http.Server
+
+	selfPlayGames             prometheus.Counter
+	selfPlayExamples          prometheus.Counter
+	selfPlayGamesPerSecond    prometheus.Gauge
+	selfPlayExamplesPerSecond prometheus.Gauge
+
+	trainingEpoch prometheus.Gauge
+	policyLoss    prometheus.Gauge
+	valueLoss     prometheus.Gauge
+
+	tournamentGames          prometheus.Gauge
+	tournamentGamesPerSecond prometheus.Gauge
+	matchupsPlayed           prometheus.Gauge
+	matchupsScheduled        prometheus.Gauge
+	elo                      *prometheus.GaugeVec
+
+	// The current self-play run, for its rates
+	mu                  sync.Mutex
+	selfPlayStart       time.Time
+	selfPlayRunGames    int
+	selfPlayRunExamples int
+}
+
+// Serve starts serving metrics on addr, for example ":9100", and returns once
+// the address is bound. The server runs until Close.
+func Serve(addr string) (*Metrics, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	m := newMetrics()
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		m.selfPlayGames, m.selfPlayExamples, m.selfPlayGamesPerSecond, m.selfPlayExamplesPerSecond,
+		m.trainingEpoch, m.policyLoss, m.valueLoss,
+		m.tournamentGames, m.tournamentGamesPerSecond, m.matchupsPlayed, m.matchupsScheduled, m.elo,
+	)
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	m.server = &http.Server{Handler: mux}
+	go m.server.Serve(listener)
+	return m, nil
+}
+
+// newMetrics creates the collectors
+func newMetrics() *Metrics {
+	gauge := func(name, help string) prometheus.Gauge {
+		return prometheus.NewGauge(prometheus.GaugeOpts{Namespace: "rps", Name: name, Help: help})
+	}
+	counter := func(name, help string) prometheus.Counter {
+		return prometheus.NewCounter(prometheus.CounterOpts{Namespace: "rps", Name: name, Help: help})
+	}
+
+	return &Metrics{
+		selfPlayGames:             counter("selfplay_games_total", "Self-play games finished."),
+		selfPlayExamples:          counter("selfplay_examples_total", "Training examples generated by self-play."),
+		selfPlayGamesPerSecond:    gauge("selfplay_games_per_second", "Games per second over the current self-play run."),
+		selfPlayExamplesPerSecond: gauge("selfplay_examples_per_second", "Examples per second over the current self-play run."),
+
+		trainingEpoch: gauge("training_epoch", "Last training epoch finished, counting from 1."),
+		policyLoss:    gauge("training_policy_loss", "Policy network loss in the last epoch."),
+		valueLoss:     gauge("training_value_loss", "Value network loss in the last epoch."),
+
+		tournamentGames:          gauge("tournament_games", "Games played in the current tournament."),
+		tournamentGamesPerSecond: gauge("tournament_games_per_second", "Games per second over the current tournament."),
+		matchupsPlayed:           gauge("tournament_matchups_played", "Matchups finished in the current tournament."),
+		matchupsScheduled:        gauge("tournament_matchups_scheduled", "Matchups scheduled when the tournament began; pruning can leave some unplayed."),
+		elo: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "rps",
+			Name:      "tournament_elo",
+			Help:      "Current ELO rating of each agent.",
+		}, []string{"agent"}),
+	}
+}
+
+// Close stops the server
+func (m *Metrics) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return m.server.Shutdown(ctx)
+}
+
+// SelfPlayStarted implements training.Monitor
+func (m *Metrics) SelfPlayStarted(games int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.selfPlayStart = time.Now()
+	m.selfPlayRunGames = 0
+	m.selfPlayRunExamples = 0
+}
+
+// SelfPlayGameFinished implements training.Monitor
+func (m *Metrics) SelfPlayGameFinished(examples int) {
+	m.selfPlayGames.Inc()
+	m.selfPlayExamples.Add(float64(examples))
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.selfPlayRunGames++
+	m.selfPlayRunExamples += examples
+	if elapsed := time.Since(m.selfPlayStart).Seconds(); elapsed > 0 {
+		m.selfPlayGamesPerSecond.Set(float64(m.selfPlayRunGames) / elapsed)
+		m.selfPlayExamplesPerSecond.Set(float64(m.selfPlayRunExamples) / elapsed)
+	}
+}
+
+// EpochFinished implements training.Monitor
+func (m *Metrics) EpochFinished(epoch int, policyLoss, valueLoss float64) {
+	m.trainingEpoch.Set(float64(epoch))
+	m.policyLoss.Set(policyLoss)
+	m.valueLoss.Set(valueLoss)
+}
+
+// TournamentGameFinished implements tournament.Monitor
+func (m *Metrics) TournamentGameFinished(gamesPlayed int, gamesPerSecond float64, ratings map[string]float64) {
+	m.tournamentGames.Set(float64(gamesPlayed))
+	m.tournamentGamesPerSecond.Set(gamesPerSecond)
+	for agent, rating := range ratings {
+		m.elo.WithLabelValues(agent).Set(rating)
+	}
+}
+
+// MatchupFinished implements tournament.Monitor
+func (m *Metrics) MatchupFinished(played, scheduled int) {
+	m.matchupsPlayed.Set(float64(played))
+	m.matchupsScheduled.Set(float64(scheduled))
+}
@@ -41,8 +41,8 @@ func (a *NeuralAgent) GetMove(state *game.RPSGame) (game.RPSMove, error) {
 		return game.RPSMove{}, fmt.Errorf("no valid moves")
 	}
 
-	// Get policy predictions
-	predictions := a.policyNetwork.Predict(state)
+	// Get policy predictions over the legal squares
+	predictions := a.policyNetwork.PredictMasked(state)
 
 	// Find best valid move
 	bestScore := -1.0
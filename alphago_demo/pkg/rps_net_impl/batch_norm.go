@@ -0,0 +1,348 @@
+package neural
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	// batchNormMomentum is the weight of each training batch in the running
+	// mean and variance
+	batchNormMomentum = 0.1
+	// batchNormEpsilon keeps the normalization finite for constant neurons
+	batchNormEpsilon = 1e-5
+)
+
+// batchNorm normalizes each hidden neuron's pre-activation before the ReLU.
+// Training normalizes with the batch's own mean and variance and folds them
+// into running averages; inference normalizes with the running averages, so a
+// prediction does not depend on what else is in its batch.
+type batchNorm struct {
+	gamma []float64 // Learned scale, per neuron
+	beta  []float64 // Learned shift, per neuron
+
+	runningMean     []float64
+	runningVariance []float64
+}
+
+// newBatchNorm returns a batch-norm layer for size neurons that starts as the
+// identity on standardized inputs
+func newBatchNorm(size int) *batchNorm {
+	bn := &batchNorm{
+		gamma:           make([]float64, size),
+		beta:            make([]float64, size),
+		runningMean:     make([]float64, size),
+		runningVariance: make([]float64, size),
+	}
+	for i := 0; i < size; i++ {
+		bn.gamma[i] = 1
+		bn.runningVariance[i] = 1
+	}
+	return bn
+}
+
+// activate returns the hidden activation of neuron i for pre-activation z at
+// inference. A nil layer is no batch norm, so networks without one call it too.
+func (bn *batchNorm) activate(i int, z float64) float64 {
+	if bn != nil {
+		z = bn.gamma[i]*(z-bn.runningMean[i])/math.Sqrt(bn.runningVariance[i]+batchNormEpsilon) + bn.beta[i]
+	}
+	return relu(z)
+}
+
+// batchNormCache holds what the backward pass needs from a training forward pass
+type batchNormCache struct {
+	normalized [][]float64 // [batch][neuron], before gamma and beta
+	stddev     []float64   // [neuron], of the batch
+}
+
+// forwardTrain normalizes a batch of pre-activations, [batch][neuron], with the
+// batch's statistics and updates the running averages. It returns the
+// normalized, scaled and shifted values, before the ReLU.
+func (bn *batchNorm) forwardTrain(z [][]float64) ([][]float64, batchNormCache) {
+	batchSize, size := len(z), len(bn.gamma)
+	cache := batchNormCache{normalized: make([][]float64, batchSize), stddev: make([]float64, size)}
+	out := make([][]float64, batchSize)
+	for b := range out {
+		cache.normalized[b] = make([]float64, size)
+		out[b] = make([]float64, size)
+	}
+
+	for i := 0; i < size; i++ {
+		mean := 0.0
+		for b := range z {
+			mean += z[b][i]
+		}
+		mean /= float64(batchSize)
+		variance := 0.0
+		for b := range z {
+			d := z[b][i] - mean
+			variance += d * d
+		}
+		variance /= float64(batchSize)
+
+		cache.stddev[i] = math.Sqrt(variance + batchNormEpsilon)
+		for b := range z {
+			cache.normalized[b][i] = (z[b][i] - mean) / cache.stddev[i]
+			out[b][i] = bn.gamma[i]*cache.normalized[b][i] + bn.beta[i]
+		}
+
+		bn.runningMean[i] = (1-batchNormMomentum)*bn.runningMean[i] + batchNormMomentum*mean
+		bn.runningVariance[i] = (1-batchNormMomentum)*bn.runningVariance[i] + batchNormMomentum*variance
+	}
+	return out, cache
+}
+
+// backward takes the loss gradient with respect to forwardTrain's output and
+// returns it with respect to the pre-activations. It updates gamma and beta
+// with the summed gradient, as Train sums the per-example weight updates.
+func (bn *batchNorm) backward(grad [][]float64, cache batchNormCache, learningRate float64) [][]float64 {
+	batchSize, size := len(grad), len(bn.gamma)
+	n := float64(batchSize)
+	gradIn := make([][]float64, batchSize)
+	for b := range gradIn {
+		gradIn[b] = make([]float64, size)
+	}
+
+	for i := 0; i < size; i++ {
+		gradGamma, gradBeta := 0.0, 0.0
+		for b := range grad {
+			gradGamma += grad[b][i] * cache.normalized[b][i]
+			gradBeta += grad[b][i]
+		}
+
+		// The mean and variance depend on every input, so each input's
+		// gradient loses the batch's mean gradient and its projection on
+		// the normalized values
+		for b := range grad {
+			gradIn[b][i] = bn.gamma[i] / cache.stddev[i] *
+				(grad[b][i] - gradBeta/n - cache.normalized[b][i]*gradGamma/n)
+		}
+
+		bn.gamma[i] -= learningRate * gradGamma
+		bn.beta[i] -= learningRate * gradBeta
+	}
+	return gradIn
+}
+
+// batchNormPass is one training batch's pass through the input->hidden layer,
+// batch norm and the ReLU
+type batchNormPass struct {
+	inputs     [][]float64 // [batch][input]
+	normalized [][]float64 // [batch][neuron], after batch norm and before the ReLU
+	hidden     [][]float64 // [batch][neuron], after the ReLU
+	cache      batchNormCache
+}
+
+// forwardHidden runs a training batch through the input->hidden layer with
+// the given weights and biases, batch norm and the ReLU
+func (bn *batchNorm) forwardHidden(inputs [][]float64, weights [][]float64, biases []float64) batchNormPass {
+	preActivations := make([][]float64, len(inputs))
+	for b, input := range inputs {
+		preActivations[b] = make([]float64, len(weights))
+		for i, row := range weights {
+			sum := biases[i]
+			for j, w := range row {
+				sum += w * input[j]
+			}
+			preActivations[b][i] = sum
+		}
+	}
+
+	pass := batchNormPass{inputs: inputs}
+	pass.normalized, pass.cache = bn.forwardTrain(preActivations)
+	pass.hidden = make([][]float64, len(inputs))
+	for b, row := range pass.normalized {
+		pass.hidden[b] = make([]float64, len(row))
+		for i, v := range row {
+			pass.hidden[b][i] = relu(v)
+		}
+	}
+	return pass
+}
+
+// backwardHidden takes the loss gradients with respect to a pass's hidden
+// activations and updates batch norm and the input->hidden weights and biases.
+// Gradients and updates are clipped as in Train.
+func (bn *batchNorm) backwardHidden(pass batchNormPass, hiddenGradients [][]float64, weights [][]float64, biases []float64, learningRate float64) {
+	const gradientThreshold = 1.0
+
+	for b, row := range hiddenGradients {
+		for i := range row {
+			// The ReLU passes no gradient where it was off
+			if pass.normalized[b][i] <= 0 {
+				row[i] = 0
+			}
+			row[i] = clipGradient(row[i], gradientThreshold)
+		}
+	}
+	preActivationGradients := bn.backward(hiddenGradients, pass.cache, learningRate)
+
+	for i, row := range weights {
+		for j := range row {
+			grad := 0.0
+			for b, input := range pass.inputs {
+				grad += preActivationGradients[b][i] * input[j]
+			}
+			row[j] -= clipGradient(learningRate*grad, 0.1)
+		}
+		grad := 0.0
+		for b := range pass.inputs {
+			grad += preActivationGradients[b][i]
+		}
+		biases[i] -= learningRate * grad
+	}
+}
+
+// trainBatchNorm is Train for a policy network with batch norm. The batch's
+// statistics tie its examples together, so the whole batch goes forward
+// before any weight changes, and each weight then moves by the sum of the
+// updates Train would have made one example at a time.
+func (n *RPSPolicyNetwork) trainBatchNorm(inputFeatures [][]float64, targetProbs [][]float64, learningRate float64) float64 {
+	const gradientThreshold = 1.0
+	pass := n.batchNorm.forwardHidden(inputFeatures, n.weightsInputHidden, n.biasesHidden)
+
+	totalLoss := 0.0
+	outputGradients := make([][]float64, len(inputFeatures))
+	for b, hidden := range pass.hidden {
+		logits := make([]float64, n.outputSize)
+		for i := range logits {
+			sum := n.biasesOutput[i]
+			for j, h := range hidden {
+				sum += n.weightsHiddenOutput[i][j] * h
+			}
+			logits[i] = sum
+		}
+		probs := softmax(logits)
+
+		outputGradients[b] = make([]float64, n.outputSize)
+		for i, p := range probs {
+			if targetProbs[b][i] > 0 {
+				totalLoss -= targetProbs[b][i] * math.Log(math.Max(p, 1e-15))
+			}
+			outputGradients[b][i] = clipGradient(p-targetProbs[b][i], gradientThreshold)
+		}
+	}
+
+	// Hidden gradients use the hidden->output weights the forward pass used
+	hiddenGradients := make([][]float64, len(inputFeatures))
+	for b := range hiddenGradients {
+		hiddenGradients[b] = make([]float64, n.hiddenSize)
+		for i := range hiddenGradients[b] {
+			for j := 0; j < n.outputSize; j++ {
+				hiddenGradients[b][i] += outputGradients[b][j] * n.weightsHiddenOutput[j][i]
+			}
+		}
+	}
+
+	for i := 0; i < n.outputSize; i++ {
+		for j := 0; j < n.hiddenSize; j++ {
+			grad := 0.0
+			for b, hidden := range pass.hidden {
+				grad += outputGradients[b][i] * hidden[j]
+			}
+			n.weightsHiddenOutput[i][j] -= clipGradient(learningRate*grad, 0.1)
+		}
+		grad := 0.0
+		for b := range outputGradients {
+			grad += outputGradients[b][i]
+		}
+		n.biasesOutput[i] -= learningRate * grad
+	}
+
+	n.batchNorm.backwardHidden(pass, hiddenGradients, n.weightsInputHidden, n.biasesHidden, learningRate)
+	return totalLoss / float64(len(inputFeatures))
+}
+
+// trainBatchNorm is Train for a value network with batch norm, updating the
+// weights once per batch as the policy network's trainBatchNorm does
+func (n *RPSValueNetwork) trainBatchNorm(inputFeatures [][]float64, targetValues []float64, learningRate float64) float64 {
+	const gradientThreshold = 1.0
+	pass := n.batchNorm.forwardHidden(inputFeatures, n.weightsInputHidden, n.biasesHidden)
+
+	totalLoss := 0.0
+	outputGradients := make([]float64, len(inputFeatures))
+	for b, hidden := range pass.hidden {
+		logit := n.biasesOutput[0]
+		for i, h := range hidden {
+			logit += n.weightsHiddenOutput[0][i] * h
+		}
+		prediction := sigmoid(logit)
+
+		totalLoss += (prediction - targetValues[b]) * (prediction - targetValues[b])
+		outputGradients[b] = clipGradient(2*(prediction-targetValues[b])*prediction*(1-prediction), gradientThreshold)
+	}
+
+	hiddenGradients := make([][]float64, len(inputFeatures))
+	for b := range hiddenGradients {
+		hiddenGradients[b] = make([]float64, n.hiddenSize)
+		for i := range hiddenGradients[b] {
+			hiddenGradients[b][i] = outputGradients[b] * n.weightsHiddenOutput[0][i]
+		}
+	}
+
+	for i := 0; i < n.hiddenSize; i++ {
+		grad := 0.0
+		for b, hidden := range pass.hidden {
+			grad += outputGradients[b] * hidden[i]
+		}
+		n.weightsHiddenOutput[0][i] -= clipGradient(learningRate*grad, 0.1)
+	}
+	grad := 0.0
+	for _, g := range outputGradients {
+		grad += g
+	}
+	n.biasesOutput[0] -= learningRate * grad
+
+	n.batchNorm.backwardHidden(pass, hiddenGradients, n.weightsInputHidden, n.biasesHidden, learningRate)
+	return totalLoss / float64(len(inputFeatures))
+}
+
+// clone returns a deep copy of the layer, or nil for none
+func (bn *batchNorm) clone() *batchNorm {
+	if bn == nil {
+		return nil
+	}
+	return &batchNorm{
+		gamma:           CloneFloat64Slice(bn.gamma),
+		beta:            CloneFloat64Slice(bn.beta),
+		runningMean:     CloneFloat64Slice(bn.runningMean),
+		runningVariance: CloneFloat64Slice(bn.runningVariance),
+	}
+}
+
+// save adds the layer to a model file's data
+func (bn *batchNorm) save(data map[string]interface{}) {
+	if bn == nil {
+		return
+	}
+	data["batchNorm"] = true
+	data["batchNormGamma"] = bn.gamma
+	data["batchNormBeta"] = bn.beta
+	data["batchNormRunningMean"] = bn.runningMean
+	data["batchNormRunningVariance"] = bn.runningVariance
+}
+
+// loadBatchNorm reads the batch-norm layer of a model file for size hidden
+// neurons, returning nil if the header says it has none
+func loadBatchNorm(header ModelHeader, data map[string]interface{}, size int) (*batchNorm, error) {
+	if !header.BatchNorm {
+		return nil, nil
+	}
+	bn := newBatchNorm(size)
+	for key, values := range map[string]*[]float64{
+		"batchNormGamma":           &bn.gamma,
+		"batchNormBeta":            &bn.beta,
+		"batchNormRunningMean":     &bn.runningMean,
+		"batchNormRunningVariance": &bn.runningVariance,
+	} {
+		vector, ok := data[key].([]interface{})
+		if !ok || len(vector) != size {
+			return nil, fmt.Errorf("model file's %s does not have a value for each of %d hidden neurons", key, size)
+		}
+		if err := loadWeightsVector(vector, values); err != nil {
+			return nil, err
+		}
+	}
+	return bn, nil
+}
@@ -0,0 +1,198 @@
+package neural
+
+import (
+	"math"
+	"math/rand"
+	"path/filepath"
+	"testing"
+)
+
+// randomBatch returns size random inputs and one-hot targets
+func randomBatch(rng *rand.Rand, size, inputSize, outputSize int) ([][]float64, [][]float64) {
+	inputs := make([][]float64, size)
+	targets := make([][]float64, size)
+	for b := range inputs {
+		inputs[b] = make([]float64, inputSize)
+		for j := range inputs[b] {
+			inputs[b][j] = rng.Float64()
+		}
+		targets[b] = make([]float64, outputSize)
+		targets[b][rng.Intn(outputSize)] = 1
+	}
+	return inputs, targets
+}
+
+func TestBatchNormBackwardMatchesFiniteDifferences(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	const batchSize, size = 5, 3
+	z := make([][]float64, batchSize)
+	upstream := make([][]float64, batchSize)
+	for b := range z {
+		z[b] = make([]float64, size)
+		upstream[b] = make([]float64, size)
+		for i := range z[b] {
+			z[b][i] = rng.NormFloat64()
+			upstream[b][i] = rng.NormFloat64()
+		}
+	}
+
+	bn := newBatchNorm(size)
+	bn.gamma = []float64{0.5, 1.5, -2}
+	bn.beta = []float64{0.1, -0.3, 0.2}
+
+	// The loss is the upstream gradients' dot product with the output
+	loss := func() float64 {
+		out, _ := bn.clone().forwardTrain(z)
+		total := 0.0
+		for b := range out {
+			for i := range out[b] {
+				total += upstream[b][i] * out[b][i]
+			}
+		}
+		return total
+	}
+
+	_, cache := bn.clone().forwardTrain(z)
+	got := bn.clone().backward(upstream, cache, 0)
+
+	const h = 1e-6
+	for b := range z {
+		for i := range z[b] {
+			original := z[b][i]
+			z[b][i] = original + h
+			plus := loss()
+			z[b][i] = original - h
+			minus := loss()
+			z[b][i] = original
+
+			expected := (plus - minus) / (2 * h)
+			if math.Abs(got[b][i]-expected) > 1e-6 {
+				t.Errorf("Gradient [%d][%d] = %v, finite differences give %v", b, i, got[b][i], expected)
+			}
+		}
+	}
+}
+
+func TestBatchNormTrainAndEvalModes(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	network := NewRPSPolicyNetworkSeeded(16, 1)
+	network.SetBatchNorm(true)
+	if !network.UsesBatchNorm() {
+		t.Fatal("Expected SetBatchNorm(true) to enable batch norm")
+	}
+
+	inputs, targets := randomBatch(rng, 32, network.inputSize, network.outputSize)
+	first := network.Train(inputs, targets, 0.01)
+	last := first
+	for epoch := 0; epoch < 50; epoch++ {
+		last = network.Train(inputs, targets, 0.01)
+	}
+	if last >= first {
+		t.Errorf("Expected the loss to fall while training on one batch, went from %.4f to %.4f", first, last)
+	}
+
+	// Training folded the batch statistics into the running averages
+	if network.batchNorm.runningMean[0] == 0 || network.batchNorm.runningVariance[0] == 1 {
+		t.Error("Expected training to update the running statistics")
+	}
+
+	// Inference uses the running statistics, so a prediction does not depend
+	// on the rest of its batch
+	batch := network.PredictBatchFeatures(inputs[:4])
+	for b, input := range inputs[:4] {
+		if !equalFloats(batch[b], network.PredictFeatures(input)) {
+			t.Fatalf("Input %d: batch prediction %v differs from single prediction %v",
+				b, batch[b], network.PredictFeatures(input))
+		}
+	}
+
+	network.SetBatchNorm(false)
+	if network.UsesBatchNorm() || network.ParameterCount() != NewRPSPolicyNetwork(16).ParameterCount() {
+		t.Error("Expected SetBatchNorm(false) to remove batch norm")
+	}
+}
+
+func TestValueBatchNormTrains(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	network := NewRPSValueNetworkSeeded(16, 1)
+	network.SetBatchNorm(true)
+
+	inputs, _ := randomBatch(rng, 32, network.inputSize, 1)
+	targets := make([]float64, len(inputs))
+	for b := range targets {
+		targets[b] = float64(b % 2)
+	}
+
+	first := network.Train(inputs, targets, 0.1)
+	last := first
+	for epoch := 0; epoch < 100; epoch++ {
+		last = network.Train(inputs, targets, 0.1)
+	}
+	if last >= first {
+		t.Errorf("Expected the loss to fall while training on one batch, went from %.4f to %.4f", first, last)
+	}
+}
+
+func TestBatchNormSaveLoad(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	dir := t.TempDir()
+
+	policy := NewRPSPolicyNetworkSeeded(16, 1)
+	policy.SetBatchNorm(true)
+	inputs, targets := randomBatch(rng, 16, policy.inputSize, policy.outputSize)
+	policy.Train(inputs, targets, 0.01)
+
+	path := filepath.Join(dir, "policy.model")
+	if err := policy.SaveToFile(path); err != nil {
+		t.Fatalf("Failed to save: %v", err)
+	}
+	header, err := ReadModelHeader(path)
+	if err != nil {
+		t.Fatalf("Failed to read header: %v", err)
+	}
+	if !header.BatchNorm || header.Version != ModelFormatVersion {
+		t.Errorf("Expected a version %d header recording batch norm, got version %d, batch norm %v",
+			ModelFormatVersion, header.Version, header.BatchNorm)
+	}
+
+	loaded := NewRPSPolicyNetwork(16)
+	if err := loaded.LoadFromFile(path); err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+	if !loaded.UsesBatchNorm() {
+		t.Fatal("Expected the loaded network to use batch norm")
+	}
+	for _, input := range inputs {
+		if !equalFloats(loaded.PredictFeatures(input), policy.PredictFeatures(input)) {
+			t.Fatal("Loaded network predicts differently from the saved one")
+		}
+	}
+
+	// A model without batch norm loads into a network that had it
+	plain := NewRPSPolicyNetwork(16)
+	plainPath := filepath.Join(dir, "plain.model")
+	if err := plain.SaveToFile(plainPath); err != nil {
+		t.Fatalf("Failed to save: %v", err)
+	}
+	if err := loaded.LoadFromFile(plainPath); err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+	if loaded.UsesBatchNorm() {
+		t.Error("Expected loading a model without batch norm to remove it")
+	}
+
+	value := NewRPSValueNetworkSeeded(16, 1)
+	value.SetBatchNorm(true)
+	value.Train(inputs, make([]float64, len(inputs)), 0.01)
+	valuePath := filepath.Join(dir, "value.model")
+	if err := value.SaveToFile(valuePath); err != nil {
+		t.Fatalf("Failed to save: %v", err)
+	}
+	loadedValue := NewRPSValueNetwork(16)
+	if err := loadedValue.LoadFromFile(valuePath); err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+	if loadedValue.PredictFeatures(inputs[0]) != value.PredictFeatures(inputs[0]) {
+		t.Error("Loaded value network predicts differently from the saved one")
+	}
+}
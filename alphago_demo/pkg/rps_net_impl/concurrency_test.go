@@ -0,0 +1,77 @@
+package neural
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
+)
+
+// Tournaments and parallel self-play share one network between goroutines.
+// Run with -race: inference must not write to the network or the games.
+func TestConcurrentInferenceIsConsistent(t *testing.T) {
+	policy := NewRPSPolicyNetwork(32)
+	value := NewRPSValueNetwork(32)
+
+	rng := rand.New(rand.NewSource(1))
+	var games []*game.RPSGame
+	for len(games) < 50 {
+		g := game.NewRPSGameSeeded(21, 5, 10, rng)
+		for !g.IsGameOver() {
+			games = append(games, g.Copy())
+			moves := g.GetValidMoves()
+			g.MakeMove(moves[rng.Intn(len(moves))])
+		}
+	}
+
+	// Serial results to compare every goroutine's with
+	expectedPolicy := policy.PredictBatch(games)
+	expectedMasked := make([][]float64, len(games))
+	expectedValue := make([]float64, len(games))
+	for i, g := range games {
+		expectedMasked[i] = policy.PredictMasked(g)
+		expectedValue[i] = value.Predict(g)
+	}
+
+	const goroutines = 16
+	var wg sync.WaitGroup
+	errs := make(chan string, goroutines)
+	for w := 0; w < goroutines; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			batch := policy.PredictBatch(games)
+			for k := range games {
+				// Start each goroutine at a different game so calls overlap
+				i := (k + w*7) % len(games)
+				g := games[i]
+				if !equalFloats(policy.Predict(g), expectedPolicy[i]) ||
+					!equalFloats(batch[i], expectedPolicy[i]) ||
+					!equalFloats(policy.PredictMasked(g), expectedMasked[i]) ||
+					value.Predict(g) != expectedValue[i] {
+					errs <- "concurrent prediction differs from the serial one"
+					return
+				}
+				policy.PredictMove(g)
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func equalFloats(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,136 @@
+package neural
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
+)
+
+// FeatureVersion identifies how a network encodes positions as input. It is
+// saved with the model, so a network is always given the input it was trained
+// on even after the default encoding changes.
+type FeatureVersion int
+
+const (
+	// BoardFeatureVersion encodes only the board, game.FeaturesPerPosition
+	// features per square. Models saved before feature versions existed use it.
+	BoardFeatureVersion FeatureVersion = 0
+
+	// ProgressFeatureVersion follows the board features with the
+	// game.ProgressFeatureCount game-progress features: round, hand sizes and
+	// empty squares
+	ProgressFeatureVersion FeatureVersion = 1
+
+	// CurrentFeatureVersion is the encoding new networks use
+	CurrentFeatureVersion = ProgressFeatureVersion
+)
+
+// InputSize returns the number of inputs the encoding gives for a
+// boardDim x boardDim board
+func (v FeatureVersion) InputSize(boardDim int) int {
+	size := boardDim * boardDim * game.FeaturesPerPosition
+	if v >= ProgressFeatureVersion {
+		size += game.ProgressFeatureCount
+	}
+	return size
+}
+
+// boardSquares returns the number of board squares encoded by inputSize inputs
+// of this version
+func (v FeatureVersion) boardSquares(inputSize int) int {
+	if v >= ProgressFeatureVersion {
+		inputSize -= game.ProgressFeatureCount
+	}
+	return inputSize / game.FeaturesPerPosition
+}
+
+// Encode returns the features of gameState, from the perspective of the
+// player to move if canonical is set
+func (v FeatureVersion) Encode(gameState *game.RPSGame, canonical bool) []float64 {
+	var features []float64
+	if canonical {
+		features = gameState.GetCanonicalFeatures()
+	} else {
+		features = gameState.GetBoardAsFeatures()
+	}
+
+	if v >= ProgressFeatureVersion {
+		if canonical {
+			features = append(features, gameState.GetCanonicalProgressFeatures()...)
+		} else {
+			features = append(features, gameState.GetProgressFeatures()...)
+		}
+	}
+	return features
+}
+
+// FeatureVersionForInput returns the encoding that gives inputSize inputs on a
+// boardDim x boardDim board, for example to match precomputed training data
+func FeatureVersionForInput(inputSize, boardDim int) (FeatureVersion, error) {
+	for v := CurrentFeatureVersion; v >= BoardFeatureVersion; v-- {
+		if v.InputSize(boardDim) == inputSize {
+			return v, nil
+		}
+	}
+	return 0, fmt.Errorf("no feature encoding has %d inputs on a %dx%d board", inputSize, boardDim, boardDim)
+}
+
+// Encoder turns positions into the input of a network. A network encodes
+// positions with the encoder it was built or loaded with, so a model is always
+// given the input it was trained on.
+type Encoder interface {
+	// Version identifies the encoding, and is saved in the model header
+	Version() FeatureVersion
+
+	// Encode returns the features of a position
+	Encode(g *game.RPSGame) []float64
+
+	// Size returns the number of features Encode returns
+	Size() int
+}
+
+// featureEncoder encodes positions on a boardDim x boardDim board with one
+// feature version
+type featureEncoder struct {
+	version   FeatureVersion
+	boardDim  int
+	canonical bool
+}
+
+var _ Encoder = featureEncoder{}
+
+// NewEncoder returns the encoder for version on a boardDim x boardDim board,
+// from the perspective of the player to move if canonical is set
+func NewEncoder(version FeatureVersion, boardDim int, canonical bool) (Encoder, error) {
+	if version < BoardFeatureVersion || version > CurrentFeatureVersion {
+		return nil, fmt.Errorf("feature version %d is not supported", version)
+	}
+	if boardDim < game.MinBoardDim || boardDim > game.MaxBoardDim {
+		return nil, fmt.Errorf("board dimension %d is outside [%d, %d]", boardDim, game.MinBoardDim, game.MaxBoardDim)
+	}
+	return featureEncoder{version: version, boardDim: boardDim, canonical: canonical}, nil
+}
+
+func (e featureEncoder) Version() FeatureVersion { return e.version }
+
+func (e featureEncoder) Encode(g *game.RPSGame) []float64 { return e.version.Encode(g, e.canonical) }
+
+func (e featureEncoder) Size() int { return e.version.InputSize(e.boardDim) }
+
+// squares returns the number of board squares the encoder is for
+func (e featureEncoder) squares() int { return e.boardDim * e.boardDim }
+
+// headerEncoder returns the encoder a model was saved with, checking that the
+// saved input size matches it
+func headerEncoder(header ModelHeader) (featureEncoder, error) {
+	version := header.FeatureVersion
+	if version < BoardFeatureVersion || version > CurrentFeatureVersion {
+		return featureEncoder{}, fmt.Errorf("feature version %d is not supported", version)
+	}
+	dim := int(math.Round(math.Sqrt(float64(version.boardSquares(header.InputSize)))))
+	if dim == 0 || version.InputSize(dim) != header.InputSize {
+		return featureEncoder{}, fmt.Errorf("input size %d does not match feature version %d", header.InputSize, version)
+	}
+	return featureEncoder{version: version, boardDim: dim, canonical: header.CanonicalInput}, nil
+}
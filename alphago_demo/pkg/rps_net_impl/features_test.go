@@ -0,0 +1,168 @@
+package neural
+
+import (
+	"math/rand"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
+)
+
+func TestEncodingMatchesInputSize(t *testing.T) {
+	for _, version := range []FeatureVersion{BoardFeatureVersion, ProgressFeatureVersion} {
+		for _, dim := range []int{3, 4} {
+			policy := NewRPSPolicyNetworkWithFeatures(16, dim, version)
+			value := NewRPSValueNetworkWithFeatures(16, dim, version)
+			if policy.inputSize != version.InputSize(dim) || value.inputSize != version.InputSize(dim) {
+				t.Errorf("Version %d, %dx%d board: networks have %d and %d inputs, want %d",
+					version, dim, dim, policy.inputSize, value.inputSize, version.InputSize(dim))
+			}
+			if got, err := FeatureVersionForInput(version.InputSize(dim), dim); err != nil || got != version {
+				t.Errorf("FeatureVersionForInput(%d, %d) = %d, %v; want %d", version.InputSize(dim), dim, got, err, version)
+			}
+
+			state := game.NewRPSGameSized(dim, 40, 5, 10, rand.New(rand.NewSource(1)))
+			for _, canonical := range []bool{false, true} {
+				policy.SetCanonicalInput(canonical)
+				if n := len(policy.EncodeState(state)); n != policy.inputSize {
+					t.Errorf("Version %d, %dx%d board, canonical %v: encoded %d features for %d inputs",
+						version, dim, dim, canonical, n, policy.inputSize)
+				}
+			}
+		}
+	}
+
+	if _, err := FeatureVersionForInput(80, 3); err == nil {
+		t.Error("Expected an error for an input size no encoding gives")
+	}
+}
+
+func TestProgressFeaturesChangeDuringGame(t *testing.T) {
+	network := NewRPSPolicyNetwork(16)
+	boardInputs := BoardFeatureVersion.InputSize(3)
+	state := game.NewRPSGameSeeded(21, 5, 10, rand.New(rand.NewSource(2)))
+
+	previous := network.EncodeState(state)
+	for !state.IsGameOver() {
+		move, err := state.GetRandomMove()
+		if err != nil {
+			t.Fatalf("GetRandomMove failed: %v", err)
+		}
+		state.MakeMove(move)
+
+		features := network.EncodeState(state)
+		if reflect.DeepEqual(features[boardInputs:], previous[boardInputs:]) {
+			t.Errorf("Progress features %v did not change after a move", features[boardInputs:])
+		}
+		if !reflect.DeepEqual(features[boardInputs:], state.GetProgressFeatures()) {
+			t.Errorf("Encoded progress features %v, want %v", features[boardInputs:], state.GetProgressFeatures())
+		}
+		previous = features
+	}
+}
+
+func TestLoadBoardFeatureModel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.model")
+	original := NewRPSPolicyNetworkWithFeatures(16, 3, BoardFeatureVersion)
+	if err := original.SaveToFile(path); err != nil {
+		t.Fatalf("Failed to save: %v", err)
+	}
+	// Models saved before feature versions existed have no version field
+	rewriteModel(t, path, func(data map[string]interface{}) {
+		delete(data, "featureVersion")
+	})
+
+	loaded := NewRPSPolicyNetwork(16)
+	if err := loaded.LoadFromFile(path); err != nil {
+		t.Fatalf("Failed to load a board-only model: %v", err)
+	}
+	if loaded.FeatureVersion() != BoardFeatureVersion || loaded.inputSize != BoardFeatureVersion.InputSize(3) {
+		t.Errorf("Loaded feature version %d with %d inputs, want version %d with %d",
+			loaded.FeatureVersion(), loaded.inputSize, BoardFeatureVersion, BoardFeatureVersion.InputSize(3))
+	}
+
+	state := game.NewRPSGameSeeded(21, 5, 10, rand.New(rand.NewSource(3)))
+	if !reflect.DeepEqual(loaded.Predict(state), original.Predict(state)) {
+		t.Error("Loaded board-only model predicts differently from the original")
+	}
+
+	// A version that does not match the saved input size is rejected
+	rewriteModel(t, path, func(data map[string]interface{}) {
+		data["featureVersion"] = float64(ProgressFeatureVersion)
+	})
+	if err := NewRPSPolicyNetwork(16).LoadFromFile(path); err == nil {
+		t.Error("Expected an error loading a model whose input size does not match its feature version")
+	}
+}
+
+func TestNewEncoder(t *testing.T) {
+	state := game.NewRPSGameSized(4, 40, 5, 10, rand.New(rand.NewSource(4)))
+	state.CurrentPlayer = game.Player2
+	for _, version := range []FeatureVersion{BoardFeatureVersion, ProgressFeatureVersion} {
+		for _, canonical := range []bool{false, true} {
+			encoder, err := NewEncoder(version, 4, canonical)
+			if err != nil {
+				t.Fatalf("NewEncoder(%d, 4, %v) failed: %v", version, canonical, err)
+			}
+			features := encoder.Encode(state)
+			if encoder.Version() != version || len(features) != encoder.Size() {
+				t.Errorf("Version %d, canonical %v: encoder reports version %d and size %d, encoded %d features",
+					version, canonical, encoder.Version(), encoder.Size(), len(features))
+			}
+			if want := version.Encode(state, canonical); !reflect.DeepEqual(features, want) {
+				t.Errorf("Version %d, canonical %v: encoder features differ from the version's encoding", version, canonical)
+			}
+		}
+	}
+
+	if _, err := NewEncoder(CurrentFeatureVersion+1, 3, false); err == nil {
+		t.Error("Expected an error for an unsupported feature version")
+	}
+	if _, err := NewEncoder(CurrentFeatureVersion, 1, false); err == nil {
+		t.Error("Expected an error for an unsupported board size")
+	}
+}
+
+func TestModelHeaderRecordsEncoder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "value.model")
+	original := NewRPSValueNetworkWithFeatures(16, 3, BoardFeatureVersion)
+	original.SetCanonicalInput(true)
+	if err := original.SaveToFile(path); err != nil {
+		t.Fatalf("Failed to save: %v", err)
+	}
+
+	header, err := ReadModelHeader(path)
+	if err != nil {
+		t.Fatalf("Failed to read header: %v", err)
+	}
+	if header.FeatureVersion != BoardFeatureVersion || !header.CanonicalInput {
+		t.Errorf("Header records feature version %d, canonical %v; want %d, true",
+			header.FeatureVersion, header.CanonicalInput, BoardFeatureVersion)
+	}
+
+	// A network loading the model adopts its encoder, whatever it was built with
+	loaded := NewRPSValueNetwork(16)
+	if err := loaded.LoadFromFile(path); err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+	if loaded.Encoder() != original.Encoder() {
+		t.Errorf("Loaded encoder %+v, want %+v", loaded.Encoder(), original.Encoder())
+	}
+	state := game.NewRPSGameSeeded(21, 5, 10, rand.New(rand.NewSource(5)))
+	if loaded.Predict(state) != original.Predict(state) {
+		t.Error("Loaded model predicts differently from the original")
+	}
+}
+
+func TestPredictFeaturesRejectsOtherEncodings(t *testing.T) {
+	network := NewRPSPolicyNetworkWithFeatures(16, 3, BoardFeatureVersion)
+	state := game.NewRPSGame(21, 5, 10)
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a panic predicting from features of another encoding")
+		}
+	}()
+	network.PredictFeatures(CurrentFeatureVersion.Encode(state, false))
+}
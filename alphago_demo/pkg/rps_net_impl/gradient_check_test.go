@@ -0,0 +1,190 @@
+package neural
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// parameter is one trainable weight or bias, named for error messages
+type parameter struct {
+	name  string
+	value *float64
+}
+
+// matrixParameters returns a parameter for every entry of a weight matrix or,
+// for a single row, a bias vector
+func matrixParameters(name string, rows ...[]float64) []parameter {
+	var params []parameter
+	for i, row := range rows {
+		for j := range row {
+			label := fmt.Sprintf("%s[%d][%d]", name, i, j)
+			if len(rows) == 1 {
+				label = fmt.Sprintf("%s[%d]", name, j)
+			}
+			params = append(params, parameter{label, &row[j]})
+		}
+	}
+	return params
+}
+
+func policyParameters(n *RPSPolicyNetwork) []parameter {
+	params := matrixParameters("weightsInputHidden", n.weightsInputHidden...)
+	params = append(params, matrixParameters("biasesHidden", n.biasesHidden)...)
+	params = append(params, matrixParameters("weightsHiddenOutput", n.weightsHiddenOutput...)...)
+	params = append(params, matrixParameters("biasesOutput", n.biasesOutput)...)
+	return append(params, batchNormParameters(n.batchNorm)...)
+}
+
+func valueParameters(n *RPSValueNetwork) []parameter {
+	params := matrixParameters("weightsInputHidden", n.weightsInputHidden...)
+	params = append(params, matrixParameters("biasesHidden", n.biasesHidden)...)
+	params = append(params, matrixParameters("weightsHiddenOutput", n.weightsHiddenOutput...)...)
+	params = append(params, matrixParameters("biasesOutput", n.biasesOutput)...)
+	return append(params, batchNormParameters(n.batchNorm)...)
+}
+
+func batchNormParameters(bn *batchNorm) []parameter {
+	if bn == nil {
+		return nil
+	}
+	return append(matrixParameters("gamma", bn.gamma), matrixParameters("beta", bn.beta)...)
+}
+
+// checkGradients compares the gradients Train applies against central finite
+// differences of loss. train runs Train on a copy of the network with the
+// given learning rate and returns the copy's parameters; with a learning rate
+// this small no clipping applies, so each parameter moves by exactly the
+// learning rate times its gradient.
+func checkGradients(t *testing.T, params []parameter, train func(learningRate float64) []parameter, loss func() float64) {
+	t.Helper()
+	const learningRate, h, tolerance = 1e-4, 1e-6, 1e-6
+
+	trained := train(learningRate)
+	for k, p := range params {
+		analytic := (*p.value - *trained[k].value) / learningRate
+
+		original := *p.value
+		*p.value = original + h
+		plus := loss()
+		*p.value = original - h
+		minus := loss()
+		*p.value = original
+		numeric := (plus - minus) / (2 * h)
+
+		if math.Abs(analytic-numeric) > tolerance*math.Max(1, math.Abs(numeric)) {
+			t.Fatalf("%s: backward pass gives %.9g, finite differences give %.9g", p.name, analytic, numeric)
+		}
+	}
+}
+
+// crossEntropy is the policy loss Train minimizes for one example
+func crossEntropy(probs, target []float64) float64 {
+	loss := 0.0
+	for i, p := range probs {
+		if target[i] > 0 {
+			loss -= target[i] * math.Log(p)
+		}
+	}
+	return loss
+}
+
+func TestPolicyGradients(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	network := NewRPSPolicyNetworkSeeded(4, 1)
+	inputs, targets := randomBatch(rng, 2, network.inputSize, network.outputSize)
+
+	// Train updates after every example, so each is checked on its own
+	for b := range inputs {
+		checkGradients(t, policyParameters(network),
+			func(learningRate float64) []parameter {
+				trained := network.Clone()
+				trained.Train(inputs[b:b+1], targets[b:b+1], learningRate)
+				return policyParameters(trained)
+			},
+			func() float64 {
+				return crossEntropy(network.PredictFeatures(inputs[b]), targets[b])
+			})
+	}
+}
+
+func TestValueGradients(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	network := NewRPSValueNetworkSeeded(4, 1)
+	inputs, _ := randomBatch(rng, 2, network.inputSize, 1)
+	targets := []float64{1, 0}
+
+	for b := range inputs {
+		checkGradients(t, valueParameters(network),
+			func(learningRate float64) []parameter {
+				trained := network.Clone()
+				trained.Train(inputs[b:b+1], targets[b:b+1], learningRate)
+				return valueParameters(trained)
+			},
+			func() float64 {
+				diff := network.PredictFeatures(inputs[b]) - targets[b]
+				return diff * diff
+			})
+	}
+}
+
+// With batch norm the whole batch goes forward with its own statistics before
+// any update, and each parameter moves by the gradient of the summed loss
+
+func TestPolicyBatchNormGradients(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	network := NewRPSPolicyNetworkSeeded(4, 1)
+	network.SetBatchNorm(true)
+	inputs, targets := randomBatch(rng, 4, network.inputSize, network.outputSize)
+
+	checkGradients(t, policyParameters(network),
+		func(learningRate float64) []parameter {
+			trained := network.Clone()
+			trained.Train(inputs, targets, learningRate)
+			return policyParameters(trained)
+		},
+		func() float64 {
+			pass := network.batchNorm.clone().forwardHidden(inputs, network.weightsInputHidden, network.biasesHidden)
+			total := 0.0
+			for b, hidden := range pass.hidden {
+				logits := make([]float64, network.outputSize)
+				for i := range logits {
+					logits[i] = network.biasesOutput[i]
+					for j, h := range hidden {
+						logits[i] += network.weightsHiddenOutput[i][j] * h
+					}
+				}
+				total += crossEntropy(softmax(logits), targets[b])
+			}
+			return total
+		})
+}
+
+func TestValueBatchNormGradients(t *testing.T) {
+	rng := rand.New(rand.NewSource(4))
+	network := NewRPSValueNetworkSeeded(4, 1)
+	network.SetBatchNorm(true)
+	inputs, _ := randomBatch(rng, 4, network.inputSize, 1)
+	targets := []float64{1, 0, 0, 1}
+
+	checkGradients(t, valueParameters(network),
+		func(learningRate float64) []parameter {
+			trained := network.Clone()
+			trained.Train(inputs, targets, learningRate)
+			return valueParameters(trained)
+		},
+		func() float64 {
+			pass := network.batchNorm.clone().forwardHidden(inputs, network.weightsInputHidden, network.biasesHidden)
+			total := 0.0
+			for b, hidden := range pass.hidden {
+				logit := network.biasesOutput[0]
+				for i, h := range hidden {
+					logit += network.weightsHiddenOutput[0][i] * h
+				}
+				diff := sigmoid(logit) - targets[b]
+				total += diff * diff
+			}
+			return total
+		})
+}
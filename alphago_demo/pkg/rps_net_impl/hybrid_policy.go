@@ -0,0 +1,181 @@
+package neural
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
+	"github.com/zachbeta/neural_rps/pkg/neural/gpu"
+)
+
+// gpuRetryInterval is how long the hybrid network serves from the CPU after a GPU failure
+// before it tries the GPU service again
+const gpuRetryInterval = 30 * time.Second
+
+// HybridStats reports which backend served the calls made through a HybridPolicyNetwork
+type HybridStats struct {
+	GPUCalls     int  // Calls answered by the GPU service
+	CPUCalls     int  // Calls answered by the in-process fallback network
+	GPUFailures  int  // GPU calls that failed and were retried on the CPU
+	GPUTimeouts  int  // Failed GPU calls that ran past the timeout set with SetGPUTimeout
+	GPUAvailable bool // Whether the GPU service is currently being used
+}
+
+// HybridPolicyNetwork serves policy predictions from the GPU gRPC service when it is
+// reachable and transparently falls back to an in-process RPSPolicyNetwork otherwise
+type HybridPolicyNetwork struct {
+	gpu      *gpu.RPSGPUPolicyNetwork
+	fallback *RPSPolicyNetwork
+
+	mu          sync.Mutex
+	gpuTimeout  time.Duration
+	gpuDownTill time.Time
+	stats       HybridStats
+}
+
+// NewHybridPolicyNetwork connects to the GPU service at addr. If the service cannot be
+// reached, every call is answered by fallback.
+func NewHybridPolicyNetwork(addr string, fallback *RPSPolicyNetwork) (*HybridPolicyNetwork, error) {
+	if fallback == nil {
+		return nil, fmt.Errorf("hybrid policy network requires a fallback network")
+	}
+
+	n := &HybridPolicyNetwork{fallback: fallback}
+
+	gpuNet, err := gpu.NewRPSGPUPolicyNetwork(addr)
+	if err != nil {
+		fmt.Printf("GPU service unavailable at %s, using CPU network: %v\n", addr, err)
+		return n, nil
+	}
+	if gpuNet.InputSize != fallback.inputSize || gpuNet.OutputSize != fallback.outputSize {
+		gpuNet.Close()
+		return nil, fmt.Errorf("GPU model shape %dx%d does not match fallback network %dx%d",
+			gpuNet.InputSize, gpuNet.OutputSize, fallback.inputSize, fallback.outputSize)
+	}
+
+	n.gpu = gpuNet
+	return n, nil
+}
+
+// Predict returns move probabilities for a game state, encoded the way the fallback network expects
+func (n *HybridPolicyNetwork) Predict(gameState *game.RPSGame) []float64 {
+	return n.PredictFeatures(n.fallback.EncodeState(gameState))
+}
+
+// SetGPUTimeout bounds how long a call waits for the GPU service, retries
+// included. A call that takes longer is answered by the CPU network, and the
+// GPU is rested like after any other failure. Zero, the default, leaves only
+// the GPU client's own per-request timeouts.
+func (n *HybridPolicyNetwork) SetGPUTimeout(timeout time.Duration) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.gpuTimeout = timeout
+}
+
+// PredictFeatures returns move probabilities for an already encoded feature vector
+func (n *HybridPolicyNetwork) PredictFeatures(features []float64) []float64 {
+	if n.useGPU() {
+		ctx, cancel := n.gpuContext()
+		probs, err := n.gpu.ForwardContext(ctx, features)
+		cancel()
+		if err == nil {
+			n.recordGPU()
+			return probs
+		}
+		n.recordGPUFailure(err, ctx.Err() == context.DeadlineExceeded)
+	}
+
+	n.recordCPU()
+	return n.fallback.PredictFeatures(features)
+}
+
+// PredictFeaturesBatch returns move probabilities for a batch of feature vectors
+func (n *HybridPolicyNetwork) PredictFeaturesBatch(inputs [][]float64) [][]float64 {
+	if len(inputs) == 0 {
+		return [][]float64{}
+	}
+
+	if n.useGPU() {
+		ctx, cancel := n.gpuContext()
+		outputs, err := n.gpu.BatchForwardContext(ctx, inputs)
+		cancel()
+		if err == nil {
+			n.recordGPU()
+			return outputs
+		}
+		n.recordGPUFailure(err, ctx.Err() == context.DeadlineExceeded)
+	}
+
+	n.recordCPU()
+	outputs := make([][]float64, len(inputs))
+	for i, input := range inputs {
+		outputs[i] = n.fallback.PredictFeatures(input)
+	}
+	return outputs
+}
+
+// GetBackendStats returns how many calls each backend has served
+func (n *HybridPolicyNetwork) GetBackendStats() HybridStats {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	stats := n.stats
+	stats.GPUAvailable = n.gpu != nil && time.Now().After(n.gpuDownTill)
+	return stats
+}
+
+// Close closes the GPU connection, if any
+func (n *HybridPolicyNetwork) Close() error {
+	if n.gpu != nil {
+		return n.gpu.Close()
+	}
+	return nil
+}
+
+// useGPU reports whether the next call should be sent to the GPU service
+func (n *HybridPolicyNetwork) useGPU() bool {
+	if n.gpu == nil {
+		return false
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return time.Now().After(n.gpuDownTill)
+}
+
+// gpuContext returns the context for one GPU call, with the timeout if one is set
+func (n *HybridPolicyNetwork) gpuContext() (context.Context, context.CancelFunc) {
+	n.mu.Lock()
+	timeout := n.gpuTimeout
+	n.mu.Unlock()
+
+	if timeout <= 0 {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithTimeout(context.Background(), timeout)
+}
+
+func (n *HybridPolicyNetwork) recordGPU() {
+	n.mu.Lock()
+	n.stats.GPUCalls++
+	n.mu.Unlock()
+}
+
+func (n *HybridPolicyNetwork) recordCPU() {
+	n.mu.Lock()
+	n.stats.CPUCalls++
+	n.mu.Unlock()
+}
+
+// recordGPUFailure counts a failed GPU call and pauses GPU use for gpuRetryInterval
+func (n *HybridPolicyNetwork) recordGPUFailure(err error, timedOut bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.stats.GPUFailures++
+	if timedOut {
+		n.stats.GPUTimeouts++
+	}
+	n.gpuDownTill = time.Now().Add(gpuRetryInterval)
+	fmt.Printf("GPU inference failed, falling back to CPU for %s: %v\n", gpuRetryInterval, err)
+}
@@ -0,0 +1,104 @@
+package neural
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
+	pb "github.com/zachbeta/neural_rps/pkg/neural/proto"
+)
+
+func TestHybridPolicyNetworkFallsBackToCPU(t *testing.T) {
+	fallback := NewRPSPolicyNetwork(16)
+
+	// Nothing listens on port 1, so the hybrid network must serve from the CPU
+	network, err := NewHybridPolicyNetwork("127.0.0.1:1", fallback)
+	if err != nil {
+		t.Fatalf("Expected fallback instead of error, got %v", err)
+	}
+	defer network.Close()
+
+	g := game.NewRPSGame(21, 5, 10)
+	got := network.Predict(g)
+	want := fallback.Predict(g)
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d probabilities, got %d", len(want), len(got))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Expected probability %d to be %f, got %f", i, want[i], got[i])
+		}
+	}
+
+	batch := network.PredictFeaturesBatch([][]float64{fallback.EncodeState(g), fallback.EncodeState(g)})
+	if len(batch) != 2 {
+		t.Errorf("Expected 2 batch outputs, got %d", len(batch))
+	}
+
+	stats := network.GetBackendStats()
+	if stats.GPUAvailable {
+		t.Error("Expected GPU to be reported unavailable")
+	}
+	if stats.CPUCalls != 2 || stats.GPUCalls != 0 {
+		t.Errorf("Expected 2 CPU calls and 0 GPU calls, got %d and %d", stats.CPUCalls, stats.GPUCalls)
+	}
+}
+
+func TestHybridPolicyNetworkRequiresFallback(t *testing.T) {
+	if _, err := NewHybridPolicyNetwork("127.0.0.1:1", nil); err == nil {
+		t.Error("Expected error when no fallback network is given")
+	}
+}
+
+// stalledService describes an RPS policy model but never answers batch requests
+// before the caller gives up
+type stalledService struct {
+	pb.UnimplementedNeuralServiceServer
+}
+
+func (stalledService) GetModelInfo(ctx context.Context, req *pb.ModelInfoRequest) (*pb.ModelInfoResponse, error) {
+	return &pb.ModelInfoResponse{InputSize: int32(CurrentFeatureVersion.InputSize(3)), HiddenSize: 16, OutputSize: 9}, nil
+}
+
+func (stalledService) BatchPredict(ctx context.Context, req *pb.BatchPredictRequest) (*pb.BatchPredictResponse, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestHybridPolicyNetworkTimesOutToCPU(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	server := grpc.NewServer()
+	pb.RegisterNeuralServiceServer(server, stalledService{})
+	go server.Serve(listener)
+	defer server.Stop()
+
+	fallback := NewRPSPolicyNetwork(16)
+	network, err := NewHybridPolicyNetwork(listener.Addr().String(), fallback)
+	if err != nil {
+		t.Fatalf("Failed to create hybrid network: %v", err)
+	}
+	defer network.Close()
+	network.SetGPUTimeout(100 * time.Millisecond)
+
+	features := fallback.EncodeState(game.NewRPSGame(21, 5, 10))
+	start := time.Now()
+	outputs := network.PredictFeaturesBatch([][]float64{features, features})
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Batch took %s despite a 100ms timeout", elapsed)
+	}
+	if len(outputs) != 2 || len(outputs[0]) != 9 {
+		t.Fatalf("Expected 2 CPU outputs of 9 probabilities, got %v", outputs)
+	}
+
+	stats := network.GetBackendStats()
+	if stats.GPUTimeouts != 1 || stats.GPUFailures != 1 || stats.CPUCalls != 1 {
+		t.Errorf("Expected 1 timeout, 1 failure and 1 CPU call, got %+v", stats)
+	}
+}
@@ -0,0 +1,87 @@
+package neural
+
+import (
+	"time"
+
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
+)
+
+// InferenceProfile accumulates the time PredictProfiled spends in each stage
+// of a policy prediction, to show which stage is worth optimizing
+type InferenceProfile struct {
+	Calls int // Number of predictions profiled
+
+	Encode       time.Duration // Building the feature vector from the game
+	InputHidden  time.Duration // The input->hidden matrix multiply
+	ReLU         time.Duration // The hidden layer's activation, and batch norm if enabled
+	HiddenOutput time.Duration // The hidden->output matrix multiply
+	Softmax      time.Duration // Turning the logits into probabilities
+}
+
+// ProfileStage is one stage's share of an InferenceProfile
+type ProfileStage struct {
+	Name  string
+	Total time.Duration
+}
+
+// Stages returns the stages in the order a prediction runs them
+func (p *InferenceProfile) Stages() []ProfileStage {
+	return []ProfileStage{
+		{"Feature encoding", p.Encode},
+		{"Input->hidden matmul", p.InputHidden},
+		{"ReLU", p.ReLU},
+		{"Hidden->output matmul", p.HiddenOutput},
+		{"Softmax", p.Softmax},
+	}
+}
+
+// Total returns the time spent in all stages
+func (p *InferenceProfile) Total() time.Duration {
+	return p.Encode + p.InputHidden + p.ReLU + p.HiddenOutput + p.Softmax
+}
+
+// PredictProfiled returns the same probabilities as Predict, adding the time
+// spent in each stage to profile. It is a separate path so that Predict pays
+// nothing for the timing. profile is updated without locking, so concurrent
+// callers need one each.
+func (n *RPSPolicyNetwork) PredictProfiled(gameState *game.RPSGame, profile *InferenceProfile) []float64 {
+	start := time.Now()
+	input := n.EncodeState(gameState)
+	encoded := time.Now()
+
+	hidden := make([]float64, n.hiddenSize)
+	for i := 0; i < n.hiddenSize; i++ {
+		sum := n.biasesHidden[i]
+		for j := 0; j < n.inputSize; j++ {
+			sum += n.weightsInputHidden[i][j] * input[j]
+		}
+		hidden[i] = sum
+	}
+	multiplied := time.Now()
+
+	for i, h := range hidden {
+		hidden[i] = n.batchNorm.activate(i, h)
+	}
+	activated := time.Now()
+
+	output := make([]float64, n.outputSize)
+	for i := 0; i < n.outputSize; i++ {
+		sum := n.biasesOutput[i]
+		for j := 0; j < n.hiddenSize; j++ {
+			sum += n.weightsHiddenOutput[i][j] * hidden[j]
+		}
+		output[i] = sum
+	}
+	projected := time.Now()
+
+	probs := softmax(output)
+	done := time.Now()
+
+	profile.Calls++
+	profile.Encode += encoded.Sub(start)
+	profile.InputHidden += multiplied.Sub(encoded)
+	profile.ReLU += activated.Sub(multiplied)
+	profile.HiddenOutput += projected.Sub(activated)
+	profile.Softmax += done.Sub(projected)
+	return probs
+}
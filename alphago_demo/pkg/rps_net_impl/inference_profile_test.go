@@ -0,0 +1,44 @@
+package neural
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
+)
+
+func TestPredictProfiledMatchesPredict(t *testing.T) {
+	network := NewRPSPolicyNetwork(16)
+	rng := rand.New(rand.NewSource(1))
+	g := game.NewRPSGameSeeded(21, 5, 10, rng)
+
+	var profile InferenceProfile
+	calls := 0
+	for !g.IsGameOver() {
+		calls++
+		expected := network.Predict(g)
+		got := network.PredictProfiled(g, &profile)
+		for i := range expected {
+			if math.Abs(got[i]-expected[i]) > 1e-12 {
+				t.Fatalf("Output %d: profiled %v, Predict %v", i, got[i], expected[i])
+			}
+		}
+		moves := g.GetValidMoves()
+		g.MakeMove(moves[rng.Intn(len(moves))])
+	}
+
+	if profile.Calls != calls {
+		t.Errorf("Expected %d profiled calls, got %d", calls, profile.Calls)
+	}
+	var sum int64
+	for _, stage := range profile.Stages() {
+		if stage.Total < 0 {
+			t.Errorf("%s has negative time %v", stage.Name, stage.Total)
+		}
+		sum += int64(stage.Total)
+	}
+	if sum != int64(profile.Total()) {
+		t.Errorf("Stages sum to %d, Total is %v", sum, profile.Total())
+	}
+}
@@ -0,0 +1,85 @@
+package neural
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// InitScheme is how a network's initial weights are drawn. Every scheme
+// starts the biases at zero.
+type InitScheme int
+
+const (
+	// InitUniform draws weights uniformly from ±sqrt(2/(fanIn+fanOut)), a
+	// narrower form of Xavier whose variance is a third of it. Networks have
+	// always been initialized this way, so it is the default.
+	InitUniform InitScheme = iota
+
+	// InitXavier is Glorot's uniform initialization, ±sqrt(6/(fanIn+fanOut)),
+	// which keeps the variance of activations and gradients about equal
+	// across layers without ReLU
+	InitXavier
+
+	// InitHe draws weights from a normal distribution with variance
+	// 2/fanIn, which makes up for ReLU zeroing half of its inputs. It is the
+	// recommended scheme for these networks' ReLU hidden layer.
+	InitHe
+)
+
+// String returns the name ParseInitScheme accepts
+func (s InitScheme) String() string {
+	switch s {
+	case InitUniform:
+		return "uniform"
+	case InitXavier:
+		return "xavier"
+	case InitHe:
+		return "he"
+	default:
+		return fmt.Sprintf("InitScheme(%d)", int(s))
+	}
+}
+
+// ParseInitScheme returns the scheme named "uniform", "xavier" or "he"
+func ParseInitScheme(name string) (InitScheme, error) {
+	for _, s := range []InitScheme{InitUniform, InitXavier, InitHe} {
+		if s.String() == name {
+			return s, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown initialization scheme %q (want uniform, xavier or he)", name)
+}
+
+// initWeights returns a rows x cols weight matrix for a layer with cols inputs
+// and rows outputs, drawn from rng with the given scheme
+func initWeights(rows, cols int, scheme InitScheme, rng *rand.Rand) [][]float64 {
+	fanIn, fanOut := cols, rows
+	var draw func() float64
+	switch scheme {
+	case InitHe:
+		stddev := math.Sqrt(2.0 / float64(fanIn))
+		draw = func() float64 { return rng.NormFloat64() * stddev }
+	case InitXavier:
+		limit := math.Sqrt(6.0 / float64(fanIn+fanOut))
+		draw = func() float64 { return (rng.Float64()*2 - 1) * limit }
+	default:
+		limit := math.Sqrt(2.0 / float64(fanIn+fanOut))
+		draw = func() float64 { return (rng.Float64()*2 - 1) * limit }
+	}
+
+	weights := make([][]float64, rows)
+	for i := range weights {
+		weights[i] = make([]float64, cols)
+		for j := range weights[i] {
+			weights[i][j] = draw()
+		}
+	}
+	return weights
+}
+
+// unseededRNG returns an RNG seeded from the global source, for networks whose
+// initialization need not be reproducible
+func unseededRNG() *rand.Rand {
+	return rand.New(rand.NewSource(rand.Int63()))
+}
@@ -0,0 +1,62 @@
+package neural
+
+import (
+	"math"
+	"testing"
+)
+
+func TestInitSchemeWeightVariance(t *testing.T) {
+	for _, tc := range []struct {
+		scheme   InitScheme
+		variance func(fanIn, fanOut int) float64
+	}{
+		{InitUniform, func(fanIn, fanOut int) float64 { return 2.0 / float64(fanIn+fanOut) / 3 }},
+		{InitXavier, func(fanIn, fanOut int) float64 { return 2.0 / float64(fanIn+fanOut) }},
+		{InitHe, func(fanIn, fanOut int) float64 { return 2.0 / float64(fanIn) }},
+	} {
+		network := NewRPSPolicyNetworkWithInit(256, tc.scheme, 1)
+		layers := []struct {
+			name    string
+			weights [][]float64
+		}{
+			{"input->hidden", network.weightsInputHidden},
+			{"hidden->output", network.weightsHiddenOutput},
+		}
+		for _, layer := range layers {
+			fanOut, fanIn := len(layer.weights), len(layer.weights[0])
+			expected := tc.variance(fanIn, fanOut)
+			got := weightVariance(layer.weights)
+			// Over thousands of weights the sample variance is within a few percent
+			if math.Abs(got-expected) > 0.1*expected {
+				t.Errorf("%v %s variance = %.5f, expected %.5f", tc.scheme, layer.name, got, expected)
+			}
+		}
+	}
+}
+
+func TestParseInitScheme(t *testing.T) {
+	for _, s := range []InitScheme{InitUniform, InitXavier, InitHe} {
+		parsed, err := ParseInitScheme(s.String())
+		if err != nil || parsed != s {
+			t.Errorf("ParseInitScheme(%q) = %v, %v", s.String(), parsed, err)
+		}
+	}
+	if _, err := ParseInitScheme("gaussian"); err == nil {
+		t.Error("Expected an error for an unknown scheme")
+	}
+}
+
+// weightVariance returns the variance of the weights about their mean
+func weightVariance(weights [][]float64) float64 {
+	var sum, sumSquares float64
+	count := 0
+	for _, row := range weights {
+		for _, w := range row {
+			sum += w
+			sumSquares += w * w
+			count++
+		}
+	}
+	mean := sum / float64(count)
+	return sumSquares/float64(count) - mean*mean
+}
@@ -0,0 +1,12 @@
+package neural
+
+import "errors"
+
+// ErrBLASUnavailable is returned when BLAS inference is requested from a
+// build without the blas tag
+var ErrBLASUnavailable = errors.New("BLAS inference needs a build with -tags blas")
+
+// BLASAvailable reports whether this build can run batch inference with BLAS
+func BLASAvailable() bool {
+	return blasAvailable
+}
@@ -0,0 +1,57 @@
+//go:build blas
+// +build blas
+
+package neural
+
+import (
+	"gonum.org/v1/gonum/blas"
+	"gonum.org/v1/gonum/blas/blas64"
+)
+
+const blasAvailable = true
+
+// predictBatchBLAS lays the batch out as one row-major matrix and computes each
+// layer with a single gemm
+func (n *RPSPolicyNetwork) predictBatchBLAS(inputs [][]float64) [][]float64 {
+	batchSize := len(inputs)
+	if batchSize == 0 {
+		return [][]float64{}
+	}
+
+	input := rowMajor(inputs, n.inputSize)
+	hidden := repeatRows(n.biasesHidden, batchSize)
+	blas64.Gemm(blas.NoTrans, blas.Trans, 1, input, rowMajor(n.weightsInputHidden, n.inputSize), 1, hidden)
+	for i, h := range hidden.Data {
+		hidden.Data[i] = n.batchNorm.activate(i%n.hiddenSize, h)
+	}
+
+	output := repeatRows(n.biasesOutput, batchSize)
+	blas64.Gemm(blas.NoTrans, blas.Trans, 1, hidden, rowMajor(n.weightsHiddenOutput, n.hiddenSize), 1, output)
+
+	outputs := make([][]float64, batchSize)
+	for b := range outputs {
+		outputs[b] = softmax(output.Data[b*n.outputSize : (b+1)*n.outputSize])
+	}
+	return outputs
+}
+
+// rowMajor copies rows of length cols into a row-major matrix. The weights are
+// copied on every call rather than cached, so training and loading never
+// leave a stale copy behind.
+func rowMajor(rows [][]float64, cols int) blas64.General {
+	m := blas64.General{Rows: len(rows), Cols: cols, Stride: cols, Data: make([]float64, len(rows)*cols)}
+	for i, row := range rows {
+		copy(m.Data[i*cols:(i+1)*cols], row)
+	}
+	return m
+}
+
+// repeatRows returns a matrix with count copies of row, which gemm then adds
+// the layer's products to
+func repeatRows(row []float64, count int) blas64.General {
+	m := blas64.General{Rows: count, Cols: len(row), Stride: len(row), Data: make([]float64, count*len(row))}
+	for i := 0; i < count; i++ {
+		copy(m.Data[i*len(row):], row)
+	}
+	return m
+}
@@ -0,0 +1,12 @@
+//go:build !blas
+// +build !blas
+
+package neural
+
+const blasAvailable = false
+
+// predictBatchBLAS is never reached without the blas tag, because SetUseBLAS
+// refuses to enable it
+func (n *RPSPolicyNetwork) predictBatchBLAS(inputs [][]float64) [][]float64 {
+	return n.predictBatchLoop(inputs)
+}
@@ -0,0 +1,52 @@
+package neural
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
+)
+
+// Run with -tags blas to compare the BLAS path with the loop path
+func TestBLASMatchesLoop(t *testing.T) {
+	network := NewRPSPolicyNetwork(128)
+	if !BLASAvailable() {
+		if err := network.SetUseBLAS(true); err != ErrBLASUnavailable {
+			t.Fatalf("Expected ErrBLASUnavailable without the blas tag, got %v", err)
+		}
+		t.Skip("BLAS is not built in")
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	var inputs [][]float64
+	for len(inputs) < 64 {
+		g := game.NewRPSGameSeeded(21, 5, 10, rng)
+		for !g.IsGameOver() {
+			inputs = append(inputs, network.EncodeState(g))
+			moves := g.GetValidMoves()
+			g.MakeMove(moves[rng.Intn(len(moves))])
+		}
+	}
+
+	expected := network.PredictBatchFeatures(inputs)
+	if err := network.SetUseBLAS(true); err != nil {
+		t.Fatal(err)
+	}
+	got := network.PredictBatchFeatures(inputs)
+
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %d outputs, got %d", len(expected), len(got))
+	}
+	for b := range expected {
+		for i := range expected[b] {
+			if math.Abs(got[b][i]-expected[b][i]) > 1e-9 {
+				t.Fatalf("Input %d output %d: BLAS %v, loop %v", b, i, got[b][i], expected[b][i])
+			}
+		}
+	}
+
+	if empty := network.PredictBatchFeatures(nil); len(empty) != 0 {
+		t.Errorf("Expected no outputs for an empty batch, got %d", len(empty))
+	}
+}
@@ -0,0 +1,132 @@
+package neural
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ModelFormat identifies files written by SaveToFile
+const ModelFormat = "neural_rps-model"
+
+// ModelFormatVersion is the newest version of the model file format written
+// by SaveToFile. Files without a header are version 0 and load as before.
+// Version 2 added batch normalization, which older readers would ignore, so
+// networks without it are still written as version 1.
+const ModelFormatVersion = 2
+
+// NetworkType names the kind of network stored in a model file
+type NetworkType string
+
+const (
+	PolicyNetworkType NetworkType = "policy"
+	ValueNetworkType  NetworkType = "value"
+)
+
+// ModelMetadata records how a network was trained. Every field is optional.
+type ModelMetadata struct {
+	Games     int    `json:"games,omitempty"`     // Self-play games trained on
+	Epochs    int    `json:"epochs,omitempty"`    // Training epochs
+	Timestamp string `json:"timestamp,omitempty"` // When training finished, in RFC 3339 format
+}
+
+// ModelHeader describes the network in a model file. The header is stored in
+// the same JSON object as the weights, so headerless files written before it
+// existed still load; ReadModelHeader infers their type from the weights.
+type ModelHeader struct {
+	Format         string         `json:"format,omitempty"`
+	Version        int            `json:"version"`
+	NetworkType    NetworkType    `json:"networkType"`
+	InputSize      int            `json:"inputSize"`
+	FeatureVersion FeatureVersion `json:"featureVersion"` // Input encoding, BoardFeatureVersion if not saved
+	CanonicalInput bool           `json:"canonicalInput"` // Input encoded from the mover's perspective
+	HiddenSize     int            `json:"hiddenSize"`
+	OutputSize     int            `json:"outputSize"`
+	Activation     string         `json:"activation"` // Hidden layer activation
+	BatchNorm      bool           `json:"batchNorm"`  // Hidden layer batch-normalized before the activation
+	Metadata       ModelMetadata  `json:"metadata"`
+}
+
+// ReadModelHeader reads the header of a model file without loading its weights
+func ReadModelHeader(filename string) (ModelHeader, error) {
+	jsonData, err := os.ReadFile(filename)
+	if err != nil {
+		return ModelHeader{}, err
+	}
+	return parseModelHeader(jsonData)
+}
+
+// parseModelHeader decodes the header of a model file, filling in the type,
+// output size and activation for headerless files
+func parseModelHeader(jsonData []byte) (ModelHeader, error) {
+	var header ModelHeader
+	if err := json.Unmarshal(jsonData, &header); err != nil {
+		return ModelHeader{}, err
+	}
+
+	if header.Format == "" {
+		// Policy files store a bias per output and value files a single one
+		var keys map[string]json.RawMessage
+		if err := json.Unmarshal(jsonData, &keys); err != nil {
+			return ModelHeader{}, err
+		}
+		if _, ok := keys["biasesOutput"]; ok {
+			header.NetworkType = PolicyNetworkType
+		} else if _, ok := keys["biasOutput"]; ok {
+			header.NetworkType = ValueNetworkType
+			header.OutputSize = 1
+		} else {
+			return ModelHeader{}, errors.New("not a model file: no header and no output layer")
+		}
+		header.Activation = "relu"
+		return header, nil
+	}
+
+	if header.Format != ModelFormat {
+		return ModelHeader{}, fmt.Errorf("not a model file: format %q", header.Format)
+	}
+	if header.Version > ModelFormatVersion {
+		return ModelHeader{}, fmt.Errorf("model format version %d is newer than the supported version %d",
+			header.Version, ModelFormatVersion)
+	}
+	return header, nil
+}
+
+// loadModelFile reads a model file written for a network of type want,
+// returning its header and its contents for the network to load weights from
+func loadModelFile(filename string, want NetworkType) (ModelHeader, map[string]interface{}, error) {
+	jsonData, err := os.ReadFile(filename)
+	if err != nil {
+		return ModelHeader{}, nil, err
+	}
+
+	header, err := parseModelHeader(jsonData)
+	if err != nil {
+		return ModelHeader{}, nil, err
+	}
+	if header.NetworkType != want {
+		return ModelHeader{}, nil, fmt.Errorf("expected %s network, got %s network", want, header.NetworkType)
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal(jsonData, &data); err != nil {
+		return ModelHeader{}, nil, err
+	}
+	return header, data, nil
+}
+
+// addHeader adds the header fields that the weights do not already carry. The
+// file gets the oldest version that can hold it, so only batch-normalized
+// networks are version 2.
+func addHeader(data map[string]interface{}, networkType NetworkType, metadata ModelMetadata, batchNorm bool) {
+	version := 1
+	if batchNorm {
+		version = ModelFormatVersion
+	}
+	data["format"] = ModelFormat
+	data["version"] = version
+	data["networkType"] = networkType
+	data["activation"] = "relu"
+	data["metadata"] = metadata
+}
@@ -0,0 +1,152 @@
+package neural
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestModelHeaderRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	policyPath := filepath.Join(dir, "policy.model")
+	valuePath := filepath.Join(dir, "value.model")
+
+	metadata := ModelMetadata{Games: 100, Epochs: 5, Timestamp: "2025-05-11T16:33:34Z"}
+	policy := NewRPSPolicyNetwork(24)
+	policy.SetMetadata(metadata)
+	if err := policy.SaveToFile(policyPath); err != nil {
+		t.Fatalf("Failed to save policy network: %v", err)
+	}
+	if err := NewRPSValueNetwork(24).SaveToFile(valuePath); err != nil {
+		t.Fatalf("Failed to save value network: %v", err)
+	}
+
+	header, err := ReadModelHeader(policyPath)
+	if err != nil {
+		t.Fatalf("Failed to read policy header: %v", err)
+	}
+	want := ModelHeader{
+		Format:         ModelFormat,
+		Version:        1, // Without batch norm, readable before version 2
+		NetworkType:    PolicyNetworkType,
+		InputSize:      CurrentFeatureVersion.InputSize(3),
+		FeatureVersion: CurrentFeatureVersion,
+		HiddenSize:     24,
+		OutputSize:     9,
+		Activation:     "relu",
+		Metadata:       metadata,
+	}
+	if header != want {
+		t.Errorf("Policy header = %+v, want %+v", header, want)
+	}
+
+	header, err = ReadModelHeader(valuePath)
+	if err != nil {
+		t.Fatalf("Failed to read value header: %v", err)
+	}
+	if header.NetworkType != ValueNetworkType || header.OutputSize != 1 || header.Version != 1 {
+		t.Errorf("Value header = %+v, want a version 1 value network with one output", header)
+	}
+
+	loaded := NewRPSPolicyNetwork(8)
+	if err := loaded.LoadFromFile(policyPath); err != nil {
+		t.Fatalf("Failed to load policy network: %v", err)
+	}
+	if loaded.Metadata() != metadata {
+		t.Errorf("Loaded metadata = %+v, want %+v", loaded.Metadata(), metadata)
+	}
+}
+
+func TestLoadRejectsWrongNetworkType(t *testing.T) {
+	dir := t.TempDir()
+	policyPath := filepath.Join(dir, "policy.model")
+	valuePath := filepath.Join(dir, "value.model")
+	NewRPSPolicyNetwork(16).SaveToFile(policyPath)
+	NewRPSValueNetwork(16).SaveToFile(valuePath)
+
+	err := NewRPSPolicyNetwork(16).LoadFromFile(valuePath)
+	if err == nil || !strings.Contains(err.Error(), "expected policy network, got value network") {
+		t.Errorf("Loading a value file as a policy network: got %v", err)
+	}
+	err = NewRPSValueNetwork(16).LoadFromFile(policyPath)
+	if err == nil || !strings.Contains(err.Error(), "expected value network, got policy network") {
+		t.Errorf("Loading a policy file as a value network: got %v", err)
+	}
+
+	// Headerless files are checked by the shape of their output layer
+	stripHeader(t, valuePath)
+	err = NewRPSPolicyNetwork(16).LoadFromFile(valuePath)
+	if err == nil || !strings.Contains(err.Error(), "expected policy network, got value network") {
+		t.Errorf("Loading a headerless value file as a policy network: got %v", err)
+	}
+}
+
+func TestLoadHeaderlessModel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.model")
+	original := NewRPSPolicyNetwork(16)
+	original.SetCanonicalInput(true)
+	original.SaveToFile(path)
+	stripHeader(t, path)
+
+	header, err := ReadModelHeader(path)
+	if err != nil {
+		t.Fatalf("Failed to read headerless file: %v", err)
+	}
+	if header.Version != 0 || header.NetworkType != PolicyNetworkType || header.HiddenSize != 16 {
+		t.Errorf("Headerless header = %+v, want version 0 policy network with 16 hidden units", header)
+	}
+
+	loaded := NewRPSPolicyNetwork(8)
+	if err := loaded.LoadFromFile(path); err != nil {
+		t.Fatalf("Failed to load headerless file: %v", err)
+	}
+	if loaded.GetHiddenSize() != 16 || !loaded.UsesCanonicalInput() {
+		t.Errorf("Headerless file loaded with %d hidden units, canonical %v", loaded.GetHiddenSize(), loaded.UsesCanonicalInput())
+	}
+}
+
+func TestLoadRejectsNewerFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.model")
+	NewRPSPolicyNetwork(16).SaveToFile(path)
+	rewriteModel(t, path, func(data map[string]interface{}) {
+		data["version"] = ModelFormatVersion + 1
+	})
+
+	if err := NewRPSPolicyNetwork(16).LoadFromFile(path); err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Errorf("Loading a newer format: got %v", err)
+	}
+}
+
+// stripHeader rewrites a model file in the format used before it had a header
+func stripHeader(t *testing.T, path string) {
+	rewriteModel(t, path, func(data map[string]interface{}) {
+		for _, key := range []string{"format", "version", "networkType", "activation", "metadata"} {
+			delete(data, key)
+		}
+		if _, isValue := data["biasOutput"]; isValue {
+			delete(data, "outputSize")
+		}
+	})
+}
+
+// rewriteModel applies edit to the JSON object in a model file
+func rewriteModel(t *testing.T, path string, edit func(data map[string]interface{})) {
+	t.Helper()
+	jsonData, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+	var data map[string]interface{}
+	if err := json.Unmarshal(jsonData, &data); err != nil {
+		t.Fatalf("Failed to parse %s: %v", path, err)
+	}
+	edit(data)
+	if jsonData, err = json.Marshal(data); err != nil {
+		t.Fatalf("Failed to encode %s: %v", path, err)
+	}
+	if err := os.WriteFile(path, jsonData, 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
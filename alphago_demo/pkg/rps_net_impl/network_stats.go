@@ -1,6 +1,9 @@
 package neural
 
-import "fmt"
+import (
+	"fmt"
+	"math"
+)
 
 // NetworkStats represents statistics about a neural network's complexity
 type NetworkStats struct {
@@ -107,3 +110,84 @@ func DisplayNetworkComplexity(policyNetwork *RPSPolicyNetwork, valueNetwork *RPS
 	fmt.Printf("\nTotal model parameters: %d (%.2f KB)\n", totalParameters, totalMemory)
 	fmt.Println("===================================")
 }
+
+// LayerStats summarises the parameters of one layer of a network. Min, Max,
+// Mean and Std cover only the finite values; NaN and Inf count the others.
+type LayerStats struct {
+	Name  string
+	Count int
+	Min   float64
+	Max   float64
+	Mean  float64
+	Std   float64
+	NaN   int
+	Inf   int
+}
+
+// PolicyLayerStats returns statistics for each weight and bias layer of a policy network
+func PolicyLayerStats(network *RPSPolicyNetwork) []LayerStats {
+	return []LayerStats{
+		calculateLayerStats("input->hidden weights", flatten(network.weightsInputHidden)),
+		calculateLayerStats("hidden biases", network.biasesHidden),
+		calculateLayerStats("hidden->output weights", flatten(network.weightsHiddenOutput)),
+		calculateLayerStats("output biases", network.biasesOutput),
+	}
+}
+
+// ValueLayerStats returns statistics for each weight and bias layer of a value network
+func ValueLayerStats(network *RPSValueNetwork) []LayerStats {
+	return []LayerStats{
+		calculateLayerStats("input->hidden weights", flatten(network.weightsInputHidden)),
+		calculateLayerStats("hidden biases", network.biasesHidden),
+		calculateLayerStats("hidden->output weights", flatten(network.weightsHiddenOutput)),
+		calculateLayerStats("output biases", network.biasesOutput),
+	}
+}
+
+// calculateLayerStats computes the statistics of one layer's values
+func calculateLayerStats(name string, values []float64) LayerStats {
+	stats := LayerStats{Name: name, Count: len(values)}
+
+	finite := 0
+	sum := 0.0
+	for _, v := range values {
+		switch {
+		case math.IsNaN(v):
+			stats.NaN++
+			continue
+		case math.IsInf(v, 0):
+			stats.Inf++
+			continue
+		}
+		if finite == 0 || v < stats.Min {
+			stats.Min = v
+		}
+		if finite == 0 || v > stats.Max {
+			stats.Max = v
+		}
+		sum += v
+		finite++
+	}
+	if finite == 0 {
+		return stats
+	}
+
+	stats.Mean = sum / float64(finite)
+	variance := 0.0
+	for _, v := range values {
+		if !CheckForNaN(v) {
+			variance += (v - stats.Mean) * (v - stats.Mean)
+		}
+	}
+	stats.Std = math.Sqrt(variance / float64(finite))
+	return stats
+}
+
+// flatten joins the rows of a weight matrix
+func flatten(matrix [][]float64) []float64 {
+	var values []float64
+	for _, row := range matrix {
+		values = append(values, row...)
+	}
+	return values
+}
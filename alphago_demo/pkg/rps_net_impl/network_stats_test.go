@@ -1,6 +1,7 @@
 package neural
 
 import (
+	"math"
 	"strings"
 	"testing"
 )
@@ -14,8 +15,9 @@ func TestCalculatePolicyNetworkStats(t *testing.T) {
 	stats := CalculatePolicyNetworkStats(network)
 
 	// Test that the dimensions are correct
-	if stats.InputSize != 81 {
-		t.Errorf("Expected input size 81, got %d", stats.InputSize)
+	inputSize := CurrentFeatureVersion.InputSize(3)
+	if stats.InputSize != inputSize {
+		t.Errorf("Expected input size %d, got %d", inputSize, stats.InputSize)
 	}
 
 	if stats.HiddenSize != hiddenSize {
@@ -27,13 +29,13 @@ func TestCalculatePolicyNetworkStats(t *testing.T) {
 	}
 
 	// Test derived statistics
-	expectedTotalNeurons := 81 + hiddenSize + 9
+	expectedTotalNeurons := inputSize + hiddenSize + 9
 	if stats.TotalNeurons != expectedTotalNeurons {
 		t.Errorf("Expected total neurons %d, got %d", expectedTotalNeurons, stats.TotalNeurons)
 	}
 
 	// Input->Hidden + Hidden->Output connections
-	expectedConnections := (81 * hiddenSize) + (hiddenSize * 9)
+	expectedConnections := (inputSize * hiddenSize) + (hiddenSize * 9)
 	if stats.TotalConnections != expectedConnections {
 		t.Errorf("Expected total connections %d, got %d", expectedConnections, stats.TotalConnections)
 	}
@@ -59,8 +61,9 @@ func TestCalculateValueNetworkStats(t *testing.T) {
 	stats := CalculateValueNetworkStats(network)
 
 	// Test that the dimensions are correct
-	if stats.InputSize != 81 {
-		t.Errorf("Expected input size 81, got %d", stats.InputSize)
+	inputSize := CurrentFeatureVersion.InputSize(3)
+	if stats.InputSize != inputSize {
+		t.Errorf("Expected input size %d, got %d", inputSize, stats.InputSize)
 	}
 
 	if stats.HiddenSize != hiddenSize {
@@ -72,13 +75,13 @@ func TestCalculateValueNetworkStats(t *testing.T) {
 	}
 
 	// Test derived statistics
-	expectedTotalNeurons := 81 + hiddenSize + 1
+	expectedTotalNeurons := inputSize + hiddenSize + 1
 	if stats.TotalNeurons != expectedTotalNeurons {
 		t.Errorf("Expected total neurons %d, got %d", expectedTotalNeurons, stats.TotalNeurons)
 	}
 
 	// Input->Hidden + Hidden->Output connections
-	expectedConnections := (81 * hiddenSize) + (hiddenSize * 1)
+	expectedConnections := (inputSize * hiddenSize) + (hiddenSize * 1)
 	if stats.TotalConnections != expectedConnections {
 		t.Errorf("Expected total connections %d, got %d", expectedConnections, stats.TotalConnections)
 	}
@@ -136,3 +139,25 @@ func TestDisplayNetworkComplexity(t *testing.T) {
 	// This should not panic
 	DisplayNetworkComplexity(policyNetwork, valueNetwork)
 }
+
+func TestLayerStats(t *testing.T) {
+	stats := calculateLayerStats("layer", []float64{1, 3, math.NaN(), math.Inf(1), -1})
+	if stats.Count != 5 || stats.NaN != 1 || stats.Inf != 1 {
+		t.Errorf("Counts = %d total, %d NaN, %d Inf; want 5, 1, 1", stats.Count, stats.NaN, stats.Inf)
+	}
+	if stats.Min != -1 || stats.Max != 3 || stats.Mean != 1 {
+		t.Errorf("Min/Max/Mean = %v/%v/%v, want -1/3/1", stats.Min, stats.Max, stats.Mean)
+	}
+	if want := math.Sqrt(8.0 / 3.0); math.Abs(stats.Std-want) > 1e-12 {
+		t.Errorf("Std = %v, want %v", stats.Std, want)
+	}
+
+	network := NewRPSValueNetwork(16)
+	layers := ValueLayerStats(network)
+	counts := []int{network.inputSize * 16, 16, 16, 1}
+	for i, layer := range layers {
+		if layer.Count != counts[i] {
+			t.Errorf("%s has %d values, want %d", layer.Name, layer.Count, counts[i])
+		}
+	}
+}
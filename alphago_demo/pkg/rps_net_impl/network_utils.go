@@ -1,5 +1,7 @@
 package neural
 
+import "fmt"
+
 // CloneFloat64Slice makes a deep copy of a float64 slice
 func CloneFloat64Slice(s []float64) []float64 {
 	if s == nil {
@@ -34,6 +36,9 @@ func (n *RPSValueNetwork) Clone() *RPSValueNetwork {
 		biasesHidden:        CloneFloat64Slice(n.biasesHidden),
 		weightsHiddenOutput: CloneFloat64Matrix(n.weightsHiddenOutput),
 		biasesOutput:        CloneFloat64Slice(n.biasesOutput),
+		encoder:             n.encoder,
+		metadata:            n.metadata,
+		batchNorm:           n.batchNorm.clone(),
 	}
 
 	// Clone debug information if present
@@ -55,6 +60,10 @@ func (n *RPSPolicyNetwork) Clone() *RPSPolicyNetwork {
 		biasesHidden:        CloneFloat64Slice(n.biasesHidden),
 		weightsHiddenOutput: CloneFloat64Matrix(n.weightsHiddenOutput),
 		biasesOutput:        CloneFloat64Slice(n.biasesOutput),
+		encoder:             n.encoder,
+		metadata:            n.metadata,
+		useBLAS:             n.useBLAS,
+		batchNorm:           n.batchNorm.clone(),
 	}
 
 	// Clone debug information if present
@@ -65,3 +74,19 @@ func (n *RPSPolicyNetwork) Clone() *RPSPolicyNetwork {
 
 	return clone
 }
+
+// CopyWeightsFrom overwrites the network's weights and biases with those of
+// src, which must have the same layer sizes. Unlike SetWeights it includes the
+// biases and any batch norm, so the network then computes exactly what src does.
+func (n *RPSPolicyNetwork) CopyWeightsFrom(src *RPSPolicyNetwork) error {
+	if src.inputSize != n.inputSize || src.hiddenSize != n.hiddenSize || src.outputSize != n.outputSize {
+		return fmt.Errorf("cannot copy a %d-%d-%d network into a %d-%d-%d network",
+			src.inputSize, src.hiddenSize, src.outputSize, n.inputSize, n.hiddenSize, n.outputSize)
+	}
+	n.weightsInputHidden = CloneFloat64Matrix(src.weightsInputHidden)
+	n.biasesHidden = CloneFloat64Slice(src.biasesHidden)
+	n.weightsHiddenOutput = CloneFloat64Matrix(src.weightsHiddenOutput)
+	n.biasesOutput = CloneFloat64Slice(src.biasesOutput)
+	n.batchNorm = src.batchNorm.clone()
+	return nil
+}
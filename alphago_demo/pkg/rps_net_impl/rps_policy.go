@@ -10,6 +10,11 @@ import (
 )
 
 // RPSPolicyNetwork represents a neural network that predicts move probabilities for RPS
+//
+// Inference only reads the network and allocates its own buffers, so one
+// network can serve any number of goroutines. Training, loading and the Set
+// methods write to it and must not run alongside inference; train a Clone
+// instead.
 type RPSPolicyNetwork struct {
 	// Simple 2-layer neural network
 	inputSize  int
@@ -22,60 +27,117 @@ type RPSPolicyNetwork struct {
 	weightsHiddenOutput [][]float64
 	biasesOutput        []float64
 
+	// encoder turns positions into the input the network was built or loaded
+	// with, from the side to move's perspective if canonical is set
+	encoder featureEncoder
+
+	// metadata describes how the network was trained and is saved with it
+	metadata ModelMetadata
+
+	// batchNorm normalizes the hidden layer before the ReLU, or is nil
+	batchNorm *batchNorm
+
+	// useBLAS routes batch inference through BLAS matrix multiplies
+	useBLAS bool
+
 	// Debug information
 	DebugEpochCount []int
 }
 
-// NewRPSPolicyNetwork creates a new policy network for RPS
+// NewRPSPolicyNetwork creates a new policy network for the standard 3x3 board
 func NewRPSPolicyNetwork(hiddenSize int) *RPSPolicyNetwork {
-	// For RPS, the input size is 81 (9 positions * 9 features per position)
-	inputSize := 81
-	// The output is 9 positions (we'll select which card to play separately)
-	outputSize := 9
+	return NewRPSPolicyNetworkForBoard(hiddenSize, game.StandardBoardDim)
+}
+
+// NewRPSPolicyNetworkForBoard creates a policy network for a boardDim x boardDim board
+func NewRPSPolicyNetworkForBoard(hiddenSize int, boardDim int) *RPSPolicyNetwork {
+	return NewRPSPolicyNetworkWithFeatures(hiddenSize, boardDim, CurrentFeatureVersion)
+}
+
+// NewRPSPolicyNetworkWithFeatures creates a policy network for a boardDim x boardDim
+// board whose input is encoded with the given feature version, for example to
+// train on data encoded before the current version
+func NewRPSPolicyNetworkWithFeatures(hiddenSize int, boardDim int, version FeatureVersion) *RPSPolicyNetwork {
+	return newRPSPolicyNetwork(hiddenSize, boardDim, version, InitUniform, unseededRNG())
+}
+
+// NewRPSPolicyNetworkSeeded creates a policy network for the standard 3x3 board
+// whose initial weights depend only on seed, so experiments with untrained
+// networks can be repeated. The weights are drawn with InitUniform.
+func NewRPSPolicyNetworkSeeded(hiddenSize int, seed int64) *RPSPolicyNetwork {
+	return NewRPSPolicyNetworkWithInit(hiddenSize, InitUniform, seed)
+}
+
+// NewRPSPolicyNetworkWithInit creates a policy network for the standard 3x3 board
+// whose initial weights are drawn with scheme, seeded with seed
+func NewRPSPolicyNetworkWithInit(hiddenSize int, scheme InitScheme, seed int64) *RPSPolicyNetwork {
+	return newRPSPolicyNetwork(hiddenSize, game.StandardBoardDim, CurrentFeatureVersion, scheme, rand.New(rand.NewSource(seed)))
+}
+
+// newRPSPolicyNetwork creates a policy network with weights drawn from rng
+func newRPSPolicyNetwork(hiddenSize int, boardDim int, version FeatureVersion, scheme InitScheme, rng *rand.Rand) *RPSPolicyNetwork {
+	// The input is every position's features, then any game-progress features
+	inputSize := version.InputSize(boardDim)
+	// The output is one probability per position (we'll select which card to play separately)
+	outputSize := boardDim * boardDim
 
 	network := &RPSPolicyNetwork{
 		inputSize:  inputSize,
 		hiddenSize: hiddenSize,
 		outputSize: outputSize,
+		encoder:    featureEncoder{version: version, boardDim: boardDim},
 
-		weightsInputHidden:  make([][]float64, hiddenSize),
+		weightsInputHidden:  initWeights(hiddenSize, inputSize, scheme, rng),
 		biasesHidden:        make([]float64, hiddenSize),
-		weightsHiddenOutput: make([][]float64, outputSize),
+		weightsHiddenOutput: initWeights(outputSize, hiddenSize, scheme, rng),
 		biasesOutput:        make([]float64, outputSize),
 	}
 
-	// Initialize weights with Xavier initialization
-	xavierInput := math.Sqrt(2.0 / float64(inputSize+hiddenSize))
-	xavierHidden := math.Sqrt(2.0 / float64(hiddenSize+outputSize))
-
-	// Initialize input->hidden weights and biases
-	for i := 0; i < hiddenSize; i++ {
-		network.weightsInputHidden[i] = make([]float64, inputSize)
-		for j := 0; j < inputSize; j++ {
-			network.weightsInputHidden[i][j] = (rand.Float64()*2 - 1) * xavierInput
-		}
-		network.biasesHidden[i] = 0
-	}
-
-	// Initialize hidden->output weights and biases
-	for i := 0; i < outputSize; i++ {
-		network.weightsHiddenOutput[i] = make([]float64, hiddenSize)
-		for j := 0; j < hiddenSize; j++ {
-			network.weightsHiddenOutput[i][j] = (rand.Float64()*2 - 1) * xavierHidden
-		}
-		network.biasesOutput[i] = 0
-	}
-
 	return network
 }
 
 // Predict returns the position probabilities for a given game state
 func (n *RPSPolicyNetwork) Predict(gameState *game.RPSGame) []float64 {
-	// Convert game state to input features
-	input := gameState.GetBoardAsFeatures()
+	return n.PredictFeatures(n.EncodeState(gameState))
+}
 
-	// Forward pass through the network
-	return n.forward(input)
+// PredictFeatures returns move probabilities for a feature vector already
+// encoded with EncodeState. Callers that hold features, such as batched MCTS
+// and the supervised trainer, use it to skip rebuilding a game. It panics if
+// the features are not the size the network's encoder gives.
+func (n *RPSPolicyNetwork) PredictFeatures(features []float64) []float64 {
+	if len(features) != n.inputSize {
+		panic(fmt.Sprintf("policy network expects %d features (encoding version %d), got %d",
+			n.inputSize, n.encoder.version, len(features)))
+	}
+	return n.forward(features)
+}
+
+// PredictMasked returns the position probabilities for a game state with the
+// squares the player to move cannot play on excluded before the softmax, so
+// the result is a distribution over legal squares only. It is all zeros when
+// there is no legal move.
+func (n *RPSPolicyNetwork) PredictMasked(gameState *game.RPSGame) []float64 {
+	logits := n.logits(n.EncodeState(gameState))
+
+	legal := make([]bool, len(logits))
+	anyLegal := false
+	for _, move := range gameState.GetValidMoves() {
+		if move.Position >= 0 && move.Position < len(logits) {
+			legal[move.Position] = true
+			anyLegal = true
+		}
+	}
+	if !anyLegal {
+		return make([]float64, len(logits))
+	}
+
+	for pos := range logits {
+		if !legal[pos] {
+			logits[pos] = math.Inf(-1)
+		}
+	}
+	return softmax(logits)
 }
 
 // PredictMove returns the best move according to the policy network
@@ -86,8 +148,8 @@ func (n *RPSPolicyNetwork) PredictMove(gameState *game.RPSGame) game.RPSMove {
 		return game.RPSMove{} // No valid moves
 	}
 
-	// Get position probabilities
-	positionProbs := n.Predict(gameState)
+	// Get position probabilities over the legal squares
+	positionProbs := n.PredictMasked(gameState)
 
 	// Group moves by position
 	movesByPosition := make(map[int][]game.RPSMove)
@@ -96,11 +158,9 @@ func (n *RPSPolicyNetwork) PredictMove(gameState *game.RPSGame) game.RPSMove {
 	}
 
 	// Find the best position according to the policy network
-	bestPosition := 0
-	bestProb := positionProbs[0]
+	bestPosition := validMoves[0].Position
 	for pos, prob := range positionProbs {
-		if prob > bestProb && len(movesByPosition[pos]) > 0 {
-			bestProb = prob
+		if prob > positionProbs[bestPosition] {
 			bestPosition = pos
 		}
 	}
@@ -116,8 +176,27 @@ func (n *RPSPolicyNetwork) PredictMove(gameState *game.RPSGame) game.RPSMove {
 	return validMoves[0]
 }
 
+// PredictionEntropy returns the Shannon entropy, in nats, of the network's move
+// distribution restricted to the squares the player to move can play on. It is
+// 0 when the network is certain or only one square is legal, and at most the log
+// of the number of legal squares when the distribution is uniform.
+func (n *RPSPolicyNetwork) PredictionEntropy(g *game.RPSGame) float64 {
+	entropy := 0.0
+	for _, p := range n.PredictMasked(g) {
+		if p > 0 {
+			entropy -= p * math.Log(p)
+		}
+	}
+	return entropy
+}
+
 // forward performs a forward pass through the network
 func (n *RPSPolicyNetwork) forward(input []float64) []float64 {
+	return softmax(n.logits(input))
+}
+
+// logits returns the output layer's values before the softmax
+func (n *RPSPolicyNetwork) logits(input []float64) []float64 {
 	// Hidden layer activation
 	hidden := make([]float64, n.hiddenSize)
 	for i := 0; i < n.hiddenSize; i++ {
@@ -125,7 +204,7 @@ func (n *RPSPolicyNetwork) forward(input []float64) []float64 {
 		for j := 0; j < n.inputSize; j++ {
 			sum += n.weightsInputHidden[i][j] * input[j]
 		}
-		hidden[i] = relu(sum)
+		hidden[i] = n.batchNorm.activate(i, sum)
 	}
 
 	// Output layer
@@ -137,9 +216,81 @@ func (n *RPSPolicyNetwork) forward(input []float64) []float64 {
 		}
 		output[i] = sum
 	}
+	return output
+}
 
-	// Apply softmax to get probabilities
-	return softmax(output)
+// PredictBatch returns the position probabilities for each of the given game states
+func (n *RPSPolicyNetwork) PredictBatch(states []*game.RPSGame) [][]float64 {
+	inputs := make([][]float64, len(states))
+	for i, state := range states {
+		inputs[i] = n.EncodeState(state)
+	}
+	return n.PredictBatchFeatures(inputs)
+}
+
+// PredictBatchFeatures returns move probabilities for a batch of encoded
+// feature vectors, with BLAS if SetUseBLAS has enabled it
+func (n *RPSPolicyNetwork) PredictBatchFeatures(inputs [][]float64) [][]float64 {
+	if n.useBLAS {
+		return n.predictBatchBLAS(inputs)
+	}
+	return n.predictBatchLoop(inputs)
+}
+
+// SetUseBLAS chooses whether batch inference multiplies whole layers with
+// BLAS or loops over the weights. BLAS is only available in builds with the
+// blas tag; enabling it otherwise returns ErrBLASUnavailable.
+func (n *RPSPolicyNetwork) SetUseBLAS(use bool) error {
+	if use && !BLASAvailable() {
+		return ErrBLASUnavailable
+	}
+	n.useBLAS = use
+	return nil
+}
+
+// predictBatchLoop applies each weight row to the whole batch before moving
+// on, which keeps the weights hot in cache instead of reloading them for every
+// input
+func (n *RPSPolicyNetwork) predictBatchLoop(inputs [][]float64) [][]float64 {
+	batchSize := len(inputs)
+
+	// Hidden layer activations, one row per input
+	hidden := make([][]float64, batchSize)
+	for b := range hidden {
+		hidden[b] = make([]float64, n.hiddenSize)
+	}
+	for i := 0; i < n.hiddenSize; i++ {
+		weights := n.weightsInputHidden[i]
+		for b, input := range inputs {
+			sum := n.biasesHidden[i]
+			for j := 0; j < n.inputSize; j++ {
+				sum += weights[j] * input[j]
+			}
+			hidden[b][i] = n.batchNorm.activate(i, sum)
+		}
+	}
+
+	// Output layer
+	outputs := make([][]float64, batchSize)
+	for b := range outputs {
+		outputs[b] = make([]float64, n.outputSize)
+	}
+	for i := 0; i < n.outputSize; i++ {
+		weights := n.weightsHiddenOutput[i]
+		for b := range outputs {
+			sum := n.biasesOutput[i]
+			for j := 0; j < n.hiddenSize; j++ {
+				sum += weights[j] * hidden[b][j]
+			}
+			outputs[b][i] = sum
+		}
+	}
+
+	// Apply softmax to each row to get probabilities
+	for b := range outputs {
+		outputs[b] = softmax(outputs[b])
+	}
+	return outputs
 }
 
 // Train updates the network weights based on a batch of input features and target probabilities
@@ -149,6 +300,9 @@ func (n *RPSPolicyNetwork) Train(inputFeatures [][]float64, targetProbs [][]floa
 	if batchSize == 0 {
 		return 0
 	}
+	if n.batchNorm != nil {
+		return n.trainBatchNorm(inputFeatures, targetProbs, learningRate)
+	}
 
 	totalLoss := 0.0
 
@@ -229,18 +383,7 @@ func (n *RPSPolicyNetwork) Train(inputFeatures [][]float64, targetProbs [][]floa
 			outputGradients[i] = clipGradient(outputGradients[i], gradientThreshold)
 		}
 
-		// Update hidden->output weights and biases
-		for i := 0; i < n.outputSize; i++ {
-			for j := 0; j < n.hiddenSize; j++ {
-				update := learningRate * outputGradients[i] * hidden[j]
-				// Apply additional safety: clip the weight update
-				update = clipGradient(update, 0.1)
-				n.weightsHiddenOutput[i][j] -= update
-			}
-			n.biasesOutput[i] -= learningRate * outputGradients[i]
-		}
-
-		// Hidden layer gradients
+		// Hidden layer gradients, with the weights the forward pass used
 		hiddenGradients := make([]float64, n.hiddenSize)
 		for i := 0; i < n.hiddenSize; i++ {
 			for j := 0; j < n.outputSize; j++ {
@@ -254,6 +397,17 @@ func (n *RPSPolicyNetwork) Train(inputFeatures [][]float64, targetProbs [][]floa
 			hiddenGradients[i] = clipGradient(hiddenGradients[i], gradientThreshold)
 		}
 
+		// Update hidden->output weights and biases
+		for i := 0; i < n.outputSize; i++ {
+			for j := 0; j < n.hiddenSize; j++ {
+				update := learningRate * outputGradients[i] * hidden[j]
+				// Apply additional safety: clip the weight update
+				update = clipGradient(update, 0.1)
+				n.weightsHiddenOutput[i][j] -= update
+			}
+			n.biasesOutput[i] -= learningRate * outputGradients[i]
+		}
+
 		// Update input->hidden weights and biases
 		for i := 0; i < n.hiddenSize; i++ {
 			for j := 0; j < n.inputSize; j++ {
@@ -274,6 +428,8 @@ func (n *RPSPolicyNetwork) SaveToFile(filename string) error {
 	// Create a serializable representation of the network
 	data := map[string]interface{}{
 		"inputSize":           n.inputSize,
+		"canonicalInput":      n.encoder.canonical,
+		"featureVersion":      n.encoder.version,
 		"hiddenSize":          n.hiddenSize,
 		"outputSize":          n.outputSize,
 		"weightsInputHidden":  n.weightsInputHidden,
@@ -282,15 +438,17 @@ func (n *RPSPolicyNetwork) SaveToFile(filename string) error {
 		"biasesOutput":        n.biasesOutput,
 	}
 
+	n.batchNorm.save(data)
+	addHeader(data, PolicyNetworkType, n.metadata, n.batchNorm != nil)
+
 	// Marshal and save to file using the helper function
 	return saveToJSON(filename, data)
 }
 
 // LoadFromFile loads the network weights and biases from a file
 func (n *RPSPolicyNetwork) LoadFromFile(filename string) error {
-	// Load data from file
-	var data map[string]interface{}
-	err := loadFromJSON(filename, &data)
+	// Load data from file, checking it holds a network of this type
+	header, data, err := loadModelFile(filename, PolicyNetworkType)
 	if err != nil {
 		return err
 	}
@@ -304,13 +462,23 @@ func (n *RPSPolicyNetwork) LoadFromFile(filename string) error {
 		return errors.New("invalid network structure in file")
 	}
 
-	// Check compatibility
-	if int(inputSize) != n.inputSize || int(outputSize) != n.outputSize {
+	// The model may use an older feature encoding, but must be for the same board
+	encoder, err := headerEncoder(header)
+	if err != nil {
+		return err
+	}
+	if int(outputSize) != n.outputSize || encoder.squares() != n.outputSize {
 		return errors.New("incompatible network structure")
 	}
 
-	// Resize network if hidden size differs
-	if int(hiddenSize) != n.hiddenSize {
+	batchNorm, err := loadBatchNorm(header, data, int(hiddenSize))
+	if err != nil {
+		return err
+	}
+
+	// Resize network if the input or hidden size differs
+	if int(inputSize) != n.inputSize || int(hiddenSize) != n.hiddenSize {
+		n.inputSize = int(inputSize)
 		n.hiddenSize = int(hiddenSize)
 		n.weightsInputHidden = make([][]float64, n.hiddenSize)
 		n.biasesHidden = make([]float64, n.hiddenSize)
@@ -322,6 +490,10 @@ func (n *RPSPolicyNetwork) LoadFromFile(filename string) error {
 		}
 	}
 
+	n.encoder = encoder
+	n.metadata = header.Metadata
+	n.batchNorm = batchNorm
+
 	// Load weights and biases
 	loadWeightsMatrix(data["weightsInputHidden"], &n.weightsInputHidden)
 	loadWeightsVector(data["biasesHidden"], &n.biasesHidden)
@@ -331,11 +503,75 @@ func (n *RPSPolicyNetwork) LoadFromFile(filename string) error {
 	return nil
 }
 
+// SetCanonicalInput selects whether positions are encoded with
+// game.GetCanonicalFeatures (true) or game.GetBoardAsFeatures (false, the default).
+// The setting is saved with the model.
+func (n *RPSPolicyNetwork) SetCanonicalInput(canonical bool) {
+	n.encoder.canonical = canonical
+}
+
+// UsesCanonicalInput reports whether the network expects canonical features
+func (n *RPSPolicyNetwork) UsesCanonicalInput() bool {
+	return n.encoder.canonical
+}
+
+// SetBatchNorm adds batch normalization to the hidden layer, starting from
+// the identity, or removes it. Training then normalizes each batch with its
+// own statistics and inference with their running averages. The setting and
+// the learned statistics are saved with the model.
+func (n *RPSPolicyNetwork) SetBatchNorm(enabled bool) {
+	if !enabled {
+		n.batchNorm = nil
+	} else if n.batchNorm == nil {
+		n.batchNorm = newBatchNorm(n.hiddenSize)
+	}
+}
+
+// UsesBatchNorm reports whether the hidden layer is batch-normalized
+func (n *RPSPolicyNetwork) UsesBatchNorm() bool {
+	return n.batchNorm != nil
+}
+
+// SetMetadata records how the network was trained, to be saved with it
+func (n *RPSPolicyNetwork) SetMetadata(metadata ModelMetadata) {
+	n.metadata = metadata
+}
+
+// Metadata returns the training metadata set or loaded with the network
+func (n *RPSPolicyNetwork) Metadata() ModelMetadata {
+	return n.metadata
+}
+
+// EncodeState returns the input features the network expects for a game state
+func (n *RPSPolicyNetwork) EncodeState(gameState *game.RPSGame) []float64 {
+	return n.encoder.Encode(gameState)
+}
+
+// Encoder returns the encoder the network's input is built with
+func (n *RPSPolicyNetwork) Encoder() Encoder {
+	return n.encoder
+}
+
+// FeatureVersion returns the encoding the network's input uses
+func (n *RPSPolicyNetwork) FeatureVersion() FeatureVersion {
+	return n.encoder.version
+}
+
 // GetHiddenSize returns the hidden layer size
 func (n *RPSPolicyNetwork) GetHiddenSize() int {
 	return n.hiddenSize
 }
 
+// ParameterCount returns the number of weights and biases in the network,
+// with batch norm's scale and shift per hidden neuron if it has it
+func (n *RPSPolicyNetwork) ParameterCount() int {
+	count := n.hiddenSize*n.inputSize + n.hiddenSize + n.outputSize*n.hiddenSize + n.outputSize
+	if n.batchNorm != nil {
+		count += 2 * n.hiddenSize
+	}
+	return count
+}
+
 // GetWeights returns flattened network weights (input->hidden, hidden->output)
 func (n *RPSPolicyNetwork) GetWeights() []float64 {
 	total := n.hiddenSize*n.inputSize + n.outputSize*n.hiddenSize
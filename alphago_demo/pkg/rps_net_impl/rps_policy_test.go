@@ -1,9 +1,13 @@
 package neural
 
 import (
+	"bytes"
+	"errors"
+	"io"
 	"math"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
@@ -12,8 +16,9 @@ import (
 func TestNewRPSPolicyNetwork(t *testing.T) {
 	network := NewRPSPolicyNetwork(32)
 
-	if network.inputSize != 81 {
-		t.Errorf("Expected input size to be 81, got %d", network.inputSize)
+	// 81 board features and 4 game-progress features
+	if network.inputSize != 85 {
+		t.Errorf("Expected input size to be 85, got %d", network.inputSize)
 	}
 
 	if network.hiddenSize != 32 {
@@ -44,6 +49,34 @@ func TestNewRPSPolicyNetwork(t *testing.T) {
 		t.Errorf("Expected biasesOutput to have size %d, got %d",
 			network.outputSize, len(network.biasesOutput))
 	}
+
+	// 85*32 + 32 hidden weights and biases, 9*32 + 9 output weights and biases
+	if count := network.ParameterCount(); count != 3049 {
+		t.Errorf("Expected 3049 parameters, got %d", count)
+	}
+}
+
+func TestNewRPSPolicyNetworkSeeded(t *testing.T) {
+	a := NewRPSPolicyNetworkSeeded(32, 42)
+	b := NewRPSPolicyNetworkSeeded(32, 42)
+	if !equalFloats(a.GetWeights(), b.GetWeights()) {
+		t.Error("Expected networks with the same seed to have identical weights")
+	}
+
+	c := NewRPSPolicyNetworkSeeded(32, 43)
+	if equalFloats(a.GetWeights(), c.GetWeights()) {
+		t.Error("Expected networks with different seeds to have different weights")
+	}
+
+	// The weights must also lie within the initialization's bounds
+	limit := math.Sqrt(2.0 / float64(a.inputSize+a.hiddenSize))
+	for _, row := range a.weightsInputHidden {
+		for _, w := range row {
+			if math.Abs(w) > limit {
+				t.Fatalf("Input->hidden weight %v is outside ±%v", w, limit)
+			}
+		}
+	}
 }
 
 func TestRPSPolicyPredict(t *testing.T) {
@@ -111,6 +144,43 @@ func TestRPSPolicyPredictMove(t *testing.T) {
 	}
 }
 
+func TestRPSPolicyPredictBatch(t *testing.T) {
+	network := NewRPSPolicyNetwork(32)
+
+	// Build a batch of distinct positions by playing random moves
+	states := make([]*game.RPSGame, 0, 5)
+	g := game.NewRPSGame(21, 5, 10)
+	for i := 0; i < 5 && !g.IsGameOver(); i++ {
+		states = append(states, g.Copy())
+		move, err := g.GetRandomMove()
+		if err != nil {
+			t.Fatalf("Failed to get random move: %v", err)
+		}
+		g.MakeMove(move)
+	}
+
+	batch := network.PredictBatch(states)
+	if len(batch) != len(states) {
+		t.Fatalf("Expected %d batch outputs, got %d", len(states), len(batch))
+	}
+
+	for b, state := range states {
+		single := network.Predict(state)
+		if len(batch[b]) != len(single) {
+			t.Fatalf("Expected %d probabilities for state %d, got %d", len(single), b, len(batch[b]))
+		}
+		for i := range single {
+			if math.Abs(batch[b][i]-single[i]) > 1e-12 {
+				t.Errorf("State %d, position %d: batch %f != single %f", b, i, batch[b][i], single[i])
+			}
+		}
+	}
+
+	if empty := network.PredictBatchFeatures(nil); len(empty) != 0 {
+		t.Errorf("Expected empty output for empty batch, got %d rows", len(empty))
+	}
+}
+
 func TestRPSPolicyTrain(t *testing.T) {
 	network := NewRPSPolicyNetwork(16)
 
@@ -121,11 +191,11 @@ func TestRPSPolicyTrain(t *testing.T) {
 
 	// Initialize with some test data
 	for i := 0; i < batchSize; i++ {
-		inputFeatures[i] = make([]float64, 81)
+		inputFeatures[i] = make([]float64, network.inputSize)
 		targetProbs[i] = make([]float64, 9)
 
 		// Set some features
-		for j := 0; j < 81; j++ {
+		for j := 0; j < network.inputSize; j++ {
 			inputFeatures[i][j] = float64(j%3) * 0.1
 		}
 
@@ -227,11 +297,11 @@ func TestRPSPolicySaveLoadFile(t *testing.T) {
 	targetProbs := make([][]float64, 10)
 
 	for i := 0; i < 10; i++ {
-		inputFeatures[i] = make([]float64, 81)
+		inputFeatures[i] = make([]float64, originalNetwork.inputSize)
 		targetProbs[i] = make([]float64, 9)
 
 		// Fill with random data
-		for j := 0; j < 81; j++ {
+		for j := 0; j < originalNetwork.inputSize; j++ {
 			inputFeatures[i][j] = rand.Float64()
 		}
 
@@ -250,8 +320,8 @@ func TestRPSPolicySaveLoadFile(t *testing.T) {
 	originalNetwork.Train(inputFeatures, targetProbs, 0.01)
 
 	// Predict some values with original network
-	testInput := make([]float64, 81)
-	for i := 0; i < 81; i++ {
+	testInput := make([]float64, originalNetwork.inputSize)
+	for i := 0; i < originalNetwork.inputSize; i++ {
 		testInput[i] = rand.Float64()
 	}
 	originalPrediction := originalNetwork.forward(testInput)
@@ -297,3 +367,189 @@ func TestRPSPolicySaveLoadFile(t *testing.T) {
 		}
 	}
 }
+
+func TestSaveToFileKeepsOriginalOnFailedWrite(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "policy.model")
+
+	original := NewRPSPolicyNetwork(16)
+	if err := original.SaveToFile(path); err != nil {
+		t.Fatalf("Failed to save network: %v", err)
+	}
+	saved, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read saved network: %v", err)
+	}
+
+	// Fail after writing part of the replacement
+	errWrite := errors.New("disk full")
+	err = writeFileAtomic(path, func(w io.Writer) error {
+		w.Write(saved[:len(saved)/2])
+		return errWrite
+	})
+	if err != errWrite {
+		t.Fatalf("Expected the write error, got %v", err)
+	}
+
+	after, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read network after the failed write: %v", err)
+	}
+	if !bytes.Equal(after, saved) {
+		t.Errorf("Failed write changed the existing file")
+	}
+	if err := NewRPSPolicyNetwork(16).LoadFromFile(path); err != nil {
+		t.Errorf("Existing file no longer loads: %v", err)
+	}
+
+	// The temporary file is cleaned up
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Failed to list directory: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Expected only the model file to remain, found %d files", len(entries))
+	}
+
+	// A successful save replaces the file and keeps it readable by others
+	if err := NewRPSPolicyNetwork(32).SaveToFile(path); err != nil {
+		t.Fatalf("Failed to overwrite network: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Failed to stat network: %v", err)
+	}
+	if info.Mode().Perm() != 0644 {
+		t.Errorf("Saved file mode = %v, want 0644", info.Mode().Perm())
+	}
+	loaded := NewRPSPolicyNetwork(16)
+	if err := loaded.LoadFromFile(path); err != nil || loaded.GetHiddenSize() != 32 {
+		t.Errorf("Expected the overwritten network to load with 32 hidden units, got %d (%v)", loaded.GetHiddenSize(), err)
+	}
+}
+
+func TestRPSPolicyCanonicalInput(t *testing.T) {
+	network := NewRPSPolicyNetwork(16)
+	gameState := game.NewRPSGame(21, 5, 10)
+	move, _ := gameState.GetRandomMove()
+	gameState.MakeMove(move) // Player 2 to move, so the encodings differ
+
+	if network.UsesCanonicalInput() {
+		t.Fatal("Networks should default to absolute features")
+	}
+
+	network.SetCanonicalInput(true)
+	features := network.EncodeState(gameState)
+	expected := gameState.GetCanonicalFeatures()
+	for i := range expected {
+		if features[i] != expected[i] {
+			t.Fatalf("EncodeState differs from canonical features at %d", i)
+		}
+	}
+
+	// The setting must survive cloning and a save/load round trip
+	if !network.Clone().UsesCanonicalInput() {
+		t.Error("Clone lost the canonical input setting")
+	}
+
+	tmpPath := t.TempDir() + "/canonical.model"
+	if err := network.SaveToFile(tmpPath); err != nil {
+		t.Fatalf("Failed to save network: %v", err)
+	}
+	loaded := NewRPSPolicyNetwork(16)
+	if err := loaded.LoadFromFile(tmpPath); err != nil {
+		t.Fatalf("Failed to load network: %v", err)
+	}
+	if !loaded.UsesCanonicalInput() {
+		t.Error("Loaded network lost the canonical input setting")
+	}
+}
+
+func TestRPSPolicyNetworkForBoard(t *testing.T) {
+	policy := NewRPSPolicyNetworkForBoard(16, 4)
+	value := NewRPSValueNetworkForBoard(16, 4)
+	if policy.inputSize != 16*game.FeaturesPerPosition+game.ProgressFeatureCount || policy.outputSize != 16 {
+		t.Fatalf("Expected a 148-input, 16-output policy network, got %d inputs and %d outputs",
+			policy.inputSize, policy.outputSize)
+	}
+	if value.inputSize != 16*game.FeaturesPerPosition+game.ProgressFeatureCount {
+		t.Fatalf("Expected a 148-input value network, got %d inputs", value.inputSize)
+	}
+
+	state := game.NewRPSGameSized(4, 40, 8, 8, nil)
+	probs := policy.Predict(state)
+	if len(probs) != 16 {
+		t.Fatalf("Expected 16 move probabilities, got %d", len(probs))
+	}
+	if v := value.Predict(state); v < 0 || v > 1 {
+		t.Errorf("Value prediction %v is outside [0, 1]", v)
+	}
+}
+
+func TestRPSPolicyPredictionEntropy(t *testing.T) {
+	network := NewRPSPolicyNetwork(16)
+
+	// Every square is open at the start, so the entropy is bounded by log(9)
+	start := game.NewRPSGame(21, 5, 10)
+	entropy := network.PredictionEntropy(start)
+	if entropy <= 0 || entropy > math.Log(9)+1e-9 {
+		t.Errorf("Expected entropy in (0, log 9], got %v", entropy)
+	}
+
+	// With a single open square the choice is forced
+	forced := game.NewRPSGame(21, 5, 10)
+	for pos := 0; pos < 8; pos++ {
+		forced.Board[pos] = game.RPSCard{Type: game.Rock, Owner: game.Player2}
+	}
+	if entropy := network.PredictionEntropy(forced); entropy != 0 {
+		t.Errorf("Expected zero entropy with one legal square, got %v", entropy)
+	}
+
+	// No legal moves at all
+	forced.Board[8] = game.RPSCard{Type: game.Rock, Owner: game.Player2}
+	if entropy := network.PredictionEntropy(forced); entropy != 0 {
+		t.Errorf("Expected zero entropy with no legal moves, got %v", entropy)
+	}
+}
+
+func TestRPSPolicyPredictMasked(t *testing.T) {
+	network := NewRPSPolicyNetwork(16)
+	g := game.NewRPSGame(21, 5, 10)
+	for _, pos := range []int{0, 4, 7} {
+		g.Board[pos] = game.RPSCard{Type: game.Paper, Owner: game.Player2}
+	}
+
+	masked := network.PredictMasked(g)
+	unmasked := network.Predict(g)
+	legalTotal := 0.0
+	for pos, p := range unmasked {
+		if g.Board[pos].Owner == game.NoPlayer {
+			legalTotal += p
+		}
+	}
+
+	sum := 0.0
+	for pos, p := range masked {
+		sum += p
+		if g.Board[pos].Owner != game.NoPlayer {
+			if p != 0 {
+				t.Errorf("Occupied square %d has probability %v", pos, p)
+			}
+		} else if want := unmasked[pos] / legalTotal; math.Abs(p-want) > 1e-9 {
+			t.Errorf("Square %d has probability %v, want %v", pos, p, want)
+		}
+	}
+	if math.Abs(sum-1) > 1e-9 {
+		t.Errorf("Masked probabilities sum to %v, want 1", sum)
+	}
+
+	// No legal moves at all
+	for pos := range g.Board {
+		g.Board[pos] = game.RPSCard{Type: game.Rock, Owner: game.Player1}
+	}
+	for pos, p := range network.PredictMasked(g) {
+		if p != 0 {
+			t.Errorf("Square %d has probability %v with no legal moves", pos, p)
+		}
+	}
+}
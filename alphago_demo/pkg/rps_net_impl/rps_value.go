@@ -10,6 +10,11 @@ import (
 )
 
 // RPSValueNetwork represents a neural network that predicts the value of a position
+//
+// Inference only reads the network and allocates its own buffers, so one
+// network can serve any number of goroutines. Training, loading and the Set
+// methods write to it and must not run alongside inference; train a Clone
+// instead.
 type RPSValueNetwork struct {
 	// Simple 2-layer neural network
 	inputSize  int
@@ -22,59 +27,85 @@ type RPSValueNetwork struct {
 	weightsHiddenOutput [][]float64
 	biasesOutput        []float64
 
+	// encoder turns positions into the input the network was built or loaded
+	// with, from the side to move's perspective if canonical is set
+	encoder featureEncoder
+
+	// metadata describes how the network was trained and is saved with it
+	metadata ModelMetadata
+
+	// batchNorm normalizes the hidden layer before the ReLU, or is nil
+	batchNorm *batchNorm
+
 	// Debug information
 	DebugEpochCount []int
 }
 
-// NewRPSValueNetwork creates a new value network for RPS
+// NewRPSValueNetwork creates a new value network for the standard 3x3 board
 func NewRPSValueNetwork(hiddenSize int) *RPSValueNetwork {
-	// For RPS, the input size is 81 (9 positions * 9 features per position)
-	inputSize := 81
+	return NewRPSValueNetworkForBoard(hiddenSize, game.StandardBoardDim)
+}
+
+// NewRPSValueNetworkForBoard creates a value network for a boardDim x boardDim board
+func NewRPSValueNetworkForBoard(hiddenSize int, boardDim int) *RPSValueNetwork {
+	return NewRPSValueNetworkWithFeatures(hiddenSize, boardDim, CurrentFeatureVersion)
+}
+
+// NewRPSValueNetworkWithFeatures creates a value network for a boardDim x boardDim
+// board whose input is encoded with the given feature version, for example to
+// train on data encoded before the current version
+func NewRPSValueNetworkWithFeatures(hiddenSize int, boardDim int, version FeatureVersion) *RPSValueNetwork {
+	return newRPSValueNetwork(hiddenSize, boardDim, version, InitUniform, unseededRNG())
+}
+
+// NewRPSValueNetworkSeeded creates a value network for the standard 3x3 board
+// whose initial weights depend only on seed, so experiments with untrained
+// networks can be repeated. The weights are drawn with InitUniform.
+func NewRPSValueNetworkSeeded(hiddenSize int, seed int64) *RPSValueNetwork {
+	return NewRPSValueNetworkWithInit(hiddenSize, InitUniform, seed)
+}
+
+// NewRPSValueNetworkWithInit creates a value network for the standard 3x3 board
+// whose initial weights are drawn with scheme, seeded with seed
+func NewRPSValueNetworkWithInit(hiddenSize int, scheme InitScheme, seed int64) *RPSValueNetwork {
+	return newRPSValueNetwork(hiddenSize, game.StandardBoardDim, CurrentFeatureVersion, scheme, rand.New(rand.NewSource(seed)))
+}
+
+// newRPSValueNetwork creates a value network with weights drawn from rng
+func newRPSValueNetwork(hiddenSize int, boardDim int, version FeatureVersion, scheme InitScheme, rng *rand.Rand) *RPSValueNetwork {
+	// The input is every position's features, then any game-progress features
+	inputSize := version.InputSize(boardDim)
 	outputSize := 1
 
 	network := &RPSValueNetwork{
 		inputSize:  inputSize,
 		hiddenSize: hiddenSize,
 		outputSize: outputSize,
+		encoder:    featureEncoder{version: version, boardDim: boardDim},
 
-		weightsInputHidden:  make([][]float64, hiddenSize),
+		weightsInputHidden:  initWeights(hiddenSize, inputSize, scheme, rng),
 		biasesHidden:        make([]float64, hiddenSize),
-		weightsHiddenOutput: make([][]float64, outputSize),
+		weightsHiddenOutput: initWeights(outputSize, hiddenSize, scheme, rng),
 		biasesOutput:        make([]float64, outputSize),
 	}
 
-	// Initialize weights with Xavier initialization
-	xavierInput := math.Sqrt(2.0 / float64(inputSize+hiddenSize))
-	xavierHidden := math.Sqrt(2.0 / float64(hiddenSize+outputSize))
-
-	// Initialize input->hidden weights and biases
-	for i := 0; i < hiddenSize; i++ {
-		network.weightsInputHidden[i] = make([]float64, inputSize)
-		for j := 0; j < inputSize; j++ {
-			network.weightsInputHidden[i][j] = (rand.Float64()*2 - 1) * xavierInput
-		}
-		network.biasesHidden[i] = 0
-	}
-
-	// Initialize hidden->output weights and biases
-	for i := 0; i < outputSize; i++ {
-		network.weightsHiddenOutput[i] = make([]float64, hiddenSize)
-		for j := 0; j < hiddenSize; j++ {
-			network.weightsHiddenOutput[i][j] = (rand.Float64()*2 - 1) * xavierHidden
-		}
-		network.biasesOutput[i] = 0
-	}
-
 	return network
 }
 
 // Predict returns the value (win probability) for a given game state
 func (n *RPSValueNetwork) Predict(gameState *game.RPSGame) float64 {
-	// Convert game state to input features
-	input := gameState.GetBoardAsFeatures()
+	return n.PredictFeatures(n.EncodeState(gameState))
+}
 
-	// Forward pass through the network
-	return n.forward(input)
+// PredictFeatures returns the value for a feature vector already encoded with
+// EncodeState. It panics if the features are not the size the network's
+// encoder gives.
+func (n *RPSValueNetwork) PredictFeatures(features []float64) float64 {
+	if len(features) != n.inputSize {
+		panic(fmt.Sprintf("value network expects %d features (encoding version %d), got %d",
+			n.inputSize, n.encoder.version, len(features)))
+	}
+	return n.forward(features)
 }
 
 // forward performs a forward pass through the network
@@ -86,7 +117,7 @@ func (n *RPSValueNetwork) forward(input []float64) float64 {
 		for j := 0; j < n.inputSize; j++ {
 			sum += n.weightsInputHidden[i][j] * input[j]
 		}
-		hidden[i] = relu(sum)
+		hidden[i] = n.batchNorm.activate(i, sum)
 	}
 
 	// Output layer
@@ -106,6 +137,9 @@ func (n *RPSValueNetwork) Train(inputFeatures [][]float64, targetValues []float6
 	if batchSize == 0 {
 		return 0
 	}
+	if n.batchNorm != nil {
+		return n.trainBatchNorm(inputFeatures, targetValues, learningRate)
+	}
 
 	totalLoss := 0.0
 
@@ -172,16 +206,7 @@ func (n *RPSValueNetwork) Train(inputFeatures [][]float64, targetValues []float6
 		// Apply gradient clipping
 		outputGradient = clipGradient(outputGradient, gradientThreshold)
 
-		// Update hidden->output weights and bias
-		for i := 0; i < n.hiddenSize; i++ {
-			update := learningRate * outputGradient * hidden[i]
-			// Apply additional safety: clip the weight update
-			update = clipGradient(update, 0.1)
-			n.weightsHiddenOutput[0][i] -= update
-		}
-		n.biasesOutput[0] -= learningRate * outputGradient
-
-		// Hidden layer gradients
+		// Hidden layer gradients, with the weights the forward pass used
 		hiddenGradients := make([]float64, n.hiddenSize)
 		for i := 0; i < n.hiddenSize; i++ {
 			hiddenGradients[i] = outputGradient * n.weightsHiddenOutput[0][i]
@@ -193,6 +218,15 @@ func (n *RPSValueNetwork) Train(inputFeatures [][]float64, targetValues []float6
 			hiddenGradients[i] = clipGradient(hiddenGradients[i], gradientThreshold)
 		}
 
+		// Update hidden->output weights and bias
+		for i := 0; i < n.hiddenSize; i++ {
+			update := learningRate * outputGradient * hidden[i]
+			// Apply additional safety: clip the weight update
+			update = clipGradient(update, 0.1)
+			n.weightsHiddenOutput[0][i] -= update
+		}
+		n.biasesOutput[0] -= learningRate * outputGradient
+
 		// Update input->hidden weights and biases
 		for i := 0; i < n.hiddenSize; i++ {
 			for j := 0; j < n.inputSize; j++ {
@@ -213,22 +247,27 @@ func (n *RPSValueNetwork) SaveToFile(filename string) error {
 	// Create a serializable representation of the network
 	data := map[string]interface{}{
 		"inputSize":           n.inputSize,
+		"canonicalInput":      n.encoder.canonical,
+		"featureVersion":      n.encoder.version,
 		"hiddenSize":          n.hiddenSize,
+		"outputSize":          n.outputSize,
 		"weightsInputHidden":  n.weightsInputHidden,
 		"biasesHidden":        n.biasesHidden,
 		"weightsHiddenOutput": n.weightsHiddenOutput,
 		"biasOutput":          n.biasesOutput[0],
 	}
 
+	n.batchNorm.save(data)
+	addHeader(data, ValueNetworkType, n.metadata, n.batchNorm != nil)
+
 	// Marshal and save to file using the helper function
 	return saveToJSON(filename, data)
 }
 
 // LoadFromFile loads the network weights and biases from a file
 func (n *RPSValueNetwork) LoadFromFile(filename string) error {
-	// Load data from file
-	var data map[string]interface{}
-	err := loadFromJSON(filename, &data)
+	// Load data from file, checking it holds a network of this type
+	header, data, err := loadModelFile(filename, ValueNetworkType)
 	if err != nil {
 		return err
 	}
@@ -241,13 +280,23 @@ func (n *RPSValueNetwork) LoadFromFile(filename string) error {
 		return errors.New("invalid network structure in file")
 	}
 
-	// Check compatibility
-	if int(inputSize) != n.inputSize {
+	// The model may use an older feature encoding, but must be for the same board
+	encoder, err := headerEncoder(header)
+	if err != nil {
+		return err
+	}
+	if encoder.squares() != n.encoder.squares() {
 		return errors.New("incompatible network structure")
 	}
 
-	// Resize network if hidden size differs
-	if int(hiddenSize) != n.hiddenSize {
+	batchNorm, err := loadBatchNorm(header, data, int(hiddenSize))
+	if err != nil {
+		return err
+	}
+
+	// Resize network if the input or hidden size differs
+	if int(inputSize) != n.inputSize || int(hiddenSize) != n.hiddenSize {
+		n.inputSize = int(inputSize)
 		n.hiddenSize = int(hiddenSize)
 		n.weightsInputHidden = make([][]float64, n.hiddenSize)
 		n.biasesHidden = make([]float64, n.hiddenSize)
@@ -261,6 +310,10 @@ func (n *RPSValueNetwork) LoadFromFile(filename string) error {
 		n.biasesOutput = make([]float64, n.outputSize)
 	}
 
+	n.encoder = encoder
+	n.metadata = header.Metadata
+	n.batchNorm = batchNorm
+
 	// Load weights and biases
 	loadWeightsMatrix(data["weightsInputHidden"], &n.weightsInputHidden)
 	loadWeightsVector(data["biasesHidden"], &n.biasesHidden)
@@ -274,11 +327,75 @@ func (n *RPSValueNetwork) LoadFromFile(filename string) error {
 	return nil
 }
 
+// SetCanonicalInput selects whether positions are encoded with
+// game.GetCanonicalFeatures (true) or game.GetBoardAsFeatures (false, the default).
+// The setting is saved with the model.
+func (n *RPSValueNetwork) SetCanonicalInput(canonical bool) {
+	n.encoder.canonical = canonical
+}
+
+// UsesCanonicalInput reports whether the network expects canonical features
+func (n *RPSValueNetwork) UsesCanonicalInput() bool {
+	return n.encoder.canonical
+}
+
+// SetBatchNorm adds batch normalization to the hidden layer, starting from
+// the identity, or removes it. Training then normalizes each batch with its
+// own statistics and inference with their running averages. The setting and
+// the learned statistics are saved with the model.
+func (n *RPSValueNetwork) SetBatchNorm(enabled bool) {
+	if !enabled {
+		n.batchNorm = nil
+	} else if n.batchNorm == nil {
+		n.batchNorm = newBatchNorm(n.hiddenSize)
+	}
+}
+
+// UsesBatchNorm reports whether the hidden layer is batch-normalized
+func (n *RPSValueNetwork) UsesBatchNorm() bool {
+	return n.batchNorm != nil
+}
+
+// SetMetadata records how the network was trained, to be saved with it
+func (n *RPSValueNetwork) SetMetadata(metadata ModelMetadata) {
+	n.metadata = metadata
+}
+
+// Metadata returns the training metadata set or loaded with the network
+func (n *RPSValueNetwork) Metadata() ModelMetadata {
+	return n.metadata
+}
+
+// EncodeState returns the input features the network expects for a game state
+func (n *RPSValueNetwork) EncodeState(gameState *game.RPSGame) []float64 {
+	return n.encoder.Encode(gameState)
+}
+
+// Encoder returns the encoder the network's input is built with
+func (n *RPSValueNetwork) Encoder() Encoder {
+	return n.encoder
+}
+
+// FeatureVersion returns the encoding the network's input uses
+func (n *RPSValueNetwork) FeatureVersion() FeatureVersion {
+	return n.encoder.version
+}
+
 // GetHiddenSize returns the hidden layer size
 func (n *RPSValueNetwork) GetHiddenSize() int {
 	return n.hiddenSize
 }
 
+// ParameterCount returns the number of weights and biases in the network,
+// with batch norm's scale and shift per hidden neuron if it has it
+func (n *RPSValueNetwork) ParameterCount() int {
+	count := n.hiddenSize*n.inputSize + n.hiddenSize + n.outputSize*n.hiddenSize + n.outputSize
+	if n.batchNorm != nil {
+		count += 2 * n.hiddenSize
+	}
+	return count
+}
+
 // GetWeights returns flattened network weights (input->hidden, hidden->output)
 func (n *RPSValueNetwork) GetWeights() []float64 {
 	total := n.hiddenSize*n.inputSize + n.outputSize*n.hiddenSize
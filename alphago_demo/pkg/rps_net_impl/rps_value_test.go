@@ -13,8 +13,9 @@ func TestNewRPSValueNetwork(t *testing.T) {
 	network := NewRPSValueNetwork(64)
 
 	// Check network structure
-	if network.inputSize != 81 {
-		t.Errorf("Expected input size 81, got %d", network.inputSize)
+	// 81 board features and 4 game-progress features
+	if network.inputSize != 85 {
+		t.Errorf("Expected input size 85, got %d", network.inputSize)
 	}
 
 	if network.hiddenSize != 64 {
@@ -41,6 +42,24 @@ func TestNewRPSValueNetwork(t *testing.T) {
 	if len(network.biasesOutput) != 1 {
 		t.Errorf("Expected 1 output bias, got %d", len(network.biasesOutput))
 	}
+
+	// 85*64 + 64 hidden weights and biases, 64 + 1 output weights and bias
+	if count := network.ParameterCount(); count != 5569 {
+		t.Errorf("Expected 5569 parameters, got %d", count)
+	}
+}
+
+func TestNewRPSValueNetworkSeeded(t *testing.T) {
+	a := NewRPSValueNetworkSeeded(32, 42)
+	b := NewRPSValueNetworkSeeded(32, 42)
+	if !equalFloats(a.GetWeights(), b.GetWeights()) {
+		t.Error("Expected networks with the same seed to have identical weights")
+	}
+
+	c := NewRPSValueNetworkSeeded(32, 43)
+	if equalFloats(a.GetWeights(), c.GetWeights()) {
+		t.Error("Expected networks with different seeds to have different weights")
+	}
 }
 
 func TestRPSValuePredict(t *testing.T) {
@@ -62,9 +81,9 @@ func TestRPSValueTrain(t *testing.T) {
 	targetValues := make([]float64, 10)
 
 	for i := 0; i < 10; i++ {
-		inputFeatures[i] = make([]float64, 81)
+		inputFeatures[i] = make([]float64, network.inputSize)
 		// Fill with random values
-		for j := 0; j < 81; j++ {
+		for j := 0; j < network.inputSize; j++ {
 			inputFeatures[i][j] = rand.Float64()*2 - 1
 		}
 		// Target between 0 and 1
@@ -109,9 +128,9 @@ func TestRPSValueSaveLoadFile(t *testing.T) {
 	targetValues := make([]float64, 10)
 
 	for i := 0; i < 10; i++ {
-		inputFeatures[i] = make([]float64, 81)
+		inputFeatures[i] = make([]float64, originalNetwork.inputSize)
 		// Fill with random data
-		for j := 0; j < 81; j++ {
+		for j := 0; j < originalNetwork.inputSize; j++ {
 			inputFeatures[i][j] = rand.Float64()
 		}
 		// Make target a value between 0 and 1
@@ -122,8 +141,8 @@ func TestRPSValueSaveLoadFile(t *testing.T) {
 	originalNetwork.Train(inputFeatures, targetValues, 0.01)
 
 	// Predict a value with original network
-	testInput := make([]float64, 81)
-	for i := 0; i < 81; i++ {
+	testInput := make([]float64, originalNetwork.inputSize)
+	for i := 0; i < originalNetwork.inputSize; i++ {
 		testInput[i] = rand.Float64()
 	}
 	originalPrediction := originalNetwork.forward(testInput)
@@ -197,8 +216,8 @@ func TestRPSValueForward(t *testing.T) {
 	network := NewRPSValueNetwork(16)
 
 	// Create a test input
-	input := make([]float64, 81)
-	for i := 0; i < 81; i++ {
+	input := make([]float64, network.inputSize)
+	for i := 0; i < network.inputSize; i++ {
 		input[i] = float64(i%3) * 0.1
 	}
 
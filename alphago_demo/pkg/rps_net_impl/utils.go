@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"math"
 	"os"
+	"path/filepath"
 )
 
 // Helper functions for activation
@@ -53,16 +55,39 @@ func saveToJSON(filename string, data interface{}) error {
 		return err
 	}
 
-	return os.WriteFile(filename, jsonData, 0644)
+	return writeFileAtomic(filename, func(w io.Writer) error {
+		_, err := w.Write(jsonData)
+		return err
+	})
 }
 
-func loadFromJSON(filename string, data interface{}) error {
-	jsonData, err := os.ReadFile(filename)
+// writeFileAtomic replaces filename with what write produces. The data goes to
+// a temporary file in the same directory, which is renamed over filename only
+// once it is completely written, so an error or a crash part way through
+// leaves any existing file untouched rather than truncated.
+func writeFileAtomic(filename string, write func(w io.Writer) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".tmp*")
 	if err != nil {
 		return err
 	}
+	defer os.Remove(tmp.Name()) // Fails harmlessly once the file is renamed
 
-	return json.Unmarshal(jsonData, data)
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	// CreateTemp makes the file readable by its owner only
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filename)
 }
 
 // Helper functions for loading weights from JSON data
@@ -160,7 +185,9 @@ type PolicyNetwork interface {
 
 // LoadPolicyNetwork loads a policy network from a file
 func LoadPolicyNetwork(filename string) (*RPSPolicyNetwork, error) {
-	network := &RPSPolicyNetwork{}
+	// LoadFromFile only accepts files matching the network's input and output
+	// sizes, so start from a standard network and let it resize the hidden layer
+	network := NewRPSPolicyNetwork(1)
 	err := network.LoadFromFile(filename)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load policy network: %v", err)
@@ -0,0 +1,365 @@
+package tournament
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// BayesEloPriorDraws is the number of virtual draws each agent is given,
+// shared among its opponents, by ComputeBayesElo. The prior keeps the rating
+// of an agent that won or lost every game finite.
+const BayesEloPriorDraws = 2.0
+
+// eloScale converts Elo points to the natural logistic scale
+const eloScale = math.Ln10 / 400
+
+// BayesRating is an agent's rating fitted to all of a tournament's results
+type BayesRating struct {
+	Name  string
+	Games int
+
+	Elo     float64 // The agents that played average DefaultElo
+	EloLow  float64 // 95% confidence interval, relative to the average agent
+	EloHigh float64
+}
+
+// BayesEloResult holds the ratings fitted by ComputeBayesElo
+type BayesEloResult struct {
+	Ratings []BayesRating // Highest rated first
+
+	// DrawElo is the fitted draw parameter: a win needs a rating advantage of
+	// DrawElo more than a draw, so higher values mean more drawn games. Two
+	// equal agents draw with probability 1 - 2*ExpectedScore(0, DrawElo).
+	DrawElo float64
+}
+
+// bayesPair is the aggregate result of one pair of agents, from the first
+// agent's point of view, with the prior's virtual draws included in draw
+type bayesPair struct {
+	i, j               int
+	wins, losses, draw float64
+}
+
+// ComputeBayesElo fits every agent's rating at once to the head-to-head
+// results, in the manner of BayesElo: a Bradley-Terry model with a draw
+// parameter, whose maximum-likelihood ratings do not depend on the order the
+// games were played in, unlike the incremental updates of UpdateElo.
+// Agents that played no games are given DefaultElo and an unbounded interval.
+// It returns an error if no games were played or the agents that played
+// cannot all be compared through a chain of opponents.
+func (tm *TournamentManager) ComputeBayesElo() (*BayesEloResult, error) {
+	// Index the agents that played, counting their games and opponents
+	index := make(map[string]int)
+	var names []string
+	var games, opponents []int
+	var pairs []bayesPair
+	for a, agent1 := range tm.Agents {
+		for b := a + 1; b < len(tm.Agents); b++ {
+			name1, name2 := agent1.Name(), tm.Agents[b].Name()
+			record := tm.GameResults[name1][name2]
+			if record == nil || record.Wins+record.Losses+record.Draws == 0 {
+				continue
+			}
+			for _, name := range []string{name1, name2} {
+				if _, ok := index[name]; !ok {
+					index[name] = len(names)
+					names = append(names, name)
+					games = append(games, 0)
+					opponents = append(opponents, 0)
+				}
+			}
+			i, j := index[name1], index[name2]
+			n := record.Wins + record.Losses + record.Draws
+			games[i] += n
+			games[j] += n
+			opponents[i]++
+			opponents[j]++
+			pairs = append(pairs, bayesPair{i: i, j: j,
+				wins: float64(record.Wins), losses: float64(record.Losses), draw: float64(record.Draws)})
+		}
+	}
+	if len(pairs) == 0 {
+		return nil, errors.New("no games have been played")
+	}
+	if !connected(len(names), pairs) {
+		return nil, errors.New("the agents do not all share a chain of opponents, so their ratings cannot be compared")
+	}
+	for k := range pairs {
+		p := &pairs[k]
+		p.draw += BayesEloPriorDraws/2/float64(opponents[p.i]) + BayesEloPriorDraws/2/float64(opponents[p.j])
+	}
+
+	ratings, drawElo, err := fitBayesElo(len(names), pairs)
+	if err != nil {
+		return nil, err
+	}
+	covariance, err := bayesCovariance(ratings, drawElo, pairs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &BayesEloResult{DrawElo: drawElo}
+	for i, name := range names {
+		margin := Z95 * math.Sqrt(math.Max(covariance[i][i], 0))
+		elo := DefaultElo + ratings[i]
+		result.Ratings = append(result.Ratings, BayesRating{
+			Name: name, Games: games[i], Elo: elo, EloLow: elo - margin, EloHigh: elo + margin,
+		})
+	}
+	for _, agent := range tm.Agents {
+		if _, ok := index[agent.Name()]; !ok {
+			result.Ratings = append(result.Ratings, BayesRating{
+				Name: agent.Name(), Elo: DefaultElo, EloLow: math.Inf(-1), EloHigh: math.Inf(1),
+			})
+		}
+	}
+	sort.SliceStable(result.Ratings, func(a, b int) bool {
+		return result.Ratings[a].Elo > result.Ratings[b].Elo
+	})
+	return result, nil
+}
+
+// Print displays the ratings with their confidence intervals
+func (r *BayesEloResult) Print() {
+	fmt.Printf("%-4s %-30s %-6s %-15s %-6s\n", "Rank", "Agent", "ELO", "95% CI", "Games")
+	fmt.Println(strings.Repeat("-", 72))
+	for i, rating := range r.Ratings {
+		interval := fmt.Sprintf("%.0f to %.0f", rating.EloLow, rating.EloHigh)
+		fmt.Printf("%-4d %-30s %-6.0f %-15s %-6d\n", i+1, rating.Name, rating.Elo, interval, rating.Games)
+	}
+	fmt.Printf("Draw parameter: %.0f\n", r.DrawElo)
+}
+
+// connected reports whether the pairs link all n agents
+func connected(n int, pairs []bayesPair) bool {
+	parent := make([]int, n)
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	components := n
+	for _, p := range pairs {
+		if a, b := find(p.i), find(p.j); a != b {
+			parent[a] = b
+			components--
+		}
+	}
+	return components == 1
+}
+
+// pairProbabilities returns the chances that the first agent of a pair wins,
+// loses and draws, given its rating advantage delta and the draw parameter
+func pairProbabilities(delta, drawElo float64) (win, loss, draw float64) {
+	win = 1 / (1 + math.Exp(-eloScale*(delta-drawElo)))
+	loss = 1 / (1 + math.Exp(-eloScale*(-delta-drawElo)))
+	draw = math.Max(1-win-loss, 1e-300)
+	return win, loss, draw
+}
+
+// bayesLogLikelihood returns the log-likelihood of the results
+func bayesLogLikelihood(ratings []float64, drawElo float64, pairs []bayesPair) float64 {
+	total := 0.0
+	for _, p := range pairs {
+		win, loss, draw := pairProbabilities(ratings[p.i]-ratings[p.j], drawElo)
+		total += p.wins*math.Log(win) + p.losses*math.Log(loss) + p.draw*math.Log(draw)
+	}
+	return total
+}
+
+// pairDerivatives returns the first and second derivatives of a pair's
+// log-likelihood with respect to the rating advantage delta
+func pairDerivatives(p bayesPair, delta, drawElo float64) (first, second float64) {
+	win, loss, draw := pairProbabilities(delta, drawElo)
+	winSlope := eloScale * win * (1 - win)    // d win / d delta
+	lossSlope := eloScale * loss * (1 - loss) // -d loss / d delta
+	drawSlope := lossSlope - winSlope
+	drawCurve := -eloScale*lossSlope*(1-2*loss) - eloScale*winSlope*(1-2*win)
+
+	first = p.wins*eloScale*(1-win) - p.losses*eloScale*(1-loss) + p.draw*drawSlope/draw
+	second = -p.wins*eloScale*winSlope - p.losses*eloScale*lossSlope +
+		p.draw*(drawCurve/draw-(drawSlope/draw)*(drawSlope/draw))
+	return first, second
+}
+
+// fitBayesElo finds the maximum-likelihood ratings, centred on zero, and draw
+// parameter. The ratings are fitted by Newton's method and the draw parameter
+// by a line search, in turn, until neither changes.
+func fitBayesElo(n int, pairs []bayesPair) (ratings []float64, drawElo float64, err error) {
+	ratings = make([]float64, n)
+	drawElo = 100
+	for iteration := 0; iteration < 200; iteration++ {
+		gradient := make([]float64, n)
+		hessian := bayesHessian(ratings, drawElo, pairs)
+		for _, p := range pairs {
+			first, _ := pairDerivatives(p, ratings[p.i]-ratings[p.j], drawElo)
+			gradient[p.i] += first
+			gradient[p.j] -= first
+		}
+
+		// The likelihood depends only on rating differences; adding the
+		// all-ones matrix fixes the ratings' sum so the system can be solved
+		system := make([][]float64, n)
+		for i := range system {
+			system[i] = make([]float64, n)
+			for j := range system[i] {
+				system[i][j] = -hessian[i][j] + 1
+			}
+		}
+		step, err := solveLinear(system, gradient)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		// Halve the step until it improves the fit
+		current := bayesLogLikelihood(ratings, drawElo, pairs)
+		next := make([]float64, n)
+		largest := 0.0
+		for scale := 1.0; scale > 1e-6; scale /= 2 {
+			largest = 0
+			for i := range next {
+				next[i] = ratings[i] + scale*step[i]
+				largest = math.Max(largest, math.Abs(scale*step[i]))
+			}
+			if bayesLogLikelihood(next, drawElo, pairs) >= current {
+				break
+			}
+		}
+		copy(ratings, next)
+
+		previousDraw := drawElo
+		drawElo = goldenSectionMax(func(d float64) float64 {
+			return bayesLogLikelihood(ratings, d, pairs)
+		}, 0, 1000)
+		if largest < 1e-6 && math.Abs(drawElo-previousDraw) < 1e-6 {
+			break
+		}
+	}
+
+	mean := 0.0
+	for _, r := range ratings {
+		mean += r
+	}
+	mean /= float64(n)
+	for i := range ratings {
+		ratings[i] -= mean
+	}
+	return ratings, drawElo, nil
+}
+
+// bayesHessian returns the matrix of second derivatives of the log-likelihood
+// with respect to the ratings
+func bayesHessian(ratings []float64, drawElo float64, pairs []bayesPair) [][]float64 {
+	hessian := make([][]float64, len(ratings))
+	for i := range hessian {
+		hessian[i] = make([]float64, len(ratings))
+	}
+	for _, p := range pairs {
+		_, second := pairDerivatives(p, ratings[p.i]-ratings[p.j], drawElo)
+		hessian[p.i][p.i] += second
+		hessian[p.j][p.j] += second
+		hessian[p.i][p.j] -= second
+		hessian[p.j][p.i] -= second
+	}
+	return hessian
+}
+
+// bayesCovariance returns the covariance of the fitted ratings with their
+// average held fixed: the pseudo-inverse of the observed information matrix
+func bayesCovariance(ratings []float64, drawElo float64, pairs []bayesPair) ([][]float64, error) {
+	n := len(ratings)
+	hessian := bayesHessian(ratings, drawElo, pairs)
+
+	// The information matrix F's null space is spanned by the all-ones
+	// vector u, so (F + uu'/n)^-1 = F+ + uu'/n
+	columns := make([][]float64, n)
+	for k := range columns {
+		system := make([][]float64, n)
+		for i := range system {
+			system[i] = make([]float64, n)
+			for j := range system[i] {
+				system[i][j] = -hessian[i][j] + 1/float64(n)
+			}
+		}
+		unit := make([]float64, n)
+		unit[k] = 1
+		column, err := solveLinear(system, unit)
+		if err != nil {
+			return nil, err
+		}
+		columns[k] = column
+	}
+
+	covariance := make([][]float64, n)
+	for i := range covariance {
+		covariance[i] = make([]float64, n)
+		for j := range covariance[i] {
+			covariance[i][j] = columns[j][i] - 1/float64(n)
+		}
+	}
+	return covariance, nil
+}
+
+// solveLinear solves a x = b by Gaussian elimination with partial pivoting.
+// It overwrites a.
+func solveLinear(a [][]float64, b []float64) ([]float64, error) {
+	n := len(b)
+	x := append([]float64(nil), b...)
+	for col := 0; col < n; col++ {
+		pivot := col
+		for row := col + 1; row < n; row++ {
+			if math.Abs(a[row][col]) > math.Abs(a[pivot][col]) {
+				pivot = row
+			}
+		}
+		if math.Abs(a[pivot][col]) < 1e-12 {
+			return nil, errors.New("the ratings cannot be determined from these results")
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+		x[col], x[pivot] = x[pivot], x[col]
+
+		for row := col + 1; row < n; row++ {
+			factor := a[row][col] / a[col][col]
+			for k := col; k < n; k++ {
+				a[row][k] -= factor * a[col][k]
+			}
+			x[row] -= factor * x[col]
+		}
+	}
+	for row := n - 1; row >= 0; row-- {
+		for k := row + 1; k < n; k++ {
+			x[row] -= a[row][k] * x[k]
+		}
+		x[row] /= a[row][row]
+	}
+	return x, nil
+}
+
+// goldenSectionMax returns the x in [low, high] that maximises the unimodal f
+func goldenSectionMax(f func(float64) float64, low, high float64) float64 {
+	ratio := (math.Sqrt(5) - 1) / 2
+	a := high - ratio*(high-low)
+	b := low + ratio*(high-low)
+	fa, fb := f(a), f(b)
+	for high-low > 1e-7 {
+		if fa < fb {
+			low, a, fa = a, b, fb
+			b = low + ratio*(high-low)
+			fb = f(b)
+		} else {
+			high, b, fb = b, a, fa
+			a = high - ratio*(high-low)
+			fa = f(a)
+		}
+	}
+	return (low + high) / 2
+}
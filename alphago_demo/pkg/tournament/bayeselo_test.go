@@ -0,0 +1,143 @@
+package tournament
+
+import (
+	"math"
+	"testing"
+
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/agents"
+)
+
+// bayesTournament returns a tournament of the named agents that have played
+// no games
+func bayesTournament(names ...string) *TournamentManager {
+	tm := NewTournamentManager(false)
+	for _, name := range names {
+		tm.AddAgent(agents.NewRandomAgent(name))
+	}
+	return tm
+}
+
+func TestComputeBayesEloEvenResults(t *testing.T) {
+	tm := bayesTournament("A", "B", "C")
+	for _, pair := range [][2]string{{"A", "B"}, {"B", "C"}, {"A", "C"}} {
+		tm.RecordResult(pair[0], pair[1], pair[0])
+		tm.RecordResult(pair[0], pair[1], pair[1])
+		tm.RecordResult(pair[0], pair[1], DrawResult)
+	}
+
+	result, err := tm.ComputeBayesElo()
+	if err != nil {
+		t.Fatalf("ComputeBayesElo failed: %v", err)
+	}
+	for _, rating := range result.Ratings {
+		if math.Abs(rating.Elo-DefaultElo) > 1e-6 {
+			t.Errorf("Expected %s to be rated %.0f after even results, got %f", rating.Name, DefaultElo, rating.Elo)
+		}
+		if rating.Games != 6 {
+			t.Errorf("Expected %s to have played 6 games, got %d", rating.Name, rating.Games)
+		}
+	}
+	if result.DrawElo <= 0 {
+		t.Errorf("Expected a positive draw parameter with drawn games, got %f", result.DrawElo)
+	}
+}
+
+func TestComputeBayesEloIgnoresGameOrder(t *testing.T) {
+	games := [][3]string{
+		{"A", "B", "A"}, {"A", "B", "A"}, {"A", "B", "B"}, {"A", "B", DrawResult},
+		{"B", "C", "B"}, {"B", "C", "B"}, {"B", "C", DrawResult},
+		{"A", "C", "A"}, {"A", "C", "C"},
+	}
+	forward := bayesTournament("A", "B", "C")
+	for _, g := range games {
+		forward.RecordResult(g[0], g[1], g[2])
+	}
+	backward := bayesTournament("A", "B", "C")
+	for i := len(games) - 1; i >= 0; i-- {
+		backward.RecordResult(games[i][0], games[i][1], games[i][2])
+	}
+	if forward.EloRatings["A"] == backward.EloRatings["A"] {
+		t.Fatal("Expected the incremental ratings to depend on the order of the games")
+	}
+
+	first, err := forward.ComputeBayesElo()
+	if err != nil {
+		t.Fatalf("ComputeBayesElo failed: %v", err)
+	}
+	second, err := backward.ComputeBayesElo()
+	if err != nil {
+		t.Fatalf("ComputeBayesElo failed: %v", err)
+	}
+
+	order := []string{"A", "B", "C"}
+	for i, rating := range first.Ratings {
+		if rating.Name != order[i] {
+			t.Errorf("Expected %s to be ranked %d, got %s", order[i], i+1, rating.Name)
+		}
+		if math.Abs(rating.Elo-second.Ratings[i].Elo) > 1e-6 {
+			t.Errorf("%s: rated %f forwards and %f backwards", rating.Name, rating.Elo, second.Ratings[i].Elo)
+		}
+		if rating.EloLow >= rating.Elo || rating.EloHigh <= rating.Elo {
+			t.Errorf("%s: interval %f to %f does not surround the rating %f", rating.Name, rating.EloLow, rating.EloHigh, rating.Elo)
+		}
+	}
+}
+
+func TestComputeBayesEloIntervalsNarrowWithMoreGames(t *testing.T) {
+	width := func(repeats int) float64 {
+		tm := bayesTournament("A", "B")
+		for i := 0; i < repeats; i++ {
+			tm.RecordResult("A", "B", "A")
+			tm.RecordResult("A", "B", "A")
+			tm.RecordResult("A", "B", "B")
+		}
+		result, err := tm.ComputeBayesElo()
+		if err != nil {
+			t.Fatalf("ComputeBayesElo failed: %v", err)
+		}
+		if result.Ratings[0].Name != "A" {
+			t.Fatalf("Expected A to be ranked first, got %s", result.Ratings[0].Name)
+		}
+		return result.Ratings[0].EloHigh - result.Ratings[0].EloLow
+	}
+
+	if few, many := width(2), width(50); many >= few/3 {
+		t.Errorf("Expected 25 times the games to narrow the interval several times, got %f and %f", few, many)
+	}
+}
+
+func TestComputeBayesEloUnbeatenAgent(t *testing.T) {
+	tm := bayesTournament("A", "B", "Idle")
+	for i := 0; i < 10; i++ {
+		tm.RecordResult("A", "B", "A")
+	}
+
+	result, err := tm.ComputeBayesElo()
+	if err != nil {
+		t.Fatalf("ComputeBayesElo failed: %v", err)
+	}
+	best := result.Ratings[0]
+	if best.Name != "A" || math.IsInf(best.Elo, 0) || best.Elo <= DefaultElo {
+		t.Errorf("Expected a finite rating above %.0f for the unbeaten A, got %+v", DefaultElo, best)
+	}
+
+	// An agent that has not played keeps the starting rating and is unbounded
+	for _, rating := range result.Ratings {
+		if rating.Name == "Idle" && (rating.Elo != DefaultElo || !math.IsInf(rating.EloHigh, 1)) {
+			t.Errorf("Expected Idle to keep %.0f with an unbounded interval, got %+v", DefaultElo, rating)
+		}
+	}
+}
+
+func TestComputeBayesEloErrors(t *testing.T) {
+	tm := bayesTournament("A", "B", "C", "D")
+	if _, err := tm.ComputeBayesElo(); err == nil {
+		t.Error("Expected an error with no games played")
+	}
+
+	tm.RecordResult("A", "B", "A")
+	tm.RecordResult("C", "D", "C")
+	if _, err := tm.ComputeBayesElo(); err == nil {
+		t.Error("Expected an error for two groups that never played each other")
+	}
+}
@@ -0,0 +1,91 @@
+package tournament
+
+import (
+	"fmt"
+	"math"
+)
+
+// Z95 is the normal quantile for a two-sided 95% confidence interval
+const Z95 = 1.96
+
+// WilsonInterval returns the Wilson score interval for a proportion of score
+// successes out of n trials at normal quantile z. Unlike the normal
+// approximation it stays inside [0, 1] and behaves sensibly for small n.
+func WilsonInterval(score float64, n int, z float64) (low, high float64) {
+	if n <= 0 {
+		return 0, 1
+	}
+
+	p := score / float64(n)
+	nf := float64(n)
+	z2 := z * z
+
+	center := (p + z2/(2*nf)) / (1 + z2/nf)
+	margin := z / (1 + z2/nf) * math.Sqrt(p*(1-p)/nf+z2/(4*nf*nf))
+
+	return math.Max(0, center-margin), math.Min(1, center+margin)
+}
+
+// EloDifference returns the rating difference at which a player is expected to
+// score the given share of points; it is the inverse of ExpectedScore. A score of
+// 0 or 1 gives an infinite difference.
+func EloDifference(score float64) float64 {
+	switch {
+	case score <= 0:
+		return math.Inf(-1)
+	case score >= 1:
+		return math.Inf(1)
+	}
+	return -400 * math.Log10(1/score-1)
+}
+
+// ScoreEstimate is a player's score share over a match, with a 95% confidence
+// interval, and the Elo difference those scores imply
+type ScoreEstimate struct {
+	Games int
+
+	Score     float64 // Share of points won, counting draws as half a win
+	ScoreLow  float64
+	ScoreHigh float64
+
+	Elo     float64 // Implied rating advantage over the opponent
+	EloLow  float64
+	EloHigh float64
+}
+
+// EstimateScore computes a ScoreEstimate from one player's wins, losses and draws
+func EstimateScore(wins, losses, draws int) ScoreEstimate {
+	games := wins + losses + draws
+	estimate := ScoreEstimate{Games: games}
+	if games == 0 {
+		// Nothing is known yet: an even score with the widest possible interval
+		estimate.Score, estimate.ScoreHigh = 0.5, 1
+		estimate.EloLow, estimate.EloHigh = math.Inf(-1), math.Inf(1)
+		return estimate
+	}
+
+	points := float64(wins) + 0.5*float64(draws)
+	estimate.Score = points / float64(games)
+	estimate.ScoreLow, estimate.ScoreHigh = WilsonInterval(points, games, Z95)
+
+	estimate.Elo = EloDifference(estimate.Score)
+	estimate.EloLow = EloDifference(estimate.ScoreLow)
+	estimate.EloHigh = EloDifference(estimate.ScoreHigh)
+	return estimate
+}
+
+// Significant reports whether the interval excludes an even match
+func (e ScoreEstimate) Significant() bool {
+	return e.ScoreLow > 0.5 || e.ScoreHigh < 0.5
+}
+
+// String formats the estimate as e.g. "score 56.7% (95% CI 39.2%-72.6%), Elo +47 (-76 to +169)"
+func (e ScoreEstimate) String() string {
+	return fmt.Sprintf("score %.1f%% (95%% CI %.1f%%-%.1f%%), Elo %+.0f (%+.0f to %+.0f)",
+		e.Score*100, e.ScoreLow*100, e.ScoreHigh*100, e.Elo, e.EloLow, e.EloHigh)
+}
+
+// EstimateA returns agent A's score estimate over the series
+func (r SeriesResult) EstimateA() ScoreEstimate {
+	return EstimateScore(r.WinsA, r.WinsB, r.Draws)
+}
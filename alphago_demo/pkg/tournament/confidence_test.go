@@ -0,0 +1,63 @@
+package tournament
+
+import (
+	"math"
+	"testing"
+)
+
+func TestWilsonInterval(t *testing.T) {
+	// Reference values for 17 successes out of 30 at 95%
+	low, high := WilsonInterval(17, 30, Z95)
+	if math.Abs(low-0.3920) > 1e-3 || math.Abs(high-0.7262) > 1e-3 {
+		t.Errorf("Expected [0.392, 0.726], got [%.4f, %.4f]", low, high)
+	}
+
+	// The interval stays inside [0, 1] at the extremes
+	low, high = WilsonInterval(0, 10, Z95)
+	if low != 0 || high <= 0 || high >= 1 {
+		t.Errorf("Unexpected interval for 0/10: [%f, %f]", low, high)
+	}
+	low, high = WilsonInterval(10, 10, Z95)
+	if high != 1 || low <= 0 || low >= 1 {
+		t.Errorf("Unexpected interval for 10/10: [%f, %f]", low, high)
+	}
+
+	// More games narrow the interval
+	smallLow, smallHigh := WilsonInterval(17, 30, Z95)
+	largeLow, largeHigh := WilsonInterval(170, 300, Z95)
+	if largeHigh-largeLow >= smallHigh-smallLow {
+		t.Error("Expected a narrower interval with more games")
+	}
+}
+
+func TestEloDifferenceInvertsExpectedScore(t *testing.T) {
+	for _, diff := range []float64{-400, -120, 0, 35, 400} {
+		score := ExpectedScore(1500+diff, 1500)
+		if got := EloDifference(score); math.Abs(got-diff) > 1e-6 {
+			t.Errorf("EloDifference(%f) = %f, want %f", score, got, diff)
+		}
+	}
+	if !math.IsInf(EloDifference(1), 1) || !math.IsInf(EloDifference(0), -1) {
+		t.Error("Expected infinite differences for scores of 0 and 1")
+	}
+}
+
+func TestEstimateScore(t *testing.T) {
+	// A 17-13 result is not enough to tell the players apart
+	estimate := EstimateScore(17, 13, 0)
+	if estimate.Significant() {
+		t.Errorf("17-13 should not be significant: %s", estimate)
+	}
+	if estimate.EloLow >= 0 || estimate.EloHigh <= 0 || estimate.Elo <= 0 {
+		t.Errorf("Expected Elo error bars spanning zero around a positive estimate: %s", estimate)
+	}
+
+	// Draws count as half a point
+	if got := EstimateScore(1, 1, 2).Score; got != 0.5 {
+		t.Errorf("Expected a score of 0.5, got %f", got)
+	}
+
+	if !EstimateScore(90, 10, 0).Significant() {
+		t.Error("90-10 should be significant")
+	}
+}
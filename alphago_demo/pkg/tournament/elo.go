@@ -0,0 +1,45 @@
+package tournament
+
+import "math"
+
+const (
+	// DefaultElo is the rating every agent starts a tournament with
+	DefaultElo = 1500.0
+
+	// EloK is the K-factor applied to every rating update
+	EloK = 32.0
+)
+
+// ExpectedScore returns the expected score (0-1) of a player rated ratingA
+// against a player rated ratingB
+func ExpectedScore(ratingA, ratingB float64) float64 {
+	return 1.0 / (1.0 + math.Pow(10, (ratingB-ratingA)/400.0))
+}
+
+// UpdateElo updates ELO ratings based on game result
+func (tm *TournamentManager) UpdateElo(winner, loser string) {
+	ratingWinner := tm.EloRatings[winner]
+	ratingLoser := tm.EloRatings[loser]
+
+	// Calculate expected scores
+	expectedWinner := ExpectedScore(ratingWinner, ratingLoser)
+	expectedLoser := ExpectedScore(ratingLoser, ratingWinner)
+
+	// Update ratings
+	tm.EloRatings[winner] = ratingWinner + EloK*(1.0-expectedWinner)
+	tm.EloRatings[loser] = ratingLoser + EloK*(0.0-expectedLoser)
+}
+
+// UpdateEloForDraw updates ELO ratings for a draw
+func (tm *TournamentManager) UpdateEloForDraw(agent1, agent2 string) {
+	rating1 := tm.EloRatings[agent1]
+	rating2 := tm.EloRatings[agent2]
+
+	// Calculate expected scores
+	expected1 := ExpectedScore(rating1, rating2)
+	expected2 := ExpectedScore(rating2, rating1)
+
+	// Update ratings (0.5 for draw)
+	tm.EloRatings[agent1] = rating1 + EloK*(0.5-expected1)
+	tm.EloRatings[agent2] = rating2 + EloK*(0.5-expected2)
+}
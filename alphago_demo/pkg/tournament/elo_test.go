@@ -0,0 +1,66 @@
+package tournament
+
+import (
+	"math"
+	"testing"
+
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/agents"
+)
+
+func TestExpectedScore(t *testing.T) {
+	if got := ExpectedScore(1500, 1500); math.Abs(got-0.5) > 1e-9 {
+		t.Errorf("Expected 0.5 for equal ratings, got %f", got)
+	}
+
+	// A 400 point gap means 10:1 odds
+	if got := ExpectedScore(1900, 1500); math.Abs(got-10.0/11.0) > 1e-9 {
+		t.Errorf("Expected %f for a 400 point advantage, got %f", 10.0/11.0, got)
+	}
+
+	if sum := ExpectedScore(1620, 1480) + ExpectedScore(1480, 1620); math.Abs(sum-1) > 1e-9 {
+		t.Errorf("Expected scores to sum to 1, got %f", sum)
+	}
+}
+
+func TestUpdateElo(t *testing.T) {
+	tm := NewTournamentManager(false)
+	tm.AddAgent(agents.NewRandomAgent("A"))
+	tm.AddAgent(agents.NewRandomAgent("B"))
+
+	tm.UpdateElo("A", "B")
+
+	// Equal ratings: the winner gains K/2 and the loser drops K/2
+	if got := tm.EloRatings["A"]; math.Abs(got-(DefaultElo+EloK/2)) > 1e-9 {
+		t.Errorf("Expected winner rating %f, got %f", DefaultElo+EloK/2, got)
+	}
+	if got := tm.EloRatings["B"]; math.Abs(got-(DefaultElo-EloK/2)) > 1e-9 {
+		t.Errorf("Expected loser rating %f, got %f", DefaultElo-EloK/2, got)
+	}
+
+	// Rating points are conserved
+	if sum := tm.EloRatings["A"] + tm.EloRatings["B"]; math.Abs(sum-2*DefaultElo) > 1e-9 {
+		t.Errorf("Expected ratings to sum to %f, got %f", 2*DefaultElo, sum)
+	}
+}
+
+func TestUpdateEloForDraw(t *testing.T) {
+	tm := NewTournamentManager(false)
+	tm.AddAgent(agents.NewRandomAgent("A"))
+	tm.AddAgent(agents.NewRandomAgent("B"))
+
+	// A draw between equal ratings changes nothing
+	tm.UpdateEloForDraw("A", "B")
+	if tm.EloRatings["A"] != DefaultElo || tm.EloRatings["B"] != DefaultElo {
+		t.Errorf("Expected unchanged ratings, got %f and %f", tm.EloRatings["A"], tm.EloRatings["B"])
+	}
+
+	// A draw moves the stronger player down and the weaker player up
+	tm.EloRatings["A"] = 1700
+	tm.UpdateEloForDraw("A", "B")
+	if tm.EloRatings["A"] >= 1700 {
+		t.Errorf("Expected stronger player to lose rating on a draw, got %f", tm.EloRatings["A"])
+	}
+	if tm.EloRatings["B"] <= DefaultElo {
+		t.Errorf("Expected weaker player to gain rating on a draw, got %f", tm.EloRatings["B"])
+	}
+}
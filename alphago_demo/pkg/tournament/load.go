@@ -0,0 +1,148 @@
+package tournament
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/agents"
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
+)
+
+// savedAgent stands in for an agent of a loaded tournament. It reports the
+// network and search details that were saved, but cannot play.
+type savedAgent struct {
+	name string
+	info agents.AgentInfo
+}
+
+var (
+	_ agents.Agent     = (*savedAgent)(nil)
+	_ agents.Describer = (*savedAgent)(nil)
+)
+
+func (a *savedAgent) Name() string { return a.name }
+
+func (a *savedAgent) GetMove(state *game.RPSGame) (game.RPSMove, error) {
+	return game.RPSMove{}, fmt.Errorf("%s was loaded from saved results and cannot play", a.name)
+}
+
+func (a *savedAgent) Describe() agents.AgentInfo { return a.info }
+
+// LoadTournament rebuilds a tournament from results saved by SaveResults or
+// SaveResultsJSON, so its ratings and head-to-head records can be analysed
+// without replaying the games. The format is detected from the file's
+// contents. The agents of the loaded tournament cannot play, and ratings read
+// from a CSV file are rounded to whole points.
+func LoadTournament(resultsPath string) (*TournamentManager, error) {
+	data, err := os.ReadFile(resultsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var tm *TournamentManager
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		var results Results
+		if err = json.Unmarshal(data, &results); err == nil {
+			tm, err = tournamentFromResults(&results)
+		}
+	} else {
+		tm, err = readResultsCSV(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %v", resultsPath, err)
+	}
+	return tm, nil
+}
+
+// tournamentFromResults rebuilds a tournament from structured results
+func tournamentFromResults(results *Results) (*TournamentManager, error) {
+	tm := NewTournamentManager(false)
+	for _, r := range results.Agents {
+		tm.AddAgent(&savedAgent{name: r.Name, info: r.AgentInfo})
+		tm.EloRatings[r.Name] = r.Elo
+	}
+
+	for name, opponents := range results.HeadToHead {
+		for opponent, record := range opponents {
+			row, ok := tm.GameResults[name]
+			if !ok || row[opponent] == nil {
+				return nil, fmt.Errorf("head-to-head record for unknown agents %s and %s", name, opponent)
+			}
+			*row[opponent] = record
+		}
+	}
+	return tm, nil
+}
+
+// readResultsCSV rebuilds a tournament from the format written by WriteResults.
+// Files saved before the network and search columns were added are accepted.
+func readResultsCSV(r io.Reader) (*TournamentManager, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1 // The two tables have different widths
+	reader.LazyQuotes = true    // Agent names are written unquoted
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 || len(rows[0]) < 6 || rows[0][0] != "Agent" {
+		return nil, fmt.Errorf("not a tournament results file")
+	}
+
+	tm := NewTournamentManager(false)
+	i := 1
+	for ; i < len(rows) && rows[i][0] != "Head-to-Head Results:"; i++ {
+		row := rows[i]
+		if len(row) < 6 {
+			return nil, fmt.Errorf("row %d: expected at least 6 fields, got %d", i+1, len(row))
+		}
+		elo, err := strconv.ParseFloat(row[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: invalid ELO %q", i+1, row[1])
+		}
+
+		// Hidden Size, Parameters, Simulations and Training Games, if present
+		var counts [4]int
+		for j := range counts {
+			if 6+j < len(row) && row[6+j] != "" {
+				if counts[j], err = strconv.Atoi(row[6+j]); err != nil {
+					return nil, fmt.Errorf("row %d: invalid count %q", i+1, row[6+j])
+				}
+			}
+		}
+
+		tm.AddAgent(&savedAgent{name: row[0], info: agents.AgentInfo{
+			HiddenSize:    counts[0],
+			Parameters:    counts[1],
+			Simulations:   counts[2],
+			TrainingGames: counts[3],
+		}})
+		tm.EloRatings[row[0]] = elo
+	}
+
+	// Skip the section title and column headers of the head-to-head table
+	for i += 2; i < len(rows); i++ {
+		row := rows[i]
+		if len(row) != 5 {
+			return nil, fmt.Errorf("row %d: expected 5 head-to-head fields, got %d", i+1, len(row))
+		}
+		name1, name2 := row[0], row[1]
+		if tm.GameResults[name1][name2] == nil {
+			return nil, fmt.Errorf("row %d: head-to-head record for unknown agents %s and %s", i+1, name1, name2)
+		}
+
+		var counts [3]int
+		for j := range counts {
+			if counts[j], err = strconv.Atoi(row[2+j]); err != nil {
+				return nil, fmt.Errorf("row %d: invalid game count %q", i+1, row[2+j])
+			}
+		}
+		*tm.GameResults[name1][name2] = GameRecord{Wins: counts[0], Losses: counts[1], Draws: counts[2]}
+		*tm.GameResults[name2][name1] = GameRecord{Wins: counts[1], Losses: counts[0], Draws: counts[2]}
+	}
+	return tm, nil
+}
@@ -0,0 +1,131 @@
+package tournament
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"time"
+
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/agents"
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
+)
+
+// PlyRecord describes a single move of a recorded game
+type PlyRecord struct {
+	Ply       int     `json:"ply"`
+	Player    int     `json:"player"`
+	Agent     string  `json:"agent"`
+	Position  string  `json:"position"` // Notation of the position before the move
+	CardIndex int     `json:"card_index"`
+	CardType  string  `json:"card_type"`
+	Square    int     `json:"square"`
+	TimeMs    float64 `json:"time_ms"`
+
+	// Search statistics, present only for agents implementing agents.SearchReporter
+	Nodes int      `json:"nodes,omitempty"`
+	Value *float64 `json:"value,omitempty"`
+}
+
+// GameLog is the move-by-move record of one game
+type GameLog struct {
+	Player1 string      `json:"player1"`
+	Player2 string      `json:"player2"`
+	Result  string      `json:"result"`
+	Plies   []PlyRecord `json:"plies"`
+}
+
+// GameRecorder collects per-move timing and search statistics for the games it
+// is attached to. A nil *GameRecorder records nothing.
+type GameRecorder struct {
+	Games []*GameLog `json:"games"`
+
+	current *GameLog
+}
+
+// NewGameRecorder creates an empty recorder
+func NewGameRecorder() *GameRecorder {
+	return &GameRecorder{Games: make([]*GameLog, 0)}
+}
+
+// StartGame begins a new game log with the agents playing as player 1 and player 2
+func (r *GameRecorder) StartGame(player1, player2 string) {
+	if r == nil {
+		return
+	}
+	r.current = &GameLog{Player1: player1, Player2: player2, Plies: make([]PlyRecord, 0)}
+	r.Games = append(r.Games, r.current)
+}
+
+// RecordMove logs a move chosen by agent from state, which must be the position
+// before the move is applied
+func (r *GameRecorder) RecordMove(agent agents.Agent, state *game.RPSGame, move game.RPSMove, elapsed time.Duration) {
+	if r == nil || r.current == nil {
+		return
+	}
+
+	ply := PlyRecord{
+		Ply:       len(r.current.Plies) + 1,
+		Player:    int(state.CurrentPlayer),
+		Agent:     agent.Name(),
+		Position:  state.Notation(),
+		CardIndex: move.CardIndex,
+		Square:    move.Position,
+		TimeMs:    float64(elapsed.Microseconds()) / 1000.0,
+	}
+
+	hand := state.Player1Hand
+	if state.CurrentPlayer == game.Player2 {
+		hand = state.Player2Hand
+	}
+	if move.CardIndex >= 0 && move.CardIndex < len(hand) {
+		ply.CardType = cardTypeName(hand[move.CardIndex].Type)
+	}
+
+	if reporter, ok := agent.(agents.SearchReporter); ok {
+		ply.Nodes = reporter.GetNodesEvaluated()
+		value := reporter.GetValueEstimate()
+		ply.Value = &value
+	}
+
+	r.current.Plies = append(r.current.Plies, ply)
+}
+
+// EndGame stores the result of the current game, as returned by PlayGame
+func (r *GameRecorder) EndGame(result string) {
+	if r == nil || r.current == nil {
+		return
+	}
+	r.current.Result = result
+	r.current = nil
+}
+
+// WriteJSON writes all recorded games as indented JSON
+func (r *GameRecorder) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// SaveJSON writes all recorded games to a JSON file
+func (r *GameRecorder) SaveJSON(filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return r.WriteJSON(f)
+}
+
+// cardTypeName returns the display name of a card type
+func cardTypeName(cardType game.RPSCardType) string {
+	switch cardType {
+	case game.Rock:
+		return "Rock"
+	case game.Paper:
+		return "Paper"
+	case game.Scissors:
+		return "Scissors"
+	}
+	return "Unknown"
+}
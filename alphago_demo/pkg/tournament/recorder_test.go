@@ -0,0 +1,60 @@
+package tournament
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/agents"
+)
+
+func TestGameRecorderLogsEveryMove(t *testing.T) {
+	tm := NewTournamentManager(false)
+	tm.Recorder = NewGameRecorder()
+	a := agents.NewRandomAgent("A")
+	b := agents.NewRandomAgent("B")
+
+	result := tm.PlayGame(a, b)
+
+	if len(tm.Recorder.Games) != 1 {
+		t.Fatalf("Expected 1 recorded game, got %d", len(tm.Recorder.Games))
+	}
+	log := tm.Recorder.Games[0]
+	if log.Result != result {
+		t.Errorf("Expected recorded result %q, got %q", result, log.Result)
+	}
+	if len(log.Plies) == 0 {
+		t.Fatal("Expected recorded plies")
+	}
+	for i, ply := range log.Plies {
+		if ply.Ply != i+1 {
+			t.Errorf("Ply %d numbered %d", i+1, ply.Ply)
+		}
+		if ply.Position == "" || ply.CardType == "" {
+			t.Errorf("Ply %d missing position or card: %+v", i+1, ply)
+		}
+		if ply.Value != nil {
+			t.Errorf("Random agent should not report a value estimate")
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := tm.Recorder.WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+	var decoded GameRecorder
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("Recorded JSON does not decode: %v", err)
+	}
+	if len(decoded.Games) != 1 || len(decoded.Games[0].Plies) != len(log.Plies) {
+		t.Errorf("Decoded recording does not match the original")
+	}
+}
+
+func TestNilGameRecorderIsNoop(t *testing.T) {
+	tm := NewTournamentManager(false)
+	result := tm.PlayGame(agents.NewRandomAgent("A"), agents.NewRandomAgent("B"))
+	if result != "A" && result != "B" && result != DrawResult {
+		t.Errorf("Unexpected result %q", result)
+	}
+}
@@ -0,0 +1,157 @@
+package tournament
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/agents"
+)
+
+// Ranking summarizes one agent's rating and overall record
+type Ranking struct {
+	Name   string
+	Elo    float64
+	Wins   int
+	Losses int
+	Draws  int
+
+	// Info is what the agent reports about its networks and search, if it
+	// implements agents.Describer
+	Info agents.AgentInfo
+}
+
+// Games returns the total number of games the agent played
+func (r Ranking) Games() int {
+	return r.Wins + r.Losses + r.Draws
+}
+
+// WinPercentage returns the share of games won, in percent
+func (r Ranking) WinPercentage() float64 {
+	if r.Games() == 0 {
+		return 0
+	}
+	return 100.0 * float64(r.Wins) / float64(r.Games())
+}
+
+// record returns the total wins/losses/draws of the named agent
+func (tm *TournamentManager) record(name string) Ranking {
+	r := Ranking{Name: name, Elo: tm.EloRatings[name]}
+	for _, otherAgent := range tm.Agents {
+		otherName := otherAgent.Name()
+		if name == otherName {
+			if describer, ok := otherAgent.(agents.Describer); ok {
+				r.Info = describer.Describe()
+			}
+		} else {
+			if rec, exists := tm.GameResults[name][otherName]; exists {
+				r.Wins += rec.Wins
+				r.Losses += rec.Losses
+				r.Draws += rec.Draws
+			}
+		}
+	}
+	return r
+}
+
+// Rankings returns all agents sorted by ELO rating, highest first
+func (tm *TournamentManager) Rankings() []Ranking {
+	rankings := make([]Ranking, 0, len(tm.Agents))
+	for _, agent := range tm.Agents {
+		rankings = append(rankings, tm.record(agent.Name()))
+	}
+
+	sort.SliceStable(rankings, func(i, j int) bool {
+		return rankings[i].Elo > rankings[j].Elo
+	})
+	return rankings
+}
+
+// PrintTopRankings displays the top N agents by ELO rating (0 shows all)
+func (tm *TournamentManager) PrintTopRankings(n int) {
+	rankings := tm.Rankings()
+
+	// Limit to top N
+	if n > 0 && n < len(rankings) {
+		rankings = rankings[:n]
+	}
+
+	// Print rankings table
+	fmt.Printf("%-4s %-30s %-6s %-6s %-6s %-6s %-6s\n",
+		"Rank", "Agent", "ELO", "W", "L", "D", "W%")
+	fmt.Println(strings.Repeat("-", 72))
+
+	for i, agent := range rankings {
+		fmt.Printf("%-4d %-30s %-6.0f %-6d %-6d %-6d %-6.1f%%\n",
+			i+1, agent.Name, agent.Elo, agent.Wins, agent.Losses, agent.Draws, agent.WinPercentage())
+	}
+}
+
+// PrintRankings displays all agents sorted by ELO rating
+func (tm *TournamentManager) PrintRankings() {
+	tm.PrintTopRankings(0) // 0 means show all
+}
+
+// SaveResults saves tournament results to a CSV file
+func (tm *TournamentManager) SaveResults(filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return tm.WriteResults(f)
+}
+
+// WriteResults writes the per-agent table followed by the head-to-head results.
+// Agents appear in the order they were added. The network and search columns
+// are left empty for agents that do not report them.
+func (tm *TournamentManager) WriteResults(w io.Writer) error {
+	// Write header
+	if _, err := fmt.Fprintf(w, "Agent,ELO,Wins,Losses,Draws,Win%%,Hidden Size,Parameters,Simulations,Training Games\n"); err != nil {
+		return err
+	}
+
+	// Write data for each agent
+	for _, agent := range tm.Agents {
+		r := tm.record(agent.Name())
+		if _, err := fmt.Fprintf(w, "%s,%.0f,%d,%d,%d,%.1f%%,%s,%s,%s,%s\n",
+			r.Name, r.Elo, r.Wins, r.Losses, r.Draws, r.WinPercentage(),
+			csvCount(r.Info.HiddenSize), csvCount(r.Info.Parameters),
+			csvCount(r.Info.Simulations), csvCount(r.Info.TrainingGames)); err != nil {
+			return err
+		}
+	}
+
+	// Write detailed head-to-head results
+	fmt.Fprintf(w, "\nHead-to-Head Results:\n")
+	fmt.Fprintf(w, "Agent 1,Agent 2,Agent 1 Wins,Agent 2 Wins,Draws\n")
+
+	for i, agent1 := range tm.Agents {
+		for j, agent2 := range tm.Agents {
+			if i < j {
+				name1 := agent1.Name()
+				name2 := agent2.Name()
+				record := tm.GameResults[name1][name2]
+
+				if _, err := fmt.Fprintf(w, "%s,%s,%d,%d,%d\n",
+					name1, name2, record.Wins, tm.GameResults[name2][name1].Wins, record.Draws); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// csvCount formats a count for the results file, leaving unknown (zero)
+// counts empty
+func csvCount(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return fmt.Sprint(n)
+}
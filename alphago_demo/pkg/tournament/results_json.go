@@ -0,0 +1,80 @@
+package tournament
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/agents"
+)
+
+// AgentResult is one agent's entry in a structured results document. The
+// fields of AgentInfo appear alongside the others when the agent reports them.
+type AgentResult struct {
+	Name          string  `json:"name"`
+	Elo           float64 `json:"elo"`
+	Wins          int     `json:"wins"`
+	Losses        int     `json:"losses"`
+	Draws         int     `json:"draws"`
+	WinPercentage float64 `json:"win_percentage"`
+	agents.AgentInfo
+}
+
+// Results is the structured form of a tournament's outcome. HeadToHead is
+// indexed by agent name and then opponent name, from the first agent's point of view.
+type Results struct {
+	Agents     []AgentResult                    `json:"agents"`
+	HeadToHead map[string]map[string]GameRecord `json:"head_to_head"`
+}
+
+// Results builds the structured results, with agents sorted by ELO rating
+func (tm *TournamentManager) Results() *Results {
+	results := &Results{
+		Agents:     make([]AgentResult, 0, len(tm.Agents)),
+		HeadToHead: make(map[string]map[string]GameRecord, len(tm.Agents)),
+	}
+
+	for _, r := range tm.Rankings() {
+		results.Agents = append(results.Agents, AgentResult{
+			Name:          r.Name,
+			Elo:           r.Elo,
+			Wins:          r.Wins,
+			Losses:        r.Losses,
+			Draws:         r.Draws,
+			WinPercentage: r.WinPercentage(),
+			AgentInfo:     r.Info,
+		})
+	}
+
+	for name, opponents := range tm.GameResults {
+		row := make(map[string]GameRecord, len(opponents))
+		for opponent, record := range opponents {
+			row[opponent] = *record
+		}
+		results.HeadToHead[name] = row
+	}
+
+	return results
+}
+
+// SaveResultsJSON saves tournament results as a JSON document
+func (tm *TournamentManager) SaveResultsJSON(filename string) error {
+	data, err := json.MarshalIndent(tm.Results(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
+
+// LoadResultsJSON reads a document written by SaveResultsJSON
+func LoadResultsJSON(filename string) (*Results, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var results Results
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, err
+	}
+	return &results, nil
+}
@@ -0,0 +1,247 @@
+package tournament
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/agents"
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/mcts"
+	neural "github.com/zachbeta/neural_rps/alphago_demo/pkg/rps_net_impl"
+)
+
+func TestWriteResultsFormat(t *testing.T) {
+	tm := NewTournamentManager(false)
+	tm.AddAgent(agents.NewRandomAgent("A"))
+	tm.AddAgent(agents.NewRandomAgent("B"))
+	tm.AddAgent(agents.NewRandomAgent("C"))
+
+	tm.RecordResult("A", "B", "A")
+	tm.RecordResult("A", "B", "A")
+	tm.RecordResult("A", "B", DrawResult)
+	tm.RecordResult("B", "C", "C")
+
+	// Use fixed ratings so the expected output is easy to read
+	tm.EloRatings["A"] = 1530
+	tm.EloRatings["B"] = 1470
+	tm.EloRatings["C"] = 1516
+
+	var buf bytes.Buffer
+	if err := tm.WriteResults(&buf); err != nil {
+		t.Fatalf("WriteResults failed: %v", err)
+	}
+
+	expected := "Agent,ELO,Wins,Losses,Draws,Win%,Hidden Size,Parameters,Simulations,Training Games\n" +
+		"A,1530,2,0,1,66.7%,,,,\n" +
+		"B,1470,0,3,1,0.0%,,,,\n" +
+		"C,1516,1,0,0,100.0%,,,,\n" +
+		"\n" +
+		"Head-to-Head Results:\n" +
+		"Agent 1,Agent 2,Agent 1 Wins,Agent 2 Wins,Draws\n" +
+		"A,B,2,0,1\n" +
+		"A,C,0,0,0\n" +
+		"B,C,0,1,0\n"
+
+	if buf.String() != expected {
+		t.Errorf("Unexpected CSV output.\nExpected:\n%s\nGot:\n%s", expected, buf.String())
+	}
+}
+
+func TestRankingsSortedByElo(t *testing.T) {
+	tm := NewTournamentManager(false)
+	tm.AddAgent(agents.NewRandomAgent("A"))
+	tm.AddAgent(agents.NewRandomAgent("B"))
+	tm.EloRatings["B"] = 1600
+
+	rankings := tm.Rankings()
+	if len(rankings) != 2 {
+		t.Fatalf("Expected 2 rankings, got %d", len(rankings))
+	}
+	if rankings[0].Name != "B" || rankings[1].Name != "A" {
+		t.Errorf("Expected order B, A, got %s, %s", rankings[0].Name, rankings[1].Name)
+	}
+}
+
+func TestPlayGameReturnsParticipantOrDraw(t *testing.T) {
+	tm := NewTournamentManager(false)
+	a := agents.NewRandomAgent("A")
+	b := agents.NewRandomAgent("B")
+	tm.AddAgent(a)
+	tm.AddAgent(b)
+
+	for i := 0; i < 10; i++ {
+		result := tm.PlayGame(a, b)
+		if result != "A" && result != "B" && result != DrawResult {
+			t.Fatalf("Unexpected game result %q", result)
+		}
+	}
+}
+
+func TestResultsJSONRoundTrip(t *testing.T) {
+	tm := NewTournamentManager(false)
+	tm.AddAgent(agents.NewRandomAgent("A"))
+	tm.AddAgent(agents.NewRandomAgent("B"))
+	tm.RecordResult("A", "B", "A")
+	tm.RecordResult("A", "B", DrawResult)
+
+	filename := filepath.Join(t.TempDir(), "results.json")
+	if err := tm.SaveResultsJSON(filename); err != nil {
+		t.Fatalf("SaveResultsJSON failed: %v", err)
+	}
+
+	loaded, err := LoadResultsJSON(filename)
+	if err != nil {
+		t.Fatalf("LoadResultsJSON failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(loaded, tm.Results()) {
+		t.Errorf("Loaded results differ from saved results.\nSaved: %+v\nLoaded: %+v", tm.Results(), loaded)
+	}
+
+	if loaded.Agents[0].Name != "A" {
+		t.Errorf("Expected A to be ranked first, got %s", loaded.Agents[0].Name)
+	}
+	record := loaded.HeadToHead["B"]["A"]
+	if record.Losses != 1 || record.Draws != 1 || record.Wins != 0 {
+		t.Errorf("Expected B vs A record 0-1-1, got %d-%d-%d", record.Wins, record.Losses, record.Draws)
+	}
+}
+
+func TestResultsAgentInfo(t *testing.T) {
+	policy := neural.NewRPSPolicyNetwork(16)
+	policy.SetMetadata(neural.ModelMetadata{Games: 250})
+	value := neural.NewRPSValueNetwork(8)
+	params := mcts.DefaultRPSMCTSParams()
+	params.NumSimulations = 50
+
+	tm := NewTournamentManager(false)
+	tm.AddAgent(agents.NewMCTSAgent("MCTS", mcts.NewRPSMCTS(policy, value, params)))
+	tm.AddAgent(agents.NewRandomAgent("Random"))
+	tm.EloRatings["MCTS"] = 1600
+
+	expected := agents.AgentInfo{
+		HiddenSize:    16,
+		Parameters:    policy.ParameterCount() + value.ParameterCount(),
+		Simulations:   50,
+		TrainingGames: 250,
+	}
+	results := tm.Results()
+	if results.Agents[0].AgentInfo != expected {
+		t.Errorf("Expected MCTS info %+v, got %+v", expected, results.Agents[0].AgentInfo)
+	}
+	if results.Agents[1].AgentInfo != (agents.AgentInfo{}) {
+		t.Errorf("Expected no info for the random agent, got %+v", results.Agents[1].AgentInfo)
+	}
+
+	filename := filepath.Join(t.TempDir(), "results.json")
+	if err := tm.SaveResultsJSON(filename); err != nil {
+		t.Fatalf("SaveResultsJSON failed: %v", err)
+	}
+	loaded, err := LoadResultsJSON(filename)
+	if err != nil {
+		t.Fatalf("LoadResultsJSON failed: %v", err)
+	}
+	if loaded.Agents[0].AgentInfo != expected {
+		t.Errorf("Expected loaded MCTS info %+v, got %+v", expected, loaded.Agents[0].AgentInfo)
+	}
+
+	var buf bytes.Buffer
+	if err := tm.WriteResults(&buf); err != nil {
+		t.Fatalf("WriteResults failed: %v", err)
+	}
+	row := fmt.Sprintf("MCTS,1600,0,0,0,0.0%%,16,%d,50,250\n", expected.Parameters)
+	if !strings.Contains(buf.String(), row) {
+		t.Errorf("Expected the CSV to contain %q, got:\n%s", row, buf.String())
+	}
+}
+
+func TestLoadTournament(t *testing.T) {
+	policy := neural.NewRPSPolicyNetwork(16)
+	value := neural.NewRPSValueNetwork(8)
+	params := mcts.DefaultRPSMCTSParams()
+	params.NumSimulations = 50
+
+	tm := NewTournamentManager(false)
+	tm.AddAgent(agents.NewMCTSAgent("MCTS", mcts.NewRPSMCTS(policy, value, params)))
+	tm.AddAgent(agents.NewRandomAgent("A"))
+	tm.AddAgent(agents.NewRandomAgent("B"))
+	tm.RecordResult("MCTS", "A", "MCTS")
+	tm.RecordResult("MCTS", "B", "MCTS")
+	tm.RecordResult("A", "B", "B")
+	tm.RecordResult("A", "B", DrawResult)
+
+	dir := t.TempDir()
+	csvFile := filepath.Join(dir, "results.csv")
+	jsonFile := filepath.Join(dir, "results.json")
+	if err := tm.SaveResults(csvFile); err != nil {
+		t.Fatalf("SaveResults failed: %v", err)
+	}
+	if err := tm.SaveResultsJSON(jsonFile); err != nil {
+		t.Fatalf("SaveResultsJSON failed: %v", err)
+	}
+
+	for _, filename := range []string{csvFile, jsonFile} {
+		loaded, err := LoadTournament(filename)
+		if err != nil {
+			t.Fatalf("LoadTournament(%s) failed: %v", filename, err)
+		}
+
+		want, got := tm.Rankings(), loaded.Rankings()
+		if len(got) != len(want) {
+			t.Fatalf("%s: expected %d agents, got %d", filename, len(want), len(got))
+		}
+		for i := range want {
+			// The CSV file rounds ratings to whole points
+			want[i].Elo, got[i].Elo = math.Round(want[i].Elo), math.Round(got[i].Elo)
+			if got[i] != want[i] {
+				t.Errorf("%s: expected ranking %+v, got %+v", filename, want[i], got[i])
+			}
+		}
+		for name, opponents := range tm.GameResults {
+			for opponent, record := range opponents {
+				if *loaded.GameResults[name][opponent] != *record {
+					t.Errorf("%s: expected %s vs %s record %+v, got %+v",
+						filename, name, opponent, *record, *loaded.GameResults[name][opponent])
+				}
+			}
+		}
+	}
+}
+
+func TestLoadTournamentCSVWithoutAgentInfo(t *testing.T) {
+	// Results saved before the network and search columns were added
+	data := "Agent,ELO,Wins,Losses,Draws,Win%\n" +
+		"A,1516,1,0,0,100.0%\n" +
+		"B,1484,0,1,0,0.0%\n" +
+		"\n" +
+		"Head-to-Head Results:\n" +
+		"Agent 1,Agent 2,Agent 1 Wins,Agent 2 Wins,Draws\n" +
+		"A,B,1,0,0\n"
+	filename := filepath.Join(t.TempDir(), "old.csv")
+	if err := os.WriteFile(filename, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tm, err := LoadTournament(filename)
+	if err != nil {
+		t.Fatalf("LoadTournament failed: %v", err)
+	}
+	if tm.EloRatings["A"] != 1516 || tm.EloRatings["B"] != 1484 {
+		t.Errorf("Expected ratings 1516 and 1484, got %v", tm.EloRatings)
+	}
+	if record := *tm.GameResults["B"]["A"]; record != (GameRecord{Losses: 1}) {
+		t.Errorf("Expected B vs A record 0-1-0, got %+v", record)
+	}
+
+	if err := os.WriteFile(filename, []byte("not,a,results,file\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadTournament(filename); err == nil {
+		t.Error("Expected an error loading a file that is not tournament results")
+	}
+}
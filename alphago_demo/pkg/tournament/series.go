@@ -0,0 +1,262 @@
+package tournament
+
+import (
+	"log/slog"
+	"math/rand"
+	"time"
+
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/agents"
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
+)
+
+// SeriesOptions configures the games played by PlaySeries
+type SeriesOptions struct {
+	// Game parameters used for every game
+	DeckSize  int
+	HandSize  int
+	MaxRounds int
+
+	// Rng, when set, deals every game so a series can be reproduced exactly.
+	// The global random source is used when it is nil.
+	Rng *rand.Rand
+
+	// AlternateFirst swaps which agent moves first every game; when false the
+	// first agent passed to PlaySeries always moves first
+	AlternateFirst bool
+
+	// MirrorDeals plays every deal twice, first with agent A as player 1 and
+	// then with agent B as player 1, so both agents play the same cards. It
+	// replaces AlternateFirst and rounds the number of games up to an even number.
+	MirrorDeals bool
+
+	// Recorder, when set, logs every move
+	Recorder *GameRecorder
+
+	// OnMove, when set, is called after every move with the updated position
+	OnMove func(agent agents.Agent, move game.RPSMove, state *game.RPSGame)
+
+	// OnGame, when set, is called after every game with its 1-based number
+	OnGame func(gameNumber int, result SeriesGame)
+}
+
+// DefaultSeriesOptions returns the standard game parameters with alternating first player
+func DefaultSeriesOptions() SeriesOptions {
+	return SeriesOptions{
+		DeckSize:       21,
+		HandSize:       5,
+		MaxRounds:      10,
+		AlternateFirst: true,
+	}
+}
+
+// SeriesGame is the outcome of one game of a series
+type SeriesGame struct {
+	Player1 string // Agent that moved first
+	Player2 string
+	Winner  string // Winner's name or DrawResult
+	Moves   int
+
+	// FinalPosition is the board position of the last move, or -1 if no move was made
+	FinalPosition int
+
+	// Forfeit is set when the loser errored or played an invalid move
+	Forfeit bool
+
+	// DealSeed is the seed the game was dealt from when MirrorDeals is set
+	DealSeed int64
+}
+
+// SeriesResult summarizes a series of games between agents A and B
+type SeriesResult struct {
+	AgentA string
+	AgentB string
+
+	WinsA int
+	WinsB int
+	Draws int
+
+	Games []SeriesGame
+
+	// Wins broken down by the board position of the game's final move
+	PositionWinsA [9]int
+	PositionWinsB [9]int
+
+	// Longest run of consecutive wins by each agent; draws end a streak
+	LongestStreakA int
+	LongestStreakB int
+
+	// PairScoresA holds agent A's points (win 1, draw 0.5) over each mirrored
+	// pair of games when MirrorDeals is set; 1.0 means the pair was split evenly
+	PairScoresA []float64
+}
+
+// WinRateA returns the share of games won by agent A, counting draws as half a win
+func (r SeriesResult) WinRateA() float64 {
+	if len(r.Games) == 0 {
+		return 0
+	}
+	return (float64(r.WinsA) + 0.5*float64(r.Draws)) / float64(len(r.Games))
+}
+
+// PlaySeries plays games between a and b and returns the aggregated results.
+// The series is a match: agents that keep state across games are reset first.
+// It prints nothing; forfeits are logged as warnings.
+func PlaySeries(a, b agents.Agent, games int, opts SeriesOptions) SeriesResult {
+	resetForMatch(a, b)
+	result := SeriesResult{
+		AgentA: a.Name(),
+		AgentB: b.Name(),
+		Games:  make([]SeriesGame, 0, games),
+	}
+
+	if opts.MirrorDeals && games%2 == 1 {
+		games++
+	}
+
+	streakA, streakB := 0, 0
+	var dealSeed int64
+	for i := 0; i < games; i++ {
+		var g SeriesGame
+		switch {
+		case opts.MirrorDeals:
+			if i%2 == 0 {
+				dealSeed = newDealSeed(opts.Rng)
+			}
+			gameOpts := opts
+			gameOpts.Rng = rand.New(rand.NewSource(dealSeed))
+			if i%2 == 0 {
+				g = playGame(a, b, gameOpts)
+			} else {
+				g = playGame(b, a, gameOpts)
+			}
+			g.DealSeed = dealSeed
+		case opts.AlternateFirst && i%2 == 1:
+			g = playGame(b, a, opts)
+		default:
+			g = playGame(a, b, opts)
+		}
+		result.Games = append(result.Games, g)
+
+		switch g.Winner {
+		case a.Name():
+			result.WinsA++
+			streakA++
+			streakB = 0
+			if g.FinalPosition >= 0 {
+				result.PositionWinsA[g.FinalPosition]++
+			}
+		case b.Name():
+			result.WinsB++
+			streakB++
+			streakA = 0
+			if g.FinalPosition >= 0 {
+				result.PositionWinsB[g.FinalPosition]++
+			}
+		default:
+			result.Draws++
+			streakA, streakB = 0, 0
+		}
+
+		if streakA > result.LongestStreakA {
+			result.LongestStreakA = streakA
+		}
+		if streakB > result.LongestStreakB {
+			result.LongestStreakB = streakB
+		}
+
+		if opts.MirrorDeals && i%2 == 1 {
+			result.PairScoresA = append(result.PairScoresA,
+				scoreFor(a.Name(), result.Games[i-1])+scoreFor(a.Name(), g))
+		}
+
+		if opts.OnGame != nil {
+			opts.OnGame(i+1, g)
+		}
+	}
+
+	return result
+}
+
+// newDealSeed draws the seed for a mirrored deal
+func newDealSeed(rng *rand.Rand) int64 {
+	if rng != nil {
+		return rng.Int63()
+	}
+	return rand.Int63()
+}
+
+// scoreFor returns the points name earned in g: 1 for a win, 0.5 for a draw
+func scoreFor(name string, g SeriesGame) float64 {
+	switch g.Winner {
+	case name:
+		return 1
+	case DrawResult:
+		return 0.5
+	}
+	return 0
+}
+
+// playGame plays one game with first moving as player 1. An agent that errors
+// or plays an invalid move forfeits the game.
+func playGame(first, second agents.Agent, opts SeriesOptions) SeriesGame {
+	gameState := game.NewRPSGameSeeded(opts.DeckSize, opts.HandSize, opts.MaxRounds, opts.Rng)
+	result := SeriesGame{
+		Player1:       first.Name(),
+		Player2:       second.Name(),
+		FinalPosition: -1,
+	}
+
+	opts.Recorder.StartGame(first.Name(), second.Name())
+	defer func() { opts.Recorder.EndGame(result.Winner) }()
+
+	for !gameState.IsGameOver() {
+		currentAgent, otherAgent := first, second
+		if gameState.CurrentPlayer == game.Player2 {
+			currentAgent, otherAgent = second, first
+		}
+
+		moveStart := time.Now()
+		move, err := currentAgent.GetMove(gameState.Copy())
+		elapsed := time.Since(moveStart)
+		if err != nil {
+			slog.Warn("Agent failed to move and forfeits the game", "agent", currentAgent.Name(), "error", err)
+			result.Winner = otherAgent.Name()
+			result.Forfeit = true
+			return result
+		}
+
+		move.Player = gameState.CurrentPlayer
+		opts.Recorder.RecordMove(currentAgent, gameState, move, elapsed)
+		if err := gameState.MakeMove(move); err != nil {
+			slog.Warn("Agent played an invalid move and forfeits the game", "agent", currentAgent.Name(), "error", err)
+			result.Winner = otherAgent.Name()
+			result.Forfeit = true
+			return result
+		}
+
+		result.Moves++
+		result.FinalPosition = move.Position
+		if opts.OnMove != nil {
+			opts.OnMove(currentAgent, move, gameState)
+		}
+	}
+
+	switch gameState.GetWinner() {
+	case game.Player1:
+		result.Winner = first.Name()
+	case game.Player2:
+		result.Winner = second.Name()
+	default:
+		result.Winner = DrawResult
+	}
+	return result
+}
+
+// resetForMatch resets the agents that keep state across the games of a match
+func resetForMatch(players ...agents.Agent) {
+	for _, agent := range players {
+		if resetter, ok := agent.(agents.Resetter); ok {
+			resetter.Reset()
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package tournament
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/agents"
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/mcts"
+)
+
+// failingAgent always returns an error so every game it plays is forfeited
+type failingAgent struct{ name string }
+
+func (a *failingAgent) Name() string { return a.name }
+
+func (a *failingAgent) GetMove(state *game.RPSGame) (game.RPSMove, error) {
+	return game.RPSMove{}, errors.New("no move")
+}
+
+func TestPlaySeriesTotals(t *testing.T) {
+	opts := DefaultSeriesOptions()
+	result := PlaySeries(agents.NewRandomAgent("A"), agents.NewRandomAgent("B"), 20, opts)
+
+	if len(result.Games) != 20 {
+		t.Fatalf("Expected 20 games, got %d", len(result.Games))
+	}
+	if result.WinsA+result.WinsB+result.Draws != 20 {
+		t.Errorf("Wins and draws add up to %d, expected 20", result.WinsA+result.WinsB+result.Draws)
+	}
+
+	positionWins := 0
+	for pos := 0; pos < 9; pos++ {
+		positionWins += result.PositionWinsA[pos] + result.PositionWinsB[pos]
+	}
+	if positionWins != result.WinsA+result.WinsB {
+		t.Errorf("Position wins add up to %d, expected %d", positionWins, result.WinsA+result.WinsB)
+	}
+
+	for i, g := range result.Games {
+		expectedFirst := "A"
+		if i%2 == 1 {
+			expectedFirst = "B"
+		}
+		if g.Player1 != expectedFirst {
+			t.Errorf("Game %d: expected %s to move first, got %s", i+1, expectedFirst, g.Player1)
+		}
+	}
+}
+
+func TestPlaySeriesForfeitsAndStreaks(t *testing.T) {
+	gamesSeen := 0
+	opts := DefaultSeriesOptions()
+	opts.OnGame = func(gameNumber int, g SeriesGame) { gamesSeen = gameNumber }
+
+	result := PlaySeries(agents.NewRandomAgent("A"), &failingAgent{name: "B"}, 6, opts)
+
+	if result.WinsA != 6 || result.WinsB != 0 || result.Draws != 0 {
+		t.Errorf("Expected 6-0-0, got %d-%d-%d", result.WinsA, result.WinsB, result.Draws)
+	}
+	if result.LongestStreakA != 6 || result.LongestStreakB != 0 {
+		t.Errorf("Expected streaks 6 and 0, got %d and %d", result.LongestStreakA, result.LongestStreakB)
+	}
+	for _, g := range result.Games {
+		if !g.Forfeit {
+			t.Errorf("Expected every game to be a forfeit: %+v", g)
+		}
+	}
+	if gamesSeen != 6 {
+		t.Errorf("Expected OnGame to see 6 games, saw %d", gamesSeen)
+	}
+	if result.WinRateA() != 1 {
+		t.Errorf("Expected win rate 1, got %.2f", result.WinRateA())
+	}
+}
+
+func TestPlaySeriesSeededDealsAreReproducible(t *testing.T) {
+	deals := func() []string {
+		var positions []string
+		opts := DefaultSeriesOptions()
+		opts.Rng = rand.New(rand.NewSource(7))
+		opts.Recorder = NewGameRecorder()
+		PlaySeries(agents.NewRandomAgent("A"), agents.NewRandomAgent("B"), 4, opts)
+		for _, g := range opts.Recorder.Games {
+			positions = append(positions, g.Plies[0].Position)
+		}
+		return positions
+	}
+
+	if first, second := deals(), deals(); !reflect.DeepEqual(first, second) {
+		t.Errorf("Seeded series dealt different games: %v vs %v", first, second)
+	}
+}
+
+func TestSeededTournamentIsReproducible(t *testing.T) {
+	// play runs a small tournament and returns every move of every game
+	play := func() ([]string, map[string]float64) {
+		params := mcts.DefaultRPSMCTSParams()
+		params.NumSimulations = 20
+
+		tm := NewTournamentManager(false)
+		tm.LeaderboardInterval = 0
+		tm.Recorder = NewGameRecorder()
+		tm.AddAgent(agents.NewRandomAgent("A"))
+		tm.AddAgent(agents.NewRandomAgent("B"))
+		tm.AddAgent(agents.NewMCTSAgent("Rollout", mcts.NewRolloutMCTS(params)))
+		tm.Seed(42)
+		tm.RunTournament(4, 0)
+
+		var moves []string
+		for _, g := range tm.Recorder.Games {
+			moves = append(moves, fmt.Sprintf("%s vs %s: %s", g.Player1, g.Player2, g.Result))
+			for _, ply := range g.Plies {
+				moves = append(moves, fmt.Sprintf("%s %s card %d to %d", ply.Agent, ply.Position, ply.CardIndex, ply.Square))
+			}
+		}
+		return moves, tm.EloRatings
+	}
+
+	firstMoves, firstRatings := play()
+	secondMoves, secondRatings := play()
+	if !reflect.DeepEqual(firstMoves, secondMoves) {
+		t.Errorf("Seeded tournaments played different games:\n%v\nvs\n%v", firstMoves, secondMoves)
+	}
+	if !reflect.DeepEqual(firstRatings, secondRatings) {
+		t.Errorf("Seeded tournaments ended with different ratings: %v vs %v", firstRatings, secondRatings)
+	}
+}
+
+func TestPlaySeriesMirrorDeals(t *testing.T) {
+	opts := DefaultSeriesOptions()
+	opts.MirrorDeals = true
+	opts.Recorder = NewGameRecorder()
+
+	result := PlaySeries(agents.NewRandomAgent("A"), agents.NewRandomAgent("B"), 5, opts)
+
+	if len(result.Games) != 6 {
+		t.Fatalf("Expected the series to be rounded up to 6 games, got %d", len(result.Games))
+	}
+	if len(result.PairScoresA) != 3 {
+		t.Fatalf("Expected 3 pair scores, got %d", len(result.PairScoresA))
+	}
+
+	total := 0.0
+	for i := 0; i < len(result.Games); i += 2 {
+		first, second := result.Games[i], result.Games[i+1]
+		if first.Player1 != "A" || second.Player1 != "B" {
+			t.Errorf("Pair %d: expected A then B to move first, got %s then %s", i/2, first.Player1, second.Player1)
+		}
+		if first.DealSeed != second.DealSeed {
+			t.Errorf("Pair %d was dealt from different seeds", i/2)
+		}
+
+		// Both games of a pair start from the same deal
+		if p1, p2 := opts.Recorder.Games[i].Plies[0].Position, opts.Recorder.Games[i+1].Plies[0].Position; p1 != p2 {
+			t.Errorf("Pair %d: expected identical deals, got %s and %s", i/2, p1, p2)
+		}
+		total += result.PairScoresA[i/2]
+	}
+
+	if expected := float64(result.WinsA) + 0.5*float64(result.Draws); total != expected {
+		t.Errorf("Pair scores add up to %.1f, expected %.1f", total, expected)
+	}
+}
@@ -0,0 +1,308 @@
+package tournament
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/agents"
+)
+
+// DrawResult is returned by PlayGame when neither agent wins
+const DrawResult = "draw"
+
+// GameRecord tracks game results between two agents
+type GameRecord struct {
+	Wins   int `json:"wins"`
+	Losses int `json:"losses"`
+	Draws  int `json:"draws"`
+}
+
+// TournamentManager handles matches between agents and ELO calculations
+type TournamentManager struct {
+	Agents      []agents.Agent
+	EloRatings  map[string]float64
+	GameResults map[string]map[string]*GameRecord
+	VerboseMode bool // Log the result of every game
+
+	// Game parameters used for every match
+	DeckSize  int
+	HandSize  int
+	MaxRounds int
+
+	// LeaderboardInterval shows the leaderboard every N matchups (0 disables it)
+	LeaderboardInterval int
+
+	// Recorder, when set, logs every move played by PlayGame
+	Recorder *GameRecorder
+
+	// Monitor, when set, follows RunTournament's progress
+	Monitor Monitor
+
+	// Rng, when set, chooses the first player and deals every game. The global
+	// random source is used when it is nil. Seed sets it.
+	Rng *rand.Rand
+}
+
+// Monitor follows a tournament as it runs, for example to export its progress
+// to a dashboard
+type Monitor interface {
+	// TournamentGameFinished is called after each game with the games played
+	// so far, the rate they are being played at and the current ratings. The
+	// ratings map belongs to the tournament and must not be kept.
+	TournamentGameFinished(gamesPlayed int, gamesPerSecond float64, ratings map[string]float64)
+
+	// MatchupFinished is called after each matchup with the matchups played
+	// so far and the number scheduled when the tournament began, and once
+	// with none played as it begins
+	MatchupFinished(played, scheduled int)
+}
+
+// NewTournamentManager creates a new tournament manager using the standard game parameters
+func NewTournamentManager(verbose bool) *TournamentManager {
+	return &TournamentManager{
+		Agents:              make([]agents.Agent, 0),
+		EloRatings:          make(map[string]float64),
+		GameResults:         make(map[string]map[string]*GameRecord),
+		VerboseMode:         verbose,
+		DeckSize:            21,
+		HandSize:            5,
+		MaxRounds:           10,
+		LeaderboardInterval: 5,
+	}
+}
+
+// AddAgent adds an agent to the tournament
+func (tm *TournamentManager) AddAgent(agent agents.Agent) {
+	tm.Agents = append(tm.Agents, agent)
+	tm.EloRatings[agent.Name()] = DefaultElo
+	tm.GameResults[agent.Name()] = make(map[string]*GameRecord)
+
+	// Initialize game records for this agent
+	for _, otherAgent := range tm.Agents {
+		if otherAgent.Name() != agent.Name() {
+			tm.GameResults[agent.Name()][otherAgent.Name()] = &GameRecord{}
+			if _, exists := tm.GameResults[otherAgent.Name()][agent.Name()]; !exists {
+				tm.GameResults[otherAgent.Name()][agent.Name()] = &GameRecord{}
+			}
+		}
+	}
+}
+
+// Seed makes the tournament reproducible: the first player and deal of every
+// game, and the choices of every agent implementing agents.Seeder, all come
+// from seed. Call it after adding the agents. Agents that stop searching at a
+// time limit, such as minimax agents, can still play differently from run to
+// run on a machine under different load.
+func (tm *TournamentManager) Seed(seed int64) {
+	tm.Rng = rand.New(rand.NewSource(seed))
+	for _, agent := range tm.Agents {
+		// Every agent draws a seed, whether it uses one or not, so an
+		// agent's seed depends only on its place in the tournament
+		agentSeed := tm.Rng.Int63()
+		if seeder, ok := agent.(agents.Seeder); ok {
+			seeder.Seed(agentSeed)
+		}
+	}
+}
+
+// PlayGame plays a single game between two agents and returns the winner's name,
+// or DrawResult. The first player is chosen at random. An agent that errors or
+// plays an invalid move forfeits the game.
+func (tm *TournamentManager) PlayGame(agent1, agent2 agents.Agent) string {
+	opts := SeriesOptions{
+		DeckSize:  tm.DeckSize,
+		HandSize:  tm.HandSize,
+		MaxRounds: tm.MaxRounds,
+		Recorder:  tm.Recorder,
+		Rng:       tm.Rng,
+	}
+
+	// Determine who goes first randomly
+	var first int
+	if tm.Rng != nil {
+		first = tm.Rng.Intn(2)
+	} else {
+		first = rand.Intn(2)
+	}
+	if first == 0 {
+		return playGame(agent1, agent2, opts).Winner
+	}
+	return playGame(agent2, agent1, opts).Winner
+}
+
+// RecordResult updates head-to-head records and ELO ratings for one game
+// between agent1 and agent2, where result is the value returned by PlayGame
+func (tm *TournamentManager) RecordResult(agent1, agent2, result string) {
+	switch result {
+	case agent1:
+		tm.GameResults[agent1][agent2].Wins++
+		tm.GameResults[agent2][agent1].Losses++
+		tm.UpdateElo(agent1, agent2)
+	case agent2:
+		tm.GameResults[agent2][agent1].Wins++
+		tm.GameResults[agent1][agent2].Losses++
+		tm.UpdateElo(agent2, agent1)
+	default:
+		tm.GameResults[agent1][agent2].Draws++
+		tm.GameResults[agent2][agent1].Draws++
+		tm.UpdateEloForDraw(agent1, agent2)
+	}
+}
+
+// RunTournament runs a round robin between all agents. When eloCutoff is positive,
+// agents whose rating falls below it are dropped from the remaining matchups.
+func (tm *TournamentManager) RunTournament(gamesPerPair int, eloCutoff float64) {
+	slog.Info("Starting tournament", "agents", len(tm.Agents), "games_per_pair", gamesPerPair)
+
+	if eloCutoff > 0 {
+		slog.Info("Agents below the ELO cutoff will be removed from the tournament", "cutoff", eloCutoff)
+	}
+
+	// Active agents list (will be pruned as tournament progresses if cutoff is enabled)
+	activeAgents := make([]agents.Agent, len(tm.Agents))
+	copy(activeAgents, tm.Agents)
+
+	// Track matchups played to avoid repeats
+	matchupsPlayed := make(map[string]bool)
+
+	totalMatchups := len(activeAgents) * (len(activeAgents) - 1) / 2
+	slog.Info("Scheduled matchups", "matchups", totalMatchups)
+	if tm.Monitor != nil {
+		tm.Monitor.MatchupFinished(0, totalMatchups)
+	}
+
+	gameCount := 0
+	matchupCount := 0
+	startTime := time.Now()
+
+	// Continue until all matchups are played
+	for {
+		// Break if there are fewer than 2 active agents
+		if len(activeAgents) < 2 {
+			break
+		}
+
+		// Find next pair of agents to play
+		agent1, agent2, found := selectNextMatchup(activeAgents, matchupsPlayed)
+		if !found {
+			break // No more matchups to play
+		}
+
+		matchupKey := getMatchupKey(agent1.Name(), agent2.Name())
+		matchupsPlayed[matchupKey] = true
+		matchupCount++
+
+		slog.Info("Match",
+			"agent1", agent1.Name(), "elo1", math.Round(tm.EloRatings[agent1.Name()]),
+			"agent2", agent2.Name(), "elo2", math.Round(tm.EloRatings[agent2.Name()]),
+			"games", gamesPerPair)
+
+		resetForMatch(agent1, agent2)
+		wins1, wins2, draws := 0, 0, 0
+
+		for k := 0; k < gamesPerPair; k++ {
+			result := tm.PlayGame(agent1, agent2)
+			gameCount++
+
+			// Update statistics and ELO ratings
+			tm.RecordResult(agent1.Name(), agent2.Name(), result)
+			if tm.VerboseMode {
+				slog.Info("Game finished", "game", gameCount, "agent1", agent1.Name(), "agent2", agent2.Name(), "result", result)
+			}
+			if tm.Monitor != nil {
+				tm.Monitor.TournamentGameFinished(gameCount, float64(gameCount)/time.Since(startTime).Seconds(), tm.EloRatings)
+			}
+			switch result {
+			case agent1.Name():
+				wins1++
+			case agent2.Name():
+				wins2++
+			default:
+				draws++
+			}
+
+			// Report progress every 10 games
+			if gameCount%10 == 0 {
+				elapsed := time.Since(startTime)
+				slog.Debug("Progress", "games", gameCount,
+					"games_per_sec", float64(gameCount)/elapsed.Seconds(),
+					"matchup", matchupCount, "wins1", wins1, "wins2", wins2, "draws", draws)
+			}
+		}
+
+		// Print match results
+		slog.Info("Result",
+			"agent1", agent1.Name(), "wins1", wins1,
+			"agent2", agent2.Name(), "wins2", wins2, "draws", draws,
+			"elo1", math.Round(tm.EloRatings[agent1.Name()]),
+			"elo2", math.Round(tm.EloRatings[agent2.Name()]))
+		if tm.Monitor != nil {
+			tm.Monitor.MatchupFinished(matchupCount, totalMatchups)
+		}
+
+		// Show current leaderboard periodically
+		if tm.LeaderboardInterval > 0 && matchupCount%tm.LeaderboardInterval == 0 {
+			fmt.Println("\n--- Current Leaderboard ---")
+			tm.PrintTopRankings(10) // Show top 10 agents
+			fmt.Println()
+		}
+
+		// Prune weak agents if cutoff is enabled
+		if eloCutoff > 0 {
+			prunedAgents := tm.pruneWeakAgents(activeAgents, eloCutoff)
+			if len(prunedAgents) < len(activeAgents) {
+				activeAgents = prunedAgents
+				slog.Info("Pruned agents below the ELO cutoff", "cutoff", eloCutoff, "remaining", len(activeAgents))
+			}
+		}
+	}
+
+	elapsed := time.Since(startTime)
+	slog.Info("Tournament completed", "elapsed", elapsed.Round(time.Millisecond),
+		"games", gameCount, "matchups", matchupCount,
+		"games_per_sec", float64(gameCount)/elapsed.Seconds())
+}
+
+// selectNextMatchup selects the next pair of agents that have not played yet
+func selectNextMatchup(active []agents.Agent, played map[string]bool) (agent1, agent2 agents.Agent, found bool) {
+	for i := 0; i < len(active); i++ {
+		for j := i + 1; j < len(active); j++ {
+			a1 := active[i]
+			a2 := active[j]
+			key := getMatchupKey(a1.Name(), a2.Name())
+
+			if !played[key] {
+				return a1, a2, true
+			}
+		}
+	}
+
+	return nil, nil, false
+}
+
+// getMatchupKey creates a unique key for a matchup between two agents
+func getMatchupKey(name1, name2 string) string {
+	// Ensure consistent ordering of names
+	if name1 < name2 {
+		return name1 + ":" + name2
+	}
+	return name2 + ":" + name1
+}
+
+// pruneWeakAgents removes agents below the ELO threshold
+func (tm *TournamentManager) pruneWeakAgents(active []agents.Agent, threshold float64) []agents.Agent {
+	if threshold <= 0 {
+		return active // No pruning if threshold is disabled
+	}
+
+	filtered := make([]agents.Agent, 0, len(active))
+	for _, agent := range active {
+		if tm.EloRatings[agent.Name()] >= threshold {
+			filtered = append(filtered, agent)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,77 @@
+package tournament
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/agents"
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
+)
+
+// AgentCheck is the outcome of validating one agent
+type AgentCheck struct {
+	Agent string
+	Move  game.RPSMove // The move the agent chose, if it chose a legal one
+	Err   error        // Why the agent is broken, or nil if it is OK
+}
+
+// OK reports whether the agent passed
+func (c AgentCheck) OK() bool {
+	return c.Err == nil
+}
+
+// ValidateAgent asks agent for one move in state and checks that the move is
+// legal, without playing the game out. An agent that panics is reported as
+// broken rather than stopping the caller.
+func ValidateAgent(agent agents.Agent, state *game.RPSGame) (check AgentCheck) {
+	check.Agent = agent.Name()
+	defer func() {
+		if r := recover(); r != nil {
+			check.Err = fmt.Errorf("panicked: %v", r)
+		}
+	}()
+
+	move, err := agent.GetMove(state.Copy())
+	if err != nil {
+		check.Err = fmt.Errorf("failed to move: %v", err)
+		return check
+	}
+
+	// Play the move on a copy, as a game would, to check it is legal
+	move.Player = state.CurrentPlayer
+	if err := state.Copy().MakeMove(move); err != nil {
+		check.Err = fmt.Errorf("played an invalid move: %v", err)
+		return check
+	}
+	check.Move = move
+	return check
+}
+
+// Validate checks that every agent in the tournament can make a legal move in
+// the opening position of a game with the tournament's parameters. The deal
+// comes from seed, so every agent is given the same position.
+func (tm *TournamentManager) Validate(seed int64) []AgentCheck {
+	state := game.NewRPSGameSeeded(tm.DeckSize, tm.HandSize, tm.MaxRounds, rand.New(rand.NewSource(seed)))
+	checks := make([]AgentCheck, len(tm.Agents))
+	for i, agent := range tm.Agents {
+		checks[i] = ValidateAgent(agent, state)
+	}
+	return checks
+}
+
+// PrintValidation prints one line per agent and a summary, and returns the
+// number of broken agents
+func PrintValidation(checks []AgentCheck) int {
+	broken := 0
+	fmt.Println("=== Agent Validation ===")
+	for _, check := range checks {
+		if check.OK() {
+			fmt.Printf("OK      %-40s card %d to position %d\n", check.Agent, check.Move.CardIndex, check.Move.Position)
+		} else {
+			broken++
+			fmt.Printf("BROKEN  %-40s %v\n", check.Agent, check.Err)
+		}
+	}
+	fmt.Printf("\n%d of %d agents OK\n", len(checks)-broken, len(checks))
+	return broken
+}
@@ -0,0 +1,65 @@
+package tournament
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/agents"
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
+)
+
+// offBoardAgent always plays to a square that does not exist
+type offBoardAgent struct{}
+
+func (offBoardAgent) Name() string { return "OffBoard" }
+
+func (offBoardAgent) GetMove(state *game.RPSGame) (game.RPSMove, error) {
+	return game.RPSMove{CardIndex: 0, Position: len(state.Board)}, nil
+}
+
+// panickingAgent panics instead of moving
+type panickingAgent struct{}
+
+func (panickingAgent) Name() string { return "Panicking" }
+
+func (panickingAgent) GetMove(state *game.RPSGame) (game.RPSMove, error) {
+	panic("no network")
+}
+
+func TestValidate(t *testing.T) {
+	tm := NewTournamentManager(false)
+	tm.AddAgent(agents.NewRandomAgent("Random"))
+	tm.AddAgent(&failingAgent{name: "Failing"})
+	tm.AddAgent(offBoardAgent{})
+	tm.AddAgent(panickingAgent{})
+
+	checks := tm.Validate(1)
+	if len(checks) != 4 {
+		t.Fatalf("Expected 4 checks, got %d", len(checks))
+	}
+
+	expected := []struct {
+		agent string
+		err   string // Empty for an agent that should pass
+	}{
+		{"Random", ""},
+		{"Failing", "failed to move"},
+		{"OffBoard", "invalid move"},
+		{"Panicking", "panicked"},
+	}
+	for i, want := range expected {
+		check := checks[i]
+		if check.Agent != want.agent {
+			t.Errorf("Check %d: expected agent %s, got %s", i, want.agent, check.Agent)
+		}
+		if want.err == "" {
+			if !check.OK() {
+				t.Errorf("%s: expected OK, got %v", check.Agent, check.Err)
+			}
+			continue
+		}
+		if check.OK() || !strings.Contains(check.Err.Error(), want.err) {
+			t.Errorf("%s: expected an error containing %q, got %v", check.Agent, want.err, check.Err)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package training
+
+import (
+	"fmt"
+	"io"
+)
+
+// ConfusionMatrix counts a policy's top moves against the labelled best moves:
+// Counts[label][predicted] is the number of positions labelled label where the
+// policy's most likely move was predicted
+type ConfusionMatrix struct {
+	Counts [][]int
+}
+
+// NewConfusionMatrix runs predict on every input and tallies its most likely
+// move against the one-hot or soft target's best move
+func NewConfusionMatrix(predict func(features []float64) []float64, inputs, targets [][]float64) ConfusionMatrix {
+	size := 0
+	if len(targets) > 0 {
+		size = len(targets[0])
+	}
+	m := ConfusionMatrix{Counts: make([][]int, size)}
+	for i := range m.Counts {
+		m.Counts[i] = make([]int, size)
+	}
+
+	for i, input := range inputs {
+		m.Counts[argmax(targets[i])][argmax(predict(input))]++
+	}
+	return m
+}
+
+// Samples returns the number of positions labelled with the given move
+func (m ConfusionMatrix) Samples(label int) int {
+	total := 0
+	for _, count := range m.Counts[label] {
+		total += count
+	}
+	return total
+}
+
+// Accuracy returns the fraction of positions labelled with the given move
+// where the policy chose it too, or 0 if there are none
+func (m ConfusionMatrix) Accuracy(label int) float64 {
+	samples := m.Samples(label)
+	if samples == 0 {
+		return 0
+	}
+	return float64(m.Counts[label][label]) / float64(samples)
+}
+
+// OverallAccuracy returns the fraction of all positions where the policy chose
+// the labelled move
+func (m ConfusionMatrix) OverallAccuracy() float64 {
+	correct, total := 0, 0
+	for label := range m.Counts {
+		correct += m.Counts[label][label]
+		total += m.Samples(label)
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(correct) / float64(total)
+}
+
+// WriteReport writes the accuracy and sample count for each labelled position
+// followed by the full matrix
+func (m ConfusionMatrix) WriteReport(w io.Writer) {
+	fmt.Fprintln(w, "Position | Accuracy | Samples")
+	fmt.Fprintln(w, "---------|----------|--------")
+	for label := range m.Counts {
+		fmt.Fprintf(w, "   %d     |  %5.1f%%  | %6d\n", label, m.Accuracy(label)*100, m.Samples(label))
+	}
+
+	fmt.Fprintln(w, "\nConfusion matrix (rows: labelled move, columns: predicted move):")
+	fmt.Fprint(w, "     ")
+	for predicted := range m.Counts {
+		fmt.Fprintf(w, " %5d", predicted)
+	}
+	fmt.Fprintln(w)
+	for label, row := range m.Counts {
+		fmt.Fprintf(w, "  %d: ", label)
+		for _, count := range row {
+			fmt.Fprintf(w, " %5d", count)
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// argmax returns the index of the largest value
+func argmax(values []float64) int {
+	best := 0
+	for i, v := range values {
+		if v > values[best] {
+			best = i
+		}
+	}
+	return best
+}
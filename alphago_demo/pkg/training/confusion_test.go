@@ -0,0 +1,40 @@
+package training
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestConfusionMatrix(t *testing.T) {
+	oneHot := func(move int) []float64 {
+		v := make([]float64, 9)
+		v[move] = 1
+		return v
+	}
+
+	// The input's first value is the move the fake policy picks
+	inputs := [][]float64{{0}, {0}, {2}, {4}, {4}, {8}}
+	targets := [][]float64{oneHot(0), oneHot(0), oneHot(0), oneHot(4), oneHot(4), oneHot(4)}
+	predict := func(features []float64) []float64 { return oneHot(int(features[0])) }
+
+	m := NewConfusionMatrix(predict, inputs, targets)
+	if m.Samples(0) != 3 || m.Samples(4) != 3 || m.Samples(8) != 0 {
+		t.Errorf("Samples = %d, %d, %d; want 3, 3, 0", m.Samples(0), m.Samples(4), m.Samples(8))
+	}
+	if m.Accuracy(0) != 2.0/3 || m.Accuracy(4) != 2.0/3 || m.Accuracy(8) != 0 {
+		t.Errorf("Accuracy = %v, %v, %v; want 2/3, 2/3, 0", m.Accuracy(0), m.Accuracy(4), m.Accuracy(8))
+	}
+	if m.Counts[0][2] != 1 || m.Counts[4][8] != 1 {
+		t.Errorf("Misses not recorded: %v", m.Counts)
+	}
+	if m.OverallAccuracy() != 4.0/6 {
+		t.Errorf("OverallAccuracy = %v, want 4/6", m.OverallAccuracy())
+	}
+
+	var report bytes.Buffer
+	m.WriteReport(&report)
+	if !strings.Contains(report.String(), "   0     |   66.7%  |      3") {
+		t.Errorf("Report does not show position 0's accuracy:\n%s", report.String())
+	}
+}
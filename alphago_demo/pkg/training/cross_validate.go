@@ -0,0 +1,65 @@
+package training
+
+import (
+	"math"
+
+	neural "github.com/zachbeta/neural_rps/alphago_demo/pkg/rps_net_impl"
+)
+
+// crossValidationSeed fixes how CrossValidate assigns positions to folds, so
+// that every run and every set of hyperparameters sees the same folds
+const crossValidationSeed = 1
+
+// CrossValidate runs k-fold cross-validation: it splits examples into k folds
+// and, for each fold, trains a network with trainFn on the other k-1 folds and
+// scores it with evalFn on the fold held out. It returns the k scores in fold
+// order.
+//
+// As with SplitDataset, all examples of a position fall in the same fold.
+// Positions are shuffled with a fixed seed and dealt to the smallest fold
+// first, so folds have about equal size and are the same on every call.
+func CrossValidate(
+	examples []TrainingExample,
+	k int,
+	trainFn func(train []TrainingExample) *neural.RPSPolicyNetwork,
+	evalFn func(network *neural.RPSPolicyNetwork, val []TrainingExample) float64) []float64 {
+
+	folds := make([][]TrainingExample, k)
+	for _, group := range shuffledPositions(examples, crossValidationSeed) {
+		smallest := 0
+		for i := range folds {
+			if len(folds[i]) < len(folds[smallest]) {
+				smallest = i
+			}
+		}
+		folds[smallest] = append(folds[smallest], group...)
+	}
+
+	scores := make([]float64, k)
+	for i, val := range folds {
+		var train []TrainingExample
+		for j, fold := range folds {
+			if j != i {
+				train = append(train, fold...)
+			}
+		}
+		scores[i] = evalFn(trainFn(train), val)
+	}
+	return scores
+}
+
+// MeanStd returns the mean and population standard deviation of scores
+func MeanStd(scores []float64) (mean, std float64) {
+	if len(scores) == 0 {
+		return 0, 0
+	}
+	for _, score := range scores {
+		mean += score
+	}
+	mean /= float64(len(scores))
+
+	for _, score := range scores {
+		std += (score - mean) * (score - mean)
+	}
+	return mean, math.Sqrt(std / float64(len(scores)))
+}
@@ -0,0 +1,70 @@
+package training
+
+import (
+	"math"
+	"testing"
+
+	neural "github.com/zachbeta/neural_rps/alphago_demo/pkg/rps_net_impl"
+)
+
+func TestCrossValidate(t *testing.T) {
+	examples := append(makeExamples(0, 100), makeExamples(0, 20)...)
+
+	// Record which positions each fold trained and was scored on
+	var trainSets, valSets [][]TrainingExample
+	scores := CrossValidate(examples, 4,
+		func(train []TrainingExample) *neural.RPSPolicyNetwork {
+			trainSets = append(trainSets, train)
+			return neural.NewRPSPolicyNetwork(8)
+		},
+		func(network *neural.RPSPolicyNetwork, val []TrainingExample) float64 {
+			valSets = append(valSets, val)
+			return float64(len(val))
+		})
+
+	if len(scores) != 4 || len(trainSets) != 4 {
+		t.Fatalf("Got %d scores from %d trainings, want 4 of each", len(scores), len(trainSets))
+	}
+
+	seenInVal := make(map[uint64]int)
+	for i := range valSets {
+		if len(trainSets[i])+len(valSets[i]) != len(examples) {
+			t.Errorf("Fold %d: %d training + %d validation examples, want %d", i, len(trainSets[i]), len(valSets[i]), len(examples))
+		}
+		if len(valSets[i]) < 25 || len(valSets[i]) > 35 {
+			t.Errorf("Fold %d holds out %d examples, want about 30", i, len(valSets[i]))
+		}
+
+		trained := make(map[uint64]bool)
+		for _, example := range trainSets[i] {
+			trained[example.Game().Hash()] = true
+		}
+		for _, example := range valSets[i] {
+			hash := example.Game().Hash()
+			if trained[hash] {
+				t.Fatalf("Fold %d: a held-out position was also trained on", i)
+			}
+			seenInVal[hash] = i
+		}
+	}
+	if len(seenInVal) != UniquePositions(examples) {
+		t.Errorf("%d positions were held out, want all %d", len(seenInVal), UniquePositions(examples))
+	}
+
+	// Folds are the same on every call
+	again := CrossValidate(examples, 4,
+		func(train []TrainingExample) *neural.RPSPolicyNetwork { return nil },
+		func(network *neural.RPSPolicyNetwork, val []TrainingExample) float64 { return float64(len(val)) })
+	for i := range scores {
+		if scores[i] != again[i] {
+			t.Errorf("Fold %d has %v examples, then %v on a second call", i, scores[i], again[i])
+		}
+	}
+}
+
+func TestMeanStd(t *testing.T) {
+	mean, std := MeanStd([]float64{2, 4, 4, 4, 5, 5, 7, 9})
+	if mean != 5 || math.Abs(std-2) > 1e-12 {
+		t.Errorf("MeanStd = %v, %v; want 5, 2", mean, std)
+	}
+}
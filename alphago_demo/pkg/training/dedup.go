@@ -0,0 +1,42 @@
+package training
+
+// DeduplicateExamples merges examples of the same position, matched by their
+// Zobrist hash, into one. The merged example keeps the first one's features,
+// and its policy target and outcome are the averages over all of them, so a
+// position reached in many games is learned once with a smoothed target
+// instead of dominating the dataset. Positions keep the order in which they
+// first appear, so the result is deterministic.
+func DeduplicateExamples(examples []RPSTrainingExample) []RPSTrainingExample {
+	var merged []RPSTrainingExample
+	var counts []int
+	indexOf := make(map[uint64]int)
+	for _, example := range examples {
+		i, ok := indexOf[example.Hash]
+		if !ok {
+			indexOf[example.Hash] = len(merged)
+			example.PolicyTarget = append([]float64(nil), example.PolicyTarget...)
+			merged = append(merged, example)
+			counts = append(counts, 1)
+			continue
+		}
+
+		sum := &merged[i]
+		for move, p := range example.PolicyTarget {
+			if move < len(sum.PolicyTarget) {
+				sum.PolicyTarget[move] += p
+			}
+		}
+		sum.Outcome += example.Outcome
+		counts[i]++
+	}
+
+	for i := range merged {
+		n := float64(counts[i])
+		for move := range merged[i].PolicyTarget {
+			merged[i].PolicyTarget[move] /= n
+		}
+		merged[i].Outcome /= n
+		merged[i].ValueTarget = OutcomeValueTarget(merged[i].Outcome)
+	}
+	return merged
+}
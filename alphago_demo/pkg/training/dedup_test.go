@@ -0,0 +1,66 @@
+package training
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
+	neural "github.com/zachbeta/neural_rps/alphago_demo/pkg/rps_net_impl"
+)
+
+func TestDeduplicateExamplesAveragesTargets(t *testing.T) {
+	g := game.NewRPSGame(21, 5, 10)
+	repeat := g.Copy()
+	other := g.Copy()
+	move, _ := other.GetRandomMove()
+	other.MakeMove(move)
+
+	// The same position played two different ways, once won and once lost
+	examples := []RPSTrainingExample{
+		{BoardState: g.GetBoardAsFeatures(), PolicyTarget: oneHot(0), Outcome: 1, ValueTarget: 1, Hash: g.Hash()},
+		{BoardState: other.GetBoardAsFeatures(), PolicyTarget: oneHot(2), Outcome: 1, ValueTarget: 1, Hash: other.Hash()},
+		{BoardState: repeat.GetBoardAsFeatures(), PolicyTarget: oneHot(4), Outcome: -1, ValueTarget: 0, Hash: repeat.Hash()},
+	}
+
+	merged := DeduplicateExamples(examples)
+	if len(merged) != 2 {
+		t.Fatalf("Got %d examples, want 2", len(merged))
+	}
+
+	wantPolicy := []float64{0.5, 0, 0, 0, 0.5, 0, 0, 0, 0}
+	if !reflect.DeepEqual(merged[0].PolicyTarget, wantPolicy) {
+		t.Errorf("Merged policy target = %v, want %v", merged[0].PolicyTarget, wantPolicy)
+	}
+	if merged[0].Outcome != 0 || merged[0].ValueTarget != 0.5 {
+		t.Errorf("Merged outcome %v and value target %v, want 0 and 0.5", merged[0].Outcome, merged[0].ValueTarget)
+	}
+	if !reflect.DeepEqual(merged[1], examples[1]) {
+		t.Errorf("Unrepeated example changed to %+v", merged[1])
+	}
+	if !reflect.DeepEqual(examples[0].PolicyTarget, oneHot(0)) {
+		t.Errorf("Input policy target changed to %v", examples[0].PolicyTarget)
+	}
+}
+
+func TestRPSSelfPlayDedup(t *testing.T) {
+	params := DefaultRPSSelfPlayParams()
+	params.NumGames = 4
+	params.MCTSParams.NumSimulations = 5
+	params.Dedup = true
+	selfPlay := NewRPSSelfPlay(neural.NewRPSPolicyNetwork(16), neural.NewRPSValueNetwork(16), params)
+
+	seen := make(map[uint64]bool)
+	for _, example := range selfPlay.GenerateGames(false) {
+		if example.Hash == 0 || seen[example.Hash] {
+			t.Fatalf("Example hash %x is missing or repeated", example.Hash)
+		}
+		seen[example.Hash] = true
+	}
+}
+
+// oneHot returns a policy target with all weight on one board position
+func oneHot(position int) []float64 {
+	target := make([]float64, 9)
+	target[position] = 1
+	return target
+}
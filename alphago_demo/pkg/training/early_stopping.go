@@ -0,0 +1,59 @@
+package training
+
+import (
+	"math"
+
+	neural "github.com/zachbeta/neural_rps/alphago_demo/pkg/rps_net_impl"
+)
+
+// EarlyStopping ends training once the validation loss has not improved for
+// Patience epochs, and keeps a copy of the network from the best epoch so that
+// the model saved is the best one found rather than the last one trained
+type EarlyStopping struct {
+	Patience int
+
+	bestLoss  float64
+	bestEpoch int
+	best      *neural.RPSPolicyNetwork
+	waited    int
+}
+
+// NewEarlyStopping creates an EarlyStopping that waits patience epochs for an
+// improvement
+func NewEarlyStopping(patience int) *EarlyStopping {
+	return &EarlyStopping{Patience: patience, bestLoss: math.Inf(1), bestEpoch: -1}
+}
+
+// Update records the validation loss after an epoch, snapshotting the network
+// if it is the best so far, and reports whether training should stop
+func (e *EarlyStopping) Update(epoch int, valLoss float64, network *neural.RPSPolicyNetwork) bool {
+	if valLoss < e.bestLoss {
+		e.bestLoss = valLoss
+		e.bestEpoch = epoch
+		e.best = network.Clone()
+		e.waited = 0
+		return false
+	}
+	e.waited++
+	return e.waited >= e.Patience
+}
+
+// BestEpoch returns the epoch with the lowest validation loss, or -1 before
+// the first Update
+func (e *EarlyStopping) BestEpoch() int {
+	return e.bestEpoch
+}
+
+// BestLoss returns the lowest validation loss seen
+func (e *EarlyStopping) BestLoss() float64 {
+	return e.bestLoss
+}
+
+// Restore copies the weights from the best epoch back into network. It does
+// nothing before the first Update.
+func (e *EarlyStopping) Restore(network *neural.RPSPolicyNetwork) error {
+	if e.best == nil {
+		return nil
+	}
+	return network.CopyWeightsFrom(e.best)
+}
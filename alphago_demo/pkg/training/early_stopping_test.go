@@ -0,0 +1,52 @@
+package training
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
+	neural "github.com/zachbeta/neural_rps/alphago_demo/pkg/rps_net_impl"
+)
+
+func TestEarlyStoppingRestoresBestWeights(t *testing.T) {
+	// Seeded, since at this learning rate some initializations lose every
+	// hidden unit and the weights then stop changing
+	network := neural.NewRPSPolicyNetworkSeeded(8, 1)
+	inputs, targets := ExampleFeatures(makeExamples(0, 16))
+	probe := network.EncodeState(game.NewRPSGame(21, 5, 10))
+
+	// The loss is best after the second epoch and worsens from then on
+	losses := []float64{0.9, 0.4, 0.6, 0.8, 1.0}
+	stopper := NewEarlyStopping(3)
+	var bestWeights, bestOutput []float64
+	stoppedAt := -1
+	for epoch, loss := range losses {
+		network.Train(inputs, targets, 0.5)
+		if epoch == 1 {
+			bestWeights = network.GetWeights()
+			bestOutput = network.PredictFeatures(probe)
+		}
+		if stopper.Update(epoch, loss, network) {
+			stoppedAt = epoch
+			break
+		}
+	}
+
+	if stoppedAt != 4 || stopper.BestEpoch() != 1 || stopper.BestLoss() != 0.4 {
+		t.Fatalf("Stopped at epoch %d with best epoch %d (loss %v), want 4, 1 and 0.4",
+			stoppedAt, stopper.BestEpoch(), stopper.BestLoss())
+	}
+	if reflect.DeepEqual(network.GetWeights(), bestWeights) {
+		t.Fatal("Training after the best epoch did not change the weights")
+	}
+
+	if err := stopper.Restore(network); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if !reflect.DeepEqual(network.GetWeights(), bestWeights) {
+		t.Error("Restored weights differ from those of the best epoch")
+	}
+	if !reflect.DeepEqual(network.PredictFeatures(probe), bestOutput) {
+		t.Error("Restored network predicts differently from the best epoch, so its biases were not restored")
+	}
+}
@@ -0,0 +1,190 @@
+package training
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
+	neural "github.com/zachbeta/neural_rps/alphago_demo/pkg/rps_net_impl"
+)
+
+// NewTrainingExample records a position and the move minimax chose in it
+func NewTrainingExample(g *game.RPSGame, move game.RPSMove, depth int) TrainingExample {
+	// Empty squares are 0, player 1's rock, paper and scissors 1-3 and
+	// player 2's 4-6
+	boardState := make([]int, len(g.Board))
+	for i, card := range g.Board {
+		switch card.Owner {
+		case game.Player1:
+			boardState[i] = int(card.Type) + 1
+		case game.Player2:
+			boardState[i] = int(card.Type) + 4
+		}
+	}
+
+	currentPlayer := 1
+	if g.CurrentPlayer == game.Player2 {
+		currentPlayer = 2
+	}
+
+	return TrainingExample{
+		BoardState:    boardState,
+		Player1Hand:   handCounts(g.Player1Hand),
+		Player2Hand:   handCounts(g.Player2Hand),
+		CurrentPlayer: currentPlayer,
+		BestMove:      move.Position,
+		Evaluation:    0.0, // The minimax agent does not expose its evaluation
+		GamePhase:     GamePhase(g),
+		SearchDepth:   depth,
+	}
+}
+
+// SetDepthMoves records the best moves minimax found at consecutive depths
+// starting from minDepth, and marks the example stable if they are all the same
+func (e *TrainingExample) SetDepthMoves(minDepth int, moves []game.RPSMove) {
+	e.DepthMoves = make([]DepthMove, len(moves))
+	stable := true
+	for i, move := range moves {
+		e.DepthMoves[i] = DepthMove{Depth: minDepth + i, Move: move.Position}
+		stable = stable && move.Position == moves[0].Position
+	}
+	e.Stable = &stable
+}
+
+// PlayOut plays g to the end with chooseMove choosing every move for both
+// players, and returns the result for the player to move in g as GameOutcome
+// does. g is left unchanged.
+func PlayOut(g *game.RPSGame, chooseMove func(*game.RPSGame) (game.RPSMove, error)) (float64, error) {
+	player := g.CurrentPlayer
+	playout := g.Copy()
+	for !playout.IsGameOver() {
+		move, err := chooseMove(playout)
+		if err != nil {
+			return 0, err
+		}
+		if err := playout.MakeMove(move); err != nil {
+			return 0, err
+		}
+	}
+	return GameOutcome(playout, player), nil
+}
+
+// handCounts counts the rock, paper and scissors cards in a hand
+func handCounts(hand []game.RPSCard) []int {
+	counts := make([]int, 3)
+	for _, card := range hand {
+		counts[card.Type]++
+	}
+	return counts
+}
+
+// GamePhase classifies a position as "opening", "midgame" or "endgame" by the
+// number of cards on the board
+func GamePhase(g *game.RPSGame) string {
+	cardsOnBoard := 0
+	for _, card := range g.Board {
+		if card.Owner != game.NoPlayer {
+			cardsOnBoard++
+		}
+	}
+
+	for _, phase := range Phases {
+		if _, max := PhaseCardRange(phase); cardsOnBoard <= max {
+			return phase
+		}
+	}
+	return "endgame"
+}
+
+// Game rebuilds the position an example was recorded from. The board, hands,
+// player to move and round are restored; the undealt deck is not recorded, so
+// the game is built with the generator's standard 21-card, 10-round settings.
+func (e TrainingExample) Game() *game.RPSGame {
+	g := game.NewRPSGame(21, 5, 10)
+
+	cardsPlayed := 0
+	for pos, value := range e.BoardState {
+		if value == 0 {
+			continue
+		}
+		owner := game.Player1
+		if value > 3 {
+			owner = game.Player2
+			value -= 3
+		}
+		g.Board[pos] = game.RPSCard{Type: game.RPSCardType(value - 1), Owner: owner}
+		cardsPlayed++
+	}
+
+	g.SetPlayer1Hand(handCardTypes(e.Player1Hand))
+	g.SetPlayer2Hand(handCardTypes(e.Player2Hand))
+	g.SetCurrentPlayer(e.CurrentPlayer - 1)
+	g.SetRound(cardsPlayed/2 + 1)
+	return g
+}
+
+// handCardTypes expands rock, paper and scissors counts into a list of card types
+func handCardTypes(counts []int) []int {
+	var cardTypes []int
+	for cardType, count := range counts {
+		for i := 0; i < count; i++ {
+			cardTypes = append(cardTypes, cardType)
+		}
+	}
+	return cardTypes
+}
+
+// Features returns the input new policy and value networks see for the
+// example's position, encoded with neural.CurrentFeatureVersion
+func (e TrainingExample) Features() []float64 {
+	return exampleEncoder.Encode(e.Game())
+}
+
+// exampleEncoder is the encoder of new networks for the standard board
+var exampleEncoder, _ = neural.NewEncoder(neural.CurrentFeatureVersion, game.StandardBoardDim, false)
+
+// PolicyTarget returns a one-hot policy over the 9 board positions with the
+// best move set
+func (e TrainingExample) PolicyTarget() []float64 {
+	target := make([]float64, 9)
+	target[e.BestMove] = 1.0
+	return target
+}
+
+// ExampleFeatures converts examples into network inputs and policy targets
+func ExampleFeatures(examples []TrainingExample) (inputs, targets [][]float64) {
+	inputs = make([][]float64, len(examples))
+	targets = make([][]float64, len(examples))
+	for i, example := range examples {
+		inputs[i] = example.Features()
+		targets[i] = example.PolicyTarget()
+	}
+	return inputs, targets
+}
+
+// SaveFeatureSet writes inputs and targets to <prefix>_inputs.json and
+// <prefix>_targets.json in dir, the files train_supervised loads
+func SaveFeatureSet(dir, prefix string, inputs, targets [][]float64) error {
+	if len(inputs) != len(targets) {
+		return fmt.Errorf("%d inputs but %d targets", len(inputs), len(targets))
+	}
+	if err := writeJSON(filepath.Join(dir, prefix+"_inputs.json"), inputs); err != nil {
+		return err
+	}
+	return writeJSON(filepath.Join(dir, prefix+"_targets.json"), targets)
+}
+
+// writeJSON encodes value as JSON into a new file at path
+func writeJSON(path string, value interface{}) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(file).Encode(value); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,83 @@
+package training
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
+	neural "github.com/zachbeta/neural_rps/alphago_demo/pkg/rps_net_impl"
+)
+
+func TestTrainingExampleFeaturesMatchGame(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for trial := 0; trial < 50; trial++ {
+		g := game.NewRPSGameSeeded(21, 5, 10, rng)
+		for moves := rng.Intn(8); moves > 0 && !g.IsGameOver(); moves-- {
+			valid := g.GetValidMoves()
+			g.MakeMove(valid[rng.Intn(len(valid))])
+		}
+		if g.IsGameOver() {
+			continue
+		}
+		move := g.GetValidMoves()[0]
+
+		example := NewTrainingExample(g, move, 3)
+		if got, want := example.Features(), neural.CurrentFeatureVersion.Encode(g, false); !reflect.DeepEqual(got, want) {
+			t.Fatalf("Features differ from the current encoding for\n%s", g)
+		}
+
+		rebuilt := example.Game()
+		if rebuilt.Round != g.Round || rebuilt.CurrentPlayer != g.CurrentPlayer {
+			t.Errorf("Rebuilt round %d, player %d; want round %d, player %d",
+				rebuilt.Round, rebuilt.CurrentPlayer, g.Round, g.CurrentPlayer)
+		}
+		if !reflect.DeepEqual(handCounts(rebuilt.Player1Hand), example.Player1Hand) ||
+			!reflect.DeepEqual(handCounts(rebuilt.Player2Hand), example.Player2Hand) {
+			t.Errorf("Rebuilt hands differ from the example's")
+		}
+
+		target := example.PolicyTarget()
+		if len(target) != 9 || target[move.Position] != 1 {
+			t.Errorf("Policy target %v does not pick position %d", target, move.Position)
+		}
+	}
+}
+
+func TestPlayOut(t *testing.T) {
+	firstMove := func(g *game.RPSGame) (game.RPSMove, error) { return g.GetValidMoves()[0], nil }
+
+	g := game.NewRPSGameSeeded(21, 5, 10, rand.New(rand.NewSource(7)))
+	g.MakeMove(g.GetValidMoves()[0])
+	before := g.String()
+
+	outcome, err := PlayOut(g, firstMove)
+	if err != nil {
+		t.Fatalf("PlayOut failed: %v", err)
+	}
+	if g.String() != before {
+		t.Error("PlayOut changed the starting position")
+	}
+
+	// Playing the same moves by hand gives the same result for player 2
+	final := g.Copy()
+	for !final.IsGameOver() {
+		final.MakeMove(final.GetValidMoves()[0])
+	}
+	if want := GameOutcome(final, game.Player2); outcome != want {
+		t.Errorf("PlayOut = %v, want %v for the player to move", outcome, want)
+	}
+}
+
+func TestSetDepthMoves(t *testing.T) {
+	var example TrainingExample
+	example.SetDepthMoves(2, []game.RPSMove{{Position: 4}, {Position: 4}, {Position: 4}})
+	if example.Stable == nil || !*example.Stable || len(example.DepthMoves) != 3 || example.DepthMoves[2].Depth != 4 {
+		t.Errorf("Agreeing depths labelled %+v, stable %v", example.DepthMoves, example.Stable)
+	}
+
+	example.SetDepthMoves(2, []game.RPSMove{{Position: 4}, {Position: 4}, {Position: 2}})
+	if *example.Stable {
+		t.Error("A move that changes at depth 4 was labelled stable")
+	}
+}
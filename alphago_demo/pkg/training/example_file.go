@@ -0,0 +1,128 @@
+package training
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// TrainingExample is a position labelled by minimax search, as written by
+// generate_training_data and read by preprocess_data
+type TrainingExample struct {
+	BoardState    []int   `json:"board_state"`    // Flattened board (9 positions)
+	Player1Hand   []int   `json:"player1_hand"`   // Card types in P1's hand
+	Player2Hand   []int   `json:"player2_hand"`   // Card types in P2's hand
+	CurrentPlayer int     `json:"current_player"` // 1 or 2
+	BestMove      int     `json:"best_move"`      // 0-8 position index
+	Evaluation    float64 `json:"evaluation"`     // Minimax evaluation
+	GamePhase     string  `json:"game_phase"`     // "opening", "midgame", "endgame"
+	SearchDepth   int     `json:"search_depth"`   // Depth used for this position
+
+	// ValueTarget is the result of playing the position out, for the player to
+	// move, on the value network's scale: 1 for a win, 0.5 for a draw and 0
+	// for a loss (see OutcomeValueTarget). Examples written before it was
+	// recorded read as 0.
+	ValueTarget float64 `json:"value_target"`
+
+	// DepthMoves holds the best move found at a few search depths around
+	// SearchDepth, and Stable whether they all agree. Unstable positions are
+	// the harder ones to learn. Both are left out unless the generator was
+	// asked for them.
+	DepthMoves []DepthMove `json:"depth_moves,omitempty"`
+	Stable     *bool       `json:"stable,omitempty"`
+}
+
+// DepthMove is the best move minimax found when searching to Depth
+type DepthMove struct {
+	Depth int `json:"depth"`
+	Move  int `json:"move"` // 0-8 position index
+}
+
+// AppendExamples adds examples to the end of a dataset file, creating it if
+// needed. The file holds one JSON object per line, so runs can keep adding to
+// the same dataset and readers never need the whole file in memory.
+func AppendExamples(path string, examples []TrainingExample) error {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(file)
+	encoder := json.NewEncoder(w)
+	for _, example := range examples {
+		if err := encoder.Encode(example); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// ReadExamples streams the examples in a dataset file in order. It also reads
+// the single JSON array that generate_training_data wrote before datasets
+// could be appended to. The example channel is closed at the end of the file
+// or on the first error, after which the error channel yields the error, or
+// nil. Callers must read the example channel to the end.
+func ReadExamples(path string) (<-chan TrainingExample, <-chan error) {
+	examples := make(chan TrainingExample, 64)
+	errc := make(chan error, 1)
+
+	go func() {
+		defer close(errc)
+		defer close(examples)
+		errc <- readExamples(path, examples)
+	}()
+	return examples, errc
+}
+
+// readExamples decodes the dataset at path, sending each example to out
+func readExamples(path string, out chan<- TrainingExample) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// A legacy file is one array rather than a value per line
+	r := bufio.NewReader(file)
+	legacy := false
+	for {
+		b, err := r.ReadByte()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if b != ' ' && b != '\t' && b != '\n' && b != '\r' {
+			r.UnreadByte()
+			legacy = b == '['
+			break
+		}
+	}
+
+	decoder := json.NewDecoder(r)
+	if legacy {
+		decoder.Token()
+	}
+
+	for record := 1; decoder.More(); record++ {
+		var example TrainingExample
+		if err := decoder.Decode(&example); err != nil {
+			return fmt.Errorf("%s: example %d: %v", path, record, err)
+		}
+		out <- example
+	}
+
+	if legacy {
+		if _, err := decoder.Token(); err != nil {
+			return fmt.Errorf("%s: unterminated example array: %v", path, err)
+		}
+	}
+	return nil
+}
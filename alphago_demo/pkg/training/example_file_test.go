@@ -0,0 +1,100 @@
+package training
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// makeExamples returns n distinct examples numbered from first
+func makeExamples(first, n int) []TrainingExample {
+	examples := make([]TrainingExample, n)
+	for i := range examples {
+		id := first + i
+		examples[i] = TrainingExample{
+			BoardState:    []int{id % 7, 0, 0, 0, id % 5, 0, 0, 0, 0},
+			Player1Hand:   []int{id % 3, 1, 2},
+			Player2Hand:   []int{2, 1, id % 4},
+			CurrentPlayer: id%2 + 1,
+			BestMove:      id % 9,
+			Evaluation:    float64(id) / 7,
+			GamePhase:     "midgame",
+			SearchDepth:   id,
+		}
+	}
+	return examples
+}
+
+// readAll collects every example in a dataset file
+func readAll(t *testing.T, path string) []TrainingExample {
+	t.Helper()
+	var got []TrainingExample
+	examples, errc := ReadExamples(path)
+	for example := range examples {
+		got = append(got, example)
+	}
+	if err := <-errc; err != nil {
+		t.Fatalf("ReadExamples failed: %v", err)
+	}
+	return got
+}
+
+func TestAppendExamplesRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.jsonl")
+	first := makeExamples(0, 3000)
+	second := makeExamples(3000, 500)
+
+	if err := AppendExamples(path, first); err != nil {
+		t.Fatalf("AppendExamples failed: %v", err)
+	}
+	if got := readAll(t, path); !reflect.DeepEqual(got, first) {
+		t.Fatalf("Read back %d examples that differ from the %d written", len(got), len(first))
+	}
+
+	// A second run adds to the dataset without disturbing earlier records
+	if err := AppendExamples(path, second); err != nil {
+		t.Fatalf("AppendExamples failed: %v", err)
+	}
+	got := readAll(t, path)
+	if len(got) != len(first)+len(second) {
+		t.Fatalf("Read %d examples after appending, want %d", len(got), len(first)+len(second))
+	}
+	if !reflect.DeepEqual(got[:len(first)], first) || !reflect.DeepEqual(got[len(first):], second) {
+		t.Error("Appending changed or reordered earlier records")
+	}
+}
+
+func TestReadExamplesLegacyArray(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.json")
+	want := makeExamples(0, 20)
+	data, err := json.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := readAll(t, path); !reflect.DeepEqual(got, want) {
+		t.Errorf("Read %d examples from a JSON array that differ from the %d written", len(got), len(want))
+	}
+}
+
+func TestReadExamplesReportsBadRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.jsonl")
+	AppendExamples(path, makeExamples(0, 2))
+	file, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	file.WriteString("{\"best_move\": \"oops\"}\n")
+	file.Close()
+
+	examples, errc := ReadExamples(path)
+	count := 0
+	for range examples {
+		count++
+	}
+	if err := <-errc; err == nil || count != 2 {
+		t.Errorf("Got %d examples and error %v, want 2 examples then an error", count, err)
+	}
+}
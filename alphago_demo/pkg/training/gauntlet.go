@@ -0,0 +1,70 @@
+package training
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/agents"
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/tournament"
+)
+
+// gauntletSeed fixes the deals of a gauntlet so that every candidate is judged
+// on the same games
+const gauntletSeed = 1
+
+// GauntletResult is the outcome of a gating match
+type GauntletResult struct {
+	Series   tournament.SeriesResult
+	WinRate  float64 // Candidate's share of the points, counting draws as half
+	Accepted bool    // WinRate is above the threshold
+}
+
+// Gauntlet plays candidate against best and reports whether the candidate
+// scored more than winThreshold of the points (0.55 for 55%). Only a candidate
+// that passes should replace best as the self-play opponent.
+func Gauntlet(candidate, best agents.Agent, games int, winThreshold float64) bool {
+	return RunGauntlet(candidate, best, games, winThreshold).Accepted
+}
+
+// RunGauntlet plays the gating match for Gauntlet and returns the full
+// result. Every deal is played twice with the agents swapping seats, so luck
+// of the deal cancels out, and the deals are the same for every call.
+func RunGauntlet(candidate, best agents.Agent, games int, winThreshold float64) GauntletResult {
+	// Results are counted by name, so the agents must not share one
+	if candidate.Name() == best.Name() {
+		candidate = renamedAgent{candidate, candidate.Name() + " (candidate)"}
+	}
+
+	opts := tournament.DefaultSeriesOptions()
+	opts.MirrorDeals = true
+	opts.Rng = rand.New(rand.NewSource(gauntletSeed))
+
+	series := tournament.PlaySeries(candidate, best, games, opts)
+	winRate := series.WinRateA()
+	return GauntletResult{
+		Series:   series,
+		WinRate:  winRate,
+		Accepted: winRate > winThreshold,
+	}
+}
+
+// String summarises the match
+func (r GauntletResult) String() string {
+	verdict := "rejected"
+	if r.Accepted {
+		verdict = "accepted"
+	}
+	return fmt.Sprintf("%s vs %s: %d-%d-%d (%.1f%%), %s",
+		r.Series.AgentA, r.Series.AgentB, r.Series.WinsA, r.Series.WinsB, r.Series.Draws, r.WinRate*100, verdict)
+}
+
+// renamedAgent plays as another agent under a different name
+type renamedAgent struct {
+	agents.Agent
+	name string
+}
+
+// Name returns the new name
+func (a renamedAgent) Name() string {
+	return a.name
+}
@@ -0,0 +1,43 @@
+package training
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/agents"
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
+)
+
+// forfeitingAgent never returns a move, so it loses every game
+type forfeitingAgent struct{ name string }
+
+func (a forfeitingAgent) Name() string { return a.name }
+
+func (a forfeitingAgent) GetMove(state *game.RPSGame) (game.RPSMove, error) {
+	return game.RPSMove{}, errors.New("no move")
+}
+
+func TestGauntlet(t *testing.T) {
+	strong := agents.NewRandomAgent("Random")
+	weak := forfeitingAgent{"Forfeit"}
+
+	if !Gauntlet(strong, weak, 10, 0.55) {
+		t.Error("A candidate winning every game was rejected")
+	}
+	if Gauntlet(weak, strong, 10, 0.55) {
+		t.Error("A candidate losing every game was accepted")
+	}
+
+	// Mirrored deals round the games up to an even number
+	result := RunGauntlet(strong, weak, 5, 0.55)
+	if len(result.Series.Games) != 6 || result.WinRate != 1 || !result.Accepted {
+		t.Errorf("RunGauntlet = %v over %d games, want 6 games won", result, len(result.Series.Games))
+	}
+}
+
+func TestGauntletSameName(t *testing.T) {
+	result := RunGauntlet(agents.NewRandomAgent("Agent"), forfeitingAgent{"Agent"}, 4, 0.55)
+	if result.Series.AgentA == result.Series.AgentB || result.Series.WinsA != 4 {
+		t.Errorf("RunGauntlet = %v, want the candidate renamed and winning all 4 games", result)
+	}
+}
@@ -0,0 +1,66 @@
+package training
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+// LossHistory records the losses of each training epoch so runs can be
+// plotted and compared. Every trainer writes the same CSV columns; a metric a
+// trainer does not measure is recorded as NaN and written as an empty cell.
+type LossHistory struct {
+	PolicyLoss  []float64
+	ValueLoss   []float64
+	ValAccuracy []float64 // Fraction of validation positions predicted correctly
+}
+
+// AppendEpoch records the metrics of the next epoch. Pass math.NaN() for any
+// the trainer does not measure.
+func (h *LossHistory) AppendEpoch(policyLoss, valueLoss, valAcc float64) {
+	h.PolicyLoss = append(h.PolicyLoss, policyLoss)
+	h.ValueLoss = append(h.ValueLoss, valueLoss)
+	h.ValAccuracy = append(h.ValAccuracy, valAcc)
+}
+
+// Epochs returns the number of epochs recorded
+func (h *LossHistory) Epochs() int {
+	return len(h.PolicyLoss)
+}
+
+// WriteCSV writes a header and one line per epoch, numbered from 1
+func (h *LossHistory) WriteCSV(w io.Writer) error {
+	if _, err := fmt.Fprintln(w, "epoch,policy_loss,value_loss,val_accuracy"); err != nil {
+		return err
+	}
+	for i := range h.PolicyLoss {
+		_, err := fmt.Fprintf(w, "%d,%s,%s,%s\n", i+1,
+			csvFloat(h.PolicyLoss[i]), csvFloat(h.ValueLoss[i]), csvFloat(h.ValAccuracy[i]))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SaveCSV writes the history to a new file at path
+func (h *LossHistory) SaveCSV(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := h.WriteCSV(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// csvFloat formats a metric for the CSV, leaving missing values empty
+func csvFloat(v float64) string {
+	if math.IsNaN(v) {
+		return ""
+	}
+	return fmt.Sprintf("%.6f", v)
+}
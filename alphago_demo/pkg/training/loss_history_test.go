@@ -0,0 +1,34 @@
+package training
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLossHistorySaveCSV(t *testing.T) {
+	var history LossHistory
+	history.AppendEpoch(1.5, 0.25, math.NaN())
+	history.AppendEpoch(0.75, math.NaN(), 0.5)
+
+	if history.Epochs() != 2 {
+		t.Fatalf("Epochs() = %d, want 2", history.Epochs())
+	}
+
+	path := filepath.Join(t.TempDir(), "losses.csv")
+	if err := history.SaveCSV(path); err != nil {
+		t.Fatalf("SaveCSV failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+
+	want := "epoch,policy_loss,value_loss,val_accuracy\n" +
+		"1,1.500000,0.250000,\n" +
+		"2,0.750000,,0.500000\n"
+	if string(data) != want {
+		t.Errorf("CSV =\n%s\nwant\n%s", data, want)
+	}
+}
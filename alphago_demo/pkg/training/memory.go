@@ -0,0 +1,36 @@
+package training
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+)
+
+// MemoryStats is a summary of the Go runtime's memory use
+type MemoryStats struct {
+	HeapInUse  uint64 // Bytes in in-use heap spans
+	TotalAlloc uint64 // Bytes allocated over the life of the process
+	NumGC      uint32 // Completed garbage collections
+}
+
+// ReadMemoryStats returns the current memory use. It briefly stops the world,
+// so call it every few seconds at most rather than per game or per batch.
+func ReadMemoryStats() MemoryStats {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return MemoryStats{HeapInUse: m.HeapInuse, TotalAlloc: m.TotalAlloc, NumGC: m.NumGC}
+}
+
+func (m MemoryStats) String() string {
+	return fmt.Sprintf("heap in use %.1f MB, total allocated %.1f MB, %d GCs",
+		float64(m.HeapInUse)/(1<<20), float64(m.TotalAlloc)/(1<<20), m.NumGC)
+}
+
+// logMemoryStats logs the current memory use at debug level, reading it only
+// when debug logging is enabled
+func logMemoryStats() {
+	if slog.Default().Enabled(context.Background(), slog.LevelDebug) {
+		slog.Debug("Memory", "stats", ReadMemoryStats())
+	}
+}
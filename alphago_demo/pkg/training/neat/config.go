@@ -11,6 +11,7 @@ package neat
 //  - EvalGames: number of self-play games per genome to estimate fitness
 //  - WeightStd: standard deviation for Gaussian weight mutations
 //  - HiddenSize: number of hidden units in the neural network
+//  - HallOfFameSize: number of past generation champions kept as evaluation opponents (0 disables)
 
 type Config struct {
     PopSize          int     `json:"pop_size"`
@@ -21,4 +22,5 @@ type Config struct {
     EvalGames        int     `json:"eval_games"`
     WeightStd        float64 `json:"weight_std"`
     HiddenSize       int     `json:"hidden_size"`
+    HallOfFameSize   int     `json:"hall_of_fame_size"`
 }
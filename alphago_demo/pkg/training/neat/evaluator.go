@@ -1,7 +1,7 @@
 package neat
 
 import (
-	"fmt"
+	"log/slog"
 	"math/rand"
 	"runtime"
 	"sync"
@@ -138,8 +138,7 @@ func parallelEvaluate(pop *Population, hof []*Genome) []*GenomeResult {
 	startTime := time.Now()
 	matches := prepareMatches(pop, hof)
 	matchCount := len(matches)
-	fmt.Printf("Evaluating %d genomes with %d total matches (%d workers)...\n",
-		len(pop.Genomes), matchCount, runtime.NumCPU()-1)
+	slog.Info("Evaluating genomes", "genomes", len(pop.Genomes), "matches", matchCount, "workers", runtime.NumCPU()-1)
 
 	results := make([]*GenomeResult, len(pop.Genomes))
 	for i := range results {
@@ -168,27 +167,9 @@ func parallelEvaluate(pop *Population, hof []*Genome) []*GenomeResult {
 				return
 			case <-ticker.C:
 				completed := atomic.LoadInt32(&completedMatches)
-				percent := float64(completed) / float64(matchCount) * 100
 				elapsed := time.Since(startTime)
-				matchesPerSec := float64(completed) / elapsed.Seconds()
-
-				// Simple progress bar
-				width := 30
-				progress := int(float64(width) * float64(completed) / float64(matchCount))
-				bar := "["
-				for i := 0; i < width; i++ {
-					if i < progress {
-						bar += "="
-					} else if i == progress {
-						bar += ">"
-					} else {
-						bar += " "
-					}
-				}
-				bar += "]"
-
-				fmt.Printf("\r%s %.1f%% (%d/%d) | %.1f matches/sec | %s ",
-					bar, percent, completed, matchCount, matchesPerSec, elapsed.Round(time.Second))
+				slog.Debug("Evaluation progress", "matches", completed, "total", matchCount,
+					"matches_per_sec", float64(completed)/elapsed.Seconds(), "elapsed", elapsed.Round(time.Second))
 			}
 		}
 	}()
@@ -219,12 +200,10 @@ func parallelEvaluate(pop *Population, hof []*Genome) []*GenomeResult {
 
 	// Stop progress reporting
 	done <- true
-	fmt.Println() // Add a newline after the progress bar
 
-	// Print summary
 	duration := time.Since(startTime)
-	fmt.Printf("Evaluation complete - took %s (%.1f matches/sec)\n",
-		duration.Round(time.Second), float64(matchCount)/duration.Seconds())
+	slog.Info("Evaluation complete", "elapsed", duration.Round(time.Second),
+		"matches_per_sec", float64(matchCount)/duration.Seconds())
 
 	return results
 }
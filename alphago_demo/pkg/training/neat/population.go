@@ -2,6 +2,7 @@ package neat
 
 import (
 	"fmt"
+	"log/slog"
 	"math"
 	"math/rand"
 	"sort"
@@ -14,9 +15,13 @@ import (
 type Population struct {
 	Genomes      []*Genome     // all genomes in current generation
 	Species      map[int][]int // species ID -> indices of genomes
+	HallOfFame   []*Genome     // archived generation champions, oldest first
 	innovCounter int           // global innovation counter for new genes (if using dynamic topology)
 }
 
+// hofSampleSize is the number of hall-of-famers each generation is evaluated against.
+const hofSampleSize = 3
+
 // NewPopulation creates an initial population of random genomes.
 func NewPopulation(cfg Config) *Population {
 	pop := &Population{
@@ -33,11 +38,9 @@ func NewPopulation(cfg Config) *Population {
 // Evolve runs the NEAT algorithm for the configured number of generations
 // and returns the best genome found.
 func (p *Population) Evolve(cfg Config, threads int) *Genome {
-	fmt.Printf("\n=== Starting NEAT evolution with %d genomes, %d generations ===\n",
-		len(p.Genomes), cfg.Generations)
+	slog.Info("Starting NEAT evolution", "genomes", len(p.Genomes), "generations", cfg.Generations)
 
-	// Display network architecture information
-	fmt.Println("\n=== Network Architecture ===")
+	// Log the network architecture
 	// Create example networks to analyze structure
 	exampleGenome := p.Genomes[0]
 	policyNet, valueNet := exampleGenome.ToNetworks()
@@ -46,28 +49,19 @@ func (p *Population) Evolve(cfg Config, threads int) *Genome {
 	policyStats := neural.CalculatePolicyNetworkStats(policyNet)
 	valueStats := neural.CalculateValueNetworkStats(valueNet)
 
-	// Display structure
-	fmt.Printf("Policy Network: %d inputs, %d hidden neurons, %d outputs\n",
-		policyStats.InputSize, policyStats.HiddenSize, policyStats.OutputSize)
-	fmt.Printf("Value Network: %d inputs, %d hidden neurons, %d outputs\n",
-		valueStats.InputSize, valueStats.HiddenSize, valueStats.OutputSize)
-
-	// Calculate parameters
-	totalParams := policyStats.TotalParameters + valueStats.TotalParameters
-
-	fmt.Printf("Total parameters: %d (%d policy, %d value)\n",
-		totalParams, policyStats.TotalParameters, valueStats.TotalParameters)
+	slog.Info("Network architecture",
+		"inputs", policyStats.InputSize, "hidden", policyStats.HiddenSize,
+		"policy_outputs", policyStats.OutputSize, "value_outputs", valueStats.OutputSize,
+		"policy_parameters", policyStats.TotalParameters, "value_parameters", valueStats.TotalParameters)
 
 	// Display weight initialization statistics
 	policyWeightStats := analyzeWeights(exampleGenome.PolicyWeights)
 	valueWeightStats := analyzeWeights(exampleGenome.ValueWeights)
 
-	fmt.Printf("Policy weights: min=%.4f, max=%.4f, mean=%.4f, std=%.4f\n",
-		policyWeightStats.min, policyWeightStats.max,
-		policyWeightStats.mean, policyWeightStats.std)
-	fmt.Printf("Value weights: min=%.4f, max=%.4f, mean=%.4f, std=%.4f\n",
-		valueWeightStats.min, valueWeightStats.max,
-		valueWeightStats.mean, valueWeightStats.std)
+	slog.Debug("Initial policy weights", "min", policyWeightStats.min, "max", policyWeightStats.max,
+		"mean", policyWeightStats.mean, "std", policyWeightStats.std)
+	slog.Debug("Initial value weights", "min", valueWeightStats.min, "max", valueWeightStats.max,
+		"mean", valueWeightStats.mean, "std", valueWeightStats.std)
 
 	startTime := time.Now()
 	var bestGenome *Genome
@@ -75,10 +69,9 @@ func (p *Population) Evolve(cfg Config, threads int) *Genome {
 
 	for gen := 1; gen <= cfg.Generations; gen++ {
 		genStartTime := time.Now()
-		fmt.Printf("\n--- Generation %d/%d ---\n", gen, cfg.Generations)
 
 		// Parallel evaluation: assign fitness to all genomes
-		var hof []*Genome // Hall-of-Fame (empty for now)
+		hof := p.sampleHallOfFame(hofSampleSize)
 		results := parallelEvaluate(p, hof)
 
 		// Update fitness values
@@ -127,16 +120,12 @@ func (p *Population) Evolve(cfg Config, threads int) *Genome {
 		q1 := fitnessValues[len(fitnessValues)/4]
 		q3 := fitnessValues[3*len(fitnessValues)/4]
 
-		// Print generation summary
+		// Log the generation summary
 		genTime := time.Since(genStartTime)
-		fmt.Printf("NEAT Generation %d/%d — best=%.4f, avg=%.4f, median=%.4f\n",
-			gen, cfg.Generations, best, avg, median)
-		fmt.Printf("Fitness distribution: min=%.4f, q1=%.4f, median=%.4f, q3=%.4f, max=%.4f\n",
-			fitnessValues[0], q1, median, q3, fitnessValues[len(fitnessValues)-1])
-		fmt.Printf("Species: %d | Generation time: %s\n", len(p.Species), genTime)
-
-		// Print species information
-		fmt.Printf("Species distribution:\n")
+		slog.Info("NEAT generation", "generation", gen, "generations", cfg.Generations,
+			"best", best, "avg", avg, "min", fitnessValues[0], "q1", q1, "median", median, "q3", q3,
+			"max", fitnessValues[len(fitnessValues)-1], "species", len(p.Species), "elapsed", genTime)
+
 		speciesFitness := make(map[int]float64)
 		for speciesID, members := range p.Species {
 			speciesSum := 0.0
@@ -145,15 +134,14 @@ func (p *Population) Evolve(cfg Config, threads int) *Genome {
 			}
 			speciesAvg := speciesSum / float64(len(members))
 			speciesFitness[speciesID] = speciesAvg
-			fmt.Printf("  Species %d: %d members, avg fitness=%.4f\n",
-				speciesID, len(members), speciesAvg)
+			slog.Debug("Species", "generation", gen, "id", speciesID, "members", len(members), "avg_fitness", speciesAvg)
 		}
 
 		// Track best genome over all generations
 		if gen == 1 || best > bestFitness {
 			bestFitness = best
 			bestGenome = p.Genomes[bestIdx].Copy()
-			fmt.Printf("New best genome found: fitness=%.4f\n", bestFitness)
+			slog.Info("New best genome", "generation", gen, "fitness", bestFitness)
 		}
 
 		// Reproduction
@@ -169,15 +157,17 @@ func (p *Population) Evolve(cfg Config, threads int) *Genome {
 		// Place champion at index 0
 		champion := p.Genomes[bestIdx]
 		newGen[0] = champion
-		// Checkpoint champion networks
+		p.archiveChampion(champion, cfg.HallOfFameSize)
+		// Checkpoint champion networks. A failed checkpoint is logged rather
+		// than ending the run, which still returns the best genome.
 		polNet, valNet := champion.ToNetworks()
 		polPath := fmt.Sprintf("output/neat_gen%02d_policy.model", gen)
 		valPath := fmt.Sprintf("output/neat_gen%02d_value.model", gen)
 		if err := polNet.SaveToFile(polPath); err != nil {
-			panic(fmt.Sprintf("neat checkpoint policy save error: %v", err))
+			slog.Error("Failed to save the NEAT checkpoint", "path", polPath, "error", err)
 		}
 		if err := valNet.SaveToFile(valPath); err != nil {
-			panic(fmt.Sprintf("neat checkpoint value save error: %v", err))
+			slog.Error("Failed to save the NEAT checkpoint", "path", valPath, "error", err)
 		}
 		// Collect species reps
 		reps := make([]int, 0, len(p.Species))
@@ -197,14 +187,36 @@ func (p *Population) Evolve(cfg Config, threads int) *Genome {
 		p.Genomes = newGen
 	}
 
-	totalTime := time.Since(startTime)
-	fmt.Printf("\n=== Evolution complete ===\n")
-	fmt.Printf("Total time: %s, generations: %d\n", totalTime, cfg.Generations)
-	fmt.Printf("Best fitness achieved: %.4f\n", bestFitness)
+	slog.Info("Evolution complete", "elapsed", time.Since(startTime), "generations", cfg.Generations,
+		"best_fitness", bestFitness, "hall_of_fame", len(p.HallOfFame))
 
 	return bestGenome
 }
 
+// archiveChampion adds a copy of the generation champion to the hall of fame,
+// dropping the oldest entries once the archive exceeds size.
+func (p *Population) archiveChampion(champion *Genome, size int) {
+	if size <= 0 {
+		return
+	}
+	p.HallOfFame = append(p.HallOfFame, champion.Copy())
+	if len(p.HallOfFame) > size {
+		p.HallOfFame = p.HallOfFame[len(p.HallOfFame)-size:]
+	}
+}
+
+// sampleHallOfFame returns up to n distinct hall-of-famers chosen at random.
+func (p *Population) sampleHallOfFame(n int) []*Genome {
+	if n > len(p.HallOfFame) {
+		n = len(p.HallOfFame)
+	}
+	sample := make([]*Genome, 0, n)
+	for _, idx := range rand.Perm(len(p.HallOfFame))[:n] {
+		sample = append(sample, p.HallOfFame[idx])
+	}
+	return sample
+}
+
 // weightStats holds basic statistics about a weight array
 type weightStats struct {
 	min, max, mean, std float64
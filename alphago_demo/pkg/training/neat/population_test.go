@@ -0,0 +1,45 @@
+package neat
+
+import "testing"
+
+func TestHallOfFameArchiveTrimsOldest(t *testing.T) {
+	cfg := Config{PopSize: 1, WeightStd: 0.1, HiddenSize: 2}
+	pop := NewPopulation(cfg)
+
+	champions := make([]*Genome, 5)
+	for i := range champions {
+		champions[i] = NewGenome(cfg)
+		champions[i].Fitness = float64(i)
+		pop.archiveChampion(champions[i], 3)
+	}
+
+	if len(pop.HallOfFame) != 3 {
+		t.Fatalf("Expected 3 hall-of-famers, got %d", len(pop.HallOfFame))
+	}
+	for i, g := range pop.HallOfFame {
+		if g.Fitness != float64(i+2) {
+			t.Errorf("Expected hall-of-famer %d to have fitness %d, got %.0f", i, i+2, g.Fitness)
+		}
+		if g == champions[i+2] {
+			t.Errorf("Expected hall-of-famer %d to be a copy, got the original genome", i)
+		}
+	}
+
+	sample := pop.sampleHallOfFame(5)
+	if len(sample) != 3 {
+		t.Errorf("Expected sample capped at archive size 3, got %d", len(sample))
+	}
+}
+
+func TestHallOfFameDisabled(t *testing.T) {
+	cfg := Config{PopSize: 1, WeightStd: 0.1, HiddenSize: 2}
+	pop := NewPopulation(cfg)
+	pop.archiveChampion(NewGenome(cfg), 0)
+
+	if len(pop.HallOfFame) != 0 {
+		t.Errorf("Expected empty hall of fame when size is 0, got %d", len(pop.HallOfFame))
+	}
+	if sample := pop.sampleHallOfFame(hofSampleSize); len(sample) != 0 {
+		t.Errorf("Expected empty sample, got %d", len(sample))
+	}
+}
@@ -0,0 +1,136 @@
+package training
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Phases lists the game phases GamePhase returns, from first to last
+var Phases = []string{"opening", "midgame", "endgame"}
+
+// PhaseCardRange returns the fewest and most cards on a standard 3x3 board in
+// a position of the given phase. Every move adds a card to the board, so this
+// is also the number of moves played.
+func PhaseCardRange(phase string) (min, max int) {
+	switch phase {
+	case "opening":
+		return 0, 2
+	case "midgame":
+		return 3, 6
+	}
+	return 7, 8
+}
+
+// ParsePhaseMix parses a target phase distribution written as
+// "opening=1,midgame=2,endgame=2". Weights are relative and phases left out
+// get none.
+func ParsePhaseMix(s string) (map[string]float64, error) {
+	weights := make(map[string]float64)
+	total := 0.0
+	for _, part := range strings.Split(s, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return nil, fmt.Errorf("phase mix %q: expected phase=weight, got %q", s, part)
+		}
+		if !isPhase(name) {
+			return nil, fmt.Errorf("phase mix %q: unknown phase %q (want one of %s)", s, name, strings.Join(Phases, ", "))
+		}
+		weight, err := strconv.ParseFloat(value, 64)
+		if err != nil || weight < 0 {
+			return nil, fmt.Errorf("phase mix %q: weight for %s must be a non-negative number", s, name)
+		}
+		weights[name] = weight
+		total += weight
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("phase mix %q: no phase has a positive weight", s)
+	}
+	return weights, nil
+}
+
+// isPhase reports whether name is one of Phases
+func isPhase(name string) bool {
+	for _, phase := range Phases {
+		if phase == name {
+			return true
+		}
+	}
+	return false
+}
+
+// PhaseBalancer decides which positions to keep so that a dataset of a given
+// size ends up with a target mix of game phases
+type PhaseBalancer struct {
+	quota  map[string]int
+	counts map[string]int
+}
+
+// NewPhaseBalancer shares total positions between the phases in proportion to
+// weights, rounding so the quotas add up to total
+func NewPhaseBalancer(total int, weights map[string]float64) *PhaseBalancer {
+	sum := 0.0
+	for _, phase := range Phases {
+		sum += weights[phase]
+	}
+
+	// Largest remainder: round every share down, then give the positions
+	// left over to the phases that lost the most
+	quota := make(map[string]int)
+	remainders := make([]string, 0, len(Phases))
+	assigned := 0
+	for _, phase := range Phases {
+		share := float64(total) * weights[phase] / sum
+		quota[phase] = int(math.Floor(share))
+		assigned += quota[phase]
+		remainders = append(remainders, phase)
+	}
+	sort.SliceStable(remainders, func(i, j int) bool {
+		return fraction(float64(total)*weights[remainders[i]]/sum) > fraction(float64(total)*weights[remainders[j]]/sum)
+	})
+	for i := 0; assigned < total; i++ {
+		quota[remainders[i%len(remainders)]]++
+		assigned++
+	}
+
+	return &PhaseBalancer{quota: quota, counts: make(map[string]int)}
+}
+
+// fraction returns the part of x after the decimal point
+func fraction(x float64) float64 {
+	return x - math.Floor(x)
+}
+
+// Next returns the phase furthest short of its quota, the one a generator
+// should aim for next, or "" once every quota is met
+func (b *PhaseBalancer) Next() string {
+	next, mostMissing := "", 0.0
+	for _, phase := range Phases {
+		if b.quota[phase] == 0 {
+			continue
+		}
+		missing := float64(b.quota[phase]-b.counts[phase]) / float64(b.quota[phase])
+		if missing > mostMissing {
+			next, mostMissing = phase, missing
+		}
+	}
+	return next
+}
+
+// Wants reports whether phase is still short of its quota. Positions of a
+// phase that is not wanted should be dropped.
+func (b *PhaseBalancer) Wants(phase string) bool {
+	return b.counts[phase] < b.quota[phase]
+}
+
+// Add records that a position of the given phase was kept
+func (b *PhaseBalancer) Add(phase string) {
+	b.counts[phase]++
+}
+
+// Quota returns the number of positions wanted for phase
+func (b *PhaseBalancer) Quota(phase string) int {
+	return b.quota[phase]
+}
@@ -0,0 +1,49 @@
+package training
+
+import "testing"
+
+func TestParsePhaseMix(t *testing.T) {
+	weights, err := ParsePhaseMix("opening=1, endgame=2.5")
+	if err != nil {
+		t.Fatalf("ParsePhaseMix failed: %v", err)
+	}
+	if weights["opening"] != 1 || weights["midgame"] != 0 || weights["endgame"] != 2.5 {
+		t.Errorf("Unexpected weights %v", weights)
+	}
+
+	for _, bad := range []string{"opening", "late=1", "opening=-1", "opening=0,endgame=0"} {
+		if _, err := ParsePhaseMix(bad); err == nil {
+			t.Errorf("ParsePhaseMix(%q) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestPhaseBalancer(t *testing.T) {
+	balancer := NewPhaseBalancer(10, map[string]float64{"opening": 1, "midgame": 1, "endgame": 1})
+
+	// 10 positions split three ways round to 4, 3 and 3
+	total := 0
+	for _, phase := range Phases {
+		total += balancer.Quota(phase)
+	}
+	if total != 10 || balancer.Quota("opening") != 4 {
+		t.Errorf("Quotas %d, %d, %d, want 4, 3, 3", balancer.Quota("opening"), balancer.Quota("midgame"), balancer.Quota("endgame"))
+	}
+
+	// Taking whatever phase is furthest behind fills every quota exactly
+	for i := 0; i < 10; i++ {
+		phase := balancer.Next()
+		if !balancer.Wants(phase) {
+			t.Fatalf("Next returned %q, which is not wanted", phase)
+		}
+		balancer.Add(phase)
+	}
+	for _, phase := range Phases {
+		if balancer.Wants(phase) {
+			t.Errorf("%s still wanted after filling every quota", phase)
+		}
+	}
+	if next := balancer.Next(); next != "" {
+		t.Errorf("Next = %q after filling every quota, want \"\"", next)
+	}
+}
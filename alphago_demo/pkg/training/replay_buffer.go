@@ -0,0 +1,33 @@
+package training
+
+// ReplayBuffer holds the examples of the most recent self-play games, up to a
+// fixed number. Training on it rather than on the latest games alone lets each
+// network learn from several generations of play, which keeps it from
+// forgetting positions the newest network no longer reaches.
+type ReplayBuffer struct {
+	capacity int
+	examples []RPSTrainingExample
+}
+
+// NewReplayBuffer creates an empty buffer holding up to capacity examples
+func NewReplayBuffer(capacity int) *ReplayBuffer {
+	return &ReplayBuffer{capacity: capacity}
+}
+
+// Add appends examples, dropping the oldest ones once the buffer is full
+func (b *ReplayBuffer) Add(examples []RPSTrainingExample) {
+	b.examples = append(b.examples, examples...)
+	if len(b.examples) > b.capacity {
+		b.examples = append([]RPSTrainingExample(nil), b.examples[len(b.examples)-b.capacity:]...)
+	}
+}
+
+// Len returns the number of examples held
+func (b *ReplayBuffer) Len() int {
+	return len(b.examples)
+}
+
+// Examples returns a copy of the examples held, oldest first
+func (b *ReplayBuffer) Examples() []RPSTrainingExample {
+	return append([]RPSTrainingExample(nil), b.examples...)
+}
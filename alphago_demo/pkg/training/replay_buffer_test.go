@@ -0,0 +1,30 @@
+package training
+
+import "testing"
+
+func TestReplayBufferKeepsNewestExamples(t *testing.T) {
+	buffer := NewReplayBuffer(5)
+	for game := 0; game < 3; game++ {
+		examples := make([]RPSTrainingExample, 2)
+		for i := range examples {
+			examples[i].Outcome = float64(game*2 + i)
+		}
+		buffer.Add(examples)
+	}
+
+	if buffer.Len() != 5 {
+		t.Fatalf("Len() = %d, want 5", buffer.Len())
+	}
+	held := buffer.Examples()
+	for i, example := range held {
+		if example.Outcome != float64(i+1) {
+			t.Errorf("Example %d is from position %v, want %d", i, example.Outcome, i+1)
+		}
+	}
+
+	// The copy is the caller's to shuffle
+	held[0].Outcome = -1
+	if buffer.Examples()[0].Outcome != 1 {
+		t.Error("Changing the returned examples changed the buffer")
+	}
+}
@@ -2,9 +2,11 @@ package training
 
 import (
 	"fmt"
+	"log/slog"
 	"math/rand"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
@@ -16,7 +18,36 @@ import (
 type RPSTrainingExample struct {
 	BoardState   []float64
 	PolicyTarget []float64
-	ValueTarget  float64
+
+	// Outcome is the final result of the game for the player to move in
+	// BoardState: +1 for a win, 0 for a draw and -1 for a loss
+	Outcome float64
+
+	// ValueTarget is Outcome mapped onto the value network's [0, 1] output
+	// range; TrainNetworks regresses the value network toward it with MSE
+	ValueTarget float64
+
+	// Hash is the Zobrist hash of the position, which DeduplicateExamples
+	// uses to find repeats
+	Hash uint64
+}
+
+// GameOutcome returns the result of a finished game from player's perspective:
+// +1 if player won, -1 if they lost and 0 for a draw
+func GameOutcome(final *game.RPSGame, player game.RPSPlayer) float64 {
+	switch final.GetWinner() {
+	case game.NoPlayer:
+		return 0
+	case player:
+		return 1
+	}
+	return -1
+}
+
+// OutcomeValueTarget maps an outcome in [-1, 1] onto the sigmoid output of the
+// value network, so a win trains toward 1, a draw toward 0.5 and a loss toward 0
+func OutcomeValueTarget(outcome float64) float64 {
+	return (outcome + 1) / 2
 }
 
 // RPSSelfPlayParams contains parameters for self-play
@@ -25,9 +56,32 @@ type RPSSelfPlayParams struct {
 	DeckSize      int
 	HandSize      int
 	MaxRounds     int
+	BoardDim      int // Board side length (0 = standard 3x3); the networks must be built for it
 	MCTSParams    mcts.RPSMCTSParams
 	ForceParallel bool // Force parallel execution regardless of game count
 	NumThreads    int  // Specific number of threads to use (0 = auto)
+
+	// MaxExamples caps the examples held in memory (0 = no limit). Once it is
+	// reached no new games are started, and examples beyond it are dropped.
+	MaxExamples int
+
+	// Dedup merges the examples of positions reached more than once into one
+	// (see DeduplicateExamples) after all games are played. MaxExamples
+	// counts examples before merging, and GenerateGamesStream ignores Dedup.
+	Dedup bool
+
+	// ResignThreshold lets the player to move resign once the value network
+	// has rated their position below it, on its 0 (lost) to 1 (won) scale,
+	// on ResignPlies of their moves in a row. The positions of a resigned
+	// game are labelled as a loss for the player who resigned. 0 disables
+	// resignation.
+	ResignThreshold float64
+	ResignPlies     int
+
+	// ResignTestFraction of the games that would be resigned are played out
+	// instead, to measure how often resigning gives away a game that was not
+	// lost (see ResignStats)
+	ResignTestFraction float64
 }
 
 // DefaultRPSSelfPlayParams returns default self-play parameters
@@ -40,6 +94,9 @@ func DefaultRPSSelfPlayParams() RPSSelfPlayParams {
 		MCTSParams:    mcts.DefaultRPSMCTSParams(),
 		ForceParallel: false,
 		NumThreads:    0, // Auto-select thread count
+
+		ResignPlies:        3,
+		ResignTestFraction: 0.1,
 	}
 }
 
@@ -49,10 +106,67 @@ type RPSSelfPlay struct {
 	policyNetwork *neural.RPSPolicyNetwork
 	valueNetwork  *neural.RPSValueNetwork
 	examples      []RPSTrainingExample
+	stop          <-chan struct{}
+	monitor       Monitor
+
+	// Resignation counts for the current run, updated by every worker
+	resigned, resignChecks, falseResigns atomic.Int64
+}
+
+// ResignStats counts the resignations in a run of self-play
+type ResignStats struct {
+	Resigned     int // Games ended by resignation
+	Checked      int // Games that would have been resigned but were played out
+	FalseResigns int // Checked games the player who would have resigned did not lose
+}
+
+// FalseResignRate returns the fraction of checked games that should not have
+// been resigned, or 0 if none were checked
+func (s ResignStats) FalseResignRate() float64 {
+	if s.Checked == 0 {
+		return 0
+	}
+	return float64(s.FalseResigns) / float64(s.Checked)
+}
+
+// ResignStats returns the resignations in the most recent GenerateGames or
+// GenerateGamesStream run
+func (sp *RPSSelfPlay) ResignStats() ResignStats {
+	return ResignStats{
+		Resigned:     int(sp.resigned.Load()),
+		Checked:      int(sp.resignChecks.Load()),
+		FalseResigns: int(sp.falseResigns.Load()),
+	}
+}
+
+// resetResignStats clears the resignation counts before a run
+func (sp *RPSSelfPlay) resetResignStats() {
+	sp.resigned.Store(0)
+	sp.resignChecks.Store(0)
+	sp.falseResigns.Store(0)
 }
 
-// NewRPSSelfPlay creates a new self-play instance
+// reportResigns prints the resignation counts when resignation is enabled
+func (sp *RPSSelfPlay) reportResigns() {
+	if sp.params.ResignThreshold <= 0 {
+		return
+	}
+	stats := sp.ResignStats()
+	slog.Info("Resignations", "resigned", stats.Resigned, "checked", stats.Checked,
+		"false_resigns", stats.FalseResigns, "false_resign_rate", stats.FalseResignRate())
+}
+
+// NewRPSSelfPlay creates a new self-play instance. It panics if the networks
+// encode board states differently, since both train on the same features.
 func NewRPSSelfPlay(policyNetwork *neural.RPSPolicyNetwork, valueNetwork *neural.RPSValueNetwork, params RPSSelfPlayParams) *RPSSelfPlay {
+	policyEncoder, valueEncoder := policyNetwork.Encoder(), valueNetwork.Encoder()
+	if policyEncoder.Version() != valueEncoder.Version() || policyEncoder.Size() != valueEncoder.Size() ||
+		policyNetwork.UsesCanonicalInput() != valueNetwork.UsesCanonicalInput() {
+		panic(fmt.Sprintf("policy network encodes %d features (version %d, canonical %v), value network %d (version %d, canonical %v)",
+			policyEncoder.Size(), policyEncoder.Version(), policyNetwork.UsesCanonicalInput(),
+			valueEncoder.Size(), valueEncoder.Version(), valueNetwork.UsesCanonicalInput()))
+	}
+
 	return &RPSSelfPlay{
 		params:        params,
 		policyNetwork: policyNetwork,
@@ -61,35 +175,80 @@ func NewRPSSelfPlay(policyNetwork *neural.RPSPolicyNetwork, valueNetwork *neural
 	}
 }
 
+// StopOn makes self-play and training stop early once done is closed, for
+// example when the user interrupts a long run. Games already under way and the
+// current epoch are finished, and the examples or losses so far are returned.
+func (sp *RPSSelfPlay) StopOn(done <-chan struct{}) {
+	sp.stop = done
+}
+
+// MonitorWith reports the progress of self-play and training to monitor
+func (sp *RPSSelfPlay) MonitorWith(monitor Monitor) {
+	sp.monitor = monitor
+}
+
+// Stopped reports whether the channel given to StopOn has been closed
+func (sp *RPSSelfPlay) Stopped() bool {
+	return isClosed(sp.stop)
+}
+
 // GenerateGames generates games through self-play
 func (sp *RPSSelfPlay) GenerateGames(verbose bool) []RPSTrainingExample {
+	return sp.GenerateGamesWithProgress(verbose, nil)
+}
+
+// GenerateGamesWithProgress generates games through self-play like
+// GenerateGames, calling progress with the number of games finished so far and
+// the number to play after each game. Calls are made one at a time from the
+// calling goroutine, even when games are played in parallel. A nil progress is
+// ignored.
+func (sp *RPSSelfPlay) GenerateGamesWithProgress(verbose bool, progress func(done, total int)) []RPSTrainingExample {
 	sp.examples = make([]RPSTrainingExample, 0)
+	sp.resetResignStats()
+	if progress == nil {
+		progress = func(done, total int) {}
+	}
+	if sp.monitor != nil {
+		sp.monitor.SelfPlayStarted(sp.params.NumGames)
+	}
 
 	// Use serial or parallel generation based on game count and available cores
+	var examples []RPSTrainingExample
 	if (sp.params.NumGames < 5 || runtime.NumCPU() <= 2) && !sp.params.ForceParallel {
 		// Use original serial implementation for small jobs or limited cores
-		return sp.generateGamesSerial(verbose)
+		examples = sp.generateGamesSerial(verbose, progress)
 	} else {
 		// Use parallel implementation for larger jobs with multiple cores
 		// or when explicitly requested with ForceParallel
-		return sp.generateGamesParallel(verbose)
+		examples = sp.generateGamesParallel(verbose, progress)
 	}
+	sp.reportResigns()
+
+	if sp.params.Dedup {
+		sp.examples = DeduplicateExamples(examples)
+		slog.Info("Merged repeated positions", "examples", len(examples), "merged", len(sp.examples))
+		return sp.examples
+	}
+	return examples
 }
 
 // generateGamesSerial generates games serially (original implementation)
-func (sp *RPSSelfPlay) generateGamesSerial(verbose bool) []RPSTrainingExample {
+func (sp *RPSSelfPlay) generateGamesSerial(verbose bool, progress func(done, total int)) []RPSTrainingExample {
 	startTime := time.Now()
 	totalExamples := 0
 
-	for i := 0; i < sp.params.NumGames; i++ {
+	for i := 0; i < sp.params.NumGames && !sp.Stopped() && !sp.full(); i++ {
 		if verbose || (i+1)%10 == 0 || i == 0 {
-			fmt.Printf("Playing game %d/%d (%.1f%%)\n", i+1, sp.params.NumGames,
-				float64(i+1)/float64(sp.params.NumGames)*100)
+			slog.Debug("Playing game", "game", i+1, "games", sp.params.NumGames)
 		}
 
-		gameExamples := sp.playGame(verbose && i == 0)
+		gameExamples := sp.keep(sp.playGame(verbose && i == 0), len(sp.examples))
 		sp.examples = append(sp.examples, gameExamples...)
 		totalExamples += len(gameExamples)
+		progress(i+1, sp.params.NumGames)
+		if sp.monitor != nil {
+			sp.monitor.SelfPlayGameFinished(len(gameExamples))
+		}
 
 		// Report progress for long runs
 		if (i+1)%20 == 0 && i+1 < sp.params.NumGames {
@@ -98,10 +257,14 @@ func (sp *RPSSelfPlay) generateGamesSerial(verbose bool) []RPSTrainingExample {
 			estimatedTotal := time.Duration(float64(sp.params.NumGames) / gamesPerSecond * float64(time.Second))
 			estimatedRemaining := estimatedTotal - elapsed
 
-			fmt.Printf("  Progress: %d/%d games, %.2f games/sec, ~%s remaining\n",
-				i+1, sp.params.NumGames, gamesPerSecond, estimatedRemaining.Round(time.Second))
+			slog.Info("Self-play progress", "games", i+1, "total", sp.params.NumGames,
+				"games_per_sec", gamesPerSecond, "remaining", estimatedRemaining.Round(time.Second))
+			if verbose {
+				logMemoryStats()
+			}
 		}
 	}
+	sp.reportFull()
 
 	// Calculate statistics
 	elapsed := time.Since(startTime)
@@ -109,28 +272,21 @@ func (sp *RPSSelfPlay) generateGamesSerial(verbose bool) []RPSTrainingExample {
 	gamesPerSecond := float64(sp.params.NumGames) / elapsed.Seconds()
 
 	if verbose {
-		fmt.Printf("Generated %d training examples in %s (%.1f examples/game, %.2f games/sec)\n",
-			totalExamples, elapsed, examplesPerGame, gamesPerSecond)
+		slog.Info("Generated training examples", "examples", totalExamples, "elapsed", elapsed,
+			"examples_per_game", examplesPerGame, "games_per_sec", gamesPerSecond)
+		logMemoryStats()
 	}
 
 	return sp.examples
 }
 
 // generateGamesParallel generates games in parallel using multiple goroutines
-func (sp *RPSSelfPlay) generateGamesParallel(verbose bool) []RPSTrainingExample {
+func (sp *RPSSelfPlay) generateGamesParallel(verbose bool, progress func(done, total int)) []RPSTrainingExample {
 	startTime := time.Now()
+	numWorkers := sp.numWorkers()
 
-	// Determine number of workers based on CPU count
-	// Use N-1 workers to avoid saturating the system
-	numWorkers := runtime.NumCPU() - 1
-	if numWorkers < 1 {
-		numWorkers = 1
-	}
-
-	// Use explicit thread count if specified
-	if sp.params.NumThreads > 0 {
-		numWorkers = sp.params.NumThreads
-	}
+	// Closed by the collector once MaxExamples is reached
+	full := make(chan struct{})
 
 	// Create a buffered channel for game examples
 	gamesChan := make(chan []RPSTrainingExample, sp.params.NumGames)
@@ -162,10 +318,9 @@ func (sp *RPSSelfPlay) generateGamesParallel(verbose bool) []RPSTrainingExample
 						estimatedTotal := time.Duration(float64(sp.params.NumGames) / gamesPerSecond * float64(time.Second))
 						estimatedRemaining := estimatedTotal - elapsed
 
-						fmt.Printf("  Progress: %d/%d games (%.1f%%), %.2f games/sec, ~%s remaining\n",
-							completed, sp.params.NumGames,
-							float64(completed)/float64(sp.params.NumGames)*100,
-							gamesPerSecond, estimatedRemaining.Round(time.Second))
+						slog.Info("Self-play progress", "games", completed, "total", sp.params.NumGames,
+							"games_per_sec", gamesPerSecond, "remaining", estimatedRemaining.Round(time.Second))
+						logMemoryStats()
 					}
 
 				case <-ticker.C:
@@ -176,18 +331,16 @@ func (sp *RPSSelfPlay) generateGamesParallel(verbose bool) []RPSTrainingExample
 						estimatedTotal := time.Duration(float64(sp.params.NumGames) / gamesPerSecond * float64(time.Second))
 						estimatedRemaining := estimatedTotal - elapsed
 
-						fmt.Printf("  Progress: %d/%d games (%.1f%%), %.2f games/sec, ~%s remaining\n",
-							completed, sp.params.NumGames,
-							float64(completed)/float64(sp.params.NumGames)*100,
-							gamesPerSecond, estimatedRemaining.Round(time.Second))
+						slog.Info("Self-play progress", "games", completed, "total", sp.params.NumGames,
+							"games_per_sec", gamesPerSecond, "remaining", estimatedRemaining.Round(time.Second))
+						logMemoryStats()
 					}
 				}
 			}
 		}()
 	}
 
-	fmt.Printf("Starting parallel self-play with %d workers for %d games...\n",
-		numWorkers, sp.params.NumGames)
+	slog.Info("Starting parallel self-play", "workers", numWorkers, "games", sp.params.NumGames)
 
 	// Create and start worker goroutines
 	for i := 0; i < numWorkers; i++ {
@@ -210,7 +363,7 @@ func (sp *RPSSelfPlay) generateGamesParallel(verbose bool) []RPSTrainingExample
 			localValueNet := sp.valueNetwork.Clone()
 
 			// Each worker generates its assigned games
-			for j := startGame; j < endGame; j++ {
+			for j := startGame; j < endGame && !sp.Stopped() && !isClosed(full); j++ {
 				examples := sp.playGameWithNetworks(localPolicyNet, localValueNet, verbose && j == 0)
 				gamesChan <- examples
 				if verbose {
@@ -233,9 +386,19 @@ func (sp *RPSSelfPlay) generateGamesParallel(verbose bool) []RPSTrainingExample
 	allExamples := make([]RPSTrainingExample, 0)
 	totalExamples := 0
 
+	completed := 0
 	for examples := range gamesChan {
+		examples = sp.keep(examples, len(allExamples))
 		allExamples = append(allExamples, examples...)
 		totalExamples += len(examples)
+		completed++
+		progress(completed, sp.params.NumGames)
+		if sp.monitor != nil {
+			sp.monitor.SelfPlayGameFinished(len(examples))
+		}
+		if sp.params.MaxExamples > 0 && len(allExamples) >= sp.params.MaxExamples && !isClosed(full) {
+			close(full)
+		}
 	}
 
 	// Calculate and report statistics
@@ -243,103 +406,235 @@ func (sp *RPSSelfPlay) generateGamesParallel(verbose bool) []RPSTrainingExample
 	examplesPerGame := float64(totalExamples) / float64(sp.params.NumGames)
 	gamesPerSecond := float64(sp.params.NumGames) / elapsed.Seconds()
 
-	fmt.Printf("Generated %d training examples in %s (%.1f examples/game, %.2f games/sec)\n",
-		totalExamples, elapsed, examplesPerGame, gamesPerSecond)
+	slog.Info("Generated training examples", "examples", totalExamples, "elapsed", elapsed,
+		"examples_per_game", examplesPerGame, "games_per_sec", gamesPerSecond)
+	if verbose {
+		logMemoryStats()
+	}
 
 	sp.examples = allExamples
+	sp.reportFull()
 	return allExamples
 }
 
-// playGameWithNetworks plays a single game using the provided networks
-// This allows worker goroutines to use their own network copies
-func (sp *RPSSelfPlay) playGameWithNetworks(
-	policyNetwork *neural.RPSPolicyNetwork,
-	valueNetwork *neural.RPSValueNetwork,
-	verbose bool) []RPSTrainingExample {
+// full reports whether the examples held have reached MaxExamples
+func (sp *RPSSelfPlay) full() bool {
+	return sp.params.MaxExamples > 0 && len(sp.examples) >= sp.params.MaxExamples
+}
 
-	gameInstance := game.NewRPSGame(sp.params.DeckSize, sp.params.HandSize, sp.params.MaxRounds)
-	moveHistory := make([]game.RPSMove, 0)
-	stateHistory := make([]*game.RPSGame, 0)
-	policyHistory := make([][]float64, 0)
+// keep returns the part of a game's examples that fits under MaxExamples when
+// held already are kept
+func (sp *RPSSelfPlay) keep(examples []RPSTrainingExample, held int) []RPSTrainingExample {
+	if sp.params.MaxExamples > 0 && held+len(examples) > sp.params.MaxExamples {
+		return examples[:sp.params.MaxExamples-held]
+	}
+	return examples
+}
 
-	// Create MCTS instance with the worker's network copies
-	mctsParams := sp.params.MCTSParams
-	mctsEngine := mcts.NewRPSMCTS(policyNetwork, valueNetwork, mctsParams)
+// reportFull says when generation was cut short by MaxExamples
+func (sp *RPSSelfPlay) reportFull() {
+	if sp.full() {
+		slog.Info("Reached the example limit; stopped generating games", "max_examples", sp.params.MaxExamples)
+	}
+}
 
-	// Play until game is over
-	for !gameInstance.IsGameOver() {
-		// Store current state
-		stateHistory = append(stateHistory, gameInstance.Copy())
+// isClosed reports whether ch has been closed
+func isClosed(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
 
-		// Set root state for MCTS
-		mctsEngine.SetRootState(gameInstance)
+// numWorkers returns the number of goroutines to play games in parallel with
+func (sp *RPSSelfPlay) numWorkers() int {
+	// Use explicit thread count if specified
+	if sp.params.NumThreads > 0 {
+		return sp.params.NumThreads
+	}
 
-		// Search for best move
-		bestNode := mctsEngine.Search()
+	// Use N-1 workers to avoid saturating the system
+	if runtime.NumCPU() > 2 {
+		return runtime.NumCPU() - 1
+	}
+	return 1
+}
 
-		// Extract policy from MCTS visit counts
-		policy := sp.extractPolicy(bestNode)
-		policyHistory = append(policyHistory, policy)
+// GenerateGamesStream plays games like GenerateGames but sends each example to
+// out as soon as its game finishes, instead of keeping them all, and closes out
+// when done. Only the games under way are held in memory, so the consumer can
+// train on or save far more examples than would fit at once. MaxExamples does
+// not apply; the consumer decides how many examples to keep.
+func (sp *RPSSelfPlay) GenerateGamesStream(out chan<- RPSTrainingExample) {
+	defer close(out)
+	sp.resetResignStats()
+	if sp.monitor != nil {
+		sp.monitor.SelfPlayStarted(sp.params.NumGames)
+	}
 
-		// Make the move
-		if bestNode != nil && bestNode.Move != nil {
-			moveHistory = append(moveHistory, *bestNode.Move)
-			gameInstance.MakeMove(*bestNode.Move)
+	numWorkers := 1
+	if (sp.params.NumGames >= 5 && runtime.NumCPU() > 2) || sp.params.ForceParallel {
+		numWorkers = sp.numWorkers()
+	}
 
-			if verbose {
-				fmt.Println(gameInstance.String())
-			}
-		} else {
-			// Fallback to random move if MCTS fails
-			randomMove, err := gameInstance.GetRandomMove()
-			if err == nil {
-				moveHistory = append(moveHistory, randomMove)
-				gameInstance.MakeMove(randomMove)
+	// Hand out games one at a time so that a stop leaves no game unstarted
+	// in a worker's queue
+	gameIndices := make(chan int)
+	go func() {
+		defer close(gameIndices)
+		for i := 0; i < sp.params.NumGames && !sp.Stopped(); i++ {
+			gameIndices <- i
+		}
+	}()
 
-				if verbose {
-					fmt.Println(gameInstance.String())
-				}
-			} else {
-				// Break if no moves possible
-				break
+	gamesChan := make(chan []RPSTrainingExample, numWorkers)
+	var wg sync.WaitGroup
+	for i := 0; i < numWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			localPolicyNet := sp.policyNetwork.Clone()
+			localValueNet := sp.valueNetwork.Clone()
+			for range gameIndices {
+				gamesChan <- sp.playGameWithNetworks(localPolicyNet, localValueNet, false)
 			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(gamesChan)
+	}()
+
+	for examples := range gamesChan {
+		for _, example := range examples {
+			out <- example
+		}
+		if sp.monitor != nil {
+			sp.monitor.SelfPlayGameFinished(len(examples))
 		}
 	}
+}
 
-	// Determine game result
-	var value float64
-	winner := gameInstance.GetWinner()
+// playGameWithNetworks plays a single game using the provided networks
+// This allows worker goroutines to use their own network copies
+func (sp *RPSSelfPlay) playGameWithNetworks(
+	policyNetwork *neural.RPSPolicyNetwork,
+	valueNetwork *neural.RPSValueNetwork,
+	verbose bool) []RPSTrainingExample {
 
-	if winner == game.NoPlayer {
-		value = 0.5 // Draw
-	} else if winner == game.Player1 {
-		value = 1.0 // Player1 wins
-	} else {
-		value = 0.0 // Player2 wins
+	boardDim := sp.params.BoardDim
+	if boardDim == 0 {
+		boardDim = game.StandardBoardDim
 	}
+	gameInstance := game.NewRPSGameSized(boardDim, sp.params.DeckSize, sp.params.HandSize, sp.params.MaxRounds, nil)
 
-	// Create training examples
-	examples := make([]RPSTrainingExample, 0, len(stateHistory))
+	// Create MCTS instance with the worker's network copies
+	player := &rpsSelfPlayer{
+		sp:            sp,
+		policyNetwork: policyNetwork,
+		valueNetwork:  valueNetwork,
+		engine:        mcts.NewRPSMCTS(policyNetwork, valueNetwork, sp.params.MCTSParams),
+		playOn:        rand.Float64() < sp.params.ResignTestFraction,
+	}
 
-	for i, state := range stateHistory {
-		// Flip value based on player perspective
-		var targetValue float64
-		if state.CurrentPlayer == game.Player1 {
-			targetValue = value
+	played := PlaySelfPlayGame[*game.RPSGame, game.RPSMove](gameInstance, player, verbose)
+	if player.wouldResign != game.NoPlayer {
+		if !player.playOn {
+			sp.resigned.Add(1)
 		} else {
-			targetValue = 1.0 - value
+			// Resigning was wrong unless the player went on to lose
+			sp.resignChecks.Add(1)
+			if winner := gameInstance.GetWinner(); winner == player.wouldResign || winner == game.NoPlayer {
+				sp.falseResigns.Add(1)
+			}
 		}
+	}
 
-		example := RPSTrainingExample{
-			BoardState:   state.GetBoardAsFeatures(),
-			PolicyTarget: policyHistory[i],
-			ValueTarget:  targetValue,
+	examples := make([]RPSTrainingExample, len(played))
+	for i, e := range played {
+		examples[i] = RPSTrainingExample{
+			BoardState:   e.Features,
+			PolicyTarget: e.PolicyTarget,
+			Outcome:      e.Outcome,
+			ValueTarget:  OutcomeValueTarget(e.Outcome),
+			Hash:         e.Hash,
 		}
+	}
+	return examples
+}
+
+// rpsSelfPlayer plays RPS card games for PlaySelfPlayGame with one worker's networks
+type rpsSelfPlayer struct {
+	sp            *RPSSelfPlay
+	policyNetwork *neural.RPSPolicyNetwork
+	valueNetwork  *neural.RPSValueNetwork
+	engine        *mcts.RPSMCTS
+
+	// playOn is set for games played out to check resignation, and
+	// wouldResign to the player who resigned or would have. lowValues counts
+	// each player's moves in a row rated below ResignThreshold.
+	playOn      bool
+	wouldResign game.RPSPlayer
+	lowValues   [2]int
+}
 
-		examples = append(examples, example)
+// Resigns reports whether the player to move has rated their position below
+// ResignThreshold for ResignPlies moves in a row. In a game being played out
+// to check resignation, the first such player is noted but play continues.
+func (p *rpsSelfPlayer) Resigns(state *game.RPSGame) bool {
+	params := p.sp.params
+	if params.ResignThreshold <= 0 || p.wouldResign != game.NoPlayer {
+		return false
 	}
 
-	return examples
+	mover := 0
+	if state.CurrentPlayer == game.Player2 {
+		mover = 1
+	}
+	if p.valueNetwork.Predict(state) >= params.ResignThreshold {
+		p.lowValues[mover] = 0
+		return false
+	}
+	p.lowValues[mover]++
+	if p.lowValues[mover] < params.ResignPlies {
+		return false
+	}
+
+	p.wouldResign = state.CurrentPlayer
+	return !p.playOn
+}
+
+// ResignedOutcome scores state as a loss for the player who resigned
+func (p *rpsSelfPlayer) ResignedOutcome(resigned, state *game.RPSGame) float64 {
+	if state.CurrentPlayer == resigned.CurrentPlayer {
+		return -1
+	}
+	return 1
+}
+
+// Search returns the most visited move and the policy target: the share of the
+// root's visits that went to each square, summed over the cards played there
+func (p *rpsSelfPlayer) Search(state *game.RPSGame) (game.RPSMove, []float64, bool) {
+	p.engine.SetRootState(state)
+	bestNode := p.engine.Search()
+	policy := p.sp.extractPolicy(p.engine.Root)
+	if bestNode == nil || bestNode.Move == nil {
+		return game.RPSMove{}, policy, false
+	}
+	return *bestNode.Move, policy, true
+}
+
+// Features encodes the position as the policy network does, so canonical
+// networks train on positions seen from the player to move
+func (p *rpsSelfPlayer) Features(state *game.RPSGame) []float64 {
+	return p.policyNetwork.EncodeState(state)
+}
+
+// Outcome scores the finished game for the player to move in state
+func (p *rpsSelfPlayer) Outcome(final, state *game.RPSGame) float64 {
+	return GameOutcome(final, state.CurrentPlayer)
 }
 
 // Original playGame implementation remains unchanged
@@ -347,34 +642,28 @@ func (sp *RPSSelfPlay) playGame(verbose bool) []RPSTrainingExample {
 	return sp.playGameWithNetworks(sp.policyNetwork, sp.valueNetwork, verbose)
 }
 
-// extractPolicy extracts a policy distribution from MCTS visit counts
+// extractPolicy extracts a policy distribution over squares from the visit
+// counts of a searched node's children. Without visits to go on it spreads the
+// policy evenly over the legal squares, or over every square for a nil node.
 func (sp *RPSSelfPlay) extractPolicy(node *mcts.RPSMCTSNode) []float64 {
-	// Initialize policy target with zeros (9 possible positions)
-	policyTarget := make([]float64, 9)
+	// Initialize policy target with zeros, one entry per board position
+	positions := game.StandardBoardDim * game.StandardBoardDim
+	if node != nil && node.GameState != nil {
+		positions = len(node.GameState.Board)
+	}
+	policyTarget := make([]float64, positions)
 
 	// Check if node and children are valid
 	if node == nil || len(node.Children) == 0 {
-		// No children, return uniform distribution or zeros if no valid moves
-		if node != nil && node.GameState != nil {
-			validMoves := node.GameState.GetValidMoves()
-			if len(validMoves) > 0 {
-				prob := 1.0 / float64(len(validMoves))
-				for _, move := range validMoves {
-					if move.Position >= 0 && move.Position < 9 {
-						policyTarget[move.Position] = prob
-					}
-				}
-			}
-		}
-		return policyTarget
+		return uniformPolicy(node, policyTarget)
 	}
 
 	// Use visit counts from children to form the policy target
-	movesByPosition := make([]int64, 9) // Changed to int64 to match atomic.Int64.Load()
-	totalVisits := int64(0)             // Changed to int64
+	movesByPosition := make([]int64, positions) // Changed to int64 to match atomic.Int64.Load()
+	totalVisits := int64(0)                     // Changed to int64
 
 	for _, child := range node.Children {
-		if child.Move != nil && child.Move.Position >= 0 && child.Move.Position < 9 {
+		if child.Move != nil && child.Move.Position >= 0 && child.Move.Position < positions {
 			position := child.Move.Position
 			childVisitsLoaded := child.Visits.Load()
 			movesByPosition[position] += childVisitsLoaded
@@ -383,33 +672,57 @@ func (sp *RPSSelfPlay) extractPolicy(node *mcts.RPSMCTSNode) []float64 {
 	}
 
 	if totalVisits > 0 {
-		for i := 0; i < 9; i++ {
+		for i := 0; i < positions; i++ {
 			policyTarget[i] = float64(movesByPosition[i]) / float64(totalVisits)
 		}
 	} else {
 		// Fallback to uniform if no visits (should be rare if search ran)
-		if node.GameState != nil {
-			validMoves := node.GameState.GetValidMoves()
-			if len(validMoves) > 0 {
-				prob := 1.0 / float64(len(validMoves))
-				for _, move := range validMoves {
-					if move.Position >= 0 && move.Position < 9 {
-						policyTarget[move.Position] = prob
-					}
-				}
-			}
+		return uniformPolicy(node, policyTarget)
+	}
+
+	return policyTarget
+}
+
+// uniformPolicy fills policyTarget with an even share for each square node's
+// player can play on, or for every square if node has no game state. Several
+// cards can go on each square, so the share is per square, not per move. It
+// leaves policyTarget zero if there are no legal moves.
+func uniformPolicy(node *mcts.RPSMCTSNode, policyTarget []float64) []float64 {
+	if node == nil || node.GameState == nil {
+		for i := range policyTarget {
+			policyTarget[i] = 1.0 / float64(len(policyTarget))
 		}
+		return policyTarget
 	}
 
+	legal := make([]bool, len(policyTarget))
+	squares := 0
+	for _, move := range node.GameState.GetValidMoves() {
+		if move.Position >= 0 && move.Position < len(policyTarget) && !legal[move.Position] {
+			legal[move.Position] = true
+			squares++
+		}
+	}
+	for i, ok := range legal {
+		if ok {
+			policyTarget[i] = 1.0 / float64(squares)
+		}
+	}
 	return policyTarget
 }
 
+// SetExamples replaces the examples TrainNetworks learns from, for training on
+// examples gathered elsewhere such as a ReplayBuffer
+func (sp *RPSSelfPlay) SetExamples(examples []RPSTrainingExample) {
+	sp.examples = append([]RPSTrainingExample(nil), examples...)
+}
+
 // TrainNetworks trains the policy and value networks on the generated examples
 func (sp *RPSSelfPlay) TrainNetworks(numEpochs int, batchSize int, learningRate float64, verbose bool) ([]float64, []float64) {
 	// Check if we have examples
 	if len(sp.examples) == 0 {
 		if verbose {
-			fmt.Println("No training examples to learn from!")
+			slog.Warn("No training examples to learn from")
 		}
 		return nil, nil
 	}
@@ -488,6 +801,9 @@ func (sp *RPSSelfPlay) TrainNetworks(numEpochs int, batchSize int, learningRate
 		// Store the losses
 		policyLosses[epoch] = policyLoss
 		valueLosses[epoch] = valueLoss
+		if sp.monitor != nil {
+			sp.monitor.EpochFinished(epoch+1, policyLoss, valueLoss)
+		}
 
 		// Calculate improvement percentages
 		policyImprovement := 0.0
@@ -500,22 +816,27 @@ func (sp *RPSSelfPlay) TrainNetworks(numEpochs int, batchSize int, learningRate
 		}
 
 		if verbose {
-			improveStr := ""
+			attrs := []any{"epoch", epoch + 1, "epochs", numEpochs, "policy_loss", policyLoss, "value_loss", valueLoss}
 			if epoch > 0 {
-				improveStr = fmt.Sprintf(" (Policy: %+.1f%%, Value: %+.1f%%)",
-					policyImprovement, valueImprovement)
+				attrs = append(attrs, "policy_improvement_pct", policyImprovement, "value_improvement_pct", valueImprovement)
 			}
-
-			fmt.Printf("Epoch %d/%d - Policy Loss: %.4f, Value Loss: %.4f%s\n",
-				epoch+1, numEpochs, policyLoss, valueLoss, improveStr)
+			slog.Info("Epoch finished", attrs...)
+			logMemoryStats()
 
 			// Add extra warnings if we see unexpected patterns in the losses
 			if policyLoss < 0.0001 || valueLoss < 0.0001 {
-				fmt.Printf("WARNING: Very low loss detected, possible underfitting or training collapse\n")
+				slog.Warn("Very low loss detected, possible underfitting or training collapse", "epoch", epoch+1)
 			}
 			if epoch > 0 && (policyLoss > prevPolicyLoss*2 || valueLoss > prevValueLoss*2) {
-				fmt.Printf("WARNING: Loss increased significantly, possible training instability\n")
+				slog.Warn("Loss increased significantly, possible training instability", "epoch", epoch+1)
+			}
+		}
+
+		if sp.Stopped() && epoch+1 < numEpochs {
+			if verbose {
+				slog.Info("Stopping early", "epoch", epoch+1, "epochs", numEpochs)
 			}
+			return policyLosses[:epoch+1], valueLosses[:epoch+1]
 		}
 	}
 
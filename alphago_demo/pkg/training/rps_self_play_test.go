@@ -1,6 +1,7 @@
 package training
 
 import (
+	"math"
 	"testing"
 
 	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
@@ -60,6 +61,33 @@ func TestNewRPSSelfPlay(t *testing.T) {
 	}
 }
 
+func TestNewRPSSelfPlayRejectsMismatchedInputs(t *testing.T) {
+	canonicalPolicy := neural.NewRPSPolicyNetwork(16)
+	canonicalPolicy.SetCanonicalInput(true)
+
+	tests := []struct {
+		name   string
+		policy *neural.RPSPolicyNetwork
+		value  *neural.RPSValueNetwork
+	}{
+		{"canonical input", canonicalPolicy, neural.NewRPSValueNetwork(16)},
+		{"feature version", neural.NewRPSPolicyNetworkWithFeatures(16, 3, neural.ProgressFeatureVersion),
+			neural.NewRPSValueNetworkWithFeatures(16, 3, neural.BoardFeatureVersion)},
+		{"board size", neural.NewRPSPolicyNetworkWithFeatures(16, 3, neural.BoardFeatureVersion),
+			neural.NewRPSValueNetworkWithFeatures(16, 4, neural.BoardFeatureVersion)},
+	}
+	for _, test := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: expected a panic for networks with different encodings", test.name)
+				}
+			}()
+			NewRPSSelfPlay(test.policy, test.value, DefaultRPSSelfPlayParams())
+		}()
+	}
+}
+
 func TestRPSSelfPlayGenerateGames(t *testing.T) {
 	// Create small policy and value networks for faster testing
 	policyNetwork := neural.NewRPSPolicyNetwork(16)
@@ -90,10 +118,10 @@ func TestRPSSelfPlayGenerateGames(t *testing.T) {
 
 	// Check that examples have the correct format
 	for i, example := range examples {
-		// BoardState should have length 81
-		if len(example.BoardState) != 81 {
-			t.Errorf("Example %d: Expected BoardState to have length 81, got %d",
-				i, len(example.BoardState))
+		// BoardState should be encoded as the networks expect
+		if len(example.BoardState) != neural.CurrentFeatureVersion.InputSize(3) {
+			t.Errorf("Example %d: Expected BoardState to have length %d, got %d",
+				i, neural.CurrentFeatureVersion.InputSize(3), len(example.BoardState))
 		}
 
 		// PolicyTarget should have length 9
@@ -169,7 +197,7 @@ func TestRPSSelfPlayExtractPolicy(t *testing.T) {
 		childState.MakeMove(move)
 
 		child := mcts.NewRPSMCTSNode(childState, &move, root, nil)
-		child.Visits = (i + 1) * 10 // Position 0: 10 visits, Position 1: 20 visits, Position 2: 30 visits
+		child.Visits.Store(int64((i + 1) * 10)) // Position 0: 10 visits, Position 1: 20 visits, Position 2: 30 visits
 
 		root.Children = append(root.Children, child)
 	}
@@ -217,6 +245,63 @@ func TestRPSSelfPlayExtractPolicy(t *testing.T) {
 	}
 }
 
+func TestRPSSelfPlayExtractPolicyUniformFallback(t *testing.T) {
+	selfPlay := NewRPSSelfPlay(neural.NewRPSPolicyNetwork(16), neural.NewRPSValueNetwork(16), DefaultRPSSelfPlayParams())
+
+	// Five cards can go on each of the nine empty squares, but each square
+	// gets one share
+	gameState := game.NewRPSGame(15, 5, 10)
+	gameState.MakeMove(gameState.GetValidMoves()[0])
+	occupied := gameState.MoveHistory[0].Position
+
+	policy := selfPlay.extractPolicy(mcts.NewRPSMCTSNode(gameState, nil, nil, nil))
+	for i, p := range policy {
+		expected := 1.0 / 8.0
+		if i == occupied {
+			expected = 0
+		}
+		if math.Abs(p-expected) > 1e-12 {
+			t.Errorf("Expected policy[%d] to be %f, got %f", i, expected, p)
+		}
+	}
+}
+
+func TestRPSSelfPlayerSearchTargetsRootVisits(t *testing.T) {
+	params := DefaultRPSSelfPlayParams()
+	params.MCTSParams.NumSimulations = 50
+	params.MCTSParams.DirichletNoise = false
+	policyNetwork := neural.NewRPSPolicyNetwork(16)
+	valueNetwork := neural.NewRPSValueNetwork(16)
+	player := &rpsSelfPlayer{
+		sp:            NewRPSSelfPlay(policyNetwork, valueNetwork, params),
+		policyNetwork: policyNetwork,
+		valueNetwork:  valueNetwork,
+		engine:        mcts.NewRPSMCTS(policyNetwork, valueNetwork, params.MCTSParams),
+	}
+
+	move, policy, ok := player.Search(game.NewRPSGame(15, 5, 10))
+	if !ok {
+		t.Fatal("Expected the search to find a move")
+	}
+
+	// The target is the root's visit distribution, not that of the chosen
+	// child, whose children are the opponent's replies
+	visits := make([]float64, len(policy))
+	total := 0.0
+	for _, child := range player.engine.Root.Children {
+		visits[child.Move.Position] += float64(child.Visits.Load())
+		total += float64(child.Visits.Load())
+	}
+	for i, p := range policy {
+		if math.Abs(p-visits[i]/total) > 1e-12 {
+			t.Errorf("Expected policy[%d] to be %f, got %f", i, visits[i]/total, p)
+		}
+	}
+	if policy[move.Position] == 0 {
+		t.Errorf("Expected the chosen square %d to have visits", move.Position)
+	}
+}
+
 func TestRPSSelfPlayTrainNetworks(t *testing.T) {
 	// Create small policy and value networks for faster testing
 	policyNetwork := neural.NewRPSPolicyNetwork(16)
@@ -314,3 +399,287 @@ func TestRPSSelfPlayFullPipeline(t *testing.T) {
 			gameState.CurrentPlayer, bestMove.Player)
 	}
 }
+
+// terminalPosition builds a finished game: a full board with both hands empty,
+// where player 1 owns five cards and player 2 owns four
+func terminalPosition() *game.RPSGame {
+	g := &game.RPSGame{
+		Board:         make([]game.RPSCard, 9),
+		Player1Hand:   []game.RPSCard{},
+		Player2Hand:   []game.RPSCard{},
+		CurrentPlayer: game.Player1,
+		MoveHistory:   []game.RPSMove{},
+		Round:         10,
+		MaxRounds:     10,
+	}
+	for i := range g.Board {
+		owner := game.Player1
+		if i%2 == 1 {
+			owner = game.Player2
+		}
+		g.Board[i] = game.RPSCard{Type: game.RPSCardType(i % 3), Owner: owner}
+	}
+	return g
+}
+
+func TestGameOutcome(t *testing.T) {
+	final := terminalPosition()
+	if !final.IsGameOver() || final.GetWinner() != game.Player1 {
+		t.Fatalf("Expected a finished game won by player 1")
+	}
+
+	if got := GameOutcome(final, game.Player1); got != 1 {
+		t.Errorf("Winner's outcome = %v, want 1", got)
+	}
+	if got := GameOutcome(final, game.Player2); got != -1 {
+		t.Errorf("Loser's outcome = %v, want -1", got)
+	}
+
+	// Emptying one of player 1's squares leaves the board at four cards each
+	drawn := terminalPosition()
+	drawn.Board[0] = game.RPSCard{}
+	if got := GameOutcome(drawn, game.Player1); got != 0 {
+		t.Errorf("Draw outcome = %v, want 0", got)
+	}
+
+	for outcome, want := range map[float64]float64{1: 1, 0: 0.5, -1: 0} {
+		if got := OutcomeValueTarget(outcome); got != want {
+			t.Errorf("OutcomeValueTarget(%v) = %v, want %v", outcome, got, want)
+		}
+	}
+}
+
+func TestTrainNetworksFitsOutcome(t *testing.T) {
+	policyNetwork := neural.NewRPSPolicyNetwork(16)
+	valueNetwork := neural.NewRPSValueNetwork(16)
+	selfPlay := NewRPSSelfPlay(policyNetwork, valueNetwork, DefaultRPSSelfPlayParams())
+
+	// Player 1 is to move in a position they have already won
+	final := terminalPosition()
+	outcome := GameOutcome(final, final.CurrentPlayer)
+	uniform := make([]float64, 9)
+	for i := range uniform {
+		uniform[i] = 1.0 / 9.0
+	}
+	selfPlay.examples = []RPSTrainingExample{{
+		BoardState:   policyNetwork.EncodeState(final),
+		PolicyTarget: uniform,
+		Outcome:      outcome,
+		ValueTarget:  OutcomeValueTarget(outcome),
+	}}
+
+	before := valueNetwork.Predict(final)
+	_, valueLosses := selfPlay.TrainNetworks(50, 1, 0.1, false)
+	after := valueNetwork.Predict(final)
+
+	if len(valueLosses) != 50 {
+		t.Fatalf("Expected 50 value losses, got %d", len(valueLosses))
+	}
+	if valueLosses[len(valueLosses)-1] >= valueLosses[0] {
+		t.Errorf("Value loss did not decrease: first %v, last %v", valueLosses[0], valueLosses[len(valueLosses)-1])
+	}
+	if after <= before {
+		t.Errorf("Value prediction for a won position moved away from 1: before %v, after %v", before, after)
+	}
+}
+
+func TestGenerateGamesWithProgress(t *testing.T) {
+	for _, parallel := range []bool{false, true} {
+		params := DefaultRPSSelfPlayParams()
+		params.NumGames = 3
+		params.MCTSParams.NumSimulations = 5
+		params.ForceParallel = parallel
+		params.NumThreads = 2
+		selfPlay := NewRPSSelfPlay(neural.NewRPSPolicyNetwork(16), neural.NewRPSValueNetwork(16), params)
+
+		var calls []int
+		selfPlay.GenerateGamesWithProgress(false, func(done, total int) {
+			if total != params.NumGames {
+				t.Errorf("parallel=%v: total = %d, want %d", parallel, total, params.NumGames)
+			}
+			calls = append(calls, done)
+		})
+
+		if len(calls) != params.NumGames {
+			t.Fatalf("parallel=%v: got %d progress calls, want %d", parallel, len(calls), params.NumGames)
+		}
+		for i, done := range calls {
+			if done != i+1 {
+				t.Errorf("parallel=%v: call %d reported %d games done, want %d", parallel, i, done, i+1)
+			}
+		}
+	}
+}
+
+// recordingMonitor remembers what a Monitor is told
+type recordingMonitor struct {
+	started  []int
+	examples []int
+	epochs   []int
+}
+
+func (m *recordingMonitor) SelfPlayStarted(games int) {
+	m.started = append(m.started, games)
+}
+
+func (m *recordingMonitor) SelfPlayGameFinished(examples int) {
+	m.examples = append(m.examples, examples)
+}
+
+func (m *recordingMonitor) EpochFinished(epoch int, policyLoss, valueLoss float64) {
+	m.epochs = append(m.epochs, epoch)
+}
+
+func TestRPSSelfPlayMonitor(t *testing.T) {
+	for _, parallel := range []bool{false, true} {
+		params := DefaultRPSSelfPlayParams()
+		params.NumGames = 3
+		params.MCTSParams.NumSimulations = 5
+		params.ForceParallel = parallel
+		params.NumThreads = 2
+		selfPlay := NewRPSSelfPlay(neural.NewRPSPolicyNetwork(16), neural.NewRPSValueNetwork(16), params)
+		monitor := &recordingMonitor{}
+		selfPlay.MonitorWith(monitor)
+
+		examples := selfPlay.GenerateGames(false)
+		selfPlay.TrainNetworks(2, 8, 0.01, false)
+
+		if len(monitor.started) != 1 || monitor.started[0] != params.NumGames {
+			t.Errorf("parallel=%v: SelfPlayStarted calls %v, want one with %d", parallel, monitor.started, params.NumGames)
+		}
+		total := 0
+		for _, n := range monitor.examples {
+			total += n
+		}
+		if len(monitor.examples) != params.NumGames || total != len(examples) {
+			t.Errorf("parallel=%v: %d games reported with %d examples, want %d with %d",
+				parallel, len(monitor.examples), total, params.NumGames, len(examples))
+		}
+		if len(monitor.epochs) != 2 || monitor.epochs[0] != 1 || monitor.epochs[1] != 2 {
+			t.Errorf("parallel=%v: EpochFinished reported epochs %v, want [1 2]", parallel, monitor.epochs)
+		}
+	}
+}
+
+func TestRPSSelfPlayStopOn(t *testing.T) {
+	params := DefaultRPSSelfPlayParams()
+	params.NumGames = 3
+	params.MCTSParams.NumSimulations = 5
+	selfPlay := NewRPSSelfPlay(neural.NewRPSPolicyNetwork(16), neural.NewRPSValueNetwork(16), params)
+	examples := selfPlay.GenerateGames(false)
+
+	stop := make(chan struct{})
+	selfPlay.StopOn(stop)
+	if selfPlay.Stopped() {
+		t.Fatal("Stopped before the channel was closed")
+	}
+	close(stop)
+
+	// Training finishes the epoch under way, then stops
+	policyLosses, valueLosses := selfPlay.TrainNetworks(5, 8, 0.01, false)
+	if len(policyLosses) != 1 || len(valueLosses) != 1 {
+		t.Errorf("Expected one epoch of losses after stopping, got %d and %d", len(policyLosses), len(valueLosses))
+	}
+	if len(examples) == 0 {
+		t.Fatal("Expected examples before stopping")
+	}
+
+	// No new games are started once stopped
+	if examples := selfPlay.GenerateGames(false); len(examples) != 0 {
+		t.Errorf("Expected no examples after stopping, got %d", len(examples))
+	}
+}
+
+func TestRPSSelfPlayMaxExamples(t *testing.T) {
+	for _, parallel := range []bool{false, true} {
+		params := DefaultRPSSelfPlayParams()
+		params.NumGames = 20
+		params.MCTSParams.NumSimulations = 5
+		params.MaxExamples = 7
+		params.ForceParallel = parallel
+		params.NumThreads = 2
+		selfPlay := NewRPSSelfPlay(neural.NewRPSPolicyNetwork(16), neural.NewRPSValueNetwork(16), params)
+
+		played := 0
+		examples := selfPlay.GenerateGamesWithProgress(false, func(done, total int) { played = done })
+		if len(examples) != params.MaxExamples {
+			t.Errorf("parallel=%v: got %d examples, want the cap of %d", parallel, len(examples), params.MaxExamples)
+		}
+		// Parallel workers may finish their queued games before they see the cap
+		if !parallel && played == params.NumGames {
+			t.Errorf("All %d games were played despite the cap", played)
+		}
+	}
+}
+
+func TestRPSSelfPlayGenerateGamesStream(t *testing.T) {
+	for _, parallel := range []bool{false, true} {
+		params := DefaultRPSSelfPlayParams()
+		params.NumGames = 6
+		params.MCTSParams.NumSimulations = 5
+		params.ForceParallel = parallel
+		params.NumThreads = 3
+		selfPlay := NewRPSSelfPlay(neural.NewRPSPolicyNetwork(16), neural.NewRPSValueNetwork(16), params)
+
+		out := make(chan RPSTrainingExample)
+		go selfPlay.GenerateGamesStream(out)
+
+		count := 0
+		for example := range out {
+			count++
+			if len(example.BoardState) != neural.CurrentFeatureVersion.InputSize(3) || len(example.PolicyTarget) != 9 {
+				t.Fatalf("parallel=%v: malformed example %+v", parallel, example)
+			}
+		}
+
+		// Every game produces at least one example
+		if count < params.NumGames {
+			t.Errorf("parallel=%v: streamed %d examples from %d games", parallel, count, params.NumGames)
+		}
+		if len(selfPlay.examples) != 0 {
+			t.Errorf("parallel=%v: streaming kept %d examples", parallel, len(selfPlay.examples))
+		}
+	}
+}
+
+func TestRPSSelfPlayResign(t *testing.T) {
+	params := DefaultRPSSelfPlayParams()
+	params.NumGames = 3
+	params.MCTSParams.NumSimulations = 5
+	// The value network never rates a position above 1, so the first player
+	// resigns on their second move
+	params.ResignThreshold = 1.01
+	params.ResignPlies = 2
+	params.ResignTestFraction = 0
+	selfPlay := NewRPSSelfPlay(neural.NewRPSPolicyNetwork(16), neural.NewRPSValueNetwork(16), params)
+
+	examples := selfPlay.GenerateGames(false)
+	if len(examples) != 2*params.NumGames {
+		t.Fatalf("Got %d examples, want 2 for each of %d resigned games", len(examples), params.NumGames)
+	}
+	for i, example := range examples {
+		// Player 1, who resigned, moved first
+		want := 1.0
+		if i%2 == 0 {
+			want = -1
+		}
+		if example.Outcome != want || example.ValueTarget != OutcomeValueTarget(want) {
+			t.Errorf("Example %d labelled %v, want %v", i, example.Outcome, want)
+		}
+	}
+	if stats := selfPlay.ResignStats(); stats != (ResignStats{Resigned: params.NumGames}) {
+		t.Errorf("ResignStats() = %+v, want %d resigned games", stats, params.NumGames)
+	}
+
+	// Played out games count toward the false resignation rate instead
+	params.ResignTestFraction = 1
+	selfPlay = NewRPSSelfPlay(neural.NewRPSPolicyNetwork(16), neural.NewRPSValueNetwork(16), params)
+	examples = selfPlay.GenerateGames(false)
+	stats := selfPlay.ResignStats()
+	if stats.Resigned != 0 || stats.Checked != params.NumGames || stats.FalseResigns > stats.Checked {
+		t.Errorf("ResignStats() = %+v, want %d checked games", stats, params.NumGames)
+	}
+	if len(examples) <= 2*params.NumGames {
+		t.Errorf("Got %d examples, want the checked games played out", len(examples))
+	}
+}
@@ -1,7 +1,7 @@
 package training
 
 import (
-	"fmt"
+	"log/slog"
 	"math/rand"
 
 	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
@@ -54,7 +54,7 @@ func (sp *AGSelfPlay) GenerateGames(verbose bool) []AGTrainingExample {
 
 	for i := 0; i < sp.params.NumGames; i++ {
 		if verbose && i%10 == 0 {
-			fmt.Printf("Playing game %d/%d\n", i+1, sp.params.NumGames)
+			slog.Info("Playing game", "game", i+1, "games", sp.params.NumGames)
 		}
 
 		gameExamples := sp.playGame(verbose && i == 0)
@@ -66,88 +66,54 @@ func (sp *AGSelfPlay) GenerateGames(verbose bool) []AGTrainingExample {
 
 // playGame plays a single game and returns training examples
 func (sp *AGSelfPlay) playGame(verbose bool) []AGTrainingExample {
-	gameInstance := game.NewAGGame()
-	moveHistory := make([]game.AGMove, 0)
-	stateHistory := make([]*game.AGGame, 0)
-	policyHistory := make([][]float64, 0)
-
-	// Create MCTS instance
-	mctsEngine := mcts.NewAGMCTS(sp.policyNetwork, sp.valueNetwork, sp.params.MCTSParams)
-
-	// Play until game is over
-	for !gameInstance.IsGameOver() {
-		// Store current state
-		stateHistory = append(stateHistory, gameInstance.Copy())
-
-		// Set root state for MCTS
-		mctsEngine.SetRootState(gameInstance)
-
-		// Search for best move
-		bestNode := mctsEngine.Search()
-
-		// Extract policy from MCTS visit counts
-		policy := sp.extractPolicy(bestNode)
-		policyHistory = append(policyHistory, policy)
-
-		// Make the move
-		if bestNode != nil && bestNode.Move != nil {
-			moveHistory = append(moveHistory, *bestNode.Move)
-			gameInstance.MakeMove(*bestNode.Move)
+	player := &agSelfPlayer{
+		sp:     sp,
+		engine: mcts.NewAGMCTS(sp.policyNetwork, sp.valueNetwork, sp.params.MCTSParams),
+	}
 
-			if verbose {
-				fmt.Println(gameInstance.String())
-			}
-		} else {
-			// Fallback to random move if MCTS fails
-			randomMove, err := gameInstance.GetRandomMove()
-			if err == nil {
-				moveHistory = append(moveHistory, randomMove)
-				gameInstance.MakeMove(randomMove)
-
-				if verbose {
-					fmt.Println(gameInstance.String())
-				}
-			} else {
-				// Break if no moves possible
-				break
-			}
+	played := PlaySelfPlayGame[*game.AGGame, game.AGMove](game.NewAGGame(), player, verbose)
+	examples := make([]AGTrainingExample, len(played))
+	for i, e := range played {
+		examples[i] = AGTrainingExample{
+			BoardState:   e.Features,
+			PolicyTarget: e.PolicyTarget,
+			ValueTarget:  OutcomeValueTarget(e.Outcome),
 		}
 	}
+	return examples
+}
 
-	// Determine game result
-	var value float64
-	winner := gameInstance.GetWinner()
+// agSelfPlayer plays Tic-Tac-Toe for PlaySelfPlayGame
+type agSelfPlayer struct {
+	sp     *AGSelfPlay
+	engine *mcts.AGMCTS
+}
 
-	if winner == game.Empty {
-		value = 0.5 // Draw
-	} else if winner == game.PlayerX {
-		value = 1.0 // X wins
-	} else {
-		value = 0.0 // O wins
+// Search returns the most visited move and the visit distribution over squares
+func (p *agSelfPlayer) Search(state *game.AGGame) (game.AGMove, []float64, bool) {
+	p.engine.SetRootState(state)
+	bestNode := p.engine.Search()
+	policy := p.sp.extractPolicy(bestNode)
+	if bestNode == nil || bestNode.Move == nil {
+		return game.AGMove{}, policy, false
 	}
+	return *bestNode.Move, policy, true
+}
 
-	// Create training examples
-	examples := make([]AGTrainingExample, 0, len(stateHistory))
-
-	for i, state := range stateHistory {
-		// Flip value based on player perspective
-		var targetValue float64
-		if state.CurrentPlayer == game.PlayerX {
-			targetValue = value
-		} else {
-			targetValue = 1.0 - value
-		}
-
-		example := AGTrainingExample{
-			BoardState:   state.GetBoardAsFeatures(),
-			PolicyTarget: policyHistory[i],
-			ValueTarget:  targetValue,
-		}
+// Features encodes the board as the Tic-Tac-Toe networks expect
+func (p *agSelfPlayer) Features(state *game.AGGame) []float64 {
+	return state.GetBoardAsFeatures()
+}
 
-		examples = append(examples, example)
+// Outcome scores the finished game for the player to move in state
+func (p *agSelfPlayer) Outcome(final, state *game.AGGame) float64 {
+	switch final.GetWinner() {
+	case game.Empty:
+		return 0
+	case state.CurrentPlayer:
+		return 1
 	}
-
-	return examples
+	return -1
 }
 
 // extractPolicy extracts a policy distribution from MCTS visit counts
@@ -192,7 +158,7 @@ func (sp *AGSelfPlay) TrainNetworks(numEpochs int, batchSize int, learningRate f
 	// Check if we have examples
 	if len(sp.examples) == 0 {
 		if verbose {
-			fmt.Println("No training examples to learn from!")
+			slog.Warn("No training examples to learn from")
 		}
 		return nil, nil
 	}
@@ -252,8 +218,8 @@ func (sp *AGSelfPlay) TrainNetworks(numEpochs int, batchSize int, learningRate f
 		valueLosses[epoch] = valueLoss
 
 		if verbose || epoch%10 == 0 {
-			fmt.Printf("Epoch %d/%d - Policy Loss: %.4f, Value Loss: %.4f\n",
-				epoch+1, numEpochs, policyLoss, valueLoss)
+			slog.Info("Epoch finished", "epoch", epoch+1, "epochs", numEpochs,
+				"policy_loss", policyLoss, "value_loss", valueLoss)
 		}
 	}
 
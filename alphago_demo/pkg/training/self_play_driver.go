@@ -0,0 +1,142 @@
+package training
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// SelfPlayable is the part of a game's rules that self-play needs. G is the
+// position type itself, so that Copy returns something that can be played on,
+// and M is the game's move type. game.RPSGame and game.AGGame both satisfy it.
+type SelfPlayable[G any, M any] interface {
+	GetValidMoves() []M
+	MakeMove(move M) error
+	GetRandomMove() (M, error)
+	IsGameOver() bool
+	Copy() G
+	String() string
+}
+
+// SelfPlayer supplies what self-play needs beyond the rules of a game G with
+// moves M: a search to choose moves and the encoding of positions as examples
+type SelfPlayer[G any, M any] interface {
+	// Search chooses the move to play in state and returns the policy target
+	// for the position. ok is false when the search found no move, in which
+	// case a random move is played but the policy is still recorded.
+	Search(state G) (move M, policy []float64, ok bool)
+
+	// Features encodes a position as network input
+	Features(state G) []float64
+
+	// Outcome returns the result of the finished game final for the player
+	// to move in state: +1 for a win, 0 for a draw and -1 for a loss
+	Outcome(final G, state G) float64
+}
+
+// SelfPlayExample is one position from a self-play game with its training targets
+type SelfPlayExample struct {
+	Features     []float64
+	PolicyTarget []float64
+	Outcome      float64
+
+	// Hash identifies the position when G has a Hash method, like the Zobrist
+	// hash of game.RPSGame, and is 0 otherwise
+	Hash uint64
+}
+
+// Monitor follows self-play and training as they run, for example to export
+// their progress to a dashboard. Its methods are called from the goroutine
+// generating games or training, even when games are played in parallel.
+type Monitor interface {
+	// SelfPlayStarted is called when a run of the given number of games begins
+	SelfPlayStarted(games int)
+
+	// SelfPlayGameFinished is called after each game with the examples it
+	// produced
+	SelfPlayGameFinished(examples int)
+
+	// EpochFinished is called after each training epoch, counting from 1,
+	// with its average losses
+	EpochFinished(epoch int, policyLoss, valueLoss float64)
+}
+
+// positionHasher is implemented by games that can hash their positions
+type positionHasher interface {
+	Hash() uint64
+}
+
+// Resigner is implemented by SelfPlayers that can resign hopeless games
+type Resigner[G any] interface {
+	// Resigns is asked before every move whether the player to move in
+	// state gives up
+	Resigns(state G) bool
+
+	// ResignedOutcome labels state when the player to move in resigned gave
+	// up, on the same scale as SelfPlayer.Outcome
+	ResignedOutcome(resigned G, state G) float64
+}
+
+// PlaySelfPlayGame plays g to the end with moves chosen by player and returns
+// an example for every position in which a move was chosen. g is played on
+// directly; pass a copy to keep the starting position. If player is also a
+// Resigner the game can end early by resignation, leaving g unfinished.
+func PlaySelfPlayGame[G SelfPlayable[G, M], M any](g G, player SelfPlayer[G, M], verbose bool) []SelfPlayExample {
+	states := make([]G, 0)
+	policies := make([][]float64, 0)
+	resigner, canResign := player.(Resigner[G])
+
+	for !g.IsGameOver() {
+		if canResign && resigner.Resigns(g) {
+			if verbose {
+				slog.Info("Resigned")
+			}
+			// Label the game as lost by the player who resigned
+			resigned := g.Copy()
+			return labelExamples(states, policies, player, func(state G) float64 {
+				return resigner.ResignedOutcome(resigned, state)
+			})
+		}
+
+		states = append(states, g.Copy())
+		move, policy, ok := player.Search(g)
+		policies = append(policies, policy)
+
+		if !ok {
+			// Fall back to a random move if the search fails
+			randomMove, err := g.GetRandomMove()
+			if err != nil {
+				// No moves are possible
+				break
+			}
+			move = randomMove
+		}
+
+		g.MakeMove(move)
+		if verbose {
+			fmt.Println(g.String())
+		}
+	}
+
+	// Label every position with the final result, seen from the player who
+	// was to move there
+	return labelExamples(states, policies, player, func(state G) float64 {
+		return player.Outcome(g, state)
+	})
+}
+
+// labelExamples makes an example of each position played, with the policy
+// chosen there and the result given by outcome
+func labelExamples[G any, M any](states []G, policies [][]float64, player SelfPlayer[G, M], outcome func(state G) float64) []SelfPlayExample {
+	examples := make([]SelfPlayExample, len(states))
+	for i, state := range states {
+		examples[i] = SelfPlayExample{
+			Features:     player.Features(state),
+			PolicyTarget: policies[i],
+			Outcome:      outcome(state),
+		}
+		if hasher, ok := any(state).(positionHasher); ok {
+			examples[i].Hash = hasher.Hash()
+		}
+	}
+	return examples
+}
@@ -0,0 +1,54 @@
+package training
+
+import "math/rand"
+
+// SplitDataset shuffles examples with the given seed and splits off about
+// valFraction of them for validation. Examples of the same position, matched
+// by Zobrist hash, always go to the same side so that validation never scores
+// a position the network was trained on. The same examples, fraction and seed
+// always give the same split.
+func SplitDataset(examples []TrainingExample, valFraction float64, seed int64) (train, val []TrainingExample) {
+	// Fill validation with whole positions until it has its share
+	wantVal := int(float64(len(examples)) * valFraction)
+	for _, group := range shuffledPositions(examples, seed) {
+		if len(val) < wantVal {
+			val = append(val, group...)
+		} else {
+			train = append(train, group...)
+		}
+	}
+	return train, val
+}
+
+// shuffledPositions groups examples by position and shuffles the groups with
+// the given seed
+func shuffledPositions(examples []TrainingExample, seed int64) [][]TrainingExample {
+	// Group examples by position, in order of first appearance
+	var groups [][]TrainingExample
+	groupOf := make(map[uint64]int)
+	for _, example := range examples {
+		hash := example.Game().Hash()
+		i, ok := groupOf[hash]
+		if !ok {
+			i = len(groups)
+			groupOf[hash] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], example)
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	rng.Shuffle(len(groups), func(i, j int) {
+		groups[i], groups[j] = groups[j], groups[i]
+	})
+	return groups
+}
+
+// UniquePositions counts the distinct positions among examples
+func UniquePositions(examples []TrainingExample) int {
+	seen := make(map[uint64]bool)
+	for _, example := range examples {
+		seen[example.Game().Hash()] = true
+	}
+	return len(seen)
+}
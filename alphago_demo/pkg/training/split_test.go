@@ -0,0 +1,48 @@
+package training
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitDataset(t *testing.T) {
+	// 300 examples of 100 positions, each position appearing three times
+	var examples []TrainingExample
+	for repeat := 0; repeat < 3; repeat++ {
+		for _, example := range makeExamples(0, 100) {
+			example.SearchDepth = repeat
+			examples = append(examples, example)
+		}
+	}
+	positions := UniquePositions(examples)
+
+	train, val := SplitDataset(examples, 0.2, 42)
+	if len(train)+len(val) != len(examples) {
+		t.Fatalf("Split %d examples into %d and %d", len(examples), len(train), len(val))
+	}
+	if len(val) < 60 || len(val) > 62 {
+		t.Errorf("Validation has %d examples, want about 60", len(val))
+	}
+
+	trainPositions := make(map[uint64]bool)
+	for _, example := range train {
+		trainPositions[example.Game().Hash()] = true
+	}
+	for _, example := range val {
+		if trainPositions[example.Game().Hash()] {
+			t.Fatalf("Position %v is in both the training and validation sets", example.BoardState)
+		}
+	}
+	if UniquePositions(train)+UniquePositions(val) != positions {
+		t.Errorf("Positions split %d + %d, want %d in total", UniquePositions(train), UniquePositions(val), positions)
+	}
+
+	// The same seed gives the same split and another seed a different one
+	train2, val2 := SplitDataset(examples, 0.2, 42)
+	if !reflect.DeepEqual(train, train2) || !reflect.DeepEqual(val, val2) {
+		t.Error("Splitting twice with the same seed gave different results")
+	}
+	if _, val3 := SplitDataset(examples, 0.2, 43); reflect.DeepEqual(val, val3) {
+		t.Error("A different seed gave the same validation set")
+	}
+}
@@ -0,0 +1,148 @@
+package training
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"time"
+
+	neural "github.com/zachbeta/neural_rps/alphago_demo/pkg/rps_net_impl"
+)
+
+// ThreadTuneParams configures AutotuneThreads
+type ThreadTuneParams struct {
+	SelfPlay   RPSSelfPlayParams // Games played per trial; the thread settings are overridden
+	HiddenSize int               // Hidden layer size of the networks used for the trials
+	MaxThreads int               // Largest thread count to try (0 = min(2 x CPU cores, 32))
+
+	// The sweep stops once Patience trials in a row fail to beat the best
+	// throughput so far by MinGain (a fraction, so 0.05 is 5%). Self-play
+	// becomes memory-bound well before it runs out of cores, so past that
+	// point more threads only add contention.
+	MinGain  float64
+	Patience int
+}
+
+// DefaultThreadTuneParams returns parameters that take a few seconds per trial
+// on a typical machine
+func DefaultThreadTuneParams() ThreadTuneParams {
+	selfPlay := DefaultRPSSelfPlayParams()
+	selfPlay.NumGames = 50
+
+	return ThreadTuneParams{
+		SelfPlay:   selfPlay,
+		HiddenSize: 64,
+		MinGain:    0.05,
+		Patience:   2,
+	}
+}
+
+// ThreadTrial is the self-play throughput measured with one thread count
+type ThreadTrial struct {
+	Threads        int
+	GamesPerSecond float64
+	Speedup        float64 // Relative to a single thread
+}
+
+// ThreadTuneResult reports the trials run by AutotuneThreads
+type ThreadTuneResult struct {
+	Trials []ThreadTrial
+
+	// Peak is the thread count with the highest throughput, and Recommended
+	// the fewest threads reaching within MinGain of it
+	Peak        int
+	Recommended int
+
+	// Plateaued is true if the sweep stopped early because throughput
+	// stopped improving
+	Plateaued bool
+}
+
+// AutotuneThreads measures self-play throughput with 1, 2, 3, ... worker
+// threads and recommends a thread count. Each trial runs its own worker pool
+// with fresh networks and leaves GOMAXPROCS alone, so it is safe to call from
+// a program that is doing other work.
+func AutotuneThreads(params ThreadTuneParams) ThreadTuneResult {
+	return tuneThreads(params, func(threads int) float64 {
+		selfPlayParams := params.SelfPlay
+		selfPlayParams.ForceParallel = true
+		selfPlayParams.NumThreads = threads
+
+		selfPlay := NewRPSSelfPlay(
+			neural.NewRPSPolicyNetwork(params.HiddenSize),
+			neural.NewRPSValueNetwork(params.HiddenSize),
+			selfPlayParams)
+
+		start := time.Now()
+		selfPlay.GenerateGames(false)
+		return float64(selfPlayParams.NumGames) / time.Since(start).Seconds()
+	})
+}
+
+// tuneThreads runs the sweep for AutotuneThreads, using measure to find the
+// games per second with a given number of threads
+func tuneThreads(params ThreadTuneParams, measure func(threads int) float64) ThreadTuneResult {
+	maxThreads := params.MaxThreads
+	if maxThreads <= 0 {
+		maxThreads = runtime.NumCPU() * 2
+		if maxThreads > 32 {
+			maxThreads = 32
+		}
+	}
+	patience := params.Patience
+	if patience < 1 {
+		patience = 1
+	}
+
+	var result ThreadTuneResult
+	var baseline, best float64
+	stalled := 0
+	for threads := 1; threads <= maxThreads; threads++ {
+		gamesPerSecond := measure(threads)
+		if threads == 1 {
+			baseline = gamesPerSecond
+		}
+		result.Trials = append(result.Trials, ThreadTrial{
+			Threads:        threads,
+			GamesPerSecond: gamesPerSecond,
+			Speedup:        gamesPerSecond / baseline,
+		})
+
+		if gamesPerSecond > best*(1+params.MinGain) {
+			stalled = 0
+		} else {
+			stalled++
+		}
+		if gamesPerSecond > best {
+			best = gamesPerSecond
+			result.Peak = threads
+		}
+
+		if stalled >= patience && threads < maxThreads {
+			result.Plateaued = true
+			break
+		}
+	}
+
+	for _, trial := range result.Trials {
+		if trial.GamesPerSecond >= best*(1-params.MinGain) {
+			result.Recommended = trial.Threads
+			break
+		}
+	}
+	return result
+}
+
+// WriteCSV writes one line per trial with the thread count, games per second
+// and speedup over a single thread
+func (r ThreadTuneResult) WriteCSV(w io.Writer) error {
+	if _, err := fmt.Fprintln(w, "Thread Count,Games Per Second,Speedup Factor"); err != nil {
+		return err
+	}
+	for _, trial := range r.Trials {
+		if _, err := fmt.Fprintf(w, "%d,%.2f,%.2f\n", trial.Threads, trial.GamesPerSecond, trial.Speedup); err != nil {
+			return err
+		}
+	}
+	return nil
+}
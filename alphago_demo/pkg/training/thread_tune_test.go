@@ -0,0 +1,59 @@
+package training
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestTuneThreadsStopsAtPlateau(t *testing.T) {
+	// Throughput scales to four threads, then flattens and falls off
+	speeds := []float64{10, 19, 27, 30, 30.5, 29, 25, 20}
+	params := ThreadTuneParams{MaxThreads: len(speeds), MinGain: 0.05, Patience: 2}
+
+	result := tuneThreads(params, func(threads int) float64 { return speeds[threads-1] })
+
+	if !result.Plateaued || len(result.Trials) != 6 {
+		t.Errorf("Sweep ran %d trials (plateaued %v), want it to stop after 6", len(result.Trials), result.Plateaued)
+	}
+	if result.Peak != 5 {
+		t.Errorf("Peak = %d threads, want 5", result.Peak)
+	}
+	if result.Recommended != 4 {
+		t.Errorf("Recommended = %d threads, want 4, the fewest within 5%% of the peak", result.Recommended)
+	}
+	if trial := result.Trials[1]; trial.Speedup != 1.9 {
+		t.Errorf("Speedup with 2 threads = %v, want 1.9", trial.Speedup)
+	}
+
+	var csv bytes.Buffer
+	if err := result.WriteCSV(&csv); err != nil {
+		t.Fatalf("WriteCSV failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(csv.String()), "\n")
+	if len(lines) != 7 || lines[0] != "Thread Count,Games Per Second,Speedup Factor" || lines[2] != "2,19.00,1.90" {
+		t.Errorf("Unexpected CSV:\n%s", csv.String())
+	}
+}
+
+func TestAutotuneThreadsLeavesGOMAXPROCS(t *testing.T) {
+	params := DefaultThreadTuneParams()
+	params.SelfPlay.NumGames = 4
+	params.SelfPlay.MCTSParams.NumSimulations = 5
+	params.HiddenSize = 8
+	params.MaxThreads = 2
+
+	before := runtime.GOMAXPROCS(0)
+	result := AutotuneThreads(params)
+	if after := runtime.GOMAXPROCS(0); after != before {
+		t.Errorf("GOMAXPROCS changed from %d to %d", before, after)
+	}
+
+	if len(result.Trials) == 0 || result.Trials[0].Threads != 1 || result.Trials[0].Speedup != 1 {
+		t.Fatalf("Unexpected trials: %+v", result.Trials)
+	}
+	if result.Recommended < 1 || result.Recommended > params.MaxThreads {
+		t.Errorf("Recommended %d threads, want 1 to %d", result.Recommended, params.MaxThreads)
+	}
+}
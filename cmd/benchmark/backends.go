@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+
+	neural "github.com/zachbeta/neural_rps/alphago_demo/pkg/rps_net_impl"
+	"github.com/zachbeta/neural_rps/pkg/common"
+	"github.com/zachbeta/neural_rps/pkg/neural/gpu"
+)
+
+// backend runs a model's inference on one implementation. Every backend
+// returns the same function of its input: a policy model's move probabilities
+// or a value model's single value.
+type backend interface {
+	Name() string
+	Predict(input []float64) ([]float64, error)
+	PredictBatch(inputs [][]float64) ([][]float64, error)
+	Close() error
+}
+
+// statsReporter is implemented by backends that measure their own calls
+type statsReporter interface {
+	GetStats() common.NetworkStats
+}
+
+// goBackend is the native Go network the model was trained with, and the
+// reference the other backends are checked against
+type goBackend struct {
+	m *model
+}
+
+func newGoBackend(m *model) *goBackend {
+	return &goBackend{m: m}
+}
+
+func (b *goBackend) Name() string { return "Go" }
+
+func (b *goBackend) Predict(input []float64) ([]float64, error) {
+	if b.m.policy != nil {
+		return b.m.policy.PredictFeatures(input), nil
+	}
+	return []float64{b.m.value.PredictFeatures(input)}, nil
+}
+
+func (b *goBackend) PredictBatch(inputs [][]float64) ([][]float64, error) {
+	if b.m.policy != nil {
+		return b.m.policy.PredictBatchFeatures(inputs), nil
+	}
+	outputs := make([][]float64, len(inputs))
+	for i, input := range inputs {
+		outputs[i] = []float64{b.m.value.PredictFeatures(input)}
+	}
+	return outputs, nil
+}
+
+func (b *goBackend) Close() error { return nil }
+
+// blasBackend is the Go policy network with batch inference done by BLAS
+// matrix multiplies. It needs a build with -tags blas.
+type blasBackend struct {
+	network *neural.RPSPolicyNetwork
+}
+
+func newBLASBackend(m *model) (*blasBackend, error) {
+	if m.policy == nil {
+		return nil, fmt.Errorf("BLAS inference is only implemented for policy networks")
+	}
+	network := m.policy.Clone()
+	if err := network.SetUseBLAS(true); err != nil {
+		return nil, err
+	}
+	return &blasBackend{network: network}, nil
+}
+
+func (b *blasBackend) Name() string { return "Go BLAS" }
+
+func (b *blasBackend) Predict(input []float64) ([]float64, error) {
+	return b.network.PredictBatchFeatures([][]float64{input})[0], nil
+}
+
+func (b *blasBackend) PredictBatch(inputs [][]float64) ([][]float64, error) {
+	return b.network.PredictBatchFeatures(inputs), nil
+}
+
+func (b *blasBackend) Close() error { return nil }
+
+// serviceBackend sends inputs to the gRPC neural service. The service serves
+// the model it was started with, which must be exported from the same model
+// file for the correctness check to pass.
+type serviceBackend struct {
+	policy *gpu.RPSGPUPolicyNetwork
+	value  *gpu.RPSGPUValueNetwork
+}
+
+// newServiceBackend connects to the service at addr and checks that it serves
+// a network of the model's shape
+func newServiceBackend(m *model, addr string) (*serviceBackend, error) {
+	b := &serviceBackend{}
+	var inputSize, outputSize int
+	if m.policy != nil {
+		network, err := gpu.NewRPSGPUPolicyNetwork(addr)
+		if err != nil {
+			return nil, err
+		}
+		b.policy = network
+		inputSize, outputSize = network.InputSize, network.OutputSize
+	} else {
+		network, err := gpu.NewRPSGPUValueNetwork(addr)
+		if err != nil {
+			return nil, err
+		}
+		b.value = network
+		inputSize, outputSize = network.InputSize, network.OutputSize
+	}
+
+	if inputSize != m.header.InputSize || outputSize != m.outputSize() {
+		b.Close()
+		return nil, fmt.Errorf("service serves a %dx%d %s network, the model is %dx%d",
+			inputSize, outputSize, m.header.NetworkType, m.header.InputSize, m.outputSize())
+	}
+	return b, nil
+}
+
+func (b *serviceBackend) Name() string { return "GPU service" }
+
+func (b *serviceBackend) Predict(input []float64) ([]float64, error) {
+	if b.policy != nil {
+		return b.policy.Forward(input)
+	}
+	value, err := b.value.Evaluate(input)
+	if err != nil {
+		return nil, err
+	}
+	return []float64{value}, nil
+}
+
+func (b *serviceBackend) PredictBatch(inputs [][]float64) ([][]float64, error) {
+	if b.policy != nil {
+		return b.policy.BatchForward(inputs)
+	}
+	values, err := b.value.BatchEvaluate(inputs)
+	if err != nil {
+		return nil, err
+	}
+	outputs := make([][]float64, len(values))
+	for i, value := range values {
+		outputs[i] = []float64{value}
+	}
+	return outputs, nil
+}
+
+// GetStats returns the client's measurements of its calls to the service
+func (b *serviceBackend) GetStats() common.NetworkStats {
+	if b.policy != nil {
+		return b.policy.GetStats()
+	}
+	return b.value.GetStats()
+}
+
+func (b *serviceBackend) Close() error {
+	if b.policy != nil {
+		return b.policy.Close()
+	}
+	return b.value.Close()
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+
+	"github.com/zachbeta/neural_rps/alphago_demo/pkg/game"
+	neural "github.com/zachbeta/neural_rps/alphago_demo/pkg/rps_net_impl"
+)
+
+// model is the trained network every backend is built from
+type model struct {
+	path   string
+	header neural.ModelHeader
+
+	// Exactly one of policy and value is set
+	policy *neural.RPSPolicyNetwork
+	value  *neural.RPSValueNetwork
+
+	weights modelWeights
+}
+
+// modelWeights are the layers of a model file, read directly so they can be
+// handed to backends that do not use the neural package
+type modelWeights struct {
+	WeightsInputHidden  [][]float64 `json:"weightsInputHidden"`  // [hidden][input]
+	BiasesHidden        []float64   `json:"biasesHidden"`        // [hidden]
+	WeightsHiddenOutput [][]float64 `json:"weightsHiddenOutput"` // [output][hidden]
+	BiasesOutput        []float64   `json:"biasesOutput"`        // [output], policy files only
+	BiasOutput          float64     `json:"biasOutput"`          // Value files only
+
+	// Batch norm on the hidden layer, in files saved with it
+	BatchNorm                bool      `json:"batchNorm"`
+	BatchNormGamma           []float64 `json:"batchNormGamma"`
+	BatchNormBeta            []float64 `json:"batchNormBeta"`
+	BatchNormRunningMean     []float64 `json:"batchNormRunningMean"`
+	BatchNormRunningVariance []float64 `json:"batchNormRunningVariance"`
+}
+
+// batchNormEpsilon matches the neural package's
+const batchNormEpsilon = 1e-5
+
+// foldBatchNorm folds inference-time batch norm, a fixed affine map per hidden
+// neuron, into the input->hidden layer, so backends need not implement it
+func (w *modelWeights) foldBatchNorm() {
+	if !w.BatchNorm {
+		return
+	}
+	for i, row := range w.WeightsInputHidden {
+		scale := w.BatchNormGamma[i] / math.Sqrt(w.BatchNormRunningVariance[i]+batchNormEpsilon)
+		for j := range row {
+			row[j] *= scale
+		}
+		w.BiasesHidden[i] = scale*(w.BiasesHidden[i]-w.BatchNormRunningMean[i]) + w.BatchNormBeta[i]
+	}
+	w.BatchNorm = false
+}
+
+// loadModel loads a policy or value model saved by the training commands
+func loadModel(path string) (*model, error) {
+	header, err := neural.ReadModelHeader(path)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &model{path: path, header: header}
+	switch header.NetworkType {
+	case neural.PolicyNetworkType:
+		if m.policy, err = neural.LoadPolicyNetwork(path); err != nil {
+			return nil, err
+		}
+	case neural.ValueNetworkType:
+		m.value = neural.NewRPSValueNetwork(header.HiddenSize)
+		if err := m.value.LoadFromFile(path); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown network type %q", header.NetworkType)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &m.weights); err != nil {
+		return nil, err
+	}
+	if m.value != nil {
+		m.weights.BiasesOutput = []float64{m.weights.BiasOutput}
+	}
+	m.weights.foldBatchNorm()
+	return m, nil
+}
+
+// outputSize returns the number of outputs every backend must give per input
+func (m *model) outputSize() int {
+	if m.policy != nil {
+		return m.header.OutputSize
+	}
+	return 1
+}
+
+// encoder returns the encoder the model was trained with
+func (m *model) encoder() neural.Encoder {
+	if m.policy != nil {
+		return m.policy.Encoder()
+	}
+	return m.value.Encoder()
+}
+
+// games returns count positions reached by seeded random play, so every
+// backend is timed and checked on the same realistic inputs
+func (m *model) games(count int, seed int64) []*game.RPSGame {
+	rng := rand.New(rand.NewSource(seed))
+	games := make([]*game.RPSGame, 0, count)
+	for len(games) < count {
+		g := game.NewRPSGameSeeded(21, 5, 10, rng)
+		for !g.IsGameOver() && len(games) < count {
+			games = append(games, g.Copy())
+			moves := g.GetValidMoves()
+			g.MakeMove(moves[rng.Intn(len(moves))])
+		}
+	}
+	return games
+}
+
+// positions encodes the positions returned by games
+func (m *model) positions(count int, seed int64) [][]float64 {
+	encoder := m.encoder()
+	games := m.games(count, seed)
+	inputs := make([][]float64, len(games))
+	for i, g := range games {
+		inputs[i] = encoder.Encode(g)
+	}
+	return inputs
+}
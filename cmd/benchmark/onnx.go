@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+
+	ort "github.com/yalue/onnxruntime_go"
+)
+
+// onnxBackend runs an ONNX export of the model with ONNX Runtime on the CPU.
+// The export must come from the same model file, for example with
+// scripts/convert_go_json_to_onnx.py --input_mode go_json.
+type onnxBackend struct {
+	session    *ort.DynamicSession[float32, float32]
+	inputSize  int
+	outputSize int
+}
+
+// newONNXBackend loads the ONNX model at path, using the ONNX Runtime shared
+// library at libPath, and checks that it has the model's shape
+func newONNXBackend(m *model, path, libPath string) (*onnxBackend, error) {
+	ort.SetSharedLibraryPath(libPath)
+	if err := ort.InitializeEnvironment(); err != nil {
+		return nil, fmt.Errorf("failed to initialize ONNX Runtime: %v", err)
+	}
+
+	inputsInfo, outputsInfo, err := ort.GetInputOutputInfo(path)
+	if err != nil {
+		ort.DestroyEnvironment()
+		return nil, err
+	}
+	if len(inputsInfo) != 1 || len(outputsInfo) != 1 ||
+		len(inputsInfo[0].Dimensions) != 2 || len(outputsInfo[0].Dimensions) != 2 {
+		ort.DestroyEnvironment()
+		return nil, fmt.Errorf("expected one [batch, features] input and output")
+	}
+	inputSize := int(inputsInfo[0].Dimensions[1])
+	outputSize := int(outputsInfo[0].Dimensions[1])
+	if inputSize != m.header.InputSize || outputSize != m.outputSize() {
+		ort.DestroyEnvironment()
+		return nil, fmt.Errorf("ONNX model is %dx%d, the model is %dx%d",
+			inputSize, outputSize, m.header.InputSize, m.outputSize())
+	}
+
+	session, err := ort.NewDynamicSession[float32, float32](path,
+		[]string{inputsInfo[0].Name}, []string{outputsInfo[0].Name})
+	if err != nil {
+		ort.DestroyEnvironment()
+		return nil, err
+	}
+	return &onnxBackend{session: session, inputSize: inputSize, outputSize: outputSize}, nil
+}
+
+func (b *onnxBackend) Name() string { return "ONNX CPU" }
+
+func (b *onnxBackend) Predict(input []float64) ([]float64, error) {
+	outputs, err := b.PredictBatch([][]float64{input})
+	if err != nil {
+		return nil, err
+	}
+	return outputs[0], nil
+}
+
+func (b *onnxBackend) PredictBatch(inputs [][]float64) ([][]float64, error) {
+	data := make([]float32, 0, len(inputs)*b.inputSize)
+	for _, input := range inputs {
+		if len(input) != b.inputSize {
+			return nil, fmt.Errorf("expected %d features, got %d", b.inputSize, len(input))
+		}
+		for _, v := range input {
+			data = append(data, float32(v))
+		}
+	}
+
+	inputTensor, err := ort.NewTensor(ort.NewShape(int64(len(inputs)), int64(b.inputSize)), data)
+	if err != nil {
+		return nil, err
+	}
+	defer inputTensor.Destroy()
+	outputTensor, err := ort.NewEmptyTensor[float32](ort.NewShape(int64(len(inputs)), int64(b.outputSize)))
+	if err != nil {
+		return nil, err
+	}
+	defer outputTensor.Destroy()
+
+	if err := b.session.Run([]*ort.Tensor[float32]{inputTensor}, []*ort.Tensor[float32]{outputTensor}); err != nil {
+		return nil, err
+	}
+
+	flat := outputTensor.GetData()
+	outputs := make([][]float64, len(inputs))
+	for i := range outputs {
+		outputs[i] = make([]float64, b.outputSize)
+		for j := range outputs[i] {
+			outputs[i][j] = float64(flat[i*b.outputSize+j])
+		}
+	}
+	return outputs, nil
+}
+
+func (b *onnxBackend) Close() error {
+	err := b.session.Destroy()
+	ort.DestroyEnvironment()
+	return err
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// quantizedLayer is a dense layer with int8 weights. Each output neuron's
+// weights share one scale, chosen so its largest weight maps to 127.
+type quantizedLayer struct {
+	weights [][]int8  // [output][input]
+	scales  []float64 // [output]
+	biases  []float64 // [output], kept in full precision
+}
+
+// quantizeLayer quantizes the weights of a dense layer
+func quantizeLayer(weights [][]float64, biases []float64) quantizedLayer {
+	layer := quantizedLayer{
+		weights: make([][]int8, len(weights)),
+		scales:  make([]float64, len(weights)),
+		biases:  append([]float64(nil), biases...),
+	}
+	for i, row := range weights {
+		maxAbs := 0.0
+		for _, w := range row {
+			maxAbs = math.Max(maxAbs, math.Abs(w))
+		}
+		scale := 1.0
+		if maxAbs > 0 {
+			scale = maxAbs / 127
+		}
+
+		layer.scales[i] = scale
+		layer.weights[i] = make([]int8, len(row))
+		for j, w := range row {
+			layer.weights[i][j] = int8(math.Round(w / scale))
+		}
+	}
+	return layer
+}
+
+// apply returns the layer's pre-activation outputs for input
+func (l quantizedLayer) apply(input []float64) []float64 {
+	output := make([]float64, len(l.weights))
+	for i, row := range l.weights {
+		sum := 0.0
+		for j, w := range row {
+			sum += float64(w) * input[j]
+		}
+		output[i] = sum*l.scales[i] + l.biases[i]
+	}
+	return output
+}
+
+// quantizedBackend runs the model with int8 weights, trading accuracy for a
+// quarter of the weight memory
+type quantizedBackend struct {
+	inputSize int
+	policy    bool
+	hidden    quantizedLayer
+	output    quantizedLayer
+}
+
+func newQuantizedBackend(m *model) *quantizedBackend {
+	return &quantizedBackend{
+		inputSize: m.header.InputSize,
+		policy:    m.policy != nil,
+		hidden:    quantizeLayer(m.weights.WeightsInputHidden, m.weights.BiasesHidden),
+		output:    quantizeLayer(m.weights.WeightsHiddenOutput, m.weights.BiasesOutput),
+	}
+}
+
+func (b *quantizedBackend) Name() string { return "Go int8" }
+
+func (b *quantizedBackend) Predict(input []float64) ([]float64, error) {
+	if len(input) != b.inputSize {
+		return nil, fmt.Errorf("expected %d features, got %d", b.inputSize, len(input))
+	}
+
+	hidden := b.hidden.apply(input)
+	for i, h := range hidden {
+		hidden[i] = math.Max(h, 0)
+	}
+	output := b.output.apply(hidden)
+
+	if !b.policy {
+		return []float64{1 / (1 + math.Exp(-output[0]))}, nil
+	}
+	maxLogit := output[0]
+	for _, o := range output {
+		maxLogit = math.Max(maxLogit, o)
+	}
+	sum := 0.0
+	for i, o := range output {
+		output[i] = math.Exp(o - maxLogit)
+		sum += output[i]
+	}
+	for i := range output {
+		output[i] /= sum
+	}
+	return output, nil
+}
+
+func (b *quantizedBackend) PredictBatch(inputs [][]float64) ([][]float64, error) {
+	outputs := make([][]float64, len(inputs))
+	for i, input := range inputs {
+		output, err := b.Predict(input)
+		if err != nil {
+			return nil, err
+		}
+		outputs[i] = output
+	}
+	return outputs, nil
+}
+
+func (b *quantizedBackend) Close() error { return nil }
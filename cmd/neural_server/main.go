@@ -0,0 +1,69 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	neural "github.com/zachbeta/neural_rps/alphago_demo/pkg/rps_net_impl"
+	"github.com/zachbeta/neural_rps/pkg/neural/server"
+)
+
+const (
+	defaultPort       = 50054 // Same default as the ONNX Python gRPC service
+	defaultHiddenSize = 128
+)
+
+func main() {
+	port := flag.Int("port", defaultPort, "Port to serve the NeuralService gRPC API on")
+	policyPath := flag.String("policy", "", "Path to the policy network (.model)")
+	valuePath := flag.String("value", "", "Path to the value network (.model)")
+	flag.Parse()
+
+	if *policyPath == "" && *valuePath == "" {
+		log.Fatalf("At least one of -policy or -value must be provided")
+	}
+
+	var policyNet *neural.RPSPolicyNetwork
+	if *policyPath != "" {
+		policyNet = neural.NewRPSPolicyNetwork(defaultHiddenSize)
+		if err := policyNet.LoadFromFile(*policyPath); err != nil {
+			log.Fatalf("Failed to load policy network: %v", err)
+		}
+		fmt.Printf("Loaded policy network from %s (hidden size %d)\n", *policyPath, policyNet.GetHiddenSize())
+	}
+
+	var valueNet *neural.RPSValueNetwork
+	if *valuePath != "" {
+		valueNet = neural.NewRPSValueNetwork(defaultHiddenSize)
+		if err := valueNet.LoadFromFile(*valuePath); err != nil {
+			log.Fatalf("Failed to load value network: %v", err)
+		}
+		fmt.Printf("Loaded value network from %s (hidden size %d)\n", *valuePath, valueNet.GetHiddenSize())
+	}
+
+	srv := server.NewServer(policyNet, valueNet)
+
+	// Print server-side stats on shutdown
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigCh
+		stats := srv.GetStats()
+		fmt.Printf("\nTotal calls: %d, Total positions: %d\n", stats.TotalCalls, stats.TotalBatchSize)
+		fmt.Printf("Avg latency: %.2f µs, Avg batch size: %.2f\n", stats.AvgLatencyUs, stats.AvgBatchSize)
+		fmt.Printf("Latency p50/p90/p99/max: %.2f/%.2f/%.2f/%.2f µs, Batch size min/max: %d/%d\n",
+			stats.P50LatencyUs, stats.P90LatencyUs, stats.P99LatencyUs, stats.MaxLatencyUs,
+			stats.MinBatchSize, stats.MaxBatchSize)
+		os.Exit(0)
+	}()
+
+	addr := fmt.Sprintf(":%d", *port)
+	fmt.Printf("Go neural service listening on %s\n", addr)
+	if err := srv.ListenAndServe(addr); err != nil {
+		log.Fatalf("Server failed: %v", err)
+	}
+}
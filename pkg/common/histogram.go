@@ -0,0 +1,88 @@
+package common
+
+import (
+	"math"
+	"math/bits"
+	"time"
+)
+
+// histogramSubBuckets is the number of linear buckets per power of two. Values
+// below it are counted exactly; larger values share a bucket with others
+// within 1/64 (about 1.6%) of them.
+const histogramSubBuckets = 128
+
+// LatencyHistogram counts latencies in HDR-style buckets: exact up to 128ns,
+// then 64 buckets per power of two. It keeps quantiles accurate to about 1.6%
+// in constant memory however many latencies are recorded. The zero value is
+// ready to use; it is not safe for concurrent use.
+type LatencyHistogram struct {
+	counts []int64
+	total  int64
+}
+
+// Record adds one latency
+func (h *LatencyHistogram) Record(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	i := histogramIndex(uint64(d))
+	if i >= len(h.counts) {
+		grown := make([]int64, i+1)
+		copy(grown, h.counts)
+		h.counts = grown
+	}
+	h.counts[i]++
+	h.total++
+}
+
+// Count returns the number of latencies recorded
+func (h *LatencyHistogram) Count() int64 {
+	return h.total
+}
+
+// Quantile returns the latency that q of the recorded latencies are at or
+// below, for q in [0, 1], rounded up to the top of its bucket. It returns 0 if
+// nothing has been recorded.
+func (h *LatencyHistogram) Quantile(q float64) time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+	// The nearest rank of the latency wanted, counting from 1
+	rank := int64(math.Ceil(q * float64(h.total)))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > h.total {
+		rank = h.total
+	}
+
+	var seen int64
+	for i, count := range h.counts {
+		seen += count
+		if seen >= rank {
+			return time.Duration(histogramBucketMax(i))
+		}
+	}
+	return time.Duration(histogramBucketMax(len(h.counts) - 1))
+}
+
+// histogramIndex returns the bucket holding v
+func histogramIndex(v uint64) int {
+	if v < histogramSubBuckets {
+		return int(v)
+	}
+	// Shift v down so it lands in [64, 128), keeping its top seven bits
+	shift := bits.Len64(v) - bits.Len64(histogramSubBuckets-1)
+	return histogramSubBuckets + (shift-1)*(histogramSubBuckets/2) + int(v>>shift) - histogramSubBuckets/2
+}
+
+// histogramBucketMax returns the largest value in bucket i
+func histogramBucketMax(i int) uint64 {
+	if i < histogramSubBuckets {
+		return uint64(i)
+	}
+	half := histogramSubBuckets / 2
+	shift := (i-histogramSubBuckets)/half + 1
+	top := uint64((i-histogramSubBuckets)%half + half)
+	return (top+1)<<shift - 1
+}
@@ -0,0 +1,85 @@
+package common
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHistogramExactBelowSubBuckets(t *testing.T) {
+	for v := uint64(0); v < histogramSubBuckets; v++ {
+		if i := histogramIndex(v); i != int(v) {
+			t.Errorf("Value %d: expected bucket %d, got %d", v, v, i)
+		}
+		if max := histogramBucketMax(int(v)); max != v {
+			t.Errorf("Bucket %d: expected max %d, got %d", v, v, max)
+		}
+	}
+}
+
+func TestHistogramBucketsAreContiguous(t *testing.T) {
+	// Every value up to 2^20 lands in the bucket after the previous value's,
+	// or the same one, and a bucket ends where the next begins
+	prev := histogramIndex(0)
+	for v := uint64(1); v < 1<<20; v++ {
+		i := histogramIndex(v)
+		switch i {
+		case prev:
+		case prev + 1:
+			if max := histogramBucketMax(prev); max != v-1 {
+				t.Fatalf("Bucket %d ends at %d, but the next bucket starts at %d", prev, max, v)
+			}
+		default:
+			t.Fatalf("Value %d is in bucket %d, after value %d in bucket %d", v, i, v-1, prev)
+		}
+		prev = i
+	}
+
+	// Around every larger power of two
+	for shift := 20; shift < 63; shift++ {
+		power := uint64(1) << shift
+		below, at := histogramIndex(power-1), histogramIndex(power)
+		if at != below+1 || histogramBucketMax(below) != power-1 {
+			t.Errorf("2^%d: expected bucket %d to end at %d and the next to start there, got buckets %d and %d ending at %d",
+				shift, below, power-1, below, at, histogramBucketMax(below))
+		}
+	}
+}
+
+func TestHistogramBucketWidth(t *testing.T) {
+	for i := histogramSubBuckets; i < histogramIndex(1<<40); i++ {
+		max, prevMax := histogramBucketMax(i), histogramBucketMax(i-1)
+		if width := max - prevMax; float64(width) > float64(max)/64 {
+			t.Fatalf("Bucket %d holds %d values up to %d, wider than 1/64 of its values", i, width, max)
+		}
+	}
+}
+
+func TestHistogramQuantiles(t *testing.T) {
+	var h LatencyHistogram
+	if q := h.Quantile(0.5); q != 0 {
+		t.Errorf("Expected 0 from an empty histogram, got %v", q)
+	}
+
+	// Latencies of 1µs to 10ms in steps of 1µs
+	for i := 1; i <= 10000; i++ {
+		h.Record(time.Duration(i) * time.Microsecond)
+	}
+	if h.Count() != 10000 {
+		t.Fatalf("Expected 10000 latencies, got %d", h.Count())
+	}
+
+	for _, test := range []struct {
+		q    float64
+		want time.Duration
+	}{
+		{0.50, 5000 * time.Microsecond},
+		{0.99, 9900 * time.Microsecond},
+		{1, 10000 * time.Microsecond},
+	} {
+		// Quantiles round up to the top of their bucket
+		got := h.Quantile(test.q)
+		if got < test.want || got > test.want+test.want/64 {
+			t.Errorf("Quantile %v: expected %v to within 1/64, got %v", test.q, test.want, got)
+		}
+	}
+}
@@ -34,11 +34,38 @@ type NetworkStats struct {
 	// TotalBatchSize is the total number of positions evaluated
 	TotalBatchSize int
 
-	// AvgLatencyUs is the average latency per call in microseconds
+	// AvgLatencyUs is the average latency per successful call in microseconds
 	AvgLatencyUs float64
 
+	// P50LatencyUs, P90LatencyUs and P99LatencyUs are latency percentiles per
+	// call in microseconds. Averages hide the slow calls that make search
+	// stall, so these show the tail.
+	P50LatencyUs float64
+	P90LatencyUs float64
+	P99LatencyUs float64
+
+	// MaxLatencyUs is the slowest call's latency in microseconds
+	MaxLatencyUs float64
+
 	// AvgBatchSize is the average batch size per call
 	AvgBatchSize float64
+
+	// MinBatchSize and MaxBatchSize are the smallest and largest batch sizes
+	// of any call, or 0 if there have been no calls
+	MinBatchSize int
+	MaxBatchSize int
+
+	// FailedCalls is the number of calls that failed after exhausting retries
+	FailedCalls int
+
+	// Timeouts is the number of failed calls that ran out of time
+	Timeouts int
+
+	// Retries is the number of retry attempts made after transient failures
+	Retries int
+
+	// TunedBatchSize is the batch size chosen by autotuning, or 0 if none was chosen
+	TunedBatchSize int
 }
 
 // Agent defines the interface for all game-playing agents
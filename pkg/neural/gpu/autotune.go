@@ -0,0 +1,77 @@
+package gpu
+
+import (
+	"context"
+	"math/rand"
+	"sync/atomic"
+	"time"
+)
+
+// autotuneRounds is the number of timed requests sent for each candidate batch size
+const autotuneRounds = 5
+
+// AutotuneBatchSize measures the service's throughput at batch sizes from 1
+// up to maxBatch, doubling each time and always including maxBatch itself, and
+// keeps the size that evaluates the most positions per second. Later
+// BatchPredict and BatchForward calls with more inputs than that are split
+// into requests of the chosen size. Tuning requests are not counted in
+// GetStats, which reports the chosen size as TunedBatchSize.
+//
+// It returns the chosen size, or 0 if no size could be measured, in which case
+// batches are sent whole as before.
+func (n *RPSGPUPolicyNetwork) AutotuneBatchSize(maxBatch int) int {
+	var candidates []int
+	for size := 1; size < maxBatch; size *= 2 {
+		candidates = append(candidates, size)
+	}
+	if maxBatch >= 1 {
+		candidates = append(candidates, maxBatch)
+	}
+
+	best, bestThroughput := 0, 0.0
+	for _, size := range candidates {
+		throughput, ok := n.measureThroughput(size)
+		// Prefer the smaller size on ties: it fills sooner during search
+		if ok && throughput > bestThroughput {
+			best, bestThroughput = size, throughput
+		}
+	}
+
+	if best > 0 {
+		atomic.StoreInt64(&n.tunedBatchSize, int64(best))
+	}
+	return best
+}
+
+// BatchSize returns the batch size chosen by AutotuneBatchSize, or 0 if the
+// network has not been tuned. Batched searches can use it to decide how many
+// leaves to collect per request.
+func (n *RPSGPUPolicyNetwork) BatchSize() int {
+	return int(atomic.LoadInt64(&n.tunedBatchSize))
+}
+
+// measureThroughput returns the positions per second evaluated in batches of
+// size, or false if a request failed
+func (n *RPSGPUPolicyNetwork) measureThroughput(size int) (float64, bool) {
+	inputs := make([][]float64, size)
+	for i := range inputs {
+		inputs[i] = make([]float64, n.InputSize)
+		for j := range inputs[i] {
+			inputs[i][j] = rand.Float64()
+		}
+	}
+
+	// The first request warms up the connection and the service's model
+	var stats callStats
+	if _, err := n.sendBatch(context.Background(), inputs, &stats); err != nil {
+		return 0, false
+	}
+
+	start := time.Now()
+	for i := 0; i < autotuneRounds; i++ {
+		if _, err := n.sendBatch(context.Background(), inputs, &stats); err != nil {
+			return 0, false
+		}
+	}
+	return float64(size*autotuneRounds) / time.Since(start).Seconds(), true
+}
@@ -5,69 +5,78 @@ import (
 	"fmt"
 	"time"
 
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
-
 	"github.com/zachbeta/neural_rps/pkg/common"
 	pb "github.com/zachbeta/neural_rps/pkg/neural/proto"
 )
 
 // RPSGPUPolicyNetwork is a policy network that uses the gRPC service for GPU-accelerated inference
 type RPSGPUPolicyNetwork struct {
-	conn   *grpc.ClientConn
-	client pb.NeuralServiceClient
+	pool   *connPool
+	config ClientConfig
 
 	// Network dimensions
 	InputSize  int
 	HiddenSize int
 	OutputSize int
 
+	// tunedBatchSize is the request size chosen by AutotuneBatchSize, or 0
+	// to send every batch as one request. Accessed atomically.
+	tunedBatchSize int64
+
 	// Performance metrics
-	totalTime      time.Duration
-	totalCalls     int
-	totalBatchSize int
+	stats callStats
 }
 
 // NewRPSGPUPolicyNetwork creates a new policy network client that uses GPU acceleration
 func NewRPSGPUPolicyNetwork(addr string) (*RPSGPUPolicyNetwork, error) {
-	// Set up a connection to the server
-	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	return NewRPSGPUPolicyNetworkWithConfig(addr, DefaultClientConfig())
+}
+
+// NewRPSGPUPolicyNetworkWithConfig creates a policy network client with custom pooling, timeout and retry
+// settings. Fields of config left at zero take their default values.
+func NewRPSGPUPolicyNetworkWithConfig(addr string, config ClientConfig) (*RPSGPUPolicyNetwork, error) {
+	config = config.withDefaults()
+
+	// Set up the connection pool
+	pool, err := newConnPool(addr, config.PoolSize, config.DialOptions)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to neural service: %v", err)
+		return nil, err
 	}
 
-	client := pb.NewNeuralServiceClient(conn)
+	n := &RPSGPUPolicyNetwork{
+		pool:   pool,
+		config: config,
+	}
 
 	// Get model info
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	info, err := client.GetModelInfo(ctx, &pb.ModelInfoRequest{
-		ModelType: "policy",
+	var info *pb.ModelInfoResponse
+	err = callWithRetry(context.Background(), pool, config, config.CallTimeout, &callStats{}, func(ctx context.Context, client pb.NeuralServiceClient) error {
+		var err error
+		info, err = client.GetModelInfo(ctx, &pb.ModelInfoRequest{
+			ModelType: "policy",
+		})
+		return err
 	})
-
 	if err != nil {
-		conn.Close()
+		pool.Close()
 		return nil, fmt.Errorf("failed to get model info: %v", err)
 	}
 
-	return &RPSGPUPolicyNetwork{
-		conn:           conn,
-		client:         client,
-		InputSize:      int(info.InputSize),
-		HiddenSize:     int(info.HiddenSize),
-		OutputSize:     int(info.OutputSize),
-		totalTime:      0,
-		totalCalls:     0,
-		totalBatchSize: 0,
-	}, nil
+	n.InputSize = int(info.InputSize)
+	n.HiddenSize = int(info.HiddenSize)
+	n.OutputSize = int(info.OutputSize)
+	return n, nil
 }
 
 // Forward runs a forward pass through the policy network
 func (n *RPSGPUPolicyNetwork) Forward(input []float64) ([]float64, error) {
+	return n.ForwardContext(context.Background(), input)
+}
+
+// ForwardContext is like Forward but gives up, retries included, once ctx is done
+func (n *RPSGPUPolicyNetwork) ForwardContext(ctx context.Context, input []float64) ([]float64, error) {
 	start := time.Now()
-	n.totalCalls++
-	n.totalBatchSize++
+	n.stats.addCall(1)
 
 	// Convert float64 to float32 for gRPC
 	features := make([]float32, len(input))
@@ -81,12 +90,13 @@ func (n *RPSGPUPolicyNetwork) Forward(input []float64) ([]float64, error) {
 		ModelType: "policy",
 	}
 
-	// Set timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	// Make the gRPC call
-	resp, err := n.client.Predict(ctx, req)
+	// Make the gRPC call, retrying transient failures
+	var resp *pb.PredictResponse
+	err := callWithRetry(ctx, n.pool, n.config, n.config.CallTimeout, &n.stats, func(ctx context.Context, client pb.NeuralServiceClient) error {
+		var err error
+		resp, err = client.Predict(ctx, req)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("prediction failed: %v", err)
 	}
@@ -97,7 +107,7 @@ func (n *RPSGPUPolicyNetwork) Forward(input []float64) ([]float64, error) {
 		output[i] = float64(v)
 	}
 
-	n.totalTime += time.Since(start)
+	n.stats.addTime(time.Since(start))
 
 	return output, nil
 }
@@ -105,8 +115,7 @@ func (n *RPSGPUPolicyNetwork) Forward(input []float64) ([]float64, error) {
 // Predict returns the best move based on the input
 func (n *RPSGPUPolicyNetwork) Predict(input []float64) (int, error) {
 	start := time.Now()
-	n.totalCalls++
-	n.totalBatchSize++
+	n.stats.addCall(1)
 
 	// Convert float64 to float32 for gRPC
 	features := make([]float32, len(input))
@@ -120,79 +129,110 @@ func (n *RPSGPUPolicyNetwork) Predict(input []float64) (int, error) {
 		ModelType: "policy",
 	}
 
-	// Set timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	// Make the gRPC call
-	resp, err := n.client.Predict(ctx, req)
+	// Make the gRPC call, retrying transient failures
+	var resp *pb.PredictResponse
+	err := callWithRetry(context.Background(), n.pool, n.config, n.config.CallTimeout, &n.stats, func(ctx context.Context, client pb.NeuralServiceClient) error {
+		var err error
+		resp, err = client.Predict(ctx, req)
+		return err
+	})
 	if err != nil {
 		return -1, fmt.Errorf("prediction failed: %v", err)
 	}
 
-	n.totalTime += time.Since(start)
+	n.stats.addTime(time.Since(start))
 
 	return int(resp.BestMove), nil
 }
 
 // BatchPredict performs inference on multiple inputs at once
 func (n *RPSGPUPolicyNetwork) BatchPredict(inputs [][]float64) ([]int, error) {
+	return n.BatchPredictContext(context.Background(), inputs)
+}
+
+// BatchPredictContext is like BatchPredict but gives up once ctx is done. A
+// deadline on ctx bounds the whole call, retries and split requests included,
+// so a caller such as a batched search can fall back to the CPU instead of
+// waiting on a stalled service.
+func (n *RPSGPUPolicyNetwork) BatchPredictContext(ctx context.Context, inputs [][]float64) ([]int, error) {
 	if len(inputs) == 0 {
 		return []int{}, nil
 	}
 
-	start := time.Now()
-	n.totalCalls++
-	n.totalBatchSize += len(inputs)
-
-	// Create batch request
-	req := &pb.BatchPredictRequest{
-		ModelType: "policy",
-		Inputs:    make([]*pb.InputFeatures, len(inputs)),
-	}
-
-	// Convert all inputs to float32
-	for i, input := range inputs {
-		features := make([]float32, len(input))
-		for j, v := range input {
-			features[j] = float32(v)
-		}
-		req.Inputs[i] = &pb.InputFeatures{
-			Features: features,
-		}
-	}
-
-	// Set timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	// Make the gRPC call
-	resp, err := n.client.BatchPredict(ctx, req)
+	outputs, err := n.batchOutputs(ctx, inputs)
 	if err != nil {
 		return nil, fmt.Errorf("batch prediction failed: %v", err)
 	}
 
 	// Extract predictions
-	predictions := make([]int, len(resp.Outputs))
-	for i, output := range resp.Outputs {
+	predictions := make([]int, len(outputs))
+	for i, output := range outputs {
 		predictions[i] = int(output.BestMove)
 	}
 
-	n.totalTime += time.Since(start)
-
 	return predictions, nil
 }
 
 // BatchForward performs forward passes on multiple inputs at once
 func (n *RPSGPUPolicyNetwork) BatchForward(inputs [][]float64) ([][]float64, error) {
+	return n.BatchForwardContext(context.Background(), inputs)
+}
+
+// BatchForwardContext is like BatchForward but gives up once ctx is done; see
+// BatchPredictContext
+func (n *RPSGPUPolicyNetwork) BatchForwardContext(ctx context.Context, inputs [][]float64) ([][]float64, error) {
 	if len(inputs) == 0 {
 		return [][]float64{}, nil
 	}
 
-	start := time.Now()
-	n.totalCalls++
-	n.totalBatchSize += len(inputs)
+	outputs, err := n.batchOutputs(ctx, inputs)
+	if err != nil {
+		return nil, fmt.Errorf("batch prediction failed: %v", err)
+	}
 
+	// Extract probabilities
+	results := make([][]float64, len(outputs))
+	for i, output := range outputs {
+		probs := make([]float64, len(output.Probabilities))
+		for j, p := range output.Probabilities {
+			probs[j] = float64(p)
+		}
+		results[i] = probs
+	}
+
+	return results, nil
+}
+
+// batchOutputs evaluates inputs on the service, split into requests of the
+// tuned batch size if AutotuneBatchSize has chosen one
+func (n *RPSGPUPolicyNetwork) batchOutputs(ctx context.Context, inputs [][]float64) ([]*pb.PredictResponse, error) {
+	size := n.BatchSize()
+	if size <= 0 {
+		size = len(inputs)
+	}
+
+	outputs := make([]*pb.PredictResponse, 0, len(inputs))
+	for start := 0; start < len(inputs); start += size {
+		end := start + size
+		if end > len(inputs) {
+			end = len(inputs)
+		}
+
+		begin := time.Now()
+		n.stats.addCall(end - start)
+		resp, err := n.sendBatch(ctx, inputs[start:end], &n.stats)
+		if err != nil {
+			return nil, err
+		}
+		n.stats.addTime(time.Since(begin))
+
+		outputs = append(outputs, resp.Outputs...)
+	}
+	return outputs, nil
+}
+
+// sendBatch makes one batch request for inputs, retrying transient failures
+func (n *RPSGPUPolicyNetwork) sendBatch(ctx context.Context, inputs [][]float64, stats *callStats) (*pb.BatchPredictResponse, error) {
 	// Create batch request
 	req := &pb.BatchPredictRequest{
 		ModelType: "policy",
@@ -210,63 +250,32 @@ func (n *RPSGPUPolicyNetwork) BatchForward(inputs [][]float64) ([][]float64, err
 		}
 	}
 
-	// Set timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	// Make the gRPC call
-	resp, err := n.client.BatchPredict(ctx, req)
-	if err != nil {
-		return nil, fmt.Errorf("batch prediction failed: %v", err)
-	}
-
-	// Extract probabilities
-	outputs := make([][]float64, len(resp.Outputs))
-	for i, output := range resp.Outputs {
-		probs := make([]float64, len(output.Probabilities))
-		for j, p := range output.Probabilities {
-			probs[j] = float64(p)
-		}
-		outputs[i] = probs
-	}
-
-	n.totalTime += time.Since(start)
-
-	return outputs, nil
+	// Make the gRPC call, retrying transient failures
+	var resp *pb.BatchPredictResponse
+	err := callWithRetry(ctx, n.pool, n.config, n.config.BatchTimeout, stats, func(ctx context.Context, client pb.NeuralServiceClient) error {
+		var err error
+		resp, err = client.BatchPredict(ctx, req)
+		return err
+	})
+	return resp, err
 }
 
-// Close closes the gRPC connection
+// Close closes the pooled gRPC connections
 func (n *RPSGPUPolicyNetwork) Close() error {
-	if n.conn != nil {
-		return n.conn.Close()
-	}
-	return nil
+	return n.pool.Close()
 }
 
 // GetStats returns performance statistics
 func (n *RPSGPUPolicyNetwork) GetStats() common.NetworkStats {
-	var avgLatency float64
-	if n.totalCalls > 0 {
-		avgLatency = float64(n.totalTime.Microseconds()) / float64(n.totalCalls)
-	}
-
-	var avgBatchSize float64
-	if n.totalCalls > 0 {
-		avgBatchSize = float64(n.totalBatchSize) / float64(n.totalCalls)
-	}
-
-	return common.NetworkStats{
-		TotalCalls:     n.totalCalls,
-		TotalBatchSize: n.totalBatchSize,
-		AvgLatencyUs:   avgLatency,
-		AvgBatchSize:   avgBatchSize,
-	}
+	stats := n.stats.snapshot()
+	stats.TunedBatchSize = n.BatchSize()
+	return stats
 }
 
 // RPSGPUValueNetwork is a value network that uses the gRPC service for GPU-accelerated inference
 type RPSGPUValueNetwork struct {
-	conn   *grpc.ClientConn
-	client pb.NeuralServiceClient
+	pool   *connPool
+	config ClientConfig
 
 	// Network dimensions
 	InputSize  int
@@ -274,51 +283,54 @@ type RPSGPUValueNetwork struct {
 	OutputSize int
 
 	// Performance metrics
-	totalTime      time.Duration
-	totalCalls     int
-	totalBatchSize int
+	stats callStats
 }
 
 // NewRPSGPUValueNetwork creates a new value network client that uses GPU acceleration
 func NewRPSGPUValueNetwork(addr string) (*RPSGPUValueNetwork, error) {
-	// Set up a connection to the server
-	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	return NewRPSGPUValueNetworkWithConfig(addr, DefaultClientConfig())
+}
+
+// NewRPSGPUValueNetworkWithConfig creates a value network client with custom pooling, timeout and retry
+// settings. Fields of config left at zero take their default values.
+func NewRPSGPUValueNetworkWithConfig(addr string, config ClientConfig) (*RPSGPUValueNetwork, error) {
+	config = config.withDefaults()
+
+	// Set up the connection pool
+	pool, err := newConnPool(addr, config.PoolSize, config.DialOptions)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to neural service: %v", err)
+		return nil, err
 	}
 
-	client := pb.NewNeuralServiceClient(conn)
+	n := &RPSGPUValueNetwork{
+		pool:   pool,
+		config: config,
+	}
 
 	// Get model info
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	info, err := client.GetModelInfo(ctx, &pb.ModelInfoRequest{
-		ModelType: "value",
+	var info *pb.ModelInfoResponse
+	err = callWithRetry(context.Background(), pool, config, config.CallTimeout, &callStats{}, func(ctx context.Context, client pb.NeuralServiceClient) error {
+		var err error
+		info, err = client.GetModelInfo(ctx, &pb.ModelInfoRequest{
+			ModelType: "value",
+		})
+		return err
 	})
-
 	if err != nil {
-		conn.Close()
+		pool.Close()
 		return nil, fmt.Errorf("failed to get model info: %v", err)
 	}
 
-	return &RPSGPUValueNetwork{
-		conn:           conn,
-		client:         client,
-		InputSize:      int(info.InputSize),
-		HiddenSize:     int(info.HiddenSize),
-		OutputSize:     int(info.OutputSize),
-		totalTime:      0,
-		totalCalls:     0,
-		totalBatchSize: 0,
-	}, nil
+	n.InputSize = int(info.InputSize)
+	n.HiddenSize = int(info.HiddenSize)
+	n.OutputSize = int(info.OutputSize)
+	return n, nil
 }
 
 // Evaluate returns a value estimation for the given input
 func (n *RPSGPUValueNetwork) Evaluate(input []float64) (float64, error) {
 	start := time.Now()
-	n.totalCalls++
-	n.totalBatchSize++
+	n.stats.addCall(1)
 
 	// Convert float64 to float32 for gRPC
 	features := make([]float32, len(input))
@@ -332,30 +344,36 @@ func (n *RPSGPUValueNetwork) Evaluate(input []float64) (float64, error) {
 		ModelType: "value",
 	}
 
-	// Set timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	// Make the gRPC call
-	resp, err := n.client.Predict(ctx, req)
+	// Make the gRPC call, retrying transient failures
+	var resp *pb.PredictResponse
+	err := callWithRetry(context.Background(), n.pool, n.config, n.config.CallTimeout, &n.stats, func(ctx context.Context, client pb.NeuralServiceClient) error {
+		var err error
+		resp, err = client.Predict(ctx, req)
+		return err
+	})
 	if err != nil {
 		return 0, fmt.Errorf("evaluation failed: %v", err)
 	}
 
-	n.totalTime += time.Since(start)
+	n.stats.addTime(time.Since(start))
 
 	return float64(resp.Value), nil
 }
 
 // BatchEvaluate performs evaluations on multiple inputs at once
 func (n *RPSGPUValueNetwork) BatchEvaluate(inputs [][]float64) ([]float64, error) {
+	return n.BatchEvaluateContext(context.Background(), inputs)
+}
+
+// BatchEvaluateContext is like BatchEvaluate but gives up, retries included,
+// once ctx is done
+func (n *RPSGPUValueNetwork) BatchEvaluateContext(ctx context.Context, inputs [][]float64) ([]float64, error) {
 	if len(inputs) == 0 {
 		return []float64{}, nil
 	}
 
 	start := time.Now()
-	n.totalCalls++
-	n.totalBatchSize += len(inputs)
+	n.stats.addCall(len(inputs))
 
 	// Create batch request
 	req := &pb.BatchPredictRequest{
@@ -374,12 +392,13 @@ func (n *RPSGPUValueNetwork) BatchEvaluate(inputs [][]float64) ([]float64, error
 		}
 	}
 
-	// Set timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	// Make the gRPC call
-	resp, err := n.client.BatchPredict(ctx, req)
+	// Make the gRPC call, retrying transient failures
+	var resp *pb.BatchPredictResponse
+	err := callWithRetry(ctx, n.pool, n.config, n.config.BatchTimeout, &n.stats, func(ctx context.Context, client pb.NeuralServiceClient) error {
+		var err error
+		resp, err = client.BatchPredict(ctx, req)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("batch evaluation failed: %v", err)
 	}
@@ -390,35 +409,17 @@ func (n *RPSGPUValueNetwork) BatchEvaluate(inputs [][]float64) ([]float64, error
 		values[i] = float64(output.Value)
 	}
 
-	n.totalTime += time.Since(start)
+	n.stats.addTime(time.Since(start))
 
 	return values, nil
 }
 
-// Close closes the gRPC connection
+// Close closes the pooled gRPC connections
 func (n *RPSGPUValueNetwork) Close() error {
-	if n.conn != nil {
-		return n.conn.Close()
-	}
-	return nil
+	return n.pool.Close()
 }
 
 // GetStats returns performance statistics
 func (n *RPSGPUValueNetwork) GetStats() common.NetworkStats {
-	var avgLatency float64
-	if n.totalCalls > 0 {
-		avgLatency = float64(n.totalTime.Microseconds()) / float64(n.totalCalls)
-	}
-
-	var avgBatchSize float64
-	if n.totalCalls > 0 {
-		avgBatchSize = float64(n.totalBatchSize) / float64(n.totalCalls)
-	}
-
-	return common.NetworkStats{
-		TotalCalls:     n.totalCalls,
-		TotalBatchSize: n.totalBatchSize,
-		AvgLatencyUs:   avgLatency,
-		AvgBatchSize:   avgBatchSize,
-	}
+	return n.stats.snapshot()
 }
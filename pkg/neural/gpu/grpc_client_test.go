@@ -0,0 +1,325 @@
+package gpu
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	pb "github.com/zachbeta/neural_rps/pkg/neural/proto"
+)
+
+const fakeInputSize = 4
+
+// fakeService is a NeuralService whose Predict and BatchPredict calls fail
+// with the queued status codes before succeeding
+type fakeService struct {
+	pb.UnimplementedNeuralServiceServer
+
+	// delay is added to every BatchPredict call, so that larger batches have
+	// higher throughput
+	delay time.Duration
+
+	mu         sync.Mutex
+	failures   []codes.Code
+	calls      int
+	batchSizes []int
+}
+
+// fail queues status codes for the next calls to return
+func (s *fakeService) fail(failures ...codes.Code) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, failures...)
+}
+
+// seen returns the number of calls so far and their batch sizes
+func (s *fakeService) seen() (int, []int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls, append([]int(nil), s.batchSizes...)
+}
+
+// attempt counts a call and returns the queued failure, if any
+func (s *fakeService) attempt(batchSize int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	s.batchSizes = append(s.batchSizes, batchSize)
+	if len(s.failures) == 0 {
+		return nil
+	}
+	code := s.failures[0]
+	s.failures = s.failures[1:]
+	return status.Error(code, "injected failure")
+}
+
+// reset forgets the calls seen so far
+func (s *fakeService) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = 0
+	s.batchSizes = nil
+}
+
+func (s *fakeService) Predict(ctx context.Context, req *pb.PredictRequest) (*pb.PredictResponse, error) {
+	if err := s.attempt(1); err != nil {
+		return nil, err
+	}
+	return fakeOutput(req.Features), nil
+}
+
+func (s *fakeService) BatchPredict(ctx context.Context, req *pb.BatchPredictRequest) (*pb.BatchPredictResponse, error) {
+	time.Sleep(s.delay)
+	if err := s.attempt(len(req.Inputs)); err != nil {
+		return nil, err
+	}
+	resp := &pb.BatchPredictResponse{}
+	for _, input := range req.Inputs {
+		resp.Outputs = append(resp.Outputs, fakeOutput(input.Features))
+	}
+	return resp, nil
+}
+
+func (s *fakeService) GetModelInfo(ctx context.Context, req *pb.ModelInfoRequest) (*pb.ModelInfoResponse, error) {
+	return &pb.ModelInfoResponse{InputSize: fakeInputSize, HiddenSize: 8, OutputSize: 9}, nil
+}
+
+// fakeOutput puts all probability on the square given by the first feature
+func fakeOutput(features []float32) *pb.PredictResponse {
+	best := int(features[0])
+	probs := make([]float32, 9)
+	probs[best] = 1
+	return &pb.PredictResponse{Probabilities: probs, BestMove: int32(best), Value: features[0]}
+}
+
+// startFakeService serves service over an in-memory listener and returns a
+// config that dials it. Only DialOptions is set; the rest take their defaults.
+func startFakeService(t *testing.T, service *fakeService) ClientConfig {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	pb.RegisterNeuralServiceServer(server, service)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	return ClientConfig{
+		DialOptions: []grpc.DialOption{
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return lis.DialContext(ctx)
+			}),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		},
+	}
+}
+
+// newFakePolicyNetwork connects a policy client to a fake service, with
+// backoff short enough for tests
+func newFakePolicyNetwork(t *testing.T, service *fakeService) *RPSGPUPolicyNetwork {
+	t.Helper()
+	config := startFakeService(t, service)
+	config.InitialBackoff = time.Millisecond
+	config.MaxBackoff = 2 * time.Millisecond
+	network, err := NewRPSGPUPolicyNetworkWithConfig("bufnet", config)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	t.Cleanup(func() { network.Close() })
+	return network
+}
+
+func input(square int) []float64 {
+	features := make([]float64, fakeInputSize)
+	features[0] = float64(square)
+	return features
+}
+
+func TestConfigDefaultsFillZeroFields(t *testing.T) {
+	// Only DialOptions is set; a zero CallTimeout used to fail every call
+	config := startFakeService(t, &fakeService{})
+	network, err := NewRPSGPUValueNetworkWithConfig("bufnet", config)
+	if err != nil {
+		t.Fatalf("Failed to connect with a partial config: %v", err)
+	}
+	defer network.Close()
+
+	if network.InputSize != fakeInputSize {
+		t.Errorf("Expected input size %d, got %d", fakeInputSize, network.InputSize)
+	}
+	if value, err := network.Evaluate(input(3)); err != nil || value != 3 {
+		t.Errorf("Evaluate returned %v, %v; want 3", value, err)
+	}
+
+	defaults := DefaultClientConfig()
+	if network.config.CallTimeout != defaults.CallTimeout || network.config.MaxRetries != defaults.MaxRetries {
+		t.Errorf("Expected default timeout and retries, got %+v", network.config)
+	}
+	if disabled := (ClientConfig{MaxRetries: -1}).withDefaults(); disabled.MaxRetries != 0 {
+		t.Errorf("Expected a negative MaxRetries to disable retries, got %d", disabled.MaxRetries)
+	}
+}
+
+func TestRetryOnUnavailable(t *testing.T) {
+	service := &fakeService{}
+	network := newFakePolicyNetwork(t, service)
+	service.fail(codes.Unavailable, codes.Unavailable)
+
+	probs, err := network.Forward(input(5))
+	if err != nil {
+		t.Fatalf("Expected the call to succeed after retrying, got %v", err)
+	}
+	if probs[5] != 1 {
+		t.Errorf("Expected all probability on square 5, got %v", probs)
+	}
+	if calls, _ := service.seen(); calls != 3 {
+		t.Errorf("Expected 3 attempts, the service saw %d", calls)
+	}
+
+	stats := network.GetStats()
+	if stats.TotalCalls != 1 || stats.Retries != 2 || stats.FailedCalls != 0 {
+		t.Errorf("Expected 1 call, 2 retries and no failures, got %d, %d and %d",
+			stats.TotalCalls, stats.Retries, stats.FailedCalls)
+	}
+}
+
+func TestNoRetryOnInvalidArgument(t *testing.T) {
+	service := &fakeService{}
+	network := newFakePolicyNetwork(t, service)
+	service.fail(codes.InvalidArgument)
+
+	_, err := network.Predict(input(1))
+	if err == nil {
+		t.Fatal("Expected the call to fail")
+	}
+	if calls, _ := service.seen(); calls != 1 {
+		t.Errorf("Expected InvalidArgument not to be retried, the service saw %d attempts", calls)
+	}
+
+	stats := network.GetStats()
+	if stats.Retries != 0 || stats.FailedCalls != 1 {
+		t.Errorf("Expected no retries and 1 failure, got %d and %d", stats.Retries, stats.FailedCalls)
+	}
+}
+
+func TestRetriesExhausted(t *testing.T) {
+	service := &fakeService{}
+	network := newFakePolicyNetwork(t, service)
+	maxRetries := network.config.MaxRetries
+	for i := 0; i <= maxRetries; i++ {
+		service.fail(codes.Unavailable)
+	}
+
+	if _, err := network.Forward(input(2)); err == nil {
+		t.Fatal("Expected the call to fail once retries ran out")
+	}
+	if calls, _ := service.seen(); calls != maxRetries+1 {
+		t.Errorf("Expected %d attempts, the service saw %d", maxRetries+1, calls)
+	}
+
+	// The next call succeeds on the same connections
+	if _, err := network.Forward(input(2)); err != nil {
+		t.Fatalf("Expected the service to be usable again, got %v", err)
+	}
+
+	stats := network.GetStats()
+	if stats.TotalCalls != 2 || stats.Retries != maxRetries || stats.FailedCalls != 1 {
+		t.Errorf("Expected 2 calls, %d retries and 1 failure, got %d calls, %d retries and %d failures",
+			maxRetries, stats.TotalCalls, stats.Retries, stats.FailedCalls)
+	}
+}
+
+func TestRetryBackoffIsCapped(t *testing.T) {
+	cfg := ClientConfig{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	expected := []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+		800 * time.Millisecond,
+		time.Second,
+		time.Second,
+	}
+	for i, want := range expected {
+		if got := retryBackoff(cfg, i+1); got != want {
+			t.Errorf("Retry %d: expected backoff %v, got %v", i+1, want, got)
+		}
+	}
+
+	// Doubling stops at the cap, so a long run of retries cannot overflow
+	if got := retryBackoff(cfg, 100); got != time.Second {
+		t.Errorf("Retry 100: expected backoff %v, got %v", time.Second, got)
+	}
+}
+
+func TestBatchSplitAfterAutotune(t *testing.T) {
+	// A fixed cost per request makes the largest batch the fastest
+	service := &fakeService{delay: time.Millisecond}
+	network := newFakePolicyNetwork(t, service)
+
+	if size := network.AutotuneBatchSize(4); size != 4 {
+		t.Fatalf("Expected autotuning to choose 4, got %d", size)
+	}
+	service.reset()
+
+	inputs := make([][]float64, 10)
+	for i := range inputs {
+		inputs[i] = input(i % 9)
+	}
+	outputs, err := network.BatchForward(inputs)
+	if err != nil {
+		t.Fatalf("BatchForward failed: %v", err)
+	}
+	for i, probs := range outputs {
+		if probs[i%9] != 1 {
+			t.Errorf("Output %d is out of order: %v", i, probs)
+		}
+	}
+
+	_, sizes := service.seen()
+	if len(sizes) != 3 || sizes[0] != 4 || sizes[1] != 4 || sizes[2] != 2 {
+		t.Errorf("Expected requests of 4, 4 and 2 inputs, got %v", sizes)
+	}
+
+	// Tuning requests are not counted
+	stats := network.GetStats()
+	if stats.TunedBatchSize != 4 || stats.TotalCalls != 3 || stats.TotalBatchSize != 10 ||
+		stats.MinBatchSize != 2 || stats.MaxBatchSize != 4 {
+		t.Errorf("Expected 3 calls of 2 to 4 inputs, 10 in all, tuned to 4; got %+v", stats)
+	}
+}
+
+func TestAverageLatencyIgnoresFailedCalls(t *testing.T) {
+	// Every request takes at least the delay, so every successful call does
+	const delay = 2 * time.Millisecond
+	service := &fakeService{delay: delay}
+	network := newFakePolicyNetwork(t, service)
+	inputs := [][]float64{input(1), input(2)}
+
+	for i := 0; i < 3; i++ {
+		if i == 1 {
+			service.fail(codes.InvalidArgument)
+			if _, err := network.BatchForward(inputs); err == nil {
+				t.Fatal("Expected the injected failure")
+			}
+		}
+		if _, err := network.BatchForward(inputs); err != nil {
+			t.Fatalf("BatchForward failed: %v", err)
+		}
+	}
+
+	stats := network.GetStats()
+	if stats.TotalCalls != 4 || stats.FailedCalls != 1 {
+		t.Fatalf("Expected 4 calls with 1 failure, got %d with %d", stats.TotalCalls, stats.FailedCalls)
+	}
+	// Counting the untimed failure would bring the average below the delay
+	if min := microseconds(delay); stats.AvgLatencyUs < min || stats.AvgLatencyUs > stats.MaxLatencyUs {
+		t.Errorf("Expected an average latency between %vµs and the max of %vµs, got %vµs",
+			min, stats.MaxLatencyUs, stats.AvgLatencyUs)
+	}
+}
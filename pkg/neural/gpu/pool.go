@@ -0,0 +1,302 @@
+package gpu
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/zachbeta/neural_rps/pkg/common"
+	pb "github.com/zachbeta/neural_rps/pkg/neural/proto"
+)
+
+// ClientConfig configures the gRPC connections used by the GPU network clients.
+// Fields left at zero take their values from DefaultClientConfig.
+type ClientConfig struct {
+	// PoolSize is the number of connections requests are spread across
+	PoolSize int
+
+	// CallTimeout bounds a single-input request
+	CallTimeout time.Duration
+
+	// BatchTimeout bounds a batch request
+	BatchTimeout time.Duration
+
+	// MaxRetries is the number of times a transient failure is retried. Set it
+	// negative to disable retries.
+	MaxRetries int
+
+	// InitialBackoff is the delay before the first retry; it doubles up to MaxBackoff
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+
+	// DialOptions are passed to grpc.Dial for every pooled connection.
+	// Insecure transport credentials are used when empty.
+	DialOptions []grpc.DialOption
+}
+
+// DefaultClientConfig returns the configuration used by NewRPSGPUPolicyNetwork
+func DefaultClientConfig() ClientConfig {
+	return ClientConfig{
+		PoolSize:       4,
+		CallTimeout:    5 * time.Second,
+		BatchTimeout:   10 * time.Second,
+		MaxRetries:     3,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     2 * time.Second,
+	}
+}
+
+// withDefaults returns the config with every zero field replaced by its value
+// in DefaultClientConfig. A zero timeout would otherwise fail every call.
+func (c ClientConfig) withDefaults() ClientConfig {
+	defaults := DefaultClientConfig()
+	if c.PoolSize <= 0 {
+		c.PoolSize = defaults.PoolSize
+	}
+	if c.CallTimeout <= 0 {
+		c.CallTimeout = defaults.CallTimeout
+	}
+	if c.BatchTimeout <= 0 {
+		c.BatchTimeout = defaults.BatchTimeout
+	}
+	if c.MaxRetries == 0 {
+		c.MaxRetries = defaults.MaxRetries
+	} else if c.MaxRetries < 0 {
+		c.MaxRetries = 0
+	}
+	if c.InitialBackoff <= 0 {
+		c.InitialBackoff = defaults.InitialBackoff
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = defaults.MaxBackoff
+	}
+	return c
+}
+
+// connPool round-robins requests over a fixed set of connections. A
+// grpc.ClientConn reconnects by itself, with backoff, after the service goes
+// away, so the pool never replaces one: closing a connection would cancel the
+// calls other goroutines have in flight on it.
+type connPool struct {
+	mu      sync.Mutex
+	conns   []*grpc.ClientConn
+	clients []pb.NeuralServiceClient
+	next    uint64
+}
+
+// newConnPool dials size connections to addr
+func newConnPool(addr string, size int, opts []grpc.DialOption) (*connPool, error) {
+	if size < 1 {
+		size = 1
+	}
+	if len(opts) == 0 {
+		opts = []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	}
+
+	pool := &connPool{
+		conns:   make([]*grpc.ClientConn, size),
+		clients: make([]pb.NeuralServiceClient, size),
+	}
+	for i := 0; i < size; i++ {
+		conn, err := grpc.Dial(addr, opts...)
+		if err != nil {
+			pool.Close()
+			return nil, fmt.Errorf("failed to connect to neural service: %v", err)
+		}
+		pool.conns[i] = conn
+		pool.clients[i] = pb.NewNeuralServiceClient(conn)
+	}
+	return pool, nil
+}
+
+// get returns the next client in round-robin order
+func (p *connPool) get() pb.NeuralServiceClient {
+	idx := atomic.AddUint64(&p.next, 1) % uint64(len(p.clients))
+	return p.clients[idx]
+}
+
+// Close closes every pooled connection
+func (p *connPool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var firstErr error
+	for i, conn := range p.conns {
+		if conn == nil {
+			continue
+		}
+		if err := conn.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		p.conns[i] = nil
+	}
+	return firstErr
+}
+
+// isRetryable reports whether a failed call may succeed if attempted again
+func isRetryable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted:
+		return true
+	}
+	return false
+}
+
+// callWithRetry runs call on a pooled connection, retrying transient failures with
+// exponential backoff. Each retry goes to the next connection in the pool. Each
+// attempt is bounded by timeout, and no attempt is made once ctx is done, so a
+// deadline on ctx bounds the call as a whole, retries included.
+func callWithRetry(ctx context.Context, pool *connPool, cfg ClientConfig, timeout time.Duration, stats *callStats,
+	call func(ctx context.Context, client pb.NeuralServiceClient) error) error {
+	var err error
+	for attempt := 0; attempt <= cfg.MaxRetries; attempt++ {
+		if attempt > 0 {
+			stats.addRetry()
+			select {
+			case <-time.After(retryBackoff(cfg, attempt)):
+			case <-ctx.Done():
+			}
+		}
+		if ctx.Err() != nil {
+			if err == nil {
+				err = ctx.Err()
+			}
+			break
+		}
+
+		client := pool.get()
+		attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+		err = call(attemptCtx, client)
+		cancel()
+
+		if err == nil {
+			return nil
+		}
+		if !isRetryable(err) {
+			break
+		}
+	}
+
+	stats.addFailure()
+	if status.Code(err) == codes.DeadlineExceeded || errors.Is(err, context.DeadlineExceeded) {
+		stats.addTimeout()
+	}
+	return err
+}
+
+// retryBackoff returns the delay before the given retry, counting from 1:
+// InitialBackoff, doubled for each earlier retry and capped at MaxBackoff
+func retryBackoff(cfg ClientConfig, retry int) time.Duration {
+	backoff := cfg.InitialBackoff
+	for i := 1; i < retry && backoff < cfg.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > cfg.MaxBackoff {
+		backoff = cfg.MaxBackoff
+	}
+	return backoff
+}
+
+// callStats accumulates performance metrics shared by concurrent callers
+type callStats struct {
+	mu             sync.Mutex
+	totalTime      time.Duration
+	maxTime        time.Duration
+	latencies      common.LatencyHistogram
+	totalCalls     int
+	totalBatchSize int
+	minBatchSize   int
+	maxBatchSize   int
+	failedCalls    int
+	timeouts       int
+	retries        int
+}
+
+// addCall records the start of a call evaluating batchSize positions
+func (s *callStats) addCall(batchSize int) {
+	s.mu.Lock()
+	if s.totalCalls == 0 || batchSize < s.minBatchSize {
+		s.minBatchSize = batchSize
+	}
+	if batchSize > s.maxBatchSize {
+		s.maxBatchSize = batchSize
+	}
+	s.totalCalls++
+	s.totalBatchSize += batchSize
+	s.mu.Unlock()
+}
+
+// addTime records the latency of a successful call
+func (s *callStats) addTime(elapsed time.Duration) {
+	s.mu.Lock()
+	s.totalTime += elapsed
+	if elapsed > s.maxTime {
+		s.maxTime = elapsed
+	}
+	s.latencies.Record(elapsed)
+	s.mu.Unlock()
+}
+
+func (s *callStats) addRetry() {
+	s.mu.Lock()
+	s.retries++
+	s.mu.Unlock()
+}
+
+func (s *callStats) addFailure() {
+	s.mu.Lock()
+	s.failedCalls++
+	s.mu.Unlock()
+}
+
+func (s *callStats) addTimeout() {
+	s.mu.Lock()
+	s.timeouts++
+	s.mu.Unlock()
+}
+
+// snapshot returns the accumulated metrics as common.NetworkStats
+func (s *callStats) snapshot() common.NetworkStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Latency is only recorded for successful calls, as in the histogram
+	var avgLatency float64
+	if succeeded := s.latencies.Count(); succeeded > 0 {
+		avgLatency = microseconds(s.totalTime) / float64(succeeded)
+	}
+
+	var avgBatchSize float64
+	if s.totalCalls > 0 {
+		avgBatchSize = float64(s.totalBatchSize) / float64(s.totalCalls)
+	}
+
+	return common.NetworkStats{
+		TotalCalls:     s.totalCalls,
+		TotalBatchSize: s.totalBatchSize,
+		AvgLatencyUs:   avgLatency,
+		P50LatencyUs:   microseconds(s.latencies.Quantile(0.50)),
+		P90LatencyUs:   microseconds(s.latencies.Quantile(0.90)),
+		P99LatencyUs:   microseconds(s.latencies.Quantile(0.99)),
+		MaxLatencyUs:   microseconds(s.maxTime),
+		AvgBatchSize:   avgBatchSize,
+		MinBatchSize:   s.minBatchSize,
+		MaxBatchSize:   s.maxBatchSize,
+		FailedCalls:    s.failedCalls,
+		Timeouts:       s.timeouts,
+		Retries:        s.retries,
+	}
+}
+
+// microseconds converts a duration to fractional microseconds
+func microseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Microsecond)
+}
@@ -0,0 +1,219 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	neural "github.com/zachbeta/neural_rps/alphago_demo/pkg/rps_net_impl"
+	"github.com/zachbeta/neural_rps/pkg/common"
+	pb "github.com/zachbeta/neural_rps/pkg/neural/proto"
+)
+
+// Server implements the NeuralService gRPC API on top of the Go policy and value networks.
+// It speaks the same protocol as the Python inference services, so the gpu package clients
+// can be pointed at it unchanged.
+type Server struct {
+	pb.UnimplementedNeuralServiceServer
+
+	policy *neural.RPSPolicyNetwork
+	value  *neural.RPSValueNetwork
+
+	// Performance metrics
+	mu             sync.Mutex
+	totalTime      time.Duration
+	maxTime        time.Duration
+	latencies      common.LatencyHistogram
+	totalCalls     int
+	totalBatchSize int
+	minBatchSize   int
+	maxBatchSize   int
+}
+
+// NewServer creates a server backed by the given networks. Either network may be nil,
+// in which case requests for that model type are rejected.
+func NewServer(policy *neural.RPSPolicyNetwork, value *neural.RPSValueNetwork) *Server {
+	return &Server{
+		policy: policy,
+		value:  value,
+	}
+}
+
+// Predict runs model inference on a single input
+func (s *Server) Predict(ctx context.Context, req *pb.PredictRequest) (*pb.PredictResponse, error) {
+	start := time.Now()
+
+	resp, err := s.predict(req.ModelType, req.Features)
+	if err != nil {
+		return nil, err
+	}
+
+	s.record(time.Since(start), 1)
+	return resp, nil
+}
+
+// BatchPredict runs model inference on multiple inputs
+func (s *Server) BatchPredict(ctx context.Context, req *pb.BatchPredictRequest) (*pb.BatchPredictResponse, error) {
+	start := time.Now()
+
+	outputs := make([]*pb.PredictResponse, len(req.Inputs))
+	for i, input := range req.Inputs {
+		resp, err := s.predict(req.ModelType, input.Features)
+		if err != nil {
+			return nil, err
+		}
+		outputs[i] = resp
+	}
+
+	s.record(time.Since(start), len(req.Inputs))
+	return &pb.BatchPredictResponse{Outputs: outputs}, nil
+}
+
+// GetModelInfo returns the dimensions of the requested network
+func (s *Server) GetModelInfo(ctx context.Context, req *pb.ModelInfoRequest) (*pb.ModelInfoResponse, error) {
+	var stats neural.NetworkStats
+	switch req.ModelType {
+	case "policy":
+		if s.policy == nil {
+			return nil, status.Error(codes.Unavailable, "no policy network loaded")
+		}
+		stats = neural.CalculatePolicyNetworkStats(s.policy)
+	case "value":
+		if s.value == nil {
+			return nil, status.Error(codes.Unavailable, "no value network loaded")
+		}
+		stats = neural.CalculateValueNetworkStats(s.value)
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unknown model type %q", req.ModelType)
+	}
+
+	return &pb.ModelInfoResponse{
+		InputSize:  int32(stats.InputSize),
+		HiddenSize: int32(stats.HiddenSize),
+		OutputSize: int32(stats.OutputSize),
+		Device:     "cpu",
+		Framework:  "go",
+	}, nil
+}
+
+// predict evaluates a single feature vector with the network selected by modelType
+func (s *Server) predict(modelType string, features []float32) (*pb.PredictResponse, error) {
+	input := make([]float64, len(features))
+	for i, v := range features {
+		input[i] = float64(v)
+	}
+
+	switch modelType {
+	case "policy":
+		if s.policy == nil {
+			return nil, status.Error(codes.Unavailable, "no policy network loaded")
+		}
+		if err := checkInputSize(len(input), neural.CalculatePolicyNetworkStats(s.policy).InputSize); err != nil {
+			return nil, err
+		}
+
+		probs := s.policy.PredictFeatures(input)
+		resp := &pb.PredictResponse{
+			Probabilities: make([]float32, len(probs)),
+		}
+		bestMove := 0
+		for i, p := range probs {
+			resp.Probabilities[i] = float32(p)
+			if p > probs[bestMove] {
+				bestMove = i
+			}
+		}
+		resp.BestMove = int32(bestMove)
+		return resp, nil
+
+	case "value":
+		if s.value == nil {
+			return nil, status.Error(codes.Unavailable, "no value network loaded")
+		}
+		if err := checkInputSize(len(input), neural.CalculateValueNetworkStats(s.value).InputSize); err != nil {
+			return nil, err
+		}
+		return &pb.PredictResponse{Value: float32(s.value.PredictFeatures(input))}, nil
+	}
+
+	return nil, status.Errorf(codes.InvalidArgument, "unknown model type %q", modelType)
+}
+
+// checkInputSize rejects feature vectors that do not match the network input layer
+func checkInputSize(got, want int) error {
+	if got != want {
+		return status.Errorf(codes.InvalidArgument, "input size mismatch: expected %d, got %d", want, got)
+	}
+	return nil
+}
+
+// record updates the performance metrics for one call
+func (s *Server) record(elapsed time.Duration, batchSize int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.totalTime += elapsed
+	if elapsed > s.maxTime {
+		s.maxTime = elapsed
+	}
+	s.latencies.Record(elapsed)
+	if s.totalCalls == 0 || batchSize < s.minBatchSize {
+		s.minBatchSize = batchSize
+	}
+	if batchSize > s.maxBatchSize {
+		s.maxBatchSize = batchSize
+	}
+	s.totalCalls++
+	s.totalBatchSize += batchSize
+}
+
+// GetStats returns performance statistics, measured on the server side
+func (s *Server) GetStats() common.NetworkStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var avgLatency float64
+	if s.totalCalls > 0 {
+		avgLatency = float64(s.totalTime.Microseconds()) / float64(s.totalCalls)
+	}
+
+	var avgBatchSize float64
+	if s.totalCalls > 0 {
+		avgBatchSize = float64(s.totalBatchSize) / float64(s.totalCalls)
+	}
+
+	return common.NetworkStats{
+		TotalCalls:     s.totalCalls,
+		TotalBatchSize: s.totalBatchSize,
+		AvgLatencyUs:   avgLatency,
+		P50LatencyUs:   microseconds(s.latencies.Quantile(0.50)),
+		P90LatencyUs:   microseconds(s.latencies.Quantile(0.90)),
+		P99LatencyUs:   microseconds(s.latencies.Quantile(0.99)),
+		MaxLatencyUs:   microseconds(s.maxTime),
+		AvgBatchSize:   avgBatchSize,
+		MinBatchSize:   s.minBatchSize,
+		MaxBatchSize:   s.maxBatchSize,
+	}
+}
+
+// microseconds converts a duration to fractional microseconds
+func microseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Microsecond)
+}
+
+// ListenAndServe registers the server on a new gRPC server and serves on addr until it fails
+func (s *Server) ListenAndServe(addr string) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %v", addr, err)
+	}
+
+	grpcServer := grpc.NewServer()
+	pb.RegisterNeuralServiceServer(grpcServer, s)
+	return grpcServer.Serve(lis)
+}
@@ -0,0 +1,197 @@
+package server
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	neural "github.com/zachbeta/neural_rps/alphago_demo/pkg/rps_net_impl"
+	pb "github.com/zachbeta/neural_rps/pkg/neural/proto"
+)
+
+// startServer serves s over an in-memory listener and returns a client for it
+func startServer(t *testing.T, s *Server) pb.NeuralServiceClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer()
+	pb.RegisterNeuralServiceServer(grpcServer, s)
+	go grpcServer.Serve(lis)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewNeuralServiceClient(conn)
+}
+
+// randomFeatures returns features as sent over the wire, and the same values
+// as the network sees them
+func randomFeatures(rng *rand.Rand, size int) ([]float32, []float64) {
+	wire := make([]float32, size)
+	local := make([]float64, size)
+	for i := range wire {
+		wire[i] = rng.Float32()
+		local[i] = float64(wire[i])
+	}
+	return wire, local
+}
+
+// checkPolicyResponse compares a response against the network's own prediction
+func checkPolicyResponse(t *testing.T, resp *pb.PredictResponse, expected []float64) {
+	t.Helper()
+	if len(resp.Probabilities) != len(expected) {
+		t.Fatalf("Expected %d probabilities, got %d", len(expected), len(resp.Probabilities))
+	}
+	best := 0
+	for i, p := range expected {
+		if math.Abs(float64(resp.Probabilities[i])-p) > 1e-6 {
+			t.Errorf("Probability %d: served %v, network gives %v", i, resp.Probabilities[i], p)
+		}
+		if p > expected[best] {
+			best = i
+		}
+	}
+	if int(resp.BestMove) != best {
+		t.Errorf("Expected best move %d, got %d", best, resp.BestMove)
+	}
+}
+
+func TestServerPredictMatchesNetwork(t *testing.T) {
+	policy := neural.NewRPSPolicyNetworkSeeded(16, 1)
+	value := neural.NewRPSValueNetworkSeeded(16, 2)
+	client := startServer(t, NewServer(policy, value))
+	ctx := context.Background()
+	rng := rand.New(rand.NewSource(1))
+	inputSize := neural.CalculatePolicyNetworkStats(policy).InputSize
+
+	wire, local := randomFeatures(rng, inputSize)
+	resp, err := client.Predict(ctx, &pb.PredictRequest{ModelType: "policy", Features: wire})
+	if err != nil {
+		t.Fatalf("Predict failed: %v", err)
+	}
+	checkPolicyResponse(t, resp, policy.PredictFeatures(local))
+
+	resp, err = client.Predict(ctx, &pb.PredictRequest{ModelType: "value", Features: wire})
+	if err != nil {
+		t.Fatalf("Value Predict failed: %v", err)
+	}
+	if expected := value.PredictFeatures(local); math.Abs(float64(resp.Value)-expected) > 1e-6 {
+		t.Errorf("Served value %v, network gives %v", resp.Value, expected)
+	}
+
+	batch := &pb.BatchPredictRequest{ModelType: "policy"}
+	var expected [][]float64
+	for i := 0; i < 3; i++ {
+		wire, local := randomFeatures(rng, inputSize)
+		batch.Inputs = append(batch.Inputs, &pb.InputFeatures{Features: wire})
+		expected = append(expected, policy.PredictFeatures(local))
+	}
+	batchResp, err := client.BatchPredict(ctx, batch)
+	if err != nil {
+		t.Fatalf("BatchPredict failed: %v", err)
+	}
+	if len(batchResp.Outputs) != len(expected) {
+		t.Fatalf("Expected %d outputs, got %d", len(expected), len(batchResp.Outputs))
+	}
+	for i, output := range batchResp.Outputs {
+		checkPolicyResponse(t, output, expected[i])
+	}
+
+	info, err := client.GetModelInfo(ctx, &pb.ModelInfoRequest{ModelType: "policy"})
+	if err != nil {
+		t.Fatalf("GetModelInfo failed: %v", err)
+	}
+	if int(info.InputSize) != inputSize || info.HiddenSize != 16 || info.OutputSize != 9 {
+		t.Errorf("Expected a %d-16-9 network, got %d-%d-%d", inputSize, info.InputSize, info.HiddenSize, info.OutputSize)
+	}
+}
+
+func TestServerRejectsBadRequests(t *testing.T) {
+	policy := neural.NewRPSPolicyNetworkSeeded(16, 1)
+	client := startServer(t, NewServer(policy, nil))
+	ctx := context.Background()
+	inputSize := neural.CalculatePolicyNetworkStats(policy).InputSize
+
+	tests := []struct {
+		name string
+		call func() error
+		code codes.Code
+	}{
+		{"wrong input size", func() error {
+			_, err := client.Predict(ctx, &pb.PredictRequest{ModelType: "policy", Features: make([]float32, inputSize-1)})
+			return err
+		}, codes.InvalidArgument},
+		{"wrong input size in a batch", func() error {
+			_, err := client.BatchPredict(ctx, &pb.BatchPredictRequest{ModelType: "policy", Inputs: []*pb.InputFeatures{
+				{Features: make([]float32, inputSize)},
+				{Features: make([]float32, inputSize+1)},
+			}})
+			return err
+		}, codes.InvalidArgument},
+		{"unknown model type", func() error {
+			_, err := client.Predict(ctx, &pb.PredictRequest{ModelType: "critic", Features: make([]float32, inputSize)})
+			return err
+		}, codes.InvalidArgument},
+		{"unknown model type info", func() error {
+			_, err := client.GetModelInfo(ctx, &pb.ModelInfoRequest{ModelType: "critic"})
+			return err
+		}, codes.InvalidArgument},
+		{"no value network", func() error {
+			_, err := client.Predict(ctx, &pb.PredictRequest{ModelType: "value", Features: make([]float32, inputSize)})
+			return err
+		}, codes.Unavailable},
+	}
+	for _, test := range tests {
+		if code := status.Code(test.call()); code != test.code {
+			t.Errorf("%s: expected %v, got %v", test.name, test.code, code)
+		}
+	}
+}
+
+func TestServerStats(t *testing.T) {
+	policy := neural.NewRPSPolicyNetworkSeeded(16, 1)
+	s := NewServer(policy, nil)
+	client := startServer(t, s)
+	ctx := context.Background()
+	inputSize := neural.CalculatePolicyNetworkStats(policy).InputSize
+
+	if _, err := client.Predict(ctx, &pb.PredictRequest{ModelType: "policy", Features: make([]float32, inputSize)}); err != nil {
+		t.Fatalf("Predict failed: %v", err)
+	}
+	batch := &pb.BatchPredictRequest{ModelType: "policy"}
+	for i := 0; i < 3; i++ {
+		batch.Inputs = append(batch.Inputs, &pb.InputFeatures{Features: make([]float32, inputSize)})
+	}
+	if _, err := client.BatchPredict(ctx, batch); err != nil {
+		t.Fatalf("BatchPredict failed: %v", err)
+	}
+	// Rejected requests are not counted
+	client.Predict(ctx, &pb.PredictRequest{ModelType: "policy"})
+
+	stats := s.GetStats()
+	if stats.TotalCalls != 2 || stats.TotalBatchSize != 4 {
+		t.Errorf("Expected 2 calls of 4 inputs in all, got %d calls of %d", stats.TotalCalls, stats.TotalBatchSize)
+	}
+	if stats.AvgBatchSize != 2 || stats.MinBatchSize != 1 || stats.MaxBatchSize != 3 {
+		t.Errorf("Expected batch sizes from 1 to 3 averaging 2, got %d to %d averaging %v",
+			stats.MinBatchSize, stats.MaxBatchSize, stats.AvgBatchSize)
+	}
+	if stats.P50LatencyUs <= 0 || stats.MaxLatencyUs < stats.P50LatencyUs {
+		t.Errorf("Expected positive latencies with the max at least the median, got p50 %v, max %v",
+			stats.P50LatencyUs, stats.MaxLatencyUs)
+	}
+}
@@ -1,5 +1,7 @@
 #!/bin/bash
-# Run the benchmark comparing CPU and GPU neural network performance
+# Run the benchmark comparing inference backends on one trained model.
+# Pass --model=<file>; the service must serve an ONNX export of the same model
+# (scripts/convert_go_json_to_onnx.py --input_mode go_json).
 
 set -e
 
@@ -18,7 +20,6 @@ while [[ $# -gt 0 ]]; do
         --addr=*)
             ADDR="${1#*=}"
             PORT=$(echo $ADDR | cut -d':' -f2)
-            ARGS="$ARGS $1"
             shift
             ;;
         --batch-size=*)
@@ -31,14 +32,6 @@ while [[ $# -gt 0 ]]; do
             ARGS="$ARGS $1"
             shift
             ;;
-        --cpu-only)
-            ARGS="$ARGS $1"
-            shift
-            ;;
-        --gpu-only)
-            ARGS="$ARGS $1"
-            shift
-            ;;
         *)
             ARGS="$ARGS $1"
             shift
@@ -83,4 +76,4 @@ fi
 
 # Run the benchmark
 echo "Running benchmark with batch size $BATCH_SIZE and $ITERATIONS iterations..."
-go run cmd/benchmark/main.go --batch-size=$BATCH_SIZE --iterations=$ITERATIONS $ARGS 
\ No newline at end of file
+go run ./cmd/benchmark --gpu-addr=localhost:$PORT --batch-size=$BATCH_SIZE --iterations=$ITERATIONS $ARGS
\ No newline at end of file
@@ -47,12 +47,37 @@ class PyTorchValueNet(nn.Module):
         x = self.tanh(x)
         return x
 
+class GoPolicyNet(nn.Module):
+    """The Go RPSPolicyNetwork: ReLU hidden layer, softmax output."""
+    def __init__(self, input_size, hidden_size, output_size):
+        super().__init__()
+        self.layer1 = nn.Linear(input_size, hidden_size)
+        self.relu = nn.ReLU()
+        self.layer2 = nn.Linear(hidden_size, output_size)
+        self.softmax = nn.Softmax(dim=1)
+
+    def forward(self, x):
+        return self.softmax(self.layer2(self.relu(self.layer1(x))))
+
+class GoValueNet(nn.Module):
+    """The Go RPSValueNetwork: ReLU hidden layer, sigmoid output in [0, 1]."""
+    def __init__(self, input_size, hidden_size):
+        super().__init__()
+        self.layer1 = nn.Linear(input_size, hidden_size)
+        self.relu = nn.ReLU()
+        self.layer2 = nn.Linear(hidden_size, 1)
+        self.sigmoid = nn.Sigmoid()
+
+    def forward(self, x):
+        return self.sigmoid(self.layer2(self.relu(self.layer1(x))))
+
 # --- Conversion Functions ---
 
 def load_go_json_and_convert(json_path, onnx_path, model_type_str):
     """
-    Loads model from Go JSON, loads weights into PyTorch model, exports to ONNX.
-    (Handles the original functionality)
+    Loads a model saved by the Go RPSPolicyNetwork or RPSValueNetwork and
+    exports it to ONNX computing the same function, so the ONNX and service
+    backends of cmd/benchmark can be checked against the Go network.
     """
     abs_json_path = os.path.abspath(json_path)
     print(f"Loading Go model from JSON: {abs_json_path}")
@@ -62,51 +87,42 @@ def load_go_json_and_convert(json_path, onnx_path, model_type_str):
     input_size = model_config["inputSize"]
     hidden_size = model_config["hiddenSize"]
 
-    if model_type_str == "policy":
-        output_size = model_config.get("outputSize", 9) # Default policy output size
-        pytorch_model = PyTorchPolicyNet(input_size, hidden_size, output_size)
-        print(f"Instantiated PyTorchPolicyNet (In: {input_size}, Hidden: {hidden_size}, Out: {output_size})")
-        # --- Load Policy Weights (Assuming JSON structure) ---
-        # Example keys - adjust based on your actual Go JSON export format
-        if 'weightsInputHiddenPolicy' in model_config and 'biasesHiddenPolicy' in model_config and \
-           'weightsHiddenOutputPolicy' in model_config and 'biasesOutputPolicy' in model_config:
-           
-            print("Loading Policy weights...")
-            # Layer 1
-            w1 = np.array(model_config['weightsInputHiddenPolicy']).astype(np.float32)
-            b1 = np.array(model_config['biasesHiddenPolicy']).astype(np.float32)
-            pytorch_model.layer1.weight.data = torch.from_numpy(w1)
-            pytorch_model.layer1.bias.data = torch.from_numpy(b1)
-            # Layer 2
-            w2 = np.array(model_config['weightsHiddenOutputPolicy']).astype(np.float32)
-            b2 = np.array(model_config['biasesOutputPolicy']).astype(np.float32)
-            pytorch_model.layer2.weight.data = torch.from_numpy(w2)
-            pytorch_model.layer2.bias.data = torch.from_numpy(b2)
-            print("Policy weights loaded from JSON.")
-        else:
-            print("Warning: Policy weight keys not found in JSON. Model will have initial random weights.")
+    # Go stores weights as [out][in], the same layout as nn.Linear
+    w1 = torch.tensor(model_config['weightsInputHidden'], dtype=torch.float32)
+    b1 = torch.tensor(model_config['biasesHidden'], dtype=torch.float32)
+    w2 = torch.tensor(model_config['weightsHiddenOutput'], dtype=torch.float32)
 
+    # At inference batch norm is a fixed per-neuron affine map, so fold it
+    # into the hidden layer: gamma * (z - mean) / sqrt(var + eps) + beta
+    if model_config.get("batchNorm"):
+        mean = torch.tensor(model_config['batchNormRunningMean'], dtype=torch.float64)
+        var = torch.tensor(model_config['batchNormRunningVariance'], dtype=torch.float64)
+        gamma = torch.tensor(model_config['batchNormGamma'], dtype=torch.float64)
+        beta = torch.tensor(model_config['batchNormBeta'], dtype=torch.float64)
+        scale = gamma / torch.sqrt(var + 1e-5)  # batchNormEpsilon in batch_norm.go
+        w1 = (w1.double() * scale[:, None]).float()
+        b1 = (scale * (b1.double() - mean) + beta).float()
+        print("Folded batch norm into the hidden layer.")
+
+    if model_type_str == "policy":
+        output_size = model_config.get("outputSize", 9)
+        pytorch_model = GoPolicyNet(input_size, hidden_size, output_size)
+        b2 = torch.tensor(model_config['biasesOutput'], dtype=torch.float32)
     elif model_type_str == "value":
         output_size = 1
-        pytorch_model = PyTorchValueNet(input_size, hidden_size)
-        print(f"Instantiated PyTorchValueNet (In: {input_size}, Hidden: {hidden_size}, Out: {output_size})")
-        # --- Load Value Weights (Existing Logic) ---
-        print("Loading Value weights...")
-        # Layer 1 (fc1 in original script)
-        w1 = np.array(model_config['weightsInputHidden']).astype(np.float32)
-        b1 = np.array(model_config['biasesHidden']).astype(np.float32)
-        pytorch_model.layer1.weight.data = torch.from_numpy(w1)
-        pytorch_model.layer1.bias.data = torch.from_numpy(b1)
-        # Layer 2 (fc2 in original script)
-        w2 = np.array(model_config['weightsHiddenOutput']).astype(np.float32)
-        # Go JSON 'biasOutput' is scalar, PyTorch bias is vector [1]
+        pytorch_model = GoValueNet(input_size, hidden_size)
+        # The value network's output bias is a scalar
         b2 = torch.tensor([model_config['biasOutput']], dtype=torch.float32)
-        pytorch_model.layer2.weight.data = torch.from_numpy(w2)
-        pytorch_model.layer2.bias.data = b2
-        print("Value weights loaded from JSON.")
-
     else:
         raise ValueError(f"Unknown model_type_str: {model_type_str}")
+    print(f"Instantiated Go {model_type_str} network (In: {input_size}, Hidden: {hidden_size}, Out: {output_size})")
+
+    with torch.no_grad():
+        pytorch_model.layer1.weight.copy_(w1)
+        pytorch_model.layer1.bias.copy_(b1)
+        pytorch_model.layer2.weight.copy_(w2)
+        pytorch_model.layer2.bias.copy_(b2)
+    print(f"{model_type_str.capitalize()} weights loaded from JSON.")
 
     # --- Export to ONNX ---
     export_pytorch_model_to_onnx(pytorch_model, input_size, onnx_path, model_type_str)